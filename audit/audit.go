@@ -0,0 +1,118 @@
+// Package audit parses lock files into dependency trees and audits their packages against a curated npm
+// registry. It is the core of the ca-extension commands, and can be used by other Go tools:
+//
+//	tree, err := audit.ParseLockFile("pnpm-lock.yaml")
+//	...
+//	auditor := &audit.Auditor{Registry: &audit.Registry{URL: registryURL, AccessToken: token}, Workers: 5}
+//	results := auditor.Audit(tree.Dependencies())
+package audit
+
+import (
+	"sort"
+	"time"
+)
+
+// Types of the audited packages: those the lock file records, and those bundled inside their tarballs
+const (
+	TypePackage = "package"
+	TypeBundled = "bundled"
+)
+
+// PackageInfo represents package information
+type PackageInfo struct {
+	Version    string                 `json:"version"`
+	Type       string                 `json:"type"`
+	Resolution map[string]interface{} `json:"resolution"`
+	Engines    map[string]interface{} `json:"engines"`
+
+	OptionalDependencies map[string]string `json:"optionalDependencies,omitempty"`
+	PeerDependencies     map[string]string `json:"peerDependencies,omitempty"`
+	OptionalPeers        []string          `json:"optionalPeers,omitempty"`
+	// Dependencies maps the dependencies of the package, optional ones included, to their resolved versions
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+
+	// Ecosystem of the package, set for the trees of SBOMs, whose packages may be of several ecosystems
+	Ecosystem string `json:"ecosystem,omitempty"`
+}
+
+// Dependency represents a dependency to be audited
+type Dependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Type    string `json:"type"`
+
+	// Tarball is a pinned tarball URL to audit instead of the registry's
+	Tarball string `json:"tarball,omitempty"`
+
+	// Relationship is RelationshipDirect or RelationshipTransitive, empty when the lock file doesn't tell
+	Relationship string `json:"relationship,omitempty"`
+
+	// Ecosystem of the package, when it may differ from that of the registry, as for the packages of SBOMs
+	Ecosystem string `json:"ecosystem,omitempty"`
+}
+
+// DependencyTree represents the complete dependency tree
+type DependencyTree struct {
+	// SchemaVersion is the version of the output schema the tree was written with, empty for parsed trees
+	SchemaVersion string                 `json:"schemaVersion,omitempty"`
+	Packages      map[string]PackageInfo `json:"packages"`
+
+	// Importers maps each workspace project to its direct dependencies and their resolved versions.
+	// The versions keep the peer suffixes the lockfile records, e.g. 18.2.0(react@18.2.0).
+	Importers map[string]map[string]string `json:"importers,omitempty"`
+
+	// DevDependencies lists the direct dependencies of each project declared as devDependencies, for the lock
+	// files recording them
+	DevDependencies map[string][]string `json:"devDependencies,omitempty"`
+}
+
+// AuditResult represents the result of a single package audit
+type AuditResult struct {
+	Index      int
+	Name       string
+	Version    string
+	Type       string
+	Status     string
+	StatusCode int
+	Error      error
+
+	// Policies blocking the package, reported by the curation audit API
+	Policies []CurationPolicy
+	// Pending is set when the curation audit API hasn't evaluated the package yet, as it isn't cached in the remote
+	// repository. The registry answers 403, but no policy blocks the package.
+	Pending bool
+
+	// Attempts is the number of checks the result took, more than 1 when transient errors were retried
+	Attempts int
+	// RetryAfter is the delay the Retry-After header of a 429 or 503 response asks for before the next attempt
+	RetryAfter time.Duration
+
+	// Relationship of the package to the projects of the lock file, as tagged on its dependency
+	Relationship string
+}
+
+// Dependencies returns the packages of the tree to audit, sorted by name and tagged with their relationship
+func (tree *DependencyTree) Dependencies() []Dependency {
+	var deps []Dependency
+	relationships := tree.Relationships()
+	for _, packageName := range sortedKeys(tree.Packages) {
+		info := tree.Packages[packageName]
+		deps = append(deps, Dependency{
+			Name:         packageName,
+			Version:      info.Version,
+			Type:         info.Type,
+			Relationship: relationships[packageName],
+			Ecosystem:    info.Ecosystem,
+		})
+	}
+	return deps
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
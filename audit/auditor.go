@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrDeadlineExceeded is the error of the results of dependencies the Auditor didn't check before its deadline
+var ErrDeadlineExceeded = errors.New("not checked before the audit deadline")
+
+// Auditor checks dependencies against a registry concurrently
+type Auditor struct {
+	Registry *Registry
+
+	// Workers is the number of concurrent registry requests
+	Workers int
+
+	// OnResult, if set, is called with each result as it completes, from a single goroutine. The workers wait
+	// while it runs, so a slow callback holds the audit back instead of results piling up in memory.
+	OnResult func(AuditResult)
+
+	// Deadline, if set, is the time after which no more checks start. The dependencies left yield results with
+	// ErrDeadlineExceeded, while the checks in flight complete.
+	Deadline time.Time
+}
+
+// Audit checks the dependencies and returns the results in the original dependency order. A check that fails,
+// or panics, yields a result with the error instead of stopping the audit.
+func (auditor *Auditor) Audit(deps []Dependency) []AuditResult {
+	return auditor.AuditContext(context.Background(), deps)
+}
+
+// AuditContext is like Audit, aborting the checks in flight once the context is done. The dependencies left
+// yield results with the error of the context, which IsInterrupted reports when the context is canceled and
+// IsTimeout when its deadline passes.
+func (auditor *Auditor) AuditContext(ctx context.Context, deps []Dependency) []AuditResult {
+	workers := auditor.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make([]auditJob, len(deps))
+	for i, dep := range deps {
+		jobs[i] = auditJob{index: i, dep: dep}
+	}
+	// The results channel only holds a result per worker
+	results := make(chan AuditResult, workers)
+	go func() {
+		RunPool(ctx, jobs, workers, func(job auditJob) error {
+			if !auditor.Deadline.IsZero() && time.Now().After(auditor.Deadline) {
+				return ErrDeadlineExceeded
+			}
+			result := auditor.Registry.CheckContext(ctx, job.dep)
+			result.Index = job.index
+			results <- result
+			return nil
+		}, func(job auditJob, err error) {
+			status := "❌ Check Failed"
+			if IsTimeout(err) {
+				status = StatusTimedOut
+			}
+			results <- AuditResult{
+				Name:    job.dep.Name,
+				Version: job.dep.Version,
+				Type:    job.dep.Type,
+				Status:  status,
+				Error:   err,
+				Index:   job.index,
+			}
+		})
+		close(results)
+	}()
+
+	// Every dependency yields exactly one result, which goes back to the position of its dependency
+	ordered := make([]AuditResult, len(deps))
+	for result := range results {
+		result.Relationship = deps[result.Index].Relationship
+		ordered[result.Index] = result
+		if auditor.OnResult != nil {
+			auditor.OnResult(result)
+		}
+	}
+	return ordered
+}
+
+// IsInterrupted reports whether a result is that of a check the context of AuditContext aborted, or kept from
+// starting
+func IsInterrupted(result AuditResult) bool {
+	return errors.Is(result.Error, context.Canceled)
+}
+
+// auditJob is a dependency to check with its position in the audited dependencies, so that results are put back
+// in order even when the same name@version is listed more than once
+type auditJob struct {
+	index int
+	dep   Dependency
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditorOrdersResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/lodash/-/lodash-4.17.20.tgz" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	var completed int
+	auditor := &Auditor{Registry: &Registry{URL: server.URL}, Workers: 3, OnResult: func(AuditResult) { completed++ }}
+	results := auditor.Audit([]Dependency{{Name: "abbrev", Version: "1.1.1"}, {Name: "lodash", Version: "4.17.20"}, {Name: "@types/node", Version: "20.11.0"}})
+	assert.Equal(t, 3, completed)
+	assert.Equal(t, []string{"abbrev", "lodash", "@types/node"}, []string{results[0].Name, results[1].Name, results[2].Name})
+	assert.Equal(t, []int{http.StatusOK, http.StatusForbidden, http.StatusOK}, []int{results[0].StatusCode, results[1].StatusCode, results[2].StatusCode})
+	assert.Equal(t, 1, results[1].Index)
+}
+
+func TestAuditorTagsRelationships(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	auditor := &Auditor{Registry: &Registry{URL: server.URL}, Workers: 2}
+	results := auditor.Audit([]Dependency{
+		{Name: "lodash", Version: "4.17.21", Relationship: RelationshipDirect},
+		{Name: "ms", Version: "2.1.3", Relationship: RelationshipTransitive},
+	})
+	assert.Equal(t, []string{RelationshipDirect, RelationshipTransitive}, []string{results[0].Relationship, results[1].Relationship})
+}
+
+func TestAuditorReportsPanicsAsResults(t *testing.T) {
+	// A nil registry makes the check panic
+	results := (&Auditor{Workers: 2}).Audit([]Dependency{{Name: "lodash", Version: "4.17.21"}})
+	assert.Len(t, results, 1)
+	assert.Equal(t, "❌ Check Failed", results[0].Status)
+	assert.Error(t, results[0].Error)
+}
+
+func TestAuditorKeepsDuplicateDependencies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	auditor := &Auditor{Registry: &Registry{URL: server.URL}, Workers: 4}
+	results := auditor.Audit([]Dependency{
+		{Name: "ms", Version: "2.1.3", Relationship: RelationshipDirect},
+		{Name: "lodash", Version: "4.17.21", Relationship: RelationshipTransitive},
+		{Name: "ms", Version: "2.1.3", Relationship: RelationshipTransitive},
+	})
+	assert.Len(t, results, 3)
+	assert.Equal(t, []int{0, 1, 2}, []int{results[0].Index, results[1].Index, results[2].Index})
+	assert.Equal(t, []string{"ms", "lodash", "ms"}, []string{results[0].Name, results[1].Name, results[2].Name})
+	assert.Equal(t, []string{RelationshipDirect, RelationshipTransitive, RelationshipTransitive}, []string{results[0].Relationship, results[1].Relationship, results[2].Relationship})
+}
+
+func TestAuditorStopsAtDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	auditor := &Auditor{Registry: &Registry{URL: server.URL}, Workers: 2, Deadline: time.Now().Add(-time.Second)}
+	results := auditor.Audit([]Dependency{{Name: "lodash", Version: "4.17.21"}, {Name: "ms", Version: "2.1.3"}})
+	assert.Len(t, results, 2)
+	for _, result := range results {
+		assert.ErrorIs(t, result.Error, ErrDeadlineExceeded)
+		assert.Equal(t, StatusTimedOut, result.Status)
+	}
+}
+
+func TestAuditorAbortsOnCancel(t *testing.T) {
+	started := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	auditor := &Auditor{Registry: &Registry{URL: server.URL, Retry: RetryPolicy{Attempts: 3}}, Workers: 1}
+	results := auditor.AuditContext(ctx, []Dependency{{Name: "lodash", Version: "4.17.21"}, {Name: "ms", Version: "2.1.3"}})
+	assert.Len(t, results, 2)
+	for _, result := range results {
+		assert.True(t, IsInterrupted(result), result.Name)
+	}
+	assert.Equal(t, 1, results[0].Attempts)
+	assert.False(t, IsInterrupted(AuditResult{Error: ErrDeadlineExceeded}))
+}
@@ -0,0 +1,184 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	composerLockFileName = "composer.lock"
+
+	// Upper bound of the Composer metadata files read
+	maxComposerMetadataSize = 32 << 20
+	// Value of the minified metadata fields a version drops from the previous one
+	composerUnset = "__unset"
+)
+
+// PackageManagerComposer is the package manager of PHP packages
+const PackageManagerComposer = "composer"
+
+// composerPackage is a package of the packages or packages-dev arrays of a composer.lock
+type composerPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Type    string `json:"type"`
+	Dist    *struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"dist"`
+}
+
+// IsComposerDevVersion reports whether a version is a branch, such as dev-main or 2.x-dev, rather than a release
+func IsComposerDevVersion(version string) bool {
+	return strings.HasPrefix(version, "dev-") || strings.HasSuffix(version, "-dev")
+}
+
+// ComposerMetadataURL returns the Composer v2 metadata of a package, e.g.
+// https://acme.jfrog.io/artifactory/api/composer/php-remote/p2/symfony/console.json, where the metadata of the
+// branches is a separate ~dev file
+func ComposerMetadataURL(composerRepositoryBaseURL, name, version string) string {
+	suffix := ".json"
+	if IsComposerDevVersion(version) {
+		suffix = "~dev.json"
+	}
+	return strings.TrimSuffix(composerRepositoryBaseURL, "/") + "/p2/" + EscapePath(strings.ToLower(name)+suffix)
+}
+
+// parseComposerLockData reads the packages and packages-dev arrays of a composer.lock. Metapackages have nothing
+// to download, and packages of path repositories are local directories, so both are skipped.
+func parseComposerLockData(data []byte) (*DependencyTree, error) {
+	var lock struct {
+		Packages    []composerPackage `json:"packages"`
+		PackagesDev []composerPackage `json:"packages-dev"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("error parsing composer.lock: %v", err)
+	}
+
+	tree := &DependencyTree{Packages: make(map[string]PackageInfo)}
+	for i, pkg := range append(lock.Packages, lock.PackagesDev...) {
+		if pkg.Name == "" || pkg.Version == "" {
+			return nil, fmt.Errorf("composer.lock package %d without a name or version", i+1)
+		}
+		if pkg.Type == "metapackage" {
+			continue
+		}
+		if pkg.Dist != nil && pkg.Dist.Type == "path" {
+			log.Debug(fmt.Sprintf("Skipping %s %s, installed from a path repository", pkg.Name, pkg.Version))
+			continue
+		}
+		tree.Packages[strings.ToLower(pkg.Name)] = PackageInfo{Version: pkg.Version, Type: TypePackage}
+	}
+	return tree, nil
+}
+
+// checkComposer audits a package against a curated Composer repository: it resolves the dist URL of the version
+// from the metadata of the package, and requests the archive, which curation blocks like npm tarballs. The
+// credentials are only sent to dist URLs on the hosts of the repository and its mirrors, not to the VCS hosts
+// public repositories point dist URLs at.
+func (registry *Registry) checkComposer(ctx context.Context, dep Dependency) AuditResult {
+	result := AuditResult{Name: dep.Name, Version: dep.Version, Type: dep.Type}
+	metadataURL := ComposerMetadataURL(registry.URL, dep.Name, dep.Version)
+
+	resp, err := requestTarball(ctx, registry.client(), http.MethodGet, metadataURL, registry.credentials())
+	var redirect *RedirectError
+	if errors.As(err, &redirect) {
+		result.Status, result.StatusCode = redirect.Status(), redirect.StatusCode
+		return result
+	}
+	if err != nil {
+		result.Status, result.Error = "❌ Request Failed", err
+		return result
+	}
+	metadata, err := io.ReadAll(io.LimitReader(resp.Body, maxComposerMetadataSize))
+	resp.Body.Close()
+	if err != nil {
+		result.Status, result.Error = "❌ Request Failed", err
+		return result
+	}
+	if resp.StatusCode != http.StatusOK {
+		result.Status, result.StatusCode, result.RetryAfter = StatusForCode(resp.StatusCode), resp.StatusCode, retryAfter(resp)
+		return result
+	}
+
+	distURL, err := composerDistURL(metadataURL, metadata, dep.Name, dep.Version)
+	if err != nil {
+		result.Status, result.Error = "❌ Invalid Composer metadata", err
+		return result
+	}
+	if distURL == "" {
+		result.Status, result.StatusCode = "❌ Not Found (404): no dist of the version in the metadata", http.StatusNotFound
+		return result
+	}
+
+	var credentials Credentials
+	if host := strings.ToLower(HostOf(distURL)); host == strings.ToLower(HostOf(registry.URL)) || MatchesHost(host, registry.MirrorHosts) {
+		credentials = registry.credentials()
+	}
+	result = registry.checkTarball(ctx, dep, distURL, credentials)
+	if result.StatusCode == http.StatusOK {
+		result.Status = "✅ Available in Composer Registry"
+	}
+	return result
+}
+
+// composerDistURL returns the dist URL of a version in Composer v2 metadata, resolved against the metadata URL, or
+// is empty when the metadata doesn't have the version. Minified metadata only lists the fields of each version
+// that differ from the previous one, so the versions are expanded in order.
+func composerDistURL(metadataURL string, metadata []byte, name, version string) (string, error) {
+	var document struct {
+		Packages map[string][]map[string]interface{} `json:"packages"`
+		Minified string                              `json:"minified"`
+	}
+	if err := json.Unmarshal(metadata, &document); err != nil {
+		return "", fmt.Errorf("error parsing Composer metadata of %s: %v", name, err)
+	}
+	var versions []map[string]interface{}
+	for packageName, entries := range document.Packages {
+		if strings.EqualFold(packageName, name) {
+			versions = entries
+		}
+	}
+
+	expanded := make(map[string]interface{})
+	for _, entry := range versions {
+		if document.Minified == "" {
+			expanded = entry
+		} else {
+			for key, value := range entry {
+				if value == composerUnset {
+					delete(expanded, key)
+				} else {
+					expanded[key] = value
+				}
+			}
+		}
+		entryVersion, _ := expanded["version"].(string)
+		if entryVersion != version && strings.TrimPrefix(entryVersion, "v") != strings.TrimPrefix(version, "v") {
+			continue
+		}
+		dist, _ := expanded["dist"].(map[string]interface{})
+		distURL, _ := dist["url"].(string)
+		if distURL == "" {
+			return "", nil
+		}
+		base, err := url.Parse(metadataURL)
+		if err != nil {
+			return "", err
+		}
+		resolved, err := base.Parse(distURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid dist URL '%s' of %s %s: %v", distURL, name, version, err)
+		}
+		return resolved.String(), nil
+	}
+	return "", nil
+}
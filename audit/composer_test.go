@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseComposerLock(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "composer", "composer.lock"))
+	assert.NoError(t, err)
+
+	// Metapackages and path repositories are skipped, dev packages are kept and names are lowercased
+	assert.Equal(t, []string{"guzzlehttp/guzzle", "phpunit/phpunit", "symfony/console"}, sortedKeys(tree.Packages))
+	assert.Equal(t, "7.8.1", tree.Packages["guzzlehttp/guzzle"].Version)
+	assert.Equal(t, "v6.4.3", tree.Packages["symfony/console"].Version)
+	assert.Equal(t, TypePackage, tree.Packages["phpunit/phpunit"].Type)
+	assert.Equal(t, EcosystemComposer, EcosystemOf(PackageManagerFor("composer.lock")))
+}
+
+func TestParseComposerLockErrors(t *testing.T) {
+	_, err := parseComposerLockData([]byte(`{"packages": [{"name": "guzzlehttp/guzzle"}]}`))
+	assert.EqualError(t, err, "composer.lock package 1 without a name or version")
+
+	_, err = parseComposerLockData([]byte(`{"packages": {}}`))
+	assert.Error(t, err)
+}
+
+func TestComposerMetadataURL(t *testing.T) {
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/api/composer/php-remote/p2/symfony/console.json",
+		ComposerMetadataURL("https://acme.jfrog.io/artifactory/api/composer/php-remote/", "Symfony/Console", "v6.4.3"))
+	assert.Equal(t, "https://repo.packagist.org/p2/acme/shared~dev.json", ComposerMetadataURL("https://repo.packagist.org", "acme/shared", "dev-main"))
+	assert.True(t, IsComposerDevVersion("2.x-dev"))
+	assert.False(t, IsComposerDevVersion("1.0.0-beta1"))
+}
+
+func TestComposerDistURL(t *testing.T) {
+	// Minified metadata: 6.4.2 inherits the dist of 6.4.3 until it sets its own, and 6.4.1 unsets it
+	metadata := []byte(`{"minified": "composer/2.0", "packages": {"symfony/console": [
+		{"name": "symfony/console", "version": "v6.4.3", "dist": {"type": "zip", "url": "../../dist/symfony/console/v6.4.3.zip"}},
+		{"version": "v6.4.2", "dist": {"type": "zip", "url": "https://cdn.example.com/console-6.4.2.zip"}},
+		{"version": "v6.4.1", "dist": "__unset"}
+	]}}`)
+	metadataURL := "https://acme.jfrog.io/artifactory/api/composer/php-remote/p2/symfony/console.json"
+
+	distURL, err := composerDistURL(metadataURL, metadata, "symfony/console", "v6.4.3")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/api/composer/php-remote/dist/symfony/console/v6.4.3.zip", distURL)
+
+	distURL, err = composerDistURL(metadataURL, metadata, "Symfony/Console", "6.4.2")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/console-6.4.2.zip", distURL)
+
+	for _, version := range []string{"v6.4.1", "v7.0.0"} {
+		distURL, err = composerDistURL(metadataURL, metadata, "symfony/console", version)
+		assert.NoError(t, err)
+		assert.Empty(t, distURL, version)
+	}
+
+	_, err = composerDistURL(metadataURL, []byte("<html>"), "symfony/console", "v6.4.3")
+	assert.Error(t, err)
+}
+
+func TestCheckComposer(t *testing.T) {
+	authorized := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorized[r.URL.Path] = r.Header.Get("Authorization") == "Bearer token"
+		switch r.URL.Path {
+		case "/api/composer/php-remote/p2/guzzlehttp/guzzle.json":
+			w.Write([]byte(`{"packages": {"guzzlehttp/guzzle": [{"version": "7.8.1", "dist": {"url": "/api/composer/php-remote/direct-dists/guzzle-7.8.1.zip"}}]}}`))
+		case "/api/composer/php-remote/direct-dists/guzzle-7.8.1.zip":
+		case "/api/composer/php-remote/p2/monolog/monolog.json":
+			w.Write([]byte(`{"packages": {"monolog/monolog": [{"version": "1.25.0", "dist": {"url": "/api/composer/php-remote/direct-dists/monolog-1.25.0.zip"}}]}}`))
+		case "/api/composer/php-remote/direct-dists/monolog-1.25.0.zip":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	registry := &Registry{URL: server.URL + "/api/composer/php-remote", AccessToken: "token", Ecosystem: EcosystemComposer}
+
+	result := registry.Check(Dependency{Name: "guzzlehttp/guzzle", Version: "7.8.1", Type: TypePackage})
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, "✅ Available in Composer Registry", result.Status)
+	assert.True(t, authorized["/api/composer/php-remote/direct-dists/guzzle-7.8.1.zip"])
+
+	result = registry.Check(Dependency{Name: "monolog/monolog", Version: "1.25.0", Type: TypePackage})
+	assert.Equal(t, http.StatusForbidden, result.StatusCode)
+
+	result = registry.Check(Dependency{Name: "guzzlehttp/guzzle", Version: "6.0.0", Type: TypePackage})
+	assert.Equal(t, http.StatusNotFound, result.StatusCode)
+	assert.Equal(t, "❌ Not Found (404): no dist of the version in the metadata", result.Status)
+
+	result = registry.Check(Dependency{Name: "missing/package", Version: "1.0.0", Type: TypePackage})
+	assert.Equal(t, http.StatusNotFound, result.StatusCode)
+}
+
+func TestCheckComposerForeignDist(t *testing.T) {
+	// The token isn't sent to dist hosts other than the registry's
+	var distAuthorization string
+	dist := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		distAuthorization = r.Header.Get("Authorization")
+	}))
+	defer dist.Close()
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"packages": {"guzzlehttp/guzzle": [{"version": "7.8.1", "dist": {"url": "` + dist.URL + `/zipball/41042bc"}}]}}`))
+	}))
+	defer metadata.Close()
+	// Both servers listen on 127.0.0.1, so the metadata is addressed by localhost
+	metadataURL, err := url.Parse(metadata.URL)
+	assert.NoError(t, err)
+	registry := &Registry{URL: "http://localhost:" + metadataURL.Port(), AccessToken: "token", Ecosystem: EcosystemComposer}
+
+	result := registry.Check(Dependency{Name: "guzzlehttp/guzzle", Version: "7.8.1", Type: TypePackage})
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Empty(t, distAuthorization)
+}
@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Upper bound of the curation API response bodies read
+const maxCurationResponseSize = 1 << 20
+
+// Matches the {policy, condition, explanation, recommendation} groups of a curation block message
+var curationPolicyPattern = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// CurationPolicy represents a curation policy a package violates
+type CurationPolicy struct {
+	Policy         string `json:"policy"`
+	Condition      string `json:"condition,omitempty"`
+	Explanation    string `json:"explanation,omitempty"`
+	Recommendation string `json:"recommendation,omitempty"`
+}
+
+// curationErrors represents the errors Artifactory responds with when curation blocks a package
+type curationErrors struct {
+	Errors []struct {
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// CurationAuditURL returns the URL of the curation audit endpoint of an Artifactory npm registry, which evaluates
+// the curation policies of a package without downloading its tarball
+func CurationAuditURL(npmRegistryBaseURL string) (string, error) {
+	artifactoryURL, repoPath, found := strings.Cut(npmRegistryBaseURL, "/api/npm/")
+	if !found || repoPath == "" {
+		return "", fmt.Errorf("%s is not an Artifactory npm repository URL", npmRegistryBaseURL)
+	}
+	return artifactoryURL + "/api/curation/audit/api/npm/" + repoPath, nil
+}
+
+// checkCuration audits a dependency with the curation audit API, telling packages blocked by a policy from those
+// curation hasn't evaluated yet because the remote repository hasn't cached them
+func (registry *Registry) checkCuration(ctx context.Context, dep Dependency) AuditResult {
+	auditURL, err := CurationAuditURL(registry.URL)
+	if err == nil {
+		auditURL, err = TarballURL(auditURL, dep.Name, dep.Version)
+	}
+	if err != nil {
+		return AuditResult{
+			Name:    dep.Name,
+			Version: dep.Version,
+			Type:    dep.Type,
+			Status:  "❌ Curation API Unavailable",
+			Error:   err,
+		}
+	}
+
+	client := registry.client()
+	req, err := http.NewRequestWithContext(ctx, "GET", auditURL, nil)
+	if err != nil {
+		return AuditResult{
+			Name:    dep.Name,
+			Version: dep.Version,
+			Type:    dep.Type,
+			Status:  "❌ Request Failed",
+			Error:   err,
+		}
+	}
+	registry.credentials().Authorize(req)
+
+	resp, err := client.Do(req)
+	var redirect *RedirectError
+	if errors.As(err, &redirect) {
+		return AuditResult{
+			Name:       dep.Name,
+			Version:    dep.Version,
+			Type:       dep.Type,
+			Status:     redirect.Status(),
+			StatusCode: redirect.StatusCode,
+		}
+	}
+	if err != nil {
+		return AuditResult{
+			Name:    dep.Name,
+			Version: dep.Version,
+			Type:    dep.Type,
+			Status:  "❌ Request Failed",
+			Error:   err,
+		}
+	}
+	defer resp.Body.Close()
+
+	result := AuditResult{
+		Name:       dep.Name,
+		Version:    dep.Version,
+		Type:       dep.Type,
+		StatusCode: resp.StatusCode,
+		RetryAfter: retryAfter(resp),
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		result.Status = "✅ Approved by Curation"
+	case http.StatusForbidden:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxCurationResponseSize))
+		message := curationMessage(body)
+		if strings.Contains(strings.ToLower(message), "not cached") || strings.Contains(strings.ToLower(message), "pending") {
+			result.Status = "⚠️ Not Cached (curation pending)"
+			result.Pending = true
+			break
+		}
+		result.Policies = ParseCurationPolicies(message)
+		result.Status = "❌ Blocked by Curation Policy"
+		if len(result.Policies) > 0 {
+			var names []string
+			for _, policy := range result.Policies {
+				names = append(names, policy.Policy)
+			}
+			result.Status += ": " + strings.Join(names, ", ")
+			// The condition of the first policy is the most telling, e.g. a malicious package
+			if condition := result.Policies[0].Condition; condition != "" {
+				result.Status += fmt.Sprintf(" (%s)", condition)
+			}
+		}
+	default:
+		result.Status = StatusForCode(resp.StatusCode)
+	}
+	return result
+}
+
+// curationMessage returns the error message of a curation response, or the body itself when it isn't JSON
+func curationMessage(body []byte) string {
+	var errs curationErrors
+	if err := json.Unmarshal(body, &errs); err != nil || len(errs.Errors) == 0 {
+		return string(body)
+	}
+	return errs.Errors[0].Message
+}
+
+// ParseCurationPolicies parses the policies of a curation block message, such as:
+//
+//	Package lodash:4.17.20 download was blocked by JFrog Packages Curation service due to the following policies
+//	violated {block-malicious, Malicious package, Package is flagged as malicious, Remove the package}.
+func ParseCurationPolicies(message string) []CurationPolicy {
+	_, violated, found := strings.Cut(message, "policies violated")
+	if !found {
+		return nil
+	}
+	var policies []CurationPolicy
+	for _, match := range curationPolicyPattern.FindAllStringSubmatch(violated, -1) {
+		fields := strings.SplitN(match[1], ",", 4)
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if fields[0] == "" {
+			continue
+		}
+		policy := CurationPolicy{Policy: fields[0]}
+		if len(fields) > 1 {
+			policy.Condition = fields[1]
+		}
+		if len(fields) > 2 {
+			policy.Explanation = fields[2]
+		}
+		if len(fields) > 3 {
+			policy.Recommendation = fields[3]
+		}
+		policies = append(policies, policy)
+	}
+	return policies
+}
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurationAuditURL(t *testing.T) {
+	auditURL, err := CurationAuditURL("https://acme.jfrog.io/artifactory/api/npm/npm-remote")
+	require.NoError(t, err)
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/api/curation/audit/api/npm/npm-remote", auditURL)
+
+	_, err = CurationAuditURL("https://registry.npmjs.org")
+	assert.ErrorContains(t, err, "not an Artifactory npm repository URL")
+}
+
+func TestParseCurationPolicies(t *testing.T) {
+	policies := ParseCurationPolicies("Package lodash:4.17.20 download was blocked by JFrog Packages Curation service due to the " +
+		"following policies violated {block-malicious, Malicious package, Package is flagged as malicious, Remove the package}, " +
+		"{block-cves, CVE with CVSS score of 9 or above}.")
+	assert.Equal(t, []CurationPolicy{
+		{Policy: "block-malicious", Condition: "Malicious package", Explanation: "Package is flagged as malicious", Recommendation: "Remove the package"},
+		{Policy: "block-cves", Condition: "CVE with CVSS score of 9 or above"},
+	}, policies)
+	assert.Empty(t, ParseCurationPolicies("Forbidden"))
+}
+
+func TestCheckCuration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/artifactory/api/curation/audit/api/npm/npm-remote/abbrev/-/abbrev-1.1.1.tgz":
+		case "/artifactory/api/curation/audit/api/npm/npm-remote/lodash/-/lodash-4.17.20.tgz":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":[{"status":403,"message":"Package lodash:4.17.20 download was blocked by JFrog Packages Curation service due to the following policies violated {block-malicious, Malicious package, Package is flagged as malicious, Remove the package}."}]}`))
+		case "/artifactory/api/curation/audit/api/npm/npm-remote/yallist/-/yallist-4.0.0.tgz":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":[{"status":403,"message":"Package yallist:4.0.0 is not cached yet, curation evaluation is pending"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	registry := &Registry{URL: server.URL + "/artifactory/api/npm/npm-remote", CurationAPI: true}
+
+	result := registry.Check(Dependency{Name: "abbrev", Version: "1.1.1"})
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, "✅ Approved by Curation", result.Status)
+
+	result = registry.Check(Dependency{Name: "lodash", Version: "4.17.20"})
+	assert.Equal(t, http.StatusForbidden, result.StatusCode)
+	assert.Equal(t, "❌ Blocked by Curation Policy: block-malicious (Malicious package)", result.Status)
+	require.Len(t, result.Policies, 1)
+	assert.Equal(t, "Remove the package", result.Policies[0].Recommendation)
+
+	result = registry.Check(Dependency{Name: "yallist", Version: "4.0.0"})
+	assert.Equal(t, "⚠️ Not Cached (curation pending)", result.Status)
+	assert.True(t, result.Pending)
+	assert.Empty(t, result.Policies)
+
+	result = registry.Check(Dependency{Name: "left-pad", Version: "1.3.0"})
+	assert.Equal(t, "❌ Not Found (404)", result.Status)
+
+	result = (&Registry{URL: server.URL, CurationAPI: true}).Check(Dependency{Name: "abbrev", Version: "1.1.1"})
+	assert.Equal(t, "❌ Curation API Unavailable", result.Status)
+	assert.Error(t, result.Error)
+}
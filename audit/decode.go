@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Number of entries of a YAML mapping decoded by each task. Smaller mappings are decoded on the calling goroutine.
+const decodeChunkSize = 512
+
+// decodeYAMLMapping decodes the values of a mapping node of names to mappings, such as the packages section of a
+// pnpm lock file, and converts each entry. Chunks of entries are decoded across the cores, which matters once the
+// registry checks are cached and lock files have tens of thousands of packages. Entries keep their document order.
+// Absent and null nodes have no entries.
+func decodeYAMLMapping[T any](node *yaml.Node, convert func(key string, value map[string]interface{}) T) ([]T, error) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == 0 || node.Tag == "!!null" {
+		return nil, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping", node.Line)
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		// Merge keys pull entries from elsewhere, which only decoding the mapping as a whole resolves
+		if node.Content[i].Tag == "!!merge" {
+			return decodeYAMLMappingSequentially(node, convert)
+		}
+	}
+
+	entries := len(node.Content) / 2
+	results := make([]T, entries)
+	errs := make([]error, entries)
+	decodeRange := func(start, end int) {
+		for i := start; i < end; i++ {
+			var value map[string]interface{}
+			if err := node.Content[2*i+1].Decode(&value); err != nil {
+				errs[i] = fmt.Errorf("%s: %v", node.Content[2*i].Value, err)
+				continue
+			}
+			results[i] = convert(node.Content[2*i].Value, value)
+		}
+	}
+	if entries <= decodeChunkSize {
+		decodeRange(0, entries)
+	} else {
+		var chunks []int
+		for start := 0; start < entries; start += decodeChunkSize {
+			chunks = append(chunks, start)
+		}
+		var wg sync.WaitGroup
+		next := make(chan int)
+		for worker := 0; worker < runtime.GOMAXPROCS(0) && worker < len(chunks); worker++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for start := range next {
+					decodeRange(start, min(start+decodeChunkSize, entries))
+				}
+			}()
+		}
+		for _, start := range chunks {
+			next <- start
+		}
+		close(next)
+		wg.Wait()
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func decodeYAMLMappingSequentially[T any](node *yaml.Node, convert func(key string, value map[string]interface{}) T) ([]T, error) {
+	var mapping map[string]map[string]interface{}
+	if err := node.Decode(&mapping); err != nil {
+		return nil, err
+	}
+	keys := sortedKeys(mapping)
+	results := make([]T, len(keys))
+	for i, key := range keys {
+		results[i] = convert(key, mapping[key])
+	}
+	return results, nil
+}
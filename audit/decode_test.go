@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLargePnpmLockConcurrently(t *testing.T) {
+	packages := 3*decodeChunkSize + 7
+	var lock strings.Builder
+	lock.WriteString("lockfileVersion: '9.0'\nimporters:\n  .:\n    dependencies:\n      pkg-0:\n        specifier: ^1.0.0\n        version: 1.0.0\npackages:\n")
+	for i := 0; i < packages; i++ {
+		fmt.Fprintf(&lock, "  pkg-%d@1.0.0:\n    resolution: {integrity: sha512-%d}\n", i, i)
+	}
+	lock.WriteString("snapshots:\n")
+	for i := 0; i < packages; i++ {
+		fmt.Fprintf(&lock, "  pkg-%d@1.0.0:\n", i)
+		if i+1 < packages {
+			fmt.Fprintf(&lock, "    dependencies:\n      pkg-%d: 1.0.0\n", i+1)
+		}
+	}
+
+	tree, err := parsePnpmLockData([]byte(lock.String()))
+	assert.NoError(t, err)
+	assert.Len(t, tree.Packages, packages)
+	assert.Equal(t, map[string]string{"pkg-1": "1.0.0"}, tree.Packages["pkg-0"].Dependencies)
+	assert.Equal(t, fmt.Sprintf("sha512-%d", packages-1), tree.Packages[fmt.Sprintf("pkg-%d", packages-1)].Resolution["integrity"])
+	assert.Nil(t, tree.Packages[fmt.Sprintf("pkg-%d", packages-1)].Dependencies)
+	assert.Len(t, tree.DependencyPaths()[fmt.Sprintf("pkg-%d", packages-1)], packages+1)
+}
+
+func TestDecodeYAMLMappingErrors(t *testing.T) {
+	_, err := parsePnpmLockData([]byte("lockfileVersion: '9.0'\npackages:\n  abbrev@1.1.1: [1]\n"))
+	assert.ErrorContains(t, err, "abbrev@1.1.1")
+
+	tree, err := parsePnpmLockData([]byte("lockfileVersion: '9.0'\npackages: ~\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, tree.Packages)
+}
@@ -0,0 +1,215 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	goSumFileName = "go.sum"
+	goModFileName = "go.mod"
+)
+
+// PackageManagerGo is the package manager of Go modules
+const PackageManagerGo = "go"
+
+// Matches the module directive of a go.mod
+var goModulePattern = regexp.MustCompile(`(?m)^\s*module\s`)
+
+// EscapeGoModulePath escapes a module path or version as the Go module proxy protocol does: each uppercase
+// letter becomes an exclamation mark followed by the letter in lowercase, e.g. github.com/Azure becomes
+// github.com/!azure
+func EscapeGoModulePath(path string) string {
+	var escaped strings.Builder
+	for _, r := range path {
+		if r >= 'A' && r <= 'Z' {
+			escaped.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
+
+// GoModuleInfoURL returns the version info of a module in a Go module proxy, e.g.
+// https://acme.jfrog.io/artifactory/api/go/go-remote/github.com/!azure/go-autorest/@v/v14.2.0+incompatible.info
+func GoModuleInfoURL(goProxyBaseURL, module, version string) string {
+	return strings.TrimSuffix(goProxyBaseURL, "/") + "/" + EscapePath(EscapeGoModulePath(module)) + "/@v/" + EscapePathSegment(EscapeGoModulePath(version)+".info")
+}
+
+// goParser parses go.sum and go.mod files, told apart by the module directive only go.mod files have
+type goParser struct{}
+
+func (goParser) Parse(path string) (*DependencyTree, error) {
+	fileName := goSumFileName
+	if filepath.Base(path) == goModFileName {
+		fileName = goModFileName
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s not found at path: %s", fileName, path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return goParser{}.ParseData(data)
+}
+
+func (goParser) ParseData(data []byte) (*DependencyTree, error) {
+	if goModulePattern.Match(data) {
+		return parseGoModData(data)
+	}
+	return parseGoSumData(data)
+}
+
+// parseGoSumData reads the modules of a go.sum. Modules only listed with the hash of their go.mod take part in
+// version selection without being downloaded, so they are skipped. Of several versions of a module, the highest
+// is audited, as minimal version selection builds with it.
+func parseGoSumData(data []byte) (*DependencyTree, error) {
+	tree := &DependencyTree{Packages: make(map[string]PackageInfo)}
+	goModOnly := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("go.sum line %d: expected <module> <version> <hash>, got '%s'", lineNumber, line)
+		}
+		module, version := fields[0], fields[1]
+		if strings.HasSuffix(version, "/go.mod") {
+			goModOnly[module] = true
+			continue
+		}
+		if existing, exists := tree.Packages[module]; !exists || isHigherVersion(version, existing.Version) {
+			tree.Packages[module] = PackageInfo{Version: version, Type: TypePackage}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading go.sum: %v", err)
+	}
+
+	skipped := 0
+	for module := range goModOnly {
+		if _, exists := tree.Packages[module]; !exists {
+			skipped++
+		}
+	}
+	if skipped > 0 {
+		log.Debug(fmt.Sprintf("Skipping %d modules of which only the go.mod is downloaded", skipped))
+	}
+	return tree, nil
+}
+
+// parseGoModData reads the requirements of a go.mod, which since Go 1.17 lists every module the build needs.
+// Requirements replaced by local directories are skipped, and those replaced by other modules are audited as them.
+func parseGoModData(data []byte) (*DependencyTree, error) {
+	type goModule struct{ path, version string }
+	var requires []goModule
+	// Replacements by module path, or by path@version for those of a single version
+	replacements := make(map[string]*goModule)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	block := ""
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if comment := strings.Index(line, "//"); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if block != "" && fields[0] == ")" {
+			block = ""
+			continue
+		}
+		directive := block
+		if directive == "" {
+			directive, fields = fields[0], fields[1:]
+			if len(fields) == 1 && fields[0] == "(" {
+				block = directive
+				continue
+			}
+		}
+		for i := range fields {
+			fields[i] = strings.Trim(fields[i], "\"`")
+		}
+
+		switch directive {
+		case "require":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("go.mod line %d: expected require <module> <version>", lineNumber)
+			}
+			requires = append(requires, goModule{path: fields[0], version: fields[1]})
+		case "replace":
+			arrow := -1
+			for i, field := range fields {
+				if field == "=>" {
+					arrow = i
+				}
+			}
+			if arrow < 1 || arrow > 2 || len(fields)-arrow-1 < 1 || len(fields)-arrow-1 > 2 {
+				return nil, fmt.Errorf("go.mod line %d: expected replace <module> [<version>] => <replacement> [<version>]", lineNumber)
+			}
+			key := fields[0]
+			if arrow == 2 {
+				key += "@" + fields[1]
+			}
+			// A replacement without a version is a directory, of which nothing is downloaded
+			var replacement *goModule
+			if len(fields)-arrow-1 == 2 {
+				replacement = &goModule{path: fields[arrow+1], version: fields[arrow+2]}
+			}
+			replacements[key] = replacement
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading go.mod: %v", err)
+	}
+
+	tree := &DependencyTree{Packages: make(map[string]PackageInfo)}
+	for _, module := range requires {
+		replacement, replaced := replacements[module.path+"@"+module.version]
+		if !replaced {
+			replacement, replaced = replacements[module.path]
+		}
+		if replaced {
+			if replacement == nil {
+				log.Debug(fmt.Sprintf("Skipping %s %s, replaced by a local directory", module.path, module.version))
+				continue
+			}
+			module = *replacement
+		}
+		if existing, exists := tree.Packages[module.path]; !exists || isHigherVersion(module.version, existing.Version) {
+			tree.Packages[module.path] = PackageInfo{Version: module.version, Type: TypePackage}
+		}
+	}
+	return tree, nil
+}
+
+// checkGo audits a module against a curated Go module proxy by requesting the info of its version
+func (registry *Registry) checkGo(ctx context.Context, dep Dependency) AuditResult {
+	result := registry.checkTarball(ctx, dep, GoModuleInfoURL(registry.URL, dep.Name, dep.Version), registry.credentials())
+	if result.StatusCode == http.StatusOK {
+		result.Status = "✅ Available in Go Registry"
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGoSum(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "go", "go.sum"))
+	assert.NoError(t, err)
+
+	// Modules of which only the go.mod is hashed are skipped, and of several versions the highest is kept
+	assert.Equal(t, []string{"github.com/Azure/go-autorest", "github.com/davecgh/go-spew", "golang.org/x/net"}, sortedKeys(tree.Packages))
+	assert.Equal(t, "v14.2.0+incompatible", tree.Packages["github.com/Azure/go-autorest"].Version)
+	assert.Equal(t, "v1.1.1", tree.Packages["github.com/davecgh/go-spew"].Version)
+	assert.Equal(t, "v0.17.0", tree.Packages["golang.org/x/net"].Version)
+	assert.Equal(t, TypePackage, tree.Packages["golang.org/x/net"].Type)
+	assert.Equal(t, EcosystemGo, EcosystemOf(PackageManagerFor("go.sum")))
+}
+
+func TestParseGoSumErrors(t *testing.T) {
+	_, err := parseGoSumData([]byte("golang.org/x/net v0.17.0\n"))
+	assert.EqualError(t, err, "go.sum line 1: expected <module> <version> <hash>, got 'golang.org/x/net v0.17.0'")
+}
+
+func TestParseGoMod(t *testing.T) {
+	tree, err := goParser{}.ParseData([]byte(`module github.com/acme/service
+
+go 1.22
+
+require github.com/spf13/cobra v1.8.0
+
+require (
+	github.com/acme/shared v0.3.0
+	github.com/pkg/errors v0.9.1 // indirect
+	"golang.org/x/text" v0.14.0 // indirect
+)
+
+replace github.com/acme/shared => ../shared
+
+replace (
+	github.com/pkg/errors v0.9.1 => github.com/acme/errors v0.9.2
+)
+`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"github.com/acme/errors", "github.com/spf13/cobra", "golang.org/x/text"}, sortedKeys(tree.Packages))
+	assert.Equal(t, "v0.9.2", tree.Packages["github.com/acme/errors"].Version)
+	assert.Equal(t, "v0.14.0", tree.Packages["golang.org/x/text"].Version)
+	assert.Equal(t, PackageManagerGo, PackageManagerFor("go.mod"))
+}
+
+func TestParseGoModErrors(t *testing.T) {
+	_, err := parseGoModData([]byte("module m\n\nrequire (\n\tgolang.org/x/net\n)\n"))
+	assert.EqualError(t, err, "go.mod line 4: expected require <module> <version>")
+
+	_, err = parseGoModData([]byte("module m\n\nreplace golang.org/x/net v0.17.0\n"))
+	assert.EqualError(t, err, "go.mod line 3: expected replace <module> [<version>] => <replacement> [<version>]")
+}
+
+func TestGoModuleInfoURL(t *testing.T) {
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/api/go/go-remote/github.com/!azure/go-autorest/@v/v14.2.0%2Bincompatible.info",
+		GoModuleInfoURL("https://acme.jfrog.io/artifactory/api/go/go-remote/", "github.com/Azure/go-autorest", "v14.2.0+incompatible"))
+	assert.Equal(t, "github.com/!burnt!sushi/toml", EscapeGoModulePath("github.com/BurntSushi/toml"))
+}
+
+func TestCheckGo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/go/go-remote/github.com/!azure/go-autorest/@v/v14.2.0+incompatible.info":
+		case "/api/go/go-remote/golang.org/x/net/@v/v0.17.0.info":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	registry := &Registry{URL: server.URL + "/api/go/go-remote", Ecosystem: EcosystemGo}
+
+	result := registry.Check(Dependency{Name: "github.com/Azure/go-autorest", Version: "v14.2.0+incompatible", Type: TypePackage})
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, "✅ Available in Go Registry", result.Status)
+
+	result = registry.Check(Dependency{Name: "golang.org/x/net", Version: "v0.17.0", Type: TypePackage})
+	assert.Equal(t, http.StatusForbidden, result.StatusCode)
+
+	result = registry.Check(Dependency{Name: "golang.org/x/net", Version: "v0.18.0", Type: TypePackage})
+	assert.Equal(t, http.StatusNotFound, result.StatusCode)
+}
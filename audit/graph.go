@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"sort"
+	"strings"
+)
+
+// Relationships of the audited packages to the projects of the lock file
+const (
+	// RelationshipDirect packages are dependencies of the project, or of one of the workspace projects
+	RelationshipDirect = "direct"
+	// RelationshipTransitive packages are only pulled in by other packages
+	RelationshipTransitive = "transitive"
+)
+
+// Relationships tags each package of the tree as a direct dependency of one of its importers or a transitive one.
+// Packages are matched by name, as the tree keeps a single version of each. Trees without importers, of lock files
+// that don't record the direct dependencies, return nil.
+func (tree *DependencyTree) Relationships() map[string]string {
+	if len(tree.Importers) == 0 {
+		return nil
+	}
+	direct := make(map[string]bool)
+	for _, dependencies := range tree.Importers {
+		for name, version := range dependencies {
+			direct[tree.dependencyName(name, version)] = true
+		}
+	}
+	relationships := make(map[string]string, len(tree.Packages))
+	for name := range tree.Packages {
+		relationships[name] = RelationshipTransitive
+		if direct[name] {
+			relationships[name] = RelationshipDirect
+		}
+	}
+	return relationships
+}
+
+// DirectDependencies keeps the dependencies tagged as direct, in their order
+func DirectDependencies(deps []Dependency) []Dependency {
+	var direct []Dependency
+	for _, dep := range deps {
+		if dep.Relationship == RelationshipDirect {
+			direct = append(direct, dep)
+		}
+	}
+	return direct
+}
+
+// DependencyPaths returns the shortest chain from an importer to each package of the tree: the importer path,
+// then the <name>@<version> of each package down to the package itself, e.g. [apps/web react-dom@18.2.0
+// loose-envify@1.4.0]. Every importer is a starting point, so packages reached through a linked workspace project
+// start at that project. Trees without importers, or without recorded package dependencies beyond the direct
+// ones, only have the paths they record.
+func (tree *DependencyTree) DependencyPaths() map[string][]string {
+	paths := make(map[string][]string)
+	var queue []string
+	for _, importer := range sortedKeys(tree.Importers) {
+		dependencies := tree.Importers[importer]
+		for _, name := range sortedKeys(dependencies) {
+			if strings.HasPrefix(dependencies[name], "link:") {
+				continue
+			}
+			name = tree.dependencyName(name, dependencies[name])
+			info, exists := tree.Packages[name]
+			if _, visited := paths[name]; !exists || visited {
+				continue
+			}
+			paths[name] = []string{importer, name + "@" + info.Version}
+			queue = append(queue, name)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		dependencies := tree.Packages[current].Dependencies
+		for _, name := range sortedKeys(dependencies) {
+			name = tree.dependencyName(name, dependencies[name])
+			info, exists := tree.Packages[name]
+			if _, visited := paths[name]; !exists || visited {
+				continue
+			}
+			path := append(append([]string{}, paths[current]...), name+"@"+info.Version)
+			paths[name] = path
+			queue = append(queue, name)
+		}
+	}
+	return paths
+}
+
+// Closure returns the packages of the tree among the names, and those they depend on directly or transitively
+func (tree *DependencyTree) Closure(names ...string) map[string]bool {
+	closure := make(map[string]bool)
+	queue := append([]string{}, names...)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		info, exists := tree.Packages[name]
+		if !exists || closure[name] {
+			continue
+		}
+		closure[name] = true
+		for dependency, version := range info.Dependencies {
+			queue = append(queue, tree.dependencyName(dependency, version))
+		}
+	}
+	return closure
+}
+
+// DevOnly returns the packages that only the devDependencies of the projects pull in, which production installs
+// leave out. It is nil for trees that don't record devDependencies.
+func (tree *DependencyTree) DevOnly() map[string]bool {
+	if len(tree.DevDependencies) == 0 {
+		return nil
+	}
+	var prod, dev []string
+	for importer, dependencies := range tree.Importers {
+		devDependencies := make(map[string]bool, len(tree.DevDependencies[importer]))
+		for _, name := range tree.DevDependencies[importer] {
+			devDependencies[name] = true
+		}
+		for name, version := range dependencies {
+			if strings.HasPrefix(version, "link:") {
+				continue
+			}
+			// The peers resolved for the dependency are installed with it
+			roots := []string{tree.dependencyName(name, version)}
+			for peer := range extractIndirectDependencies(version) {
+				roots = append(roots, peer)
+			}
+			if devDependencies[name] {
+				dev = append(dev, roots...)
+			} else {
+				prod = append(prod, roots...)
+			}
+		}
+	}
+	production := tree.Closure(prod...)
+	devOnly := make(map[string]bool)
+	for name := range tree.Closure(dev...) {
+		if !production[name] {
+			devOnly[name] = true
+		}
+	}
+	return devOnly
+}
+
+// DependencyGraph maps each package of the tree to the sorted packages of the tree it depends on, aliases resolved
+// to the packages they install. Packages without recorded dependencies are left out.
+func (tree *DependencyTree) DependencyGraph() map[string][]string {
+	graph := make(map[string][]string)
+	for name, info := range tree.Packages {
+		seen := make(map[string]bool)
+		for dependency, version := range info.Dependencies {
+			dependency = tree.dependencyName(dependency, version)
+			if _, exists := tree.Packages[dependency]; exists && dependency != name && !seen[dependency] {
+				seen[dependency] = true
+				graph[name] = append(graph[name], dependency)
+			}
+		}
+		sort.Strings(graph[name])
+	}
+	return graph
+}
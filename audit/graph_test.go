@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelationships(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "pnpm-workspace", "pnpm-lock.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"js-tokens":    RelationshipTransitive,
+		"loose-envify": RelationshipDirect,
+		"react":        RelationshipDirect,
+		"react-dom":    RelationshipDirect,
+		// Direct through the string-width-cjs alias
+		"string-width": RelationshipDirect,
+		"typescript":   RelationshipDirect,
+	}, tree.Relationships())
+
+	deps := tree.Dependencies()
+	assert.Equal(t, Dependency{Name: "js-tokens", Version: "4.0.0", Type: TypePackage, Relationship: RelationshipTransitive}, deps[0])
+	direct := DirectDependencies(deps)
+	assert.Len(t, direct, 5)
+	assert.NotContains(t, direct, deps[0])
+
+	// Requirements files don't record which packages were asked for
+	requirements, err := ParseLockFileData("requirements.txt", []byte("requests==2.31.0\n"))
+	assert.NoError(t, err)
+	assert.Nil(t, requirements.Relationships())
+	assert.Empty(t, requirements.Dependencies()[0].Relationship)
+}
+
+func TestExtractIndirectDependencies(t *testing.T) {
+	assert.Equal(t, map[string]string{"react": "18.2.0", "@types/react": "18.2.0"},
+		extractIndirectDependencies("18.2.0(@types/react@18.2.0)(react@18.2.0)"))
+	assert.Equal(t, map[string]string{"react-dom": "18.2.0", "react": "18.2.0"},
+		extractIndirectDependencies("1.0.0(react-dom@18.2.0(react@18.2.0))"))
+	assert.Empty(t, extractIndirectDependencies("18.2.0"))
+}
+
+func TestDependencyPaths(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "pnpm-workspace", "pnpm-lock.yaml"))
+	assert.NoError(t, err)
+	paths := tree.DependencyPaths()
+	assert.Equal(t, []string{"legacy/old", "loose-envify@1.4.0", "js-tokens@4.0.0"}, paths["js-tokens"])
+	assert.Equal(t, []string{"apps/web", "react-dom@18.2.0"}, paths["react-dom"])
+	// The shortest path starts at the project depending on the package directly
+	assert.Equal(t, []string{"packages/ui", "react@18.2.0"}, paths["react"])
+	assert.Equal(t, []string{"packages/ui", "string-width@4.2.3"}, paths["string-width"])
+
+	tree, err = ParseLockFile(filepath.Join("testdata", "npm", "package-lock.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".", "chalk@4.1.2", "ansi-styles@4.3.0"}, tree.DependencyPaths()["ansi-styles"])
+}
+
+func TestClosure(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "pnpm-workspace", "pnpm-lock.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"react-dom": true, "react": true, "loose-envify": true, "js-tokens": true}, tree.Closure("react-dom"))
+	assert.Equal(t, map[string]bool{"typescript": true}, tree.Closure("typescript", "missing"))
+}
+
+func TestDevOnly(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "pnpm-workspace", "pnpm-lock.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{".": {"typescript"}}, tree.DevDependencies)
+	assert.Equal(t, map[string]bool{"typescript": true}, tree.DevOnly())
+
+	tree, err = ParseLockFile(filepath.Join("testdata", "npm", "package-lock.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{".": {"semver"}}, tree.DevDependencies)
+	assert.True(t, tree.DevOnly()["semver"])
+	assert.False(t, tree.DevOnly()["chalk"])
+
+	// Yarn lock files don't tell devDependencies apart
+	tree, err = ParseLockFile(filepath.Join("testdata", "yarn-classic", "yarn.lock"))
+	assert.NoError(t, err)
+	assert.Nil(t, tree.DevOnly())
+}
+
+func TestDependencyGraph(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "pnpm-workspace", "pnpm-lock.yaml"))
+	assert.NoError(t, err)
+	graph := tree.DependencyGraph()
+	assert.Equal(t, []string{"js-tokens"}, graph["loose-envify"])
+	assert.Equal(t, []string{"loose-envify", "react"}, graph["react-dom"])
+	assert.NotContains(t, graph, "js-tokens")
+}
@@ -0,0 +1,221 @@
+package audit
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	pnpmLockFileName      = "pnpm-lock.yaml"
+	yarnLockFileName      = "yarn.lock"
+	npmLockFileName       = "package-lock.json"
+	npmShrinkwrapFileName = "npm-shrinkwrap.json"
+
+	// Upper bound of the keys listed in lock file errors
+	maxDescribedKeys = 5
+)
+
+// Package managers with a registered lock file parser
+const (
+	PackageManagerPnpm = "pnpm"
+	PackageManagerYarn = "yarn"
+	PackageManagerNpm  = "npm"
+)
+
+// LockFileParser parses the lock files of a package manager into dependency trees
+type LockFileParser interface {
+	// Parse parses the lock file at path
+	Parse(path string) (*DependencyTree, error)
+	// ParseData parses the content of a lock file, e.g. one read from git history
+	ParseData(data []byte) (*DependencyTree, error)
+}
+
+// parserRegistration represents a registered parser and the lock file names it is picked for
+type parserRegistration struct {
+	parser    LockFileParser
+	fileNames []string
+}
+
+var parsers = map[string]parserRegistration{
+	PackageManagerPnpm: {parser: pnpmParser{}, fileNames: []string{pnpmLockFileName}},
+	PackageManagerYarn: {parser: lockDataParser{fileName: yarnLockFileName, parseData: parseYarnLockData}, fileNames: []string{yarnLockFileName}},
+	PackageManagerNpm: {
+		parser:    lockDataParser{fileName: npmLockFileName, parseData: parseNpmLockData},
+		fileNames: []string{npmLockFileName, npmShrinkwrapFileName},
+	},
+	PackageManagerPoetry: {
+		parser:    lockDataParser{fileName: poetryLockFileName, parseData: parsePoetryLockData},
+		fileNames: []string{poetryLockFileName},
+	},
+	PackageManagerPip: {
+		parser:    lockDataParser{fileName: requirementsFileName, parseData: parseRequirementsData},
+		fileNames: []string{requirementsFileName},
+	},
+	PackageManagerGo: {parser: goParser{}, fileNames: []string{goSumFileName, goModFileName}},
+	PackageManagerGradle: {
+		parser:    lockDataParser{fileName: gradleLockFileName, parseData: parseGradleLockData},
+		fileNames: []string{gradleLockFileName},
+	},
+	PackageManagerMaven: {
+		parser:    lockDataParser{fileName: pomFileName, parseData: parsePOMData},
+		fileNames: []string{pomFileName, effectivePomFileName},
+	},
+	PackageManagerComposer: {
+		parser:    lockDataParser{fileName: composerLockFileName, parseData: parseComposerLockData},
+		fileNames: []string{composerLockFileName},
+	},
+	PackageManagerSBOM: {parser: lockDataParser{fileName: "SBOM", parseData: parseSBOMData}, fileNames: sbomFileNames},
+}
+
+// Ecosystems of the registries packages are audited against
+const (
+	EcosystemNpm      = "npm"
+	EcosystemPyPI     = "pypi"
+	EcosystemGo       = "go"
+	EcosystemMaven    = "maven"
+	EcosystemComposer = "composer"
+)
+
+// Ecosystems of the package managers that don't install from npm registries
+var packageManagerEcosystems = map[string]string{
+	PackageManagerPoetry:   EcosystemPyPI,
+	PackageManagerPip:      EcosystemPyPI,
+	PackageManagerGo:       EcosystemGo,
+	PackageManagerGradle:   EcosystemMaven,
+	PackageManagerMaven:    EcosystemMaven,
+	PackageManagerComposer: EcosystemComposer,
+}
+
+// EcosystemOf returns the ecosystem of the registry the packages of a package manager are installed from
+func EcosystemOf(packageManager string) string {
+	if ecosystem, ok := packageManagerEcosystems[packageManager]; ok {
+		return ecosystem
+	}
+	return EcosystemNpm
+}
+
+// RegisterParser registers the lock file parser of a package manager, picked by ParseLockFile for the lock files
+// named one of fileNames, or matching one of them as a filepath.Match pattern. It replaces any parser registered for the package manager, and is meant to be called
+// from init functions, before lock files are parsed.
+func RegisterParser(packageManager string, parser LockFileParser, fileNames ...string) {
+	parsers[packageManager] = parserRegistration{parser: parser, fileNames: fileNames}
+}
+
+// Parser returns the lock file parser registered for a package manager
+func Parser(packageManager string) (LockFileParser, bool) {
+	registration, ok := parsers[packageManager]
+	return registration.parser, ok
+}
+
+// PackageManagers lists the package managers with a registered lock file parser
+func PackageManagers() []string {
+	return sortedKeys(parsers)
+}
+
+// PackageManagerFor returns the package manager whose parser is registered for a lock file name, which may be a
+// pattern such as *.cdx.json. Lock files of unknown names are parsed as pnpm-lock.yaml files.
+func PackageManagerFor(name string) string {
+	name = filepath.Base(name)
+	for _, packageManager := range PackageManagers() {
+		for _, fileName := range parsers[packageManager].fileNames {
+			if matched, _ := filepath.Match(fileName, name); matched {
+				return packageManager
+			}
+		}
+	}
+	return PackageManagerPnpm
+}
+
+// parserFor returns the parser registered for a lock file name
+func parserFor(name string) LockFileParser {
+	return parsers[PackageManagerFor(name)].parser
+}
+
+// ParseLockFile parses a lock file into a dependency tree, with the parser registered for its file name
+func ParseLockFile(lockFilePath string) (*DependencyTree, error) {
+	return parserFor(lockFilePath).Parse(lockFilePath)
+}
+
+// ParseLockFileData parses the content of the lock file named name
+func ParseLockFileData(name string, data []byte) (*DependencyTree, error) {
+	return parserFor(name).ParseData(data)
+}
+
+// lockDataParser reads lock files whose parsing only needs their content
+type lockDataParser struct {
+	fileName  string
+	parseData func(data []byte) (*DependencyTree, error)
+}
+
+func (p lockDataParser) Parse(path string) (*DependencyTree, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s not found at path: %s", p.fileName, path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return p.parseData(data)
+}
+
+func (p lockDataParser) ParseData(data []byte) (*DependencyTree, error) {
+	return p.parseData(data)
+}
+
+// yamlKeyLines returns the line of every key of a top-level section of a YAML document, or of the document
+// itself when section is empty
+func yamlKeyLines(data []byte, section string) map[string]int {
+	lines := make(map[string]int)
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return lines
+	}
+	mapping := root.Content[0]
+	if section != "" {
+		mapping = yamlMappingValue(mapping, section)
+	}
+	if mapping != nil && mapping.Kind == yaml.AliasNode {
+		mapping = mapping.Alias
+	}
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return lines
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		lines[mapping.Content[i].Value] = mapping.Content[i].Line
+	}
+	return lines
+}
+
+func yamlMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// describeYAMLKeys lists keys with their lines, e.g. line 12 'abbrev', line 15 'yallist'
+func describeYAMLKeys(keys []string, lines map[string]int) string {
+	var described []string
+	for i, key := range keys {
+		if i == maxDescribedKeys {
+			described = append(described, fmt.Sprintf("and %d more", len(keys)-maxDescribedKeys))
+			break
+		}
+		if line, ok := lines[key]; ok {
+			described = append(described, fmt.Sprintf("line %d '%s'", line, key))
+		} else {
+			described = append(described, fmt.Sprintf("'%s'", key))
+		}
+	}
+	return strings.Join(described, ", ")
+}
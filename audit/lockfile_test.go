@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cargoParser stands in for the parser of an ecosystem registered by another package
+type cargoParser struct{}
+
+func (cargoParser) Parse(path string) (*DependencyTree, error) {
+	return &DependencyTree{Packages: map[string]PackageInfo{"serde": {Version: "1.0.210", Type: TypePackage}}}, nil
+}
+
+func (p cargoParser) ParseData([]byte) (*DependencyTree, error) {
+	return p.Parse("")
+}
+
+func TestParserRegistry(t *testing.T) {
+	assert.Equal(t, []string{PackageManagerComposer, PackageManagerGo, PackageManagerGradle, PackageManagerMaven, PackageManagerNpm, PackageManagerPip, PackageManagerPnpm, PackageManagerPoetry, PackageManagerSBOM, PackageManagerYarn}, PackageManagers())
+	assert.IsType(t, pnpmParser{}, parserFor("pnpm-lock.yaml"))
+	assert.IsType(t, pnpmParser{}, parserFor(filepath.Join("web", "custom.yaml")))
+	assert.Equal(t, npmLockFileName, parserFor(filepath.Join("web", "npm-shrinkwrap.json")).(lockDataParser).fileName)
+
+	assert.Equal(t, PackageManagerYarn, PackageManagerFor(filepath.Join("web", "yarn.lock")))
+	assert.Equal(t, PackageManagerPnpm, PackageManagerFor("custom.yaml"))
+
+	parser, ok := Parser(PackageManagerPnpm)
+	require.True(t, ok)
+	tree, err := parser.Parse(filepath.Join("testdata", "pnpm-lock.yaml"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, tree.Packages)
+	_, ok = Parser("cargo")
+	assert.False(t, ok)
+
+	RegisterParser("cargo", cargoParser{}, "Cargo.lock")
+	defer delete(parsers, "cargo")
+	tree, err = ParseLockFile(filepath.Join("rust", "Cargo.lock"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.210", tree.Packages["serde"].Version)
+	tree, err = ParseLockFileData("Cargo.lock", nil)
+	require.NoError(t, err)
+	assert.Contains(t, tree.Packages, "serde")
+}
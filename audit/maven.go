@@ -0,0 +1,182 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	gradleLockFileName   = "gradle.lockfile"
+	pomFileName          = "pom.xml"
+	effectivePomFileName = "effective-pom.xml"
+)
+
+// Package managers of the Maven ecosystem
+const (
+	PackageManagerGradle = "gradle"
+	PackageManagerMaven  = "maven"
+)
+
+// pomDependency is a dependency element of a POM
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+// pomProject is a project of a POM. Only the direct dependencies are read, not the managed ones.
+type pomProject struct {
+	Dependencies []pomDependency `xml:"dependencies>dependency"`
+}
+
+// MavenCoordinates splits a groupId:artifactId package name
+func MavenCoordinates(name string) (groupID, artifactID string, ok bool) {
+	groupID, artifactID, ok = strings.Cut(name, ":")
+	return groupID, artifactID, ok && groupID != "" && artifactID != ""
+}
+
+// MavenPOMURL returns the POM of an artifact version in the Maven repository layout, e.g.
+// https://acme.jfrog.io/artifactory/maven-remote/com/google/guava/guava/33.0.0-jre/guava-33.0.0-jre.pom
+func MavenPOMURL(mavenRepositoryBaseURL, name, version string) (string, error) {
+	groupID, artifactID, ok := MavenCoordinates(name)
+	if !ok {
+		return "", fmt.Errorf("invalid Maven artifact '%s'. Expected <groupId>:<artifactId>", name)
+	}
+	groupPath := strings.ReplaceAll(groupID, ".", "/")
+	for _, segment := range append(strings.Split(groupPath, "/"), artifactID, version) {
+		if !isPathSegment(segment) || strings.Contains(segment, "/") {
+			return "", fmt.Errorf("invalid Maven artifact '%s' version '%s'", name, version)
+		}
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%s", strings.TrimSuffix(mavenRepositoryBaseURL, "/"), EscapePath(groupPath),
+		EscapePathSegment(artifactID), EscapePathSegment(version), EscapePathSegment(artifactID+"-"+version+".pom")), nil
+}
+
+// parseGradleLockData reads the <group>:<artifact>:<version>=<configurations> lines of a gradle.lockfile. Of
+// several versions of an artifact, locked for different configurations, the highest is audited.
+func parseGradleLockData(data []byte) (*DependencyTree, error) {
+	tree := &DependencyTree{Packages: make(map[string]PackageInfo)}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		coordinates, _, _ := strings.Cut(line, "=")
+		// The configurations without any dependency
+		if coordinates == "empty" {
+			continue
+		}
+		parts := strings.Split(coordinates, ":")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("gradle.lockfile line %d: expected <group>:<artifact>:<version>=<configurations>, got '%s'", lineNumber, line)
+		}
+		name := parts[0] + ":" + parts[1]
+		if existing, exists := tree.Packages[name]; !exists || isHigherVersion(parts[2], existing.Version) {
+			tree.Packages[name] = PackageInfo{Version: parts[2], Type: TypePackage}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading gradle.lockfile: %v", err)
+	}
+	return tree, nil
+}
+
+// parsePOMData reads the dependencies of a POM, or of the projects of the effective POM of a multi-module build
+// (mvn help:effective-pom -Doutput=effective-pom.xml). System dependencies aren't downloaded, so they are skipped,
+// as are dependencies without a resolved version, such as those managed by a parent POM or set by a property.
+func parsePOMData(data []byte) (*DependencyTree, error) {
+	var projects []pomProject
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("POM without a project element")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing POM: %v", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "project":
+			var project pomProject
+			if err := decoder.DecodeElement(&project, &start); err != nil {
+				return nil, fmt.Errorf("error parsing POM: %v", err)
+			}
+			projects = append(projects, project)
+		case "projects":
+			var effective struct {
+				Projects []pomProject `xml:"project"`
+			}
+			if err := decoder.DecodeElement(&effective, &start); err != nil {
+				return nil, fmt.Errorf("error parsing POM: %v", err)
+			}
+			projects = effective.Projects
+		default:
+			return nil, fmt.Errorf("unexpected POM root element <%s>. Expected <project> or <projects>", start.Name.Local)
+		}
+		break
+	}
+
+	tree := &DependencyTree{Packages: make(map[string]PackageInfo)}
+	var unresolved []string
+	for _, project := range projects {
+		for _, dep := range project.Dependencies {
+			groupID, artifactID, version := strings.TrimSpace(dep.GroupID), strings.TrimSpace(dep.ArtifactID), strings.TrimSpace(dep.Version)
+			if strings.TrimSpace(dep.Scope) == "system" {
+				continue
+			}
+			name := groupID + ":" + artifactID
+			if version == "" || strings.Contains(version, "${") {
+				unresolved = append(unresolved, name)
+				continue
+			}
+			if existing, exists := tree.Packages[name]; !exists || isHigherVersion(version, existing.Version) {
+				tree.Packages[name] = PackageInfo{Version: version, Type: TypePackage}
+			}
+		}
+	}
+
+	if len(unresolved) > 0 {
+		described := unresolved
+		if len(described) > maxDescribedKeys {
+			described = append(described[:maxDescribedKeys:maxDescribedKeys], fmt.Sprintf("and %d more", len(unresolved)-maxDescribedKeys))
+		}
+		if len(tree.Packages) == 0 {
+			return nil, fmt.Errorf("none of the %d dependencies have a resolved version, audit the effective POM (mvn help:effective-pom -Doutput=%s): %s",
+				len(unresolved), effectivePomFileName, strings.Join(described, ", "))
+		}
+		log.Warn(fmt.Sprintf("Skipping %d dependencies without a resolved version, audit the effective POM (mvn help:effective-pom -Doutput=%s) to include them: %s",
+			len(unresolved), effectivePomFileName, strings.Join(described, ", ")))
+	}
+	return tree, nil
+}
+
+// checkMaven audits an artifact against a curated Maven repository by requesting the POM of its version, which
+// every artifact has, whatever its packaging
+func (registry *Registry) checkMaven(ctx context.Context, dep Dependency) AuditResult {
+	pomURL, err := MavenPOMURL(registry.URL, dep.Name, dep.Version)
+	if err != nil {
+		return AuditResult{Name: dep.Name, Version: dep.Version, Type: dep.Type, Status: "❌ Invalid Maven artifact", Error: err}
+	}
+	result := registry.checkTarball(ctx, dep, pomURL, registry.credentials())
+	if result.StatusCode == http.StatusOK {
+		result.Status = "✅ Available in Maven Registry"
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGradleLockfile(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "gradle", "gradle.lockfile"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"com.google.code.findbugs:jsr305", "com.google.guava:failureaccess", "com.google.guava:guava", "org.junit.jupiter:junit-jupiter-api"},
+		sortedKeys(tree.Packages))
+	// Of the versions locked for different configurations, the highest is kept
+	assert.Equal(t, "33.0.0-jre", tree.Packages["com.google.guava:guava"].Version)
+	assert.Equal(t, TypePackage, tree.Packages["com.google.guava:guava"].Type)
+	assert.Equal(t, EcosystemMaven, EcosystemOf(PackageManagerFor("gradle.lockfile")))
+}
+
+func TestParseGradleLockfileErrors(t *testing.T) {
+	_, err := parseGradleLockData([]byte("com.google.guava:guava=compileClasspath\n"))
+	assert.EqualError(t, err, "gradle.lockfile line 1: expected <group>:<artifact>:<version>=<configurations>, got 'com.google.guava:guava=compileClasspath'")
+}
+
+func TestParseEffectivePOM(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "maven", "effective-pom.xml"))
+	assert.NoError(t, err)
+
+	// Managed, system and plugin artifacts aren't dependencies
+	assert.Equal(t, []string{"com.fasterxml.jackson.core:jackson-databind", "org.junit.jupiter:junit-jupiter", "org.slf4j:slf4j-api"}, sortedKeys(tree.Packages))
+	assert.Equal(t, "2.16.1", tree.Packages["com.fasterxml.jackson.core:jackson-databind"].Version)
+	assert.Equal(t, PackageManagerMaven, PackageManagerFor("pom.xml"))
+}
+
+func TestParsePOM(t *testing.T) {
+	tree, err := parsePOMData([]byte(`<project>
+  <dependencies>
+    <dependency><groupId>org.slf4j</groupId><artifactId>slf4j-api</artifactId><version>2.0.11</version></dependency>
+    <dependency><groupId>com.acme</groupId><artifactId>shared</artifactId><version>${shared.version}</version></dependency>
+  </dependencies>
+</project>`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"org.slf4j:slf4j-api"}, sortedKeys(tree.Packages))
+
+	_, err = parsePOMData([]byte(`<project><dependencies><dependency><groupId>com.acme</groupId><artifactId>shared</artifactId></dependency></dependencies></project>`))
+	assert.EqualError(t, err, "none of the 1 dependencies have a resolved version, audit the effective POM (mvn help:effective-pom -Doutput=effective-pom.xml): com.acme:shared")
+
+	_, err = parsePOMData([]byte(`<settings/>`))
+	assert.EqualError(t, err, "unexpected POM root element <settings>. Expected <project> or <projects>")
+}
+
+func TestMavenPOMURL(t *testing.T) {
+	pomURL, err := MavenPOMURL("https://acme.jfrog.io/artifactory/maven-remote/", "com.google.guava:guava", "33.0.0-jre")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/maven-remote/com/google/guava/guava/33.0.0-jre/guava-33.0.0-jre.pom", pomURL)
+
+	_, err = MavenPOMURL("https://acme.jfrog.io/artifactory/maven-remote", "guava", "33.0.0-jre")
+	assert.EqualError(t, err, "invalid Maven artifact 'guava'. Expected <groupId>:<artifactId>")
+}
+
+func TestCheckMaven(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/maven-remote/org/slf4j/slf4j-api/2.0.11/slf4j-api-2.0.11.pom":
+		case "/maven-remote/org/apache/logging/log4j/log4j-core/2.14.1/log4j-core-2.14.1.pom":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	registry := &Registry{URL: server.URL + "/maven-remote", Ecosystem: EcosystemMaven}
+
+	result := registry.Check(Dependency{Name: "org.slf4j:slf4j-api", Version: "2.0.11", Type: TypePackage})
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, "✅ Available in Maven Registry", result.Status)
+
+	result = registry.Check(Dependency{Name: "org.apache.logging.log4j:log4j-core", Version: "2.14.1", Type: TypePackage})
+	assert.Equal(t, http.StatusForbidden, result.StatusCode)
+
+	result = registry.Check(Dependency{Name: "log4j-core", Version: "2.14.1", Type: TypePackage})
+	assert.Equal(t, "❌ Invalid package", result.Status)
+	assert.Error(t, result.Error)
+}
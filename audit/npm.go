@@ -0,0 +1,237 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const npmModulesDir = "node_modules/"
+
+// npmLockData represents the structure of package-lock.json and npm-shrinkwrap.json
+type npmLockData struct {
+	LockfileVersion int `json:"lockfileVersion"`
+
+	// Lockfiles v2 and v3 key every installed package by its path, e.g. node_modules/a/node_modules/b
+	Packages map[string]npmLockPackage `json:"packages"`
+
+	// Lockfiles v1 nest the packages installed below others in their dependencies
+	Dependencies map[string]npmLockDependency `json:"dependencies"`
+}
+
+type npmLockPackage struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Resolved  string `json:"resolved"`
+	Integrity string `json:"integrity"`
+	Link      bool   `json:"link"`
+	InBundle  bool   `json:"inBundle"`
+
+	// Engines is usually an object, but very old packages declare an array
+	Engines              interface{}       `json:"engines"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	PeerDependenciesMeta map[string]struct {
+		Optional bool `json:"optional"`
+	} `json:"peerDependenciesMeta"`
+}
+
+type npmLockDependency struct {
+	Version      string                       `json:"version"`
+	Resolved     string                       `json:"resolved"`
+	Integrity    string                       `json:"integrity"`
+	Bundled      bool                         `json:"bundled"`
+	Dependencies map[string]npmLockDependency `json:"dependencies"`
+}
+
+// parseNpmLockData parses a package-lock.json. Packages are keyed by name as in pnpm trees; of the versions
+// installed at several node_modules depths, the highest is kept.
+func parseNpmLockData(data []byte) (*DependencyTree, error) {
+	var lockData npmLockData
+	if err := json.Unmarshal(data, &lockData); err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
+	}
+	if lockData.LockfileVersion == 0 {
+		return nil, fmt.Errorf("not an npm lock file: missing lockfileVersion")
+	}
+
+	tree := &DependencyTree{Packages: make(map[string]PackageInfo)}
+	if len(lockData.Packages) == 0 {
+		addNpmV1Dependencies(tree.Packages, lockData.Dependencies)
+		return tree, nil
+	}
+
+	var invalidKeys []string
+	importers := make(map[string]map[string]string)
+	for _, key := range sortedKeys(lockData.Packages) {
+		pkg := lockData.Packages[key]
+		if !strings.HasPrefix(key, npmModulesDir) && !strings.Contains(key, "/"+npmModulesDir) {
+			// The root project is keyed "" and workspace projects by their directory
+			importer := key
+			if importer == "" {
+				importer = "."
+			}
+			importers[importer] = resolveNpmDirectDependencies(lockData.Packages, key, pkg)
+			if dev := npmDevDependencies(lockData.Packages, key, pkg); len(dev) > 0 {
+				if tree.DevDependencies == nil {
+					tree.DevDependencies = make(map[string][]string)
+				}
+				tree.DevDependencies[importer] = dev
+			}
+			continue
+		}
+		// Links point to workspace projects, which are audited through their own entries
+		if pkg.Link || isNonRegistryResolution(pkg.Resolved) {
+			continue
+		}
+		name := pkg.Name
+		if name == "" {
+			name = key[strings.LastIndex(key, npmModulesDir)+len(npmModulesDir):]
+		}
+		if name == "" || pkg.Version == "" {
+			invalidKeys = append(invalidKeys, key)
+			continue
+		}
+		if existing, exists := tree.Packages[name]; exists && !isHigherVersion(pkg.Version, existing.Version) {
+			continue
+		}
+		tree.Packages[name] = npmPackageInfo(lockData.Packages, key, pkg)
+	}
+
+	if len(invalidKeys) > 0 {
+		keys := describeYAMLKeys(invalidKeys, yamlKeyLines(data, "packages"))
+		if len(tree.Packages) == 0 {
+			return nil, fmt.Errorf("none of the %d installed entries of the packages section have a version: %s", len(invalidKeys), keys)
+		}
+		log.Warn(fmt.Sprintf("Skipping %d entries of the packages section without a version: %s", len(invalidKeys), keys))
+	}
+	if len(importers) > 0 {
+		tree.Importers = importers
+	}
+	return tree, nil
+}
+
+func npmPackageInfo(packages map[string]npmLockPackage, key string, pkg npmLockPackage) PackageInfo {
+	info := PackageInfo{
+		Version:          pkg.Version,
+		Type:             TypePackage,
+		Resolution:       npmResolution(pkg.Resolved, pkg.Integrity),
+		PeerDependencies: pkg.PeerDependencies,
+	}
+	if pkg.InBundle {
+		info.Type = TypeBundled
+	}
+	if engines, ok := pkg.Engines.(map[string]interface{}); ok {
+		info.Engines = engines
+	}
+	if len(pkg.OptionalDependencies) > 0 {
+		info.OptionalDependencies = make(map[string]string, len(pkg.OptionalDependencies))
+		for name := range pkg.OptionalDependencies {
+			// Optional packages of other platforms aren't installed, so they have no entry to resolve
+			if version := resolveNpmDependency(packages, key, name); version != "" {
+				info.OptionalDependencies[name] = version
+			}
+		}
+	}
+	if dependencies := resolveNpmDirectDependencies(packages, key, pkg); len(dependencies) > 0 {
+		info.Dependencies = dependencies
+	}
+	for peer, meta := range pkg.PeerDependenciesMeta {
+		if meta.Optional {
+			info.OptionalPeers = append(info.OptionalPeers, peer)
+		}
+	}
+	sort.Strings(info.OptionalPeers)
+	return info
+}
+
+// resolveNpmDirectDependencies maps the dependencies of a project to their installed versions
+func resolveNpmDirectDependencies(packages map[string]npmLockPackage, key string, project npmLockPackage) map[string]string {
+	direct := make(map[string]string)
+	for _, section := range []map[string]string{project.Dependencies, project.DevDependencies, project.OptionalDependencies} {
+		for name := range section {
+			if version := resolveNpmDependency(packages, key, name); version != "" {
+				direct[name] = version
+			}
+		}
+	}
+	return direct
+}
+
+// npmDevDependencies lists the installed devDependencies of a project that it doesn't also list as dependencies
+func npmDevDependencies(packages map[string]npmLockPackage, key string, project npmLockPackage) []string {
+	var dev []string
+	for _, name := range sortedKeys(project.DevDependencies) {
+		if _, exists := project.Dependencies[name]; !exists && resolveNpmDependency(packages, key, name) != "" {
+			dev = append(dev, name)
+		}
+	}
+	return dev
+}
+
+// resolveNpmDependency finds the version of name a package at key loads, searching the node_modules
+// directories from the package's own up to the root as Node.js does
+func resolveNpmDependency(packages map[string]npmLockPackage, key, name string) string {
+	for {
+		candidate := npmModulesDir + name
+		if key != "" {
+			candidate = key + "/" + candidate
+		}
+		if pkg, ok := packages[candidate]; ok {
+			if pkg.Link {
+				return ""
+			}
+			return pkg.Version
+		}
+		if key == "" {
+			return ""
+		}
+		if parent := strings.LastIndex(key, "/"+npmModulesDir); parent >= 0 {
+			key = key[:parent]
+		} else {
+			key = ""
+		}
+	}
+}
+
+// addNpmV1Dependencies adds the nested dependencies sections of lockfile v1
+func addNpmV1Dependencies(packages map[string]PackageInfo, dependencies map[string]npmLockDependency) {
+	for name, dep := range dependencies {
+		version := dep.Version
+		// Aliases such as "string-width-cjs": {"version": "npm:string-width@4.2.3"} install the aliased package
+		if strings.HasPrefix(version, "npm:") {
+			name, version = splitYarnDescriptor(strings.TrimPrefix(version, "npm:"))
+		}
+		if name != "" && version != "" && !yarnProtocolPattern.MatchString(version) && !isNonRegistryResolution(dep.Resolved) {
+			if existing, exists := packages[name]; !exists || isHigherVersion(version, existing.Version) {
+				info := PackageInfo{Version: version, Type: TypePackage, Resolution: npmResolution(dep.Resolved, dep.Integrity)}
+				if dep.Bundled {
+					info.Type = TypeBundled
+				}
+				packages[name] = info
+			}
+		}
+		addNpmV1Dependencies(packages, dep.Dependencies)
+	}
+}
+
+// isNonRegistryResolution reports whether a package was installed from git, a local directory or tarball
+func isNonRegistryResolution(resolved string) bool {
+	return yarnProtocolPattern.MatchString(resolved) && !strings.HasPrefix(resolved, "http://") && !strings.HasPrefix(resolved, "https://")
+}
+
+func npmResolution(resolved, integrity string) map[string]interface{} {
+	var resolution map[string]interface{}
+	if resolved != "" {
+		resolution = setResolutionField(resolution, "tarball", resolved)
+	}
+	if integrity != "" {
+		resolution = setResolutionField(resolution, "integrity", integrity)
+	}
+	return resolution
+}
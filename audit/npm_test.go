@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNpmLock(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "npm", "package-lock.json"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"ansi-styles", "chalk", "lru-cache", "react", "semver", "string-width", "supports-color"}, sortedKeys(tree.Packages))
+	// Of the versions installed at several depths, the highest is kept
+	assert.Equal(t, "4.3.0", tree.Packages["ansi-styles"].Version)
+	assert.Equal(t, ">=8", tree.Packages["ansi-styles"].Engines["node"])
+	assert.Equal(t, "https://registry.npmjs.org/chalk/-/chalk-4.1.2.tgz", tree.Packages["chalk"].Resolution["tarball"])
+	assert.Equal(t, map[string]string{"supports-color": "7.2.0"}, tree.Packages["chalk"].OptionalDependencies)
+	// Dependencies resolve to the versions the package loads, here those of its own node_modules
+	assert.Equal(t, map[string]string{"ansi-styles": "4.3.0", "supports-color": "7.2.0"}, tree.Packages["chalk"].Dependencies)
+	assert.Equal(t, TypeBundled, tree.Packages["lru-cache"].Type)
+	assert.Equal(t, "4.2.3", tree.Packages["string-width"].Version)
+	assert.Equal(t, []string{"scheduler"}, tree.Packages["react"].OptionalPeers)
+	assert.Equal(t, map[string]map[string]string{
+		".":           {"chalk": "4.1.2", "semver": "7.5.4"},
+		"packages/ui": {"react": "18.2.0"},
+	}, tree.Importers)
+}
+
+func TestParseNpmLockV1(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "npm-v1", "package-lock.json"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"ansi-styles", "chalk", "npm-bundled", "string-width"}, sortedKeys(tree.Packages))
+	assert.Equal(t, "4.3.0", tree.Packages["ansi-styles"].Version)
+	assert.Equal(t, TypeBundled, tree.Packages["npm-bundled"].Type)
+	assert.Equal(t, "4.2.3", tree.Packages["string-width"].Version)
+	assert.Nil(t, tree.Importers)
+}
+
+func TestParseNpmLockErrors(t *testing.T) {
+	_, err := parseNpmLockData([]byte(`{"packages": {}}`))
+	assert.EqualError(t, err, "not an npm lock file: missing lockfileVersion")
+
+	_, err = parseNpmLockData([]byte("{\n  \"lockfileVersion\": 3,\n  \"packages\": {\n    \"node_modules/abbrev\": {}\n  }\n}\n"))
+	assert.EqualError(t, err, "none of the 1 installed entries of the packages section have a version: line 4 'node_modules/abbrev'")
+
+	_, err = parseNpmLockData([]byte("lockfileVersion: 3"))
+	assert.Error(t, err)
+}
+
+func FuzzParseNpmLockData(f *testing.F) {
+	for _, path := range []string{filepath.Join("testdata", "npm", "package-lock.json"), filepath.Join("testdata", "npm-v1", "package-lock.json")} {
+		seed, err := os.ReadFile(path)
+		assert.NoError(f, err)
+		f.Add(seed)
+	}
+	f.Add([]byte(`{"lockfileVersion": 3, "packages": {"node_modules/": {"version": "1.0.0"}}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tree, err := parseNpmLockData(data)
+		if err != nil {
+			return
+		}
+		for name, info := range tree.Packages {
+			if name == "" || info.Version == "" {
+				t.Fatalf("package with an empty name or version: '%s' '%s'", name, info.Version)
+			}
+		}
+	})
+}
@@ -0,0 +1,341 @@
+package audit
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Pattern to match the (package@version) peer suffixes of a version string, where the name may be scoped
+var peerSuffixPattern = regexp.MustCompile(`\((@?[^@()]+)@([^()]+)`)
+
+// LockData represents the structure of pnpm-lock.yaml
+type LockData struct {
+	LockfileVersion interface{}                       `yaml:"lockfileVersion"`
+	Importers       map[string]map[string]interface{} `yaml:"importers"`
+
+	// Lockfiles of single projects before v9 record the direct dependencies at the top level
+	Dependencies         map[string]interface{}            `yaml:"dependencies"`
+	DevDependencies      map[string]interface{}            `yaml:"devDependencies"`
+	OptionalDependencies map[string]interface{}            `yaml:"optionalDependencies"`
+	Packages             map[string]map[string]interface{} `yaml:"packages"`
+	Snapshots            map[string]map[string]interface{} `yaml:"snapshots"`
+}
+
+// extractIndirectDependencies reads the peers a version string resolves, e.g. react@18.2.0 and
+// @types/react@18.2.0 of 18.2.0(@types/react@18.2.0)(react@18.2.0), including those of nested suffixes
+func extractIndirectDependencies(versionString string) map[string]string {
+	indirectDeps := make(map[string]string)
+	for _, match := range peerSuffixPattern.FindAllStringSubmatch(versionString, -1) {
+		indirectDeps[match[1]] = match[2]
+	}
+	return indirectDeps
+}
+
+// ParsePackageKey splits a key of the packages section of a pnpm lock file into the package name and version,
+// returning empty strings for keys of another shape
+func ParsePackageKey(packageKey string) (string, string) {
+	if strings.HasPrefix(packageKey, "/") {
+		return parseLegacyPackageKey(strings.TrimPrefix(packageKey, "/"))
+	}
+
+	// Handle scoped packages like '@cypress/listr-verbose-renderer@0.4.1' and regular ones like 'abbrev@1.1.1'.
+	// The version starts at the first @ after the name, as versions with peer suffixes such as
+	// 18.2.0(@types/react@18.2.0) contain more.
+	at := strings.Index(strings.TrimPrefix(packageKey, "@"), "@")
+	if strings.HasPrefix(packageKey, "@") {
+		at++
+	}
+	if at > 0 && at < len(packageKey)-1 {
+		return packageKey[:at], packageKey[at+1:]
+	}
+
+	return "", ""
+}
+
+// parseLegacyPackageKey reads the keys of lockfiles before v9: /name@version(peer@version) in v6, and
+// /name/version_peer@version in v5
+func parseLegacyPackageKey(key string) (string, string) {
+	nameEnd := strings.IndexAny(key, "/@")
+	if strings.HasPrefix(key, "@") {
+		slash := strings.Index(key, "/")
+		if slash < 0 {
+			return "", ""
+		}
+		nameEnd = strings.IndexAny(key[slash+1:], "/@")
+		if nameEnd >= 0 {
+			nameEnd += slash + 1
+		}
+	}
+	if nameEnd <= 0 {
+		return "", ""
+	}
+
+	name, version := key[:nameEnd], key[nameEnd+1:]
+	if key[nameEnd] == '/' {
+		version = strings.SplitN(version, "_", 2)[0]
+	}
+	if version = strings.SplitN(version, "(", 2)[0]; version == "" {
+		return "", ""
+	}
+	return name, version
+}
+
+// pnpmParser parses pnpm-lock.yaml files
+type pnpmParser struct{}
+
+func (pnpmParser) Parse(path string) (*DependencyTree, error) {
+	return parsePnpmLock(path)
+}
+
+func (pnpmParser) ParseData(data []byte) (*DependencyTree, error) {
+	return parsePnpmLockData(data)
+}
+
+func parsePnpmLock(lockFilePath string) (*DependencyTree, error) {
+	// Check if the specified file exists
+	if _, err := os.Stat(lockFilePath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("pnpm-lock.yaml not found at path: %s", lockFilePath)
+	}
+
+	// Read the YAML file
+	data, err := ioutil.ReadFile(lockFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", lockFilePath, err)
+	}
+
+	return parsePnpmLockData(data)
+}
+
+// pnpmLockNodes is LockData with the packages and snapshots sections kept as YAML nodes, which are decoded entry by
+// entry across the cores, as they make up most of the lock files of large monorepos
+type pnpmLockNodes struct {
+	LockfileVersion      interface{}                       `yaml:"lockfileVersion"`
+	Importers            map[string]map[string]interface{} `yaml:"importers"`
+	Dependencies         map[string]interface{}            `yaml:"dependencies"`
+	DevDependencies      map[string]interface{}            `yaml:"devDependencies"`
+	OptionalDependencies map[string]interface{}            `yaml:"optionalDependencies"`
+	Packages             yaml.Node                         `yaml:"packages"`
+	Snapshots            yaml.Node                         `yaml:"snapshots"`
+}
+
+// pnpmPackageEntry is an entry of the packages section, with an empty name when its key can't be read
+type pnpmPackageEntry struct {
+	key  string
+	name string
+	info PackageInfo
+}
+
+// pnpmSnapshotEntry is an entry of the snapshots section, resolving the dependencies of a package version
+type pnpmSnapshotEntry struct {
+	name         string
+	version      string
+	dependencies map[string]string
+	optional     map[string]string
+}
+
+func parsePnpmLockData(data []byte) (*DependencyTree, error) {
+	// Parse YAML using the yaml.v3 library, which resolves anchors, aliases and merge keys
+	var lockData pnpmLockNodes
+	if err := yaml.Unmarshal(data, &lockData); err != nil {
+		return nil, fmt.Errorf("error parsing YAML: %v", err)
+	}
+	if lockData.LockfileVersion == nil {
+		return nil, fmt.Errorf("not a pnpm lock file: missing lockfileVersion")
+	}
+
+	packages, err := decodeYAMLMapping(&lockData.Packages, newPnpmPackageEntry)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing YAML: packages: %v", err)
+	}
+	// Lockfile v9 moved the resolved dependencies of each package to the snapshots section
+	snapshots, err := decodeYAMLMapping(&lockData.Snapshots, newPnpmSnapshotEntry)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing YAML: snapshots: %v", err)
+	}
+
+	allPackages := make(map[string]PackageInfo, len(packages))
+	var invalidKeys []string
+	for _, entry := range packages {
+		if entry.name == "" {
+			invalidKeys = append(invalidKeys, entry.key)
+		} else {
+			allPackages[entry.name] = entry.info
+		}
+	}
+
+	if len(invalidKeys) > 0 {
+		sort.Strings(invalidKeys)
+		keys := describeYAMLKeys(invalidKeys, yamlKeyLines(data, "packages"))
+		if len(allPackages) == 0 {
+			return nil, fmt.Errorf("none of the %d entries of the packages section could be read, expected <name>@<version> keys: %s",
+				len(packages), keys)
+		}
+		log.Warn(fmt.Sprintf("Skipping %d entries of the packages section, expected <name>@<version> keys: %s", len(invalidKeys), keys))
+	}
+
+	for _, snapshot := range snapshots {
+		info, exists := allPackages[snapshot.name]
+		if !exists || info.Version != snapshot.version {
+			continue
+		}
+		if snapshot.optional != nil {
+			info.OptionalDependencies = snapshot.optional
+		}
+		// Snapshots of the peer variants of a package may resolve different dependencies, so they are merged
+		info.Dependencies = mergeStringMaps(info.Dependencies, snapshot.dependencies, snapshot.optional)
+		allPackages[snapshot.name] = info
+	}
+
+	importers := rootImporter(LockData{
+		Importers:            lockData.Importers,
+		Dependencies:         lockData.Dependencies,
+		DevDependencies:      lockData.DevDependencies,
+		OptionalDependencies: lockData.OptionalDependencies,
+	})
+	return &DependencyTree{
+		Packages:        allPackages,
+		Importers:       parseImporters(importers),
+		DevDependencies: parseDevDependencies(importers),
+	}, nil
+}
+
+func newPnpmPackageEntry(packageKey string, packageInfo map[string]interface{}) pnpmPackageEntry {
+	packageName, version := ParsePackageKey(packageKey)
+	if packageName == "" || version == "" {
+		return pnpmPackageEntry{key: packageKey}
+	}
+	info := PackageInfo{
+		Version: version,
+		Type:    TypePackage,
+	}
+
+	// Extract resolution and engines if they exist
+	if resolution, exists := packageInfo["resolution"]; exists {
+		if resMap, ok := resolution.(map[string]interface{}); ok {
+			info.Resolution = resMap
+		}
+	}
+	if engines, exists := packageInfo["engines"]; exists {
+		if engMap, ok := engines.(map[string]interface{}); ok {
+			info.Engines = engMap
+		}
+	}
+
+	info.OptionalDependencies = toStringMap(packageInfo["optionalDependencies"])
+	// Lockfiles before v9 record the resolved dependencies with the packages
+	info.Dependencies = mergeStringMaps(info.Dependencies, toStringMap(packageInfo["dependencies"]), info.OptionalDependencies)
+	info.PeerDependencies = toStringMap(packageInfo["peerDependencies"])
+	if meta, ok := packageInfo["peerDependenciesMeta"].(map[string]interface{}); ok {
+		for peer, value := range meta {
+			if fields, ok := value.(map[string]interface{}); ok && fields["optional"] == true {
+				info.OptionalPeers = append(info.OptionalPeers, peer)
+			}
+		}
+		sort.Strings(info.OptionalPeers)
+	}
+	return pnpmPackageEntry{key: packageKey, name: packageName, info: info}
+}
+
+func newPnpmSnapshotEntry(snapshotKey string, snapshot map[string]interface{}) pnpmSnapshotEntry {
+	packageName, version := ParsePackageKey(snapshotKey)
+	return pnpmSnapshotEntry{
+		name:         packageName,
+		version:      strings.SplitN(version, "(", 2)[0],
+		dependencies: toStringMap(snapshot["dependencies"]),
+		optional:     toStringMap(snapshot["optionalDependencies"]),
+	}
+}
+
+func rootImporter(lockData LockData) map[string]map[string]interface{} {
+	if len(lockData.Importers) > 0 || (lockData.Dependencies == nil && lockData.DevDependencies == nil && lockData.OptionalDependencies == nil) {
+		return lockData.Importers
+	}
+	return map[string]map[string]interface{}{
+		".": {
+			"dependencies":         lockData.Dependencies,
+			"devDependencies":      lockData.DevDependencies,
+			"optionalDependencies": lockData.OptionalDependencies,
+		},
+	}
+}
+
+// parseImporters reads the direct dependencies of each project, recorded either as {specifier, version}
+// mappings (lockfile v6 and later) or as plain versions (lockfile v5)
+func parseImporters(importers map[string]map[string]interface{}) map[string]map[string]string {
+	if len(importers) == 0 {
+		return nil
+	}
+	result := make(map[string]map[string]string, len(importers))
+	for importer, sections := range importers {
+		direct := make(map[string]string)
+		for _, section := range []string{"dependencies", "devDependencies", "optionalDependencies"} {
+			deps, ok := sections[section].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for name, value := range deps {
+				if fields, ok := value.(map[string]interface{}); ok {
+					value = fields["version"]
+				}
+				if value != nil {
+					direct[name] = fmt.Sprint(value)
+				}
+			}
+		}
+		result[importer] = direct
+	}
+	return result
+}
+
+// parseDevDependencies lists the devDependencies of each project, leaving out those it also lists as dependencies
+func parseDevDependencies(importers map[string]map[string]interface{}) map[string][]string {
+	var result map[string][]string
+	for importer, sections := range importers {
+		dev, _ := sections["devDependencies"].(map[string]interface{})
+		prod, _ := sections["dependencies"].(map[string]interface{})
+		for name := range dev {
+			if _, exists := prod[name]; exists {
+				continue
+			}
+			if result == nil {
+				result = make(map[string][]string)
+			}
+			result[importer] = append(result[importer], name)
+		}
+		sort.Strings(result[importer])
+	}
+	return result
+}
+
+// toStringMap converts a YAML mapping of names to versions, dropping peer suffixes from the versions
+func toStringMap(value interface{}) map[string]string {
+	mapping, ok := value.(map[string]interface{})
+	if !ok || len(mapping) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(mapping))
+	for key, item := range mapping {
+		result[key] = strings.SplitN(fmt.Sprint(item), "(", 2)[0]
+	}
+	return result
+}
+
+// mergeStringMaps merges mappings into the first, which is allocated when needed. It stays nil when all are empty.
+func mergeStringMaps(into map[string]string, mappings ...map[string]string) map[string]string {
+	for _, mapping := range mappings {
+		for key, value := range mapping {
+			if into == nil {
+				into = make(map[string]string)
+			}
+			into[key] = value
+		}
+	}
+	return into
+}
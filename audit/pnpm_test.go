@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePackageKey(t *testing.T) {
+	name, version := ParsePackageKey("abbrev@1.1.1")
+	assert.Equal(t, "abbrev", name)
+	assert.Equal(t, "1.1.1", version)
+
+	name, version = ParsePackageKey("@cypress/listr-verbose-renderer@0.4.1")
+	assert.Equal(t, "@cypress/listr-verbose-renderer", name)
+	assert.Equal(t, "0.4.1", version)
+}
+
+func TestParsePnpmLock(t *testing.T) {
+	tree, err := parsePnpmLock(filepath.Join("testdata", "pnpm-lock.yaml"))
+	assert.NoError(t, err)
+	assert.Len(t, tree.Packages, 2)
+	assert.Equal(t, "3.1.4", tree.Packages["@types/keyv"].Version)
+	assert.Equal(t, ">=4", tree.Packages["abbrev"].Engines["node"])
+}
+
+func FuzzParsePackageKey(f *testing.F) {
+	for _, key := range []string{"abbrev@1.1.1", "@cypress/listr-verbose-renderer@0.4.1", "/react-dom/17.0.2_react@17.0.2", "/@babel/core@7.0.0(react@18.2.0)", "@", "/"} {
+		f.Add(key)
+	}
+	f.Fuzz(func(t *testing.T, key string) {
+		name, version := ParsePackageKey(key)
+		if (name == "") != (version == "") {
+			t.Fatalf("'%s' parsed to a partial key: '%s' '%s'", key, name, version)
+		}
+	})
+}
+
+func TestParsePnpmLockAnchorsAndMergeKeys(t *testing.T) {
+	tree, err := parsePnpmLockData([]byte(`
+lockfileVersion: 5.3
+x-node: &node
+  node: '>=10'
+packages:
+  /abbrev/1.1.1:
+    resolution: &abbrev {integrity: sha512-abc}
+    engines: *node
+  /yallist/4.0.0:
+    <<: &base
+      dev: false
+      engines: {node: '>=12'}
+    resolution: {integrity: sha512-def}
+  /semver/7.5.4:
+    <<: *base
+    resolution: *abbrev
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"abbrev", "semver", "yallist"}, sortedKeys(tree.Packages))
+	assert.Equal(t, ">=10", tree.Packages["abbrev"].Engines["node"])
+	assert.Equal(t, ">=12", tree.Packages["semver"].Engines["node"])
+	assert.Equal(t, "sha512-abc", tree.Packages["semver"].Resolution["integrity"])
+}
+
+func TestParseLegacyPackageKeys(t *testing.T) {
+	for key, expected := range map[string][2]string{
+		"/abbrev/1.1.1":                      {"abbrev", "1.1.1"},
+		"/@babel/core/7.0.0":                 {"@babel/core", "7.0.0"},
+		"/react-dom/17.0.2_react@17.0.2":     {"react-dom", "17.0.2"},
+		"/abbrev@1.1.1":                      {"abbrev", "1.1.1"},
+		"/@babel/core@7.0.0":                 {"@babel/core", "7.0.0"},
+		"/react-dom@18.2.0(react@18.2.0)":    {"react-dom", "18.2.0"},
+		"/@scope":                            {"", ""},
+		"/abbrev/":                           {"", ""},
+		"/abbrev/_react@17.0.2":              {"", ""},
+		"/0/(":                               {"", ""},
+		"/@types/react-dom@18.2.0(react@18)": {"@types/react-dom", "18.2.0"},
+	} {
+		name, version := ParsePackageKey(key)
+		assert.Equal(t, expected, [2]string{name, version}, key)
+	}
+}
+
+func TestParsePnpmLockErrors(t *testing.T) {
+	_, err := parsePnpmLockData([]byte("packages:\n  abbrev@1.1.1: {}\n"))
+	assert.EqualError(t, err, "not a pnpm lock file: missing lockfileVersion")
+
+	_, err = parsePnpmLockData([]byte("lockfileVersion: '9.0'\npackages:\n  abbrev: {}\n  yallist: {}\n"))
+	assert.EqualError(t, err, "none of the 2 entries of the packages section could be read, expected <name>@<version> keys: line 3 'abbrev', line 4 'yallist'")
+
+	tree, err := parsePnpmLockData([]byte("lockfileVersion: '9.0'\npackages:\n  abbrev@1.1.1: {}\n  yallist: {}\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"abbrev"}, sortedKeys(tree.Packages))
+
+	_, err = parsePnpmLockData([]byte("lockfileVersion: '9.0'\npackages: [abbrev]\n"))
+	assert.Error(t, err)
+}
+
+func FuzzParsePnpmLockData(f *testing.F) {
+	seed, err := os.ReadFile(filepath.Join("testdata", "pnpm-lock.yaml"))
+	assert.NoError(f, err)
+	f.Add(seed)
+	f.Add([]byte("lockfileVersion: 5.3\npackages:\n  /abbrev/1.1.1:\n    <<: &base {dev: false}\n  /yallist/4.0.0: *base\n"))
+	f.Add([]byte("lockfileVersion: '6.0'\npackages:\n  /@babel/core@7.0.0(react@18.2.0): {}\n"))
+	f.Add([]byte("a: &a [*a]\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tree, err := parsePnpmLockData(data)
+		if err != nil {
+			return
+		}
+		for name, info := range tree.Packages {
+			if name == "" || info.Version == "" {
+				t.Fatalf("package with an empty name or version: '%s' '%s'", name, info.Version)
+			}
+		}
+	})
+}
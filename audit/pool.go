@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// RunPool runs task for every item on at most workers goroutines and waits for them. Tasks are independent: an
+// error, or a panic recovered into one, is handed to onError and doesn't stop the other tasks, so a single
+// malformed package can't take down a whole audit. Once the context is done, the items left aren't run and are
+// handed to onError with the error of the context.
+func RunPool[T any](ctx context.Context, items []T, workers int, task func(T) error, onError func(T, error)) {
+	if workers < 1 {
+		workers = 1
+	}
+	var group errgroup.Group
+	group.SetLimit(workers)
+	for _, item := range items {
+		group.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				onError(item, err)
+				return nil
+			}
+			if err := runTask(item, task); err != nil {
+				onError(item, err)
+			}
+			return nil
+		})
+	}
+	group.Wait()
+}
+
+// runTask calls task, converting a panic into an error
+func runTask[T any](item T, task func(T) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Debug(fmt.Sprintf("Recovered from panic: %v\n%s", r, debug.Stack()))
+			err = fmt.Errorf("internal error: %v", r)
+		}
+	}()
+	return task(item)
+}
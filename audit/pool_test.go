@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPoolContainsPanics(t *testing.T) {
+	var mu sync.Mutex
+	var done []int
+	failed := make(map[int]string)
+	RunPool(context.Background(), []int{1, 2, 3, 4}, 2, func(i int) error {
+		switch i {
+		case 2:
+			var tree *DependencyTree
+			_ = tree.Packages["lodash"]
+		case 3:
+			return errors.New("registry unavailable")
+		}
+		mu.Lock()
+		done = append(done, i)
+		mu.Unlock()
+		return nil
+	}, func(i int, err error) {
+		mu.Lock()
+		failed[i] = err.Error()
+		mu.Unlock()
+	})
+
+	sort.Ints(done)
+	assert.Equal(t, []int{1, 4}, done)
+	assert.Contains(t, failed[2], "internal error: runtime error: invalid memory address")
+	assert.Equal(t, "registry unavailable", failed[3])
+}
+
+func TestRunPoolLimitsConcurrency(t *testing.T) {
+	var running, peak int32
+	RunPool(context.Background(), make([]int, 20), 3, func(int) error {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			seen := atomic.LoadInt32(&peak)
+			if current <= seen || atomic.CompareAndSwapInt32(&peak, seen, current) {
+				break
+			}
+		}
+		atomic.AddInt32(&running, -1)
+		return nil
+	}, func(int, error) {})
+	assert.LessOrEqual(t, peak, int32(3))
+}
@@ -0,0 +1,274 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	poetryLockFileName   = "poetry.lock"
+	requirementsFileName = "requirements.txt"
+
+	// Upper bound of the simple index pages read
+	maxIndexPageSize = 16 << 20
+)
+
+// Package managers of the PyPI ecosystem
+const (
+	PackageManagerPoetry = "poetry"
+	PackageManagerPip    = "pip"
+)
+
+var (
+	// Runs of the characters PEP 503 normalizes project names on
+	pypiSeparatorPattern = regexp.MustCompile(`[-_.]+`)
+	// Matches a requirement pinned with == or ===, with optional extras and environment markers
+	pinnedRequirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*===?\s*([^\s;,]+)\s*(?:;.*)?$`)
+	// Matches the file links of a PEP 503 simple index page
+	indexLinkPattern = regexp.MustCompile(`(?is)<a\s[^>]*href\s*=\s*"([^"]+)"[^>]*>([^<]+)</a>`)
+	// Extensions of the source distributions, of which the version is the end of the name
+	sdistExtensions = []string{".tar.gz", ".tar.bz2", ".tar.xz", ".zip", ".tgz"}
+)
+
+// NormalizePyPIName normalizes a project name as PEP 503 does, e.g. Zope.Interface becomes zope-interface
+func NormalizePyPIName(name string) string {
+	return strings.ToLower(pypiSeparatorPattern.ReplaceAllString(name, "-"))
+}
+
+// PyPIIndexURL returns the simple index page of a project, e.g.
+// https://acme.jfrog.io/artifactory/api/pypi/pypi-remote/simple/requests/
+func PyPIIndexURL(simpleIndexBaseURL, name string) string {
+	return strings.TrimSuffix(simpleIndexBaseURL, "/") + "/" + EscapePathSegment(NormalizePyPIName(name)) + "/"
+}
+
+// parsePoetryLockData reads the [[package]] tables of a poetry.lock. Packages of git, directory, file or URL
+// sources aren't installed from an index, so they are skipped.
+func parsePoetryLockData(data []byte) (*DependencyTree, error) {
+	tree := &DependencyTree{Packages: make(map[string]PackageInfo)}
+	type poetryPackage struct {
+		name, version, sourceType string
+		line                      int
+	}
+	var packages []poetryPackage
+	var current *poetryPackage
+	table := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	inArray := false
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Skip the lines of multi-line arrays, such as the files of a package
+		if inArray {
+			inArray = !strings.HasPrefix(line, "]")
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(line, "[] ")
+			if line == "[[package]]" {
+				packages = append(packages, poetryPackage{line: lineNumber})
+				current = &packages[len(packages)-1]
+			}
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("poetry.lock line %d: expected key = value, got '%s'", lineNumber, line)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if value == "[" || (strings.HasPrefix(value, "[") && !strings.HasSuffix(value, "]")) {
+			inArray = true
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch {
+		case table == "package" && key == "name":
+			current.name = strings.Trim(value, `"'`)
+		case table == "package" && key == "version":
+			current.version = strings.Trim(value, `"'`)
+		case table == "package.source" && key == "type":
+			current.sourceType = strings.Trim(value, `"'`)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading poetry.lock: %v", err)
+	}
+
+	for _, pkg := range packages {
+		if pkg.name == "" || pkg.version == "" {
+			return nil, fmt.Errorf("poetry.lock line %d: package without a name or version", pkg.line)
+		}
+		if pkg.sourceType != "" && pkg.sourceType != "legacy" {
+			log.Debug(fmt.Sprintf("Skipping %s %s, installed from a %s source", pkg.name, pkg.version, pkg.sourceType))
+			continue
+		}
+		tree.Packages[NormalizePyPIName(pkg.name)] = PackageInfo{Version: pkg.version, Type: TypePackage}
+	}
+	return tree, nil
+}
+
+// parseRequirementsData reads the requirements pinned with == of a requirements.txt, as written by pip freeze or
+// pip-compile. Requirements without a pinned version can't be audited, so they are skipped with a warning.
+func parseRequirementsData(data []byte) (*DependencyTree, error) {
+	tree := &DependencyTree{Packages: make(map[string]PackageInfo)}
+	var unpinned []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber, requirementLine := 0, 0
+	var requirement strings.Builder
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if requirement.Len() == 0 {
+			requirementLine = lineNumber
+		}
+		// Continuations, such as those of the --hash options of pip-compile
+		if strings.HasSuffix(line, `\`) {
+			requirement.WriteString(strings.TrimSuffix(line, `\`) + " ")
+			continue
+		}
+		requirement.WriteString(line)
+		text := requirement.String()
+		requirement.Reset()
+
+		if comment := strings.Index(text, " #"); comment >= 0 {
+			text = text[:comment]
+		}
+		text = strings.TrimSpace(text)
+		// Options, such as -r, -c, -e or --index-url, and comments
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "-") {
+			continue
+		}
+		if options := strings.Index(text, " --"); options >= 0 {
+			text = strings.TrimSpace(text[:options])
+		}
+		match := pinnedRequirementPattern.FindStringSubmatch(text)
+		if match == nil {
+			unpinned = append(unpinned, fmt.Sprintf("line %d '%s'", requirementLine, text))
+			continue
+		}
+		tree.Packages[NormalizePyPIName(match[1])] = PackageInfo{Version: match[2], Type: TypePackage}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading requirements.txt: %v", err)
+	}
+
+	if len(unpinned) > 0 {
+		described := unpinned
+		if len(described) > maxDescribedKeys {
+			described = append(described[:maxDescribedKeys:maxDescribedKeys], fmt.Sprintf("and %d more", len(unpinned)-maxDescribedKeys))
+		}
+		if len(tree.Packages) == 0 {
+			return nil, fmt.Errorf("none of the %d requirements are pinned with ==: %s", len(unpinned), strings.Join(described, ", "))
+		}
+		log.Warn(fmt.Sprintf("Skipping %d requirements not pinned with ==: %s", len(unpinned), strings.Join(described, ", ")))
+	}
+	return tree, nil
+}
+
+// checkPyPI audits a dependency against a curated PyPI registry: it finds the files of the version on the
+// simple index page of the project, and requests the first one, which curation blocks like npm tarballs
+func (registry *Registry) checkPyPI(ctx context.Context, dep Dependency) AuditResult {
+	result := AuditResult{Name: dep.Name, Version: dep.Version, Type: dep.Type}
+	client := registry.client()
+	indexURL := PyPIIndexURL(registry.URL, dep.Name)
+
+	resp, err := requestTarball(ctx, client, http.MethodGet, indexURL, registry.credentials())
+	var redirect *RedirectError
+	if errors.As(err, &redirect) {
+		result.Status, result.StatusCode = redirect.Status(), redirect.StatusCode
+		return result
+	}
+	if err != nil {
+		result.Status, result.Error = "❌ Request Failed", err
+		return result
+	}
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxIndexPageSize))
+	resp.Body.Close()
+	if err != nil {
+		result.Status, result.Error = "❌ Request Failed", err
+		return result
+	}
+	if resp.StatusCode != http.StatusOK {
+		result.Status, result.StatusCode, result.RetryAfter = StatusForCode(resp.StatusCode), resp.StatusCode, retryAfter(resp)
+		return result
+	}
+
+	fileURL, found := pypiFileURL(indexURL, page, dep.Name, dep.Version)
+	if !found {
+		result.Status, result.StatusCode = "❌ Not Found (404): no files of the version on the index", http.StatusNotFound
+		return result
+	}
+	result = registry.checkTarball(ctx, dep, fileURL, registry.credentials())
+	if result.StatusCode == http.StatusOK {
+		result.Status = "✅ Available in PyPI Registry"
+	}
+	return result
+}
+
+// pypiFileURL returns the URL of the first file of a version linked by a simple index page, resolved against
+// the page URL
+func pypiFileURL(indexURL string, page []byte, name, version string) (string, bool) {
+	base, err := url.Parse(indexURL)
+	if err != nil {
+		return "", false
+	}
+	for _, match := range indexLinkPattern.FindAllSubmatch(page, -1) {
+		fileName := strings.TrimSpace(html.UnescapeString(string(match[2])))
+		if !isPyPIFileOf(fileName, name, version) {
+			continue
+		}
+		link, err := base.Parse(html.UnescapeString(string(match[1])))
+		if err != nil {
+			continue
+		}
+		// The fragment holds the file hash, which isn't part of the request
+		link.Fragment = ""
+		return link.String(), true
+	}
+	return "", false
+}
+
+// isPyPIFileOf reports whether a wheel (<name>-<version>-<tags>.whl) or a source distribution
+// (<name>-<version>.tar.gz) is a file of a project version
+func isPyPIFileOf(fileName, name, version string) bool {
+	var fileProject, fileVersion string
+	if stem, isWheel := strings.CutSuffix(fileName, ".whl"); isWheel {
+		parts := strings.Split(stem, "-")
+		if len(parts) < 3 {
+			return false
+		}
+		fileProject, fileVersion = parts[0], parts[1]
+	} else {
+		for _, extension := range sdistExtensions {
+			if stem, isSdist := strings.CutSuffix(fileName, extension); isSdist {
+				separator := strings.LastIndex(stem, "-")
+				if separator < 0 {
+					return false
+				}
+				fileProject, fileVersion = stem[:separator], stem[separator+1:]
+				break
+			}
+		}
+	}
+	return fileProject != "" && NormalizePyPIName(fileProject) == NormalizePyPIName(name) && strings.EqualFold(fileVersion, version)
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePoetryLock(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "poetry", "poetry.lock"))
+	assert.NoError(t, err)
+
+	// Packages of git sources are skipped, and names are normalized
+	assert.Equal(t, []string{"certifi", "charset-normalizer", "requests", "urllib3"}, sortedKeys(tree.Packages))
+	assert.Equal(t, "2024.2.2", tree.Packages["certifi"].Version)
+	assert.Equal(t, "3.3.2", tree.Packages["charset-normalizer"].Version)
+	assert.Equal(t, TypePackage, tree.Packages["requests"].Type)
+	assert.Equal(t, PackageManagerPoetry, PackageManagerFor(filepath.Join("app", "poetry.lock")))
+	assert.Equal(t, EcosystemPyPI, EcosystemOf(PackageManagerPoetry))
+}
+
+func TestParsePoetryLockErrors(t *testing.T) {
+	_, err := parsePoetryLockData([]byte("[[package]]\nname\n"))
+	assert.EqualError(t, err, "poetry.lock line 2: expected key = value, got 'name'")
+
+	_, err = parsePoetryLockData([]byte("[[package]]\nname = \"requests\"\n"))
+	assert.EqualError(t, err, "poetry.lock line 1: package without a name or version")
+}
+
+func TestParseRequirements(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "pip", "requirements.txt"))
+	assert.NoError(t, err)
+
+	// flask isn't pinned, and the editable requirement is an option
+	assert.Equal(t, []string{"certifi", "requests", "urllib3", "zope-interface"}, sortedKeys(tree.Packages))
+	assert.Equal(t, "2024.2.2", tree.Packages["certifi"].Version)
+	assert.Equal(t, "2.31.0", tree.Packages["requests"].Version)
+	assert.Equal(t, "2.2.1", tree.Packages["urllib3"].Version)
+	assert.Equal(t, "6.2", tree.Packages["zope-interface"].Version)
+	assert.Equal(t, EcosystemPyPI, EcosystemOf(PackageManagerFor("requirements.txt")))
+}
+
+func TestParseRequirementsErrors(t *testing.T) {
+	_, err := parseRequirementsData([]byte("flask>=3.0\nrequests\n"))
+	assert.EqualError(t, err, "none of the 2 requirements are pinned with ==: line 1 'flask>=3.0', line 2 'requests'")
+}
+
+func TestNormalizePyPIName(t *testing.T) {
+	assert.Equal(t, "zope-interface", NormalizePyPIName("Zope.Interface"))
+	assert.Equal(t, "charset-normalizer", NormalizePyPIName("charset__normalizer"))
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/api/pypi/pypi-remote/simple/zope-interface/",
+		PyPIIndexURL("https://acme.jfrog.io/artifactory/api/pypi/pypi-remote/simple/", "Zope_Interface"))
+}
+
+func TestIsPyPIFileOf(t *testing.T) {
+	assert.True(t, isPyPIFileOf("requests-2.31.0-py3-none-any.whl", "requests", "2.31.0"))
+	assert.True(t, isPyPIFileOf("zope.interface-6.2.tar.gz", "Zope.Interface", "6.2"))
+	assert.True(t, isPyPIFileOf("charset_normalizer-3.3.2-cp312-cp312-manylinux_2_17_x86_64.whl", "charset-normalizer", "3.3.2"))
+	assert.False(t, isPyPIFileOf("requests-2.31.0.tar.gz", "requests", "2.3"))
+	assert.False(t, isPyPIFileOf("requests-toolbelt-1.0.0.tar.gz", "requests", "1.0.0"))
+	assert.False(t, isPyPIFileOf("requests-2.31.0.exe", "requests", "2.31.0"))
+}
+
+func TestCheckPyPI(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		switch r.URL.Path {
+		case "/simple/requests/":
+			w.Write([]byte(`<html><body>
+<a href="../../packages/requests-2.30.0.tar.gz#sha256=abc">requests-2.30.0.tar.gz</a>
+<a href="../../packages/requests-2.31.0-py3-none-any.whl#sha256=def" data-requires-python="&gt;=3.7">requests-2.31.0-py3-none-any.whl</a>
+</body></html>`))
+		case "/packages/requests-2.31.0-py3-none-any.whl":
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		case "/simple/urllib3/":
+			w.Write([]byte(`<a href="/packages/urllib3-2.2.1.tar.gz">urllib3-2.2.1.tar.gz</a>`))
+		case "/packages/urllib3-2.2.1.tar.gz":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	registry := &Registry{URL: server.URL + "/simple", AccessToken: "token", Ecosystem: EcosystemPyPI}
+
+	result := registry.Check(Dependency{Name: "Requests", Version: "2.31.0", Type: TypePackage})
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, "✅ Available in PyPI Registry", result.Status)
+	assert.Contains(t, requested, "/packages/requests-2.31.0-py3-none-any.whl")
+
+	result = registry.Check(Dependency{Name: "urllib3", Version: "2.2.1", Type: TypePackage})
+	assert.Equal(t, http.StatusForbidden, result.StatusCode)
+
+	result = registry.Check(Dependency{Name: "requests", Version: "9.9.9", Type: TypePackage})
+	assert.Equal(t, http.StatusNotFound, result.StatusCode)
+	assert.Equal(t, "❌ Not Found (404): no files of the version on the index", result.Status)
+
+	result = registry.Check(Dependency{Name: "missing", Version: "1.0.0", Type: TypePackage})
+	assert.Equal(t, http.StatusNotFound, result.StatusCode)
+}
@@ -0,0 +1,336 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Registry is a curated npm registry the packages are audited against
+type Registry struct {
+	// URL is the base URL of the registry, e.g. https://acme.jfrog.io/artifactory/api/npm/npm-remote
+	URL         string
+	AccessToken string
+
+	// User and Password authenticate with basic authentication when there is no AccessToken. The password may be
+	// an API key.
+	User     string
+	Password string
+
+	// MirrorHosts are the hosts of pinned tarballs the credentials are sent to. *.example.com matches subdomains.
+	MirrorHosts []string
+
+	// Scopes are the registries the npm packages of a scope, such as @acme, are checked against instead, as an
+	// .npmrc routes their installs
+	Scopes map[string]*Registry
+
+	// CurationAPI checks packages with the curation audit API of Artifactory instead of requesting their tarballs,
+	// which tells the policies blocking a package, and packages not cached yet, apart
+	CurationAPI bool
+
+	// Ecosystem of the registry, EcosystemNpm, EcosystemPyPI, EcosystemGo, EcosystemMaven or EcosystemComposer.
+	// Defaults to npm.
+	Ecosystem string
+
+	// Ecosystems are the registries the packages of other ecosystems, such as those of an SBOM, are checked against
+	Ecosystems map[string]*Registry
+
+	// Retry configures the retries of checks failing with a transient error. Defaults to a single attempt.
+	Retry RetryPolicy
+
+	// Client sends the tarball requests. Its CheckRedirect may return a *RedirectError to report a redirect as
+	// the outcome of the check. Defaults to a client with a 30 second timeout.
+	Client *http.Client
+}
+
+// RedirectError represents a registry redirect that wasn't followed
+type RedirectError struct {
+	StatusCode int
+	Location   string
+	Unexpected bool
+}
+
+func (e *RedirectError) Error() string {
+	if e.Unexpected {
+		return fmt.Sprintf("unexpected redirect (%d) to %s", e.StatusCode, e.Location)
+	}
+	return fmt.Sprintf("redirect (%d) to %s not followed", e.StatusCode, e.Location)
+}
+
+// Status describes the redirect as an audit finding
+func (e *RedirectError) Status() string {
+	host := HostOf(e.Location)
+	if host == "" {
+		host = e.Location
+	}
+	if e.Unexpected {
+		return fmt.Sprintf("⚠️ Unexpected Redirect (%d) to %s", e.StatusCode, host)
+	}
+	return fmt.Sprintf("⚠️ Redirect (%d) to %s not followed", e.StatusCode, host)
+}
+
+// Credentials authenticate the requests to a registry. An access token, whether an identity or a reference token,
+// is sent as a bearer token, or else the user and password, which may be an API key, with basic authentication.
+type Credentials struct {
+	AccessToken string
+	User        string
+	Password    string
+}
+
+// Authorize sets the authorization header of a request, unless there are no credentials
+func (credentials Credentials) Authorize(req *http.Request) {
+	if credentials.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+credentials.AccessToken)
+	} else if credentials.User != "" || credentials.Password != "" {
+		req.SetBasicAuth(credentials.User, credentials.Password)
+	}
+}
+
+func (registry *Registry) credentials() Credentials {
+	return Credentials{AccessToken: registry.AccessToken, User: registry.User, Password: registry.Password}
+}
+
+// StatusTimedOut is the status of the checks that didn't complete in time, which tells a slow registry or proxy
+// apart from a failing one
+const StatusTimedOut = "⏱️ Timed Out"
+
+// Check audits a dependency against the registry, or against its pinned tarball. The credentials are only sent
+// to the mirror hosts. Transient errors are retried as the Retry policy configures.
+func (registry *Registry) Check(dep Dependency) AuditResult {
+	return registry.CheckContext(context.Background(), dep)
+}
+
+// CheckContext is like Check, aborting the requests in flight and the retries left once the context is done.
+// Checks that time out, whether a request or the context, yield a StatusTimedOut result.
+func (registry *Registry) CheckContext(ctx context.Context, dep Dependency) AuditResult {
+	if ecosystem := dep.Ecosystem; ecosystem != "" && ecosystem != registry.ecosystem() {
+		routed := registry.Ecosystems[ecosystem]
+		if routed == nil || routed.ecosystem() != ecosystem {
+			return AuditResult{
+				Name:    dep.Name,
+				Version: dep.Version,
+				Type:    dep.Type,
+				Status:  "❌ No " + ecosystem + " registry",
+				Error:   fmt.Errorf("no registry to check the %s packages against", ecosystem),
+			}
+		}
+		return routed.CheckContext(ctx, dep)
+	}
+	if scope, scoped := registry.scopeRegistry(dep); scoped != nil {
+		result := scoped.CheckContext(ctx, dep)
+		result.Status += fmt.Sprintf(" (%s registry)", scope)
+		return result
+	}
+	result := registry.Retry.withRetries(ctx, func() AuditResult {
+		return registry.check(ctx, dep)
+	})
+	if result.Error != nil && IsTimeout(result.Error) {
+		result.Status = StatusTimedOut
+	}
+	return result
+}
+
+// ecosystem returns the ecosystem of the registry, npm by default
+func (registry *Registry) ecosystem() string {
+	if registry.Ecosystem == "" {
+		return EcosystemNpm
+	}
+	return registry.Ecosystem
+}
+
+// scopeRegistry returns the scope of a package and the registry of its Scopes it is checked against, if any. Pinned
+// tarballs are checked against their host whatever the scope.
+func (registry *Registry) scopeRegistry(dep Dependency) (string, *Registry) {
+	if len(registry.Scopes) == 0 || dep.Tarball != "" || !strings.HasPrefix(dep.Name, "@") {
+		return "", nil
+	}
+	if registry.Ecosystem != "" && registry.Ecosystem != EcosystemNpm {
+		return "", nil
+	}
+	scope, _, _ := strings.Cut(dep.Name, "/")
+	return scope, registry.Scopes[scope]
+}
+
+func (registry *Registry) check(ctx context.Context, dep Dependency) AuditResult {
+	// Names and versions that break the rules of the ecosystem aren't sent to the registry
+	if err := ValidateDependency(registry.Ecosystem, dep); err != nil {
+		return AuditResult{Name: dep.Name, Version: dep.Version, Type: dep.Type, Status: "❌ Invalid package", Error: err}
+	}
+	if dep.Tarball != "" {
+		host := strings.ToLower(HostOf(dep.Tarball))
+		var credentials Credentials
+		if MatchesHost(host, registry.MirrorHosts) {
+			credentials = registry.credentials()
+		}
+		result := registry.checkTarball(ctx, dep, dep.Tarball, credentials)
+		result.Status += fmt.Sprintf(" (pinned to %s)", host)
+		return result
+	}
+
+	switch registry.Ecosystem {
+	case EcosystemPyPI:
+		return registry.checkPyPI(ctx, dep)
+	case EcosystemGo:
+		return registry.checkGo(ctx, dep)
+	case EcosystemMaven:
+		return registry.checkMaven(ctx, dep)
+	case EcosystemComposer:
+		return registry.checkComposer(ctx, dep)
+	}
+	if registry.CurationAPI {
+		return registry.checkCuration(ctx, dep)
+	}
+
+	packageURL, err := TarballURL(registry.URL, dep.Name, dep.Version)
+	if err != nil {
+		return AuditResult{
+			Name:    dep.Name,
+			Version: dep.Version,
+			Type:    dep.Type,
+			Status:  "❌ Invalid scoped package format",
+			Error:   err,
+		}
+	}
+	return registry.checkTarball(ctx, dep, packageURL, registry.credentials())
+}
+
+// checkTarball requests a tarball and describes the curation outcome of its response. Only the headers are
+// requested, with a GET fallback for registries that don't allow HEAD requests.
+func (registry *Registry) checkTarball(ctx context.Context, dep Dependency, packageURL string, credentials Credentials) AuditResult {
+	client := registry.client()
+
+	resp, err := requestTarball(ctx, client, http.MethodHead, packageURL, credentials)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = requestTarball(ctx, client, http.MethodGet, packageURL, credentials)
+	}
+	var redirect *RedirectError
+	if errors.As(err, &redirect) {
+		return AuditResult{
+			Name:       dep.Name,
+			Version:    dep.Version,
+			Type:       dep.Type,
+			Status:     redirect.Status(),
+			StatusCode: redirect.StatusCode,
+		}
+	}
+	if err != nil {
+		return AuditResult{
+			Name:    dep.Name,
+			Version: dep.Version,
+			Type:    dep.Type,
+			Status:  "❌ Request Failed",
+			Error:   err,
+		}
+	}
+	defer resp.Body.Close()
+
+	if IsRedirect(resp.StatusCode) {
+		// Redirects the client didn't follow
+		redirect := &RedirectError{StatusCode: resp.StatusCode, Location: resp.Header.Get("Location")}
+		return AuditResult{
+			Name:       dep.Name,
+			Version:    dep.Version,
+			Type:       dep.Type,
+			Status:     redirect.Status(),
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	return AuditResult{
+		Name:       dep.Name,
+		Version:    dep.Version,
+		Type:       dep.Type,
+		Status:     StatusForCode(resp.StatusCode),
+		StatusCode: resp.StatusCode,
+		RetryAfter: retryAfter(resp),
+	}
+}
+
+// defaultClient checks the packages of the registries without a Client. It is shared, so concurrent checks reuse
+// their connections.
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+func (registry *Registry) client() *http.Client {
+	if registry.Client == nil {
+		return defaultClient
+	}
+	return registry.Client
+}
+
+func requestTarball(ctx context.Context, client *http.Client, method, packageURL string, credentials Credentials) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, packageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	credentials.Authorize(req)
+	return client.Do(req)
+}
+
+// TarballURL builds the tarball URL of a package version in an npm registry, with the name and version
+// percent-encoded
+func TarballURL(npmRegistryBaseURL, packageName, packageVersion string) (string, error) {
+	scope, name, err := npmNameSegments(packageName)
+	if err != nil {
+		return "", err
+	}
+	if !isPathSegment(packageVersion) {
+		return "", fmt.Errorf("invalid version '%s' of %s", packageVersion, packageName)
+	}
+	tarball := EscapePathSegment(name + "-" + packageVersion + ".tgz")
+	// For scoped packages: @scope/package -> @scope/package/-/package-version.tgz
+	if scope != "" {
+		return fmt.Sprintf("%s/@%s/%s/-/%s", npmRegistryBaseURL, EscapePathSegment(scope), EscapePathSegment(name), tarball), nil
+	}
+	// For regular packages: package -> package/-/package-version.tgz
+	return fmt.Sprintf("%s/%s/-/%s", npmRegistryBaseURL, EscapePathSegment(name), tarball), nil
+}
+
+// StatusForCode describes the curation outcome signaled by a tarball response status
+func StatusForCode(statusCode int) string {
+	switch statusCode {
+	case http.StatusOK:
+		return "✅ Available in NPM Registry"
+	case http.StatusForbidden:
+		return "❌ Blocked (403 Forbidden)"
+	case http.StatusNotFound:
+		return "❌ Not Found (404)"
+	default:
+		return fmt.Sprintf("⚠️ Unexpected Response: %d", statusCode)
+	}
+}
+
+// IsRedirect reports whether a response status is a redirect
+func IsRedirect(statusCode int) bool {
+	return statusCode >= 300 && statusCode < 400 && statusCode != http.StatusNotModified
+}
+
+// MatchesHost reports whether a host is one of the hosts, where *.example.com matches subdomains. Hosts are
+// compared in their normalized form, so internationalized hosts match their punycode.
+func MatchesHost(host string, hosts []string) bool {
+	host = NormalizeHost(host)
+	for _, allowed := range hosts {
+		if wildcard := strings.HasPrefix(allowed, "*."); wildcard {
+			allowed = "*." + NormalizeHost(allowed[2:])
+		} else {
+			allowed = NormalizeHost(allowed)
+		}
+		if host == allowed || (strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+// HostOf returns the normalized host of a URL, or an empty string if it doesn't parse
+func HostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return NormalizeHost(parsed.Hostname())
+}
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRequestsHeadersOnly(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write(make([]byte, 1<<20))
+	}))
+	defer server.Close()
+
+	result := (&Registry{URL: server.URL}).Check(Dependency{Name: "lodash", Version: "4.17.20"})
+	assert.Equal(t, http.StatusForbidden, result.StatusCode)
+	assert.Equal(t, []string{http.MethodHead}, methods)
+}
+
+func TestCheckFallsBackToGet(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	result := (&Registry{URL: server.URL}).Check(Dependency{Name: "abbrev", Version: "1.1.1"})
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, "✅ Available in NPM Registry", result.Status)
+	assert.Equal(t, []string{http.MethodHead, http.MethodGet}, methods)
+}
+
+func TestCheckSendsBasicAuth(t *testing.T) {
+	var user, password string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ = r.BasicAuth()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// API keys authenticate as the password of the user
+	registry := &Registry{URL: server.URL, User: "ci", Password: "api-key"}
+	result := registry.Check(Dependency{Name: "lodash", Version: "4.17.21"})
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, "ci", user)
+	assert.Equal(t, "api-key", password)
+}
+
+func TestCredentialsAuthorize(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://acme.jfrog.io/artifactory/api/npm/npm-remote", nil)
+	Credentials{}.Authorize(req)
+	assert.Empty(t, req.Header.Get("Authorization"))
+
+	// Tokens take precedence over the user and password
+	Credentials{AccessToken: "token", User: "ci", Password: "api-key"}.Authorize(req)
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+}
+
+func TestCheckScopedPackages(t *testing.T) {
+	var curated, internal []string
+	curatedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		curated = append(curated, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer curatedServer.Close()
+	internalServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		internal = append(internal, r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer internal-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer internalServer.Close()
+
+	registry := &Registry{URL: curatedServer.URL, AccessToken: "token", Scopes: map[string]*Registry{
+		"@acme": {URL: internalServer.URL, AccessToken: "internal-token"},
+	}}
+	result := registry.Check(Dependency{Name: "@acme/ui", Version: "2.0.0"})
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Contains(t, result.Status, "(@acme registry)")
+	assert.Equal(t, http.StatusOK, registry.Check(Dependency{Name: "@babel/core", Version: "7.24.0"}).StatusCode)
+	assert.Equal(t, http.StatusOK, registry.Check(Dependency{Name: "lodash", Version: "4.17.21"}).StatusCode)
+
+	assert.Equal(t, []string{"/@acme/ui/-/ui-2.0.0.tgz"}, internal)
+	assert.Equal(t, []string{"/@babel/core/-/core-7.24.0.tgz", "/lodash/-/lodash-4.17.21.tgz"}, curated)
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy configures how checks failing with a transient registry error are retried. The zero value checks
+// each package once.
+type RetryPolicy struct {
+	// Attempts is the maximum number of checks of a package, including the first
+	Attempts int
+
+	// BaseDelay is the delay before the first retry, doubled for each of the next ones up to MaxDelay. Each delay
+	// is jittered to a random duration up to it, so workers throttled together don't retry together.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// sleep waits between attempts until the delay passes or the context is done, replaced by tests
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Sleep waits until the delay passes or the context is done, in which case it returns the error of the context
+func Sleep(ctx context.Context, d time.Duration) error {
+	return sleep(ctx, d)
+}
+
+// delay returns the jittered delay before a retry, where retry 1 follows the first attempt
+func (policy RetryPolicy) delay(retry int) time.Duration {
+	backoff := policy.BaseDelay
+	for i := 1; i < retry && (policy.MaxDelay <= 0 || backoff < policy.MaxDelay); i++ {
+		backoff *= 2
+	}
+	if policy.MaxDelay > 0 && backoff > policy.MaxDelay {
+		backoff = policy.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return rand.N(backoff + 1)
+}
+
+// retryDelay returns the delay before a retry of a result: the Retry-After of the registry, up to MaxDelay, else the
+// jittered backoff
+func (policy RetryPolicy) retryDelay(retry int, result AuditResult) time.Duration {
+	if result.RetryAfter > 0 {
+		if policy.MaxDelay > 0 && result.RetryAfter > policy.MaxDelay {
+			return policy.MaxDelay
+		}
+		return result.RetryAfter
+	}
+	return policy.delay(retry)
+}
+
+// retryAfter returns the delay the Retry-After header of a 429 or 503 response asks for, in seconds or as an HTTP
+// date, or 0
+func retryAfter(resp *http.Response) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
+// IsTransient reports whether a result is a transient registry error worth retrying: throttling (429), a server
+// error (5xx), or a timeout
+func IsTransient(result AuditResult) bool {
+	if result.Error != nil {
+		return isRequestTimeout(result.Error)
+	}
+	return result.StatusCode == http.StatusTooManyRequests || result.StatusCode >= 500
+}
+
+// IsTimeout reports whether a check failed because it timed out: a request exceeding the client timeout, or the
+// deadline of the audit passing before the check completed or started
+func IsTimeout(err error) bool {
+	return isRequestTimeout(err) || errors.Is(err, ErrDeadlineExceeded)
+}
+
+func isRequestTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// withRetries runs a check until it yields a result that isn't transient, the attempts are used up or the context
+// is done, waiting or not, and records the attempts used in the result
+func (policy RetryPolicy) withRetries(ctx context.Context, check func() AuditResult) AuditResult {
+	attempts := max(policy.Attempts, 1)
+	var result AuditResult
+	for attempt := 1; ; attempt++ {
+		result = check()
+		result.Attempts = attempt
+		if attempt >= attempts || !IsTransient(result) || ctx.Err() != nil {
+			return result
+		}
+		if sleep(ctx, policy.retryDelay(attempt, result)) != nil {
+			return result
+		}
+	}
+}
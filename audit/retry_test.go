@@ -0,0 +1,172 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSleep replaces the wait between attempts, returning the function restoring it
+func stubSleep(stub func(context.Context, time.Duration) error) func() {
+	original := sleep
+	sleep = stub
+	return func() { sleep = original }
+}
+
+func TestCheckRetriesTransientErrors(t *testing.T) {
+	var delays []time.Duration
+	defer stubSleep(func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	})()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	registry := &Registry{URL: server.URL, Retry: RetryPolicy{Attempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}}
+	result := registry.Check(Dependency{Name: "abbrev", Version: "1.1.1"})
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, 3, result.Attempts)
+	assert.Len(t, delays, 2)
+	assert.LessOrEqual(t, delays[0], 100*time.Millisecond)
+	assert.LessOrEqual(t, delays[1], 200*time.Millisecond)
+}
+
+func TestCheckReportsTransientErrorAfterAttempts(t *testing.T) {
+	defer stubSleep(func(context.Context, time.Duration) error { return nil })()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	registry := &Registry{URL: server.URL, Retry: RetryPolicy{Attempts: 3}}
+	result := registry.Check(Dependency{Name: "abbrev", Version: "1.1.1"})
+	assert.Equal(t, http.StatusServiceUnavailable, result.StatusCode)
+	assert.Equal(t, 3, result.Attempts)
+	assert.Equal(t, 3, requests)
+}
+
+func TestCheckMarksTimedOutRequests(t *testing.T) {
+	defer stubSleep(func(context.Context, time.Duration) error { return nil })()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	registry := &Registry{URL: server.URL, Retry: RetryPolicy{Attempts: 2}, Client: &http.Client{Timeout: 20 * time.Millisecond}}
+	result := registry.Check(Dependency{Name: "abbrev", Version: "1.1.1"})
+	assert.Equal(t, StatusTimedOut, result.Status)
+	assert.True(t, IsTimeout(result.Error))
+	assert.Equal(t, 2, result.Attempts)
+	assert.False(t, IsTimeout(errors.New("connection refused")))
+}
+
+func TestCheckDoesNotRetryCurationOutcomes(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	registry := &Registry{URL: server.URL, Retry: RetryPolicy{Attempts: 3}}
+	result := registry.Check(Dependency{Name: "lodash", Version: "4.17.20"})
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, 1, requests)
+
+	// The zero policy checks once
+	result = (&Registry{URL: server.URL}).Check(Dependency{Name: "lodash", Version: "4.17.20"})
+	assert.Equal(t, 1, result.Attempts)
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for retry := 1; retry <= 10; retry++ {
+		assert.LessOrEqual(t, policy.delay(retry), 5*time.Second)
+	}
+	assert.Equal(t, time.Duration(0), RetryPolicy{}.delay(1))
+}
+
+func TestCheckWaitsForRetryAfter(t *testing.T) {
+	var delays []time.Duration
+	defer stubSleep(func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	})()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 3:
+			// Only throttling and unavailability responses are waited for as they ask
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	registry := &Registry{URL: server.URL, Retry: RetryPolicy{Attempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Minute}}
+	result := registry.Check(Dependency{Name: "abbrev", Version: "1.1.1"})
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	require.Len(t, delays, 3)
+	assert.Equal(t, 3*time.Second, delays[0])
+	// Capped at the MaxDelay of the policy
+	assert.Equal(t, time.Minute, delays[1])
+	assert.LessOrEqual(t, delays[2], 400*time.Millisecond)
+}
+
+func TestRetryAfter(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	assert.Equal(t, time.Duration(0), retryAfter(resp))
+	resp.Header.Set("Retry-After", "7")
+	assert.Equal(t, 7*time.Second, retryAfter(resp))
+	resp.Header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.InDelta(t, float64(time.Hour), float64(retryAfter(resp)), float64(2*time.Second))
+	resp.Header.Set("Retry-After", "soon")
+	assert.Equal(t, time.Duration(0), retryAfter(resp))
+	resp.StatusCode = http.StatusInternalServerError
+	resp.Header.Set("Retry-After", "7")
+	assert.Equal(t, time.Duration(0), retryAfter(resp))
+}
+
+func TestRetriesStopWaitingWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	checks := 0
+	policy := RetryPolicy{Attempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	started := time.Now()
+	time.AfterFunc(50*time.Millisecond, cancel)
+	result := policy.withRetries(ctx, func() AuditResult {
+		checks++
+		return AuditResult{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Hour}
+	})
+	assert.Less(t, time.Since(started), 10*time.Second)
+	assert.Equal(t, 1, checks)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, result.StatusCode)
+}
@@ -0,0 +1,260 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PackageManagerSBOM reads CycloneDX and SPDX JSON SBOMs, whose components may be of several ecosystems
+const PackageManagerSBOM = "sbom"
+
+// Matched against the base names of the audited files, as the SBOM tools name their outputs
+var sbomFileNames = []string{"*.cdx.json", "*.spdx.json", "bom.json", "sbom.json"}
+
+// purlEcosystems maps the package URL types to the ecosystems of their registries
+var purlEcosystems = map[string]string{
+	"npm":      EcosystemNpm,
+	"pypi":     EcosystemPyPI,
+	"golang":   EcosystemGo,
+	"maven":    EcosystemMaven,
+	"composer": EcosystemComposer,
+}
+
+// cycloneDXDocument represents the parts of a CycloneDX JSON BOM that make a dependency tree
+type cycloneDXDocument struct {
+	BOMFormat string `json:"bomFormat"`
+	Metadata  struct {
+		Component *cycloneDXComponent `json:"component"`
+	} `json:"metadata"`
+	Components   []cycloneDXComponent `json:"components"`
+	Dependencies []struct {
+		Ref       string   `json:"ref"`
+		DependsOn []string `json:"dependsOn"`
+	} `json:"dependencies"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef     string               `json:"bom-ref"`
+	PURL       string               `json:"purl"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// spdxDocument represents the parts of an SPDX 2.x JSON document that make a dependency tree
+type spdxDocument struct {
+	SPDXVersion       string   `json:"spdxVersion"`
+	DocumentDescribes []string `json:"documentDescribes"`
+	Packages          []struct {
+		SPDXID       string `json:"SPDXID"`
+		ExternalRefs []struct {
+			ReferenceType    string `json:"referenceType"`
+			ReferenceLocator string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+	Relationships []struct {
+		SPDXElementID      string `json:"spdxElementId"`
+		RelationshipType   string `json:"relationshipType"`
+		RelatedSPDXElement string `json:"relatedSpdxElement"`
+	} `json:"relationships"`
+}
+
+// sbomPackage is a package of an SBOM, as its package URL identifies it
+type sbomPackage struct {
+	ecosystem string
+	name      string
+	version   string
+}
+
+// parseSBOMData reads the components of a CycloneDX BOM, or the packages of an SPDX document, with a package URL of
+// a supported ecosystem into a tree: each package is tagged with its ecosystem, and the dependencies of the SBOM
+// make the graph of the tree, those of the components it describes being the direct dependencies. The tree keeps
+// the highest version of each name, and a name listed in several ecosystems in only one of them.
+func parseSBOMData(data []byte) (*DependencyTree, error) {
+	var format struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &format); err != nil {
+		return nil, fmt.Errorf("error parsing SBOM: %v", err)
+	}
+	builder := &sbomTreeBuilder{refs: make(map[string]sbomPackage), dependencies: make(map[string][]string)}
+	switch {
+	case format.BOMFormat == "CycloneDX":
+		var bom cycloneDXDocument
+		if err := json.Unmarshal(data, &bom); err != nil {
+			return nil, fmt.Errorf("error parsing CycloneDX BOM: %v", err)
+		}
+		builder.addCycloneDX(bom)
+	case strings.HasPrefix(format.SPDXVersion, "SPDX-2."):
+		var document spdxDocument
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("error parsing SPDX document: %v", err)
+		}
+		builder.addSPDX(document)
+	default:
+		return nil, fmt.Errorf("unsupported SBOM: expected a CycloneDX JSON BOM or an SPDX 2.x JSON document")
+	}
+	return builder.tree(), nil
+}
+
+// sbomTreeBuilder collects the packages of an SBOM by their reference, the bom-ref or SPDXID, and the references
+// each depends on
+type sbomTreeBuilder struct {
+	refs         map[string]sbomPackage
+	roots        []string
+	dependencies map[string][]string
+}
+
+func (b *sbomTreeBuilder) addCycloneDX(bom cycloneDXDocument) {
+	if root := bom.Metadata.Component; root != nil {
+		b.roots = append(b.roots, root.BOMRef)
+	}
+	var add func(components []cycloneDXComponent)
+	add = func(components []cycloneDXComponent) {
+		for _, component := range components {
+			if pkg, ok := parsePURL(component.PURL); ok {
+				ref := component.BOMRef
+				if ref == "" {
+					ref = component.PURL
+				}
+				b.refs[ref] = pkg
+			}
+			add(component.Components)
+		}
+	}
+	add(bom.Components)
+	for _, dependency := range bom.Dependencies {
+		b.dependencies[dependency.Ref] = append(b.dependencies[dependency.Ref], dependency.DependsOn...)
+	}
+}
+
+func (b *sbomTreeBuilder) addSPDX(document spdxDocument) {
+	b.roots = append(b.roots, document.DocumentDescribes...)
+	for _, pkg := range document.Packages {
+		for _, ref := range pkg.ExternalRefs {
+			if ref.ReferenceType != "purl" {
+				continue
+			}
+			if parsed, ok := parsePURL(ref.ReferenceLocator); ok {
+				b.refs[pkg.SPDXID] = parsed
+				break
+			}
+		}
+	}
+	for _, relationship := range document.Relationships {
+		from, to := relationship.SPDXElementID, relationship.RelatedSPDXElement
+		switch relationship.RelationshipType {
+		case "DESCRIBES":
+			b.roots = append(b.roots, to)
+		case "DESCRIBED_BY":
+			b.roots = append(b.roots, from)
+		case "DEPENDS_ON", "DEV_DEPENDENCY_OF", "OPTIONAL_DEPENDENCY_OF", "RUNTIME_DEPENDENCY_OF", "BUILD_DEPENDENCY_OF":
+			if relationship.RelationshipType != "DEPENDS_ON" {
+				// The *_DEPENDENCY_OF relationships point from the dependency to its dependent
+				from, to = to, from
+			}
+			b.dependencies[from] = append(b.dependencies[from], to)
+		case "DEPENDENCY_OF":
+			b.dependencies[to] = append(b.dependencies[to], from)
+		}
+	}
+}
+
+// tree builds the dependency tree of the SBOM. The packages the roots depend on are the direct dependencies of the
+// project, or all the packages when the SBOM records no root dependencies.
+func (b *sbomTreeBuilder) tree() *DependencyTree {
+	tree := &DependencyTree{Packages: make(map[string]PackageInfo)}
+	for _, ref := range sortedKeys(b.refs) {
+		pkg := b.refs[ref]
+		existing, exists := tree.Packages[pkg.name]
+		if exists && (existing.Ecosystem != pkg.ecosystem || !isHigherVersion(pkg.version, existing.Version)) {
+			continue
+		}
+		tree.Packages[pkg.name] = PackageInfo{Version: pkg.version, Type: TypePackage, Ecosystem: pkg.ecosystem}
+	}
+	for ref, dependsOn := range b.dependencies {
+		pkg, exists := b.refs[ref]
+		if !exists || tree.Packages[pkg.name].Version != pkg.version {
+			continue
+		}
+		info := tree.Packages[pkg.name]
+		for _, dependency := range dependsOn {
+			if dep, exists := b.refs[dependency]; exists && dep.name != pkg.name {
+				if info.Dependencies == nil {
+					info.Dependencies = make(map[string]string)
+				}
+				info.Dependencies[dep.name] = dep.version
+			}
+		}
+		tree.Packages[pkg.name] = info
+	}
+	direct := make(map[string]string)
+	for _, root := range b.roots {
+		for _, dependency := range b.dependencies[root] {
+			if dep, exists := b.refs[dependency]; exists {
+				direct[dep.name] = dep.version
+			}
+		}
+	}
+	if len(direct) > 0 {
+		tree.Importers = map[string]map[string]string{".": direct}
+	}
+	return tree
+}
+
+// parsePURL reads the ecosystem, name and version of a package URL, e.g. pkg:npm/%40babel/core@7.23.0, returning
+// false for those of unsupported types or without a version
+func parsePURL(purl string) (sbomPackage, bool) {
+	rest, found := strings.CutPrefix(purl, "pkg:")
+	if !found {
+		return sbomPackage{}, false
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+	purlType, path, found := strings.Cut(strings.TrimLeft(rest, "/"), "/")
+	ecosystem, supported := purlEcosystems[strings.ToLower(purlType)]
+	at := strings.LastIndex(path, "@")
+	if !found || !supported || at < 0 {
+		return sbomPackage{}, false
+	}
+	version, err := url.PathUnescape(path[at+1:])
+	if err != nil || version == "" {
+		return sbomPackage{}, false
+	}
+	segments := strings.Split(strings.Trim(path[:at], "/"), "/")
+	for i, segment := range segments {
+		if segments[i], err = url.PathUnescape(segment); err != nil {
+			return sbomPackage{}, false
+		}
+	}
+	name := strings.Join(segments, "/")
+	switch ecosystem {
+	case EcosystemPyPI:
+		name = NormalizePyPIName(name)
+	case EcosystemMaven:
+		if len(segments) != 2 {
+			return sbomPackage{}, false
+		}
+		name = segments[0] + ":" + segments[1]
+	case EcosystemComposer:
+		name = strings.ToLower(name)
+	}
+	if name == "" {
+		return sbomPackage{}, false
+	}
+	return sbomPackage{ecosystem: ecosystem, name: name, version: version}, true
+}
+
+// Ecosystems counts the packages of the tree by ecosystem, those not tagged with one being npm packages
+func (tree *DependencyTree) Ecosystems() map[string]int {
+	counts := make(map[string]int)
+	for _, info := range tree.Packages {
+		ecosystem := info.Ecosystem
+		if ecosystem == "" {
+			ecosystem = EcosystemNpm
+		}
+		counts[ecosystem]++
+	}
+	return counts
+}
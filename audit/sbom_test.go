@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCycloneDXSBOM(t *testing.T) {
+	path := filepath.Join("testdata", "sbom", "bom.cdx.json")
+	assert.Equal(t, PackageManagerSBOM, PackageManagerFor(path))
+	tree, err := ParseLockFile(path)
+	require.NoError(t, err)
+
+	// Components of unsupported types and without a package URL are left out, nested components kept
+	assert.Equal(t, []string{"@babel/core", "com.google.guava:guava", "golang.org/x/text", "react", "react-dom", "requests", "semver"}, sortedKeys(tree.Packages))
+	assert.Equal(t, PackageInfo{Version: "2.31.0", Type: TypePackage, Ecosystem: EcosystemPyPI}, tree.Packages["requests"])
+	assert.Equal(t, "32.1.2-jre", tree.Packages["com.google.guava:guava"].Version)
+	assert.Equal(t, EcosystemGo, tree.Packages["golang.org/x/text"].Ecosystem)
+	assert.Equal(t, map[string]string{"react": "18.2.0"}, tree.Packages["react-dom"].Dependencies)
+	assert.Equal(t, map[string]int{EcosystemNpm: 4, EcosystemPyPI: 1, EcosystemMaven: 1, EcosystemGo: 1}, tree.Ecosystems())
+
+	relationships := tree.Relationships()
+	assert.Equal(t, RelationshipDirect, relationships["@babel/core"])
+	assert.Equal(t, RelationshipDirect, relationships["requests"])
+	assert.Equal(t, RelationshipTransitive, relationships["semver"])
+	for _, dep := range tree.Dependencies() {
+		if dep.Name == "requests" {
+			assert.Equal(t, EcosystemPyPI, dep.Ecosystem)
+		}
+	}
+}
+
+func TestParseSPDXSBOM(t *testing.T) {
+	path := filepath.Join("testdata", "sbom", "app.spdx.json")
+	assert.Equal(t, PackageManagerSBOM, PackageManagerFor(path))
+	tree, err := ParseLockFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"monolog/monolog", "react", "react-dom"}, sortedKeys(tree.Packages))
+	assert.Equal(t, EcosystemComposer, tree.Packages["monolog/monolog"].Ecosystem)
+	assert.Equal(t, map[string]string{"react": "18.2.0"}, tree.Packages["react-dom"].Dependencies)
+	assert.Equal(t, map[string]map[string]string{".": {"react-dom": "18.2.0", "monolog/monolog": "3.5.0"}}, tree.Importers)
+
+	_, err = parseSBOMData([]byte(`{"name": "web-app"}`))
+	assert.EqualError(t, err, "unsupported SBOM: expected a CycloneDX JSON BOM or an SPDX 2.x JSON document")
+}
+
+func TestParsePURL(t *testing.T) {
+	tests := []struct {
+		purl     string
+		expected sbomPackage
+		ok       bool
+	}{
+		{"pkg:npm/%40angular/core@17.0.0", sbomPackage{EcosystemNpm, "@angular/core", "17.0.0"}, true},
+		{"pkg:npm/lodash@4.17.21?arch=x86#lib", sbomPackage{EcosystemNpm, "lodash", "4.17.21"}, true},
+		{"pkg:pypi/Zope.Interface@6.1", sbomPackage{EcosystemPyPI, "zope-interface", "6.1"}, true},
+		{"pkg:maven/org.apache.commons/commons-lang3@3.14.0", sbomPackage{EcosystemMaven, "org.apache.commons:commons-lang3", "3.14.0"}, true},
+		{"pkg:golang/github.com/gorilla/mux@v1.8.1", sbomPackage{EcosystemGo, "github.com/gorilla/mux", "v1.8.1"}, true},
+		{"pkg:composer/Laravel/Framework@10.0.0", sbomPackage{EcosystemComposer, "laravel/framework", "10.0.0"}, true},
+		{"pkg:maven/commons-lang3@3.14.0", sbomPackage{}, false},
+		{"pkg:npm/lodash", sbomPackage{}, false},
+		{"pkg:deb/debian/curl@7.50.3", sbomPackage{}, false},
+		{"cpe:2.3:a:lodash:lodash:4.17.21", sbomPackage{}, false},
+	}
+	for _, test := range tests {
+		pkg, ok := parsePURL(test.purl)
+		assert.Equal(t, test.ok, ok, test.purl)
+		assert.Equal(t, test.expected, pkg, test.purl)
+	}
+}
+
+func TestCheckOtherEcosystems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/pypi/") {
+			w.Write([]byte(`<a href="../../packages/requests-2.31.0.tar.gz">requests-2.31.0.tar.gz</a>`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	registry := &Registry{
+		URL:        server.URL + "/npm",
+		Ecosystems: map[string]*Registry{EcosystemPyPI: {URL: server.URL + "/pypi/simple", Ecosystem: EcosystemPyPI}},
+	}
+	result := registry.CheckContext(context.Background(), Dependency{Name: "requests", Version: "2.31.0", Ecosystem: EcosystemPyPI})
+	assert.NoError(t, result.Error)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+
+	result = registry.CheckContext(context.Background(), Dependency{Name: "text", Version: "v0.14.0", Ecosystem: EcosystemGo})
+	assert.EqualError(t, result.Error, "no registry to check the go packages against")
+	assert.Equal(t, "❌ No go registry", result.Status)
+
+	result = registry.CheckContext(context.Background(), Dependency{Name: "react", Version: "18.2.0", Ecosystem: EcosystemNpm})
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+}
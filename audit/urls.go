@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// EscapePathSegment percent-encodes a name or version as a single segment of a URL path, as encodeURIComponent
+// does: only the unreserved characters and !'()* are kept. Plus signs are encoded, as some servers decode them to
+// spaces in paths, and unicode is encoded as UTF-8.
+func EscapePathSegment(segment string) string {
+	const hex = "0123456789ABCDEF"
+	var escaped strings.Builder
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.IndexByte("-_.~!'()*", c) >= 0 {
+			escaped.WriteByte(c)
+			continue
+		}
+		escaped.WriteByte('%')
+		escaped.WriteByte(hex[c>>4])
+		escaped.WriteByte(hex[c&15])
+	}
+	return escaped.String()
+}
+
+// EscapePath percent-encodes each segment of a slash separated path, such as a Go module path
+func EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = EscapePathSegment(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// EscapeNpmName encodes a package name as npm does in packument URLs: the slash of a scoped name is encoded, e.g.
+// @types/node becomes @types%2Fnode, so the name is a single path segment
+func EscapeNpmName(name string) string {
+	if scope, scopedName, found := strings.Cut(strings.TrimPrefix(name, "@"), "/"); found && strings.HasPrefix(name, "@") {
+		return "@" + EscapePathSegment(scope) + "%2F" + EscapePathSegment(scopedName)
+	}
+	return EscapePathSegment(name)
+}
+
+// NormalizeHost lowercases a host and converts an internationalized one to its punycode form, so that hosts are
+// compared the same way however they were written, e.g. bücher.example and xn--bcher-kva.example. Hosts IDNA
+// rejects, such as IP addresses, are only lowercased.
+func NormalizeHost(host string) string {
+	host = strings.ToLower(host)
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return host
+	}
+	return ascii
+}
+
+// isPathSegment reports whether a name or version can be a path segment on its own, which the relative
+// segments and empty strings can't
+func isPathSegment(segment string) bool {
+	return segment != "" && segment != "." && segment != ".."
+}
+
+// npmNameSegments splits a package name into its scope, empty for unscoped packages, and its name. Names that
+// wouldn't map to a single package path, such as @scope/a/b or ../admin, are rejected.
+func npmNameSegments(packageName string) (scope, name string, err error) {
+	if strings.HasPrefix(packageName, "@") {
+		scope, name, _ = strings.Cut(strings.TrimPrefix(packageName, "@"), "/")
+		if !isPathSegment(scope) || !isPathSegment(name) || strings.Contains(name, "/") {
+			return "", "", fmt.Errorf("invalid scoped package format")
+		}
+		return scope, name, nil
+	}
+	if !isPathSegment(packageName) || strings.Contains(packageName, "/") {
+		return "", "", fmt.Errorf("invalid package name '%s'", packageName)
+	}
+	return "", packageName, nil
+}
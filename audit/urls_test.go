@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapePathSegment(t *testing.T) {
+	cases := map[string]string{
+		"lodash":               "lodash",
+		"JSONStream":           "JSONStream",
+		"1.0.0+build.5":        "1.0.0%2Bbuild.5",
+		"left pad":             "left%20pad",
+		"a/b":                  "a%2Fb",
+		"?x=1#frag":            "%3Fx%3D1%23frag",
+		"100%":                 "100%25",
+		"café":                 "caf%C3%A9",
+		"name!'()*~":           "name!'()*~",
+		"1.0.0\r\nX-Header: 1": "1.0.0%0D%0AX-Header%3A%201",
+	}
+	for segment, escaped := range cases {
+		assert.Equal(t, escaped, EscapePathSegment(segment), segment)
+	}
+	assert.Equal(t, "golang.org/x/caf%C3%A9", EscapePath("golang.org/x/café"))
+}
+
+func TestEscapeNpmName(t *testing.T) {
+	assert.Equal(t, "lodash", EscapeNpmName("lodash"))
+	assert.Equal(t, "@types%2Fnode", EscapeNpmName("@types/node"))
+	assert.Equal(t, "@a%2Fb%2Fc", EscapeNpmName("@a/b/c"))
+	assert.Equal(t, "..%2Fadmin", EscapeNpmName("../admin"))
+}
+
+func TestTarballURLEscaping(t *testing.T) {
+	base := "https://registry.example.com/npm"
+	valid := map[[2]string]string{
+		{"lodash", "4.17.21"}:          base + "/lodash/-/lodash-4.17.21.tgz",
+		{"@babel/core", "7.24.0"}:      base + "/@babel/core/-/core-7.24.0.tgz",
+		{"typescript", "5.4.0+sha.1"}:  base + "/typescript/-/typescript-5.4.0%2Bsha.1.tgz",
+		{"left pad", "1.3.0"}:          base + "/left%20pad/-/left%20pad-1.3.0.tgz",
+		{"lodash", "1.0.0?x=1#y"}:      base + "/lodash/-/lodash-1.0.0%3Fx%3D1%23y.tgz",
+		{"пакет", "1.0.0"}:             base + "/%D0%BF%D0%B0%D0%BA%D0%B5%D1%82/-/%D0%BF%D0%B0%D0%BA%D0%B5%D1%82-1.0.0.tgz",
+		{"@scope/na me", "1.0.0-rc.1"}: base + "/@scope/na%20me/-/na%20me-1.0.0-rc.1.tgz",
+	}
+	for input, expected := range valid {
+		tarballURL, err := TarballURL(base, input[0], input[1])
+		assert.NoError(t, err, input[0])
+		assert.Equal(t, expected, tarballURL)
+	}
+
+	// Names and versions that would map to another path, or none
+	for _, input := range [][2]string{
+		{"@scope/a/b", "1.0.0"}, {"@scope", "1.0.0"}, {"@/name", "1.0.0"}, {"a/b", "1.0.0"},
+		{"..", "1.0.0"}, {".", "1.0.0"}, {"", "1.0.0"}, {"lodash", ""}, {"lodash", ".."},
+	} {
+		_, err := TarballURL(base, input[0], input[1])
+		assert.Error(t, err, input[0]+"@"+input[1])
+	}
+}
+
+func TestEcosystemURLEscaping(t *testing.T) {
+	pomURL, err := MavenPOMURL("https://repo.example.com/maven", "org.acme:lib+extra", "1.0+build")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://repo.example.com/maven/org/acme/lib%2Bextra/1.0%2Bbuild/lib%2Bextra-1.0%2Bbuild.pom", pomURL)
+	_, err = MavenPOMURL("https://repo.example.com/maven", "org..acme:lib", "1.0")
+	assert.Error(t, err)
+	_, err = MavenPOMURL("https://repo.example.com/maven", "org.acme:lib", "../1.0")
+	assert.Error(t, err)
+
+	assert.Equal(t, "https://proxy.example.com/go/example.com/caf%C3%A9/@v/v1.0.0.info", GoModuleInfoURL("https://proxy.example.com/go", "example.com/café", "v1.0.0"))
+	assert.Equal(t, "https://pypi.example.com/simple/zope%20interface/", PyPIIndexURL("https://pypi.example.com/simple", "zope interface"))
+	assert.Equal(t, "https://repo.packagist.org/p2/acme/caf%C3%A9.json", ComposerMetadataURL("https://repo.packagist.org", "acme/café", "1.0.0"))
+}
+
+func TestNormalizeHost(t *testing.T) {
+	assert.Equal(t, "xn--bcher-kva.example", NormalizeHost("Bücher.example"))
+	assert.Equal(t, "xn--bcher-kva.example", NormalizeHost("xn--bcher-kva.example"))
+	assert.Equal(t, "registry.npmjs.org", NormalizeHost("Registry.NPMJS.org"))
+	assert.Equal(t, "127.0.0.1", NormalizeHost("127.0.0.1"))
+	assert.Equal(t, "my_host.internal", NormalizeHost("My_Host.internal"))
+
+	assert.True(t, MatchesHost("xn--bcher-kva.example", []string{"bücher.example"}))
+	assert.True(t, MatchesHost("cdn.bücher.example", []string{"*.xn--bcher-kva.example"}))
+	assert.Equal(t, "xn--bcher-kva.example", HostOf("https://bücher.example/npm/lodash"))
+}
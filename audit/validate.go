@@ -0,0 +1,155 @@
+package audit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Upper bound of the length of npm package names
+const maxNpmNameLength = 214
+
+var (
+	// Versions as defined by SemVer 2.0.0, which npm requires of published versions
+	strictSemverPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+	// Characters npm package names may have, those encodeURIComponent leaves as they are. Uppercase letters are
+	// only allowed in legacy names, which are still installed.
+	npmNamePattern = regexp.MustCompile(`^[A-Za-z0-9\-._~!'()*]+$`)
+	// Project names as defined by PEP 508
+	pypiNamePattern = regexp.MustCompile(`^([A-Za-z0-9]|[A-Za-z0-9][A-Za-z0-9._-]*[A-Za-z0-9])$`)
+	// Characters of the PEP 440 versions, including local versions
+	pypiVersionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.+!_-]*$`)
+	// Characters the Go module proxy protocol allows in module path elements
+	goPathElementPattern = regexp.MustCompile(`^[A-Za-z0-9\-._~]+$`)
+	// Maven groupIds, artifactIds and versions
+	mavenIDPattern      = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	mavenVersionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+	// Package names as Composer validates them, <vendor>/<project> in lowercase
+	composerNamePattern = regexp.MustCompile(`^[a-z0-9]([_.-]?[a-z0-9]+)*/[a-z0-9](([_.]|-{1,2})?[a-z0-9]+)*$`)
+	// Versions of releases and branches, such as dev-feature/login
+	composerVersionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+/-]*$`)
+)
+
+// InvalidDependency is a dependency of which the name or version breaks the rules of its ecosystem, so it isn't
+// requested from the registry
+type InvalidDependency struct {
+	Dependency
+	Reason string
+}
+
+// ValidateDependency checks the name and version of a dependency against the rules of its ecosystem, before they
+// are part of any registry URL. The Ecosystem of the dependency, if set, is that of the rules.
+func ValidateDependency(ecosystem string, dep Dependency) error {
+	if dep.Ecosystem != "" {
+		ecosystem = dep.Ecosystem
+	}
+	switch ecosystem {
+	case EcosystemPyPI:
+		return validatePyPIDependency(dep)
+	case EcosystemGo:
+		return validateGoDependency(dep)
+	case EcosystemMaven:
+		return validateMavenDependency(dep)
+	case EcosystemComposer:
+		return validateComposerDependency(dep)
+	default:
+		return validateNpmDependency(dep)
+	}
+}
+
+// ValidateDependencies splits dependencies into the valid ones, which keep their order, and the invalid ones
+func ValidateDependencies(ecosystem string, deps []Dependency) ([]Dependency, []InvalidDependency) {
+	valid := make([]Dependency, 0, len(deps))
+	var invalid []InvalidDependency
+	for _, dep := range deps {
+		if err := ValidateDependency(ecosystem, dep); err != nil {
+			invalid = append(invalid, InvalidDependency{Dependency: dep, Reason: err.Error()})
+			continue
+		}
+		valid = append(valid, dep)
+	}
+	return valid, invalid
+}
+
+// ValidateNpmName checks a package name against the npm naming rules, as validate-npm-package-name does for the
+// legacy names still installed
+func ValidateNpmName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("empty package name")
+	case len(name) > maxNpmNameLength:
+		return fmt.Errorf("package name longer than %d characters", maxNpmNameLength)
+	case strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_"):
+		return fmt.Errorf("package name '%s' starts with a period or an underscore", name)
+	case name == "node_modules" || name == "favicon.ico":
+		return fmt.Errorf("package name '%s' is reserved", name)
+	}
+	packageName := name
+	if strings.HasPrefix(name, "@") {
+		scope, scopedName, found := strings.Cut(strings.TrimPrefix(name, "@"), "/")
+		if !found || scope == "" || scopedName == "" || !npmNamePattern.MatchString(scope) {
+			return fmt.Errorf("invalid scoped package name '%s'. Expected @<scope>/<name>", name)
+		}
+		packageName = scopedName
+	}
+	if !npmNamePattern.MatchString(packageName) {
+		return fmt.Errorf("package name '%s' has characters that aren't URL-safe", name)
+	}
+	return nil
+}
+
+func validateNpmDependency(dep Dependency) error {
+	if err := ValidateNpmName(dep.Name); err != nil {
+		return err
+	}
+	if !strictSemverPattern.MatchString(dep.Version) {
+		return fmt.Errorf("version '%s' of %s isn't a valid semver version", dep.Version, dep.Name)
+	}
+	return nil
+}
+
+func validatePyPIDependency(dep Dependency) error {
+	if !pypiNamePattern.MatchString(dep.Name) {
+		return fmt.Errorf("invalid PyPI project name '%s'", dep.Name)
+	}
+	if !pypiVersionPattern.MatchString(dep.Version) {
+		return fmt.Errorf("version '%s' of %s isn't a valid PEP 440 version", dep.Version, dep.Name)
+	}
+	return nil
+}
+
+func validateGoDependency(dep Dependency) error {
+	elements := strings.Split(dep.Name, "/")
+	for _, element := range elements {
+		if element == "." || element == ".." || !goPathElementPattern.MatchString(element) {
+			return fmt.Errorf("invalid Go module path '%s'", dep.Name)
+		}
+	}
+	if !strings.HasPrefix(dep.Version, "v") || !strictSemverPattern.MatchString(strings.TrimPrefix(dep.Version, "v")) {
+		return fmt.Errorf("version '%s' of %s isn't a valid Go module version", dep.Version, dep.Name)
+	}
+	return nil
+}
+
+func validateMavenDependency(dep Dependency) error {
+	groupID, artifactID, ok := MavenCoordinates(dep.Name)
+	if !ok || !mavenIDPattern.MatchString(groupID) || !mavenIDPattern.MatchString(artifactID) || strings.Contains(groupID, "..") {
+		return fmt.Errorf("invalid Maven artifact '%s'. Expected <groupId>:<artifactId>", dep.Name)
+	}
+	if !mavenVersionPattern.MatchString(dep.Version) {
+		return fmt.Errorf("version '%s' of %s isn't a valid Maven version", dep.Version, dep.Name)
+	}
+	return nil
+}
+
+func validateComposerDependency(dep Dependency) error {
+	if !composerNamePattern.MatchString(dep.Name) {
+		return fmt.Errorf("invalid Composer package name '%s'. Expected <vendor>/<project>", dep.Name)
+	}
+	if !composerVersionPattern.MatchString(dep.Version) {
+		return fmt.Errorf("version '%s' of %s isn't a valid Composer version", dep.Version, dep.Name)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateNpmName(t *testing.T) {
+	for _, name := range []string{"lodash", "@types/node", "JSONStream", "lodash.merge", "@babel/plugin-transform-runtime"} {
+		assert.NoError(t, ValidateNpmName(name), name)
+	}
+	invalid := map[string]string{
+		"":                       "empty package name",
+		".bin":                   "package name '.bin' starts with a period or an underscore",
+		"_private":               "package name '_private' starts with a period or an underscore",
+		"node_modules":           "package name 'node_modules' is reserved",
+		"@types":                 "invalid scoped package name '@types'. Expected @<scope>/<name>",
+		"@/node":                 "invalid scoped package name '@/node'. Expected @<scope>/<name>",
+		"left pad":               "package name 'left pad' has characters that aren't URL-safe",
+		"../../admin":            "package name '../../admin' starts with a period or an underscore",
+		"lodash/../../admin":     "package name 'lodash/../../admin' has characters that aren't URL-safe",
+		"@scope/a/b":             "package name '@scope/a/b' has characters that aren't URL-safe",
+		strings.Repeat("a", 215): "package name longer than 214 characters",
+	}
+	for name, reason := range invalid {
+		assert.EqualError(t, ValidateNpmName(name), reason, name)
+	}
+}
+
+func TestValidateDependency(t *testing.T) {
+	valid := []struct {
+		ecosystem string
+		dep       Dependency
+	}{
+		{EcosystemNpm, Dependency{Name: "react", Version: "18.2.0"}},
+		{EcosystemNpm, Dependency{Name: "typescript", Version: "5.4.0-dev.20240101+sha.5114d1e"}},
+		{EcosystemPyPI, Dependency{Name: "zope.interface", Version: "6.1"}},
+		{EcosystemPyPI, Dependency{Name: "torch", Version: "2.1.0+cpu"}},
+		{EcosystemGo, Dependency{Name: "github.com/Azure/go-autorest", Version: "v14.2.0+incompatible"}},
+		{EcosystemGo, Dependency{Name: "golang.org/x/net", Version: "v0.0.0-20230101000000-abcdef123456"}},
+		{EcosystemMaven, Dependency{Name: "com.google.guava:guava", Version: "33.0.0-jre"}},
+		{EcosystemComposer, Dependency{Name: "symfony/polyfill-mbstring", Version: "dev-feature/login"}},
+	}
+	for _, tc := range valid {
+		assert.NoError(t, ValidateDependency(tc.ecosystem, tc.dep), tc.dep.Name)
+	}
+
+	invalid := []struct {
+		ecosystem string
+		dep       Dependency
+		reason    string
+	}{
+		{EcosystemNpm, Dependency{Name: "react", Version: "^18.2.0"}, "version '^18.2.0' of react isn't a valid semver version"},
+		{EcosystemNpm, Dependency{Name: "react", Version: "18.02.0"}, "version '18.02.0' of react isn't a valid semver version"},
+		{EcosystemNpm, Dependency{Name: "react", Version: "18.2.0\r\nX-Injected: 1"}, "version '18.2.0\r\nX-Injected: 1' of react isn't a valid semver version"},
+		{EcosystemPyPI, Dependency{Name: "-requests", Version: "2.31.0"}, "invalid PyPI project name '-requests'"},
+		{EcosystemPyPI, Dependency{Name: "requests", Version: "2.31.0; python_version<'3.8'"}, "version '2.31.0; python_version<'3.8'' of requests isn't a valid PEP 440 version"},
+		{EcosystemGo, Dependency{Name: "golang.org/x/../net", Version: "v0.17.0"}, "invalid Go module path 'golang.org/x/../net'"},
+		{EcosystemGo, Dependency{Name: "golang.org/x/net", Version: "0.17.0"}, "version '0.17.0' of golang.org/x/net isn't a valid Go module version"},
+		{EcosystemMaven, Dependency{Name: "log4j-core", Version: "2.14.1"}, "invalid Maven artifact 'log4j-core'. Expected <groupId>:<artifactId>"},
+		{EcosystemMaven, Dependency{Name: "org.slf4j:slf4j-api", Version: "../2.0.11"}, "version '../2.0.11' of org.slf4j:slf4j-api isn't a valid Maven version"},
+		{EcosystemComposer, Dependency{Name: "guzzle", Version: "7.8.1"}, "invalid Composer package name 'guzzle'. Expected <vendor>/<project>"},
+		{EcosystemComposer, Dependency{Name: "guzzlehttp/guzzle", Version: "7.8.1 as 7.9.0"}, "version '7.8.1 as 7.9.0' of guzzlehttp/guzzle isn't a valid Composer version"},
+	}
+	for _, tc := range invalid {
+		assert.EqualError(t, ValidateDependency(tc.ecosystem, tc.dep), tc.reason, tc.dep.Name)
+	}
+}
+
+func TestValidateDependencies(t *testing.T) {
+	deps := []Dependency{
+		{Name: "react", Version: "18.2.0"},
+		{Name: "left pad", Version: "1.3.0"},
+		{Name: "lodash", Version: "4.17.21"},
+	}
+	valid, invalid := ValidateDependencies(EcosystemNpm, deps)
+	assert.Equal(t, []Dependency{deps[0], deps[2]}, valid)
+	assert.Equal(t, []InvalidDependency{{Dependency: deps[1], Reason: "package name 'left pad' has characters that aren't URL-safe"}}, invalid)
+}
+
+func TestCheckSkipsInvalidDependencies(t *testing.T) {
+	// Invalid dependencies are reported without any request, so the unreachable registry isn't contacted
+	registry := &Registry{URL: "http://127.0.0.1:0/npm-remote"}
+	result := registry.Check(Dependency{Name: "lodash", Version: "latest", Type: TypePackage})
+	assert.Equal(t, "❌ Invalid package", result.Status)
+	assert.Zero(t, result.StatusCode)
+	assert.EqualError(t, result.Error, "version 'latest' of lodash isn't a valid semver version")
+}
@@ -0,0 +1,184 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// PnpmWorkspaceFileName is the workspace file pnpm keeps next to the lock file
+	PnpmWorkspaceFileName = "pnpm-workspace.yaml"
+
+	// The importer of the workspace root
+	rootImporterPath = "."
+)
+
+// Workspace represents a pnpm monorepo: the projects of its pnpm-workspace.yaml and their package names
+type Workspace struct {
+	Root     string
+	Patterns []string
+	// Projects maps the importer path of each project of the lock file, relative to the root, to its package name
+	Projects map[string]string
+}
+
+// ReadPnpmWorkspace reads the pnpm-workspace.yaml of the directory of a lock file and names the importers of the
+// lock file after the package.json of their projects. It returns nil when the directory isn't a workspace root.
+// Importers outside the workspace patterns, left in the lock file by removed projects, are reported with a warning.
+func ReadPnpmWorkspace(root string, importers map[string]map[string]string) (*Workspace, error) {
+	data, err := ioutil.ReadFile(filepath.Join(root, PnpmWorkspaceFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", PnpmWorkspaceFileName, err)
+	}
+	var config struct {
+		Packages []string `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", PnpmWorkspaceFileName, err)
+	}
+
+	workspace := &Workspace{Root: root, Patterns: config.Packages, Projects: make(map[string]string)}
+	var outside []string
+	for _, importer := range sortedKeys(importers) {
+		if importer != rootImporterPath && !workspace.Includes(importer) {
+			outside = append(outside, importer)
+		}
+		workspace.Projects[importer] = projectName(filepath.Join(root, filepath.FromSlash(importer)), importer)
+	}
+	if len(outside) > 0 {
+		log.Warn(fmt.Sprintf("The lock file has %d importers outside the %s patterns, regenerate it with pnpm install: %s",
+			len(outside), PnpmWorkspaceFileName, strings.Join(outside, ", ")))
+	}
+	return workspace, nil
+}
+
+// Includes reports whether a project directory, relative to the workspace root, matches the workspace patterns.
+// Patterns starting with ! exclude the directories they match.
+func (w *Workspace) Includes(dir string) bool {
+	included := false
+	for _, pattern := range w.Patterns {
+		if exclusion, excluded := strings.CutPrefix(pattern, "!"); excluded {
+			if matchWorkspacePattern(exclusion, dir) {
+				return false
+			}
+		} else if matchWorkspacePattern(pattern, dir) {
+			included = true
+		}
+	}
+	return included
+}
+
+// Describe names a project by its package name and importer path, e.g. @acme/web (apps/web)
+func (w *Workspace) Describe(importer string) string {
+	if w == nil {
+		return importer
+	}
+	if name := w.Projects[importer]; name != "" && name != importer {
+		return fmt.Sprintf("%s (%s)", name, importer)
+	}
+	return importer
+}
+
+// matchWorkspacePattern matches a directory against a workspace glob, where ** matches any number of directories
+func matchWorkspacePattern(pattern, dir string) bool {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(path.Clean("/"+pattern), "/"), "/")
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(path.Clean(dir), "/"))
+}
+
+func matchSegments(pattern, dir []string) bool {
+	if len(pattern) == 0 {
+		return len(dir) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(dir); i++ {
+			if matchSegments(pattern[1:], dir[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(dir) == 0 {
+		return false
+	}
+	if matched, err := path.Match(pattern[0], dir[0]); err != nil || !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], dir[1:])
+}
+
+// projectName reads the package name of a project, falling back to its importer path
+func projectName(dir, importer string) string {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return importer
+	}
+	var manifest struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil || manifest.Name == "" {
+		return importer
+	}
+	return manifest.Name
+}
+
+// ImportersOf maps each package of the tree to the importers pulling it in, directly or through other packages
+// and workspace projects linked with link: versions. Trees without recorded package dependencies only map the
+// direct dependencies.
+func (tree *DependencyTree) ImportersOf() map[string][]string {
+	pulledBy := make(map[string][]string)
+	for _, importer := range sortedKeys(tree.Importers) {
+		for name := range tree.importerClosure(importer) {
+			pulledBy[name] = append(pulledBy[name], importer)
+		}
+	}
+	return pulledBy
+}
+
+// importerClosure returns the packages an importer depends on, through the packages and the linked projects
+func (tree *DependencyTree) importerClosure(importer string) map[string]bool {
+	visitedImporters := map[string]bool{importer: true}
+	var queue []string
+	pending := []string{importer}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+		for name, version := range tree.Importers[current] {
+			if target, linked := strings.CutPrefix(version, "link:"); linked {
+				linkedImporter := path.Clean(path.Join(current, target))
+				if _, exists := tree.Importers[linkedImporter]; exists && !visitedImporters[linkedImporter] {
+					visitedImporters[linkedImporter] = true
+					pending = append(pending, linkedImporter)
+				}
+				continue
+			}
+			queue = append(queue, tree.dependencyName(name, version))
+			// The peers resolved for the dependency are installed with it
+			for peer := range extractIndirectDependencies(version) {
+				queue = append(queue, peer)
+			}
+		}
+	}
+	return tree.Closure(queue...)
+}
+
+// dependencyName returns the package a dependency resolves to, which is another package for aliases recorded as
+// <name>@<version>, e.g. string-width-cjs: string-width@4.2.3
+func (tree *DependencyTree) dependencyName(name, version string) string {
+	version = strings.SplitN(version, "(", 2)[0]
+	if alias, _ := ParsePackageKey(strings.TrimPrefix(version, "npm:")); alias != "" {
+		if _, exists := tree.Packages[alias]; exists {
+			return alias
+		}
+	}
+	return name
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadPnpmWorkspace(t *testing.T) {
+	root := filepath.Join("testdata", "pnpm-workspace")
+	tree, err := ParseLockFile(filepath.Join(root, "pnpm-lock.yaml"))
+	assert.NoError(t, err)
+
+	workspace, err := ReadPnpmWorkspace(root, tree.Importers)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		".":           "acme-monorepo",
+		"apps/web":    "@acme/web",
+		"packages/ui": "@acme/ui",
+		// Left by a removed project, without a package.json
+		"legacy/old": "legacy/old",
+	}, workspace.Projects)
+	assert.Equal(t, "@acme/web (apps/web)", workspace.Describe("apps/web"))
+	assert.Equal(t, "legacy/old", workspace.Describe("legacy/old"))
+
+	workspace, err = ReadPnpmWorkspace(t.TempDir(), tree.Importers)
+	assert.NoError(t, err)
+	assert.Nil(t, workspace)
+	assert.Equal(t, "apps/web", workspace.Describe("apps/web"))
+}
+
+func TestWorkspaceIncludes(t *testing.T) {
+	workspace := &Workspace{Patterns: []string{"apps/*", "./packages/**", "!**/*-test"}}
+	assert.True(t, workspace.Includes("apps/web"))
+	assert.False(t, workspace.Includes("apps/web/nested"))
+	assert.True(t, workspace.Includes("packages/ui"))
+	assert.True(t, workspace.Includes("packages/tools/lint"))
+	assert.False(t, workspace.Includes("packages/ui-test"))
+	assert.False(t, workspace.Includes("legacy/old"))
+}
+
+func TestImportersOf(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "pnpm-workspace", "pnpm-lock.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"loose-envify": "1.4.0", "react": "18.2.0"}, tree.Packages["react-dom"].Dependencies)
+
+	pulledBy := tree.ImportersOf()
+	// apps/web pulls in the dependencies of the packages/ui project it links
+	assert.Equal(t, []string{"apps/web", "packages/ui"}, pulledBy["react"])
+	assert.Equal(t, []string{"apps/web"}, pulledBy["react-dom"])
+	assert.Equal(t, []string{"apps/web", "legacy/old", "packages/ui"}, pulledBy["js-tokens"])
+	assert.Equal(t, []string{"apps/web", "packages/ui"}, pulledBy["string-width"])
+	assert.Equal(t, []string{"."}, pulledBy["typescript"])
+}
@@ -0,0 +1,320 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/internal/semver"
+	"gopkg.in/yaml.v3"
+)
+
+// Matches the protocol prefix of ranges such as file:../lib, workspace:*, patch:... or git+https://...
+var yarnProtocolPattern = regexp.MustCompile(`^[a-z][a-z0-9+.-]*:`)
+
+// yarnEntry represents a resolved package of a yarn.lock file
+type yarnEntry struct {
+	descriptors          []string
+	name                 string
+	version              string
+	resolution           map[string]interface{}
+	dependencies         map[string]string
+	optionalDependencies map[string]string
+	peerDependencies     map[string]string
+	optionalPeers        []string
+	workspace            string
+}
+
+// isBerryLock reports whether a yarn.lock was written by Yarn 2 or later, which use YAML with a __metadata entry
+func isBerryLock(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "__metadata:") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseYarnLockData parses a yarn.lock in the classic v1 format or the YAML based Berry format
+func parseYarnLockData(data []byte) (*DependencyTree, error) {
+	var entries []yarnEntry
+	var err error
+	if isBerryLock(data) {
+		entries, err = parseBerryEntries(data)
+	} else {
+		entries, err = parseClassicYarnEntries(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return yarnDependencyTree(entries), nil
+}
+
+// parseClassicYarnEntries reads the v1 format: unindented, comma separated descriptors ending with ':',
+// followed by indented `key value` fields and `dependencies:` sections of indented `name range` lines
+func parseClassicYarnEntries(data []byte) ([]yarnEntry, error) {
+	var entries []yarnEntry
+	var current *yarnEntry
+	var section map[string]string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), " \r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		switch {
+		case indent == 0:
+			if !strings.HasSuffix(line, ":") {
+				return nil, fmt.Errorf("yarn.lock line %d: expected package descriptors, got '%s'", lineNumber, line)
+			}
+			descriptors := splitYarnDescriptors(strings.TrimSuffix(line, ":"))
+			if len(descriptors) == 0 {
+				return nil, fmt.Errorf("yarn.lock line %d: expected package descriptors, got '%s'", lineNumber, line)
+			}
+			entries = append(entries, yarnEntry{descriptors: descriptors})
+			current = &entries[len(entries)-1]
+			section = nil
+		case current == nil:
+			return nil, fmt.Errorf("yarn.lock line %d: field outside of a package entry", lineNumber)
+		case indent == 2 && strings.HasSuffix(trimmed, ":"):
+			section = make(map[string]string)
+			switch strings.TrimSuffix(trimmed, ":") {
+			case "dependencies":
+				current.dependencies = section
+			case "optionalDependencies":
+				current.optionalDependencies = section
+			}
+		case indent == 2:
+			key, value := splitYarnField(trimmed)
+			section = nil
+			switch key {
+			case "version":
+				current.version = value
+			case "resolved":
+				current.resolution = setResolutionField(current.resolution, "tarball", value)
+			case "integrity":
+				current.resolution = setResolutionField(current.resolution, "integrity", value)
+			}
+		case section != nil:
+			key, value := splitYarnField(trimmed)
+			section[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading yarn.lock: %v", err)
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		name, versionRange := splitYarnDescriptor(entry.descriptors[0])
+		// Aliases such as string-width-cjs@npm:string-width@^4.2.0 install the aliased package
+		if strings.HasPrefix(versionRange, "npm:") {
+			name, _ = splitYarnDescriptor(strings.TrimPrefix(versionRange, "npm:"))
+		} else if yarnProtocolPattern.MatchString(versionRange) {
+			// Local, git and tarball URL dependencies aren't served by the registry
+			continue
+		}
+		entry.name = name
+	}
+	return entries, nil
+}
+
+// parseBerryEntries reads the YAML format of Yarn 2 and later. Only npm: resolutions are packages of the
+// registry; workspace: resolutions are the projects of the repository.
+func parseBerryEntries(data []byte) ([]yarnEntry, error) {
+	var lock map[string]map[string]interface{}
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("error parsing YAML: %v", err)
+	}
+
+	var entries []yarnEntry
+	for key, fields := range lock {
+		if key == "__metadata" {
+			continue
+		}
+		resolution, _ := fields["resolution"].(string)
+		name, reference := splitYarnDescriptor(resolution)
+		if name == "" {
+			return nil, fmt.Errorf("yarn.lock %s: invalid resolution '%s'", describeYAMLKeys([]string{key}, yamlKeyLines(data, "")), resolution)
+		}
+		entry := yarnEntry{
+			descriptors:          splitYarnDescriptors(key),
+			dependencies:         toStringMap(fields["dependencies"]),
+			optionalDependencies: optionalBerryDependencies(fields),
+			peerDependencies:     toStringMap(fields["peerDependencies"]),
+		}
+		switch {
+		case strings.HasPrefix(reference, "npm:"):
+			entry.name = name
+			entry.version = strings.TrimPrefix(reference, "npm:")
+		case strings.HasPrefix(reference, "workspace:"):
+			entry.workspace = strings.TrimPrefix(reference, "workspace:")
+		default:
+			continue
+		}
+		if checksum, ok := fields["checksum"].(string); ok {
+			entry.resolution = map[string]interface{}{"checksum": checksum}
+		}
+		if meta, ok := fields["peerDependenciesMeta"].(map[string]interface{}); ok {
+			for peer, value := range meta {
+				if peerFields, ok := value.(map[string]interface{}); ok && peerFields["optional"] == true {
+					entry.optionalPeers = append(entry.optionalPeers, peer)
+				}
+			}
+			sort.Strings(entry.optionalPeers)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// optionalBerryDependencies returns the dependencies Berry marks optional in dependenciesMeta
+func optionalBerryDependencies(fields map[string]interface{}) map[string]string {
+	dependencies := toStringMap(fields["dependencies"])
+	meta, ok := fields["dependenciesMeta"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	optional := make(map[string]string)
+	for name, value := range meta {
+		if metaFields, ok := value.(map[string]interface{}); ok && metaFields["optional"] == true && dependencies[name] != "" {
+			optional[name] = dependencies[name]
+		}
+	}
+	if len(optional) == 0 {
+		return nil
+	}
+	return optional
+}
+
+// yarnDependencyTree converts the entries into the tree pnpm lock files produce. Packages are keyed by name
+// as in pnpm trees; of several versions of a package, the highest is kept.
+func yarnDependencyTree(entries []yarnEntry) *DependencyTree {
+	resolved := make(map[string]string)
+	for _, entry := range entries {
+		if entry.name == "" {
+			continue
+		}
+		for _, descriptor := range entry.descriptors {
+			resolved[descriptor] = entry.version
+		}
+	}
+
+	packages := make(map[string]PackageInfo)
+	importers := make(map[string]map[string]string)
+	for _, entry := range entries {
+		if entry.workspace != "" {
+			importers[entry.workspace] = resolveYarnRanges(entry.dependencies, resolved)
+			continue
+		}
+		if entry.name == "" || entry.version == "" {
+			continue
+		}
+		if existing, exists := packages[entry.name]; exists && !isHigherVersion(entry.version, existing.Version) {
+			continue
+		}
+		optional := resolveYarnRanges(entry.optionalDependencies, resolved)
+		packages[entry.name] = PackageInfo{
+			Version:              entry.version,
+			Type:                 TypePackage,
+			Resolution:           entry.resolution,
+			OptionalDependencies: optional,
+			PeerDependencies:     entry.peerDependencies,
+			OptionalPeers:        entry.optionalPeers,
+			Dependencies:         mergeStringMaps(nil, resolveYarnRanges(entry.dependencies, resolved), optional),
+		}
+	}
+
+	tree := &DependencyTree{Packages: packages}
+	if len(importers) > 0 {
+		tree.Importers = importers
+	}
+	return tree
+}
+
+// resolveYarnRanges maps dependency ranges to the versions the lock file resolved them to
+func resolveYarnRanges(dependencies map[string]string, resolved map[string]string) map[string]string {
+	if len(dependencies) == 0 {
+		return nil
+	}
+	versions := make(map[string]string, len(dependencies))
+	for name, versionRange := range dependencies {
+		for _, descriptor := range []string{name + "@" + versionRange, name + "@npm:" + versionRange} {
+			if version, ok := resolved[descriptor]; ok {
+				versions[name] = version
+				break
+			}
+		}
+	}
+	return versions
+}
+
+// isHigherVersion compares two versions, falling back to their text for versions that aren't semver
+func isHigherVersion(version, than string) bool {
+	a, errA := semver.Parse(version)
+	b, errB := semver.Parse(than)
+	if errA != nil || errB != nil {
+		return version > than
+	}
+	return semver.Compare(a, b) > 0
+}
+
+// splitYarnDescriptors splits an entry key such as "@babel/core@^7.0.0", "@babel/core@^7.1.0"
+func splitYarnDescriptors(key string) []string {
+	var descriptors []string
+	for _, descriptor := range strings.Split(key, ",") {
+		if descriptor = strings.Trim(strings.TrimSpace(descriptor), `"`); descriptor != "" {
+			descriptors = append(descriptors, descriptor)
+		}
+	}
+	return descriptors
+}
+
+// splitYarnDescriptor splits name@range at the @ following the package name, so scoped names and ranges
+// containing @ are kept whole
+func splitYarnDescriptor(descriptor string) (string, string) {
+	at := strings.Index(strings.TrimPrefix(descriptor, "@"), "@")
+	if at < 0 {
+		return "", ""
+	}
+	if strings.HasPrefix(descriptor, "@") {
+		at++
+	}
+	return descriptor[:at], descriptor[at+1:]
+}
+
+// splitYarnField splits an indented `key value` line, unquoting both
+func splitYarnField(line string) (string, string) {
+	var key string
+	if strings.HasPrefix(line, `"`) {
+		end := strings.Index(line[1:], `"`)
+		if end < 0 {
+			return strings.Trim(line, `"`), ""
+		}
+		key, line = line[1:end+1], line[end+2:]
+	} else if space := strings.IndexAny(line, " \t"); space >= 0 {
+		key, line = line[:space], line[space:]
+	} else {
+		return line, ""
+	}
+	return key, strings.Trim(strings.TrimSpace(line), `"`)
+}
+
+func setResolutionField(resolution map[string]interface{}, key, value string) map[string]interface{} {
+	if resolution == nil {
+		resolution = make(map[string]interface{})
+	}
+	resolution[key] = value
+	return resolution
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClassicYarnLock(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "yarn-classic", "yarn.lock"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"@babel/code-frame", "@babel/highlight", "lodash", "string-width"}, sortedKeys(tree.Packages))
+	assert.Equal(t, "7.12.13", tree.Packages["@babel/code-frame"].Version)
+	assert.Equal(t, TypePackage, tree.Packages["@babel/code-frame"].Type)
+	assert.Equal(t, "https://registry.yarnpkg.com/@babel/highlight/-/highlight-7.13.10.tgz#a8b2a66148f5b27d666b15d81774347a731d52d1",
+		tree.Packages["@babel/highlight"].Resolution["tarball"])
+	// Of several versions of a package, the tree keeps the highest
+	assert.Equal(t, "4.17.21", tree.Packages["lodash"].Version)
+	assert.Equal(t, "4.2.3", tree.Packages["string-width"].Version)
+	assert.Nil(t, tree.Importers)
+}
+
+func TestParseBerryYarnLock(t *testing.T) {
+	tree, err := ParseLockFile(filepath.Join("testdata", "yarn-berry", "yarn.lock"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"@esbuild/linux-x64", "esbuild", "react-dom"}, sortedKeys(tree.Packages))
+	assert.Equal(t, map[string]string{"@esbuild/linux-x64": "0.19.12"}, tree.Packages["esbuild"].OptionalDependencies)
+	assert.Equal(t, map[string]string{"@esbuild/linux-x64": "0.19.12"}, tree.Packages["esbuild"].Dependencies)
+	assert.Equal(t, map[string]string{"react": "^18.2.0"}, tree.Packages["react-dom"].PeerDependencies)
+	assert.Equal(t, map[string]map[string]string{".": {"esbuild": "0.19.12", "react-dom": "18.2.0"}}, tree.Importers)
+}
+
+func TestParseClassicYarnLockErrors(t *testing.T) {
+	_, err := parseYarnLockData([]byte("  version \"1.0.0\"\n"))
+	assert.EqualError(t, err, "yarn.lock line 1: field outside of a package entry")
+
+	_, err = parseYarnLockData([]byte("lodash@^4.17.21\n"))
+	assert.EqualError(t, err, "yarn.lock line 1: expected package descriptors, got 'lodash@^4.17.21'")
+}
+
+func TestSplitYarnDescriptor(t *testing.T) {
+	name, versionRange := splitYarnDescriptor("@babel/core@npm:^7.0.0")
+	assert.Equal(t, "@babel/core", name)
+	assert.Equal(t, "npm:^7.0.0", versionRange)
+
+	name, versionRange = splitYarnDescriptor("string-width-cjs@npm:string-width@^4.2.0")
+	assert.Equal(t, "string-width-cjs", name)
+	assert.Equal(t, "npm:string-width@^4.2.0", versionRange)
+
+	name, _ = splitYarnDescriptor("lodash")
+	assert.Empty(t, name)
+}
+
+func FuzzParseYarnLockData(f *testing.F) {
+	for _, path := range []string{filepath.Join("testdata", "yarn-classic", "yarn.lock"), filepath.Join("testdata", "yarn-berry", "yarn.lock")} {
+		seed, err := os.ReadFile(path)
+		assert.NoError(f, err)
+		f.Add(seed)
+	}
+	f.Add([]byte("\"\":\n  version \"1.0.0\"\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tree, err := parseYarnLockData(data)
+		if err != nil {
+			return
+		}
+		for name, info := range tree.Packages {
+			if name == "" || info.Version == "" {
+				t.Fatalf("package with an empty name or version: '%s' '%s'", name, info.Version)
+			}
+		}
+	})
+}
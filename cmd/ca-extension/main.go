@@ -0,0 +1,12 @@
+package main
+
+import (
+	"github.com/jfrog/jfrog-cli-core/v2/plugins"
+	"github.com/jfrog/jfrog-cli-plugin-template/commands"
+)
+
+// The standalone entry point, for running ca-extension without JFrog CLI. It drives the exact same
+// app as the plugin, only the binary name differs.
+func main() {
+	plugins.PluginMain(commands.GetApp())
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/commands"
+)
+
+// Generates the man pages and the JSON CLI specification of ca-extension from the command definitions, for
+// packaging and documentation tooling. Run by go generate from the repository root:
+//
+//	go generate ./...
+//
+// which writes dist/man/*.1 and dist/cli-spec.json.
+func main() {
+	out := flag.String("out", "dist", "Directory to write the man pages and the CLI specification to")
+	flag.Parse()
+	if err := generate(*out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate(out string) error {
+	spec := commands.NewCLISpec(commands.GetApp())
+	manDir := filepath.Join(out, "man")
+	if err := os.MkdirAll(manDir, 0755); err != nil {
+		return fmt.Errorf("error creating %s: %v", manDir, err)
+	}
+	for name, page := range spec.ManPages() {
+		if err := ioutil.WriteFile(filepath.Join(manDir, name), []byte(page), 0644); err != nil {
+			return fmt.Errorf("error writing man page %s: %v", name, err)
+		}
+	}
+
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+	specPath := filepath.Join(out, "cli-spec.json")
+	if err := ioutil.WriteFile(specPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %v", specPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Default targets of the release binaries, as GOOS/GOARCH
+const defaultTargets = "linux/amd64,linux/arm64,darwin/amd64,darwin/arm64,windows/amd64,windows/arm64"
+
+// Builds the release binaries of ca-extension for every target, and a SHA256SUMS file of them. Builds are
+// reproducible: paths are trimmed, cgo is off and the build ID is only derived from the inputs, so the same
+// commit and Go version produce the same bytes on any machine. The toolchain embeds the VCS revision, commit time
+// and build settings, which 'ca-extension version --json' prints. Run from the repository root:
+//
+//	go run ./cmd/release -version v1.1.0
+//
+// which writes dist/release/ca-extension-<os>-<arch>[.exe] and dist/release/SHA256SUMS.
+func main() {
+	out := flag.String("out", filepath.Join("dist", "release"), "Directory to write the binaries and their checksums to")
+	version := flag.String("version", "", "Version to embed, e.g. v1.1.0. Defaults to the version of the source")
+	targets := flag.String("targets", defaultTargets, "Comma separated GOOS/GOARCH targets to build")
+	tags := flag.String("tags", "", "Comma separated build tags, e.g. fips")
+	flag.Parse()
+	if err := release(*out, *version, *targets, *tags); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func release(out, version, targets, tags string) error {
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return fmt.Errorf("error creating %s: %v", out, err)
+	}
+	sums := make(map[string]string)
+	for _, target := range strings.Split(targets, ",") {
+		goos, goarch, found := strings.Cut(strings.TrimSpace(target), "/")
+		if !found || goos == "" || goarch == "" {
+			return fmt.Errorf("invalid target '%s'. Expected GOOS/GOARCH", target)
+		}
+		name := fmt.Sprintf("ca-extension-%s-%s", goos, goarch)
+		if goos == "windows" {
+			name += ".exe"
+		}
+		binary := filepath.Join(out, name)
+		if err := build(binary, goos, goarch, version, tags); err != nil {
+			return err
+		}
+		sum, err := fileSHA256(binary)
+		if err != nil {
+			return err
+		}
+		sums[name] = sum
+		fmt.Println(sum, binary)
+	}
+	return writeChecksums(filepath.Join(out, "SHA256SUMS"), sums)
+}
+
+func build(binary, goos, goarch, version, tags string) error {
+	ldflags := "-s -w -buildid="
+	if version != "" {
+		ldflags += " -X github.com/jfrog/jfrog-cli-plugin-template/commands.appVersion=" + version
+	}
+	args := []string{"build", "-trimpath", "-buildvcs=true", "-ldflags=" + ldflags, "-o", binary}
+	if tags != "" {
+		args = append(args, "-tags="+tags)
+	}
+	cmd := exec.Command("go", append(args, "./cmd/ca-extension")...)
+	// Settings of the environment would otherwise end up in the binaries
+	cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch, "CGO_ENABLED=0", "GOFLAGS=-mod=readonly")
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error building %s/%s: %v", goos, goarch, err)
+	}
+	return nil
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("error hashing %s: %v", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writeChecksums writes the sums in the format of sha256sum, which 'sha256sum -c SHA256SUMS' verifies
+func writeChecksums(path string, sums map[string]string) error {
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var lines strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&lines, "%s  %s\n", sums[name], name)
+	}
+	if err := os.WriteFile(path, []byte(lines.String()), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+const (
+	accessibleFlag = "accessible"
+	accessibleEnv  = "CA_EXTENSION_ACCESSIBLE"
+)
+
+// statusWords maps the icons statuses start with to the words the accessible mode prints instead, as icons are
+// read out inconsistently by screen readers, or not at all
+var statusWords = []struct {
+	icon string
+	word string
+}{
+	{"✅", "[OK]"},
+	{"❌", "[FAIL]"},
+	{"⚠️", "[WARNING]"},
+	{"⏳", "[PENDING]"},
+	{"⏱️", "[TIMEOUT]"},
+	{"➖", "[SKIPPED]"},
+	{"➡️", "[UPGRADE]"},
+}
+
+func getAccessibleFlag() components.Flag {
+	return components.NewBoolFlag(
+		accessibleFlag,
+		"Print status words such as [OK] and [FAIL] instead of icons, and no live progress line, for screen readers. Also set by "+accessibleEnv+"=true",
+		components.WithBoolDefaultValue(false),
+	)
+}
+
+func isAccessible(c *components.Context) bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(accessibleEnv))
+	return enabled || c.GetBoolFlagValue(accessibleFlag)
+}
+
+// status returns a status for the console, replacing its leading icon with a status word in the accessible mode
+func (f *displayFormat) status(status string) string {
+	if !f.accessible {
+		return status
+	}
+	for _, sw := range statusWords {
+		if rest, found := strings.CutPrefix(status, sw.icon); found {
+			return sw.word + rest
+		}
+	}
+	return status
+}
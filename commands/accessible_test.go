@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayFormatStatus(t *testing.T) {
+	display := newDisplayFormat(true)
+	assert.Equal(t, "❌ Blocked (403 Forbidden)", display.status("❌ Blocked (403 Forbidden)"))
+
+	display.accessible = true
+	assert.Equal(t, "[FAIL] Blocked (403 Forbidden)", display.status("❌ Blocked (403 Forbidden)"))
+	assert.Equal(t, "[OK] Available in NPM Registry", display.status("✅ Available in NPM Registry"))
+	assert.Equal(t, "[WARNING] Unexpected Response: 500", display.status("⚠️ Unexpected Response: 500"))
+	assert.Equal(t, "[TIMEOUT] Timed Out", display.status(audit.StatusTimedOut))
+	assert.Equal(t, "[UPGRADE] 4.17.21", display.status("➡️ 4.17.21"))
+	assert.Equal(t, "[SKIPPED]", display.status("➖"))
+	assert.Equal(t, "Unknown", display.status("Unknown"))
+}
+
+func TestRenderReportTextAccessible(t *testing.T) {
+	report := newAuditReport("pnpm-lock.yaml", []audit.AuditResult{
+		{Name: "yallist", Version: "4.0.0", Type: "package", Status: "❌ Blocked (403 Forbidden)", StatusCode: http.StatusForbidden},
+	})
+	display := newDisplayFormat(true)
+	display.accessible = true
+	output, err := renderReport(report, formatText, display)
+	assert.NoError(t, err)
+	assert.Equal(t, "[1/1] yallist@4.0.0 (package) [FAIL] Blocked (403 Forbidden)\n\npnpm-lock.yaml: 1 packages, 1 blocked\n", output)
+
+	// JSON outputs keep the statuses as recorded
+	output, err = renderReport(report, formatJSON, display)
+	assert.NoError(t, err)
+	assert.Contains(t, output, "❌ Blocked (403 Forbidden)")
+}
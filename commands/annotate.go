@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	setFlag   = "set"
+	unsetFlag = "unset"
+)
+
+// resultAnnotation is a key/value annotation of a result, or its removal when value is empty
+type resultAnnotation struct {
+	name    string
+	version string
+	key     string
+	value   string
+}
+
+func GetAnnotateCommand() components.Command {
+	return components.Command{
+		Name:        "annotate",
+		Description: "Sets or removes key/value annotations of the results stored by 'audit --output', such as triage states.",
+		Arguments:   getAnnotateArguments(),
+		Flags:       getAnnotateFlags(),
+		Action: func(c *components.Context) error {
+			return annotateCmd(c)
+		},
+	}
+}
+
+func getAnnotateArguments() []components.Argument {
+	return []components.Argument{
+		{
+			Name:        "results-file",
+			Description: "The audit results JSON file, updated in place.",
+		},
+	}
+}
+
+func getAnnotateFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			setFlag,
+			"Comma separated annotations to set, as <name>@<version>:<key>=<value>, e.g. react@18.2.0:triaged=true",
+			components.WithHelpValue("annotations"),
+		),
+		components.NewStringFlag(
+			unsetFlag,
+			"Comma separated annotations to remove, as <name>@<version>:<key>",
+			components.WithHelpValue("annotations"),
+		),
+	}
+}
+
+func annotateCmd(c *components.Context) error {
+	if len(c.Arguments) != 1 {
+		return wrongArguments("annotate", "<results-file>", len(c.Arguments))
+	}
+	annotations, err := parseAnnotations(c.GetStringFlagValue(setFlag), true)
+	if err != nil {
+		return err
+	}
+	removals, err := parseAnnotations(c.GetStringFlagValue(unsetFlag), false)
+	if err != nil {
+		return err
+	}
+	annotations = append(annotations, removals...)
+	if len(annotations) == 0 {
+		return fmt.Errorf("nothing to annotate, set --%s or --%s", setFlag, unsetFlag)
+	}
+
+	path := c.Arguments[0]
+	report, err := loadAuditReport(path)
+	if err != nil {
+		return err
+	}
+	if err := applyAnnotations(report, annotations); err != nil {
+		return err
+	}
+	if err := writeAuditReport(report, path); err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("Updated %d annotations of %s", len(annotations), path))
+	return nil
+}
+
+// parseAnnotations reads comma separated <name>@<version>:<key>=<value> annotations, or <name>@<version>:<key>
+// removals. The key follows the last colon before the value, as Maven package names have colons too.
+func parseAnnotations(value string, withValues bool) ([]resultAnnotation, error) {
+	expected := "<name>@<version>:<key>"
+	if withValues {
+		expected += "=<value>"
+	}
+	var annotations []resultAnnotation
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		target, annotationValue := item, ""
+		if withValues {
+			var found bool
+			if target, annotationValue, found = strings.Cut(item, "="); !found || annotationValue == "" {
+				return nil, fmt.Errorf("invalid annotation '%s'. Expected %s", item, expected)
+			}
+		}
+		keySeparator := strings.LastIndex(target, ":")
+		if keySeparator < 0 {
+			return nil, fmt.Errorf("invalid annotation '%s'. Expected %s", item, expected)
+		}
+		pkg, key := target[:keySeparator], target[keySeparator+1:]
+		versionSeparator := strings.LastIndex(pkg, "@")
+		if versionSeparator <= 0 || versionSeparator == len(pkg)-1 || key == "" {
+			return nil, fmt.Errorf("invalid annotation '%s'. Expected %s", item, expected)
+		}
+		annotations = append(annotations, resultAnnotation{
+			name:    pkg[:versionSeparator],
+			version: pkg[versionSeparator+1:],
+			key:     key,
+			value:   annotationValue,
+		})
+	}
+	return annotations, nil
+}
+
+// applyAnnotations sets or removes the annotations of the results they name, failing on packages the results don't
+// have, so typos don't go unnoticed
+func applyAnnotations(report *AuditReport, annotations []resultAnnotation) error {
+	for _, annotation := range annotations {
+		found := false
+		for i := range report.Results {
+			entry := &report.Results[i]
+			if entry.Name != annotation.name || entry.Version != annotation.version {
+				continue
+			}
+			found = true
+			if annotation.value == "" {
+				delete(entry.Annotations, annotation.key)
+				if len(entry.Annotations) == 0 {
+					entry.Annotations = nil
+				}
+				continue
+			}
+			if entry.Annotations == nil {
+				entry.Annotations = make(map[string]string)
+			}
+			entry.Annotations[annotation.key] = annotation.value
+		}
+		if !found {
+			return fmt.Errorf("%s@%s is not in the results", annotation.name, annotation.version)
+		}
+	}
+	report.SchemaVersion = outputSchemaVersion
+	return nil
+}
+
+// carryAnnotations copies the annotations of the results previously stored at a path to the results of the same
+// package versions, so re-running an audit over its results file keeps their triage
+func carryAnnotations(report *AuditReport, previousPath string) {
+	if _, err := os.Stat(previousPath); err != nil {
+		return
+	}
+	previous, err := loadAuditReport(previousPath)
+	if err != nil {
+		log.Warn("Could not keep the annotations of the previous results:", err.Error())
+		return
+	}
+	annotations := make(map[string]map[string]string)
+	for _, entry := range previous.Results {
+		if len(entry.Annotations) > 0 {
+			annotations[entry.Name+"@"+entry.Version] = entry.Annotations
+		}
+	}
+	for i := range report.Results {
+		entry := &report.Results[i]
+		if kept := annotations[entry.Name+"@"+entry.Version]; kept != nil && entry.Annotations == nil {
+			entry.Annotations = kept
+		}
+	}
+}
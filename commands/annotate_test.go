@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	annotations, err := parseAnnotations("@babel/core@7.24.0:triaged=true, org.apache:commons@1.2:owner=team=web", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []resultAnnotation{
+		{name: "@babel/core", version: "7.24.0", key: "triaged", value: "true"},
+		{name: "org.apache:commons", version: "1.2", key: "owner", value: "team=web"},
+	}, annotations)
+
+	removals, err := parseAnnotations("lodash@4.17.20:triaged", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []resultAnnotation{{name: "lodash", version: "4.17.20", key: "triaged"}}, removals)
+
+	for _, invalid := range []string{"lodash@4.17.20:triaged", "lodash:triaged=true", "lodash@:triaged=true", "lodash@4.17.20:=true", "lodash@4.17.20:triaged="} {
+		_, err := parseAnnotations(invalid, true)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestApplyAnnotations(t *testing.T) {
+	report := &AuditReport{SchemaVersion: "1.2", Results: []ResultEntry{{Name: "lodash", Version: "4.17.20"}, {Name: "react", Version: "18.2.0"}}}
+	assert.NoError(t, applyAnnotations(report, []resultAnnotation{
+		{name: "lodash", version: "4.17.20", key: "triaged", value: "true"},
+		{name: "lodash", version: "4.17.20", key: "owner", value: "web"},
+	}))
+	assert.Equal(t, map[string]string{"triaged": "true", "owner": "web"}, report.Results[0].Annotations)
+	assert.Nil(t, report.Results[1].Annotations)
+	assert.Equal(t, outputSchemaVersion, report.SchemaVersion)
+
+	assert.NoError(t, applyAnnotations(report, []resultAnnotation{{name: "lodash", version: "4.17.20", key: "owner"}}))
+	assert.Equal(t, map[string]string{"triaged": "true"}, report.Results[0].Annotations)
+
+	err := applyAnnotations(report, []resultAnnotation{{name: "lodash", version: "4.17.21", key: "triaged", value: "true"}})
+	assert.EqualError(t, err, "lodash@4.17.21 is not in the results")
+}
+
+func TestCarryAnnotations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	previous := &AuditReport{SchemaVersion: outputSchemaVersion, Results: []ResultEntry{
+		{Name: "lodash", Version: "4.17.20", Annotations: map[string]string{"triaged": "true"}},
+		{Name: "react", Version: "18.1.0", Annotations: map[string]string{"triaged": "true"}},
+	}}
+	assert.NoError(t, writeAuditReport(previous, path))
+
+	report := &AuditReport{Results: []ResultEntry{{Name: "lodash", Version: "4.17.20"}, {Name: "react", Version: "18.2.0"}}}
+	carryAnnotations(report, path)
+	assert.Equal(t, map[string]string{"triaged": "true"}, report.Results[0].Annotations)
+	assert.Nil(t, report.Results[1].Annotations)
+
+	carryAnnotations(report, filepath.Join(t.TempDir(), "missing.json"))
+}
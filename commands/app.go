@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+const appName = "ca-extension"
+
+// appVersion is set by release builds, with -ldflags "-X github.com/jfrog/jfrog-cli-plugin-template/commands.appVersion=<version>"
+var appVersion = "v1.0.0"
+
+// GetApp returns the application shared by the JFrog CLI plugin and the standalone ca-extension binary,
+// so both entry points always expose the same commands.
+func GetApp() components.App {
+	app := components.App{}
+	app.Name = appName
+	app.Description = "Curation Audit Extension to unofficially support new package managers."
+	app.Version = appVersion
+	app.Commands = withCrashBundles(withIntegrityChecks(withUsageExamples("", GetCommands())))
+	app.Subcommands = GetNamespaces()
+	for i := range app.Subcommands {
+		app.Subcommands[i].Commands = withCrashBundles(withIntegrityChecks(withUsageExamples(app.Subcommands[i].Name, app.Subcommands[i].Commands)))
+	}
+	return app
+}
+
+func GetCommands() []components.Command {
+	return []components.Command{
+		GetAuditCommand(),
+		GetCheckCommand(),
+		GetCanIAddCommand(),
+		GetDiffCommand(),
+		GetStatsCommand(),
+		GetReportCommand(),
+		GetAnnotateCommand(),
+		GetServeCommand(),
+		GetProxyCommand(),
+		GetDaemonCommand(),
+		GetConfigCommand(),
+		GetDoctorCommand(),
+		GetExamplesCommand(),
+		GetVersionCommand(),
+	}
+}
+
+func GetNamespaces() []components.Namespace {
+	return []components.Namespace{
+		GetTreeNamespace(),
+		GetProfileNamespace(),
+		GetClientNamespace(),
+		GetStateNamespace(),
+	}
+}
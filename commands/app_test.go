@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppConverts(t *testing.T) {
+	_, err := components.ConvertApp(GetApp())
+	assert.NoError(t, err)
+}
+
+func TestCommandNamesAreUnique(t *testing.T) {
+	seen := make(map[string]string)
+	for _, cmd := range GetCommands() {
+		assert.NotNil(t, cmd.Action, cmd.Name)
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			other, exists := seen[name]
+			assert.False(t, exists, "%s is used by both %s and %s", name, other, cmd.Name)
+			seen[name] = cmd.Name
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+const (
+	ociPushFlag  = "oci-push"
+	ociTokenFlag = "oci-token"
+	ociTokenEnv  = "CA_EXTENSION_OCI_TOKEN"
+
+	auditArtifactType  = "application/vnd.ca-extension.audit.v1"
+	reportMediaType    = "application/vnd.ca-extension.report.v1+json"
+	treeMediaType      = "application/vnd.ca-extension.tree.v1+json"
+	cycloneDXMediaType = "application/vnd.cyclonedx+json"
+	spdxMediaType      = "application/spdx+json"
+	ociEmptyMediaType  = "application/vnd.oci.empty.v1+json"
+
+	lockFileDigestAnnotation = "dev.ca-extension.lockfile.digest"
+)
+
+func getOCIPushFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			ociPushFlag,
+			"Push the audit report, dependency tree and SBOM as an OCI artifact to oci://<host>/<repository>, tagged with the lock file digest unless a tag is given",
+			components.WithHelpValue("ref"),
+		),
+		components.NewStringFlag(
+			ociTokenFlag,
+			"Token of the registry --"+ociPushFlag+" pushes to. The access token is only sent to the host of the Artifactory registry",
+			components.WithHelpValue("token"),
+		),
+	}
+}
+
+func getOCIPushEnvVars() []components.EnvVar {
+	return []components.EnvVar{{
+		Name:        ociTokenEnv,
+		Description: "Token of the registry --" + ociPushFlag + " pushes to, used when --" + ociTokenFlag + " is not set.",
+	}}
+}
+
+// parseOCIPushReference validates the --oci-push reference before the audit runs
+func parseOCIPushReference(ref string) (*ociReference, error) {
+	if ref == "" {
+		return nil, nil
+	}
+	parsed, err := parseOCIRepository(ref)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// ociPushToken returns the token to push to the registry of ref with: the --oci-token, else the access token of the
+// registry when ref is on the same host, else none, leaving the registry to its anonymous token challenge. The access
+// token is never sent to another host, which could be any registry, or a mistyped one.
+func ociPushToken(token string, ref ociReference, registry *registryConfiguration) string {
+	if token != "" || registry == nil || registry.accessToken == "" {
+		return token
+	}
+	parsed, err := url.Parse(registry.registryURL)
+	if err != nil || !strings.EqualFold(parsed.Host, ref.host) {
+		return ""
+	}
+	return registry.accessToken
+}
+
+// lockFileTag returns the tag of the lock file digest. Tags can't contain ':', so sha256:<hex> becomes sha256-<hex>.
+func lockFileTag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1)
+}
+
+// pushAuditArtifact pushes the report, the dependency tree and the SBOM, when one was written, as the layers of an OCI
+// artifact, so the curation outcome of a lock file can be stored next to the images built from it. It returns the
+// pushed reference.
+func pushAuditArtifact(ref ociReference, token, algorithm, lockFile string, report *AuditReport, treePath string, sbom *sbomConfiguration) (string, error) {
+	lockData, err := ioutil.ReadFile(lockFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %v", lockFile, err)
+	}
+	lockDigest := digestOf(algorithm, lockData)
+	if ref.reference == "" {
+		ref.reference = lockFileTag(lockDigest)
+	}
+
+	reportData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	treeData, err := ioutil.ReadFile(treePath)
+	if err != nil {
+		return "", fmt.Errorf("error reading dependency tree: %v", err)
+	}
+
+	client := newOCIClient(ref, token, algorithm)
+	client.tokenFlag = ociTokenFlag
+	config, err := client.pushBlob(ociEmptyMediaType, []byte("{}"))
+	if err != nil {
+		return "", err
+	}
+	manifest := &ociManifest{
+		SchemaVersion: 2,
+		MediaType:     ociManifestMediaType,
+		ArtifactType:  auditArtifactType,
+		Config:        config,
+		Annotations: map[string]string{
+			"org.opencontainers.image.created": time.Now().UTC().Format(time.RFC3339),
+			lockFileDigestAnnotation:           lockDigest,
+		},
+	}
+	if report.Metadata != nil {
+		if report.Metadata.Commit != "" {
+			manifest.Annotations["org.opencontainers.image.revision"] = report.Metadata.Commit
+		}
+		if report.Metadata.Repository != "" {
+			manifest.Annotations["org.opencontainers.image.source"] = report.Metadata.Repository
+		}
+	}
+
+	layers := []struct {
+		mediaType string
+		title     string
+		data      []byte
+	}{
+		{reportMediaType, "curation-report.json", reportData},
+		{treeMediaType, filepath.Base(treePath), treeData},
+	}
+	if sbom != nil {
+		sbomData, err := ioutil.ReadFile(sbom.output)
+		if err != nil {
+			return "", fmt.Errorf("error reading SBOM: %v", err)
+		}
+		mediaType := cycloneDXMediaType
+		if sbom.format == formatSPDX {
+			mediaType = spdxMediaType
+		}
+		layers = append(layers, struct {
+			mediaType string
+			title     string
+			data      []byte
+		}{mediaType, filepath.Base(sbom.output), sbomData})
+	}
+	for _, layer := range layers {
+		descriptor, err := client.pushBlob(layer.mediaType, layer.data)
+		if err != nil {
+			return "", err
+		}
+		descriptor.Annotations = map[string]string{"org.opencontainers.image.title": layer.title}
+		manifest.Layers = append(manifest.Layers, descriptor)
+	}
+
+	digest, err := client.pushManifest(manifest)
+	if err != nil {
+		return "", err
+	}
+	return ref.String() + "@" + digest, nil
+}
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeOCIRegistry serves the blob upload and manifest endpoints of the distribution API from memory
+func newFakeOCIRegistry(t *testing.T) (*httptest.Server, map[string][]byte) {
+	var mu sync.Mutex
+	stored := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case r.Method == "HEAD" && strings.Contains(r.URL.Path, "/blobs/"):
+			if _, ok := stored[r.URL.Path]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/blobs/uploads/"):
+			w.Header().Set("Location", "/upload/1?state=abc")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == "PUT" && r.URL.Path == "/upload/1":
+			assert.Equal(t, "abc", r.URL.Query().Get("state"))
+			digest := r.URL.Query().Get("digest")
+			assert.Equal(t, digestOf(digestSHA256, body), digest)
+			stored["/v2/curation/reports/blobs/"+digest] = body
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "PUT" && strings.Contains(r.URL.Path, "/manifests/"):
+			assert.Equal(t, ociManifestMediaType, r.Header.Get("Content-Type"))
+			stored[r.URL.Path] = body
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server, stored
+}
+
+func TestPushAuditArtifactTagsLockFileDigest(t *testing.T) {
+	server, stored := newFakeOCIRegistry(t)
+	defer server.Close()
+
+	dir := t.TempDir()
+	lockFile := filepath.Join(dir, "pnpm-lock.yaml")
+	treePath := filepath.Join(dir, "pnpm_dependency_tree.json")
+	assert.NoError(t, os.WriteFile(lockFile, []byte("lockfileVersion: '9.0'\n"), 0644))
+	assert.NoError(t, os.WriteFile(treePath, []byte("{}\n"), 0644))
+
+	ref, err := parseOCIPushReference("oci://" + strings.TrimPrefix(server.URL, "http://") + "/curation/reports")
+	assert.NoError(t, err)
+	report := newAuditReport(lockFile, []audit.AuditResult{{Name: "lodash", Version: "4.17.21", StatusCode: http.StatusOK}})
+	report.Metadata = &RunMetadata{ToolVersion: appVersion, Commit: "0123abcd"}
+
+	pushed, err := pushAuditArtifact(*ref, "", digestSHA256, lockFile, report, treePath, nil)
+	assert.NoError(t, err)
+
+	tag := lockFileTag(digestOf(digestSHA256, []byte("lockfileVersion: '9.0'\n")))
+	assert.True(t, strings.HasPrefix(tag, "sha256-"))
+	manifestData := stored["/v2/curation/reports/manifests/"+tag]
+	assert.NotNil(t, manifestData)
+	assert.True(t, strings.HasSuffix(pushed, ":"+tag+"@"+digestOf(digestSHA256, manifestData)))
+
+	var manifest ociManifest
+	assert.NoError(t, json.Unmarshal(manifestData, &manifest))
+	assert.Equal(t, auditArtifactType, manifest.ArtifactType)
+	assert.Equal(t, ociEmptyMediaType, manifest.Config.MediaType)
+	assert.Equal(t, "0123abcd", manifest.Annotations["org.opencontainers.image.revision"])
+	assert.Len(t, manifest.Layers, 2)
+	assert.Equal(t, reportMediaType, manifest.Layers[0].MediaType)
+	assert.Equal(t, treeMediaType, manifest.Layers[1].MediaType)
+	assert.Equal(t, "{}\n", string(stored["/v2/curation/reports/blobs/"+manifest.Layers[1].Digest]))
+}
+
+func TestPushAuditArtifactSBOMLayer(t *testing.T) {
+	server, stored := newFakeOCIRegistry(t)
+	defer server.Close()
+
+	dir := t.TempDir()
+	lockFile := filepath.Join(dir, "pnpm-lock.yaml")
+	treePath := filepath.Join(dir, "pnpm_dependency_tree.json")
+	sbomPath := filepath.Join(dir, "bom.spdx.json")
+	assert.NoError(t, os.WriteFile(lockFile, []byte("lockfileVersion: '9.0'\n"), 0644))
+	assert.NoError(t, os.WriteFile(treePath, []byte("{}\n"), 0644))
+	assert.NoError(t, os.WriteFile(sbomPath, []byte(`{"spdxVersion":"SPDX-2.3"}`), 0644))
+
+	ref, err := parseOCIPushReference("oci://" + strings.TrimPrefix(server.URL, "http://") + "/curation/reports:release")
+	assert.NoError(t, err)
+	report := newAuditReport(lockFile, nil)
+	_, err = pushAuditArtifact(*ref, "", digestSHA256, lockFile, report, treePath, &sbomConfiguration{format: formatSPDX, output: sbomPath})
+	assert.NoError(t, err)
+
+	var manifest ociManifest
+	assert.NoError(t, json.Unmarshal(stored["/v2/curation/reports/manifests/release"], &manifest))
+	assert.Len(t, manifest.Layers, 3)
+	assert.Equal(t, spdxMediaType, manifest.Layers[2].MediaType)
+	assert.Equal(t, "bom.spdx.json", manifest.Layers[2].Annotations["org.opencontainers.image.title"])
+	assert.Equal(t, `{"spdxVersion":"SPDX-2.3"}`, string(stored["/v2/curation/reports/blobs/"+manifest.Layers[2].Digest]))
+
+	_, err = pushAuditArtifact(*ref, "", digestSHA256, lockFile, report, treePath, &sbomConfiguration{format: formatCycloneDX, output: filepath.Join(dir, "missing.cdx.json")})
+	assert.ErrorContains(t, err, "error reading SBOM")
+}
+
+func TestOCIPushToken(t *testing.T) {
+	registry := &registryConfiguration{registryURL: "https://acme.jfrog.io/artifactory/api/npm/npm-curated", accessToken: "jfrog-token"}
+	artifactory := ociReference{host: "acme.jfrog.io", repository: "curation/reports"}
+	assert.Equal(t, "jfrog-token", ociPushToken("", artifactory, registry))
+	assert.Equal(t, "ghcr-token", ociPushToken("ghcr-token", artifactory, registry))
+
+	// The access token isn't sent to other registries, nor to hosts that merely look like Artifactory's
+	for _, host := range []string{"ghcr.io", "acme.jfrog.io.evil.com", "acme.jfrog.io:8443"} {
+		assert.Empty(t, ociPushToken("", ociReference{host: host, repository: "curation/reports"}, registry), host)
+	}
+	assert.Equal(t, "ghcr-token", ociPushToken("ghcr-token", ociReference{host: "ghcr.io", repository: "acme/reports"}, registry))
+	assert.Empty(t, ociPushToken("", artifactory, nil))
+}
+
+func TestParseOCIPushReference(t *testing.T) {
+	ref, err := parseOCIPushReference("oci://acme.jfrog.io/curation/reports:release")
+	assert.NoError(t, err)
+	assert.Equal(t, "oci://acme.jfrog.io/curation/reports:release", ref.String())
+
+	ref, err = parseOCIPushReference("")
+	assert.NoError(t, err)
+	assert.Nil(t, ref)
+
+	_, err = parseOCIPushReference("acme.jfrog.io/curation/reports")
+	assert.Error(t, err)
+}
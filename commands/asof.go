@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const asOfFlag = "as-of"
+
+// PublicationEntry represents whether an audited version was published at the --as-of date
+type PublicationEntry struct {
+	Name        string     `json:"name"`
+	Version     string     `json:"version"`
+	PublishedAt *time.Time `json:"publishedAt,omitempty"`
+	Existed     bool       `json:"existed"`
+}
+
+// PublicationReport represents the versions of an audit evaluated against a past date
+type PublicationReport struct {
+	AsOf     time.Time          `json:"asOf"`
+	Existed  int                `json:"existed"`
+	Missing  int                `json:"missing"`
+	Packages []PublicationEntry `json:"packages"`
+}
+
+// parseAsOf accepts a date such as 2024-03-01, taken as the end of that day in UTC, or an RFC 3339 time
+func parseAsOf(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		endOfDay := date.Add(24*time.Hour - time.Nanosecond)
+		return &endOfDay, nil
+	}
+	moment, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s date '%s'. Expected YYYY-MM-DD or an RFC 3339 time", asOfFlag, value)
+	}
+	return &moment, nil
+}
+
+// evaluateAsOf reads the publish times of the audited versions from the registry metadata and reports which
+// existed at asOf. Versions without a publish time are reported as missing, they can't be shown to have existed.
+func evaluateAsOf(results []audit.AuditResult, asOf time.Time, registry *registryConfiguration, numWorkers int) *PublicationReport {
+	names := make(map[string][]string)
+	for _, result := range results {
+		names[result.Name] = append(names[result.Name], result.Version)
+	}
+
+	var mu sync.Mutex
+	report := &PublicationReport{AsOf: asOf.UTC(), Packages: []PublicationEntry{}}
+	addEntries := func(name string, doc *packument) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, version := range names[name] {
+			entry := PublicationEntry{Name: name, Version: version}
+			if publishedAt, err := time.Parse(time.RFC3339, doc.Time[version]); err == nil {
+				publishedAt = publishedAt.UTC()
+				entry.PublishedAt = &publishedAt
+				entry.Existed = !publishedAt.After(asOf)
+			}
+			if entry.Existed {
+				report.Existed++
+			} else {
+				report.Missing++
+			}
+			report.Packages = append(report.Packages, entry)
+		}
+	}
+
+	audit.RunPool(context.Background(), sortedKeys(names), numWorkers, func(name string) error {
+		doc, err := fetchPackument(name, registry)
+		if err != nil {
+			return err
+		}
+		addEntries(name, doc)
+		return nil
+	}, func(name string, err error) {
+		log.Warn(fmt.Sprintf("Could not read publish times of %s: %v", name, err))
+		addEntries(name, &packument{})
+	})
+
+	sort.Slice(report.Packages, func(i, j int) bool {
+		return cacheKey(report.Packages[i].Name, report.Packages[i].Version) < cacheKey(report.Packages[j].Name, report.Packages[j].Version)
+	})
+	return report
+}
+
+// printPublicationReport lists the audited versions that weren't published yet at the --as-of date
+func printPublicationReport(report *PublicationReport, display *displayFormat) {
+	fmt.Printf("\n\nAs of %s: %s versions existed, %s did not", display.timestamp(report.AsOf), display.count(report.Existed), display.count(report.Missing))
+	for _, entry := range report.Packages {
+		if entry.Existed {
+			continue
+		}
+		if entry.PublishedAt == nil {
+			fmt.Printf("\n%s@%s %s", entry.Name, entry.Version, display.status("⚠️ Publish time unknown"))
+			continue
+		}
+		fmt.Printf("\n%s@%s %s", entry.Name, entry.Version, display.status("⏳ Published "+display.timestamp(*entry.PublishedAt)))
+	}
+}
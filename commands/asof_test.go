@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAsOf(t *testing.T) {
+	asOf, err := parseAsOf("2024-03-01")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 23, 59, 59, 999999999, time.UTC), *asOf)
+
+	asOf, err = parseAsOf("2024-03-01T12:00:00+02:00")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), asOf.UTC())
+
+	asOf, err = parseAsOf("")
+	assert.NoError(t, err)
+	assert.Nil(t, asOf)
+
+	_, err = parseAsOf("March 1st")
+	assert.ErrorContains(t, err, "invalid --as-of date")
+}
+
+func TestEvaluateAsOf(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/lodash" {
+			w.Write([]byte(`{"time": {"4.17.20": "2020-08-13T16:53:54.152Z", "4.17.21": "2021-02-20T15:42:16.891Z"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer registry.Close()
+
+	results := []audit.AuditResult{
+		{Name: "lodash", Version: "4.17.21"},
+		{Name: "lodash", Version: "4.17.20"},
+		{Name: "private-pkg", Version: "1.0.0"},
+	}
+	asOf, err := parseAsOf("2021-01-01")
+	assert.NoError(t, err)
+	report := evaluateAsOf(results, *asOf, &registryConfiguration{registryURL: registry.URL}, 2)
+
+	assert.Equal(t, 1, report.Existed)
+	assert.Equal(t, 2, report.Missing)
+	assert.Equal(t, "4.17.20", report.Packages[0].Version)
+	assert.True(t, report.Packages[0].Existed)
+	assert.False(t, report.Packages[1].Existed)
+	assert.NotNil(t, report.Packages[1].PublishedAt)
+	assert.Equal(t, "private-pkg", report.Packages[2].Name)
+	assert.Nil(t, report.Packages[2].PublishedAt)
+}
@@ -0,0 +1,697 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	treeOutputFlag  = "tree-output"
+	outputFlag      = "output"
+	curationAPIFlag = "curation-api"
+	directOnlyFlag  = "direct-only"
+)
+
+func GetAuditCommand() components.Command {
+	return components.Command{
+		Name:        "audit",
+		Description: "Audits every package of a pnpm, yarn or npm lock file against the curated registry.",
+		Aliases:     []string{"a"},
+		Arguments:   getAuditArguments(),
+		Flags:       getAuditFlags(),
+		EnvVars:     append(append(getRegistryEnvVars(), getOCIPushEnvVars()...), getTelemetryEnvVars()...),
+		Action: func(c *components.Context) error {
+			return auditCmd(c)
+		},
+	}
+}
+
+func getAuditArguments() []components.Argument {
+	return []components.Argument{
+		{
+			Name:        "lock-file",
+			Description: "The path to the pnpm-lock.yaml, yarn.lock or package-lock.json file to audit, or to a poetry.lock, requirements.txt, go.sum, go.mod, gradle.lockfile, pom.xml, effective-pom.xml or composer.lock.",
+		},
+	}
+}
+
+func getAuditFlags() []components.Flag {
+	flags := append(getRegistryFlags(),
+		getWorkersFlag(),
+		getNotifyConfigFlag(),
+		components.NewBoolFlag(
+			cacheFlag,
+			"Reuse curation outcomes from the cache, e.g. those recorded by the 'proxy' command, and cache new ones",
+			components.WithBoolDefaultValue(false),
+		),
+		getCacheFileFlag(),
+		getCacheTTLFlag(),
+		components.NewBoolFlag(
+			bundledFlag,
+			"Download the available tarballs and also audit the packages bundled inside them",
+			components.WithBoolDefaultValue(false),
+		),
+		components.NewBoolFlag(
+			directOnlyFlag,
+			"Only audit the direct dependencies of the projects of the lock file, leaving out the packages they pull in",
+			components.WithBoolDefaultValue(false),
+		),
+		getRootFlag(),
+		components.NewStringFlag(
+			platformsFlag,
+			"Comma separated <os>-<cpu> platforms to also audit per-platform optional packages for, e.g. linux-x64,darwin-arm64,win32-x64",
+			components.WithHelpValue("platforms"),
+		),
+		components.NewBoolFlag(
+			peersFlag,
+			"Report direct dependencies whose peerDependencies are missing from the tree or resolved out of range",
+			components.WithBoolDefaultValue(false),
+		),
+		components.NewBoolFlag(
+			suggestFlag,
+			"Suggest the lowest newer version the curated registry serves for each blocked package",
+			components.WithBoolDefaultValue(false),
+		),
+		components.NewBoolFlag(
+			releaseNotesFlag,
+			"With --"+suggestFlag+", summarize the GitHub releases between the blocked and the suggested version",
+			components.WithBoolDefaultValue(false),
+		),
+		components.NewStringFlag(
+			asOfFlag,
+			"Report which audited versions were already published at a past date, YYYY-MM-DD or an RFC 3339 time, from the registry's publish times",
+			components.WithHelpValue("date"),
+		),
+		components.NewBoolFlag(
+			binariesFlag,
+			"Report the hosts packages download binaries from at install time (node-pre-gyp, prebuild-install, node-gyp)",
+			components.WithBoolDefaultValue(false),
+		),
+		components.NewBoolFlag(
+			curationAPIFlag,
+			"Check packages with the Artifactory curation audit API instead of requesting their tarballs, reporting the policy blocking each package and the packages not cached yet",
+			components.WithBoolDefaultValue(false),
+		),
+		components.NewStringFlag(
+			treeOutputFlag,
+			"Path of the dependency tree JSON file. Defaults to pnpm_dependency_tree.json next to the lock file, or in the --output-dir of --read-only runs",
+			components.WithHelpValue("path"),
+		),
+		components.NewStringFlag(
+			outputFlag,
+			"Path of a JSON file to store the audit results in, for use with the 'report' command",
+			components.WithHelpValue("path"),
+		),
+		components.NewStringFlag(
+			indexFlag,
+			"Path of a compact name -> version -> status index to write, for editor plugins and shell completions",
+			components.WithHelpValue("path"),
+		),
+	)
+	flags = append(flags, getOCIPushFlags()...)
+	flags = append(flags,
+		getDigestAlgorithmFlag(),
+		getTelemetryFlag(),
+		getUTCFlag(),
+		getAccessibleFlag(),
+	)
+	flags = append(flags, getTarballFlags()...)
+	flags = append(flags, getNpmrcFlag(), getEcosystemReposFlag(), getVerifyLockfileFlag())
+	flags = append(flags, getThresholdFlags()...)
+	flags = append(flags, getIgnoreFileFlag(), getReasonsFileFlag())
+	flags = append(flags, getBaselineFlags()...)
+	flags = append(flags, getEscalateAfterFlag())
+	flags = append(flags, getBudgetFlag(), getAuditTimeoutFlag())
+	flags = append(flags, getPipelineFlags()...)
+	flags = append(flags, getGitLabFlags()...)
+	flags = append(flags, getSBOMFlags()...)
+	flags = append(flags, getFilterResultsFlag())
+	flags = append(flags, getReadOnlyFlags()...)
+	flags = append(flags, getProfilingFlags()...)
+	flags = append(flags, getVerboseFlag())
+	flags = append(flags, getCassetteFlags()...)
+	return append(flags, getStreamFlags()...)
+}
+
+type auditConfiguration struct {
+	registry        *registryConfiguration
+	lockFile        string
+	workers         int
+	enrichWorkers   int
+	treeOutput      string
+	output          string
+	index           string
+	codeQuality     string
+	sbom            *sbomConfiguration
+	cache           *outcomeCache
+	bundled         bool
+	directOnly      bool
+	roots           []string
+	binaries        bool
+	platforms       []string
+	peers           bool
+	suggest         bool
+	notes           bool
+	asOf            *time.Time
+	honor           bool
+	verifyLockfile  bool
+	ociPush         *ociReference
+	ociToken        string
+	digestAlgorithm string
+	stream          *resultStream
+	filter          *resultFilter
+	// Deadline of the package checks from the start of the run, if any
+	timeout time.Duration
+
+	notifications *notificationDispatcher
+	gitlabNote    *gitlabMergeRequest
+	telemetry     *telemetryReporter
+	summary       *runSummary
+	stages        *runStages
+	sandbox       *readOnlySandbox
+	failure       *failurePolicy
+	ignore        *ignoreList
+	reasons       *reasonMapping
+	baseline      *auditBaseline
+	escalation    *escalationPolicy
+	display       *displayFormat
+}
+
+func getAuditConfiguration(c *components.Context) (*auditConfiguration, error) {
+	if len(c.Arguments) == 3 {
+		// The <repository> <lock-file> <access-token> form is intentionally unsupported: a token argument ends up in
+		// the shell history and the process list
+		return nil, fmt.Errorf("%v. The <repository> <lock-file> <access-token> form isn't supported: pass the repository with --%s and --%s, and the token in %s",
+			wrongArguments("audit", "<lock-file>", len(c.Arguments)), artifactoryURLFlag, repoFlag, accessTokenEnv)
+	}
+	if len(c.Arguments) != 1 {
+		return nil, wrongArguments("audit", "<lock-file>", len(c.Arguments))
+	}
+
+	ecosystem, otherEcosystems, err := inputEcosystems(c.Arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	if ecosystem != ecosystemNpm {
+		if err := checkNpmOnlyFlags(c, ecosystem); err != nil {
+			return nil, err
+		}
+	}
+	registry, err := getEcosystemRegistryConfiguration(c, ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	workers, err := getWorkers(c)
+	if err != nil {
+		return nil, err
+	}
+
+	registry.workers = workers
+	conf := &auditConfiguration{
+		registry:       registry,
+		lockFile:       c.Arguments[0],
+		workers:        workers,
+		treeOutput:     flagOrConfig(c, treeOutputFlag),
+		output:         flagOrConfig(c, outputFlag),
+		index:          flagOrConfig(c, indexFlag),
+		codeQuality:    flagOrEnv(c, gitlabCodeQualityFlag, gitlabCodeQualityEnv),
+		gitlabNote:     getGitLabNote(c),
+		bundled:        c.GetBoolFlagValue(bundledFlag),
+		directOnly:     c.GetBoolFlagValue(directOnlyFlag),
+		roots:          splitList(c.GetStringFlagValue(rootFlag)),
+		binaries:       c.GetBoolFlagValue(binariesFlag),
+		peers:          c.GetBoolFlagValue(peersFlag),
+		suggest:        c.GetBoolFlagValue(suggestFlag),
+		notes:          c.GetBoolFlagValue(releaseNotesFlag),
+		honor:          c.GetBoolFlagValue(honorResolutionFlag),
+		verifyLockfile: getVerifyLockfile(c),
+		display:        getDisplayFormat(c),
+	}
+	if conf.verifyLockfile {
+		if _, err := lockfileCheckCommand(conf.lockFile); err != nil {
+			return nil, err
+		}
+	}
+	if conf.directOnly && (conf.bundled || c.GetStringFlagValue(platformsFlag) != "") {
+		return nil, fmt.Errorf("--%s can't be combined with --%s or --%s, which audit transitive packages", directOnlyFlag, bundledFlag, platformsFlag)
+	}
+	if conf.directOnly && len(conf.roots) > 0 {
+		return nil, fmt.Errorf("--%s can't be combined with --%s", directOnlyFlag, rootFlag)
+	}
+	registry.mirrorHosts = parseRedirectHosts(flagOrEnv(c, mirrorHostsFlag, mirrorHostsEnv))
+	registry.curationAPI = c.GetBoolFlagValue(curationAPIFlag)
+	if registry.scopes, err = getNpmScopes(c, conf.lockFile, registry.registryURL); err != nil {
+		return nil, err
+	}
+	if registry.ecosystemRegistries, err = getEcosystemRegistries(c, otherEcosystems); err != nil {
+		return nil, err
+	}
+	if conf.sandbox, err = getReadOnlySandbox(c); err != nil {
+		return nil, err
+	}
+	if conf.treeOutput == "" {
+		conf.treeOutput = conf.sandbox.defaultOutput(defaultTreeFileName, filepath.Join(filepath.Dir(conf.lockFile), defaultTreeFileName))
+	}
+	if conf.sbom, err = getSBOMConfiguration(c, conf.lockFile, conf.sandbox); err != nil {
+		return nil, err
+	}
+	if conf.sbom != nil && samePath(conf.sbom.output, conf.lockFile) {
+		return nil, fmt.Errorf("the SBOM would overwrite the audited %s: set another --%s", conf.lockFile, sbomOutputFlag)
+	}
+	if conf.notifications, err = getNotificationDispatcher(c); err != nil {
+		return nil, err
+	}
+	if conf.ociPush, err = parseOCIPushReference(c.GetStringFlagValue(ociPushFlag)); err != nil {
+		return nil, err
+	}
+	conf.ociToken = flagOrEnv(c, ociTokenFlag, ociTokenEnv)
+	if conf.digestAlgorithm, err = getDigestAlgorithm(c); err != nil {
+		return nil, err
+	}
+	if conf.asOf, err = parseAsOf(c.GetStringFlagValue(asOfFlag)); err != nil {
+		return nil, err
+	}
+	if conf.platforms, err = parsePlatforms(c.GetStringFlagValue(platformsFlag)); err != nil {
+		return nil, err
+	}
+	if c.GetBoolFlagValue(cacheFlag) {
+		if conf.cache, err = openOutcomeCache(c); err != nil {
+			return nil, err
+		}
+	}
+	if conf.filter, err = getResultFilter(c); err != nil {
+		return nil, err
+	}
+	if conf.stream, err = getResultStream(c); err != nil {
+		return nil, err
+	}
+	if conf.stream != nil {
+		conf.stream.filter = conf.filter
+	}
+	if conf.telemetry, err = getTelemetryReporter(c, "audit"); err != nil {
+		return nil, err
+	}
+	if conf.failure, err = getFailurePolicy(c); err != nil {
+		return nil, err
+	}
+	if conf.ignore, err = getIgnoreList(c); err != nil {
+		return nil, err
+	}
+	if conf.reasons, err = getReasonMapping(c); err != nil {
+		return nil, err
+	}
+	if conf.baseline, err = getAuditBaseline(c); err != nil {
+		return nil, err
+	}
+	if conf.stages, err = getRunStages(c); err != nil {
+		return nil, err
+	}
+	skipEnrichments(conf)
+	if conf.enrichWorkers, err = getEnrichWorkers(c, workers); err != nil {
+		return nil, err
+	}
+	if conf.timeout, err = parseTimeout(auditTimeoutFlag, flagOrEnv(c, auditTimeoutFlag, auditTimeoutEnv), 0); err != nil {
+		return nil, err
+	}
+	if conf.escalation, err = getEscalationPolicy(c); err != nil {
+		return nil, err
+	}
+	conf.failure.ignore, conf.failure.baseline, conf.failure.escalation = conf.ignore, conf.baseline, conf.escalation
+	if err := checkReadOnlyOutputs(c, conf); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// checkNpmOnlyFlags fails on the flags relying on the npm registry metadata, when auditing a lock file of another
+// ecosystem
+func checkNpmOnlyFlags(c *components.Context, ecosystem string) error {
+	for _, flag := range []string{bundledFlag, binariesFlag, peersFlag, suggestFlag, releaseNotesFlag, honorResolutionFlag, curationAPIFlag, npmrcFlag} {
+		if c.GetBoolFlagValue(flag) {
+			return fmt.Errorf("--%s is only supported for npm lock files, not %s ones", flag, ecosystem)
+		}
+	}
+	for _, flag := range []string{platformsFlag, asOfFlag} {
+		if c.GetStringFlagValue(flag) != "" {
+			return fmt.Errorf("--%s is only supported for npm lock files, not %s ones", flag, ecosystem)
+		}
+	}
+	return nil
+}
+
+// directDependencies keeps the direct dependencies for --direct-only, which needs a lock file recording them
+func directDependencies(deps []audit.Dependency, tree *audit.DependencyTree, lockFile string) ([]audit.Dependency, error) {
+	if len(tree.Importers) == 0 {
+		return nil, fmt.Errorf("--%s needs a lock file recording the direct dependencies, which %s doesn't", directOnlyFlag, filepath.Base(lockFile))
+	}
+	direct := audit.DirectDependencies(deps)
+	log.Info(fmt.Sprintf("Skipping %d transitive dependencies (--%s)", len(deps)-len(direct), directOnlyFlag))
+	return direct, nil
+}
+
+func auditCmd(c *components.Context) (err error) {
+	summary := newRunSummary("audit", os.Stderr)
+	defer func() {
+		summary.write(err)
+	}()
+	conf, err := getAuditConfiguration(c)
+	if err != nil {
+		return err
+	}
+	conf.summary = summary
+	defer conf.registry.cassette.save()
+	profile, err := startProfiling(c)
+	if err != nil {
+		return err
+	}
+	defer profile.stop()
+	ctx, stop := interruptContext()
+	defer stop()
+	return runAudit(ctx, conf)
+}
+
+// runAudit audits the lock file. Once the context is done, e.g. on Ctrl-C, the checks in flight are aborted and
+// the run reports and writes the results checked so far, then fails. Past the --audit-timeout, the packages not
+// checked are reported as timed out instead.
+func runAudit(ctx context.Context, conf *auditConfiguration) (err error) {
+	runStart := time.Now()
+	timings := conf.registry.timings
+	// Notifications are sent past the --audit-timeout, with the partial results
+	runCtx := ctx
+	if conf.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, conf.timeout)
+		defer cancel()
+	}
+	defer func() {
+		// The stage the run failed at is reported with the usage metrics
+		stage := conf.stages.current
+		conf.stages.finish()
+		if err == nil {
+			stage = ""
+		} else if over := conf.stages.overBudget(); over != "" {
+			stage = over
+		}
+		conf.telemetry.send(stage)
+		conf.summary.recordStages(conf.stages)
+		timings.print(conf.display)
+	}()
+
+	if err := conf.stages.start(stageParse); err != nil {
+		return err
+	}
+	timings.phase(phaseParse)
+
+	log.Info("Parsing", conf.lockFile)
+	conf.telemetry.recordLockFile(conf.lockFile)
+	conf.summary.recordLockFile(conf.lockFile)
+	dependencies, err := audit.ParseLockFile(conf.lockFile)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %v", filepath.Base(conf.lockFile), err)
+	}
+	recordTreeStats(conf.lockFile, dependencies)
+	if conf.verifyLockfile {
+		if err := verifyLockfile(ctx, conf.lockFile, dependencies, conf.sandbox.tempDir()); err != nil {
+			return err
+		}
+	}
+
+	timings.phase(phaseGraph)
+	if conf.treeOutput != "" {
+		if err := saveDependencyTree(dependencies, conf.treeOutput); err != nil {
+			return fmt.Errorf("error saving dependency tree: %v", err)
+		}
+		conf.summary.recordReport("tree", conf.treeOutput)
+	}
+
+	conf.filter.setTree(dependencies)
+
+	timings.phase(phaseDedupe)
+	deps := dependencies.Dependencies()
+	if conf.directOnly {
+		if deps, err = directDependencies(deps, dependencies, conf.lockFile); err != nil {
+			return err
+		}
+	}
+	// The per-platform variants of --root are those of its subtrees
+	variantTree := dependencies
+	if len(conf.roots) > 0 {
+		closure, err := rootClosure(dependencies, conf.roots, conf.lockFile)
+		if err != nil {
+			return err
+		}
+		deps = rootDependencies(deps, closure, conf.roots)
+		variantTree = rootSubtree(dependencies, closure)
+	}
+	deps = conf.ignore.skip(deps)
+	deps = skipUnroutedDependencies(deps, conf.registry)
+	deps, findings := validateDependencies(deps, conf.registry)
+	if len(findings) > 0 {
+		log.Warn(fmt.Sprintf("Skipping %s entries of %s with an invalid name or version", conf.display.count(len(findings)), filepath.Base(conf.lockFile)))
+	}
+	pinned := findPinnedTarballs(dependencies, conf.registry)
+	if conf.honor {
+		deps = honorPinnedTarballs(deps, pinned)
+	}
+
+	timings.phase(phaseNetwork)
+	startTime := time.Now()
+	auditDeps := func(deps []audit.Dependency) []audit.AuditResult {
+		return collectAuditResults(ctx, deps, conf.registry, conf.workers, !conf.display.accessible, conf.stream, conf.stages.deadline())
+	}
+	if conf.cache != nil {
+		check := auditDeps
+		auditDeps = func(deps []audit.Dependency) []audit.AuditResult {
+			return auditWithCache(deps, conf.cache, conf.registry, check, conf.stream)
+		}
+	}
+	results := []audit.AuditResult{}
+	if conf.stages.skips(stageAudit) {
+		log.Info(fmt.Sprintf("Skipping the checks of the %s dependencies (--%s)", conf.display.count(len(deps)), skipStagesFlag))
+	} else {
+		if err := conf.stages.start(stageAudit); err != nil {
+			return err
+		}
+		log.Info(fmt.Sprintf("Auditing %s dependencies against %s with %d workers", conf.display.count(len(deps)), conf.registry.registryURL, conf.workers))
+		results = auditDeps(deps)
+	}
+
+	if conf.bundled && ctx.Err() == nil {
+		if err := conf.stages.check(); err != nil {
+			return err
+		}
+		bundled := fetchBundledDependencies(results, deps, conf.registry, conf.workers)
+		log.Info(fmt.Sprintf("Found %s bundled packages inside the tarballs", conf.display.count(len(bundled))))
+		results = append(results, auditDeps(bundled)...)
+	}
+
+	if len(conf.platforms) > 0 && ctx.Err() == nil {
+		if err := conf.stages.check(); err != nil {
+			return err
+		}
+		variants := platformVariants(variantTree, conf.platforms, func(name, version string) (map[string]string, error) {
+			manifest, err := fetchPackageManifest(name, version, conf.registry)
+			if err != nil {
+				return nil, err
+			}
+			return manifest.OptionalDependencies, nil
+		})
+		log.Info(fmt.Sprintf("Found %s per-platform optional packages for %s", conf.display.count(len(variants)), strings.Join(conf.platforms, ", ")))
+		results = append(results, auditDeps(variants)...)
+	}
+
+	checks := len(results)
+	conf.reasons.apply(results)
+	interrupted := errors.Is(ctx.Err(), context.Canceled)
+	timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+	if interrupted {
+		results = checkedResults(results)
+		log.Warn(fmt.Sprintf("Interrupted: reporting the %s of %s packages checked so far", conf.display.count(len(results)), conf.display.count(checks)))
+	}
+	if timedOut {
+		log.Warn(fmt.Sprintf("The audit timed out after %s (--%s): %s of %s packages timed out", conf.display.duration(conf.timeout), auditTimeoutFlag, conf.display.count(timedOutResults(results)), conf.display.count(checks)))
+	}
+	if interrupted || timedOut {
+		// The enrichments can't be interrupted, so an incomplete run reports the checks alone
+		conf.binaries, conf.suggest, conf.asOf = false, false, nil
+	} else if count := requestTimeouts(results); count > 0 {
+		log.Warn(fmt.Sprintf("%s packages timed out, consider a longer --%s", conf.display.count(count), requestTimeoutFlag))
+	}
+
+	conf.telemetry.recordResults(results)
+	conf.summary.recordResults(results)
+
+	if conf.cache != nil {
+		if err := conf.cache.save(); err != nil {
+			log.Warn(err.Error())
+		}
+	}
+
+	enriched := &enrichments{}
+	if !conf.stages.skips(stageEnrich) {
+		if err := conf.stages.start(stageEnrich); err != nil {
+			return err
+		}
+		enriched = runEnrichments(conf, results, dependencies)
+	}
+	downloads, peerGaps, suggestions, publications := enriched.downloads, enriched.peerGaps, enriched.suggestions, enriched.publications
+
+	if err := conf.stages.start(stageReport); err != nil {
+		return err
+	}
+	timings.phase(phaseReport)
+	if err := conf.stream.close(); err != nil {
+		return err
+	}
+	// The outputs report the results of --filter-results, the notifications, baseline and policy all of them
+	reported := conf.filter.apply(results)
+	printAuditResults(reported, conf.display)
+	conf.ignore.warn(reported)
+	printPinnedTarballs(pinned, conf.display)
+	printParseFindings(findings, conf.display)
+	projects, err := findWorkspaceProjects(dependencies, conf.lockFile, reported)
+	if err != nil {
+		log.Warn(err.Error())
+	}
+	projects.print(reported)
+	paths := findDependencyPaths(dependencies, reported)
+	paths.print(reported, projects)
+	links := newRemediationLinks(conf.registry.registryURL, conf.registry.ecosystem)
+	links.print(reported)
+	printBlockingReasons(reported)
+	conf.baseline.print(reported, conf.display)
+	conf.escalation.print(reported, conf.ignore, conf.baseline, conf.display)
+	if conf.binaries {
+		printBinaryDownloads(downloads, conf.display)
+	}
+	if conf.peers {
+		printPeerGaps(peerGaps, conf.display)
+	}
+	if conf.suggest {
+		printSuggestions(suggestions, conf.display)
+	}
+	if conf.asOf != nil {
+		printPublicationReport(publications, conf.display)
+	}
+	summary := newAuditSummary(results, time.Since(runStart))
+	fmt.Print("\n\n" + summary.table(conf.display))
+	fmt.Println()
+	if summary := conf.registry.rateLimiter.summary(conf.display); summary != "" {
+		log.Info(summary)
+	}
+	if summary := conf.registry.failover.summary(conf.display); summary != "" {
+		log.Info(summary)
+	}
+	log.Info(fmt.Sprintf("Processed %s dependencies from %s in %s", conf.display.count(len(deps)), conf.lockFile, conf.display.duration(time.Since(startTime))))
+
+	metadata := detectRunMetadata(filepath.Dir(conf.lockFile))
+	report := newAuditReport(conf.lockFile, reported)
+	report.Metadata = metadata
+	report.Summary = summary
+	report.PinnedTarballs = pinned
+	report.ParseFindings = findings
+	projects.annotate(report)
+	paths.annotate(report)
+	links.annotate(report.Results)
+	conf.ignore.annotate(report, reported)
+	conf.baseline.annotate(report, reported)
+	conf.escalation.annotate(report, reported, conf.ignore, conf.baseline)
+	report.BinaryDownloads = downloads
+	report.PeerGaps = peerGaps
+	report.Suggestions = suggestions
+	report.Publications = publications
+	if conf.output != "" {
+		carryAnnotations(report, conf.output)
+		if err := writeAuditReport(report, conf.output); err != nil {
+			return err
+		}
+		log.Info("Audit results saved to", conf.output)
+		conf.summary.recordReport("results", conf.output)
+	}
+
+	if conf.sbom != nil {
+		quality, err := conf.sbom.write(dependencies, report, conf.registry.registryURL)
+		if err != nil {
+			return err
+		}
+		log.Info("SBOM saved to", conf.sbom.output)
+		quality.print(conf.sbom.output, conf.display)
+		conf.summary.recordReport("sbom", conf.sbom.output)
+	}
+
+	if conf.ociPush != nil {
+		token := ociPushToken(conf.ociToken, *conf.ociPush, conf.registry)
+		pushed, err := pushAuditArtifact(*conf.ociPush, token, conf.digestAlgorithm, conf.lockFile, report, conf.treeOutput, conf.sbom)
+		if err != nil {
+			return fmt.Errorf("error pushing audit artifact: %v", err)
+		}
+		log.Info("Audit artifact pushed to", pushed)
+		conf.summary.recordReport("oci", pushed)
+	}
+
+	if conf.index != "" {
+		index := newPackageIndex(conf.registry.registryURL, reported)
+		index.Metadata = metadata
+		if err := writePackageIndex(index, conf.index); err != nil {
+			return err
+		}
+		log.Info("Package index saved to", conf.index)
+		conf.summary.recordReport("index", conf.index)
+	}
+
+	if conf.codeQuality != "" {
+		if err := writeCodeQualityReport(report, conf.codeQuality); err != nil {
+			return err
+		}
+		log.Info("Code Quality report saved to", conf.codeQuality)
+		conf.summary.recordReport("code-quality", conf.codeQuality)
+	}
+	if conf.gitlabNote != nil {
+		// The note is a courtesy to the reviewers: failing to post it doesn't fail the audit
+		if err := conf.gitlabNote.postNote(report, conf.display); err != nil {
+			log.Warn(err.Error())
+		} else {
+			log.Info("Audit results posted to merge request !" + conf.gitlabNote.mrIID)
+		}
+	}
+
+	events := newNotificationEvents("audit", conf.lockFile, results, metadata, conf.failure)
+	for _, event := range events {
+		links.annotate(event.Packages)
+	}
+	conf.notifications.dispatch(runCtx, events)
+
+	if interrupted {
+		// Recording the findings of a partial audit would drop those of the packages left from the baseline
+		return fmt.Errorf("audit interrupted after checking %d of %d packages", len(results), checks)
+	}
+	if timedOut {
+		// An audit past its deadline is as partial, with the packages left unchecked
+		return fmt.Errorf("audit timed out after %v with %d of %d packages timed out", conf.timeout, timedOutResults(results), checks)
+	}
+	if conf.stages.skips(stageAudit) {
+		// Nothing was checked to record in the baseline or hold to the policy
+		return nil
+	}
+	if err := conf.baseline.record(conf.lockFile, results); err != nil {
+		return err
+	}
+
+	if conf.stages.skips(stagePolicy) {
+		log.Info(fmt.Sprintf("Skipping the policy stage (--%s): the findings don't fail the run", skipStagesFlag))
+		return nil
+	}
+	if err := conf.stages.start(stagePolicy); err != nil {
+		return err
+	}
+	return conf.failure.evaluate(results)
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditConfigurationFromEnv(t *testing.T) {
+	t.Setenv(registryURLEnv, "https://acme.jfrog.io/artifactory/api/npm/npm-remote/")
+	conf, err := getAuditConfiguration(&components.Context{Arguments: []string{filepath.Join("app", "pnpm-lock.yaml")}})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/api/npm/npm-remote", conf.registry.registryURL)
+	assert.Equal(t, defaultWorkers, conf.workers)
+	assert.Equal(t, filepath.Join("app", "pnpm_dependency_tree.json"), conf.treeOutput)
+}
+
+func TestAuditConfigurationMissingRegistry(t *testing.T) {
+	t.Setenv(registryURLEnv, "")
+	_, err := getAuditConfiguration(&components.Context{Arguments: []string{"pnpm-lock.yaml"}})
+	assert.ErrorContains(t, err, "missing registry URL")
+}
+
+func TestAuditConfigurationPositionalForm(t *testing.T) {
+	_, err := getAuditConfiguration(&components.Context{Arguments: []string{"npm-remote", "pnpm-lock.yaml", "token"}})
+	assert.EqualError(t, err, "wrong number of arguments, got 3. Expected: ca-extension audit <lock-file>. Run 'ca-extension examples audit' for examples. "+
+		"The <repository> <lock-file> <access-token> form isn't supported: pass the repository with --artifactory-url and --repo, and the token in CA_EXTENSION_ACCESS_TOKEN")
+}
+
+func TestDirectDependencies(t *testing.T) {
+	lockFile := filepath.Join("..", "audit", "testdata", "pnpm-workspace", "pnpm-lock.yaml")
+	tree, err := audit.ParseLockFile(lockFile)
+	assert.NoError(t, err)
+	direct, err := directDependencies(tree.Dependencies(), tree, lockFile)
+	assert.NoError(t, err)
+	for _, dep := range direct {
+		assert.NotEqual(t, "js-tokens", dep.Name)
+		assert.Equal(t, audit.RelationshipDirect, dep.Relationship)
+	}
+
+	requirements, err := audit.ParseLockFileData("requirements.txt", []byte("requests==2.31.0\n"))
+	assert.NoError(t, err)
+	_, err = directDependencies(requirements.Dependencies(), requirements, "requirements.txt")
+	assert.ErrorContains(t, err, "--direct-only needs a lock file recording the direct dependencies")
+}
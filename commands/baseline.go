@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	baselineFlag       = "baseline"
+	updateBaselineFlag = "update-baseline"
+
+	// Version of the baseline file
+	baselineSchemaVersion = "1"
+)
+
+// BaselineFinding is a blocked or not found package version accepted when the baseline was recorded
+type BaselineFinding struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	StatusCode int    `json:"statusCode"`
+	// FirstSeen is when the finding was first recorded, kept by later updates of the baseline
+	FirstSeen string `json:"firstSeen,omitempty"`
+}
+
+// Baseline represents the findings of a lock file recorded by --baseline, which later runs don't fail or report
+// as new
+type Baseline struct {
+	SchemaVersion string            `json:"schemaVersion"`
+	RecordedAt    string            `json:"recordedAt"`
+	LockFile      string            `json:"lockFile"`
+	Findings      []BaselineFinding `json:"findings"`
+}
+
+// auditBaseline is the baseline of an audit. A nil baseline accepts no findings.
+type auditBaseline struct {
+	path     string
+	update   bool
+	findings map[string]bool
+	// When each finding was first recorded, or when the baseline was for those recorded without a first-seen time
+	firstSeen map[string]time.Time
+}
+
+func getBaselineFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			baselineFlag,
+			"Path of a baseline of the blocked and not found packages. Recorded by the first run, it keeps later runs from failing on or reporting its packages as new findings",
+			components.WithHelpValue("path"),
+		),
+		components.NewBoolFlag(
+			updateBaselineFlag,
+			"Record the findings of the run as the new --"+baselineFlag+", e.g. after fixing some",
+			components.WithBoolDefaultValue(false),
+		),
+	}
+}
+
+func getAuditBaseline(c *components.Context) (*auditBaseline, error) {
+	path := flagOrConfig(c, baselineFlag)
+	if path == "" {
+		if c.GetBoolFlagValue(updateBaselineFlag) {
+			return nil, fmt.Errorf("--%s needs --%s", updateBaselineFlag, baselineFlag)
+		}
+		return nil, nil
+	}
+	return loadAuditBaseline(path, c.GetBoolFlagValue(updateBaselineFlag))
+}
+
+// loadAuditBaseline reads a baseline file. A missing one is recorded by the run, as with update.
+func loadAuditBaseline(path string, update bool) (*auditBaseline, error) {
+	baseline := &auditBaseline{path: path, update: update}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		baseline.update = true
+		return baseline, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading baseline: %v", err)
+	}
+	var file Baseline
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing baseline %s: %v", path, err)
+	}
+	if file.SchemaVersion != baselineSchemaVersion {
+		return nil, fmt.Errorf("unsupported baseline version '%s' in %s", file.SchemaVersion, path)
+	}
+	recordedAt, _ := time.Parse(time.RFC3339, file.RecordedAt)
+	baseline.findings = make(map[string]bool, len(file.Findings))
+	baseline.firstSeen = make(map[string]time.Time, len(file.Findings))
+	for _, finding := range file.Findings {
+		key := cacheKey(finding.Name, finding.Version)
+		baseline.findings[key] = true
+		baseline.firstSeen[key] = recordedAt
+		if firstSeen, err := time.Parse(time.RFC3339, finding.FirstSeen); err == nil {
+			baseline.firstSeen[key] = firstSeen
+		}
+	}
+	return baseline, nil
+}
+
+// isBaselineFinding reports whether a result is a finding baselines record: blocked or not found. Errors and
+// packages pending curation are left out, as they may not happen on the next run.
+func isBaselineFinding(result audit.AuditResult) bool {
+	return isBlocked(result) || (result.Error == nil && result.StatusCode == http.StatusNotFound)
+}
+
+// accepted reports whether the finding of a result is in the baseline. Baselines being recorded accept every
+// finding of the run.
+func (b *auditBaseline) accepted(result audit.AuditResult) bool {
+	if b == nil || !isBaselineFinding(result) {
+		return false
+	}
+	return b.update || b.findings[cacheKey(result.Name, result.Version)]
+}
+
+// since returns when the accepted finding of a result was first recorded in the baseline. Findings the run records
+// are seen for the first time, unless an earlier version of the baseline had them.
+func (b *auditBaseline) since(result audit.AuditResult, now time.Time) (time.Time, bool) {
+	if !b.accepted(result) {
+		return time.Time{}, false
+	}
+	if firstSeen, ok := b.firstSeen[cacheKey(result.Name, result.Version)]; ok && !firstSeen.IsZero() {
+		return firstSeen, true
+	}
+	return now, true
+}
+
+// record writes the findings of the results as the baseline, when it is missing or updated
+func (b *auditBaseline) record(lockFile string, results []audit.AuditResult) error {
+	if b == nil || !b.update {
+		return nil
+	}
+	now := time.Now().UTC()
+	file := Baseline{
+		SchemaVersion: baselineSchemaVersion,
+		RecordedAt:    now.Format(time.RFC3339),
+		LockFile:      filepath.Base(lockFile),
+		Findings:      []BaselineFinding{},
+	}
+	for _, result := range results {
+		if isBaselineFinding(result) {
+			firstSeen, _ := b.since(result, now)
+			file.Findings = append(file.Findings, BaselineFinding{
+				Name:       result.Name,
+				Version:    result.Version,
+				StatusCode: result.StatusCode,
+				FirstSeen:  firstSeen.UTC().Format(time.RFC3339),
+			})
+		}
+	}
+	sort.Slice(file.Findings, func(i, j int) bool {
+		return cacheKey(file.Findings[i].Name, file.Findings[i].Version) < cacheKey(file.Findings[j].Name, file.Findings[j].Version)
+	})
+	jsonData, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	if err := ioutil.WriteFile(b.path, append(jsonData, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing baseline: %v", err)
+	}
+	log.Info(fmt.Sprintf("Recorded %d findings in the baseline %s", len(file.Findings), b.path))
+	return nil
+}
+
+// print lists the findings that aren't in the baseline
+func (b *auditBaseline) print(results []audit.AuditResult, display *displayFormat) {
+	if b == nil || b.update {
+		return
+	}
+	accepted := 0
+	var fresh []audit.AuditResult
+	for _, result := range results {
+		if b.accepted(result) {
+			accepted++
+		} else if isBaselineFinding(result) {
+			fresh = append(fresh, result)
+		}
+	}
+	fmt.Printf("\n\nNew findings since the baseline (%s in the baseline):", display.count(accepted))
+	if len(fresh) == 0 {
+		fmt.Printf("\nNone")
+	}
+	for _, result := range fresh {
+		fmt.Printf("\n%s@%s: %s", result.Name, result.Version, display.status(result.Status))
+	}
+}
+
+// annotate marks the results of the report whose findings are in the baseline
+func (b *auditBaseline) annotate(report *AuditReport, results []audit.AuditResult) {
+	if b == nil {
+		return
+	}
+	for i, result := range results {
+		if i < len(report.Results) && b.accepted(result) {
+			report.Results[i].Baseline = true
+		}
+	}
+}
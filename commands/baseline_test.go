@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "curation-baseline.json")
+	legacy := []audit.AuditResult{
+		{Name: "lodash", Version: "4.17.20", StatusCode: http.StatusForbidden},
+		{Name: "left-pad", Version: "1.3.0", StatusCode: http.StatusNotFound},
+		{Name: "react", Version: "18.2.0", StatusCode: http.StatusOK},
+		{Name: "flaky", Version: "1.0.0", Error: errors.New("timeout")},
+	}
+
+	// A missing baseline is recorded by the run, which accepts its findings
+	baseline, err := loadAuditBaseline(path, false)
+	assert.NoError(t, err)
+	assert.True(t, baseline.accepted(legacy[0]))
+	assert.False(t, baseline.accepted(legacy[3]))
+	assert.NoError(t, baseline.record("apps/pnpm-lock.yaml", legacy))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"lockFile": "pnpm-lock.yaml"`)
+
+	baseline, err = loadAuditBaseline(path, false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"left-pad@1.3.0": true, "lodash@4.17.20": true}, baseline.findings)
+
+	results := []audit.AuditResult{
+		{Name: "lodash", Version: "4.17.20", StatusCode: http.StatusForbidden},
+		{Name: "lodash", Version: "4.17.21", StatusCode: http.StatusForbidden},
+	}
+	assert.True(t, baseline.accepted(results[0]))
+	assert.False(t, baseline.accepted(results[1]))
+
+	report := newAuditReport("pnpm-lock.yaml", results)
+	baseline.annotate(report, results)
+	assert.True(t, report.Results[0].Baseline)
+	assert.False(t, report.Results[1].Baseline)
+
+	policy, err := parseFailurePolicy(failOnBlocked, "")
+	assert.NoError(t, err)
+	policy.baseline = baseline
+	assert.EqualError(t, policy.evaluate(results), "audit failed: 1 of 2 packages are blocked by curation (--fail-on=blocked)")
+	assert.NoError(t, policy.evaluate(results[:1]))
+
+	// Existing baselines are only rewritten when updated
+	assert.NoError(t, baseline.record("pnpm-lock.yaml", results))
+	unchanged, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, data, unchanged)
+}
+
+func TestLoadAuditBaselineRejectsUnknownVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "curation-baseline.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"schemaVersion":"2","findings":[]}`), 0644))
+	_, err := loadAuditBaseline(path, false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	binariesFlag = "binaries"
+
+	// node-gyp downloads the Node.js headers unless a mirror or local headers are configured
+	nodeHeadersHost = "nodejs.org"
+)
+
+// Matches URLs hardcoded in lifecycle scripts
+var scriptURLPattern = regexp.MustCompile(`https?://[^\s'"]+`)
+
+// BinaryDownload represents an artifact a package downloads at install time, outside of the registry
+type BinaryDownload struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Tool    string `json:"tool"`
+	Host    string `json:"host"`
+	Curated bool   `json:"curated"`
+}
+
+// packageManifest holds the parts of a registry version document the audit inspects
+type packageManifest struct {
+	Scripts map[string]string `json:"scripts"`
+	GypFile bool              `json:"gypfile"`
+	Binary  struct {
+		Host string `json:"host"`
+	} `json:"binary"`
+	Repository json.RawMessage `json:"repository"`
+
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// findBinaryDownloads inspects the registry metadata of the available packages for install-time downloads
+func findBinaryDownloads(results []audit.AuditResult, registry *registryConfiguration, numWorkers int) []BinaryDownload {
+	registryHost := ""
+	if parsed, err := url.Parse(registry.registryURL); err == nil {
+		registryHost = parsed.Hostname()
+	}
+
+	var mu sync.Mutex
+	var downloads []BinaryDownload
+	audit.RunPool(context.Background(), availableResults(results), numWorkers, func(result audit.AuditResult) error {
+		manifest, err := fetchPackageManifest(result.Name, result.Version, registry)
+		if err != nil {
+			return err
+		}
+		found := binaryDownloadsOf(result.Name, result.Version, manifest, registryHost)
+		mu.Lock()
+		downloads = append(downloads, found...)
+		mu.Unlock()
+		return nil
+	}, func(result audit.AuditResult, err error) {
+		log.Warn(fmt.Sprintf("Could not read metadata of %s@%s: %v", result.Name, result.Version, err))
+	})
+
+	sort.Slice(downloads, func(i, j int) bool {
+		if downloads[i].Name != downloads[j].Name {
+			return downloads[i].Name < downloads[j].Name
+		}
+		return downloads[i].Host < downloads[j].Host
+	})
+	return downloads
+}
+
+func fetchPackageManifest(packageName, packageVersion string, registry *registryConfiguration) (*packageManifest, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s/%s", registry.registryURL, audit.EscapeNpmName(packageName), audit.EscapePathSegment(packageVersion)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	registry.credentials().Authorize(req)
+
+	client := registry.httpClient(registry.requestTimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response: %d", resp.StatusCode)
+	}
+
+	var manifest packageManifest
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("error parsing metadata: %v", err)
+	}
+	return &manifest, nil
+}
+
+// binaryDownloadsOf detects node-pre-gyp, prebuild-install, node-gyp and URLs hardcoded in install scripts
+func binaryDownloadsOf(packageName, packageVersion string, manifest *packageManifest, registryHost string) []BinaryDownload {
+	hosts := make(map[string]string)
+	installScripts := manifest.Scripts["preinstall"] + " " + manifest.Scripts["install"] + " " + manifest.Scripts["postinstall"]
+
+	if manifest.Binary.Host != "" {
+		hosts[audit.HostOf(manifest.Binary.Host)] = "node-pre-gyp"
+	}
+	if strings.Contains(installScripts, "prebuild-install") {
+		if host := repositoryHost(manifest.Repository); host != "" {
+			hosts[host] = "prebuild-install"
+		}
+	}
+	if manifest.GypFile || (strings.Contains(installScripts, "node-gyp") && !strings.Contains(installScripts, "node-gyp-build")) {
+		if _, exists := hosts[nodeHeadersHost]; !exists {
+			hosts[nodeHeadersHost] = "node-gyp"
+		}
+	}
+	for _, match := range scriptURLPattern.FindAllString(installScripts, -1) {
+		if host := audit.HostOf(match); host != "" {
+			if _, exists := hosts[host]; !exists {
+				hosts[host] = "install script"
+			}
+		}
+	}
+
+	var downloads []BinaryDownload
+	for host, tool := range hosts {
+		if host == "" {
+			continue
+		}
+		downloads = append(downloads, BinaryDownload{
+			Name:    packageName,
+			Version: packageVersion,
+			Tool:    tool,
+			Host:    host,
+			Curated: host == registryHost,
+		})
+	}
+	return downloads
+}
+
+// repositoryHost returns the host a prebuild-install package publishes its binaries to, its repository's host
+func repositoryHost(repository json.RawMessage) string {
+	var repositoryURL string
+	if err := json.Unmarshal(repository, &repositoryURL); err != nil {
+		var repositoryObject struct {
+			URL string `json:"url"`
+		}
+		if err := json.Unmarshal(repository, &repositoryObject); err != nil {
+			return ""
+		}
+		repositoryURL = repositoryObject.URL
+	}
+	// Shorthands such as "github:user/repo" or "user/repo" point to GitHub
+	if repositoryURL != "" && !strings.Contains(repositoryURL, "://") {
+		return "github.com"
+	}
+	return audit.HostOf(strings.TrimPrefix(repositoryURL, "git+"))
+}
+
+// printBinaryDownloads lists the install-time downloads, flagging those bypassing the curated registry
+func printBinaryDownloads(downloads []BinaryDownload, display *displayFormat) {
+	if len(downloads) == 0 {
+		return
+	}
+	fmt.Printf("\n\nInstall-time binary downloads:")
+	for _, download := range downloads {
+		status := "⚠️ Not routed through the curated registry"
+		if download.Curated {
+			status = "✅ Routed through the curated registry"
+		}
+		fmt.Printf("\n%s@%s (%s) %s %s", download.Name, download.Version, download.Tool, download.Host, display.status(status))
+	}
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseManifest(t *testing.T, data string) *packageManifest {
+	var manifest packageManifest
+	assert.NoError(t, json.Unmarshal([]byte(data), &manifest))
+	return &manifest
+}
+
+func TestBinaryDownloadsNodePreGyp(t *testing.T) {
+	manifest := parseManifest(t, `{"scripts":{"install":"node-pre-gyp install --fallback-to-build"},"binary":{"host":"https://mapbox-node-binary.s3.amazonaws.com","remote_path":"./{name}/v{version}"}}`)
+	downloads := binaryDownloadsOf("sqlite3", "5.1.6", manifest, "acme.jfrog.io")
+	assert.Equal(t, []BinaryDownload{{Name: "sqlite3", Version: "5.1.6", Tool: "node-pre-gyp", Host: "mapbox-node-binary.s3.amazonaws.com"}}, downloads)
+}
+
+func TestBinaryDownloadsPrebuildInstall(t *testing.T) {
+	manifest := parseManifest(t, `{"scripts":{"install":"prebuild-install || node-gyp rebuild"},"gypfile":true,"repository":{"type":"git","url":"git+https://github.com/lovell/sharp.git"}}`)
+	downloads := binaryDownloadsOf("sharp", "0.32.6", manifest, "acme.jfrog.io")
+	assert.ElementsMatch(t, []BinaryDownload{
+		{Name: "sharp", Version: "0.32.6", Tool: "prebuild-install", Host: "github.com"},
+		{Name: "sharp", Version: "0.32.6", Tool: "node-gyp", Host: nodeHeadersHost},
+	}, downloads)
+}
+
+func TestBinaryDownloadsInstallScriptURL(t *testing.T) {
+	manifest := parseManifest(t, `{"scripts":{"postinstall":"curl -sL https://acme.jfrog.io/artifactory/generic/tool.tar.gz | tar xz"}}`)
+	downloads := binaryDownloadsOf("tool", "1.0.0", manifest, "acme.jfrog.io")
+	assert.Equal(t, []BinaryDownload{{Name: "tool", Version: "1.0.0", Tool: "install script", Host: "acme.jfrog.io", Curated: true}}, downloads)
+}
+
+func TestBinaryDownloadsNone(t *testing.T) {
+	manifest := parseManifest(t, `{"scripts":{"test":"jest","install":"node-gyp-build"}}`)
+	assert.Empty(t, binaryDownloadsOf("bufferutil", "4.0.8", manifest, "acme.jfrog.io"))
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+const (
+	budgetFlag = "budget"
+
+	budgetEnv = "CA_EXTENSION_BUDGET"
+)
+
+// Stages of an audit run, in the order they run. The audit stage checks the packages against the registry,
+// bundled and per-platform ones included, and the enrich stage looks up what --binaries, --suggest and --as-of
+// report about them.
+const (
+	stageParse  = "parse"
+	stageAudit  = "audit"
+	stageEnrich = "enrich"
+	stageReport = "report"
+	stagePolicy = "policy"
+)
+
+var runStageNames = []string{stageParse, stageAudit, stageEnrich, stageReport, stagePolicy}
+
+// StageTiming is the time a stage of the run took, written with the run summary
+type StageTiming struct {
+	Stage      string `json:"stage"`
+	DurationMs int64  `json:"durationMs"`
+	// BudgetMs is the --budget of the stage, if any
+	BudgetMs   int64 `json:"budgetMs,omitempty"`
+	OverBudget bool  `json:"overBudget,omitempty"`
+}
+
+// runStages tracks the stage a run is at, times the stages and holds them to their budgets
+type runStages struct {
+	budgets map[string]time.Duration
+	timings []StageTiming
+	// skipped are the stages of --skip-stages, which the run leaves out
+	skipped map[string]bool
+
+	current string
+	started time.Time
+}
+
+func getBudgetFlag() components.Flag {
+	return components.NewStringFlag(
+		budgetFlag,
+		"Comma separated time budgets of the stages of the run, as <stage>=<duration> with the parse, audit, enrich, report and policy stages, e.g. parse=30s,audit=10m. A stage over its budget fails the run",
+		components.WithHelpValue("stage=duration"),
+	)
+}
+
+func getRunStages(c *components.Context) (*runStages, error) {
+	budgets, err := parseStageBudgets(flagOrEnv(c, budgetFlag, budgetEnv))
+	if err != nil {
+		return nil, err
+	}
+	stages := newRunStages(budgets)
+	if stages.skipped, err = parseSkippedStages(flagOrEnv(c, skipStagesFlag, skipStagesEnv)); err != nil {
+		return nil, err
+	}
+	return stages, nil
+}
+
+func newRunStages(budgets map[string]time.Duration) *runStages {
+	return &runStages{budgets: budgets}
+}
+
+// parseStageBudgets reads the <stage>=<duration> value of --budget
+func parseStageBudgets(value string) (map[string]time.Duration, error) {
+	budgets := make(map[string]time.Duration)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		stage, duration, found := strings.Cut(item, "=")
+		if !found || !isRunStage(stage) {
+			return nil, fmt.Errorf("invalid --%s '%s'. Expected <stage>=<duration> with a stage of %s", budgetFlag, item, strings.Join(runStageNames, ", "))
+		}
+		budget, err := time.ParseDuration(duration)
+		if err != nil || budget <= 0 {
+			return nil, fmt.Errorf("invalid --%s '%s'. Expected a positive duration such as 30s or 10m", budgetFlag, item)
+		}
+		budgets[stage] = budget
+	}
+	return budgets, nil
+}
+
+func isRunStage(stage string) bool {
+	for _, name := range runStageNames {
+		if name == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// start ends the current stage and starts the next one. It fails, without starting the next stage, when the
+// ended stage went over its budget.
+func (s *runStages) start(stage string) error {
+	err := s.finish()
+	if err != nil {
+		return err
+	}
+	s.current, s.started = stage, time.Now()
+	return nil
+}
+
+// finish ends the current stage, if any, failing when it went over its budget
+func (s *runStages) finish() error {
+	if s.current == "" {
+		return nil
+	}
+	elapsed := time.Since(s.started)
+	timing := StageTiming{Stage: s.current, DurationMs: elapsed.Milliseconds()}
+	var err error
+	if budget, exists := s.budgets[s.current]; exists {
+		timing.BudgetMs = budget.Milliseconds()
+		if elapsed > budget {
+			timing.OverBudget = true
+			err = overBudgetError(s.current, elapsed, budget)
+		}
+	}
+	s.timings = append(s.timings, timing)
+	s.current = ""
+	return err
+}
+
+// skips tells whether the run leaves a stage out
+func (s *runStages) skips(stage string) bool {
+	return s.skipped[stage]
+}
+
+// check fails when the current stage already went over its budget, so a stage can stop between its steps
+func (s *runStages) check() error {
+	if budget, exists := s.budgets[s.current]; exists {
+		if elapsed := time.Since(s.started); elapsed > budget {
+			return overBudgetError(s.current, elapsed, budget)
+		}
+	}
+	return nil
+}
+
+// deadline returns the time the current stage runs out of budget, zero without a budget
+func (s *runStages) deadline() time.Time {
+	if budget, exists := s.budgets[s.current]; exists {
+		return s.started.Add(budget)
+	}
+	return time.Time{}
+}
+
+// overBudget returns the stage that went over its budget, if any
+func (s *runStages) overBudget() string {
+	for _, timing := range s.timings {
+		if timing.OverBudget {
+			return timing.Stage
+		}
+	}
+	return ""
+}
+
+func overBudgetError(stage string, elapsed, budget time.Duration) error {
+	return fmt.Errorf("the %s stage went over its --%s of %s after %s", stage, budgetFlag, budget, elapsed.Round(time.Millisecond))
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStageBudgets(t *testing.T) {
+	budgets, err := parseStageBudgets("parse=30s, audit=10m")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{stageParse: 30 * time.Second, stageAudit: 10 * time.Minute}, budgets)
+
+	budgets, err = parseStageBudgets("")
+	assert.NoError(t, err)
+	assert.Empty(t, budgets)
+
+	for _, value := range []string{"audit", "network=1m", "audit=soon", "report=0s"} {
+		_, err := parseStageBudgets(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestRunStages(t *testing.T) {
+	stages := newRunStages(map[string]time.Duration{stageAudit: time.Millisecond, stageReport: time.Hour})
+	assert.True(t, stages.deadline().IsZero())
+
+	assert.NoError(t, stages.start(stageParse))
+	assert.True(t, stages.deadline().IsZero())
+	assert.NoError(t, stages.check())
+
+	assert.NoError(t, stages.start(stageAudit))
+	assert.False(t, stages.deadline().IsZero())
+	time.Sleep(5 * time.Millisecond)
+	assert.ErrorContains(t, stages.check(), "the audit stage went over its --budget of 1ms")
+
+	// The next stage doesn't start once a stage went over its budget
+	assert.ErrorContains(t, stages.start(stageReport), "the audit stage went over its --budget of 1ms")
+	assert.Equal(t, "", stages.current)
+	assert.Equal(t, stageAudit, stages.overBudget())
+	assert.Equal(t, []string{stageParse, stageAudit}, []string{stages.timings[0].Stage, stages.timings[1].Stage})
+	assert.Equal(t, StageTiming{Stage: stageAudit, DurationMs: stages.timings[1].DurationMs, BudgetMs: 1, OverBudget: true}, stages.timings[1])
+	assert.NoError(t, stages.finish())
+
+	var out bytes.Buffer
+	summary := newRunSummary("audit", &out)
+	summary.recordStages(stages)
+	summary.write(nil)
+	var written RunSummary
+	assert.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(out.String())), &written))
+	assert.Equal(t, stages.timings, written.Stages)
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	bundledFlag = "bundled"
+
+	// Upper bound of a single package.json read from a tarball
+	maxManifestSize = 1 << 20
+)
+
+// Matches the manifests of packages embedded in a tarball, at any node_modules depth
+var bundledManifestPattern = regexp.MustCompile(`^package/(?:.*/)?node_modules/((?:@[^/]+/)?[^/]+)/package\.json$`)
+
+// fetchBundledDependencies downloads the tarballs of the available packages and returns the packages bundled
+// inside them, which bypass lockfile-level curation. Packages already in known are skipped.
+func fetchBundledDependencies(results []audit.AuditResult, known []audit.Dependency, registry *registryConfiguration, numWorkers int) []audit.Dependency {
+	seen := make(map[string]bool)
+	for _, dep := range known {
+		seen[cacheKey(dep.Name, dep.Version)] = true
+	}
+
+	var mu sync.Mutex
+	var bundled []audit.Dependency
+	audit.RunPool(context.Background(), availableResults(results), numWorkers, func(result audit.AuditResult) error {
+		deps, err := downloadBundledDependencies(result.Name, result.Version, registry)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, dep := range deps {
+			key := cacheKey(dep.Name, dep.Version)
+			if !seen[key] {
+				seen[key] = true
+				bundled = append(bundled, dep)
+			}
+		}
+		return nil
+	}, func(result audit.AuditResult, err error) {
+		log.Warn(fmt.Sprintf("Could not inspect %s@%s for bundled packages: %v", result.Name, result.Version, err))
+	})
+
+	sort.Slice(bundled, func(i, j int) bool {
+		return cacheKey(bundled[i].Name, bundled[i].Version) < cacheKey(bundled[j].Name, bundled[j].Version)
+	})
+	return bundled
+}
+
+func downloadBundledDependencies(packageName, packageVersion string, registry *registryConfiguration) ([]audit.Dependency, error) {
+	packageURL, err := audit.TarballURL(registry.registryURL, packageName, packageVersion)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", packageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	registry.credentials().Authorize(req)
+
+	client := registry.httpClient(2 * time.Minute)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response: %d", resp.StatusCode)
+	}
+	return listBundledPackages(resp.Body)
+}
+
+// listBundledPackages enumerates the packages embedded under node_modules in a gzipped package tarball
+func listBundledPackages(tarball io.Reader) ([]audit.Dependency, error) {
+	gz, err := gzip.NewReader(tarball)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tarball: %v", err)
+	}
+	defer gz.Close()
+
+	var deps []audit.Dependency
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading tarball: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg || !bundledManifestPattern.MatchString(header.Name) {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(io.LimitReader(reader, maxManifestSize))
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", header.Name, err)
+		}
+		var manifest struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil || manifest.Name == "" || manifest.Version == "" {
+			// Fixtures and partial manifests are common in node_modules, they aren't installable packages
+			continue
+		}
+		deps = append(deps, audit.Dependency{
+			Name:    manifest.Name,
+			Version: manifest.Version,
+			Type:    audit.TypeBundled,
+			// Packages bundled inside a tarball are never dependencies of the project itself
+			Relationship: audit.RelationshipTransitive,
+		})
+	}
+	return deps, nil
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTarball(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestListBundledPackages(t *testing.T) {
+	tarball := buildTarball(t, map[string]string{
+		"package/package.json":                                               `{"name":"npm","version":"10.0.0"}`,
+		"package/node_modules/abbrev/package.json":                           `{"name":"abbrev","version":"2.0.0"}`,
+		"package/node_modules/@npmcli/arborist/package.json":                 `{"name":"@npmcli/arborist","version":"7.0.0"}`,
+		"package/node_modules/@npmcli/arborist/node_modules/ms/package.json": `{"name":"ms","version":"2.1.3"}`,
+		"package/node_modules/abbrev/test/fixtures/package.json":             `{"name":"fixture"}`,
+	})
+
+	deps, err := listBundledPackages(bytes.NewReader(tarball))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []audit.Dependency{
+		{Name: "abbrev", Version: "2.0.0", Type: audit.TypeBundled, Relationship: audit.RelationshipTransitive},
+		{Name: "@npmcli/arborist", Version: "7.0.0", Type: audit.TypeBundled, Relationship: audit.RelationshipTransitive},
+		{Name: "ms", Version: "2.1.3", Type: audit.TypeBundled, Relationship: audit.RelationshipTransitive},
+	}, deps)
+}
+
+func TestFetchBundledDependencies(t *testing.T) {
+	tarball := buildTarball(t, map[string]string{
+		"package/node_modules/abbrev/package.json": `{"name":"abbrev","version":"2.0.0"}`,
+		"package/node_modules/ms/package.json":     `{"name":"ms","version":"2.1.3"}`,
+	})
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(tarball)
+	}))
+	defer registry.Close()
+
+	known := []audit.Dependency{{Name: "npm", Version: "10.0.0"}, {Name: "ms", Version: "2.1.3"}}
+	results := []audit.AuditResult{{Name: "npm", Version: "10.0.0", StatusCode: http.StatusOK}}
+	bundled := fetchBundledDependencies(results, known, &registryConfiguration{registryURL: registry.URL}, 2)
+	assert.Equal(t, []audit.Dependency{{Name: "abbrev", Version: "2.0.0", Type: audit.TypeBundled, Relationship: audit.RelationshipTransitive}}, bundled)
+}
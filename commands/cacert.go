@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	caCertFlag   = "cacert"
+	insecureFlag = "insecure"
+
+	caCertEnv   = "CA_EXTENSION_CACERT"
+	insecureEnv = "CA_EXTENSION_INSECURE"
+)
+
+func getCACertFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			caCertFlag,
+			"Path of a PEM file of CA certificates trusted for registry connections besides the system ones, e.g. the private CA of a TLS-inspecting proxy",
+			components.WithHelpValue("path"),
+		),
+		components.NewBoolFlag(
+			insecureFlag,
+			"Don't verify the TLS certificates of registry connections. For troubleshooting only, prefer --"+caCertFlag,
+			components.WithBoolDefaultValue(false),
+		),
+	}
+}
+
+func getCACertEnvVars() []components.EnvVar {
+	return []components.EnvVar{
+		{
+			Name:        caCertEnv,
+			Description: "PEM file of the CA certificates trusted for registry connections, used when --" + caCertFlag + " is not set.",
+		},
+		{
+			Name:        insecureEnv,
+			Description: "Set to true to not verify the TLS certificates of registry connections, as with --" + insecureFlag + ".",
+		},
+	}
+}
+
+// loadCACertPool returns the system certificate pool with the certificates of the PEM file added, or nil for the
+// system pool alone when no file is given
+func loadCACertPool(path string) (*x509.CertPool, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading --%s: %v", caCertFlag, err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		// Systems without a readable certificate store have no pool to extend
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("--%s %s has no PEM encoded certificate", caCertFlag, path)
+	}
+	return pool, nil
+}
+
+// getInsecure tells whether TLS certificates aren't verified, as set by --insecure or its environment variable
+func getInsecure(c *components.Context) bool {
+	insecure := c.GetBoolFlagValue(insecureFlag)
+	if !insecure {
+		value := os.Getenv(insecureEnv)
+		if value == "" {
+			value = configSetting(insecureFlag)
+		}
+		insecure, _ = strconv.ParseBool(value)
+	}
+	if insecure {
+		log.Warn(fmt.Sprintf("--%s: the TLS certificates of registry connections are not verified", insecureFlag))
+	}
+	return insecure
+}
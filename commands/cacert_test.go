@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// The certificate of the test server is self-signed, so system roots alone don't trust it
+	registry := &registryConfiguration{registryURL: server.URL}
+	_, err := registry.httpClient(0).Get(server.URL)
+	assert.Error(t, err)
+
+	caCertFile := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caCertFile, certificate, 0600))
+	rootCAs, err := loadCACertPool(caCertFile)
+	require.NoError(t, err)
+	registry = &registryConfiguration{registryURL: server.URL, rootCAs: rootCAs}
+	resp, err := registry.httpClient(0).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	registry = &registryConfiguration{registryURL: server.URL, insecure: true}
+	resp, err = registry.httpClient(0).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestLoadCACertPool(t *testing.T) {
+	pool, err := loadCACertPool("")
+	assert.NoError(t, err)
+	assert.Nil(t, pool)
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0600))
+	_, err = loadCACertPool(notPEM)
+	assert.EqualError(t, err, "--cacert "+notPEM+" has no PEM encoded certificate")
+
+	_, err = loadCACertPool(filepath.Join(t.TempDir(), "missing.pem"))
+	assert.ErrorContains(t, err, "error reading --cacert")
+}
@@ -0,0 +1,297 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	cacheFlag     = "cache"
+	cacheFileFlag = "cache-file"
+	cacheTTLFlag  = "cache-ttl"
+
+	cacheFileEnv = "CA_EXTENSION_CACHE_FILE"
+	cacheTTLEnv  = "CA_EXTENSION_CACHE_TTL"
+
+	// Curation policies change, so outcomes are checked again once they are a day old by default
+	defaultCacheTTL = 24 * time.Hour
+)
+
+// cachedOutcome represents a curation outcome observed for a package version: the status of its check, with the
+// policies blocking it, so cached results read as the checks did
+type cachedOutcome struct {
+	StatusCode int                    `json:"statusCode"`
+	Status     string                 `json:"status,omitempty"`
+	Policies   []audit.CurationPolicy `json:"policies,omitempty"`
+	ObservedAt time.Time              `json:"observedAt"`
+}
+
+// outcomeCache stores curation outcomes keyed by the registry, name and version of the packages, shared by the proxy
+// and audit commands
+type outcomeCache struct {
+	path string
+	// Age after which outcomes are checked again, or 0 to keep them forever
+	ttl time.Duration
+	mu  sync.Mutex
+	// Number of outcomes recorded since the cache was last saved
+	unsaved  int
+	Outcomes map[string]cachedOutcome `json:"outcomes"`
+}
+
+func getCacheFileFlag() components.Flag {
+	return components.NewStringFlag(
+		cacheFileFlag,
+		"Path of the curation outcome cache. Defaults to ca-extension/outcomes.json in the user cache directory",
+		components.WithHelpValue("path"),
+	)
+}
+
+func getCacheTTLFlag() components.Flag {
+	return components.NewStringFlag(
+		cacheTTLFlag,
+		fmt.Sprintf("Age after which cached outcomes are checked again, e.g. 6h, or 0 to keep them until the cache file is removed. Defaults to %s", defaultCacheTTL),
+		components.WithHelpValue("duration"),
+	)
+}
+
+// openOutcomeCache loads the cache of the flags, with their TTL
+func openOutcomeCache(c *components.Context) (*outcomeCache, error) {
+	ttl := defaultCacheTTL
+	if value := flagOrEnv(c, cacheTTLFlag, cacheTTLEnv); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if value == "0" {
+			parsed, err = 0, nil
+		}
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("--%s must be a duration such as 6h, or 0, got '%s'", cacheTTLFlag, value)
+		}
+		ttl = parsed
+	}
+	path, err := getCacheFilePath(c)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := loadOutcomeCache(path)
+	if err != nil {
+		return nil, err
+	}
+	cache.ttl = ttl
+	return cache, nil
+}
+
+func getCacheFilePath(c *components.Context) (string, error) {
+	if path := flagOrEnv(c, cacheFileFlag, cacheFileEnv); path != "" {
+		return path, nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error locating the user cache directory, use --%s: %v", cacheFileFlag, err)
+	}
+	return filepath.Join(cacheDir, "ca-extension", "outcomes.json"), nil
+}
+
+// loadOutcomeCache reads the cache file, starting empty when it doesn't exist yet
+func loadOutcomeCache(path string) (*outcomeCache, error) {
+	cache := &outcomeCache{path: path, Outcomes: make(map[string]cachedOutcome)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading cache %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("error parsing cache %s: %v", path, err)
+	}
+	if cache.Outcomes == nil {
+		cache.Outcomes = make(map[string]cachedOutcome)
+	}
+	return cache, nil
+}
+
+func cacheKey(name, version string) string {
+	return name + "@" + version
+}
+
+// outcomeKey keys the outcome of a package by the ecosystem and the URL of the registry checking it, as in
+// "npm https://acme.jfrog.io/artifactory/api/npm/npm-remote lodash@4.17.21", since the same version may be approved
+// by one repository or ecosystem and blocked by another
+func outcomeKey(registry *registryConfiguration, dep audit.Dependency) string {
+	ecosystem := registry.ecosystem
+	if ecosystem == "" {
+		ecosystem = ecosystemNpm
+	}
+	registryURL := registry.publishEndpoint()
+	if dep.Ecosystem != "" && dep.Ecosystem != ecosystem {
+		ecosystem, registryURL = dep.Ecosystem, registry.ecosystemRegistries[dep.Ecosystem]
+	} else if scope, _, found := strings.Cut(dep.Name, "/"); found && ecosystem == ecosystemNpm {
+		if routed, exists := registry.scopes[scope]; exists {
+			registryURL = routed.registryURL
+		}
+	}
+	return ecosystem + " " + strings.TrimSuffix(stripURLCredentials(registryURL), "/") + " " + cacheKey(dep.Name, dep.Version)
+}
+
+// lookup returns the outcome of a package version on the registry, unless it expired
+func (cache *outcomeCache) lookup(registry *registryConfiguration, dep audit.Dependency) (cachedOutcome, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	outcome, exists := cache.Outcomes[outcomeKey(registry, dep)]
+	if exists && cache.expired(outcome) {
+		return cachedOutcome{}, false
+	}
+	return outcome, exists
+}
+
+func (cache *outcomeCache) expired(outcome cachedOutcome) bool {
+	return cache.ttl > 0 && time.Since(outcome.ObservedAt) > cache.ttl
+}
+
+// record stores a final verdict of the registry on a package. Transient failures and packages pending curation
+// aren't cached, so they are checked again next time.
+func (cache *outcomeCache) record(registry *registryConfiguration, dep audit.Dependency, result audit.AuditResult) bool {
+	if !isCacheableResult(result) {
+		return false
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.Outcomes[outcomeKey(registry, dep)] = cachedOutcome{
+		StatusCode: result.StatusCode,
+		Status:     result.Status,
+		Policies:   result.Policies,
+		ObservedAt: time.Now().UTC(),
+	}
+	cache.unsaved++
+	return true
+}
+
+// result replays the outcome as the result of the dependency, with the status of its check
+func (outcome cachedOutcome) result(dep audit.Dependency) audit.AuditResult {
+	status := outcome.Status
+	if status == "" {
+		status = audit.StatusForCode(outcome.StatusCode)
+	}
+	return audit.AuditResult{
+		Name:         dep.Name,
+		Version:      dep.Version,
+		Type:         dep.Type,
+		Status:       status + " (cached)",
+		StatusCode:   outcome.StatusCode,
+		Policies:     outcome.Policies,
+		Relationship: dep.Relationship,
+	}
+}
+
+// flush saves the cache once at least batch outcomes were recorded since it was last saved
+func (cache *outcomeCache) flush(batch int) error {
+	cache.mu.Lock()
+	unsaved := cache.unsaved
+	cache.mu.Unlock()
+	if unsaved == 0 || unsaved < batch {
+		return nil
+	}
+	return cache.save()
+}
+
+// save writes the cache, leaving out the expired outcomes. The cache is written to a temporary file renamed over
+// it, so concurrent readers and interrupted writes never see a partial cache.
+func (cache *outcomeCache) save() error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for key, outcome := range cache.Outcomes {
+		if cache.expired(outcome) {
+			delete(cache.Outcomes, key)
+		}
+	}
+
+	jsonData, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cache.path), 0755); err != nil {
+		return fmt.Errorf("error creating cache directory: %v", err)
+	}
+	if err := writeFileAtomic(cache.path, jsonData); err != nil {
+		return fmt.Errorf("error writing cache %s: %v", cache.path, err)
+	}
+	cache.unsaved = 0
+	return nil
+}
+
+// writeFileAtomic writes a file through a temporary file of its directory renamed over it
+func writeFileAtomic(path string, data []byte) error {
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+// isCacheableResult reports whether a result is a final verdict: approved, blocked or not found
+func isCacheableResult(result audit.AuditResult) bool {
+	if result.Error != nil || result.Pending {
+		return false
+	}
+	return result.StatusCode == http.StatusOK || result.StatusCode == http.StatusForbidden || result.StatusCode == http.StatusNotFound
+}
+
+// auditWithCache answers the dependencies found in the cache directly, and audits and caches the others
+func auditWithCache(deps []audit.Dependency, cache *outcomeCache, registry *registryConfiguration, auditDeps func([]audit.Dependency) []audit.AuditResult, stream *resultStream) []audit.AuditResult {
+	results := make([]audit.AuditResult, len(deps))
+	var missing []audit.Dependency
+	var missingIndexes []int
+
+	for i, dep := range deps {
+		// Outcomes of pinned tarballs are those of their host, not of the registry
+		if outcome, exists := cache.lookup(registry, dep); exists && dep.Tarball == "" {
+			results[i] = outcome.result(dep)
+			results[i].Index = i
+			stream.send(results[i])
+			continue
+		}
+		missing = append(missing, dep)
+		missingIndexes = append(missingIndexes, i)
+	}
+
+	if len(missing) > 0 {
+		for j, result := range auditDeps(missing) {
+			if j >= len(missingIndexes) {
+				break
+			}
+			result.Index = missingIndexes[j]
+			results[missingIndexes[j]] = result
+			if missing[j].Tarball == "" {
+				cache.record(registry, missing[j], result)
+			}
+		}
+	}
+	return results
+}
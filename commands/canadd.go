@@ -0,0 +1,268 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-cli-plugin-template/internal/semver"
+)
+
+const (
+	minAgeDaysFlag      = "min-age-days"
+	allowedLicensesFlag = "allowed-licenses"
+
+	defaultMinAgeDays = 3
+)
+
+// packument holds the parts of a registry package document used to pick and vet a version
+type packument struct {
+	DistTags map[string]string `json:"dist-tags"`
+	Versions map[string]struct {
+		License    json.RawMessage `json:"license"`
+		Deprecated string          `json:"deprecated"`
+	} `json:"versions"`
+	Time       map[string]string `json:"time"`
+	Repository json.RawMessage   `json:"repository"`
+}
+
+func GetCanIAddCommand() components.Command {
+	return components.Command{
+		Name:        "can-i-add",
+		Description: "Verifies a package before adding it: resolves the version to install and checks its curation status, license and age.",
+		Arguments:   getCanIAddArguments(),
+		Flags:       getCanIAddFlags(),
+		EnvVars:     getRegistryEnvVars(),
+		Action: func(c *components.Context) error {
+			return canIAddCmd(c)
+		},
+	}
+}
+
+func getCanIAddArguments() []components.Argument {
+	return []components.Argument{
+		{
+			Name:        "package",
+			Description: "The package to add, as <name>, <name>@<range> or <name>@<dist-tag>, e.g. lodash@^4.17.0.",
+		},
+	}
+}
+
+func getCanIAddFlags() []components.Flag {
+	return append(getRegistryFlags(),
+		components.NewStringFlag(
+			minAgeDaysFlag,
+			"Minimum age in days of the resolved version, as freshly published versions are the most likely to be compromised",
+			components.WithIntDefaultValue(defaultMinAgeDays),
+		),
+		components.NewStringFlag(
+			allowedLicensesFlag,
+			"Comma separated SPDX license identifiers the package must be released under, e.g. MIT,Apache-2.0,ISC",
+			components.WithHelpValue("licenses"),
+		),
+		getAccessibleFlag(),
+	)
+}
+
+// canIAdd holds the policy the resolved version is vetted against
+type canIAdd struct {
+	registry        *registryConfiguration
+	minAge          time.Duration
+	allowedLicenses []string
+	now             func() time.Time
+}
+
+func canIAddCmd(c *components.Context) error {
+	if len(c.Arguments) != 1 {
+		return wrongArguments("can-i-add", "<name>[@<range>]", len(c.Arguments))
+	}
+	registry, err := getRegistryConfiguration(c)
+	if err != nil {
+		return err
+	}
+	minAgeDays, err := c.GetIntFlagValue(minAgeDaysFlag)
+	if err != nil {
+		return err
+	}
+
+	a := &canIAdd{
+		registry:        registry,
+		minAge:          time.Duration(minAgeDays) * 24 * time.Hour,
+		allowedLicenses: splitList(c.GetStringFlagValue(allowedLicensesFlag)),
+		now:             time.Now,
+	}
+	name, versionRange := parsePackageRange(c.Arguments[0])
+	version, checks, err := a.run(name, versionRange)
+	if err != nil {
+		return err
+	}
+
+	display := getDisplayFormat(c)
+	failed := 0
+	for _, check := range checks {
+		fmt.Println(formatDoctorCheck(check, display))
+		if check.status == checkFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%s@%s failed %d checks", name, version, failed)
+	}
+	fmt.Printf("\npnpm add %s@%s\n", name, version)
+	return nil
+}
+
+// parsePackageRange splits <name>[@<range>], the range defaulting to the latest dist-tag
+func parsePackageRange(spec string) (string, string) {
+	if name, versionRange := audit.ParsePackageKey(spec); name != "" {
+		return name, versionRange
+	}
+	return spec, "latest"
+}
+
+// run resolves the version to add and vets it
+func (a *canIAdd) run(name, versionRange string) (string, []doctorCheck, error) {
+	doc, err := fetchPackument(name, a.registry)
+	if err != nil {
+		return "", nil, fmt.Errorf("error reading %s from the registry: %v", name, err)
+	}
+	version, err := resolveVersion(doc, versionRange)
+	if err != nil {
+		return "", nil, fmt.Errorf("error resolving %s@%s: %v", name, versionRange, err)
+	}
+
+	checks := []doctorCheck{{name: "Version", status: checkPass, detail: fmt.Sprintf("%s@%s resolves to %s", name, versionRange, version)}}
+	result := a.registry.auditRegistry().Check(audit.Dependency{Name: name, Version: version, Type: audit.TypePackage})
+	curation := doctorCheck{name: "Curation", status: checkPass, detail: result.Status}
+	if isBlocking(result) {
+		curation.status = checkFail
+		if result.Error != nil {
+			curation.detail += " - Error: " + result.Error.Error()
+		}
+	}
+	checks = append(checks, curation, a.checkLicense(licenseOf(doc.Versions[version].License)), a.checkAge(doc.Time[version]))
+	if deprecated := doc.Versions[version].Deprecated; deprecated != "" {
+		checks = append(checks, doctorCheck{name: "Deprecation", status: checkWarn, detail: deprecated})
+	}
+	return version, checks, nil
+}
+
+func (a *canIAdd) checkLicense(license string) doctorCheck {
+	if license == "" {
+		return doctorCheck{name: "License", status: checkWarn, detail: "no license declared"}
+	}
+	if len(a.allowedLicenses) == 0 {
+		return doctorCheck{name: "License", status: checkPass, detail: license}
+	}
+	// SPDX expressions such as (MIT OR Apache-2.0) pass when any of their licenses is allowed
+	for _, id := range strings.FieldsFunc(license, func(r rune) bool { return r == ' ' || r == '(' || r == ')' }) {
+		for _, allowed := range a.allowedLicenses {
+			if strings.EqualFold(id, allowed) {
+				return doctorCheck{name: "License", status: checkPass, detail: license}
+			}
+		}
+	}
+	return doctorCheck{name: "License", status: checkFail, detail: license + " is not an allowed license"}
+}
+
+func (a *canIAdd) checkAge(published string) doctorCheck {
+	publishedAt, err := time.Parse(time.RFC3339, published)
+	if err != nil {
+		return doctorCheck{name: "Age", status: checkWarn, detail: "publish time unknown"}
+	}
+	age := a.now().Sub(publishedAt)
+	days := int(age.Hours() / 24)
+	if age < a.minAge {
+		return doctorCheck{name: "Age", status: checkFail, detail: fmt.Sprintf("published %d days ago, less than %d", days, int(a.minAge.Hours()/24))}
+	}
+	return doctorCheck{name: "Age", status: checkPass, detail: fmt.Sprintf("published %d days ago", days)}
+}
+
+func fetchPackument(packageName string, registry *registryConfiguration) (*packument, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s", registry.registryURL, audit.EscapeNpmName(packageName)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	registry.credentials().Authorize(req)
+
+	client := registry.httpClient(registry.requestTimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response: %d", resp.StatusCode)
+	}
+
+	var doc packument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error parsing metadata: %v", err)
+	}
+	return &doc, nil
+}
+
+// resolveVersion picks the version npm would install: the dist-tag's version, else the highest version matching
+// the range, preferring versions that aren't deprecated
+func resolveVersion(doc *packument, versionRange string) (string, error) {
+	if version, exists := doc.DistTags[versionRange]; exists {
+		return version, nil
+	}
+	// Like npm, the latest version wins when it satisfies the range
+	if latest, exists := doc.DistTags["latest"]; exists {
+		if satisfied, err := semver.Satisfies(latest, versionRange); err == nil && satisfied && doc.Versions[latest].Deprecated == "" {
+			return latest, nil
+		}
+	}
+
+	if _, err := semver.Satisfies("0.0.0", versionRange); err != nil {
+		return "", err
+	}
+	best, bestDeprecated := "", ""
+	for version, info := range doc.Versions {
+		if satisfied, err := semver.Satisfies(version, versionRange); err != nil || !satisfied {
+			continue
+		}
+		candidate := &best
+		if info.Deprecated != "" {
+			candidate = &bestDeprecated
+		}
+		if *candidate == "" || isNewerVersion(version, *candidate) {
+			*candidate = version
+		}
+	}
+	if best == "" {
+		best = bestDeprecated
+	}
+	if best == "" {
+		return "", errors.New("no version matches")
+	}
+	return best, nil
+}
+
+func isNewerVersion(a, b string) bool {
+	aVersion, aErr := semver.Parse(a)
+	bVersion, bErr := semver.Parse(b)
+	return aErr == nil && bErr == nil && semver.Compare(aVersion, bVersion) > 0
+}
+
+// licenseOf reads the license field, a SPDX expression or, in older packages, a {type} object
+func licenseOf(raw json.RawMessage) string {
+	var license string
+	if err := json.Unmarshal(raw, &license); err == nil {
+		return license
+	}
+	var licenseObject struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &licenseObject); err == nil {
+		return licenseObject.Type
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const lodashPackument = `{
+  "dist-tags": {"latest": "4.17.21", "next": "5.0.0-beta.1"},
+  "versions": {
+    "4.17.20": {"license": "MIT"},
+    "4.17.21": {"license": "MIT"},
+    "4.18.0": {"license": "MIT", "deprecated": "broken release"},
+    "5.0.0-beta.1": {"license": {"type": "MIT"}}
+  },
+  "time": {"4.17.21": "2021-02-20T15:42:16.891Z", "5.0.0-beta.1": "2026-10-13T00:00:00Z"}
+}`
+
+func TestResolveVersion(t *testing.T) {
+	doc := &packument{}
+	assert.NoError(t, json.Unmarshal([]byte(lodashPackument), doc))
+
+	tests := map[string]string{
+		"latest":   "4.17.21",
+		"next":     "5.0.0-beta.1",
+		"^4.17.0":  "4.17.21",
+		"~4.17.20": "4.17.21",
+		"4.17.20":  "4.17.20",
+		">=4.18.0": "4.18.0",
+	}
+	for versionRange, expected := range tests {
+		version, err := resolveVersion(doc, versionRange)
+		assert.NoError(t, err, versionRange)
+		assert.Equal(t, expected, version, versionRange)
+	}
+
+	_, err := resolveVersion(doc, "^6.0.0")
+	assert.ErrorContains(t, err, "no version matches")
+}
+
+func TestCanIAddRun(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/-/") {
+			if strings.HasSuffix(r.URL.Path, "5.0.0-beta.1.tgz") {
+				w.WriteHeader(http.StatusForbidden)
+			}
+			return
+		}
+		w.Write([]byte(lodashPackument))
+	}))
+	defer registry.Close()
+
+	a := &canIAdd{
+		registry:        &registryConfiguration{registryURL: registry.URL},
+		minAge:          3 * 24 * time.Hour,
+		allowedLicenses: []string{"MIT"},
+		now:             func() time.Time { return time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC) },
+	}
+
+	version, checks, err := a.run("lodash", "^4.17.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "4.17.21", version)
+	for _, check := range checks {
+		assert.Equal(t, checkPass, check.status, check.name)
+	}
+
+	version, checks, err = a.run("lodash", "next")
+	assert.NoError(t, err)
+	assert.Equal(t, "5.0.0-beta.1", version)
+	statuses := make(map[string]string)
+	for _, check := range checks {
+		statuses[check.name] = check.status
+	}
+	assert.Equal(t, map[string]string{"Version": checkPass, "Curation": checkFail, "License": checkPass, "Age": checkFail}, statuses)
+}
+
+func TestCheckLicense(t *testing.T) {
+	a := &canIAdd{allowedLicenses: []string{"Apache-2.0"}}
+	assert.Equal(t, checkPass, a.checkLicense("(MIT OR Apache-2.0)").status)
+	assert.Equal(t, checkFail, a.checkLicense("GPL-3.0").status)
+	assert.Equal(t, checkWarn, a.checkLicense("").status)
+}
+
+func TestParsePackageRange(t *testing.T) {
+	name, versionRange := parsePackageRange("@types/node")
+	assert.Equal(t, "@types/node", name)
+	assert.Equal(t, "latest", versionRange)
+
+	name, versionRange = parsePackageRange("@types/node@^20")
+	assert.Equal(t, "@types/node", name)
+	assert.Equal(t, "^20", versionRange)
+}
@@ -0,0 +1,289 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	recordFlag = "record"
+	replayFlag = "replay"
+
+	cassetteVersion = 1
+)
+
+// Response headers left out of cassettes, which are attached to CI failure reports
+var unrecordedHeaders = []string{"Set-Cookie"}
+
+// httpCassette holds the registry interactions of a run, recorded by --record to replay them with --replay. A nil
+// cassette neither records nor replays.
+type httpCassette struct {
+	path   string
+	replay bool
+
+	mu           sync.Mutex
+	interactions []*cassetteInteraction
+	// Recorded interactions of each request, and how many of them were replayed
+	byRequest map[string][]*cassetteInteraction
+	replayed  map[string]int
+}
+
+// cassetteFile is the JSON document of a cassette
+type cassetteFile struct {
+	Version      int                    `json:"version"`
+	RecordedAt   time.Time              `json:"recordedAt"`
+	Interactions []*cassetteInteraction `json:"interactions"`
+}
+
+// cassetteInteraction is a registry request and its response, or the error it failed with. The request headers,
+// which hold the credentials, aren't recorded.
+type cassetteInteraction struct {
+	Method        string      `json:"method"`
+	URL           string      `json:"url"`
+	StatusCode    int         `json:"statusCode,omitempty"`
+	Header        http.Header `json:"header,omitempty"`
+	ContentLength int64       `json:"contentLength,omitempty"`
+	// The part of the body the client read, base64 encoded, and the error reading more of it failed with
+	Body      []byte `json:"body,omitempty"`
+	BodyError string `json:"bodyError,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Timeout   bool   `json:"timeout,omitempty"`
+}
+
+func getCassetteFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			recordFlag,
+			"Path of a JSON cassette to record the registry responses of the run to, e.g. to debug a flaky CI failure with --"+replayFlag,
+			components.WithHelpValue("path"),
+		),
+		components.NewStringFlag(
+			replayFlag,
+			"Path of a cassette written by --"+recordFlag+" to replay the registry responses of, without sending any registry request",
+			components.WithHelpValue("path"),
+		),
+	}
+}
+
+// getCassette returns the cassette of --record or --replay, if any
+func getCassette(c *components.Context) (*httpCassette, error) {
+	record, replay := c.GetStringFlagValue(recordFlag), c.GetStringFlagValue(replayFlag)
+	switch {
+	case record != "" && replay != "":
+		return nil, fmt.Errorf("--%s and --%s can't be combined", recordFlag, replayFlag)
+	case replay != "":
+		return loadCassette(replay)
+	case record != "":
+		return &httpCassette{path: record}, nil
+	}
+	return nil, nil
+}
+
+// loadCassette reads a cassette to replay
+func loadCassette(path string) (*httpCassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cassette: %v", err)
+	}
+	var file cassetteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing cassette %s: %v", path, err)
+	}
+	if file.Version != cassetteVersion {
+		return nil, fmt.Errorf("unsupported version %d of cassette %s. Expected %d", file.Version, path, cassetteVersion)
+	}
+	cassette := &httpCassette{
+		path:         path,
+		replay:       true,
+		interactions: file.Interactions,
+		byRequest:    make(map[string][]*cassetteInteraction),
+		replayed:     make(map[string]int),
+	}
+	for _, interaction := range file.Interactions {
+		key := cassetteKey(interaction.Method, interaction.URL)
+		cassette.byRequest[key] = append(cassette.byRequest[key], interaction)
+	}
+	log.Info(fmt.Sprintf("Replaying %d registry responses recorded %s from %s", len(file.Interactions), file.RecordedAt.Format(time.RFC3339), path))
+	return cassette, nil
+}
+
+// recordPath returns the path the cassette is written to, or an empty path when it isn't recording
+func (cassette *httpCassette) recordPath() string {
+	if cassette == nil || cassette.replay {
+		return ""
+	}
+	return cassette.path
+}
+
+// transport records the interactions of base, or replays them instead of sending the requests to base
+func (cassette *httpCassette) transport(base http.RoundTripper) http.RoundTripper {
+	if cassette == nil {
+		return base
+	}
+	return &cassetteTransport{base: base, cassette: cassette}
+}
+
+// save writes the recorded interactions to the cassette, logging instead of failing the run it records
+func (cassette *httpCassette) save() {
+	if cassette.recordPath() == "" {
+		return
+	}
+	cassette.mu.Lock()
+	data, err := json.MarshalIndent(cassetteFile{Version: cassetteVersion, RecordedAt: time.Now().UTC(), Interactions: cassette.interactions}, "", "  ")
+	count := len(cassette.interactions)
+	cassette.mu.Unlock()
+	if err == nil {
+		err = os.WriteFile(cassette.path, data, 0644)
+	}
+	if err != nil {
+		log.Warn("Could not write the cassette:", err.Error())
+		return
+	}
+	log.Info(fmt.Sprintf("Recorded %d registry responses to %s", count, cassette.path))
+}
+
+// cassetteKey identifies the request of an interaction, without the credentials of its URL
+func cassetteKey(method, requestURL string) string {
+	return method + " " + stripURLCredentials(requestURL)
+}
+
+// cassetteTransport records or replays the registry interactions of a cassette
+type cassetteTransport struct {
+	base     http.RoundTripper
+	cassette *httpCassette
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.cassette.replay {
+		return t.cassette.replayRequest(req)
+	}
+	resp, err := t.base.RoundTrip(req)
+	// Requests aborted by an interrupt aren't part of the run to replay
+	if errors.Is(req.Context().Err(), context.Canceled) {
+		return resp, err
+	}
+	interaction := &cassetteInteraction{Method: req.Method, URL: stripURLCredentials(req.URL.String())}
+	if err != nil {
+		interaction.Error = err.Error()
+		interaction.Timeout = audit.IsTimeout(err) || errors.Is(req.Context().Err(), context.DeadlineExceeded)
+	} else {
+		interaction.StatusCode, interaction.ContentLength = resp.StatusCode, resp.ContentLength
+		interaction.Header = resp.Header.Clone()
+		for _, name := range unrecordedHeaders {
+			interaction.Header.Del(name)
+		}
+		resp.Body = &recordingBody{ReadCloser: resp.Body, cassette: t.cassette, interaction: interaction}
+	}
+	t.cassette.mu.Lock()
+	t.cassette.interactions = append(t.cassette.interactions, interaction)
+	t.cassette.mu.Unlock()
+	return resp, err
+}
+
+// replayRequest answers a request with the next of its recorded interactions, in the order they were recorded,
+// e.g. the failed attempts of a check before its retry succeeded
+func (cassette *httpCassette) replayRequest(req *http.Request) (*http.Response, error) {
+	key := cassetteKey(req.Method, req.URL.String())
+	cassette.mu.Lock()
+	recorded, index := cassette.byRequest[key], cassette.replayed[key]
+	cassette.replayed[key]++
+	cassette.mu.Unlock()
+	if index >= len(recorded) {
+		return nil, fmt.Errorf("%s was requested %d times, but the cassette %s recorded %d", key, index+1, cassette.path, len(recorded))
+	}
+	interaction := recorded[index]
+	if interaction.Error != "" {
+		return nil, &replayedError{message: interaction.Error, timeout: interaction.Timeout}
+	}
+	var bodyErr error
+	if interaction.BodyError != "" {
+		bodyErr = errors.New(interaction.BodyError)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Header.Clone(),
+		ContentLength: interaction.ContentLength,
+		Body:          &replayedBody{Reader: bytes.NewReader(interaction.Body), err: bodyErr},
+		Request:       req,
+	}, nil
+}
+
+// recordingBody records the part of a response body the client reads, once it is read or closed
+type recordingBody struct {
+	io.ReadCloser
+	cassette    *httpCassette
+	interaction *cassetteInteraction
+
+	body bytes.Buffer
+	once sync.Once
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.body.Write(p[:n])
+	if err != nil {
+		b.finish(err)
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	b.finish(nil)
+	return b.ReadCloser.Close()
+}
+
+func (b *recordingBody) finish(err error) {
+	b.once.Do(func() {
+		b.cassette.mu.Lock()
+		defer b.cassette.mu.Unlock()
+		b.interaction.Body = b.body.Bytes()
+		if err != nil && err != io.EOF {
+			b.interaction.BodyError = err.Error()
+		}
+	})
+}
+
+// replayedBody serves a recorded body, then the error reading it failed with, if any
+type replayedBody struct {
+	*bytes.Reader
+	err error
+}
+
+func (b *replayedBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if err == io.EOF && b.err != nil {
+		return n, b.err
+	}
+	return n, err
+}
+
+func (b *replayedBody) Close() error {
+	return nil
+}
+
+// replayedError is a recorded request error, which keeps whether it was a timeout so replayed checks time out
+// and are retried as the recorded ones were
+type replayedError struct {
+	message string
+	timeout bool
+}
+
+func (e *replayedError) Error() string   { return e.message }
+func (e *replayedError) Timeout() bool   { return e.timeout }
+func (e *replayedError) Temporary() bool { return e.timeout }
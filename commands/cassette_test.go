@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassetteRecordReplay(t *testing.T) {
+	var flaky int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			if atomic.AddInt32(&flaky, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"name":"flaky"}`))
+		case "/slow":
+			<-r.Context().Done()
+		default:
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recording := &httpCassette{path: path}
+	assert.Equal(t, path, recording.recordPath())
+	client := &http.Client{Transport: recording.transport(http.DefaultTransport), Timeout: 200 * time.Millisecond}
+	get := func(client *http.Client, method, target string) (*http.Response, string, error) {
+		req, err := http.NewRequest(method, target, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body), nil
+	}
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusOK} {
+		resp, _, err := get(client, http.MethodGet, server.URL+"/flaky")
+		require.NoError(t, err)
+		assert.Equal(t, status, resp.StatusCode)
+	}
+	_, _, err := get(client, http.MethodHead, server.URL+"/blocked")
+	require.NoError(t, err)
+	_, _, err = get(client, http.MethodGet, server.URL+"/slow")
+	require.Error(t, err)
+	recording.save()
+	server.Close()
+
+	replaying, err := loadCassette(path)
+	require.NoError(t, err)
+	assert.Empty(t, replaying.recordPath())
+	client = &http.Client{Transport: replaying.transport(nil), Timeout: time.Minute}
+	resp, _, err := get(client, http.MethodGet, server.URL+"/flaky")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	resp, body, err := get(client, http.MethodGet, server.URL+"/flaky")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"name":"flaky"}`, body)
+	_, _, err = get(client, http.MethodGet, server.URL+"/flaky")
+	assert.ErrorContains(t, err, "was requested 3 times, but the cassette")
+
+	resp, _, err = get(client, http.MethodHead, server.URL+"/blocked")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Set-Cookie"))
+	_, _, err = get(client, http.MethodGet, server.URL+"/slow")
+	assert.True(t, audit.IsTimeout(err), err)
+	_, _, err = get(client, http.MethodGet, server.URL+"/unrecorded")
+	assert.ErrorContains(t, err, "recorded 0")
+
+	_, err = loadCassette(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "error reading cassette")
+
+	var none *httpCassette
+	assert.Equal(t, http.DefaultTransport, none.transport(http.DefaultTransport))
+	none.save()
+}
+
+func TestAuditReplaysCassette(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "react-dom") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	dir := t.TempDir()
+	cassettePath := filepath.Join(dir, "cassette.json")
+	run := func(cassette *httpCassette, output string) *AuditReport {
+		notifications, err := newNotificationDispatcher(&notificationConfig{}, nil)
+		require.NoError(t, err)
+		conf := &auditConfiguration{
+			registry:   &registryConfiguration{registryURL: server.URL, requestTimeout: time.Minute, cassette: cassette},
+			lockFile:   filepath.Join("..", "audit", "testdata", "pnpm-workspace", "pnpm-lock.yaml"),
+			workers:    2,
+			treeOutput: filepath.Join(dir, defaultTreeFileName),
+			output:     filepath.Join(dir, output),
+			display:    newDisplayFormat(true),
+			stages:     newRunStages(nil),
+
+			notifications: notifications,
+		}
+		require.NoError(t, runAudit(context.Background(), conf))
+		cassette.save()
+		report, err := loadAuditReport(conf.output)
+		require.NoError(t, err)
+		return report
+	}
+	recorded := run(&httpCassette{path: cassettePath}, "recorded.json")
+	server.Close()
+
+	cassette, err := loadCassette(cassettePath)
+	require.NoError(t, err)
+	replayed := run(cassette, "replayed.json")
+	statuses := func(report *AuditReport) map[string]string {
+		byName := make(map[string]string)
+		for _, result := range report.Results {
+			byName[result.Name] = result.Status
+		}
+		return byName
+	}
+	assert.Equal(t, statuses(recorded), statuses(replayed))
+	assert.Equal(t, 1, replayed.Blocked)
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+func GetCheckCommand() components.Command {
+	return components.Command{
+		Name:        "check",
+		Description: "Checks the curation status of individual packages.",
+		Aliases:     []string{"c"},
+		Arguments:   getCheckArguments(),
+		Flags:       append(getRegistryFlags(), getWorkersFlag(), getAccessibleFlag()),
+		EnvVars:     getRegistryEnvVars(),
+		Action: func(c *components.Context) error {
+			return checkCmd(c)
+		},
+	}
+}
+
+func getCheckArguments() []components.Argument {
+	return []components.Argument{
+		{
+			Name:        "packages",
+			Description: "One or more packages to check, in <name>@<version> form, e.g. lodash@4.17.21 @types/node@20.11.0.",
+		},
+	}
+}
+
+func checkCmd(c *components.Context) error {
+	if len(c.Arguments) == 0 {
+		return wrongArguments("check", "<name>@<version> [<name>@<version>...]", len(c.Arguments))
+	}
+	deps, err := parsePackageSpecs(c.Arguments)
+	if err != nil {
+		return err
+	}
+	registry, err := getRegistryConfiguration(c)
+	if err != nil {
+		return err
+	}
+	workers, err := getWorkers(c)
+	if err != nil {
+		return err
+	}
+	registry.workers = workers
+
+	display := getDisplayFormat(c)
+	blocked := 0
+	for _, result := range collectAuditResults(context.Background(), deps, registry, workers, false, nil, time.Time{}) {
+		fmt.Printf("%s@%s %s", result.Name, result.Version, display.status(result.Status))
+		if result.Error != nil {
+			fmt.Printf(" - Error: %v", result.Error)
+		}
+		fmt.Println()
+		if isBlocking(result) {
+			blocked++
+		}
+	}
+
+	if blocked > 0 {
+		return fmt.Errorf("%d of %d packages are not available from the curated registry", blocked, len(deps))
+	}
+	return nil
+}
+
+// parsePackageSpecs converts <name>@<version> arguments to dependencies
+func parsePackageSpecs(specs []string) ([]audit.Dependency, error) {
+	var deps []audit.Dependency
+	for _, spec := range specs {
+		name, version := audit.ParsePackageKey(spec)
+		if name == "" || version == "" {
+			return nil, fmt.Errorf("invalid package '%s'. Expected <name>@<version>", spec)
+		}
+		deps = append(deps, audit.Dependency{
+			Name:    name,
+			Version: version,
+			Type:    "package",
+		})
+	}
+	return deps, nil
+}
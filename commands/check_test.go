@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePackageSpecs(t *testing.T) {
+	deps, err := parsePackageSpecs([]string{"lodash@4.17.21", "@types/node@20.11.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, []audit.Dependency{
+		{Name: "lodash", Version: "4.17.21", Type: "package"},
+		{Name: "@types/node", Version: "20.11.0", Type: "package"},
+	}, deps)
+}
+
+func TestParsePackageSpecsInvalid(t *testing.T) {
+	_, err := parsePackageSpecs([]string{"lodash"})
+	assert.ErrorContains(t, err, "invalid package 'lodash'")
+}
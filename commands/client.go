@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+// Requests to the daemon are routed by path only, the host names no listener
+const daemonBaseURL = "http://" + appName
+
+// daemonClient talks to the daemon listening on a unix socket
+type daemonClient struct {
+	socket string
+	http   *http.Client
+}
+
+func GetClientNamespace() components.Namespace {
+	return components.Namespace{
+		Name:        "client",
+		Description: "Talks to the running 'daemon', sharing its cache, token and registry connections.",
+		Commands: []components.Command{
+			GetClientCheckCommand(),
+			GetClientStatusCommand(),
+			GetClientStopCommand(),
+		},
+	}
+}
+
+func GetClientCheckCommand() components.Command {
+	return components.Command{
+		Name:        "check",
+		Description: "Checks the curation status of individual packages through the daemon.",
+		Arguments:   getCheckArguments(),
+		Flags:       []components.Flag{getSocketFlag(), getAccessibleFlag()},
+		EnvVars:     []components.EnvVar{getSocketEnvVar()},
+		Action: func(c *components.Context) error {
+			return clientCheckCmd(c)
+		},
+	}
+}
+
+func GetClientStatusCommand() components.Command {
+	return components.Command{
+		Name:        "status",
+		Description: "Prints the registry, uptime and checks of the daemon.",
+		Flags:       []components.Flag{getSocketFlag(), getUTCFlag()},
+		EnvVars:     []components.EnvVar{getSocketEnvVar()},
+		Action: func(c *components.Context) error {
+			return clientStatusCmd(c)
+		},
+	}
+}
+
+func GetClientStopCommand() components.Command {
+	return components.Command{
+		Name:        "stop",
+		Description: "Stops the daemon.",
+		Flags:       []components.Flag{getSocketFlag()},
+		EnvVars:     []components.EnvVar{getSocketEnvVar()},
+		Action: func(c *components.Context) error {
+			return clientStopCmd(c)
+		},
+	}
+}
+
+func newDaemonClient(socket string) *daemonClient {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &daemonClient{socket: socket, http: &http.Client{Transport: transport, Timeout: time.Minute}}
+}
+
+// do sends a request to the daemon and decodes its JSON response into v, unless v is nil
+func (client *daemonClient) do(method, path string, v interface{}) error {
+	req, err := http.NewRequest(method, daemonBaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.http.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return fmt.Errorf("no daemon is serving %s, start one with 'ca-extension daemon': %v", client.socket, opErr.Err)
+		}
+		return fmt.Errorf("error requesting the daemon: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("daemon responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("error parsing the daemon response: %v", err)
+	}
+	return nil
+}
+
+func (client *daemonClient) check(spec string) (ResultEntry, error) {
+	var entry ResultEntry
+	err := client.do(http.MethodGet, "/api/v1/check?"+url.Values{"package": {spec}}.Encode(), &entry)
+	return entry, err
+}
+
+func (client *daemonClient) status() (DaemonStatus, error) {
+	var status DaemonStatus
+	err := client.do(http.MethodGet, "/api/v1/status", &status)
+	return status, err
+}
+
+func (client *daemonClient) stop() error {
+	return client.do(http.MethodPost, "/api/v1/shutdown", nil)
+}
+
+func clientCheckCmd(c *components.Context) error {
+	if len(c.Arguments) == 0 {
+		return wrongArguments("client check", "<name>@<version> [<name>@<version>...]", len(c.Arguments))
+	}
+	// Invalid packages fail before any request
+	if _, err := parsePackageSpecs(c.Arguments); err != nil {
+		return err
+	}
+	client := newDaemonClient(getSocketPath(c))
+	display := getDisplayFormat(c)
+	blocked := 0
+	for _, spec := range c.Arguments {
+		entry, err := client.check(spec)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s@%s %s", entry.Name, entry.Version, display.status(entry.Status))
+		if entry.Error != "" {
+			fmt.Printf(" - Error: %s", entry.Error)
+		}
+		fmt.Println()
+		if entry.StatusCode != http.StatusOK {
+			blocked++
+		}
+	}
+
+	if blocked > 0 {
+		return fmt.Errorf("%d of %d packages are not available from the curated registry", blocked, len(c.Arguments))
+	}
+	return nil
+}
+
+func clientStatusCmd(c *components.Context) error {
+	if len(c.Arguments) != 0 {
+		return wrongArguments("client status", "", len(c.Arguments))
+	}
+	socket := getSocketPath(c)
+	status, err := newDaemonClient(socket).status()
+	if err != nil {
+		return err
+	}
+	display := getDisplayFormat(c)
+	fmt.Printf("Daemon %d serving %s on %s since %s\n", status.PID, status.RegistryURL, socket, display.timestamp(status.StartedAt))
+	fmt.Printf("%s checks, %s from the cache\n", display.count(int(status.Checks)), display.count(int(status.Cached)))
+	return nil
+}
+
+func clientStopCmd(c *components.Context) error {
+	if len(c.Arguments) != 0 {
+		return wrongArguments("client stop", "", len(c.Arguments))
+	}
+	socket := getSocketPath(c)
+	if err := newDaemonClient(socket).stop(); err != nil {
+		return err
+	}
+	fmt.Println("Stopped the daemon serving", socket)
+	return nil
+}
@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+// cliSpecVersion is the version of the CLI specification format, bumped when fields change meaning or are removed
+const cliSpecVersion = "1"
+
+// CLISpec is the machine-readable description of the commands of the app, for packaging and documentation tooling
+type CLISpec struct {
+	SpecVersion string          `json:"specVersion"`
+	Name        string          `json:"name"`
+	Version     string          `json:"version"`
+	Description string          `json:"description"`
+	Namespaces  []NamespaceSpec `json:"namespaces,omitempty"`
+	Commands    []CommandSpec   `json:"commands"`
+}
+
+// NamespaceSpec describes a group of commands, such as tree
+type NamespaceSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CommandSpec describes a command. Commands of a namespace are named after it, e.g. tree diff.
+type CommandSpec struct {
+	Name        string         `json:"name"`
+	Namespace   string         `json:"namespace,omitempty"`
+	Description string         `json:"description"`
+	Aliases     []string       `json:"aliases,omitempty"`
+	Arguments   []ArgumentSpec `json:"arguments,omitempty"`
+	Flags       []FlagSpec     `json:"flags,omitempty"`
+	EnvVars     []EnvVarSpec   `json:"envVars,omitempty"`
+	Examples    []ExampleSpec  `json:"examples,omitempty"`
+}
+
+type ArgumentSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Optional    bool   `json:"optional,omitempty"`
+}
+
+// FlagSpec describes a flag. Type is string or bool, and Default is empty for flags without a default value.
+type FlagSpec struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Default     string `json:"default,omitempty"`
+	Mandatory   bool   `json:"mandatory,omitempty"`
+	HelpValue   string `json:"helpValue,omitempty"`
+}
+
+type EnvVarSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     string `json:"default,omitempty"`
+}
+
+type ExampleSpec struct {
+	Description string `json:"description"`
+	Command     string `json:"command"`
+}
+
+// NewCLISpec describes the commands of an app, such as GetApp(), leaving out the hidden commands and flags
+func NewCLISpec(app components.App) *CLISpec {
+	spec := &CLISpec{
+		SpecVersion: cliSpecVersion,
+		Name:        app.Name,
+		Version:     app.Version,
+		Description: app.Description,
+	}
+	spec.Commands = commandSpecs("", app.Commands)
+	for _, namespace := range app.Subcommands {
+		spec.Namespaces = append(spec.Namespaces, NamespaceSpec{Name: namespace.Name, Description: namespace.Description})
+		spec.Commands = append(spec.Commands, commandSpecs(namespace.Name, namespace.Commands)...)
+	}
+	return spec
+}
+
+func commandSpecs(namespace string, cmds []components.Command) []CommandSpec {
+	var specs []CommandSpec
+	for _, cmd := range cmds {
+		if cmd.Hidden {
+			continue
+		}
+		name := strings.TrimSpace(namespace + " " + cmd.Name)
+		spec := CommandSpec{Name: name, Namespace: namespace, Description: cmd.Description, Aliases: cmd.Aliases}
+		for _, argument := range cmd.Arguments {
+			spec.Arguments = append(spec.Arguments, ArgumentSpec{Name: argument.Name, Description: argument.Description, Optional: argument.Optional})
+		}
+		for _, flag := range cmd.Flags {
+			if flagSpec, visible := newFlagSpec(flag); visible {
+				spec.Flags = append(spec.Flags, flagSpec)
+			}
+		}
+		sort.Slice(spec.Flags, func(i, j int) bool {
+			return spec.Flags[i].Name < spec.Flags[j].Name
+		})
+		for _, envVar := range cmd.EnvVars {
+			spec.EnvVars = append(spec.EnvVars, EnvVarSpec{Name: envVar.Name, Description: envVar.Description, Default: envVar.Default})
+		}
+		for _, example := range commandExamples[name] {
+			spec.Examples = append(spec.Examples, ExampleSpec{Description: example.description, Command: exampleCommandLine(example)})
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// newFlagSpec describes a flag, and reports whether it is visible in the help
+func newFlagSpec(flag components.Flag) (FlagSpec, bool) {
+	spec := FlagSpec{Name: flag.GetName(), Description: flag.GetDescription(), Mandatory: flag.IsMandatory()}
+	switch typed := flag.(type) {
+	case components.StringFlag:
+		spec.Type, spec.Default, spec.HelpValue = "string", typed.DefaultValue, typed.HelpValue
+		return spec, !typed.Hidden
+	case components.BoolFlag:
+		spec.Type, spec.Default = "bool", fmt.Sprint(typed.DefaultValue)
+		return spec, !typed.Hidden
+	default:
+		spec.Type = "string"
+		return spec, true
+	}
+}
+
+// ManPages renders the spec as man pages in section 1: one for the app, listing the commands, and one per command,
+// keyed by their file names, e.g. ca-extension-tree-diff.1
+func (spec *CLISpec) ManPages() map[string]string {
+	pages := map[string]string{spec.Name + ".1": spec.appManPage()}
+	for _, cmd := range spec.Commands {
+		pages[spec.commandPageName(cmd)+".1"] = spec.commandManPage(cmd)
+	}
+	return pages
+}
+
+func (spec *CLISpec) commandPageName(cmd CommandSpec) string {
+	return spec.Name + "-" + strings.ReplaceAll(cmd.Name, " ", "-")
+}
+
+// manHeader starts a page. The date is left out so the pages of a version are reproducible.
+func (spec *CLISpec) manHeader(out *strings.Builder, title, summary string) {
+	fmt.Fprintf(out, ".TH %s 1 \"\" \"%s %s\" \"%s manual\"\n", strings.ToUpper(title), spec.Name, spec.Version, spec.Name)
+	fmt.Fprintf(out, ".SH NAME\n%s \\- %s\n", roffEscape(title), roffEscape(strings.TrimSuffix(summary, ".")))
+}
+
+func (spec *CLISpec) appManPage() string {
+	var out strings.Builder
+	spec.manHeader(&out, spec.Name, spec.Description)
+	fmt.Fprintf(&out, ".SH SYNOPSIS\n.B %s\n\\fIcommand\\fR [\\fIoptions\\fR] [\\fIarguments\\fR]\n", roffEscape(spec.Name))
+	fmt.Fprintf(&out, ".SH DESCRIPTION\n%s\n", roffText(spec.Description))
+	out.WriteString(".SH COMMANDS\n")
+	for _, cmd := range spec.Commands {
+		fmt.Fprintf(&out, ".TP\n.B %s\n%s\n", roffEscape(cmd.Name), roffText(cmd.Description))
+	}
+	out.WriteString(".SH SEE ALSO\n")
+	references := make([]string, 0, len(spec.Commands))
+	for _, cmd := range spec.Commands {
+		references = append(references, fmt.Sprintf(".BR %s (1)", roffEscape(spec.commandPageName(cmd))))
+	}
+	out.WriteString(strings.Join(references, ",\n") + "\n")
+	return out.String()
+}
+
+func (spec *CLISpec) commandManPage(cmd CommandSpec) string {
+	var out strings.Builder
+	spec.manHeader(&out, spec.commandPageName(cmd), cmd.Description)
+
+	fmt.Fprintf(&out, ".SH SYNOPSIS\n.B %s %s\n[\\fIoptions\\fR]", roffEscape(spec.Name), roffEscape(cmd.Name))
+	for _, argument := range cmd.Arguments {
+		if argument.Optional {
+			fmt.Fprintf(&out, " [\\fI%s\\fR]", roffEscape(argument.Name))
+		} else {
+			fmt.Fprintf(&out, " \\fI%s\\fR", roffEscape(argument.Name))
+		}
+	}
+	out.WriteString("\n")
+
+	fmt.Fprintf(&out, ".SH DESCRIPTION\n%s\n", roffText(cmd.Description))
+	if len(cmd.Aliases) > 0 {
+		fmt.Fprintf(&out, ".PP\nAliases: %s\n", roffEscape(strings.Join(cmd.Aliases, ", ")))
+	}
+	if len(cmd.Arguments) > 0 {
+		out.WriteString(".SH ARGUMENTS\n")
+		for _, argument := range cmd.Arguments {
+			fmt.Fprintf(&out, ".TP\n.I %s\n%s\n", roffEscape(argument.Name), roffText(argument.Description))
+		}
+	}
+	if len(cmd.Flags) > 0 {
+		out.WriteString(".SH OPTIONS\n")
+		for _, flag := range cmd.Flags {
+			fmt.Fprintf(&out, ".TP\n\\fB\\-\\-%s\\fR", roffEscape(flag.Name))
+			if flag.Type != "bool" {
+				helpValue := flag.HelpValue
+				if helpValue == "" {
+					helpValue = "value"
+				}
+				fmt.Fprintf(&out, "=\\fI%s\\fR", roffEscape(helpValue))
+			}
+			description := flag.Description
+			if flag.Default != "" {
+				description += fmt.Sprintf(" [Default: %s]", flag.Default)
+			}
+			if flag.Mandatory {
+				description += " [Mandatory]"
+			}
+			fmt.Fprintf(&out, "\n%s\n", roffText(description))
+		}
+	}
+	if len(cmd.EnvVars) > 0 {
+		out.WriteString(".SH ENVIRONMENT\n")
+		for _, envVar := range cmd.EnvVars {
+			fmt.Fprintf(&out, ".TP\n.B %s\n%s\n", roffEscape(envVar.Name), roffText(envVar.Description))
+		}
+	}
+	if len(cmd.Examples) > 0 {
+		out.WriteString(".SH EXAMPLES\n")
+		for _, example := range cmd.Examples {
+			fmt.Fprintf(&out, ".PP\n%s\n.PP\n.RS\n.nf\n$ %s\n.fi\n.RE\n", roffText(example.Description), roffEscape(example.Command))
+		}
+	}
+	fmt.Fprintf(&out, ".SH SEE ALSO\n.BR %s (1)\n", roffEscape(spec.Name))
+	return out.String()
+}
+
+// roffEscape escapes the backslashes and dashes of text, so roff prints them as they are
+func roffEscape(text string) string {
+	return strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(text)
+}
+
+// roffText escapes a paragraph, and the periods and apostrophes starting its lines, which roff reads as requests
+func roffText(text string) string {
+	lines := strings.Split(roffEscape(text), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
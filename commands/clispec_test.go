@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCLISpec(t *testing.T) {
+	spec := NewCLISpec(GetApp())
+	assert.Equal(t, appName, spec.Name)
+	assert.Equal(t, appVersion, spec.Version)
+
+	commands := make(map[string]CommandSpec)
+	for _, cmd := range spec.Commands {
+		commands[cmd.Name] = cmd
+	}
+	for name := range commandExamples {
+		assert.Contains(t, commands, name)
+	}
+	assert.Equal(t, "tree", commands["tree diff"].Namespace)
+
+	auditSpec := commands["audit"]
+	assert.Equal(t, []string{"a"}, auditSpec.Aliases)
+	assert.NotEmpty(t, auditSpec.EnvVars)
+	assert.Equal(t, exampleCommandLine(commandExamples["audit"][0]), auditSpec.Examples[0].Command)
+	flags := make(map[string]FlagSpec)
+	for _, flag := range auditSpec.Flags {
+		flags[flag.Name] = flag
+	}
+	assert.Equal(t, FlagSpec{Name: directOnlyFlag, Type: "bool", Description: flags[directOnlyFlag].Description, Default: "false"}, flags[directOnlyFlag])
+	assert.Equal(t, "path", flags[outputFlag].HelpValue)
+}
+
+func TestCLISpecSkipsHidden(t *testing.T) {
+	app := components.CreateApp("tool", "v1.0.0", "A tool.", []components.Command{
+		{Name: "run", Flags: []components.Flag{
+			components.NewStringFlag("debug-dir", "Hidden", components.SetHiddenStrFlag()),
+			components.NewStringFlag("out", "Output", components.WithStrDefaultValue("-")),
+		}},
+		{Name: "internal", Hidden: true},
+	})
+	spec := NewCLISpec(app)
+	assert.Len(t, spec.Commands, 1)
+	assert.Equal(t, []FlagSpec{{Name: "out", Type: "string", Description: "Output", Default: "-"}}, spec.Commands[0].Flags)
+}
+
+func TestManPages(t *testing.T) {
+	pages := NewCLISpec(GetApp()).ManPages()
+	assert.Contains(t, pages, "ca-extension.1")
+	assert.Contains(t, pages["ca-extension.1"], ".BR ca\\-extension\\-tree\\-diff (1)")
+
+	auditPage := pages["ca-extension-audit.1"]
+	assert.True(t, strings.HasPrefix(auditPage, ".TH CA-EXTENSION-AUDIT 1 \"\" \"ca-extension v1.0.0\""))
+	assert.Contains(t, auditPage, "\\fB\\-\\-output\\fR=\\fIpath\\fR\n")
+	assert.Contains(t, auditPage, ".SH ENVIRONMENT\n.TP\n.B CA_EXTENSION_REGISTRY_URL\n")
+	assert.Contains(t, auditPage, "$ ca\\-extension audit pnpm\\-lock.yaml")
+}
+
+func TestRoffText(t *testing.T) {
+	assert.Equal(t, "C:\\eUsers \\-\\-flag\n\\&.hidden\n\\&'quoted'", roffText("C:\\Users --flag\n.hidden\n'quoted'"))
+}
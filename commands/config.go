@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"strconv"
+)
+
+const showTokenFlag = "show-token"
+
+func GetConfigCommand() components.Command {
+	return components.Command{
+		Name:        "config",
+		Description: "Prints the effective configuration, after applying flags, environment variables and the project config file.",
+		Flags:       getConfigFlags(),
+		EnvVars:     getRegistryEnvVars(),
+		Action: func(c *components.Context) error {
+			return configCmd(c)
+		},
+	}
+}
+
+func getConfigFlags() []components.Flag {
+	return append(getRegistryFlags(),
+		getWorkersFlag(),
+		components.NewBoolFlag(
+			showTokenFlag,
+			"Print the access token and custom header values instead of masked values",
+			components.WithBoolDefaultValue(false),
+		),
+	)
+}
+
+func configCmd(c *components.Context) error {
+	if len(c.Arguments) != 0 {
+		return wrongArguments("config", "", len(c.Arguments))
+	}
+	workers, err := getWorkers(c)
+	if err != nil {
+		return err
+	}
+	registry, err := resolveRegistryConfiguration(c)
+	if err != nil {
+		return err
+	}
+	fmt.Print(formatConfiguration(registry, workers, c.GetBoolFlagValue(showTokenFlag)))
+	fmt.Print(describeProjectConfig())
+	return nil
+}
+
+func formatConfiguration(registry *registryConfiguration, workers int, showToken bool) string {
+	registryURL := registry.registryURL
+	if registryURL == "" {
+		registryURL = "<not set>"
+	}
+	token := registry.accessToken
+	if !showToken {
+		token = maskSecret(token)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", registryURLFlag, registryURL))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", accessTokenFlag, token))
+	if registry.serverID != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", serverIDFlag, registry.serverID))
+		if registry.user != "" {
+			sb.WriteString(fmt.Sprintf("user: %s\n", registry.user))
+		}
+	}
+	if registry.project != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", projectFlag, registry.project))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %d\n", workersFlag, workers))
+	if registry.proxyURL != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", proxyFlag, registry.proxyURL.Redacted()))
+	}
+	if registry.caCertFile != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", caCertFlag, registry.caCertFile))
+	}
+	if registry.insecure {
+		sb.WriteString(fmt.Sprintf("%s: true\n", insecureFlag))
+	}
+	for _, host := range sortedKeys(registry.resolve) {
+		sb.WriteString(fmt.Sprintf("%s: %s:%s\n", resolveFlag, host, registry.resolve[host]))
+	}
+	if registry.ipFamily != "" && registry.ipFamily != ipFamilyAuto {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", ipFamilyFlag, registry.ipFamily))
+	}
+	if registry.tlsProfile != "" && registry.tlsProfile != tlsProfileDefault {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", tlsProfileFlag, registry.tlsProfile))
+	}
+	if len(registry.readReplicas) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", readReplicasFlag, strings.Join(registry.readReplicas, ", ")))
+	}
+	if registry.requestTimeout > 0 && registry.requestTimeout != defaultRequestTimeout {
+		sb.WriteString(fmt.Sprintf("%s: %v\n", requestTimeoutFlag, registry.requestTimeout))
+	}
+	if registry.rateLimiter != nil {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", rateLimitFlag, strconv.FormatFloat(registry.rateLimiter.rate, 'f', -1, 64)))
+	}
+	if registry.retry.Attempts > 0 && registry.retry != defaultRetryPolicy {
+		sb.WriteString(fmt.Sprintf("%s: %d, %s: %v, %s: %v\n", retryAttemptsFlag, registry.retry.Attempts,
+			retryBaseDelayFlag, registry.retry.BaseDelay, retryMaxDelayFlag, registry.retry.MaxDelay))
+	}
+	for _, rule := range registry.headers {
+		names := make([]string, 0, len(rule.header))
+		for name := range rule.header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value := rule.header.Get(name)
+			if !showToken {
+				value = maskSecret(value)
+			}
+			sb.WriteString(fmt.Sprintf("header: %s %s: %s\n", rule.host, name, value))
+		}
+	}
+	return sb.String()
+}
+
+// maskSecret keeps only the last 4 characters of a secret, enough to tell tokens apart
+func maskSecret(secret string) string {
+	if secret == "" {
+		return "<not set>"
+	}
+	if len(secret) <= 8 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
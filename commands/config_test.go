@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatConfiguration(t *testing.T) {
+	t.Setenv(registryURLEnv, "https://acme.jfrog.io/artifactory/api/npm/npm-remote")
+	t.Setenv(accessTokenEnv, "eyJ2ZXIiOiIyIiwidHlwIjoiSldUIn0")
+
+	registry, err := resolveRegistryConfiguration(&components.Context{})
+	assert.NoError(t, err)
+	assert.Equal(t, "registry-url: https://acme.jfrog.io/artifactory/api/npm/npm-remote\naccess-token: ****UIn0\nworkers: 5\n",
+		formatConfiguration(registry, 5, false))
+}
+
+func TestMaskSecret(t *testing.T) {
+	assert.Equal(t, "<not set>", maskSecret(""))
+	assert.Equal(t, "****", maskSecret("short"))
+	assert.Equal(t, "****cdef", maskSecret("0123456789abcdef"))
+}
+
+func TestFormatConfigurationHeaders(t *testing.T) {
+	registry := &registryConfiguration{headers: []hostHeaders{
+		{host: "acme.jfrog.io", header: http.Header{"X-Tenant-Id": {"web"}, "X-Jfrog-Art-Api": {"AKCp8secretkey"}}},
+	}}
+	assert.Equal(t, "registry-url: <not set>\naccess-token: <not set>\nworkers: 5\n"+
+		"header: acme.jfrog.io X-Jfrog-Art-Api: ****tkey\nheader: acme.jfrog.io X-Tenant-Id: ****\n",
+		formatConfiguration(registry, 5, false))
+	assert.Contains(t, formatConfiguration(registry, 5, true), "header: acme.jfrog.io X-Tenant-Id: web\n")
+}
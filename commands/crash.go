@@ -0,0 +1,265 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	// crashBundleEnv selects when diagnostic bundles are written: on panics (the default), also on errors, or off
+	crashBundleEnv = "CA_EXTENSION_CRASH_BUNDLE"
+
+	crashBundleError = "error"
+	crashBundleOff   = "off"
+
+	// Number of log lines kept for the bundle
+	crashLogLines = 200
+)
+
+// Fragments of the flag and environment variable names whose values are masked in bundles
+var secretNameFragments = []string{"token", "password", "secret", "api-key", "api_key"}
+
+// CrashSummary represents the diagnostics of a failed run, with secrets masked
+type CrashSummary struct {
+	ToolVersion string            `json:"toolVersion"`
+	GoVersion   string            `json:"goVersion"`
+	OS          string            `json:"os"`
+	Arch        string            `json:"arch"`
+	Time        string            `json:"time"`
+	Command     string            `json:"command"`
+	Arguments   []string          `json:"arguments"`
+	Failure     string            `json:"failure"`
+	Flags       map[string]string `json:"flags"`
+	Environment map[string]string `json:"environment"`
+	Tree        *TreeStats        `json:"tree,omitempty"`
+}
+
+// TreeStats describes the dependency tree parsed by the failed run
+type TreeStats struct {
+	LockFile      string         `json:"lockFile"`
+	LockFileBytes int64          `json:"lockFileBytes"`
+	Importers     int            `json:"importers"`
+	Packages      int            `json:"packages"`
+	PackageTypes  map[string]int `json:"packageTypes"`
+}
+
+// crashDiagnostics collects what a run does for the bundle written when it fails
+type crashDiagnostics struct {
+	mu   sync.Mutex
+	tree *TreeStats
+	logs []string
+}
+
+var diagnostics = &crashDiagnostics{}
+
+// recordTreeStats records the shape of the parsed tree, with the base name of the lock file only
+func recordTreeStats(lockFile string, tree *audit.DependencyTree) {
+	stats := &TreeStats{
+		LockFile:     filepath.Base(lockFile),
+		Importers:    len(tree.Importers),
+		Packages:     len(tree.Packages),
+		PackageTypes: make(map[string]int),
+	}
+	if info, err := os.Stat(lockFile); err == nil {
+		stats.LockFileBytes = info.Size()
+	}
+	for _, info := range tree.Packages {
+		stats.PackageTypes[info.Type]++
+	}
+	diagnostics.mu.Lock()
+	defer diagnostics.mu.Unlock()
+	diagnostics.tree = stats
+}
+
+func (d *crashDiagnostics) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.tree = nil
+	d.logs = nil
+}
+
+func (d *crashDiagnostics) appendLog(line string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.logs = append(d.logs, line)
+	if len(d.logs) > crashLogLines {
+		d.logs = d.logs[len(d.logs)-crashLogLines:]
+	}
+}
+
+// tailLogger keeps the last log lines for the bundle, and logs them with the wrapped logger
+type tailLogger struct {
+	log.Log
+}
+
+func (l *tailLogger) record(level log.LevelType, prefix string, a []interface{}) {
+	if l.GetLogLevel() >= level {
+		diagnostics.appendLog(time.Now().UTC().Format(time.RFC3339) + " [" + prefix + "] " + strings.TrimSpace(fmt.Sprintln(a...)))
+	}
+}
+
+func (l *tailLogger) Debug(a ...interface{}) {
+	l.record(log.DEBUG, "Debug", a)
+	l.Log.Debug(a...)
+}
+
+func (l *tailLogger) Info(a ...interface{}) {
+	l.record(log.INFO, "Info", a)
+	l.Log.Info(a...)
+}
+
+func (l *tailLogger) Warn(a ...interface{}) {
+	l.record(log.WARN, "Warn", a)
+	l.Log.Warn(a...)
+}
+
+func (l *tailLogger) Error(a ...interface{}) {
+	l.record(log.ERROR, "Error", a)
+	l.Log.Error(a...)
+}
+
+// withCrashBundles makes the commands write a diagnostic bundle when they panic, or fail with
+// CA_EXTENSION_CRASH_BUNDLE=error, and print its path
+func withCrashBundles(commands []components.Command) []components.Command {
+	wrapped := make([]components.Command, len(commands))
+	for i, command := range commands {
+		wrapped[i] = withCrashBundle(command)
+	}
+	return wrapped
+}
+
+func withCrashBundle(command components.Command) components.Command {
+	action := command.Action
+	flags := command.Flags
+	command.Action = func(c *components.Context) (err error) {
+		mode := strings.ToLower(os.Getenv(crashBundleEnv))
+		// A --read-only run writes no bundle to the temp directory either
+		if mode == crashBundleOff || c.GetBoolFlagValue(readOnlyFlag) {
+			return action(c)
+		}
+		diagnostics.reset()
+		logger := log.GetLogger()
+		log.SetLogger(&tailLogger{Log: logger})
+		defer log.SetLogger(logger)
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				failure := fmt.Sprintf("panic: %v", recovered)
+				err = crashError(newCrashSummary(c, command.Name, flags, failure), debug.Stack(), fmt.Errorf("%s crashed: %v", command.Name, recovered))
+			} else if err != nil && mode == crashBundleError {
+				err = crashError(newCrashSummary(c, command.Name, flags, err.Error()), nil, err)
+			}
+		}()
+		return action(c)
+	}
+	return command
+}
+
+// crashError writes the bundle and returns the error of the run, pointing at the bundle
+func crashError(summary *CrashSummary, stack []byte, err error) error {
+	dir, bundleErr := writeCrashBundle(summary, stack)
+	if bundleErr != nil {
+		log.Warn("Could not write the diagnostic bundle:", bundleErr.Error())
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "A diagnostic bundle was written to %s. Secrets are masked; review its log lines, then attach it to the bug report.\n", dir)
+	return fmt.Errorf("%v (diagnostic bundle: %s)", err, dir)
+}
+
+func newCrashSummary(c *components.Context, command string, flags []components.Flag, failure string) *CrashSummary {
+	summary := &CrashSummary{
+		ToolVersion: appVersion,
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		Time:        time.Now().UTC().Format(time.RFC3339),
+		Command:     command,
+		Arguments:   []string{},
+		Failure:     failure,
+		Flags:       make(map[string]string),
+		Environment: make(map[string]string),
+	}
+	// Arguments are paths and package specs, of which only the base names are kept
+	for _, argument := range c.Arguments {
+		summary.Arguments = append(summary.Arguments, filepath.Base(argument))
+	}
+	for _, flag := range flags {
+		name := flag.GetName()
+		switch flag.(type) {
+		case components.BoolFlag, *components.BoolFlag:
+			if c.GetBoolFlagValue(name) {
+				summary.Flags[name] = "true"
+			}
+		default:
+			if value := c.GetStringFlagValue(name); value != "" {
+				summary.Flags[name] = sanitizeSetting(name, value)
+			}
+		}
+	}
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		if strings.HasPrefix(name, "CA_EXTENSION_") {
+			summary.Environment[name] = sanitizeSetting(name, value)
+		}
+	}
+
+	diagnostics.mu.Lock()
+	defer diagnostics.mu.Unlock()
+	summary.Tree = diagnostics.tree
+	return summary
+}
+
+// sanitizeSetting masks secrets, and the credentials of URLs
+func sanitizeSetting(name, value string) string {
+	lower := strings.ToLower(name)
+	for _, fragment := range secretNameFragments {
+		if strings.Contains(lower, fragment) {
+			return maskSecret(value)
+		}
+	}
+	if parsed, err := url.Parse(value); err == nil && parsed.User != nil {
+		return parsed.Redacted()
+	}
+	return value
+}
+
+// writeCrashBundle writes summary.json, the last log lines and the stack trace, if any, to a new temp directory
+func writeCrashBundle(summary *CrashSummary, stack []byte) (string, error) {
+	dir, err := os.MkdirTemp("", appName+"-crash-")
+	if err != nil {
+		return "", err
+	}
+	jsonData, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	diagnostics.mu.Lock()
+	logs := strings.Join(diagnostics.logs, "\n")
+	diagnostics.mu.Unlock()
+
+	files := map[string][]byte{
+		"summary.json": append(jsonData, '\n'),
+		"log.txt":      []byte(logs + "\n"),
+	}
+	if len(stack) > 0 {
+		files["stack.txt"] = stack
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
@@ -0,0 +1,247 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	socketFlag = "socket"
+	socketEnv  = "CA_EXTENSION_SOCKET"
+)
+
+// errDaemonRunning is returned by lockDaemon when another daemon holds the lock
+var errDaemonRunning = errors.New("a daemon is already running")
+
+// DaemonStatus is served by GET /api/v1/status of the daemon
+type DaemonStatus struct {
+	PID         int       `json:"pid"`
+	RegistryURL string    `json:"registryUrl"`
+	StartedAt   time.Time `json:"startedAt"`
+	Checks      int64     `json:"checks"`
+	Cached      int64     `json:"cached"`
+}
+
+// daemon serves the checks of the client commands with a single registry client and outcome cache
+type daemon struct {
+	registry  *registryConfiguration
+	checker   *audit.Registry
+	cache     *outcomeCache
+	startedAt time.Time
+	checks    atomic.Int64
+	cached    atomic.Int64
+	// stop shuts the daemon down, once the shutdown request is answered
+	stop func()
+}
+
+func GetDaemonCommand() components.Command {
+	return components.Command{
+		Name:        "daemon",
+		Description: "Runs the daemon serving curation checks to the 'client' commands over a unix socket, one per socket.",
+		Flags:       getDaemonFlags(),
+		EnvVars:     append(getRegistryEnvVars(), getSocketEnvVar()),
+		Action: func(c *components.Context) error {
+			return daemonCmd(c)
+		},
+	}
+}
+
+func getDaemonFlags() []components.Flag {
+	return append(getRegistryFlags(),
+		getSocketFlag(),
+		components.NewBoolFlag(
+			cacheFlag,
+			"Answer checks from the curation outcome cache, e.g. those recorded by the 'proxy' command, and cache new outcomes",
+			components.WithBoolDefaultValue(false),
+		),
+		getCacheFileFlag(),
+		getCacheTTLFlag(),
+	)
+}
+
+func getSocketFlag() components.Flag {
+	return components.NewStringFlag(
+		socketFlag,
+		"Path of the unix socket of the daemon. Defaults to ca-extension.sock in $XDG_RUNTIME_DIR, or ca-extension-<uid>.sock in the temporary directory",
+		components.WithHelpValue("path"),
+	)
+}
+
+func getSocketEnvVar() components.EnvVar {
+	return components.EnvVar{
+		Name:        socketEnv,
+		Description: "Path of the unix socket of the daemon, used when --" + socketFlag + " is not set.",
+	}
+}
+
+func getSocketPath(c *components.Context) string {
+	if path := flagOrEnv(c, socketFlag, socketEnv); path != "" {
+		return path
+	}
+	return defaultSocketPath()
+}
+
+// defaultSocketPath is private to the user: their runtime directory, or a per-user name in the temporary directory
+func defaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, appName+".sock")
+	}
+	return filepath.Join(os.TempDir(), appName+"-"+strconv.Itoa(os.Getuid())+".sock")
+}
+
+func daemonCmd(c *components.Context) error {
+	if len(c.Arguments) != 0 {
+		return wrongArguments("daemon", "", len(c.Arguments))
+	}
+	registry, err := getRegistryConfiguration(c)
+	if err != nil {
+		return err
+	}
+	var cache *outcomeCache
+	if c.GetBoolFlagValue(cacheFlag) {
+		if cache, err = openOutcomeCache(c); err != nil {
+			return err
+		}
+	}
+	socket := getSocketPath(c)
+	listener, release, err := listenDaemon(socket)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	d := newDaemon(registry, cache)
+	d.stop = cancel
+	server := &http.Server{Handler: d.handler()}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancelShutdown()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Warn("Could not shut the daemon down gracefully:", err.Error())
+		}
+	}()
+
+	log.Info(fmt.Sprintf("Serving curation checks for %s on %s", registry.registryURL, socket))
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error serving %s: %v", socket, err)
+	}
+	log.Info("Daemon stopped")
+	return nil
+}
+
+// listenDaemon takes the lock of the socket, so concurrent daemons of the same socket fail instead of replacing
+// each other's, and listens on it. The release closes the listener, which removes the socket, and the lock.
+func listenDaemon(socket string) (net.Listener, func(), error) {
+	if err := os.MkdirAll(filepath.Dir(socket), 0700); err != nil {
+		return nil, nil, fmt.Errorf("error creating the socket directory: %v", err)
+	}
+	unlock, err := lockDaemon(socket + ".lock")
+	if errors.Is(err, errDaemonRunning) {
+		return nil, nil, fmt.Errorf("a daemon is already serving %s, stop it with 'ca-extension client stop'", socket)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	// Holding the lock, an existing socket is that of a daemon that didn't exit cleanly
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		unlock()
+		return nil, nil, fmt.Errorf("error removing the stale socket %s: %v", socket, err)
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		unlock()
+		return nil, nil, fmt.Errorf("error listening on %s: %v", socket, err)
+	}
+	// The daemon checks with the user's token, so only the user may connect
+	if err := os.Chmod(socket, 0600); err != nil {
+		listener.Close()
+		unlock()
+		return nil, nil, fmt.Errorf("error restricting the permissions of %s: %v", socket, err)
+	}
+	return listener, func() {
+		listener.Close()
+		unlock()
+	}, nil
+}
+
+func newDaemon(registry *registryConfiguration, cache *outcomeCache) *daemon {
+	return &daemon{
+		registry:  registry,
+		checker:   registry.auditRegistry(),
+		cache:     cache,
+		startedAt: time.Now().UTC(),
+		stop:      func() {},
+	}
+}
+
+// handler adds GET /api/v1/status and POST /api/v1/shutdown to the endpoints of the serve command
+func (d *daemon) handler() http.Handler {
+	mux := newCheckHandler(d.check)
+	mux.HandleFunc("/api/v1/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(d.status()); err != nil {
+			log.Warn("Could not write response:", err.Error())
+		}
+	})
+	mux.HandleFunc("/api/v1/shutdown", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		log.Info("Shutdown requested")
+		d.stop()
+	})
+	return mux
+}
+
+// check answers from the cache when it has the outcome, and caches the outcomes of the registry otherwise
+func (d *daemon) check(dep audit.Dependency) audit.AuditResult {
+	d.checks.Add(1)
+	if d.cache == nil {
+		return d.checker.Check(dep)
+	}
+	if outcome, exists := d.cache.lookup(d.registry, dep); exists {
+		d.cached.Add(1)
+		return outcome.result(dep)
+	}
+	result := d.checker.Check(dep)
+	if d.cache.record(d.registry, dep, result) {
+		if err := d.cache.save(); err != nil {
+			log.Warn(err.Error())
+		}
+	}
+	return result
+}
+
+func (d *daemon) status() DaemonStatus {
+	return DaemonStatus{
+		PID:         os.Getpid(),
+		RegistryURL: d.registry.registryURL,
+		StartedAt:   d.startedAt,
+		Checks:      d.checks.Load(),
+		Cached:      d.cached.Load(),
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package commands
+
+import "errors"
+
+// lockDaemon is only implemented on unix systems, where the daemon and its socket are supported
+func lockDaemon(path string) (func(), error) {
+	return nil, errors.New("the daemon is only supported on unix systems")
+}
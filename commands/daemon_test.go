@@ -0,0 +1,86 @@
+//go:build unix
+
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenDaemonIsSingleton(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "ca.sock")
+	// A socket left by a daemon that didn't exit cleanly is replaced
+	assert.NoError(t, os.WriteFile(socket, nil, 0600))
+
+	listener, release, err := listenDaemon(socket)
+	require.NoError(t, err)
+	info, err := os.Stat(socket)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, _, err = listenDaemon(socket)
+	assert.EqualError(t, err, "a daemon is already serving "+socket+", stop it with 'ca-extension client stop'")
+
+	release()
+	listener, release, err = listenDaemon(socket)
+	require.NoError(t, err)
+	assert.NotNil(t, listener)
+	release()
+}
+
+func TestDaemonClient(t *testing.T) {
+	var requests int
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/@types/keyv/-/keyv-3.1.4.tgz" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer registry.Close()
+
+	socket := filepath.Join(t.TempDir(), "ca.sock")
+	listener, release, err := listenDaemon(socket)
+	require.NoError(t, err)
+	defer release()
+	cache := &outcomeCache{path: filepath.Join(t.TempDir(), "outcomes.json"), Outcomes: map[string]cachedOutcome{}}
+	d := newDaemon(&registryConfiguration{registryURL: registry.URL}, cache)
+	stopped := make(chan struct{})
+	d.stop = func() { close(stopped) }
+	server := &http.Server{Handler: d.handler()}
+	go server.Serve(listener)
+	defer server.Close()
+
+	client := newDaemonClient(socket)
+	entry, err := client.check("@types/keyv@3.1.4")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, entry.StatusCode)
+	entry, err = client.check("@types/keyv@3.1.4")
+	assert.NoError(t, err)
+	assert.Equal(t, "❌ Blocked (403 Forbidden) (cached)", entry.Status)
+	assert.Equal(t, 1, requests)
+
+	_, err = client.check("lodash")
+	assert.ErrorContains(t, err, "daemon responded with status 400")
+
+	status, err := client.status()
+	assert.NoError(t, err)
+	assert.Equal(t, os.Getpid(), status.PID)
+	assert.Equal(t, registry.URL, status.RegistryURL)
+	assert.Equal(t, int64(2), status.Checks)
+	assert.Equal(t, int64(1), status.Cached)
+
+	assert.NoError(t, client.stop())
+	<-stopped
+}
+
+func TestDaemonClientWithoutDaemon(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "ca.sock")
+	_, err := newDaemonClient(socket).status()
+	assert.ErrorContains(t, err, "no daemon is serving "+socket+", start one with 'ca-extension daemon'")
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockDaemon takes an exclusive lock of the file, held by the process until the returned unlock. The kernel
+// releases it when the process exits, so a daemon that crashed doesn't keep others from starting.
+func lockDaemon(path string) (func(), error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening the daemon lock %s: %v", path, err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errDaemonRunning
+		}
+		return nil, fmt.Errorf("error locking %s: %v", path, err)
+	}
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	verdictPass  = "pass"
+	verdictBlock = "block"
+)
+
+// PrecheckReport represents the machine-readable verdict for a bot PR
+type PrecheckReport struct {
+	Metadata *RunMetadata  `json:"metadata,omitempty"`
+	Verdict  string        `json:"verdict"`
+	LockFile string        `json:"lockFile"`
+	Bumped   int           `json:"bumped"`
+	Blocked  int           `json:"blocked"`
+	Packages []ResultEntry `json:"packages"`
+}
+
+func GetDiffCommand() components.Command {
+	return components.Command{
+		Name:        "diff",
+		Description: "Audits only the packages bumped between two lock files and prints a pass/block verdict. Designed for Renovate/Dependabot PRs.",
+		Aliases:     []string{"d"},
+		Arguments:   getDiffArguments(),
+		Flags:       append(append(getRegistryFlags(), getWorkersFlag(), getNotifyConfigFlag(), getReasonsFileFlag()), append(getProfilingFlags(), getVerboseFlag())...),
+		EnvVars:     getRegistryEnvVars(),
+		Action: func(c *components.Context) error {
+			return diffCmd(c)
+		},
+	}
+}
+
+func getDiffArguments() []components.Argument {
+	return []components.Argument{
+		{
+			Name:        "base",
+			Description: "The base lock file, or git:<ref> to read the lock file from a git revision, e.g. git:origin/main.",
+		},
+		{
+			Name:        "lock-file",
+			Description: "The path to the updated pnpm-lock.yaml, yarn.lock or package-lock.json file.",
+		},
+	}
+}
+
+// diffCmd writes the JSON report to stdout so Renovate's postUpgradeTasks can consume it, and fails when
+// any bumped package is blocked.
+func diffCmd(c *components.Context) error {
+	if len(c.Arguments) != 2 {
+		return wrongArguments("diff", "<base> <lock-file>", len(c.Arguments))
+	}
+	registry, err := getRegistryConfiguration(c)
+	if err != nil {
+		return err
+	}
+	workers, err := getWorkers(c)
+	if err != nil {
+		return err
+	}
+	registry.workers = workers
+	profile, err := startProfiling(c)
+	if err != nil {
+		return err
+	}
+	defer profile.stop()
+	timings := registry.timings
+	defer timings.print(getDisplayFormat(c))
+
+	notifications, err := getNotificationDispatcher(c)
+	if err != nil {
+		return err
+	}
+	reasons, err := getReasonMapping(c)
+	if err != nil {
+		return err
+	}
+
+	baseRef, lockFilePath := c.Arguments[0], c.Arguments[1]
+	timings.phase(phaseParse)
+	base, err := loadBaseLock(baseRef, lockFilePath)
+	if err != nil {
+		return fmt.Errorf("error loading base lock file: %v", err)
+	}
+	head, err := audit.ParseLockFile(lockFilePath)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %v", filepath.Base(lockFilePath), err)
+	}
+
+	timings.phase(phaseDedupe)
+	deps, previous := bumpedDependencies(base, head)
+	timings.phase(phaseNetwork)
+	results := collectAuditResults(context.Background(), deps, registry, workers, false, nil, time.Time{})
+	timings.phase(phaseReport)
+	reasons.apply(results)
+	report := buildPrecheckReport(lockFilePath, results, previous)
+	report.Metadata = detectRunMetadata(filepath.Dir(lockFilePath))
+	links := newRemediationLinks(registry.registryURL, registry.ecosystem)
+	links.annotate(report.Packages)
+
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	fmt.Println(string(jsonData))
+
+	events := newNotificationEvents("diff", lockFilePath, results, report.Metadata, nil)
+	for _, event := range events {
+		links.annotate(event.Packages)
+	}
+	notifications.dispatch(context.Background(), events)
+
+	verdict := fmt.Sprintf("Curation precheck: %s (%d/%d bumped packages blocked)",
+		strings.ToUpper(report.Verdict), report.Blocked, report.Bumped)
+	if report.Verdict == verdictBlock {
+		return errors.New(verdict)
+	}
+	log.Info(verdict)
+	return nil
+}
+
+// loadBaseLock reads the base lock file either from disk or, for "git:<ref>", from the given git revision
+func loadBaseLock(baseRef, lockFilePath string) (*audit.DependencyTree, error) {
+	if !strings.HasPrefix(baseRef, "git:") {
+		return audit.ParseLockFile(baseRef)
+	}
+
+	ref := strings.TrimPrefix(baseRef, "git:")
+	cmd := exec.Command("git", "show", ref+":./"+filepath.Base(lockFilePath))
+	cmd.Dir = filepath.Dir(lockFilePath)
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s at %s: %v", lockFilePath, ref, err)
+	}
+	return audit.ParseLockFileData(lockFilePath, data)
+}
+
+// bumpedDependencies returns the packages that were added or changed version in head, together with
+// the version each changed package had in base
+func bumpedDependencies(base, head *audit.DependencyTree) ([]audit.Dependency, map[string]string) {
+	diff := diffTrees(base, head)
+
+	var deps []audit.Dependency
+	previous := make(map[string]string)
+	relationships := head.Relationships()
+	for _, pkg := range diff.Added {
+		deps = append(deps, audit.Dependency{Name: pkg.Name, Version: pkg.Version, Type: pkg.Type, Relationship: relationships[pkg.Name]})
+	}
+	for _, change := range diff.Changed {
+		previous[change.Name] = change.FromVersion
+		deps = append(deps, audit.Dependency{Name: change.Name, Version: change.ToVersion, Type: change.Type, Relationship: relationships[change.Name]})
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].Name < deps[j].Name
+	})
+
+	return deps, previous
+}
+
+func buildPrecheckReport(lockFilePath string, results []audit.AuditResult, previous map[string]string) PrecheckReport {
+	report := PrecheckReport{
+		Verdict:  verdictPass,
+		LockFile: lockFilePath,
+		Bumped:   len(results),
+		Packages: []ResultEntry{},
+	}
+
+	for _, result := range results {
+		entry := newResultEntry(result)
+		entry.PreviousVersion = previous[result.Name]
+		// Anything the curated registry won't serve would fail the bot's install
+		if isBlocking(result) {
+			report.Blocked++
+			report.Verdict = verdictBlock
+		}
+		report.Packages = append(report.Packages, entry)
+	}
+
+	return report
+}
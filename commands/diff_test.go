@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBumpedDependencies(t *testing.T) {
+	base := &audit.DependencyTree{Packages: map[string]audit.PackageInfo{
+		"abbrev": {Version: "1.1.1", Type: "package"},
+		"semver": {Version: "7.6.0", Type: "package"},
+	}}
+	head := &audit.DependencyTree{Packages: map[string]audit.PackageInfo{
+		"abbrev":  {Version: "2.0.0", Type: "package"},
+		"semver":  {Version: "7.6.0", Type: "package"},
+		"yallist": {Version: "4.0.0", Type: "package"},
+	}}
+
+	deps, previous := bumpedDependencies(base, head)
+	assert.Equal(t, []audit.Dependency{
+		{Name: "abbrev", Version: "2.0.0", Type: "package"},
+		{Name: "yallist", Version: "4.0.0", Type: "package"},
+	}, deps)
+	assert.Equal(t, map[string]string{"abbrev": "1.1.1"}, previous)
+}
+
+func TestBuildPrecheckReport(t *testing.T) {
+	results := []audit.AuditResult{
+		{Name: "abbrev", Version: "2.0.0", StatusCode: http.StatusOK},
+		{Name: "yallist", Version: "4.0.0", StatusCode: http.StatusForbidden},
+		{Name: "semver", Version: "7.6.1", Error: errors.New("timeout")},
+	}
+
+	report := buildPrecheckReport("pnpm-lock.yaml", results, map[string]string{"abbrev": "1.1.1"})
+	assert.Equal(t, verdictBlock, report.Verdict)
+	assert.Equal(t, 3, report.Bumped)
+	assert.Equal(t, 2, report.Blocked)
+	assert.Equal(t, "1.1.1", report.Packages[0].PreviousVersion)
+	assert.Equal(t, "timeout", report.Packages[2].Error)
+}
+
+func TestBuildPrecheckReportPass(t *testing.T) {
+	report := buildPrecheckReport("pnpm-lock.yaml", nil, nil)
+	assert.Equal(t, verdictPass, report.Verdict)
+	assert.Empty(t, report.Packages)
+}
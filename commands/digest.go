@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+const (
+	digestAlgorithmFlag = "digest-algorithm"
+	digestAlgorithmEnv  = "CA_EXTENSION_DIGEST_ALGORITHM"
+
+	digestSHA256 = "sha256"
+	digestSHA512 = "sha512"
+)
+
+// digestAlgorithms maps the algorithms of the written and verified digests, named as in OCI digests, to their
+// hashes. Both are FIPS 140 approved, and served by the validated module in FIPS builds (GOFIPS140).
+var digestAlgorithms = map[string]func() hash.Hash{
+	digestSHA256: sha256.New,
+	digestSHA512: sha512.New,
+}
+
+func getDigestAlgorithmFlag() components.Flag {
+	return components.NewStringFlag(
+		digestAlgorithmFlag,
+		"Algorithm of the digests written: "+digestSHA256+" or "+digestSHA512+". Digests are verified with the algorithm they name. Defaults to "+digestSHA256,
+		components.WithHelpValue(digestSHA256+"|"+digestSHA512),
+	)
+}
+
+func getDigestAlgorithm(c *components.Context) (string, error) {
+	return parseDigestAlgorithm(flagOrEnv(c, digestAlgorithmFlag, digestAlgorithmEnv))
+}
+
+func parseDigestAlgorithm(algorithm string) (string, error) {
+	algorithm = strings.ToLower(algorithm)
+	if algorithm == "" {
+		return digestSHA256, nil
+	}
+	if _, ok := digestAlgorithms[algorithm]; !ok {
+		return "", fmt.Errorf("unsupported --%s '%s'. Expected %s or %s", digestAlgorithmFlag, algorithm, digestSHA256, digestSHA512)
+	}
+	return algorithm, nil
+}
+
+// digestOf returns the <algorithm>:<hex> digest of the data
+func digestOf(algorithm string, data []byte) string {
+	h := digestAlgorithms[algorithm]()
+	h.Write(data)
+	return algorithm + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// verifyDigest checks the data matches an <algorithm>:<hex> digest, or a bare hex digest of the algorithm its
+// length tells, and returns the digest of the data
+func verifyDigest(expected string, data []byte) (string, error) {
+	algorithm, encoded, found := strings.Cut(expected, ":")
+	if !found {
+		encoded = expected
+		switch len(expected) {
+		case sha256.Size * 2:
+			algorithm = digestSHA256
+		case sha512.Size * 2:
+			algorithm = digestSHA512
+		default:
+			return "", fmt.Errorf("invalid digest '%s'. Expected <algorithm>:<hex>", expected)
+		}
+	}
+	algorithm = strings.ToLower(algorithm)
+	if _, ok := digestAlgorithms[algorithm]; !ok {
+		return "", fmt.Errorf("unsupported digest algorithm '%s'. Expected %s or %s", algorithm, digestSHA256, digestSHA512)
+	}
+	actual := digestOf(algorithm, data)
+	if algorithm+":"+strings.ToLower(encoded) != actual {
+		return actual, fmt.Errorf("digest mismatch: expected %s, got %s", expected, actual)
+	}
+	return actual, nil
+}
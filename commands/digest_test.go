@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigestOf(t *testing.T) {
+	assert.Equal(t, "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", digestOf(digestSHA256, []byte("hello")))
+	assert.Equal(t, "sha512:9b71d224bd62f3785d96d46ad3ea3d73319bfbc2890caadae2dff72519673ca72323c3d99ba5c11d7c7acc6e14b8c5da0c4663475c2e5c3adef46f73bcdec043",
+		digestOf(digestSHA512, []byte("hello")))
+}
+
+func TestParseDigestAlgorithm(t *testing.T) {
+	algorithm, err := parseDigestAlgorithm("")
+	assert.NoError(t, err)
+	assert.Equal(t, digestSHA256, algorithm)
+
+	algorithm, err = parseDigestAlgorithm("SHA512")
+	assert.NoError(t, err)
+	assert.Equal(t, digestSHA512, algorithm)
+
+	_, err = parseDigestAlgorithm("md5")
+	assert.ErrorContains(t, err, "unsupported --digest-algorithm 'md5'")
+}
+
+func TestVerifyDigest(t *testing.T) {
+	data := []byte("hello")
+	digest, err := verifyDigest(digestOf(digestSHA512, data), data)
+	assert.NoError(t, err)
+	assert.Equal(t, digestOf(digestSHA512, data), digest)
+
+	// Bare hex digests are told apart by their length
+	_, err = verifyDigest("2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824", data)
+	assert.NoError(t, err)
+
+	_, err = verifyDigest(digestOf(digestSHA256, []byte("other")), data)
+	assert.ErrorContains(t, err, "digest mismatch")
+	_, err = verifyDigest("md5:5d41402abc4b2a76b9719d911017c592", data)
+	assert.ErrorContains(t, err, "unsupported digest algorithm 'md5'")
+	_, err = verifyDigest("abc", data)
+	assert.ErrorContains(t, err, "invalid digest 'abc'")
+}
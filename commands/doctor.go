@@ -0,0 +1,245 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+	checkSkip = "skip"
+
+	maxClockSkew = 5 * time.Minute
+)
+
+func GetDoctorCommand() components.Command {
+	return components.Command{
+		Name:        "doctor",
+		Description: "Verifies connectivity, authentication and the curation configuration of the target repository.",
+		Flags:       append(getRegistryFlags(), getAccessibleFlag()),
+		EnvVars:     getRegistryEnvVars(),
+		Action: func(c *components.Context) error {
+			return doctorCmd(c)
+		},
+	}
+}
+
+// doctorCheck represents the outcome of a single health check
+type doctorCheck struct {
+	name   string
+	status string
+	detail string
+}
+
+type doctor struct {
+	artifactoryURL string
+	repo           string
+	credentials    audit.Credentials
+	// JFrog project of the repository, if any
+	project string
+	client  *http.Client
+	now     func() time.Time
+	// Proxy of the requests to Artifactory, if any: the --proxy or the environment proxy, unless NO_PROXY
+	// excludes its host
+	proxy *url.URL
+}
+
+func doctorCmd(c *components.Context) error {
+	if len(c.Arguments) != 0 {
+		return wrongArguments("doctor", "", len(c.Arguments))
+	}
+	registry, err := getRegistryConfiguration(c)
+	if err != nil {
+		return err
+	}
+	artifactoryURL, repo := splitRegistryURL(registry.registryURL)
+	if artifactoryURL == "" {
+		return fmt.Errorf("%s is not an Artifactory repository URL", registry.registryURL)
+	}
+
+	proxy, err := registry.proxyOf(artifactoryURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL: %v", err)
+	}
+	d := &doctor{
+		artifactoryURL: artifactoryURL,
+		repo:           repo,
+		credentials:    registry.credentials(),
+		project:        registry.project,
+		client:         &http.Client{Timeout: 30 * time.Second, Transport: registry.transport()},
+		now:            time.Now,
+		proxy:          proxy,
+	}
+
+	display := getDisplayFormat(c)
+	failed := 0
+	for _, check := range d.run() {
+		fmt.Println(formatDoctorCheck(check, display))
+		if check.status == checkFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d health checks failed", failed)
+	}
+	return nil
+}
+
+// splitRegistryURL splits a registry URL such as https://acme.jfrog.io/artifactory/api/npm/npm-remote into the
+// Artifactory base URL and the repository key
+func splitRegistryURL(registryURL string) (string, string) {
+	index := strings.Index(registryURL, "/api/")
+	if index < 0 {
+		return "", ""
+	}
+	parts := strings.Split(strings.Trim(registryURL[index+len("/api/"):], "/"), "/")
+	if len(parts) < 2 {
+		return "", ""
+	}
+	// NuGet v3 repositories have the version before the repository key
+	if parts[0] == "nuget" && parts[1] == "v3" && len(parts) > 2 {
+		return registryURL[:index], parts[2]
+	}
+	return registryURL[:index], parts[1]
+}
+
+func (d *doctor) run() []doctorCheck {
+	checks := []doctorCheck{d.checkConnectivity()}
+	if checks[0].status == checkFail {
+		return append(checks, doctorCheck{name: "Repository", status: checkSkip, detail: "Artifactory is unreachable"})
+	}
+	checks = append(checks, d.checkRepository()...)
+	return append(checks, d.checkProxy())
+}
+
+// checkConnectivity pings Artifactory and compares its clock with the local one
+func (d *doctor) checkConnectivity() doctorCheck {
+	resp, err := d.get("/api/system/ping")
+	if err != nil {
+		return doctorCheck{name: "Connectivity", status: checkFail, detail: err.Error()}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return doctorCheck{name: "Connectivity", status: checkFail, detail: fmt.Sprintf("ping returned %d", resp.StatusCode)}
+	}
+
+	check := doctorCheck{name: "Connectivity", status: checkPass, detail: d.artifactoryURL + " is reachable"}
+	if serverTime, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		skew := d.now().Sub(serverTime)
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > maxClockSkew {
+			check.status = checkWarn
+			check.detail += fmt.Sprintf(", but the local clock is %v off, which may invalidate tokens", skew.Round(time.Second))
+		}
+	}
+	return check
+}
+
+// checkRepository verifies authentication, and that the repository exists, is a remote and has curation enabled
+func (d *doctor) checkRepository() []doctorCheck {
+	resp, err := d.get("/api/repositories/" + url.PathEscape(d.repo))
+	if err != nil {
+		return []doctorCheck{{name: "Authentication", status: checkFail, detail: err.Error()}}
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return []doctorCheck{{name: "Authentication", status: checkFail, detail: "the credentials were rejected (401)"}}
+	case http.StatusForbidden:
+		detail := "the credentials lack permissions on " + d.repo + " (403)"
+		if d.project == "" {
+			detail += ". If the repository belongs to a JFrog project, set --" + projectFlag
+		}
+		return []doctorCheck{{name: "Authentication", status: checkFail, detail: detail}}
+	case http.StatusBadRequest, http.StatusNotFound:
+		return []doctorCheck{
+			{name: "Authentication", status: checkPass, detail: "credentials accepted"},
+			{name: "Repository", status: checkFail, detail: "repository " + d.repo + " does not exist"},
+		}
+	default:
+		return []doctorCheck{{name: "Repository", status: checkFail, detail: fmt.Sprintf("unexpected response: %d", resp.StatusCode)}}
+	}
+
+	var repoConfig struct {
+		Rclass      string `json:"rclass"`
+		PackageType string `json:"packageType"`
+		Curated     bool   `json:"curated"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&repoConfig); err != nil {
+		return []doctorCheck{{name: "Repository", status: checkFail, detail: "error parsing repository configuration: " + err.Error()}}
+	}
+
+	checks := []doctorCheck{{name: "Authentication", status: checkPass, detail: "credentials accepted"}}
+	if repoConfig.Rclass != "remote" {
+		checks = append(checks, doctorCheck{name: "Repository", status: checkFail,
+			detail: fmt.Sprintf("%s is a %s %s repository, curation applies to remote repositories", d.repo, repoConfig.Rclass, repoConfig.PackageType)})
+		return append(checks, doctorCheck{name: "Curation", status: checkSkip, detail: "not a remote repository"})
+	}
+	checks = append(checks, doctorCheck{name: "Repository", status: checkPass, detail: fmt.Sprintf("%s is a remote %s repository", d.repo, repoConfig.PackageType)})
+	if !repoConfig.Curated {
+		return append(checks, doctorCheck{name: "Curation", status: checkFail, detail: "curation is not enabled on " + d.repo})
+	}
+	return append(checks, doctorCheck{name: "Curation", status: checkPass, detail: "curation is enabled on " + d.repo})
+}
+
+// checkProxy verifies the proxy of the requests to Artifactory accepts connections
+func (d *doctor) checkProxy() doctorCheck {
+	if d.proxy == nil {
+		return doctorCheck{name: "Proxy", status: checkSkip, detail: "no proxy configured"}
+	}
+	address := proxyAddress(d.proxy)
+	conn, err := net.DialTimeout("tcp", address, 10*time.Second)
+	if err != nil {
+		return doctorCheck{name: "Proxy", status: checkFail, detail: err.Error()}
+	}
+	conn.Close()
+	return doctorCheck{name: "Proxy", status: checkPass, detail: address + " is reachable"}
+}
+
+// Ports proxies listen on when their URL has none, by scheme
+var defaultProxyPorts = map[string]string{"http": "80", "https": "443", "socks5": "1080", "socks5h": "1080"}
+
+// proxyAddress returns the host and port to connect to the proxy on, defaulting the port from the scheme
+func proxyAddress(proxy *url.URL) string {
+	if proxy.Port() != "" {
+		return proxy.Host
+	}
+	port, ok := defaultProxyPorts[strings.ToLower(proxy.Scheme)]
+	if !ok {
+		port = "80"
+	}
+	return net.JoinHostPort(proxy.Hostname(), port)
+}
+
+func (d *doctor) get(path string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", d.artifactoryURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	d.credentials.Authorize(req)
+	return d.client.Do(req)
+}
+
+func formatDoctorCheck(check doctorCheck, display *displayFormat) string {
+	icon := map[string]string{
+		checkPass: "✅",
+		checkWarn: "⚠️",
+		checkFail: "❌",
+		checkSkip: "➖",
+	}[check.status]
+	return fmt.Sprintf("%s %s: %s", display.status(icon), check.name, check.detail)
+}
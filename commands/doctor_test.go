@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitRegistryURL(t *testing.T) {
+	artifactoryURL, repo := splitRegistryURL("https://acme.jfrog.io/artifactory/api/npm/npm-remote")
+	assert.Equal(t, "https://acme.jfrog.io/artifactory", artifactoryURL)
+	assert.Equal(t, "npm-remote", repo)
+
+	artifactoryURL, repo = splitRegistryURL("https://acme.jfrog.io/artifactory/api/nuget/v3/nuget-remote")
+	assert.Equal(t, "https://acme.jfrog.io/artifactory", artifactoryURL)
+	assert.Equal(t, "nuget-remote", repo)
+
+	artifactoryURL, _ = splitRegistryURL("https://registry.npmjs.org")
+	assert.Empty(t, artifactoryURL)
+}
+
+func newTestDoctor(t *testing.T, repoConfig string, serverTime time.Time) *doctor {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/system/ping":
+			w.Header().Set("Date", serverTime.UTC().Format(http.TimeFormat))
+			_, _ = w.Write([]byte("OK"))
+		case "/api/repositories/npm-remote":
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(repoConfig))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("HTTP_PROXY", "")
+
+	return &doctor{
+		artifactoryURL: server.URL,
+		repo:           "npm-remote",
+		credentials:    audit.Credentials{AccessToken: "token"},
+		client:         server.Client(),
+		now:            time.Now,
+	}
+}
+
+func TestDoctorHealthy(t *testing.T) {
+	d := newTestDoctor(t, `{"rclass":"remote","packageType":"npm","curated":true}`, time.Now())
+	var statuses []string
+	for _, check := range d.run() {
+		statuses = append(statuses, check.name+":"+check.status)
+	}
+	assert.Equal(t, []string{"Connectivity:pass", "Authentication:pass", "Repository:pass", "Curation:pass", "Proxy:skip"}, statuses)
+}
+
+func TestDoctorCurationDisabledAndClockSkew(t *testing.T) {
+	d := newTestDoctor(t, `{"rclass":"remote","packageType":"npm","curated":false}`, time.Now().Add(-time.Hour))
+	checks := d.run()
+	assert.Equal(t, checkWarn, checks[0].status)
+	assert.Equal(t, checkFail, checks[3].status)
+}
+
+func TestDoctorUnauthorized(t *testing.T) {
+	d := newTestDoctor(t, `{}`, time.Now())
+	d.credentials.AccessToken = "wrong"
+	checks := d.run()
+	assert.Equal(t, "Authentication", checks[1].name)
+	assert.Equal(t, checkFail, checks[1].status)
+}
+
+func TestProxyAddress(t *testing.T) {
+	for proxy, address := range map[string]string{
+		"http://proxy.acme.io":            "proxy.acme.io:80",
+		"https://proxy.acme.io":           "proxy.acme.io:443",
+		"socks5://proxy.acme.io":          "proxy.acme.io:1080",
+		"socks5h://proxy.acme.io":         "proxy.acme.io:1080",
+		"https://proxy.acme.io:8443":      "proxy.acme.io:8443",
+		"http://[2001:db8::1]":            "[2001:db8::1]:80",
+		"https://user:secret@[::1]:3128/": "[::1]:3128",
+	} {
+		parsed, err := url.Parse(proxy)
+		assert.NoError(t, err)
+		assert.Equal(t, address, proxyAddress(parsed), proxy)
+	}
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const ecosystemNpm = "npm"
+
+// registryPaths maps each ecosystem to the Artifactory API path of a repository, %s being the repository key
+var registryPaths = map[string]string{
+	"cargo":    "api/cargo/%s",
+	"composer": "api/composer/%s",
+	"conan":    "api/conan/%s",
+	"gems":     "api/gems/%s",
+	"go":       "api/go/%s",
+	"gradle":   "%s",
+	"maven":    "%s",
+	"npm":      "api/npm/%s",
+	"nuget":    "api/nuget/v3/%s",
+	"pypi":     "api/pypi/%s/simple",
+}
+
+// buildRegistryURL constructs the registry URL of an ecosystem from the Artifactory (or JFrog platform)
+// base URL and the repository key, e.g. https://acme.jfrog.io + npm-remote becomes
+// https://acme.jfrog.io/artifactory/api/npm/npm-remote.
+func buildRegistryURL(ecosystem, artifactoryURL, repo string) (string, error) {
+	path, exists := registryPaths[ecosystem]
+	if !exists {
+		return "", fmt.Errorf("unsupported ecosystem '%s'. Expected one of: %s", ecosystem, strings.Join(supportedEcosystems(), ", "))
+	}
+	if artifactoryURL == "" || repo == "" {
+		return "", fmt.Errorf("both the Artifactory URL and the repository key are required")
+	}
+
+	baseURL := strings.TrimSuffix(artifactoryURL, "/")
+	if !strings.HasSuffix(baseURL, "/artifactory") {
+		baseURL += "/artifactory"
+	}
+	return baseURL + "/" + fmt.Sprintf(path, strings.Trim(repo, "/")), nil
+}
+
+func supportedEcosystems() []string {
+	var ecosystems []string
+	for ecosystem := range registryPaths {
+		ecosystems = append(ecosystems, ecosystem)
+	}
+	sort.Strings(ecosystems)
+	return ecosystems
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildRegistryURL(t *testing.T) {
+	tests := []struct {
+		ecosystem      string
+		artifactoryURL string
+		expected       string
+	}{
+		{"npm", "https://acme.jfrog.io", "https://acme.jfrog.io/artifactory/api/npm/curated"},
+		{"npm", "https://acme.jfrog.io/artifactory/", "https://acme.jfrog.io/artifactory/api/npm/curated"},
+		{"pypi", "https://acme.jfrog.io", "https://acme.jfrog.io/artifactory/api/pypi/curated/simple"},
+		{"go", "https://acme.jfrog.io", "https://acme.jfrog.io/artifactory/api/go/curated"},
+		{"maven", "https://acme.jfrog.io", "https://acme.jfrog.io/artifactory/curated"},
+	}
+	for _, test := range tests {
+		t.Run(test.ecosystem, func(t *testing.T) {
+			registryURL, err := buildRegistryURL(test.ecosystem, test.artifactoryURL, "curated")
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, registryURL)
+		})
+	}
+}
+
+func TestBuildRegistryURLErrors(t *testing.T) {
+	_, err := buildRegistryURL("bower", "https://acme.jfrog.io", "curated")
+	assert.ErrorContains(t, err, "unsupported ecosystem 'bower'")
+
+	_, err = buildRegistryURL("npm", "https://acme.jfrog.io", "")
+	assert.ErrorContains(t, err, "repository key")
+}
+
+func TestRegistryURLFromArtifactoryEnv(t *testing.T) {
+	t.Setenv(registryURLEnv, "")
+	t.Setenv(artifactoryURLEnv, "https://acme.jfrog.io")
+	t.Setenv(repoEnv, "npm-remote")
+
+	conf, err := getRegistryConfiguration(&components.Context{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/api/npm/npm-remote", conf.registryURL)
+}
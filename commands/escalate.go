@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	escalateAfterFlag = "escalate-after"
+
+	escalateAfterEnv = "CA_EXTENSION_ESCALATE_AFTER"
+)
+
+// escalationPolicy fails the run on the accepted findings, acknowledged by a warn rule or in the baseline, that are
+// left unresolved past the age of their kind. A nil policy escalates nothing.
+type escalationPolicy struct {
+	// Age past which the accepted findings of each kind, blocked or not-found, fail the run
+	ages map[string]time.Duration
+	now  time.Time
+}
+
+// escalatedFinding is an accepted finding unresolved past its age
+type escalatedFinding struct {
+	result audit.AuditResult
+	since  time.Time
+	after  time.Duration
+}
+
+func getEscalateAfterFlag() components.Flag {
+	return components.NewStringFlag(
+		escalateAfterFlag,
+		"Fail the run on findings accepted by the ignore rules or the --"+baselineFlag+" for longer than an age, e.g. "+failOnBlocked+"=30d,"+failOnNotFound+"=90d, or 60d for both, to get long-lived exceptions cleaned up",
+		components.WithHelpValue("ages"),
+	)
+}
+
+func getEscalationPolicy(c *components.Context) (*escalationPolicy, error) {
+	return parseEscalationPolicy(flagOrEnv(c, escalateAfterFlag, escalateAfterEnv), time.Now())
+}
+
+// parseEscalationPolicy reads a comma separated list of <kind>=<age>, or a bare age for both kinds
+func parseEscalationPolicy(value string, now time.Time) (*escalationPolicy, error) {
+	if value == "" {
+		return nil, nil
+	}
+	policy := &escalationPolicy{ages: make(map[string]time.Duration), now: now}
+	for _, entry := range splitList(value) {
+		kind, age, found := strings.Cut(entry, "=")
+		if !found {
+			kind, age = "", entry
+		}
+		after, err := parseEscalationAge(age)
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case "":
+			policy.ages[failOnBlocked], policy.ages[failOnNotFound] = after, after
+		case failOnBlocked, failOnNotFound:
+			policy.ages[kind] = after
+		default:
+			return nil, fmt.Errorf("unsupported --%s finding '%s'. Expected %s or %s", escalateAfterFlag, kind, failOnBlocked, failOnNotFound)
+		}
+	}
+	return policy, nil
+}
+
+// parseEscalationAge reads a number of days, such as 30d, or a duration, such as 12h
+func parseEscalationAge(value string) (time.Duration, error) {
+	var age time.Duration
+	var err error
+	if days, found := strings.CutSuffix(value, "d"); found {
+		var n int
+		n, err = strconv.Atoi(days)
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		age, err = time.ParseDuration(value)
+	}
+	if err != nil || age <= 0 {
+		return 0, fmt.Errorf("invalid --%s age '%s'. Expected a positive number of days such as 30d, or a duration such as 12h", escalateAfterFlag, value)
+	}
+	return age, nil
+}
+
+// findingKind returns whether a result is a blocked or a not-found finding, or an empty kind
+func findingKind(result audit.AuditResult) string {
+	if result.Error != nil || result.Pending {
+		return ""
+	}
+	switch result.StatusCode {
+	case http.StatusForbidden:
+		return failOnBlocked
+	case http.StatusNotFound:
+		return failOnNotFound
+	}
+	return ""
+}
+
+// acceptedSince returns since when the finding of a result has been accepted: the earliest of the since date of the
+// ignore rule acknowledging it and of when it was first recorded in the baseline
+func acceptedSince(result audit.AuditResult, ignore *ignoreList, baseline *auditBaseline, now time.Time) (time.Time, bool) {
+	since, accepted := ignore.since(result)
+	if firstSeen, inBaseline := baseline.since(result, now); inBaseline && (!accepted || firstSeen.Before(since)) {
+		since, accepted = firstSeen, true
+	}
+	return since, accepted
+}
+
+// escalated returns the accepted findings unresolved past their age, the oldest first
+func (policy *escalationPolicy) escalated(results []audit.AuditResult, ignore *ignoreList, baseline *auditBaseline) []escalatedFinding {
+	if policy == nil {
+		return nil
+	}
+	var findings []escalatedFinding
+	for _, result := range results {
+		after, ok := policy.ages[findingKind(result)]
+		if !ok {
+			continue
+		}
+		if since, accepted := acceptedSince(result, ignore, baseline, policy.now); accepted && policy.now.Sub(since) > after {
+			findings = append(findings, escalatedFinding{result: result, since: since, after: after})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].since.Before(findings[j].since)
+	})
+	return findings
+}
+
+// describe returns the ages of the policy, as --escalate-after takes them
+func (policy *escalationPolicy) describe() string {
+	var ages []string
+	for _, kind := range sortedKeys(policy.ages) {
+		ages = append(ages, kind+"="+formatEscalationAge(policy.ages[kind]))
+	}
+	return strings.Join(ages, ",")
+}
+
+// formatEscalationAge prints whole days as such, e.g. 30d
+func formatEscalationAge(age time.Duration) string {
+	if age%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", age/(24*time.Hour))
+	}
+	return age.String()
+}
+
+// evaluate fails when accepted findings are unresolved past their age, whatever --fail-on is
+func (policy *escalationPolicy) evaluate(results []audit.AuditResult, ignore *ignoreList, baseline *auditBaseline) error {
+	escalated := policy.escalated(results, ignore, baseline)
+	if len(escalated) == 0 {
+		return nil
+	}
+	return fmt.Errorf("audit failed: %d accepted findings are unresolved past --%s=%s", len(escalated), escalateAfterFlag, policy.describe())
+}
+
+// print lists the accepted findings unresolved past their age
+func (policy *escalationPolicy) print(results []audit.AuditResult, ignore *ignoreList, baseline *auditBaseline, display *displayFormat) {
+	if policy == nil {
+		return
+	}
+	escalated := policy.escalated(results, ignore, baseline)
+	fmt.Printf("\n\nFindings unresolved past --%s (%s):", escalateAfterFlag, policy.describe())
+	if len(escalated) == 0 {
+		fmt.Printf("\nNone")
+	}
+	for _, finding := range escalated {
+		days := int(policy.now.Sub(finding.since) / (24 * time.Hour))
+		fmt.Printf("\n%s@%s: %s, accepted since %s (%s days, past %s)", finding.result.Name, finding.result.Version,
+			display.status(finding.result.Status), finding.since.UTC().Format("2006-01-02"), display.count(days), formatEscalationAge(finding.after))
+	}
+}
+
+// annotate marks the results of the report whose accepted findings are unresolved past their age
+func (policy *escalationPolicy) annotate(report *AuditReport, results []audit.AuditResult, ignore *ignoreList, baseline *auditBaseline) {
+	escalated := make(map[string]bool)
+	for _, finding := range policy.escalated(results, ignore, baseline) {
+		escalated[cacheKey(finding.result.Name, finding.result.Version)] = true
+	}
+	for i, result := range results {
+		if i < len(report.Results) && escalated[cacheKey(result.Name, result.Version)] {
+			report.Results[i].Escalated = true
+		}
+	}
+}
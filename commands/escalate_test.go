@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEscalationPolicy(t *testing.T) {
+	now := time.Now()
+	policy, err := parseEscalationPolicy("", now)
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+	assert.Empty(t, policy.escalated([]audit.AuditResult{{Name: "lodash", StatusCode: http.StatusForbidden}}, nil, nil))
+
+	policy, err = parseEscalationPolicy("blocked=30d, not-found=12h", now)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{failOnBlocked: 30 * 24 * time.Hour, failOnNotFound: 12 * time.Hour}, policy.ages)
+	assert.Equal(t, "blocked=30d,not-found=12h0m0s", policy.describe())
+
+	policy, err = parseEscalationPolicy("60d", now)
+	require.NoError(t, err)
+	assert.Equal(t, "blocked=60d,not-found=60d", policy.describe())
+
+	_, err = parseEscalationPolicy("errors=30d", now)
+	assert.EqualError(t, err, "unsupported --escalate-after finding 'errors'. Expected blocked or not-found")
+	_, err = parseEscalationPolicy("blocked=0d", now)
+	assert.ErrorContains(t, err, "invalid --escalate-after age '0d'")
+	_, err = parseEscalationPolicy("blocked=a month", now)
+	assert.ErrorContains(t, err, "invalid --escalate-after age 'a month'")
+}
+
+func TestEscalationPolicy(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "curation-baseline.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "schemaVersion": "1",
+  "recordedAt": "2024-05-20T00:00:00Z",
+  "findings": [
+    {"name": "left-pad", "version": "1.3.0", "statusCode": 404, "firstSeen": "2024-01-10T00:00:00Z"},
+    {"name": "lodash", "version": "4.17.20", "statusCode": 403}
+  ]
+}`), 0644))
+	baseline, err := loadAuditBaseline(path, false)
+	require.NoError(t, err)
+	ignore, err := newIgnoreList([]ignoreRule{
+		{Package: "minimist@1.2.5", Since: "2024-04-01"},
+		{Package: "axios"},
+	})
+	require.NoError(t, err)
+	_, err = newIgnoreList([]ignoreRule{{Package: "axios", Since: "April"}})
+	assert.EqualError(t, err, "invalid since date 'April' of the ignore rule of axios. Expected YYYY-MM-DD")
+
+	results := []audit.AuditResult{
+		// Acknowledged since 2024-04-01, for 61 days
+		{Name: "minimist", Version: "1.2.5", StatusCode: http.StatusForbidden},
+		// Aged from when the baseline was recorded, without a first-seen time
+		{Name: "lodash", Version: "4.17.20", StatusCode: http.StatusForbidden},
+		// In the baseline since 2024-01-10, for 143 days
+		{Name: "left-pad", Version: "1.3.0", StatusCode: http.StatusNotFound},
+		// Acknowledged without a since date
+		{Name: "axios", Version: "0.21.1", StatusCode: http.StatusForbidden},
+		// A new finding, left to --fail-on
+		{Name: "request", Version: "2.88.2", StatusCode: http.StatusForbidden},
+	}
+	policy, err := parseEscalationPolicy("blocked=30d,not-found=180d", now)
+	require.NoError(t, err)
+	escalated := policy.escalated(results, ignore, baseline)
+	require.Len(t, escalated, 1)
+	assert.Equal(t, "minimist", escalated[0].result.Name)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), escalated[0].since)
+
+	policy.ages[failOnBlocked], policy.ages[failOnNotFound] = 10*24*time.Hour, 90*24*time.Hour
+	var names []string
+	for _, finding := range policy.escalated(results, ignore, baseline) {
+		names = append(names, finding.result.Name)
+	}
+	assert.Equal(t, []string{"left-pad", "minimist", "lodash"}, names)
+
+	report := newAuditReport("pnpm-lock.yaml", results)
+	policy.annotate(report, results, ignore, baseline)
+	assert.True(t, report.Results[0].Escalated)
+	assert.False(t, report.Results[3].Escalated)
+
+	// Escalated findings fail the run whatever --fail-on is
+	failure, err := parseFailurePolicy("", "")
+	require.NoError(t, err)
+	failure.ignore, failure.baseline, failure.escalation = ignore, baseline, policy
+	assert.EqualError(t, failure.evaluate(results), "audit failed: 3 accepted findings are unresolved past --escalate-after=blocked=10d,not-found=90d")
+	assert.NoError(t, failure.evaluate(results[3:]))
+
+	// Updating the baseline keeps the first-seen times of its findings
+	baseline.update = true
+	require.NoError(t, baseline.record("pnpm-lock.yaml", results))
+	updated, err := loadAuditBaseline(path, false)
+	require.NoError(t, err)
+	since, accepted := updated.since(results[2], now)
+	assert.True(t, accepted)
+	assert.Equal(t, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), since)
+	since, _ = updated.since(results[1], now)
+	assert.Equal(t, time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC), since)
+	since, _ = updated.since(results[4], now)
+	assert.WithinDuration(t, time.Now(), since, time.Minute)
+}
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+// commandExample is an example invocation of a command, listed in its help and by the examples command
+type commandExample struct {
+	description string
+	// args follow the app name, e.g. audit pnpm-lock.yaml
+	args string
+}
+
+// commandExamples maps each command, prefixed by its namespace, to its examples
+var commandExamples = map[string][]commandExample{
+	"audit": {
+		{"Audit a pnpm lock file against a curated npm remote repository", "audit pnpm-lock.yaml --artifactory-url=https://acme.jfrog.io --repo=npm-remote"},
+		{"Audit with the curation audit API and store the results", "audit pnpm-lock.yaml --curation-api --output=results.json"},
+		{"Fail a CI step when more than 2 packages are blocked", "audit package-lock.json --fail-on=blocked --max-blocked=2"},
+		{"Audit only the direct dependencies of the projects", "audit pnpm-lock.yaml --direct-only"},
+		{"Audit the subtree of a single upgraded dependency in isolation", "audit pnpm-lock.yaml --root=react-dom"},
+		{"Only fail on the findings that aren't in the baseline", "audit pnpm-lock.yaml --baseline=curation-baseline.json --fail-on=any"},
+		{"Fail on baselined blocked packages left unresolved for more than 30 days", "audit pnpm-lock.yaml --baseline=curation-baseline.json --escalate-after=blocked=30d"},
+		{"Comment the results on the GitLab merge request and list them in its Code Quality widget", "audit pnpm-lock.yaml --gitlab-note --gitlab-code-quality=gl-code-quality-report.json"},
+		{"Fail when the registry checks take longer than 10 minutes", "audit pnpm-lock.yaml --budget=audit=10m"},
+		{"Allow slow proxies 2 minutes per request, and the audit 20 minutes in all", "audit pnpm-lock.yaml --request-timeout=2m --audit-timeout=20m"},
+		{"Audit behind a TLS-inspecting proxy with a corporate CA", "audit pnpm-lock.yaml --proxy=http://proxy.acme.io:3128 --cacert=/etc/ssl/acme-root-ca.pem"},
+		{"Audit with the Artifactory URL, credentials and resolver repository of a jf config server", "audit pnpm-lock.yaml --server-id=acme"},
+		{"Audit against a repository of the webapp JFrog project", "audit pnpm-lock.yaml --artifactory-url=https://acme.jfrog.io --repo=webapp-npm-remote --project=webapp"},
+		{"Explain the policies blocking packages with the internal process to follow", "audit pnpm-lock.yaml --curation-api --reasons-file=curation-reasons.yaml"},
+		{"Check the packages of the scopes .npmrc routes to an internal registry against it", "audit package-lock.json --npmrc"},
+		{"Fail before auditing when the lock file is out of date with package.json", "audit pnpm-lock.yaml --verify-lockfile"},
+		{"Audit the components of a CycloneDX SBOM, those of PyPI and Go against their own repositories", "audit bom.cdx.json --repo=npm-remote --ecosystem-repos=pypi=pypi-remote,go=go-remote"},
+		{"Write a CycloneDX SBOM of the lock file annotated with the curation status of each package", "audit pnpm-lock.yaml --sbom=cyclonedx"},
+		{"Write an SPDX document of the lock file for SPDX tooling", "audit pnpm-lock.yaml --sbom=spdx --sbom-output=out/bom.spdx.json"},
+		{"Push the report, dependency tree and SBOM next to the images of the lock file, with a token of that registry", "audit pnpm-lock.yaml --sbom=cyclonedx --oci-push=oci://ghcr.io/acme/curation-reports --oci-token=$GHCR_TOKEN"},
+		{"Only report the blocked production dependencies", "audit pnpm-lock.yaml --filter-results=\"status == 'blocked' && depClass == 'prod'\""},
+		{"Only parse the lock file into its dependency tree and SBOM, without checking packages", "audit pnpm-lock.yaml --skip-stages=audit --sbom=cyclonedx"},
+		{"Re-run an audit against the registry responses recorded in CI", "audit pnpm-lock.yaml --replay=cassette.json"},
+		{"Print how long each phase of the audit took, to tell a slow registry from a slow tree", "audit pnpm-lock.yaml --verbose"},
+		{"Audit an immutable checkout, writing only to an output directory", "audit pnpm-lock.yaml --read-only --output-dir=out --output=out/results.json"},
+		{"Audit a Python lock file against a curated PyPI remote repository", "audit poetry.lock --artifactory-url=https://acme.jfrog.io --repo=pypi-remote"},
+	},
+	"version": {
+		{"Print the source revision and build settings of the binary as JSON", "version --json"},
+	},
+	"check": {
+		{"Check packages without a lock file", "check lodash@4.17.21 @types/node@20.11.0"},
+	},
+	"can-i-add": {
+		{"Check the version a range resolves to before adding it", "can-i-add react@^18.2.0"},
+	},
+	"diff": {
+		{"Audit only the packages a branch adds or changes", "diff main pnpm-lock.yaml"},
+	},
+	"report": {
+		{"Render stored results as SARIF for code scanning", "report results.json --format=sarif"},
+		{"Render stored results as a markdown table to post as a pull request comment", "report results.json --format=markdown"},
+		{"Print the JSON schema of the results", "report --schema=report"},
+	},
+	"annotate": {
+		{"Mark a blocked package as triaged", "annotate results.json --set=lodash@4.17.20:triaged=true,lodash@4.17.20:owner=web"},
+	},
+	"serve": {
+		{"Serve checks over HTTP", "serve --registry-url=https://acme.jfrog.io/artifactory/api/npm/npm-remote --port=8080"},
+	},
+	"proxy": {
+		{"Record the installs forwarded to the curated registry", "proxy --artifactory-url=https://acme.jfrog.io --repo=npm-remote"},
+	},
+	"daemon": {
+		{"Keep a daemon with an outcome cache for the client commands", "daemon --artifactory-url=https://acme.jfrog.io --repo=npm-remote --cache"},
+	},
+	"config": {
+		{"Print the effective configuration", "config --artifactory-url=https://acme.jfrog.io --repo=npm-remote"},
+	},
+	"doctor": {
+		{"Verify the connectivity and curation of a repository", "doctor --artifactory-url=https://acme.jfrog.io --repo=npm-remote"},
+	},
+	"examples": {
+		{"List the examples of a command", "examples audit"},
+	},
+	"stats": {
+		{"Print the composition of the dependency tree of a lock file", "stats pnpm-lock.yaml"},
+		{"List the 20 heaviest direct dependencies as JSON", "stats package-lock.json --top=20 --format=json"},
+	},
+	"tree diff": {
+		{"Compare the dependency trees of two branches", "tree diff main/pnpm_dependency_tree.json pnpm_dependency_tree.json --format=json"},
+	},
+	"profile pull": {
+		{"Pull a curation profile from an OCI registry", "profile pull oci://acme.jfrog.io/curation/profile:1.4.0"},
+	},
+	"state export": {
+		{"Bundle the state of an audit for a support case", "state export pnpm-lock.yaml --results=results.json --output=support.tar.gz"},
+	},
+	"state import": {
+		{"Extract a state archive to reproduce the run", "state import support.tar.gz --dir=support"},
+	},
+	"client check": {
+		{"Check packages through the daemon", "client check lodash@4.17.21"},
+	},
+	"client status": {
+		{"Print the status of the daemon", "client status"},
+	},
+	"client stop": {
+		{"Stop the daemon", "client stop"},
+	},
+}
+
+func GetExamplesCommand() components.Command {
+	return components.Command{
+		Name:        "examples",
+		Description: "Prints example invocations of every command, or of the given command.",
+		Arguments: []components.Argument{
+			{
+				Name:        "command",
+				Description: "The command to print the examples of, e.g. audit or tree diff.",
+				Optional:    true,
+			},
+		},
+		Action: func(c *components.Context) error {
+			return examplesCmd(c)
+		},
+	}
+}
+
+func examplesCmd(c *components.Context) error {
+	command := strings.Join(c.Arguments, " ")
+	if command != "" && commandExamples[command] == nil {
+		return fmt.Errorf("unknown command '%s'. Expected one of: %s", command, strings.Join(sortedKeys(commandExamples), ", "))
+	}
+	fmt.Print(formatExamples(command))
+	return nil
+}
+
+// formatExamples lists the examples of a command, or of every command when command is empty
+func formatExamples(command string) string {
+	commands := []string{command}
+	if command == "" {
+		commands = sortedKeys(commandExamples)
+	}
+	var out strings.Builder
+	for _, name := range commands {
+		examples := commandExamples[name]
+		if len(examples) == 0 {
+			continue
+		}
+		fmt.Fprintf(&out, "%s\n", name)
+		for _, example := range examples {
+			fmt.Fprintf(&out, "  %s\n    $ %s\n", example.description, exampleCommandLine(example))
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+func exampleCommandLine(example commandExample) string {
+	return appName + " " + example.args
+}
+
+// withUsageExamples appends the examples of each command to the usages its help prints, which are relative to the
+// app like the generated ones
+func withUsageExamples(namespace string, cmds []components.Command) []components.Command {
+	for i := range cmds {
+		name := strings.TrimSpace(namespace + " " + cmds[i].Name)
+		examples := commandExamples[name]
+		if len(examples) == 0 {
+			continue
+		}
+		usage := &components.UsageOptions{}
+		if cmds[i].UsageOptions != nil {
+			*usage = *cmds[i].UsageOptions
+		}
+		for _, example := range examples {
+			usage.Usage = append(usage.Usage, example.args)
+		}
+		cmds[i].UsageOptions = usage
+	}
+	return cmds
+}
+
+// wrongArguments describes a call with the wrong number of arguments, pointing at the examples of the command
+func wrongArguments(command, arguments string, got int) error {
+	usage := strings.TrimSpace(appName + " " + command + " " + arguments)
+	return fmt.Errorf("wrong number of arguments, got %d. Expected: %s. Run '%s examples %s' for examples", got, usage, appName, command)
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEveryCommandHasExamples(t *testing.T) {
+	app := GetApp()
+	commands := map[string]components.Command{}
+	for _, cmd := range app.Commands {
+		commands[cmd.Name] = cmd
+	}
+	for _, namespace := range app.Subcommands {
+		for _, cmd := range namespace.Commands {
+			commands[namespace.Name+" "+cmd.Name] = cmd
+		}
+	}
+
+	for name, cmd := range commands {
+		if assert.NotEmpty(t, commandExamples[name], name) {
+			assert.NotNil(t, cmd.UsageOptions, name)
+			assert.Len(t, cmd.UsageOptions.Usage, len(commandExamples[name]), name)
+		}
+	}
+	for name, examples := range commandExamples {
+		cmd, exists := commands[name]
+		if !assert.True(t, exists, "examples of unknown command %s", name) {
+			continue
+		}
+		// The examples only use flags the command defines
+		flags := map[string]bool{}
+		for _, flag := range cmd.Flags {
+			flags[flag.GetName()] = true
+		}
+		for _, example := range examples {
+			assert.True(t, strings.HasPrefix(example.args+" ", name+" "), example.args)
+			for _, arg := range strings.Fields(example.args) {
+				if flag, isFlag := strings.CutPrefix(arg, "--"); isFlag {
+					flag, _, _ = strings.Cut(flag, "=")
+					assert.True(t, flags[flag], "%s doesn't have the --%s flag", name, flag)
+				}
+			}
+		}
+	}
+}
+
+func TestFormatExamples(t *testing.T) {
+	out := formatExamples("tree diff")
+	assert.Equal(t, "tree diff\n  Compare the dependency trees of two branches\n"+
+		"    $ ca-extension tree diff main/pnpm_dependency_tree.json pnpm_dependency_tree.json --format=json\n\n", out)
+	assert.Contains(t, formatExamples(""), "client stop\n  Stop the daemon\n    $ ca-extension client stop\n")
+}
+
+func TestWrongArguments(t *testing.T) {
+	assert.EqualError(t, wrongArguments("tree diff", "<base-tree> <tree>", 1),
+		"wrong number of arguments, got 1. Expected: ca-extension tree diff <base-tree> <tree>. Run 'ca-extension examples tree diff' for examples")
+	assert.EqualError(t, wrongArguments("config", "", 2),
+		"wrong number of arguments, got 2. Expected: ca-extension config. Run 'ca-extension examples config' for examples")
+}
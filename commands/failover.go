@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// How long an endpoint that failed is only tried after the healthy ones
+const endpointCooldown = 30 * time.Second
+
+// endpointFailover ranks the equivalent endpoints of a registry, the fastest first, and tracks the ones that failed
+type endpointFailover struct {
+	endpoints []string
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu sync.Mutex
+	// When each endpoint that failed is healthy again
+	unhealthyUntil map[string]time.Time
+	failovers      int
+}
+
+func newEndpointFailover(endpoints []string, cooldown time.Duration, now func() time.Time) *endpointFailover {
+	return &endpointFailover{
+		endpoints:      endpoints,
+		cooldown:       cooldown,
+		now:            now,
+		unhealthyUntil: make(map[string]time.Time),
+	}
+}
+
+// endpointOf returns the endpoint a URL is under
+func (f *endpointFailover) endpointOf(rawURL string) (string, bool) {
+	for _, endpoint := range f.endpoints {
+		if rawURL == endpoint || strings.HasPrefix(rawURL, endpoint+"/") || strings.HasPrefix(rawURL, endpoint+"?") {
+			return endpoint, true
+		}
+	}
+	return "", false
+}
+
+// candidates returns the endpoints to try, the healthy ones by rank and then those in their cooldown, which are
+// still better than failing the request
+func (f *endpointFailover) candidates() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.now()
+	healthy := make([]string, 0, len(f.endpoints))
+	var cooling []string
+	for _, endpoint := range f.endpoints {
+		if until, failed := f.unhealthyUntil[endpoint]; failed && now.Before(until) {
+			cooling = append(cooling, endpoint)
+		} else {
+			healthy = append(healthy, endpoint)
+		}
+	}
+	return append(healthy, cooling...)
+}
+
+func (f *endpointFailover) markUnhealthy(endpoint string, reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if until, failed := f.unhealthyUntil[endpoint]; !failed || !f.now().Before(until) {
+		log.Warn(fmt.Sprintf("Registry endpoint %s failed (%s), preferring the other endpoints for %v", endpoint, reason, f.cooldown))
+	}
+	f.unhealthyUntil[endpoint] = f.now().Add(f.cooldown)
+}
+
+func (f *endpointFailover) markHealthy(endpoint string, failedOver bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.unhealthyUntil, endpoint)
+	if failedOver {
+		f.failovers++
+	}
+}
+
+// summary tells how many requests failed over to another endpoint, or is empty when none did
+func (f *endpointFailover) summary(display *displayFormat) string {
+	if f == nil {
+		return ""
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failovers == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s requests failed over to another registry endpoint", display.count(f.failovers))
+}
+
+// failoverTransport sends the reads under an endpoint of the registry to the healthy endpoints in turn, until one
+// neither fails to connect nor answers 502, 503 or 504. Other requests, and the requests sent before the
+// endpoints are ranked, are passed through.
+type failoverTransport struct {
+	base     http.RoundTripper
+	registry *registryConfiguration
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	failover := t.registry.failover
+	if failover == nil || (req.Method != http.MethodGet && req.Method != http.MethodHead) || req.Body != nil && req.Body != http.NoBody {
+		return t.base.RoundTrip(req)
+	}
+	endpoint, ok := failover.endpointOf(req.URL.String())
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	rest := strings.TrimPrefix(req.URL.String(), endpoint)
+
+	candidates := failover.candidates()
+	var resp *http.Response
+	var err error
+	for i, candidate := range candidates {
+		attempt := req
+		if candidate != endpoint {
+			candidateURL, parseErr := url.Parse(candidate + rest)
+			if parseErr != nil {
+				continue
+			}
+			attempt = req.Clone(req.Context())
+			attempt.URL = candidateURL
+			attempt.Host = ""
+		}
+		resp, err = t.base.RoundTrip(attempt)
+		// Cancelled requests aren't failures of the endpoint
+		if req.Context().Err() != nil {
+			return resp, err
+		}
+		reason := endpointFailure(resp, err)
+		if reason == "" {
+			failover.markHealthy(candidate, candidate != endpoint)
+			return resp, err
+		}
+		failover.markUnhealthy(candidate, reason)
+		if i < len(candidates)-1 && resp != nil {
+			resp.Body.Close()
+		}
+	}
+	return resp, err
+}
+
+// endpointFailure describes why a response tells its endpoint is unavailable, or is empty when it doesn't
+func endpointFailure(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return resp.Status
+	}
+	return ""
+}
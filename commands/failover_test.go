@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailoverTransport(t *testing.T) {
+	var downRequests, upRequests []string
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downRequests = append(downRequests, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upRequests = append(upRequests, r.Method+" "+r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+	}))
+	defer up.Close()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	registry := &registryConfiguration{registryURL: down.URL + "/api/npm/npm-remote", readReplicas: []string{up.URL + "/api/npm/npm-remote"}}
+	registry.failover = newEndpointFailover([]string{down.URL + "/api/npm/npm-remote", up.URL + "/api/npm/npm-remote"}, time.Minute, func() time.Time { return now })
+	client := registry.httpClient(5 * time.Second)
+
+	get := func(url string) int {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, get(registry.registryURL+"/lodash/-/lodash-4.17.21.tgz"))
+	// During its cooldown, the failed endpoint is only tried after the healthy ones
+	assert.Equal(t, http.StatusOK, get(registry.registryURL+"/abbrev/-/abbrev-1.1.1.tgz"))
+	assert.Equal(t, []string{"GET /api/npm/npm-remote/lodash/-/lodash-4.17.21.tgz"}, downRequests)
+	assert.Equal(t, []string{"GET /api/npm/npm-remote/lodash/-/lodash-4.17.21.tgz", "GET /api/npm/npm-remote/abbrev/-/abbrev-1.1.1.tgz"}, upRequests)
+	assert.Equal(t, "2 requests failed over to another registry endpoint", registry.failover.summary(newDisplayFormat(true)))
+
+	// After the cooldown, the endpoint is tried first again
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, http.StatusOK, get(registry.registryURL+"/yallist/-/yallist-4.0.0.tgz"))
+	assert.Len(t, downRequests, 2)
+
+	// Publishes aren't failed over
+	req, err := http.NewRequest(http.MethodPut, registry.registryURL+"/yallist", nil)
+	assert.NoError(t, err)
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestFailoverTransportAllEndpointsDown(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+	unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachable.Close()
+
+	registry := &registryConfiguration{registryURL: unreachable.URL, readReplicas: []string{down.URL}}
+	registry.failover = newEndpointFailover([]string{unreachable.URL, down.URL}, time.Minute, time.Now)
+	resp, err := registry.httpClient(5 * time.Second).Get(unreachable.URL + "/lodash")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	// The response of the last endpoint is returned
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Empty(t, registry.failover.summary(newDisplayFormat(true)))
+}
+
+func TestEndpointOf(t *testing.T) {
+	failover := newEndpointFailover([]string{"https://eu.acme.io/api/npm/npm-remote", "https://us.acme.io/api/npm/npm-remote"}, time.Minute, time.Now)
+	endpoint, ok := failover.endpointOf("https://us.acme.io/api/npm/npm-remote/lodash")
+	assert.True(t, ok)
+	assert.Equal(t, "https://us.acme.io/api/npm/npm-remote", endpoint)
+	_, ok = failover.endpointOf("https://us.acme.io/api/npm/npm-remote-other/lodash")
+	assert.False(t, ok)
+}
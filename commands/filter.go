@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-cli-plugin-template/internal/expr"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	filterResultsFlag = "filter-results"
+
+	filterResultsEnv = "CA_EXTENSION_FILTER_RESULTS"
+
+	depClassProd = "prod"
+	depClassDev  = "dev"
+)
+
+// filterFields are the fields of the results --filter-results expressions can refer to
+var filterFields = map[string]expr.Type{
+	"name":         expr.String,
+	"version":      expr.String,
+	"type":         expr.String,
+	"status":       expr.String,
+	"statusCode":   expr.Number,
+	"error":        expr.String,
+	"timedOut":     expr.Bool,
+	"attempts":     expr.Number,
+	"relationship": expr.String,
+	"depClass":     expr.String,
+	"policies":     expr.StringList,
+}
+
+// resultFilter keeps the results matching a --filter-results expression. A nil filter keeps them all.
+type resultFilter struct {
+	program *expr.Program
+	// devOnly holds the packages only devDependencies pull in, nil when the lock file doesn't record them
+	devOnly map[string]bool
+}
+
+func getFilterResultsFlag() components.Flag {
+	return components.NewStringFlag(
+		filterResultsFlag,
+		"CEL expression selecting the results to print and write, e.g. \"status == 'blocked' && depClass == 'prod'\". The policy, baseline and notifications see all the results",
+		components.WithHelpValue("expression"),
+	)
+}
+
+func getResultFilter(c *components.Context) (*resultFilter, error) {
+	return parseResultFilter(flagOrEnv(c, filterResultsFlag, filterResultsEnv))
+}
+
+// parseResultFilter compiles a --filter-results expression, none for an empty one
+func parseResultFilter(expression string) (*resultFilter, error) {
+	if expression == "" {
+		return nil, nil
+	}
+	program, err := expr.Compile(expression, filterFields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %v", filterResultsFlag, err)
+	}
+	return &resultFilter{program: program}, nil
+}
+
+// setTree classifies the packages of the tree as prod or dev dependencies, for the depClass field
+func (f *resultFilter) setTree(tree *audit.DependencyTree) {
+	if f == nil {
+		return
+	}
+	f.devOnly = tree.DevOnly()
+}
+
+// keep tells whether the result matches the expression
+func (f *resultFilter) keep(result audit.AuditResult) bool {
+	if f == nil {
+		return true
+	}
+	matches, err := f.program.Eval(f.record(result))
+	if err != nil {
+		log.Warn(fmt.Sprintf("Error filtering the result of %s@%s: %v", result.Name, result.Version, err))
+		return true
+	}
+	return matches
+}
+
+// apply returns the results matching the expression, in their order
+func (f *resultFilter) apply(results []audit.AuditResult) []audit.AuditResult {
+	if f == nil {
+		return results
+	}
+	kept := []audit.AuditResult{}
+	for _, result := range results {
+		if f.keep(result) {
+			kept = append(kept, result)
+		}
+	}
+	log.Info(fmt.Sprintf("Reporting %d of %d results matching --%s %s", len(kept), len(results), filterResultsFlag, f.program))
+	return kept
+}
+
+// record returns the fields of a result for the expression. The status is that of the package index, e.g. blocked,
+// and depClass is empty when the lock file doesn't tell.
+func (f *resultFilter) record(result audit.AuditResult) map[string]interface{} {
+	entry := newResultEntry(result)
+	policies := make([]string, len(result.Policies))
+	for i, policy := range result.Policies {
+		policies[i] = policy.Policy
+	}
+	depClass := ""
+	if f.devOnly != nil {
+		depClass = depClassProd
+		if f.devOnly[result.Name] {
+			depClass = depClassDev
+		}
+	}
+	return map[string]interface{}{
+		"name":         result.Name,
+		"version":      result.Version,
+		"type":         result.Type,
+		"status":       indexStatus(result),
+		"statusCode":   result.StatusCode,
+		"error":        entry.Error,
+		"timedOut":     entry.TimedOut,
+		"attempts":     result.Attempts,
+		"relationship": result.Relationship,
+		"depClass":     depClass,
+		"policies":     policies,
+	}
+}
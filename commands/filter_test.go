@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultFilter(t *testing.T) {
+	filter, err := parseResultFilter("")
+	assert.NoError(t, err)
+	assert.Nil(t, filter)
+	assert.True(t, filter.keep(audit.AuditResult{Name: "react"}))
+
+	_, err = parseResultFilter("status == 403")
+	assert.EqualError(t, err, "invalid --filter-results: no matching overload for '==' with string and number at position 8")
+
+	tree, err := audit.ParseLockFile(filepath.Join("..", "audit", "testdata", "pnpm-workspace", "pnpm-lock.yaml"))
+	require.NoError(t, err)
+	filter, err = parseResultFilter("status == 'blocked' && depClass == 'prod'")
+	require.NoError(t, err)
+	filter.setTree(tree)
+	results := []audit.AuditResult{
+		{Name: "react", Version: "18.2.0", StatusCode: http.StatusForbidden},
+		{Name: "react-dom", Version: "18.2.0", StatusCode: http.StatusOK},
+		{Name: "typescript", Version: "5.3.3", StatusCode: http.StatusForbidden},
+		{Name: "js-tokens", Version: "4.0.0", StatusCode: http.StatusForbidden, Error: errors.New("connection reset")},
+	}
+	assert.Equal(t, results[:1], filter.apply(results))
+
+	filter, err = parseResultFilter("'malicious-package' in policies || error.contains('reset')")
+	require.NoError(t, err)
+	results[2].Policies = []audit.CurationPolicy{{Policy: "malicious-package"}}
+	assert.Equal(t, []audit.AuditResult{results[2], results[3]}, filter.apply(results))
+
+	// Without devDependencies in the lock file, depClass is empty
+	filter, err = parseResultFilter("depClass == ''")
+	require.NoError(t, err)
+	assert.True(t, filter.keep(results[0]))
+}
+
+func TestAuditFiltersResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "react-dom") || strings.Contains(r.URL.Path, "typescript") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var notified []NotificationEvent
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event NotificationEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		notified = append(notified, event)
+	}))
+	defer webhook.Close()
+	notifications, err := newNotificationDispatcher(&notificationConfig{Notifiers: []notifierConfig{{Type: notifierWebhook, URL: webhook.URL}}}, nil)
+	require.NoError(t, err)
+	filter, err := parseResultFilter("status == 'blocked' && depClass == 'prod'")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	streamed := filepath.Join(dir, "results.ndjson")
+	sink, err := newResultSink(streamed)
+	require.NoError(t, err)
+	stream := newResultStream(sink, 10)
+	stream.filter = filter
+	conf := &auditConfiguration{
+		registry:   &registryConfiguration{registryURL: server.URL, requestTimeout: time.Minute},
+		lockFile:   filepath.Join("..", "audit", "testdata", "pnpm-workspace", "pnpm-lock.yaml"),
+		workers:    2,
+		treeOutput: filepath.Join(dir, defaultTreeFileName),
+		output:     filepath.Join(dir, "results.json"),
+		stream:     stream,
+		filter:     filter,
+		display:    newDisplayFormat(true),
+		stages:     newRunStages(nil),
+		failure:    &failurePolicy{failOn: failOnBlocked},
+
+		notifications: notifications,
+	}
+	// The policy sees the blocked dev dependency the filter leaves out
+	err = runAudit(context.Background(), conf)
+	assert.ErrorContains(t, err, "2 of")
+
+	report, err := loadAuditReport(conf.output)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, "react-dom", report.Results[0].Name)
+	assert.Equal(t, 1, report.Blocked)
+	// The summary counts the results the filter leaves out
+	require.NotNil(t, report.Summary)
+	assert.Equal(t, 6, report.Summary.Packages)
+	assert.Equal(t, 2, report.Summary.Blocked)
+
+	data, err := os.ReadFile(streamed)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "\n"))
+	assert.Contains(t, string(data), `"react-dom"`)
+
+	// The notifications report the blocked dev dependency too
+	require.Len(t, notified, 2)
+	assert.Equal(t, eventPackagesBlocked, notified[1].Type)
+	assert.Equal(t, 6, notified[1].Total)
+	assert.Equal(t, 2, notified[1].Blocked)
+}
@@ -0,0 +1,415 @@
+package commands
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	registryURLFlag    = "registry-url"
+	artifactoryURLFlag = "artifactory-url"
+	repoFlag           = "repo"
+	accessTokenFlag    = "access-token"
+	workersFlag        = "workers"
+
+	registryURLEnv    = "CA_EXTENSION_REGISTRY_URL"
+	artifactoryURLEnv = "CA_EXTENSION_ARTIFACTORY_URL"
+	repoEnv           = "CA_EXTENSION_REPO"
+	accessTokenEnv    = "CA_EXTENSION_ACCESS_TOKEN"
+
+	defaultWorkers = 5
+)
+
+func getRegistryFlags() []components.Flag {
+	flags := []components.Flag{
+		components.NewStringFlag(
+			registryURLFlag,
+			"Base URL of the curated npm registry, e.g. https://acme.jfrog.io/artifactory/api/npm/npm-remote. Equivalent endpoints may follow, comma separated, which are read from as --"+readReplicasFlag,
+			components.WithHelpValue("url"),
+		),
+		components.NewStringFlag(
+			artifactoryURLFlag,
+			"Artifactory or JFrog platform URL, e.g. https://acme.jfrog.io. Used with --"+repoFlag+" instead of --"+registryURLFlag,
+			components.WithHelpValue("url"),
+		),
+		components.NewStringFlag(
+			repoFlag,
+			"Key of the curated remote repository in Artifactory",
+			components.WithHelpValue("key"),
+		),
+		components.NewStringFlag(
+			accessTokenFlag,
+			"JFrog access token used to authenticate against the registry",
+			components.WithHelpValue("token"),
+		),
+		getServerIDFlag(),
+		getProjectFlag(),
+		getHeadersFileFlag(),
+	}
+	flags = append(flags, getTransportFlags()...)
+	flags = append(flags, getRetryFlags()...)
+	flags = append(flags, getRequestTimeoutFlag(), getRateLimitFlag(), getTLSProfileFlag())
+	flags = append(flags, getCACertFlags()...)
+	flags = append(flags, getReadReplicasFlag())
+	return append(flags, getRedirectFlags()...)
+}
+
+func getWorkersFlag() components.Flag {
+	return components.NewStringFlag(
+		workersFlag,
+		fmt.Sprintf("Number of concurrent registry requests. Defaults to %d", defaultWorkers),
+		components.WithHelpValue("n"),
+	)
+}
+
+func getRegistryEnvVars() []components.EnvVar {
+	envVars := []components.EnvVar{
+		{
+			Name:        registryURLEnv,
+			Description: "Base URL of the curated npm registry, used when --" + registryURLFlag + " is not set.",
+		},
+		{
+			Name:        artifactoryURLEnv,
+			Description: "Artifactory or JFrog platform URL, used when --" + artifactoryURLFlag + " is not set.",
+		},
+		{
+			Name:        repoEnv,
+			Description: "Key of the curated remote repository, used when --" + repoFlag + " is not set.",
+		},
+		{
+			Name:        accessTokenEnv,
+			Description: "JFrog access token, used when --" + accessTokenFlag + " is not set.",
+		},
+		{
+			Name:        serverIDEnv,
+			Description: "ID of the JFrog CLI server to use, used when --" + serverIDFlag + " is not set.",
+		},
+		{
+			Name:        projectEnv,
+			Description: "Key of the JFrog project of the repository, used when --" + projectFlag + " is not set.",
+		},
+		{
+			Name:        headersFileEnv,
+			Description: "YAML file of the custom headers sent to registry hosts, used when --" + headersFileFlag + " is not set.",
+		},
+		{
+			Name:        proxyEnv,
+			Description: "Proxy of registry requests, used when --" + proxyFlag + " is not set.",
+		},
+		{
+			Name:        resolveEnv,
+			Description: "DNS overrides of registry hosts, used when --" + resolveFlag + " is not set.",
+		},
+		{
+			Name:        ipFamilyEnv,
+			Description: "IP family of registry connections, used when --" + ipFamilyFlag + " is not set.",
+		},
+	}
+	envVars = append(envVars, getRetryEnvVars()...)
+	envVars = append(envVars, components.EnvVar{
+		Name:        requestTimeoutEnv,
+		Description: "Timeout of each registry request of the package checks, used when --" + requestTimeoutFlag + " is not set.",
+	})
+	envVars = append(envVars, components.EnvVar{
+		Name:        tlsProfileEnv,
+		Description: "TLS profile of registry connections, used when --" + tlsProfileFlag + " is not set.",
+	})
+	envVars = append(envVars, getCACertEnvVars()...)
+	envVars = append(envVars, components.EnvVar{
+		Name:        rateLimitEnv,
+		Description: "Maximum registry requests per second, used when --" + rateLimitFlag + " is not set.",
+	}, components.EnvVar{
+		Name:        readReplicasEnv,
+		Description: "Comma separated URLs of read replicas of the registry, used when --" + readReplicasFlag + " is not set.",
+	})
+	return append(envVars, getRedirectEnvVars()...)
+}
+
+// registryConfiguration holds the registry connection settings shared by all commands
+type registryConfiguration struct {
+	registryURL string
+	accessToken string
+	// JFrog CLI server the settings not set otherwise are read from, and its user and password, or API key, when it
+	// authenticates without an access token
+	serverID string
+	user     string
+	password string
+	// JFrog project of the repository, sent in the project header of the requests to the registry hosts
+	project string
+	// Ecosystem of the registry, npm unless the audited lock file is of another
+	ecosystem string
+
+	// Read replicas of the registry, and the registry URL publishes are sent to once the nearest endpoint is
+	// selected for reads
+	readReplicas []string
+	publishURL   string
+
+	// Ranking of the registry endpoints reads fail over by, set once they are probed
+	failover *endpointFailover
+
+	redirectPolicy string
+	redirectHosts  []string
+
+	// Custom headers sent to the matching hosts, exact hosts after wildcards
+	headers []hostHeaders
+
+	// Proxy of registry requests, or nil for the environment proxy
+	proxyURL *url.URL
+
+	// DNS overrides of the dialed hosts, and the IP family connections are restricted to
+	resolve  map[string]string
+	ipFamily string
+
+	// TLS profile of registry connections, the CA certificates they trust besides the system ones, from the
+	// caCertFile, and whether certificates are verified at all
+	tlsProfile string
+	caCertFile string
+	rootCAs    *x509.CertPool
+	insecure   bool
+
+	// Retries of the checks failing with a transient error, and the timeout of each of their requests
+	retry          audit.RetryPolicy
+	requestTimeout time.Duration
+
+	// Limit of the registry requests per second, or nil
+	rateLimiter *rateLimiter
+
+	// Cassette the registry interactions are recorded to or replayed from, or nil
+	cassette *httpCassette
+	// Timing breakdown of --verbose, which times the registry requests, or nil
+	timings *timingBreakdown
+
+	// Round tripper shared by the registry requests, created on first use
+	transportOnce sync.Once
+	roundTripper  http.RoundTripper
+
+	// Client of the package checks, shared by the workers and created on first use
+	clientOnce  sync.Once
+	checkClient *http.Client
+
+	// Number of concurrent registry requests of the command, which sizes the idle connections kept per host.
+	// Zero for the commands serving checks as they come.
+	workers int
+
+	// Hosts lock files may pin tarballs to, set by the audit command
+	mirrorHosts []string
+
+	// Registries of the npm scopes an .npmrc routes elsewhere, set by the audit command
+	scopes map[string]npmScope
+
+	// Registry URLs of the other ecosystems of an audited SBOM, set by the audit command
+	ecosystemRegistries map[string]string
+
+	// Whether packages are checked with the curation audit API, set by the audit command
+	curationAPI bool
+}
+
+// auditRegistry returns the registry tarballs are checked against, with the redirect policy applied
+func (registry *registryConfiguration) auditRegistry() *audit.Registry {
+	return &audit.Registry{
+		URL:         registry.registryURL,
+		AccessToken: registry.accessToken,
+		User:        registry.user,
+		Password:    registry.password,
+		MirrorHosts: registry.mirrorHosts,
+		CurationAPI: registry.curationAPI,
+		Client:      registry.packageCheckClient(),
+		Retry:       registry.retry,
+		Ecosystem:   registry.ecosystem,
+		Scopes:      registry.scopeRegistries(),
+		Ecosystems:  registry.otherEcosystemRegistries(),
+	}
+}
+
+// scopeRegistries returns the registries of the npm scopes, which share the client of the package checks
+func (registry *registryConfiguration) scopeRegistries() map[string]*audit.Registry {
+	if len(registry.scopes) == 0 {
+		return nil
+	}
+	scopes := make(map[string]*audit.Registry, len(registry.scopes))
+	for scope, routed := range registry.scopes {
+		scopes[scope] = &audit.Registry{
+			URL:         routed.registryURL,
+			AccessToken: routed.credentials.AccessToken,
+			User:        routed.credentials.User,
+			Password:    routed.credentials.Password,
+			MirrorHosts: registry.mirrorHosts,
+			// Only Artifactory npm repositories have the curation audit API
+			CurationAPI: registry.curationAPI && strings.Contains(routed.registryURL, "/api/npm/"),
+			Client:      registry.packageCheckClient(),
+			Retry:       registry.retry,
+			Ecosystem:   registry.ecosystem,
+		}
+	}
+	return scopes
+}
+
+// credentials returns the credentials registry requests authenticate with
+func (registry *registryConfiguration) credentials() audit.Credentials {
+	return audit.Credentials{AccessToken: registry.accessToken, User: registry.user, Password: registry.password}
+}
+
+// resolveRegistryConfiguration reads the registry settings from the flags, falling back to the environment.
+// Without an explicit registry URL, it is constructed from the Artifactory URL and repository key. The Artifactory
+// URL, credentials and repository not set otherwise are those of the --server-id.
+func resolveRegistryConfiguration(c *components.Context) (*registryConfiguration, error) {
+	return resolveEcosystemRegistryConfiguration(c, ecosystemNpm)
+}
+
+// resolveEcosystemRegistryConfiguration is like resolveRegistryConfiguration, for a registry of the ecosystem
+func resolveEcosystemRegistryConfiguration(c *components.Context, ecosystem string) (*registryConfiguration, error) {
+	conf := &registryConfiguration{
+		registryURL: flagOrEnv(c, registryURLFlag, registryURLEnv),
+		accessToken: flagOrEnv(c, accessTokenFlag, accessTokenEnv),
+		ecosystem:   ecosystem,
+	}
+	conf.serverID = flagOrEnv(c, serverIDFlag, serverIDEnv)
+	server, err := loadServerDetails(conf.serverID)
+	if err != nil {
+		return nil, err
+	}
+	if conf.accessToken == "" {
+		credentials := serverCredentials(server)
+		conf.accessToken, conf.user, conf.password = credentials.AccessToken, credentials.User, credentials.Password
+	}
+	if conf.project, err = resolveProject(flagOrEnv(c, projectFlag, projectEnv), conf.accessToken); err != nil {
+		return nil, err
+	}
+	if conf.registryURL == "" {
+		artifactoryURL := flagOrEnv(c, artifactoryURLFlag, artifactoryURLEnv)
+		if artifactoryURL == "" {
+			artifactoryURL = serverArtifactoryURL(server)
+		}
+		repo := flagOrEnv(c, repoFlag, repoEnv)
+		if repo == "" && server != nil {
+			repo = resolverRepo(ecosystem, conf.serverID)
+		}
+		if artifactoryURL != "" || repo != "" {
+			registryURL, err := buildRegistryURL(ecosystem, artifactoryURL, repo)
+			if err != nil {
+				return nil, err
+			}
+			conf.registryURL = registryURL
+		}
+	}
+	// Equivalent endpoints listed after the registry URL are read from as its replicas
+	var endpoints []string
+	if urls := splitList(conf.registryURL); len(urls) > 1 {
+		conf.registryURL, endpoints = urls[0], urls[1:]
+	}
+	conf.registryURL = strings.TrimSuffix(conf.registryURL, "/")
+
+	var redirectPolicy string
+	redirectPolicy, err = parseRedirectPolicy(flagOrEnv(c, redirectsFlag, redirectsEnv))
+	if err != nil {
+		return nil, err
+	}
+	conf.redirectPolicy = redirectPolicy
+	conf.redirectHosts = parseRedirectHosts(flagOrEnv(c, redirectHostsFlag, redirectHostsEnv))
+	if conf.headers, err = loadRegistryHeaders(flagOrEnv(c, headersFileFlag, headersFileEnv)); err != nil {
+		return nil, err
+	}
+	if conf.proxyURL, err = parseProxyURL(flagOrEnv(c, proxyFlag, proxyEnv)); err != nil {
+		return nil, err
+	}
+	if conf.resolve, err = parseResolveOverrides(flagOrEnv(c, resolveFlag, resolveEnv)); err != nil {
+		return nil, err
+	}
+	if conf.ipFamily, err = parseIPFamily(flagOrEnv(c, ipFamilyFlag, ipFamilyEnv)); err != nil {
+		return nil, err
+	}
+	if conf.tlsProfile, err = parseTLSProfile(flagOrEnv(c, tlsProfileFlag, tlsProfileEnv)); err != nil {
+		return nil, err
+	}
+	conf.caCertFile = flagOrEnv(c, caCertFlag, caCertEnv)
+	if conf.rootCAs, err = loadCACertPool(conf.caCertFile); err != nil {
+		return nil, err
+	}
+	conf.insecure = getInsecure(c)
+	if conf.rateLimiter, err = parseRateLimit(flagOrEnv(c, rateLimitFlag, rateLimitEnv)); err != nil {
+		return nil, err
+	}
+	if conf.cassette, err = getCassette(c); err != nil {
+		return nil, err
+	}
+	conf.timings = getTimingBreakdown(c)
+	if conf.readReplicas, err = parseReadReplicas(strings.Join(append(endpoints, flagOrEnv(c, readReplicasFlag, readReplicasEnv)), ",")); err != nil {
+		return nil, err
+	}
+	conf.retry, err = parseRetryPolicy(flagOrEnv(c, retryAttemptsFlag, retryAttemptsEnv),
+		flagOrEnv(c, retryBaseDelayFlag, retryBaseDelayEnv), flagOrEnv(c, retryMaxDelayFlag, retryMaxDelayEnv))
+	if err != nil {
+		return nil, err
+	}
+	if conf.requestTimeout, err = parseTimeout(requestTimeoutFlag, flagOrEnv(c, requestTimeoutFlag, requestTimeoutEnv), defaultRequestTimeout); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// getRegistryConfiguration is like resolveRegistryConfiguration, but fails when no registry is configured
+func getRegistryConfiguration(c *components.Context) (*registryConfiguration, error) {
+	return getEcosystemRegistryConfiguration(c, ecosystemNpm)
+}
+
+func getEcosystemRegistryConfiguration(c *components.Context, ecosystem string) (*registryConfiguration, error) {
+	conf, err := resolveEcosystemRegistryConfiguration(c, ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	if conf.registryURL == "" {
+		return nil, fmt.Errorf("missing registry URL: use --%s, or --%s with --%s, or --%s, or set %s", registryURLFlag, artifactoryURLFlag, repoFlag, serverIDFlag, registryURLEnv)
+	}
+	if len(conf.readReplicas) > 0 {
+		conf.selectReadReplica()
+	}
+	return conf, nil
+}
+
+// flagOrEnv returns the flag value, falling back to the environment variable, the project config file and then
+// the active profile
+func flagOrEnv(c *components.Context, flagName, envName string) string {
+	if value := c.GetStringFlagValue(flagName); value != "" {
+		return value
+	}
+	if value := os.Getenv(envName); value != "" {
+		return value
+	}
+	return configSetting(flagName)
+}
+
+func getWorkers(c *components.Context) (int, error) {
+	value := flagOrConfig(c, workersFlag)
+	if value == "" {
+		return defaultWorkers, nil
+	}
+	workers, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("--%s must be a number, got '%s'", workersFlag, value)
+	}
+	if workers < 1 {
+		return 0, fmt.Errorf("--%s must be at least 1, got %d", workersFlag, workers)
+	}
+	return workers, nil
+}
+
+// splitList splits a comma separated flag value, dropping blank items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
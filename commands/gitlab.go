@@ -0,0 +1,262 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	gitlabNoteFlag        = "gitlab-note"
+	gitlabCodeQualityFlag = "gitlab-code-quality"
+
+	gitlabNoteEnv        = "CA_EXTENSION_GITLAB_NOTE"
+	gitlabCodeQualityEnv = "CA_EXTENSION_GITLAB_CODE_QUALITY"
+
+	// gitlabNoteMarker is the hidden first line of the note, which later pipelines of the merge request update instead
+	// of adding a note per pipeline
+	gitlabNoteMarker = "<!-- ca-extension curation audit -->"
+)
+
+// gitlabMergeRequest is the merge request of the current GitLab CI pipeline, and the API token acting on it
+type gitlabMergeRequest struct {
+	apiURL    string
+	projectID string
+	mrIID     string
+	token     string
+}
+
+// CodeQualityIssue represents an issue of a GitLab Code Quality report, a subset of the Code Climate format
+type CodeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    CodeQualityLocation `json:"location"`
+}
+
+type CodeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines CodeQualityLines `json:"lines"`
+}
+
+type CodeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+func getGitLabFlags() []components.Flag {
+	return []components.Flag{
+		components.NewBoolFlag(
+			gitlabNoteFlag,
+			"In a GitLab merge request pipeline, post a note summarizing the packages that wouldn't install, with GITLAB_TOKEN. Later pipelines update it",
+			components.WithBoolDefaultValue(false),
+		),
+		components.NewStringFlag(
+			gitlabCodeQualityFlag,
+			"Path to write a GitLab Code Quality report of the packages that wouldn't install to, for the codequality artifact of the job",
+			components.WithHelpValue("path"),
+		),
+	}
+}
+
+// getGitLabNote returns the merge request to post the note on, when --gitlab-note or its environment variable or
+// config setting is set, or nil. Outside merge request pipelines, or without GITLAB_TOKEN, no note is posted.
+func getGitLabNote(c *components.Context) *gitlabMergeRequest {
+	enabled := c.GetBoolFlagValue(gitlabNoteFlag)
+	if !enabled {
+		value := os.Getenv(gitlabNoteEnv)
+		if value == "" {
+			value = configSetting(gitlabNoteFlag)
+		}
+		enabled, _ = strconv.ParseBool(value)
+	}
+	if !enabled {
+		return nil
+	}
+	mr := detectGitLabMergeRequest()
+	if mr == nil {
+		log.Warn(fmt.Sprintf("--%s: not posting a note outside a GitLab merge request pipeline with GITLAB_TOKEN set", gitlabNoteFlag))
+	}
+	return mr
+}
+
+// detectGitLabMergeRequest returns the merge request of the current pipeline, if the run is a merge request pipeline
+// on GitLab CI with GITLAB_TOKEN set
+func detectGitLabMergeRequest() *gitlabMergeRequest {
+	if os.Getenv("GITLAB_CI") != "true" {
+		return nil
+	}
+	mr := &gitlabMergeRequest{
+		apiURL:    os.Getenv("CI_API_V4_URL"),
+		projectID: os.Getenv("CI_PROJECT_ID"),
+		mrIID:     os.Getenv("CI_MERGE_REQUEST_IID"),
+		token:     os.Getenv("GITLAB_TOKEN"),
+	}
+	if mr.token == "" || mr.apiURL == "" || mr.projectID == "" || mr.mrIID == "" {
+		return nil
+	}
+	return mr
+}
+
+// postNote posts the markdown report as a note of the merge request, or updates the note of an earlier pipeline
+func (mr *gitlabMergeRequest) postNote(report *AuditReport, display *displayFormat) error {
+	notesURL := fmt.Sprintf("%s/projects/%s/merge_requests/%s/notes", mr.apiURL, url.PathEscape(mr.projectID), mr.mrIID)
+	body, err := json.Marshal(map[string]string{"body": gitlabNoteMarker + "\n" + renderMarkdown(report, display)})
+	if err != nil {
+		return err
+	}
+	noteID, err := mr.findNote(notesURL)
+	if err != nil {
+		return fmt.Errorf("error listing the merge request notes: %v", err)
+	}
+	if noteID != 0 {
+		if _, err := mr.send(http.MethodPut, fmt.Sprintf("%s/%d", notesURL, noteID), body); err != nil {
+			return fmt.Errorf("error updating the merge request note: %v", err)
+		}
+		return nil
+	}
+	if _, err := mr.send(http.MethodPost, notesURL, body); err != nil {
+		return fmt.Errorf("error posting the merge request note: %v", err)
+	}
+	return nil
+}
+
+// findNote returns the ID of the note an earlier pipeline posted, or 0
+func (mr *gitlabMergeRequest) findNote(notesURL string) (int, error) {
+	for page := 1; ; page++ {
+		data, err := mr.send(http.MethodGet, fmt.Sprintf("%s?per_page=100&page=%d", notesURL, page), nil)
+		if err != nil {
+			return 0, err
+		}
+		var notes []struct {
+			ID   int    `json:"id"`
+			Body string `json:"body"`
+		}
+		if err := json.Unmarshal(data, &notes); err != nil {
+			return 0, fmt.Errorf("error parsing notes: %v", err)
+		}
+		for _, note := range notes {
+			if strings.HasPrefix(note.Body, gitlabNoteMarker) {
+				return note.ID, nil
+			}
+		}
+		if len(notes) < 100 {
+			return 0, nil
+		}
+	}
+}
+
+func (mr *gitlabMergeRequest) send(method, requestURL string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", mr.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected response: %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// newCodeQualityReport converts the packages that wouldn't install into Code Quality issues on the lock file, checked
+// as the rules of the SARIF report. The findings accepted by the ignore rules or the baseline are minor, unless
+// escalated.
+func newCodeQualityReport(report *AuditReport) []CodeQualityIssue {
+	lines := lockFilePackageLines(report.LockFile)
+	path := codeQualityPath(report.LockFile)
+	issues := []CodeQualityIssue{}
+	for _, entry := range report.Results {
+		if entry.StatusCode == http.StatusOK && entry.Error == "" {
+			continue
+		}
+		rule := sarifRuleFor(entry)
+		severity := "critical"
+		switch {
+		case rule.DefaultLevel.Level != "error":
+			severity = "info"
+		case (entry.Acknowledged != "" || entry.Baseline) && !entry.Escalated:
+			severity = "minor"
+		case entry.StatusCode == http.StatusNotFound:
+			severity = "major"
+		}
+		description := fmt.Sprintf("%s@%s: %s", entry.Name, entry.Version, entry.Status)
+		if entry.Error != "" {
+			description += " - Error: " + entry.Error
+		}
+		line := lines[entry.Name+"@"+entry.Version]
+		if line == 0 {
+			line = 1
+		}
+		fingerprint := sha256.Sum256([]byte(rule.ID + " " + entry.Name + "@" + entry.Version))
+		issues = append(issues, CodeQualityIssue{
+			Description: description,
+			CheckName:   rule.ID,
+			Fingerprint: hex.EncodeToString(fingerprint[:]),
+			Severity:    severity,
+			Location:    CodeQualityLocation{Path: path, Lines: CodeQualityLines{Begin: line}},
+		})
+	}
+	return issues
+}
+
+// codeQualityPath returns the path of the lock file relative to the root of the repository, CI_PROJECT_DIR in GitLab
+// CI jobs or else the top level of its git work tree, which GitLab needs to show the issues in the merge request diff.
+// Lock files outside the repository keep the path they were given.
+func codeQualityPath(lockFile string) string {
+	abs, err := filepath.Abs(lockFile)
+	if err != nil {
+		return filepath.ToSlash(lockFile)
+	}
+	root := os.Getenv("CI_PROJECT_DIR")
+	if root == "" {
+		root = gitOutput(filepath.Dir(abs), "rev-parse", "--show-toplevel")
+	}
+	if root == "" {
+		return filepath.ToSlash(lockFile)
+	}
+	// git reports the top level with the symlinks resolved, e.g. /private/var rather than /var on macOS
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(lockFile)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func writeCodeQualityReport(report *AuditReport, path string) error {
+	jsonData, err := json.MarshalIndent(newCodeQualityReport(report), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	if err := os.WriteFile(path, append(jsonData, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing Code Quality report: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectGitLabMergeRequest(t *testing.T) {
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_API_V4_URL", "https://gitlab.example.com/api/v4")
+	t.Setenv("CI_PROJECT_ID", "42")
+	t.Setenv("CI_MERGE_REQUEST_IID", "7")
+	t.Setenv("GITLAB_TOKEN", "")
+	assert.Nil(t, detectGitLabMergeRequest())
+
+	t.Setenv("GITLAB_TOKEN", "glpat-token")
+	assert.Equal(t, &gitlabMergeRequest{apiURL: "https://gitlab.example.com/api/v4", projectID: "42", mrIID: "7", token: "glpat-token"}, detectGitLabMergeRequest())
+
+	// Branch pipelines have no merge request to post on
+	t.Setenv("CI_MERGE_REQUEST_IID", "")
+	assert.Nil(t, detectGitLabMergeRequest())
+}
+
+func TestGitLabNote(t *testing.T) {
+	var posted, updated []string
+	existing := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "glpat-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var note struct {
+			Body string `json:"body"`
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == "/projects/group%2Fapp/merge_requests/7/notes":
+			notes := []map[string]any{{"id": 1, "body": "LGTM"}}
+			if existing != "" {
+				notes = append(notes, map[string]any{"id": 2, "body": existing})
+			}
+			json.NewEncoder(w).Encode(notes)
+		case r.Method == http.MethodPost && r.URL.EscapedPath() == "/projects/group%2Fapp/merge_requests/7/notes":
+			data, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(data, &note))
+			posted = append(posted, note.Body)
+			existing = note.Body
+		case r.Method == http.MethodPut && r.URL.EscapedPath() == "/projects/group%2Fapp/merge_requests/7/notes/2":
+			data, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(data, &note))
+			updated = append(updated, note.Body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	report := newAuditReport("pnpm-lock.yaml", []audit.AuditResult{
+		{Name: "lodash", Version: "4.17.20", Status: "❌ Blocked by Curation Policy: Block Malicious", StatusCode: http.StatusForbidden},
+	})
+	mr := &gitlabMergeRequest{apiURL: server.URL, projectID: "group/app", mrIID: "7", token: "glpat-token"}
+	require.NoError(t, mr.postNote(report, newDisplayFormat(true)))
+	require.Len(t, posted, 1)
+	assert.True(t, strings.HasPrefix(posted[0], gitlabNoteMarker+"\n### Curation audit of `pnpm-lock.yaml`"))
+
+	// The pipelines after the first update its note
+	require.NoError(t, mr.postNote(report, newDisplayFormat(true)))
+	assert.Len(t, posted, 1)
+	assert.Equal(t, posted, updated)
+
+	mr.token = "revoked"
+	assert.EqualError(t, mr.postNote(report, newDisplayFormat(true)), "error listing the merge request notes: unexpected response: 401")
+}
+
+func TestCodeQualityPath(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Setenv("CI_PROJECT_DIR", projectDir)
+	assert.Equal(t, "apps/web/pnpm-lock.yaml", codeQualityPath(filepath.Join(projectDir, "apps", "web", "pnpm-lock.yaml")))
+	// Lock files outside the repository keep their path
+	outside := filepath.Join(t.TempDir(), "pnpm-lock.yaml")
+	assert.Equal(t, filepath.ToSlash(outside), codeQualityPath(outside))
+
+	// Outside GitLab CI, relative to the top level of the git work tree
+	t.Setenv("CI_PROJECT_DIR", "")
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", repo).Run())
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "apps", "web"), 0755))
+	assert.Equal(t, "apps/web/pnpm-lock.yaml", codeQualityPath(filepath.Join(repo, "apps", "web", "pnpm-lock.yaml")))
+}
+
+func TestCodeQualityReport(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Setenv("CI_PROJECT_DIR", projectDir)
+	lockFile := filepath.Join(projectDir, "pnpm-lock.yaml")
+	require.NoError(t, os.WriteFile(lockFile, []byte("lockfileVersion: '9.0'\n\npackages:\n\n  left-pad@1.3.0:\n    resolution: {}\n\n  lodash@4.17.20:\n    resolution: {}\n"), 0644))
+	report := newAuditReport(lockFile, []audit.AuditResult{
+		{Name: "abbrev", Version: "1.1.1", Status: "✅ Available in NPM Registry", StatusCode: http.StatusOK},
+		{Name: "lodash", Version: "4.17.20", Status: "❌ Blocked by Curation Policy: Block Malicious", StatusCode: http.StatusForbidden,
+			Policies: []audit.CurationPolicy{{Policy: "Block Malicious"}}},
+		{Name: "left-pad", Version: "1.3.0", Status: "❌ Not Found (404)", StatusCode: http.StatusNotFound},
+		{Name: "chalk", Version: "5.3.0", Status: "❌ Not Found (404)", StatusCode: http.StatusNotFound},
+	})
+	report.Results[3].Acknowledged = "chalk: mirrored next week"
+
+	issues := newCodeQualityReport(report)
+	require.Len(t, issues, 3)
+	assert.Equal(t, "lodash@4.17.20: ❌ Blocked by Curation Policy: Block Malicious", issues[0].Description)
+	assert.Equal(t, "curation/policy/block-malicious", issues[0].CheckName)
+	assert.Equal(t, "critical", issues[0].Severity)
+	// The absolute path of the lock file is made relative to the project directory
+	assert.Equal(t, CodeQualityLocation{Path: "pnpm-lock.yaml", Lines: CodeQualityLines{Begin: 8}}, issues[0].Location)
+	assert.Equal(t, "major", issues[1].Severity)
+	assert.Equal(t, 5, issues[1].Location.Lines.Begin)
+	assert.Equal(t, "minor", issues[2].Severity)
+	assert.Equal(t, 1, issues[2].Location.Lines.Begin)
+	assert.NotEqual(t, issues[1].Fingerprint, issues[2].Fingerprint)
+
+	report.Results[3].Escalated = true
+	assert.Equal(t, "major", newCodeQualityReport(report)[2].Severity)
+
+	path := filepath.Join(t.TempDir(), "gl-code-quality-report.json")
+	require.NoError(t, writeCodeQualityReport(report, path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var written []CodeQualityIssue
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.Len(t, written, 3)
+}
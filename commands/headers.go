@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	headersFileFlag = "headers-file"
+
+	headersFileEnv = "CA_EXTENSION_HEADERS_FILE"
+)
+
+// Matches the token characters HTTP allows in header names
+var headerNamePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// hostHeaders represents the custom headers sent to the registry hosts matching a host pattern
+type hostHeaders struct {
+	host   string
+	header http.Header
+}
+
+func getHeadersFileFlag() components.Flag {
+	return components.NewStringFlag(
+		headersFileFlag,
+		"Path of a YAML file mapping registry hosts to the custom headers sent to them, e.g. for API gateways. *.example.com matches subdomains",
+		components.WithHelpValue("path"),
+	)
+}
+
+// loadRegistryHeaders reads a headers file such as:
+//
+//	acme.jfrog.io:
+//	  X-JFrog-Art-Api: ${ARTIFACTORY_API_KEY}
+//	"*.gateway.acme.io":
+//	  X-Tenant-Id: web
+//
+// Values expand environment variables, so secrets can stay out of the file. Patterns with a wildcard come first,
+// so the headers of an exact host override theirs.
+func loadRegistryHeaders(path string) ([]hostHeaders, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading headers file: %v", err)
+	}
+	var hosts map[string]map[string]string
+	if err := yaml.Unmarshal(data, &hosts); err != nil {
+		return nil, fmt.Errorf("error parsing headers file %s: %v", path, err)
+	}
+
+	var headers []hostHeaders
+	for host, values := range hosts {
+		header := make(http.Header, len(values))
+		for name, value := range values {
+			if !headerNamePattern.MatchString(name) {
+				return nil, fmt.Errorf("invalid header name '%s' for %s in %s", name, host, path)
+			}
+			header.Set(name, os.ExpandEnv(value))
+		}
+		headers = append(headers, hostHeaders{host: strings.ToLower(host), header: header})
+	}
+	sort.Slice(headers, func(i, j int) bool {
+		iWildcard, jWildcard := strings.HasPrefix(headers[i].host, "*."), strings.HasPrefix(headers[j].host, "*.")
+		if iWildcard != jWildcard {
+			return iWildcard
+		}
+		return headers[i].host < headers[j].host
+	})
+	return headers, nil
+}
+
+// headerTransport adds the custom headers of the request host
+type headerTransport struct {
+	base    http.RoundTripper
+	headers []hostHeaders
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.ToLower(req.URL.Hostname())
+	cloned := false
+	for _, rule := range t.headers {
+		if !audit.MatchesHost(host, []string{rule.host}) {
+			continue
+		}
+		// A RoundTripper must not modify the request it was given
+		if !cloned {
+			req = req.Clone(req.Context())
+			cloned = true
+		}
+		for name, values := range rule.header {
+			req.Header[name] = values
+		}
+	}
+	return t.base.RoundTrip(req)
+}
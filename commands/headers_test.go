@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHeadersFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "headers.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadRegistryHeaders(t *testing.T) {
+	t.Setenv("ARTIFACTORY_API_KEY", "AKCp8secret")
+	path := writeHeadersFile(t, `
+Acme.JFrog.io:
+  X-JFrog-Art-Api: ${ARTIFACTORY_API_KEY}
+"*.jfrog.io":
+  X-Tenant-Id: web
+`)
+
+	headers, err := loadRegistryHeaders(path)
+	require.NoError(t, err)
+	require.Len(t, headers, 2)
+	assert.Equal(t, "*.jfrog.io", headers[0].host)
+	assert.Equal(t, "web", headers[0].header.Get("X-Tenant-Id"))
+	assert.Equal(t, "acme.jfrog.io", headers[1].host)
+	assert.Equal(t, "AKCp8secret", headers[1].header.Get("X-JFrog-Art-Api"))
+
+	headers, err = loadRegistryHeaders("")
+	assert.NoError(t, err)
+	assert.Empty(t, headers)
+
+	_, err = loadRegistryHeaders(writeHeadersFile(t, "acme.jfrog.io:\n  \"X Tenant\": web\n"))
+	assert.ErrorContains(t, err, "invalid header name 'X Tenant'")
+	_, err = loadRegistryHeaders(writeHeadersFile(t, "- acme.jfrog.io\n"))
+	assert.ErrorContains(t, err, "error parsing headers file")
+}
+
+func TestHeaderTransport(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	registry := &registryConfiguration{headers: []hostHeaders{
+		{host: "*.example.com", header: http.Header{"X-Tenant-Id": {"other"}}},
+		{host: "127.0.0.1", header: http.Header{"X-Tenant-Id": {"web"}, "X-Jfrog-Art-Api": {"key"}}},
+	}}
+	req, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+	resp, err := registry.httpClient(0).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "web", received.Get("X-Tenant-Id"))
+	assert.Equal(t, "key", received.Get("X-JFrog-Art-Api"))
+	// The request of the caller is left untouched
+	assert.Empty(t, req.Header)
+
+	registry = &registryConfiguration{headers: registry.headers[:1]}
+	resp, err = registry.httpClient(0).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, received.Get("X-Tenant-Id"))
+}
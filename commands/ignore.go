@@ -0,0 +1,218 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-cli-plugin-template/internal/semver"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ignoreFileFlag = "ignore-file"
+
+	ignoreFileEnv = "CA_EXTENSION_IGNORE_FILE"
+
+	// ignoreSkip packages aren't audited at all
+	ignoreSkip = "skip"
+	// ignoreWarn packages are audited, but their findings are warnings that don't fail the run
+	ignoreWarn = "warn"
+
+	ignoreSinceLayout = "2006-01-02"
+)
+
+// ignoreRule acknowledges the packages matching a <name>@<version> pattern
+type ignoreRule struct {
+	// Package is a name glob, e.g. @babel/*, optionally followed by @ and a version glob or semver range
+	Package string `yaml:"package"`
+	// Action is skip or warn, the default
+	Action string `yaml:"action"`
+	// Reason is printed with the acknowledged findings, e.g. the ticket approving the exception
+	Reason string `yaml:"reason"`
+	// Since is the YYYY-MM-DD date the exception was granted, from which --escalate-after ages its findings
+	Since string `yaml:"since"`
+}
+
+// ignoreList is the set of rules of the ignore file and the project config file. A nil list ignores nothing.
+type ignoreList struct {
+	rules []ignoreRule
+}
+
+func getIgnoreFileFlag() components.Flag {
+	return components.NewStringFlag(
+		ignoreFileFlag,
+		"Path of a YAML file of known-accepted packages, as <name>@<version> patterns, to skip or to report as warnings that don't fail the run",
+		components.WithHelpValue("path"),
+	)
+}
+
+// getIgnoreList reads the rules of the ignore file, if any, after those of the ignore section of the project config
+// file
+func getIgnoreList(c *components.Context) (*ignoreList, error) {
+	var rules []ignoreRule
+	if config := activeProjectConfig(); config != nil {
+		rules = append(rules, config.Ignore...)
+	}
+	if path := flagOrEnv(c, ignoreFileFlag, ignoreFileEnv); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading ignore file: %v", err)
+		}
+		var file struct {
+			Ignore []ignoreRule `yaml:"ignore"`
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("error parsing ignore file %s: %v", path, err)
+		}
+		rules = append(rules, file.Ignore...)
+	}
+	return newIgnoreList(rules)
+}
+
+func newIgnoreList(rules []ignoreRule) (*ignoreList, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	for i, rule := range rules {
+		if rule.Package == "" {
+			return nil, fmt.Errorf("ignore rule %d has no package pattern", i+1)
+		}
+		if _, err := path.Match(ignoreNamePattern(rule.Package), ""); err != nil {
+			return nil, fmt.Errorf("invalid package pattern '%s' of the ignore rules: %v", rule.Package, err)
+		}
+		switch rule.Action {
+		case "":
+			rules[i].Action = ignoreWarn
+		case ignoreSkip, ignoreWarn:
+		default:
+			return nil, fmt.Errorf("unsupported action '%s' of the ignore rule of %s. Expected %s or %s", rule.Action, rule.Package, ignoreSkip, ignoreWarn)
+		}
+		if rule.Since != "" {
+			if _, err := time.Parse(ignoreSinceLayout, rule.Since); err != nil {
+				return nil, fmt.Errorf("invalid since date '%s' of the ignore rule of %s. Expected YYYY-MM-DD", rule.Since, rule.Package)
+			}
+		}
+	}
+	return &ignoreList{rules: rules}, nil
+}
+
+// ignoreNamePattern returns the name glob of a package pattern
+func ignoreNamePattern(pattern string) string {
+	if separator := strings.LastIndex(pattern, "@"); separator > 0 {
+		return pattern[:separator]
+	}
+	return pattern
+}
+
+// matches reports whether a package version matches the pattern of the rule. Versions match a glob, such as 4.17.*,
+// or a semver range, such as ^7.0.0. Versions that aren't semver, of other ecosystems, only match globs.
+func (rule ignoreRule) matches(name, version string) bool {
+	namePattern, versionPattern := rule.Package, ""
+	if separator := strings.LastIndex(rule.Package, "@"); separator > 0 {
+		namePattern, versionPattern = rule.Package[:separator], rule.Package[separator+1:]
+	}
+	if matched, _ := path.Match(namePattern, name); !matched {
+		return false
+	}
+	if versionPattern == "" || versionPattern == "*" {
+		return true
+	}
+	if matched, err := path.Match(versionPattern, version); err == nil && matched {
+		return true
+	}
+	satisfied, err := semver.Satisfies(version, versionPattern)
+	return err == nil && satisfied
+}
+
+// find returns the first rule matching a package version, or nil
+func (l *ignoreList) find(name, version string) *ignoreRule {
+	if l == nil {
+		return nil
+	}
+	for i := range l.rules {
+		if l.rules[i].matches(name, version) {
+			return &l.rules[i]
+		}
+	}
+	return nil
+}
+
+// skip leaves out the dependencies of the skip rules
+func (l *ignoreList) skip(deps []audit.Dependency) []audit.Dependency {
+	if l == nil {
+		return deps
+	}
+	var kept []audit.Dependency
+	for _, dep := range deps {
+		if rule := l.find(dep.Name, dep.Version); rule == nil || rule.Action != ignoreSkip {
+			kept = append(kept, dep)
+		}
+	}
+	if skipped := len(deps) - len(kept); skipped > 0 {
+		log.Info(fmt.Sprintf("Skipping %d dependencies of the ignore rules", skipped))
+	}
+	return kept
+}
+
+// acknowledged returns the rule downgrading the findings of a result to a warning, or nil
+func (l *ignoreList) acknowledged(result audit.AuditResult) *ignoreRule {
+	if !isBlocking(result) {
+		return nil
+	}
+	if rule := l.find(result.Name, result.Version); rule != nil && rule.Action == ignoreWarn {
+		return rule
+	}
+	return nil
+}
+
+// since returns the date the rule acknowledging the findings of a result was granted, if it has one
+func (l *ignoreList) since(result audit.AuditResult) (time.Time, bool) {
+	rule := l.acknowledged(result)
+	if rule == nil || rule.Since == "" {
+		return time.Time{}, false
+	}
+	since, err := time.Parse(ignoreSinceLayout, rule.Since)
+	return since, err == nil
+}
+
+// warn logs the acknowledged findings as warnings
+func (l *ignoreList) warn(results []audit.AuditResult) {
+	for _, result := range results {
+		if rule := l.acknowledged(result); rule != nil {
+			log.Warn(fmt.Sprintf("%s@%s: %s, acknowledged by the ignore rule of %s%s", result.Name, result.Version, result.Status, rule.Package, describeReason(rule.Reason)))
+		}
+	}
+}
+
+// annotate records the reason the findings of each acknowledged result are warnings in the report
+func (l *ignoreList) annotate(report *AuditReport, results []audit.AuditResult) {
+	if l == nil {
+		return
+	}
+	for i, result := range results {
+		if rule := l.acknowledged(result); rule != nil && i < len(report.Results) {
+			report.Results[i].Acknowledged = rule.acknowledgement()
+		}
+	}
+}
+
+// acknowledgement describes the rule in the results it acknowledges: its package, and its reason if it has one
+func (r *ignoreRule) acknowledgement() string {
+	if r.Reason == "" {
+		return r.Package
+	}
+	return r.Package + ": " + r.Reason
+}
+
+func describeReason(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return " (" + reason + ")"
+}
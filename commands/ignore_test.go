@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnoreRuleMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		version string
+		matches bool
+	}{
+		{"lodash", "lodash", "4.17.20", true},
+		{"lodash@4.17.20", "lodash", "4.17.20", true},
+		{"lodash@4.17.20", "lodash", "4.17.21", false},
+		{"lodash@4.17.*", "lodash", "4.17.21", true},
+		{"@babel/*", "@babel/core", "7.24.0", true},
+		{"@babel/*@^7.20.0", "@babel/core", "7.24.0", true},
+		{"@babel/*@^7.20.0", "@babel/core", "8.0.0", false},
+		{"@babel/*", "babel-loader", "9.1.0", false},
+		{"org.apache.commons:*@2.*", "org.apache.commons:commons-text", "2.1", true},
+		{"golang.org/x/*@v0.17.*", "golang.org/x/net", "v0.18.0", false},
+		{"golang.org/x/net@v0.17.*", "golang.org/x/net", "v0.17.0", true},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.matches, ignoreRule{Package: test.pattern}.matches(test.name, test.version), test.pattern)
+	}
+}
+
+func TestNewIgnoreList(t *testing.T) {
+	list, err := newIgnoreList(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, list)
+
+	list, err = newIgnoreList([]ignoreRule{{Package: "lodash@4.17.20"}, {Package: "left-pad", Action: ignoreSkip}})
+	assert.NoError(t, err)
+	assert.Equal(t, ignoreWarn, list.rules[0].Action)
+
+	_, err = newIgnoreList([]ignoreRule{{Package: "lodash", Action: "allow"}})
+	assert.Error(t, err)
+	_, err = newIgnoreList([]ignoreRule{{Action: ignoreSkip}})
+	assert.Error(t, err)
+	_, err = newIgnoreList([]ignoreRule{{Package: "[lodash"}})
+	assert.Error(t, err)
+}
+
+func TestIgnoreList(t *testing.T) {
+	list, err := newIgnoreList([]ignoreRule{
+		{Package: "lodash@4.17.*", Reason: "SEC-1234"},
+		{Package: "left-pad", Action: ignoreSkip},
+	})
+	assert.NoError(t, err)
+
+	deps := list.skip([]audit.Dependency{{Name: "lodash", Version: "4.17.20"}, {Name: "left-pad", Version: "1.3.0"}, {Name: "react", Version: "18.2.0"}})
+	assert.Equal(t, []audit.Dependency{{Name: "lodash", Version: "4.17.20"}, {Name: "react", Version: "18.2.0"}}, deps)
+
+	results := []audit.AuditResult{
+		{Name: "lodash", Version: "4.17.20", StatusCode: http.StatusForbidden},
+		{Name: "react", Version: "18.2.0", StatusCode: http.StatusForbidden},
+	}
+	report := newAuditReport("pnpm-lock.yaml", results)
+	list.annotate(report, results)
+	assert.Equal(t, "lodash@4.17.*: SEC-1234", report.Results[0].Acknowledged)
+	assert.Equal(t, "", report.Results[1].Acknowledged)
+
+	policy, err := parseFailurePolicy(failOnBlocked, "")
+	assert.NoError(t, err)
+	policy.ignore = list
+	assert.EqualError(t, policy.evaluate(results), "audit failed: 1 of 2 packages are blocked by curation (--fail-on=blocked)")
+	assert.NoError(t, policy.evaluate(results[:1]))
+
+	var none *ignoreList
+	assert.Nil(t, none.acknowledged(results[0]))
+	assert.Equal(t, deps, none.skip(deps))
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	indexFlag = "index"
+
+	indexStatusApproved = "approved"
+	indexStatusBlocked  = "blocked"
+	indexStatusNotFound = "not-found"
+	indexStatusPending  = "pending"
+	indexStatusUnknown  = "unknown"
+)
+
+// PackageIndex represents the compact name -> version -> status index consumed by editor plugins and completions
+type PackageIndex struct {
+	Metadata    *RunMetadata                 `json:"metadata,omitempty"`
+	Registry    string                       `json:"registry"`
+	GeneratedAt time.Time                    `json:"generatedAt"`
+	Packages    map[string]map[string]string `json:"packages"`
+}
+
+func newPackageIndex(registryURL string, results []audit.AuditResult) *PackageIndex {
+	index := &PackageIndex{
+		Registry:    registryURL,
+		GeneratedAt: time.Now().UTC(),
+		Packages:    make(map[string]map[string]string),
+	}
+	for _, result := range results {
+		versions, exists := index.Packages[result.Name]
+		if !exists {
+			versions = make(map[string]string)
+			index.Packages[result.Name] = versions
+		}
+		versions[result.Version] = indexStatus(result)
+	}
+	return index
+}
+
+func indexStatus(result audit.AuditResult) string {
+	if result.Error == nil && result.Pending {
+		return indexStatusPending
+	}
+	return indexStatusOf(result.Error != nil, result.StatusCode)
+}
+
+// indexStatusOf returns the index status of a check, from whether it failed and the status code of the registry
+func indexStatusOf(failed bool, statusCode int) string {
+	if failed {
+		return indexStatusUnknown
+	}
+	switch statusCode {
+	case http.StatusOK:
+		return indexStatusApproved
+	case http.StatusForbidden:
+		return indexStatusBlocked
+	case http.StatusNotFound:
+		return indexStatusNotFound
+	default:
+		return indexStatusUnknown
+	}
+}
+
+// writePackageIndex writes the index without indentation, it is meant to be read by tools rather than people
+func writePackageIndex(index *PackageIndex, outputPath string) error {
+	jsonData, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	if err := ioutil.WriteFile(outputPath, append(jsonData, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing package index: %v", err)
+	}
+	return nil
+}
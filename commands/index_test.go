@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageIndex(t *testing.T) {
+	results := []audit.AuditResult{
+		{Name: "abbrev", Version: "1.1.1", StatusCode: http.StatusOK},
+		{Name: "abbrev", Version: "2.0.0", StatusCode: http.StatusForbidden},
+		{Name: "left-pad", Version: "1.3.0", StatusCode: http.StatusNotFound},
+		{Name: "semver", Version: "7.6.0", Error: errors.New("timeout")},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "index.json")
+	assert.NoError(t, writePackageIndex(newPackageIndex("http://registry", results), outputPath))
+
+	data, err := os.ReadFile(outputPath)
+	assert.NoError(t, err)
+	var index PackageIndex
+	assert.NoError(t, json.Unmarshal(data, &index))
+	assert.Equal(t, "http://registry", index.Registry)
+	assert.Equal(t, map[string]map[string]string{
+		"abbrev":   {"1.1.1": indexStatusApproved, "2.0.0": indexStatusBlocked},
+		"left-pad": {"1.3.0": indexStatusNotFound},
+		"semver":   {"7.6.0": indexStatusUnknown},
+	}, index.Packages)
+}
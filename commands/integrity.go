@@ -0,0 +1,256 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	// integrityEnv selects whether the commands verify the running binary against its release manifest first: off
+	// (the default), warn, or enforce to refuse to run a binary that doesn't match
+	integrityEnv         = "CA_EXTENSION_INTEGRITY"
+	integrityManifestEnv = "CA_EXTENSION_INTEGRITY_MANIFEST"
+	integrityKeyEnv      = "CA_EXTENSION_INTEGRITY_KEY"
+
+	integrityOff     = "off"
+	integrityWarn    = "warn"
+	integrityEnforce = "enforce"
+
+	// The manifest of the release builds, and the suffix of its cosign sign-blob signature
+	integrityManifestName    = "SHA256SUMS"
+	integritySignatureSuffix = ".sig"
+
+	integrityFetchTimeout = 30 * time.Second
+)
+
+// integrityCheck verifies the running binary against the SHA256SUMS manifest of the release builds, and the manifest
+// against its cosign signature when a public key is configured
+type integrityCheck struct {
+	mode string
+	// Path or URL of the manifest, and path of the cosign public key, if any
+	manifest  string
+	publicKey string
+	// Path of the running binary, and the names it may be listed under in the manifest
+	executable string
+	names      []string
+}
+
+// withIntegrityChecks makes the commands verify the running binary first, as CA_EXTENSION_INTEGRITY selects
+func withIntegrityChecks(commands []components.Command) []components.Command {
+	wrapped := make([]components.Command, len(commands))
+	for i, command := range commands {
+		action := command.Action
+		command.Action = func(c *components.Context) error {
+			if err := runIntegrityCheck(); err != nil {
+				return err
+			}
+			return action(c)
+		}
+		wrapped[i] = command
+	}
+	return wrapped
+}
+
+// runIntegrityCheck verifies the running binary, failing in the enforce mode and logging a warning otherwise
+func runIntegrityCheck() error {
+	check, err := getIntegrityCheck()
+	if err != nil || check == nil {
+		return err
+	}
+	if err := check.verify(); err != nil {
+		if check.mode == integrityWarn {
+			log.Warn(fmt.Sprintf("The integrity check of %s failed: %v", check.executable, err))
+			return nil
+		}
+		return fmt.Errorf("refusing to run: the integrity check of %s failed (%s=%s): %v", check.executable, integrityEnv, check.mode, err)
+	}
+	log.Debug(fmt.Sprintf("Verified %s against %s", check.executable, check.manifest))
+	return nil
+}
+
+// getIntegrityCheck returns the check of the environment, or nil when it is off
+func getIntegrityCheck() (*integrityCheck, error) {
+	mode := strings.ToLower(os.Getenv(integrityEnv))
+	switch mode {
+	case "", integrityOff:
+		return nil, nil
+	case integrityWarn, integrityEnforce:
+	default:
+		return nil, fmt.Errorf("unsupported %s '%s'. Expected %s, %s or %s", integrityEnv, mode, integrityOff, integrityWarn, integrityEnforce)
+	}
+	executable, err := os.Executable()
+	if err == nil {
+		executable, err = filepath.EvalSymlinks(executable)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error locating the running binary: %v", err)
+	}
+	check := &integrityCheck{
+		mode:       mode,
+		manifest:   os.Getenv(integrityManifestEnv),
+		publicKey:  os.Getenv(integrityKeyEnv),
+		executable: executable,
+		names:      []string{filepath.Base(executable), releaseBinaryName(runtime.GOOS, runtime.GOARCH)},
+	}
+	if check.manifest == "" {
+		check.manifest = filepath.Join(filepath.Dir(executable), integrityManifestName)
+	}
+	if mode == integrityEnforce {
+		if err := check.validateEnforce(); err != nil {
+			return nil, err
+		}
+	}
+	return check, nil
+}
+
+// validateEnforce rejects the settings that would let the enforce mode pass on a tampered manifest: the warn mode
+// accepts them, as it doesn't block anything
+func (check *integrityCheck) validateEnforce() error {
+	if strings.HasPrefix(check.manifest, "http://") {
+		return fmt.Errorf("%s=%s refuses to fetch the manifest over plain HTTP: %s. Set %s to an https URL or a local path",
+			integrityEnv, integrityEnforce, stripURLCredentials(check.manifest), integrityManifestEnv)
+	}
+	if check.publicKey == "" {
+		return fmt.Errorf("%s=%s requires %s, the path of the cosign public key to verify the signature of the manifest with",
+			integrityEnv, integrityEnforce, integrityKeyEnv)
+	}
+	return nil
+}
+
+// releaseBinaryName returns the name of the release binary of a target, as cmd/release builds it
+func releaseBinaryName(goos, goarch string) string {
+	name := fmt.Sprintf("ca-extension-%s-%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// verify checks the signature of the manifest, if a public key is configured, then the checksum of the binary
+func (check *integrityCheck) verify() error {
+	manifest, err := readIntegrityFile(check.manifest)
+	if err != nil {
+		return fmt.Errorf("error reading the manifest: %v", err)
+	}
+	if check.publicKey != "" {
+		key, err := os.ReadFile(check.publicKey)
+		if err != nil {
+			return fmt.Errorf("error reading the public key: %v", err)
+		}
+		signature, err := readIntegrityFile(check.manifest + integritySignatureSuffix)
+		if err != nil {
+			return fmt.Errorf("error reading the signature of the manifest: %v", err)
+		}
+		if err := verifyBlobSignature(key, manifest, signature); err != nil {
+			return fmt.Errorf("the signature of %s doesn't verify: %v", check.manifest, err)
+		}
+	}
+
+	sums := parseChecksums(manifest)
+	expected, name := "", ""
+	for _, candidate := range check.names {
+		if sum := sums[candidate]; sum != "" {
+			expected, name = sum, candidate
+			break
+		}
+	}
+	if expected == "" {
+		return fmt.Errorf("%s lists none of %s", check.manifest, strings.Join(check.names, ", "))
+	}
+	actual, err := fileSHA256(check.executable)
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return fmt.Errorf("its SHA-256 is %s, but %s lists %s for %s", actual, check.manifest, expected, name)
+	}
+	return nil
+}
+
+// readIntegrityFile reads a file, or fetches it when the location is an http(s) URL
+func readIntegrityFile(location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "https://") && !strings.HasPrefix(location, "http://") {
+		return os.ReadFile(location)
+	}
+	client := &http.Client{Timeout: integrityFetchTimeout}
+	resp, err := client.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded %d", stripURLCredentials(location), resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// verifyBlobSignature verifies a signature of cosign sign-blob: the base64 ASN.1 ECDSA signature of the SHA-256 of the
+// blob, by the key pair of cosign generate-key-pair
+func verifyBlobSignature(publicKeyPEM, blob, signature []byte) error {
+	block, _ := pem.Decode(publicKeyPEM)
+	if block == nil {
+		return errors.New("the public key isn't PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("error parsing the public key: %v", err)
+	}
+	ecdsaKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("unsupported %T public key. Expected an ECDSA key of cosign generate-key-pair", key)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+	if err != nil {
+		return fmt.Errorf("the signature isn't base64 encoded: %v", err)
+	}
+	digest := sha256.Sum256(blob)
+	if !ecdsa.VerifyASN1(ecdsaKey, digest[:], decoded) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// parseChecksums reads a manifest in the format of sha256sum, binary mode entries included
+func parseChecksums(data []byte) map[string]string {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		sum, name, found := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !found {
+			continue
+		}
+		sums[strings.TrimPrefix(strings.TrimSpace(name), "*")] = strings.ToLower(sum)
+	}
+	return sums
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %v", path, err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("error hashing %s: %v", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrityCheck(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "ca-extension")
+	require.NoError(t, os.WriteFile(binary, []byte("release build"), 0755))
+	sum := sha256.Sum256([]byte("release build"))
+	manifest := []byte(hex.EncodeToString(sum[:]) + "  ca-extension-linux-amd64\n" + hex.EncodeToString(sum[:]) + " *ca-extension-windows-amd64.exe\n")
+	manifestPath := filepath.Join(dir, integrityManifestName)
+	require.NoError(t, os.WriteFile(manifestPath, manifest, 0644))
+
+	// The key pair and signature of cosign generate-key-pair and sign-blob
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, "cosign.pub")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644))
+	digest := sha256.Sum256(manifest)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(manifestPath+integritySignatureSuffix, []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), 0644))
+
+	check := &integrityCheck{
+		mode:       integrityEnforce,
+		manifest:   manifestPath,
+		publicKey:  keyPath,
+		executable: binary,
+		names:      []string{"ca-extension", releaseBinaryName("linux", "amd64")},
+	}
+	assert.NoError(t, check.verify())
+	check.names = []string{"ca-extension", releaseBinaryName("windows", "amd64")}
+	assert.NoError(t, check.verify())
+
+	check.names = []string{"ca-extension", releaseBinaryName("darwin", "arm64")}
+	assert.ErrorContains(t, check.verify(), "lists none of ca-extension, ca-extension-darwin-arm64")
+	check.names = []string{releaseBinaryName("linux", "amd64")}
+
+	require.NoError(t, os.WriteFile(binary, []byte("tampered build"), 0755))
+	assert.ErrorContains(t, check.verify(), "but "+manifestPath+" lists "+hex.EncodeToString(sum[:])+" for ca-extension-linux-amd64")
+
+	// A manifest listing the tampered binary doesn't verify against the signature of the published one
+	tampered := sha256.Sum256([]byte("tampered build"))
+	require.NoError(t, os.WriteFile(manifestPath, []byte(hex.EncodeToString(tampered[:])+"  ca-extension-linux-amd64\n"), 0644))
+	assert.EqualError(t, check.verify(), "the signature of "+manifestPath+" doesn't verify: invalid signature")
+	check.publicKey = ""
+	assert.NoError(t, check.verify())
+}
+
+func TestIntegrityCheckFetchesManifest(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "ca-extension")
+	require.NoError(t, os.WriteFile(binary, []byte("release build"), 0755))
+	sum := sha256.Sum256([]byte("release build"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1.1.0/SHA256SUMS" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(hex.EncodeToString(sum[:]) + "  ca-extension\n"))
+	}))
+	defer server.Close()
+
+	check := &integrityCheck{manifest: server.URL + "/v1.1.0/SHA256SUMS", executable: binary, names: []string{"ca-extension"}}
+	assert.NoError(t, check.verify())
+	check.manifest = server.URL + "/v1.0.0/SHA256SUMS"
+	assert.ErrorContains(t, check.verify(), "responded 404")
+}
+
+func TestRunIntegrityCheck(t *testing.T) {
+	t.Setenv(integrityManifestEnv, filepath.Join(t.TempDir(), integrityManifestName))
+	t.Setenv(integrityEnv, "")
+	assert.NoError(t, runIntegrityCheck())
+
+	// The test binary isn't in any manifest
+	t.Setenv(integrityEnv, integrityWarn)
+	assert.NoError(t, runIntegrityCheck())
+	t.Setenv(integrityEnv, integrityEnforce)
+	t.Setenv(integrityKeyEnv, filepath.Join(t.TempDir(), "cosign.pub"))
+	assert.ErrorContains(t, runIntegrityCheck(), "refusing to run: the integrity check of")
+
+	t.Setenv(integrityEnv, "strict")
+	assert.EqualError(t, runIntegrityCheck(), "unsupported CA_EXTENSION_INTEGRITY 'strict'. Expected off, warn or enforce")
+}
+
+func TestEnforcedIntegrityCheckSettings(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "cosign.pub")
+	t.Setenv(integrityEnv, integrityEnforce)
+	t.Setenv(integrityKeyEnv, keyPath)
+	t.Setenv(integrityManifestEnv, "http://releases.acme.io/ca-extension/v1.1.0/SHA256SUMS")
+	_, err := getIntegrityCheck()
+	assert.EqualError(t, err, "CA_EXTENSION_INTEGRITY=enforce refuses to fetch the manifest over plain HTTP: http://releases.acme.io/ca-extension/v1.1.0/SHA256SUMS. Set CA_EXTENSION_INTEGRITY_MANIFEST to an https URL or a local path")
+
+	t.Setenv(integrityManifestEnv, "https://releases.acme.io/ca-extension/v1.1.0/SHA256SUMS")
+	t.Setenv(integrityKeyEnv, "")
+	_, err = getIntegrityCheck()
+	assert.EqualError(t, err, "CA_EXTENSION_INTEGRITY=enforce requires CA_EXTENSION_INTEGRITY_KEY, the path of the cosign public key to verify the signature of the manifest with")
+	assert.EqualError(t, runIntegrityCheck(), err.Error())
+
+	t.Setenv(integrityKeyEnv, keyPath)
+	check, err := getIntegrityCheck()
+	require.NoError(t, err)
+	assert.Equal(t, keyPath, check.publicKey)
+
+	// The warn mode doesn't block anything, so it accepts both
+	t.Setenv(integrityEnv, integrityWarn)
+	t.Setenv(integrityManifestEnv, "http://releases.acme.io/ca-extension/v1.1.0/SHA256SUMS")
+	t.Setenv(integrityKeyEnv, "")
+	check, err = getIntegrityCheck()
+	require.NoError(t, err)
+	assert.Equal(t, "http://releases.acme.io/ca-extension/v1.1.0/SHA256SUMS", check.manifest)
+}
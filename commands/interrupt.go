@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+// interruptContext returns a context canceled by the first Ctrl-C or SIGTERM. The signals then get their default
+// behavior back, so a second Ctrl-C exits at once instead of waiting for the partial results.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// checkedResults leaves out the results of the checks an interrupt aborted or kept from starting
+func checkedResults(results []audit.AuditResult) []audit.AuditResult {
+	var checked []audit.AuditResult
+	for _, result := range results {
+		if !audit.IsInterrupted(result) {
+			checked = append(checked, result)
+		}
+	}
+	return checked
+}
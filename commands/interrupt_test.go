@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterruptedAuditWritesPartialResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	notifications, err := newNotificationDispatcher(&notificationConfig{}, nil)
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	conf := &auditConfiguration{
+		registry:   &registryConfiguration{registryURL: server.URL},
+		lockFile:   filepath.Join("..", "audit", "testdata", "pnpm-lock.yaml"),
+		workers:    2,
+		treeOutput: filepath.Join(dir, defaultTreeFileName),
+		output:     filepath.Join(dir, "results.json"),
+		display:    newDisplayFormat(true),
+		stages:     newRunStages(nil),
+
+		notifications: notifications,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorContains(t, runAudit(ctx, conf), "audit interrupted after checking 0 of")
+
+	// The report of the packages checked before the interrupt is still written
+	report, err := loadAuditReport(conf.output)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Results)
+	assert.Equal(t, []string{stageParse, stageAudit, stageEnrich, stageReport}, stageNames(conf.stages.timings))
+}
+
+func stageNames(timings []StageTiming) []string {
+	var names []string
+	for _, timing := range timings {
+		names = append(names, timing.Stage)
+	}
+	return names
+}
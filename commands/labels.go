@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	labelBlocked = "curation/blocked"
+	labelClean   = "curation/clean"
+)
+
+var errLabelNotFound = errors.New("label not found")
+
+// prLabeler applies and removes labels on the pull/merge request of the current CI run
+type prLabeler interface {
+	applyLabels(add, remove string) error
+}
+
+// githubLabeler labels GitHub pull requests through the issues API
+type githubLabeler struct {
+	apiURL string
+	repo   string
+	token  string
+	number int
+}
+
+// gitlabLabeler labels GitLab merge requests through the merge requests API
+type gitlabLabeler struct {
+	apiURL    string
+	projectID string
+	mrIID     string
+	token     string
+}
+
+// isBlocking reports whether a package would fail to install from the curated registry. Packages pending curation
+// are fetched on their first install, and evaluated then.
+func isBlocking(result audit.AuditResult) bool {
+	return result.StatusCode != http.StatusOK && !result.Pending
+}
+
+// isBlocked reports whether curation blocked a package, rather than not having evaluated it yet
+func isBlocked(result audit.AuditResult) bool {
+	return result.Error == nil && result.StatusCode == http.StatusForbidden && !result.Pending
+}
+
+// detectPRLabeler returns the labeler of the current PR, if the run is a PR pipeline on GitHub Actions or
+// GitLab CI with an API token available
+func detectPRLabeler() prLabeler {
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		token := os.Getenv("GITHUB_TOKEN")
+		repo := os.Getenv("GITHUB_REPOSITORY")
+		number := githubPullRequestNumber(os.Getenv("GITHUB_EVENT_PATH"))
+		if token == "" || repo == "" || number == 0 {
+			return nil
+		}
+		apiURL := os.Getenv("GITHUB_API_URL")
+		if apiURL == "" {
+			apiURL = "https://api.github.com"
+		}
+		return &githubLabeler{apiURL: apiURL, repo: repo, token: token, number: number}
+	}
+
+	if mr := detectGitLabMergeRequest(); mr != nil {
+		return &gitlabLabeler{apiURL: mr.apiURL, projectID: mr.projectID, mrIID: mr.mrIID, token: mr.token}
+	}
+
+	return nil
+}
+
+// githubPullRequestNumber reads the PR number from the GitHub Actions event payload
+func githubPullRequestNumber(eventPath string) int {
+	if eventPath == "" {
+		return 0
+	}
+	data, err := ioutil.ReadFile(eventPath)
+	if err != nil {
+		return 0
+	}
+
+	var event struct {
+		Number      int `json:"number"`
+		PullRequest struct {
+			Number int `json:"number"`
+		} `json:"pull_request"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return 0
+	}
+	if event.PullRequest.Number != 0 {
+		return event.PullRequest.Number
+	}
+	return event.Number
+}
+
+func (l *githubLabeler) applyLabels(add, remove string) error {
+	issueURL := fmt.Sprintf("%s/repos/%s/issues/%d/labels", l.apiURL, l.repo, l.number)
+
+	body, err := json.Marshal(map[string][]string{"labels": {add}})
+	if err != nil {
+		return err
+	}
+	if err := l.send("POST", issueURL, body); err != nil {
+		return fmt.Errorf("error adding label %s: %v", add, err)
+	}
+
+	// A 404 only means the label wasn't set, which is fine
+	err = l.send("DELETE", issueURL+"/"+url.PathEscape(remove), nil)
+	if err != nil && err != errLabelNotFound {
+		return fmt.Errorf("error removing label %s: %v", remove, err)
+	}
+	return nil
+}
+
+func (l *githubLabeler) send(method, requestURL string, body []byte) error {
+	req, err := http.NewRequest(method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+l.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return doLabelRequest(req)
+}
+
+func (l *gitlabLabeler) applyLabels(add, remove string) error {
+	mrURL := fmt.Sprintf("%s/projects/%s/merge_requests/%s", l.apiURL, url.PathEscape(l.projectID), l.mrIID)
+
+	form := url.Values{}
+	form.Set("add_labels", add)
+	form.Set("remove_labels", remove)
+
+	req, err := http.NewRequest("PUT", mrURL, bytes.NewBufferString(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", l.token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := doLabelRequest(req); err != nil {
+		return fmt.Errorf("error updating merge request labels: %v", err)
+	}
+	return nil
+}
+
+func doLabelRequest(req *http.Request) error {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errLabelNotFound
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response: %d", resp.StatusCode)
+	}
+	return nil
+}
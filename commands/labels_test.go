@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// labelRequest is a request the fake GitHub or GitLab API received
+type labelRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   string
+}
+
+// newLabelServer records the requests it receives, answering each with the status of its method
+func newLabelServer(t *testing.T, statuses map[string]int) (*httptest.Server, *[]labelRequest) {
+	var requests []labelRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, labelRequest{method: r.Method, path: r.URL.EscapedPath(), header: r.Header.Clone(), body: string(body)})
+		if status, ok := statuses[r.Method]; ok {
+			w.WriteHeader(status)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func writeEventFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "event.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestGitHubPullRequestNumber(t *testing.T) {
+	assert.Equal(t, 42, githubPullRequestNumber(writeEventFile(t, `{"number": 7, "pull_request": {"number": 42}}`)))
+	// pull_request_target and issue_comment events only have the top-level number
+	assert.Equal(t, 7, githubPullRequestNumber(writeEventFile(t, `{"number": 7}`)))
+	assert.Equal(t, 0, githubPullRequestNumber(writeEventFile(t, `{"ref": "refs/heads/main"}`)))
+	assert.Equal(t, 0, githubPullRequestNumber(writeEventFile(t, `not json`)))
+	assert.Equal(t, 0, githubPullRequestNumber(filepath.Join(t.TempDir(), "missing.json")))
+	assert.Equal(t, 0, githubPullRequestNumber(""))
+}
+
+func TestGitHubLabeler(t *testing.T) {
+	server, requests := newLabelServer(t, map[string]int{http.MethodDelete: http.StatusNotFound})
+	labeler := &githubLabeler{apiURL: server.URL, repo: "acme/webapp", token: "ghs-token", number: 42}
+
+	// The label to remove not being set isn't an error
+	require.NoError(t, labeler.applyLabels(labelBlocked, labelClean))
+	require.Len(t, *requests, 2)
+	added, removed := (*requests)[0], (*requests)[1]
+	assert.Equal(t, http.MethodPost, added.method)
+	assert.Equal(t, "/repos/acme/webapp/issues/42/labels", added.path)
+	assert.Equal(t, "Bearer ghs-token", added.header.Get("Authorization"))
+	assert.Equal(t, "application/vnd.github+json", added.header.Get("Accept"))
+	assert.Equal(t, "application/json", added.header.Get("Content-Type"))
+	var body map[string][]string
+	require.NoError(t, json.Unmarshal([]byte(added.body), &body))
+	assert.Equal(t, map[string][]string{"labels": {labelBlocked}}, body)
+	assert.Equal(t, http.MethodDelete, removed.method)
+	assert.Equal(t, "/repos/acme/webapp/issues/42/labels/curation%2Fclean", removed.path)
+	assert.Equal(t, "Bearer ghs-token", removed.header.Get("Authorization"))
+	assert.Empty(t, removed.header.Get("Content-Type"))
+}
+
+func TestGitHubLabelerFailures(t *testing.T) {
+	server, _ := newLabelServer(t, map[string]int{http.MethodDelete: http.StatusForbidden})
+	labeler := &githubLabeler{apiURL: server.URL, repo: "acme/webapp", token: "ghs-token", number: 42}
+	assert.EqualError(t, labeler.applyLabels(labelBlocked, labelClean), "error removing label curation/clean: unexpected response: 403")
+
+	server, requests := newLabelServer(t, map[string]int{http.MethodPost: http.StatusUnauthorized})
+	labeler.apiURL = server.URL
+	assert.EqualError(t, labeler.applyLabels(labelBlocked, labelClean), "error adding label curation/blocked: unexpected response: 401")
+	// Nothing is removed once adding fails
+	assert.Len(t, *requests, 1)
+}
+
+func TestGitLabLabeler(t *testing.T) {
+	server, requests := newLabelServer(t, nil)
+	labeler := &gitlabLabeler{apiURL: server.URL, projectID: "group/webapp", mrIID: "7", token: "glpat-token"}
+
+	require.NoError(t, labeler.applyLabels(labelClean, labelBlocked))
+	require.Len(t, *requests, 1)
+	request := (*requests)[0]
+	assert.Equal(t, http.MethodPut, request.method)
+	assert.Equal(t, "/projects/group%2Fwebapp/merge_requests/7", request.path)
+	assert.Equal(t, "glpat-token", request.header.Get("PRIVATE-TOKEN"))
+	assert.Equal(t, "application/x-www-form-urlencoded", request.header.Get("Content-Type"))
+	form, err := url.ParseQuery(request.body)
+	require.NoError(t, err)
+	assert.Equal(t, url.Values{"add_labels": {labelClean}, "remove_labels": {labelBlocked}}, form)
+
+	server, _ = newLabelServer(t, map[string]int{http.MethodPut: http.StatusNotFound})
+	labeler.apiURL = server.URL
+	assert.EqualError(t, labeler.applyLabels(labelClean, labelBlocked), "error updating merge request labels: label not found")
+	server, _ = newLabelServer(t, map[string]int{http.MethodPut: http.StatusInternalServerError})
+	labeler.apiURL = server.URL
+	assert.EqualError(t, labeler.applyLabels(labelClean, labelBlocked), "error updating merge request labels: unexpected response: 500")
+}
+
+func TestDetectPRLabeler(t *testing.T) {
+	for _, name := range []string{"GITHUB_ACTIONS", "GITHUB_TOKEN", "GITHUB_REPOSITORY", "GITHUB_EVENT_PATH", "GITHUB_API_URL",
+		"GITLAB_CI", "GITLAB_TOKEN", "CI_API_V4_URL", "CI_PROJECT_ID", "CI_MERGE_REQUEST_IID"} {
+		t.Setenv(name, "")
+	}
+	assert.Nil(t, detectPRLabeler())
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_REPOSITORY", "acme/webapp")
+	t.Setenv("GITHUB_EVENT_PATH", writeEventFile(t, `{"pull_request": {"number": 42}}`))
+	// No token, no labels
+	assert.Nil(t, detectPRLabeler())
+	t.Setenv("GITHUB_TOKEN", "ghs-token")
+	assert.Equal(t, &githubLabeler{apiURL: "https://api.github.com", repo: "acme/webapp", token: "ghs-token", number: 42}, detectPRLabeler())
+	t.Setenv("GITHUB_API_URL", "https://github.acme.io/api/v3")
+	assert.Equal(t, "https://github.acme.io/api/v3", detectPRLabeler().(*githubLabeler).apiURL)
+	// Push events have no pull request to label
+	t.Setenv("GITHUB_EVENT_PATH", writeEventFile(t, `{"ref": "refs/heads/main"}`))
+	assert.Nil(t, detectPRLabeler())
+
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_API_V4_URL", "https://gitlab.acme.io/api/v4")
+	t.Setenv("CI_PROJECT_ID", "12")
+	t.Setenv("CI_MERGE_REQUEST_IID", "7")
+	assert.Nil(t, detectPRLabeler())
+	t.Setenv("GITLAB_TOKEN", "glpat-token")
+	assert.Equal(t, &gitlabLabeler{apiURL: "https://gitlab.acme.io/api/v4", projectID: "12", mrIID: "7", token: "glpat-token"}, detectPRLabeler())
+}
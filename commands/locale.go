@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+const utcFlag = "utc"
+
+// localeConventions represents how a locale writes dates and numbers
+type localeConventions struct {
+	dateLayout string
+	decimal    string
+	// Separator of the thousands of counts, or empty to not group them
+	group string
+}
+
+// The conventions of the C and POSIX locales, and of locales missing from localeTable: ISO 8601 dates, ungrouped counts
+var posixConventions = localeConventions{dateLayout: "2006-01-02 15:04:05 MST", decimal: "."}
+
+// localeTable maps a language, or a language_TERRITORY, to its conventions
+var localeTable = map[string]localeConventions{
+	"en":    {dateLayout: "2 Jan 2006 15:04:05 MST", decimal: ".", group: ","},
+	"en_US": {dateLayout: "Jan 2, 2006 3:04:05 PM MST", decimal: ".", group: ","},
+	"de":    {dateLayout: "02.01.2006 15:04:05 MST", decimal: ",", group: "."},
+	"es":    {dateLayout: "02/01/2006 15:04:05 MST", decimal: ",", group: "."},
+	"fr":    {dateLayout: "02/01/2006 15:04:05 MST", decimal: ",", group: "\u202f"},
+	"it":    {dateLayout: "02/01/2006 15:04:05 MST", decimal: ",", group: "."},
+	"ja":    {dateLayout: "2006/01/02 15:04:05 MST", decimal: ".", group: ","},
+	"nl":    {dateLayout: "02-01-2006 15:04:05 MST", decimal: ",", group: "."},
+	"pl":    {dateLayout: "02.01.2006 15:04:05 MST", decimal: ",", group: "\u00a0"},
+	"pt":    {dateLayout: "02/01/2006 15:04:05 MST", decimal: ",", group: "."},
+	"ru":    {dateLayout: "02.01.2006 15:04:05 MST", decimal: ",", group: "\u00a0"},
+	"sv":    {dateLayout: "2006-01-02 15:04:05 MST", decimal: ",", group: "\u00a0"},
+	"zh":    {dateLayout: "2006/01/02 15:04:05 MST", decimal: ".", group: ","},
+}
+
+// displayFormat formats the timestamps, durations and counts of the console and text outputs, by the locale of the
+// environment, or as ISO 8601 UTC times and plain numbers with --utc. JSON outputs are locale independent, with
+// RFC 3339 UTC times and numbers.
+type displayFormat struct {
+	conventions localeConventions
+	location    *time.Location
+	utc         bool
+	// Whether statuses are printed with words instead of icons, see status
+	accessible bool
+}
+
+func getUTCFlag() components.Flag {
+	return components.NewBoolFlag(
+		utcFlag,
+		"Print times as ISO 8601 UTC, and durations and counts without locale formatting, instead of by LC_ALL, LC_TIME, LC_NUMERIC or LANG",
+		components.WithBoolDefaultValue(false),
+	)
+}
+
+func getDisplayFormat(c *components.Context) *displayFormat {
+	display := newDisplayFormat(c.GetBoolFlagValue(utcFlag))
+	display.accessible = isAccessible(c)
+	return display
+}
+
+// newDisplayFormat reads the locale from the environment, as the C library does, unless utc is set
+func newDisplayFormat(utc bool) *displayFormat {
+	if utc {
+		return &displayFormat{conventions: posixConventions, location: time.UTC, utc: true}
+	}
+	conventions := lookupLocale(localeEnv("LC_TIME"))
+	numeric := lookupLocale(localeEnv("LC_NUMERIC"))
+	conventions.decimal, conventions.group = numeric.decimal, numeric.group
+	return &displayFormat{conventions: conventions, location: time.Local}
+}
+
+// localeEnv returns the locale of a category: LC_ALL overrides the category, which overrides LANG
+func localeEnv(category string) string {
+	for _, name := range []string{"LC_ALL", category, "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// lookupLocale returns the conventions of a locale such as de_DE.UTF-8 or en_US@euro, falling back to its language
+func lookupLocale(locale string) localeConventions {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.ReplaceAll(locale, "-", "_")
+	if conventions, ok := localeTable[locale]; ok {
+		return conventions
+	}
+	language, _, _ := strings.Cut(locale, "_")
+	if conventions, ok := localeTable[strings.ToLower(language)]; ok {
+		return conventions
+	}
+	return posixConventions
+}
+
+// timestamp formats a time in the local time zone by the locale, or as RFC 3339 in UTC
+func (f *displayFormat) timestamp(t time.Time) string {
+	if f.utc {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return t.In(f.location).Format(f.conventions.dateLayout)
+}
+
+// duration formats a duration in milliseconds below a second, in tenths of seconds below a minute, and in whole
+// seconds otherwise, e.g. 850ms, 12.3s or 2m5s
+func (f *displayFormat) duration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		seconds := strconv.FormatFloat(d.Round(100*time.Millisecond).Seconds(), 'f', 1, 64)
+		return strings.Replace(seconds, ".", f.conventions.decimal, 1) + "s"
+	default:
+		return d.Round(time.Second).String()
+	}
+}
+
+// count formats a count with the thousands separator of the locale, e.g. 12,345 or 12.345
+func (f *displayFormat) count(n int) string {
+	digits := strconv.Itoa(n)
+	if f.conventions.group == "" {
+		return digits
+	}
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	var sb strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteString(f.conventions.group)
+		}
+		sb.WriteRune(digit)
+	}
+	return sign + sb.String()
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayFormatUTC(t *testing.T) {
+	t.Setenv("LANG", "de_DE.UTF-8")
+	display := newDisplayFormat(true)
+	moment := time.Date(2024, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600))
+	assert.Equal(t, "2024-03-01T11:30:00Z", display.timestamp(moment))
+	assert.Equal(t, "12.3s", display.duration(12345*time.Millisecond))
+	assert.Equal(t, "1234567", display.count(1234567))
+}
+
+func TestDisplayFormatLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_TIME", "en_US.UTF-8")
+	t.Setenv("LC_NUMERIC", "")
+	t.Setenv("LANG", "de_DE.UTF-8@euro")
+	display := newDisplayFormat(false)
+	display.location = time.UTC
+	moment := time.Date(2024, 3, 1, 14, 5, 9, 0, time.UTC)
+	assert.Equal(t, "Mar 1, 2024 2:05:09 PM UTC", display.timestamp(moment))
+	assert.Equal(t, "12,3s", display.duration(12345*time.Millisecond))
+	assert.Equal(t, "1.234.567", display.count(1234567))
+	assert.Equal(t, "-1.000", display.count(-1000))
+	assert.Equal(t, "999", display.count(999))
+
+	t.Setenv("LC_ALL", "C")
+	display = newDisplayFormat(false)
+	assert.Equal(t, "1234567", display.count(1234567))
+}
+
+func TestDisplayFormatDuration(t *testing.T) {
+	display := newDisplayFormat(true)
+	assert.Equal(t, "850ms", display.duration(850*time.Millisecond))
+	assert.Equal(t, "2m5s", display.duration(125400*time.Millisecond))
+}
+
+func TestLookupLocale(t *testing.T) {
+	assert.Equal(t, localeTable["fr"], lookupLocale("fr_CA.UTF-8"))
+	assert.Equal(t, localeTable["en_US"], lookupLocale("en-US"))
+	assert.Equal(t, localeTable["en"], lookupLocale("en_GB"))
+	assert.Equal(t, posixConventions, lookupLocale("POSIX"))
+	assert.Equal(t, posixConventions, lookupLocale(""))
+}
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	verifyLockfileFlag = "verify-lockfile"
+
+	verifyLockfileEnv = "CA_EXTENSION_VERIFY_LOCKFILE"
+
+	// Deadline of the dry-run resolution, which may fetch the metadata of every package
+	lockfileCheckTimeout = 10 * time.Minute
+	// Lines of the package manager output quoted in the error of an outdated lock file
+	maxLockfileCheckLines = 10
+)
+
+// Files next to the lock file the package managers read to resolve it, copied to the directory the check runs in
+var lockfileCheckFiles = []string{"package.json", audit.PnpmWorkspaceFileName, ".npmrc", ".yarnrc", ".yarnrc.yml", ".pnpmfile.cjs"}
+
+func getVerifyLockfileFlag() components.Flag {
+	return components.NewBoolFlag(
+		verifyLockfileFlag,
+		"Before auditing, verify the lock file is up to date with the package.json files, by running the dry-run resolution of the package manager in a temporary directory: pnpm install --lockfile-only --frozen-lockfile, npm ci --dry-run or yarn install --frozen-lockfile (--immutable for Yarn 2+). The package manager must be on the PATH",
+		components.WithBoolDefaultValue(false),
+	)
+}
+
+func getVerifyLockfile(c *components.Context) bool {
+	if c.GetBoolFlagValue(verifyLockfileFlag) {
+		return true
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv(verifyLockfileEnv))
+	return enabled
+}
+
+// lockfileCheckCommand returns the command resolving the lock file without changing it, which fails when the lock
+// file is out of date with the manifests. Install scripts aren't run.
+func lockfileCheckCommand(lockFile string) ([]string, error) {
+	switch audit.PackageManagerFor(lockFile) {
+	case audit.PackageManagerPnpm:
+		return []string{"pnpm", "install", "--lockfile-only", "--frozen-lockfile", "--ignore-scripts"}, nil
+	case audit.PackageManagerNpm:
+		return []string{"npm", "ci", "--dry-run", "--ignore-scripts", "--no-audit", "--no-fund"}, nil
+	case audit.PackageManagerYarn:
+		data, err := os.ReadFile(lockFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", lockFile, err)
+		}
+		// Yarn 2+ lock files start with their __metadata
+		if bytes.Contains(data, []byte("\n__metadata:")) || bytes.HasPrefix(data, []byte("__metadata:")) {
+			return []string{"yarn", "install", "--immutable", "--mode=skip-build"}, nil
+		}
+		return []string{"yarn", "install", "--frozen-lockfile", "--ignore-scripts", "--non-interactive"}, nil
+	default:
+		return nil, fmt.Errorf("--%s is only supported for pnpm, npm and yarn lock files, not %s", verifyLockfileFlag, filepath.Base(lockFile))
+	}
+}
+
+// verifyLockfile runs the dry-run resolution of the package manager on copies of the lock file and the manifests of
+// its projects, failing when the lock file doesn't match them, so the audit reflects what would install. The copies
+// keep the check from touching the project, e.g. its node_modules. They are made in tempRoot, if set.
+func verifyLockfile(ctx context.Context, lockFile string, tree *audit.DependencyTree, tempRoot string) error {
+	command, err := lockfileCheckCommand(lockFile)
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp(tempRoot, "ca-extension-lockfile-")
+	if err != nil {
+		return fmt.Errorf("error creating the lock file check directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := copyLockfileProject(lockFile, tree, dir); err != nil {
+		return err
+	}
+
+	log.Info(fmt.Sprintf("Verifying %s is up to date with package.json (%s)", filepath.Base(lockFile), strings.Join(command, " ")))
+	ctx, cancel := context.WithTimeout(ctx, lockfileCheckTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		log.Info(filepath.Base(lockFile), "is up to date")
+		return nil
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("error verifying %s: %s didn't complete: %v", filepath.Base(lockFile), strings.Join(command[:2], " "), ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fmt.Errorf("error running %s: %v", command[0], err)
+	}
+	return fmt.Errorf("%s is out of date with package.json, run %s install to update it:\n%s", filepath.Base(lockFile), command[0], lastLines(string(output), maxLockfileCheckLines))
+}
+
+// copyLockfileProject copies the lock file, the files next to it the package manager reads and the package.json of
+// each workspace project the lock file records to dir
+func copyLockfileProject(lockFile string, tree *audit.DependencyTree, dir string) error {
+	root := filepath.Dir(lockFile)
+	if !fileExists(filepath.Join(root, "package.json")) {
+		return fmt.Errorf("--%s needs the package.json next to %s", verifyLockfileFlag, lockFile)
+	}
+	files := []string{filepath.Base(lockFile)}
+	for _, name := range lockfileCheckFiles {
+		if fileExists(filepath.Join(root, name)) {
+			files = append(files, name)
+		}
+	}
+	for _, importer := range sortedKeys(tree.Importers) {
+		manifest := filepath.Join(filepath.FromSlash(importer), "package.json")
+		if importer != "." && !strings.HasPrefix(filepath.Clean(manifest), "..") && fileExists(filepath.Join(root, manifest)) {
+			files = append(files, manifest)
+		}
+	}
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", filepath.Join(root, name), err)
+		}
+		target := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("error creating %s: %v", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return fmt.Errorf("error writing %s: %v", target, err)
+		}
+	}
+	return nil
+}
+
+// lastLines returns the last n non-blank lines of an output
+func lastLines(output string, n int) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimRight(line, "\r"))
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
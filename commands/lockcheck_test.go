@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockfileCheckCommand(t *testing.T) {
+	command, err := lockfileCheckCommand("pnpm-lock.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pnpm", "install", "--lockfile-only", "--frozen-lockfile", "--ignore-scripts"}, command)
+
+	command, err = lockfileCheckCommand(filepath.Join("..", "audit", "testdata", "yarn-berry", "yarn.lock"))
+	require.NoError(t, err)
+	assert.Equal(t, "--immutable", command[2])
+	command, err = lockfileCheckCommand(filepath.Join("..", "audit", "testdata", "yarn-classic", "yarn.lock"))
+	require.NoError(t, err)
+	assert.Equal(t, "--frozen-lockfile", command[2])
+
+	_, err = lockfileCheckCommand("poetry.lock")
+	assert.EqualError(t, err, "--verify-lockfile is only supported for pnpm, npm and yarn lock files, not poetry.lock")
+}
+
+func TestVerifyLockfile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake package manager is a shell script")
+	}
+	// A fake pnpm records the files it was run on, and fails as an outdated lock file makes pnpm fail
+	bin := t.TempDir()
+	script := `#!/bin/sh
+find . -type f | sort > "$CA_TEST_FILES"
+if [ -n "$CA_TEST_OUTDATED" ]; then
+  echo "Progress: resolved 1, reused 0"
+  echo " ERR_PNPM_OUTDATED_LOCKFILE  Cannot install with \"frozen-lockfile\" because pnpm-lock.yaml is not up to date with apps/web/package.json"
+  exit 1
+fi
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "pnpm"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	files := filepath.Join(t.TempDir(), "files.txt")
+	t.Setenv("CA_TEST_FILES", files)
+
+	lockFile := filepath.Join("..", "audit", "testdata", "pnpm-workspace", "pnpm-lock.yaml")
+	tree, err := audit.ParseLockFile(lockFile)
+	require.NoError(t, err)
+	require.NoError(t, verifyLockfile(context.Background(), lockFile, tree, ""))
+	copied, err := os.ReadFile(files)
+	require.NoError(t, err)
+	assert.Equal(t, "./apps/web/package.json\n./package.json\n./packages/ui/package.json\n./pnpm-lock.yaml\n./pnpm-workspace.yaml\n", string(copied))
+
+	t.Setenv("CA_TEST_OUTDATED", "true")
+	err = verifyLockfile(context.Background(), lockFile, tree, t.TempDir())
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "pnpm-lock.yaml is out of date with package.json, run pnpm install to update it:\nProgress: resolved 1"), err.Error())
+	assert.Contains(t, err.Error(), "ERR_PNPM_OUTDATED_LOCKFILE")
+
+	// The project needs its package.json
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pnpm-lock.yaml"), []byte("lockfileVersion: '9.0'\n"), 0644))
+	err = verifyLockfile(context.Background(), filepath.Join(dir, "pnpm-lock.yaml"), &audit.DependencyTree{}, "")
+	assert.ErrorContains(t, err, "--verify-lockfile needs the package.json next to")
+}
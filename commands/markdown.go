@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	formatMarkdown = "markdown"
+
+	// Rows of the markdown table, which keeps PR comments of large legacy trees under the comment size limits
+	markdownMaxRows = 50
+)
+
+// Characters of the cells of a markdown table that would break its layout
+var markdownCellReplacer = strings.NewReplacer("|", `\|`, "\r", " ", "\n", " ")
+
+// renderMarkdown renders a compact report of the packages that wouldn't install, with their dependency paths and the
+// reasons the policies blocked them, to post as a pull request comment
+func renderMarkdown(report *AuditReport, display *displayFormat) string {
+	var failing []ResultEntry
+	for _, entry := range report.Results {
+		if entry.StatusCode != http.StatusOK || entry.Error != "" {
+			failing = append(failing, entry)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("### Curation audit of `%s`\n\n", report.LockFile))
+	if len(failing) == 0 {
+		sb.WriteString(fmt.Sprintf("All %s packages are approved by the curated registry.\n", display.count(report.Total)))
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("**%s of %s packages wouldn't install from the curated registry.**\n\n", display.count(len(failing)), display.count(report.Total)))
+	sb.WriteString("| Package | Status | Dependency path | Reason |\n")
+	sb.WriteString("| --- | --- | --- | --- |\n")
+	for i, entry := range failing {
+		if i == markdownMaxRows {
+			break
+		}
+		pkg := "`" + entry.Name + "@" + entry.Version + "`"
+		if entry.RemediationURL != "" {
+			pkg = fmt.Sprintf("[%s](%s)", pkg, entry.RemediationURL)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", pkg, markdownCell(markdownStatus(entry)), markdownCell(markdownPath(entry.Path)), markdownCell(markdownReason(entry))))
+	}
+	if len(failing) > markdownMaxRows {
+		sb.WriteString(fmt.Sprintf("\n_%s more packages are left out, see the full results of the audit._\n", display.count(len(failing)-markdownMaxRows)))
+	}
+	return sb.String()
+}
+
+// markdownStatus returns the status of an entry, noting the findings accepted by an ignore rule or the baseline
+func markdownStatus(entry ResultEntry) string {
+	status := entry.Status
+	switch {
+	case entry.Escalated:
+		status += " (escalated)"
+	case entry.Acknowledged != "":
+		status += " (acknowledged)"
+	case entry.Baseline:
+		status += " (baseline)"
+	}
+	return status
+}
+
+// markdownPath returns the dependency path of an entry, without the root importer of single project lock files
+func markdownPath(path []string) string {
+	if len(path) > 0 && (path[0] == "." || path[0] == "") {
+		path = path[1:]
+	}
+	if len(path) == 0 {
+		return ""
+	}
+	return "`" + strings.Join(path, "` › `") + "`"
+}
+
+// markdownReason returns the policies blocking an entry with their explanations, or its error
+func markdownReason(entry ResultEntry) string {
+	if entry.Error != "" {
+		return entry.Error
+	}
+	var reasons []string
+	for _, policy := range entry.Policies {
+		reason := "**" + policy.Policy + "**"
+		if explanation := strings.TrimSpace(policy.Explanation + " " + policy.Recommendation); explanation != "" {
+			reason += ": " + explanation
+		} else if policy.Condition != "" {
+			reason += ": " + policy.Condition
+		}
+		reasons = append(reasons, reason)
+	}
+	return strings.Join(reasons, "<br>")
+}
+
+func markdownCell(text string) string {
+	return markdownCellReplacer.Replace(text)
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	report := newAuditReport("pnpm-lock.yaml", []audit.AuditResult{
+		{Name: "abbrev", Version: "1.1.1", Status: "✅ Available in NPM Registry", StatusCode: http.StatusOK},
+		{Name: "lodash", Version: "4.17.20", Status: "❌ Blocked by Curation Policy: Block Malicious (Malicious package)", StatusCode: http.StatusForbidden,
+			Policies: []audit.CurationPolicy{{Policy: "Block Malicious", Condition: "Malicious package", Recommendation: "Remove the package"}, {Policy: "Licenses", Condition: "GPL | AGPL"}}},
+		{Name: "left-pad", Version: "1.3.0", Status: "❌ Not Found (404)", StatusCode: http.StatusNotFound},
+	})
+	report.Results[1].Path = []string{".", "express@4.18.2", "lodash@4.17.20"}
+	report.Results[1].RemediationURL = "https://acme.jfrog.io/ui/curation/audit"
+	report.Results[2].Baseline = true
+
+	output, err := renderReport(report, formatMarkdown, newDisplayFormat(true))
+	require.NoError(t, err)
+	assert.Equal(t, "### Curation audit of `pnpm-lock.yaml`\n\n"+
+		"**2 of 3 packages wouldn't install from the curated registry.**\n\n"+
+		"| Package | Status | Dependency path | Reason |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| [`lodash@4.17.20`](https://acme.jfrog.io/ui/curation/audit) | ❌ Blocked by Curation Policy: Block Malicious (Malicious package) | `express@4.18.2` › `lodash@4.17.20` | **Block Malicious**: Remove the package<br>**Licenses**: GPL \\| AGPL |\n"+
+		"| `left-pad@1.3.0` | ❌ Not Found (404) (baseline) |  |  |\n", output)
+
+	output, err = renderReport(newAuditReport("pnpm-lock.yaml", []audit.AuditResult{
+		{Name: "abbrev", Version: "1.1.1", Status: "✅ Available in NPM Registry", StatusCode: http.StatusOK},
+	}), formatMarkdown, newDisplayFormat(true))
+	require.NoError(t, err)
+	assert.Equal(t, "### Curation audit of `pnpm-lock.yaml`\n\nAll 1 packages are approved by the curated registry.\n", output)
+}
+
+func TestRenderMarkdownLeavesOutRowsPastTheLimit(t *testing.T) {
+	var results []audit.AuditResult
+	for i := 0; i < markdownMaxRows+5; i++ {
+		results = append(results, audit.AuditResult{Name: fmt.Sprintf("pkg-%d", i), Version: "1.0.0", Status: "❌ Blocked (403 Forbidden)", StatusCode: http.StatusForbidden})
+	}
+	output := renderMarkdown(newAuditReport("pnpm-lock.yaml", results), newDisplayFormat(true))
+	assert.Equal(t, markdownMaxRows+2, strings.Count(output, "\n|"))
+	assert.Contains(t, output, "_5 more packages are left out, see the full results of the audit._")
+}
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// RunMetadata represents the source revision and pipeline run an output was produced from
+type RunMetadata struct {
+	ToolVersion string `json:"toolVersion"`
+	Commit      string `json:"commit,omitempty"`
+	Branch      string `json:"branch,omitempty"`
+	Repository  string `json:"repository,omitempty"`
+	CI          string `json:"ci,omitempty"`
+	JobURL      string `json:"jobUrl,omitempty"`
+}
+
+// ciEnvironment maps the environment variables of a CI provider to the run metadata
+type ciEnvironment struct {
+	name       string
+	detect     string
+	commit     []string
+	branch     []string
+	repository []string
+	jobURL     func() string
+}
+
+var ciEnvironments = []ciEnvironment{
+	{
+		name:       "github-actions",
+		detect:     "GITHUB_ACTIONS",
+		commit:     []string{"GITHUB_SHA"},
+		branch:     []string{"GITHUB_HEAD_REF", "GITHUB_REF_NAME"},
+		repository: []string{"GITHUB_REPOSITORY"},
+		jobURL: func() string {
+			if os.Getenv("GITHUB_RUN_ID") == "" {
+				return ""
+			}
+			return os.Getenv("GITHUB_SERVER_URL") + "/" + os.Getenv("GITHUB_REPOSITORY") + "/actions/runs/" + os.Getenv("GITHUB_RUN_ID")
+		},
+	},
+	{
+		name:       "gitlab-ci",
+		detect:     "GITLAB_CI",
+		commit:     []string{"CI_COMMIT_SHA"},
+		branch:     []string{"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME", "CI_COMMIT_REF_NAME"},
+		repository: []string{"CI_PROJECT_PATH"},
+		jobURL:     envFunc("CI_JOB_URL"),
+	},
+	{
+		name:       "jenkins",
+		detect:     "JENKINS_URL",
+		commit:     []string{"GIT_COMMIT"},
+		branch:     []string{"CHANGE_BRANCH", "BRANCH_NAME", "GIT_BRANCH"},
+		repository: []string{"GIT_URL"},
+		jobURL:     envFunc("BUILD_URL"),
+	},
+	{
+		name:       "circleci",
+		detect:     "CIRCLECI",
+		commit:     []string{"CIRCLE_SHA1"},
+		branch:     []string{"CIRCLE_BRANCH"},
+		repository: []string{"CIRCLE_REPOSITORY_URL"},
+		jobURL:     envFunc("CIRCLE_BUILD_URL"),
+	},
+	{
+		name:       "azure-pipelines",
+		detect:     "TF_BUILD",
+		commit:     []string{"BUILD_SOURCEVERSION"},
+		branch:     []string{"SYSTEM_PULLREQUEST_SOURCEBRANCH", "BUILD_SOURCEBRANCHNAME"},
+		repository: []string{"BUILD_REPOSITORY_NAME"},
+		jobURL: func() string {
+			if os.Getenv("BUILD_BUILDID") == "" {
+				return ""
+			}
+			return os.Getenv("SYSTEM_COLLECTIONURI") + os.Getenv("SYSTEM_TEAMPROJECT") + "/_build/results?buildId=" + os.Getenv("BUILD_BUILDID")
+		},
+	},
+	{
+		name:       "buildkite",
+		detect:     "BUILDKITE",
+		commit:     []string{"BUILDKITE_COMMIT"},
+		branch:     []string{"BUILDKITE_BRANCH"},
+		repository: []string{"BUILDKITE_REPO"},
+		jobURL:     envFunc("BUILDKITE_BUILD_URL"),
+	},
+}
+
+// detectRunMetadata reads the run metadata from the CI environment, falling back to the git repository
+// containing dir for what the environment doesn't provide
+func detectRunMetadata(dir string) *RunMetadata {
+	metadata := &RunMetadata{ToolVersion: appVersion}
+	for _, ci := range ciEnvironments {
+		if os.Getenv(ci.detect) == "" {
+			continue
+		}
+		metadata.CI = ci.name
+		metadata.Commit = firstEnv(ci.commit)
+		metadata.Branch = firstEnv(ci.branch)
+		metadata.Repository = stripURLCredentials(firstEnv(ci.repository))
+		metadata.JobURL = ci.jobURL()
+		break
+	}
+
+	if metadata.Commit == "" {
+		metadata.Commit = gitOutput(dir, "rev-parse", "HEAD")
+	}
+	if metadata.Branch == "" {
+		if branch := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD"); branch != "HEAD" {
+			metadata.Branch = branch
+		}
+	}
+	if metadata.Repository == "" {
+		metadata.Repository = stripURLCredentials(gitOutput(dir, "config", "--get", "remote.origin.url"))
+	}
+	return metadata
+}
+
+func envFunc(name string) func() string {
+	return func() string {
+		return os.Getenv(name)
+	}
+}
+
+func firstEnv(names []string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// gitOutput runs a git command in dir, returning an empty string when git or the repository is unavailable
+func gitOutput(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// stripURLCredentials drops the user info of remote URLs such as https://token@github.com/acme/app.git
+func stripURLCredentials(remoteURL string) string {
+	scheme := strings.Index(remoteURL, "://")
+	if scheme < 0 {
+		return remoteURL
+	}
+	rest := remoteURL[scheme+3:]
+	if at := strings.Index(rest, "@"); at >= 0 && at < strings.Index(rest+"/", "/") {
+		return remoteURL[:scheme+3] + rest[at+1:]
+	}
+	return remoteURL
+}
@@ -0,0 +1,382 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/smtp"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	notifyConfigFlag = "notify-config"
+
+	notifyConfigEnv = "CA_EXTENSION_NOTIFY_CONFIG"
+
+	eventAuditCompleted  = "audit.completed"
+	eventPackagesBlocked = "packages.blocked"
+
+	notifierWebhook = "webhook"
+	notifierSlack   = "slack"
+	notifierEmail   = "email"
+
+	defaultNotifyRetries = 2
+	defaultNotifyBackoff = time.Second
+
+	// Upper bound of the blocked packages listed in human-readable messages
+	maxNotifiedPackages = 20
+)
+
+// NotificationEvent represents the outcome of a run sent to the notifiers
+type NotificationEvent struct {
+	Metadata *RunMetadata  `json:"metadata,omitempty"`
+	Type     string        `json:"type"`
+	Command  string        `json:"command"`
+	LockFile string        `json:"lockFile"`
+	Total    int           `json:"total"`
+	Blocked  int           `json:"blocked"`
+	Packages []ResultEntry `json:"blockedPackages,omitempty"`
+	Time     time.Time     `json:"time"`
+}
+
+// notifier delivers events to a single destination
+type notifier interface {
+	notify(event NotificationEvent) error
+}
+
+// notificationRoute sends the listed events, or all events when none are listed, to a notifier
+type notificationRoute struct {
+	name     string
+	events   []string
+	notifier notifier
+}
+
+// notificationDispatcher fans events out to the routes, retrying failed deliveries and recording those that
+// still fail in the dead-letter log
+type notificationDispatcher struct {
+	routes     []notificationRoute
+	retries    int
+	backoff    time.Duration
+	deadLetter string
+}
+
+// notificationConfig represents the notification settings file. ${VAR} references are expanded from the
+// environment, so secrets such as webhook URLs don't have to be committed.
+type notificationConfig struct {
+	Retries    *int             `yaml:"retries"`
+	DeadLetter string           `yaml:"deadLetter"`
+	Notifiers  []notifierConfig `yaml:"notifiers"`
+}
+
+// notifierConfig represents a single notifier and the events routed to it
+type notifierConfig struct {
+	Name    string            `yaml:"name"`
+	Type    string            `yaml:"type"`
+	Events  []string          `yaml:"events"`
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+
+	SMTP     string   `yaml:"smtp"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+}
+
+// webhookNotifier posts events as JSON
+type webhookNotifier struct {
+	url     string
+	headers map[string]string
+}
+
+// slackNotifier posts a summary to a Slack incoming webhook
+type slackNotifier struct {
+	url string
+}
+
+// emailNotifier mails a summary through an SMTP server
+type emailNotifier struct {
+	address  string
+	from     string
+	to       []string
+	username string
+	password string
+}
+
+// labelNotifier sets the curation labels on the pull/merge request of the current CI run
+type labelNotifier struct {
+	labeler prLabeler
+}
+
+func getNotifyConfigFlag() components.Flag {
+	return components.NewStringFlag(
+		notifyConfigFlag,
+		"Path of a YAML file routing run events to webhook, Slack and email notifiers",
+		components.WithHelpValue("path"),
+	)
+}
+
+// getNotificationDispatcher loads the notifiers of the configuration file, if any, together with the pull
+// request labeler detected from the CI environment
+func getNotificationDispatcher(c *components.Context) (*notificationDispatcher, error) {
+	config := &notificationConfig{}
+	if path := flagOrEnv(c, notifyConfigFlag, notifyConfigEnv); path != "" {
+		var err error
+		if config, err = loadNotificationConfig(path); err != nil {
+			return nil, err
+		}
+	}
+	return newNotificationDispatcher(config, detectPRLabeler())
+}
+
+func loadNotificationConfig(path string) (*notificationConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading notification config %s: %v", path, err)
+	}
+	var config notificationConfig
+	if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), &config); err != nil {
+		return nil, fmt.Errorf("error parsing notification config %s: %v", path, err)
+	}
+	return &config, nil
+}
+
+func newNotificationDispatcher(config *notificationConfig, labeler prLabeler) (*notificationDispatcher, error) {
+	dispatcher := &notificationDispatcher{
+		retries:    defaultNotifyRetries,
+		backoff:    defaultNotifyBackoff,
+		deadLetter: config.DeadLetter,
+	}
+	if config.Retries != nil {
+		dispatcher.retries = *config.Retries
+	}
+	if labeler != nil {
+		dispatcher.routes = append(dispatcher.routes, notificationRoute{
+			name:     "pull request labels",
+			events:   []string{eventAuditCompleted},
+			notifier: &labelNotifier{labeler: labeler},
+		})
+	}
+
+	for i, notifierConfig := range config.Notifiers {
+		n, err := newNotifier(notifierConfig)
+		if err != nil {
+			return nil, fmt.Errorf("notifier %d: %v", i+1, err)
+		}
+		for _, event := range notifierConfig.Events {
+			if event != eventAuditCompleted && event != eventPackagesBlocked {
+				return nil, fmt.Errorf("notifier %d: unsupported event '%s'. Expected %s or %s", i+1, event, eventAuditCompleted, eventPackagesBlocked)
+			}
+		}
+		name := notifierConfig.Name
+		if name == "" {
+			name = fmt.Sprintf("%s notifier %d", notifierConfig.Type, i+1)
+		}
+		dispatcher.routes = append(dispatcher.routes, notificationRoute{name: name, events: notifierConfig.Events, notifier: n})
+	}
+	return dispatcher, nil
+}
+
+func newNotifier(config notifierConfig) (notifier, error) {
+	switch config.Type {
+	case notifierWebhook, notifierSlack:
+		if config.URL == "" {
+			return nil, fmt.Errorf("missing url for the %s notifier", config.Type)
+		}
+		if config.Type == notifierSlack {
+			return &slackNotifier{url: config.URL}, nil
+		}
+		return &webhookNotifier{url: config.URL, headers: config.Headers}, nil
+	case notifierEmail:
+		if config.SMTP == "" || config.From == "" || len(config.To) == 0 {
+			return nil, fmt.Errorf("the email notifier requires smtp, from and to")
+		}
+		return &emailNotifier{address: config.SMTP, from: config.From, to: config.To, username: config.Username, password: config.Password}, nil
+	}
+	return nil, fmt.Errorf("unsupported notifier type '%s'. Expected %s, %s or %s", config.Type, notifierWebhook, notifierSlack, notifierEmail)
+}
+
+// newNotificationEvents returns the events of a run: always audit.completed, and packages.blocked when any
+// package is blocked. The blocked packages are marked with their acceptance by the failure policy, if any.
+func newNotificationEvents(command, lockFile string, results []audit.AuditResult, metadata *RunMetadata, policy *failurePolicy) []NotificationEvent {
+	completed := NotificationEvent{
+		Metadata: metadata,
+		Type:     eventAuditCompleted,
+		Command:  command,
+		LockFile: lockFile,
+		Total:    len(results),
+		Time:     time.Now().UTC(),
+	}
+	for _, result := range results {
+		if isBlocking(result) {
+			entry := newResultEntry(result)
+			policy.annotate(&entry, result)
+			completed.Blocked++
+			completed.Packages = append(completed.Packages, entry)
+		}
+	}
+	events := []NotificationEvent{completed}
+	if completed.Blocked > 0 {
+		blocked := completed
+		blocked.Type = eventPackagesBlocked
+		events = append(events, blocked)
+	}
+	return events
+}
+
+// dispatch delivers every event to the routes subscribed to it. Failures are logged rather than returned,
+// as notifications never decide the outcome of a run. Once the context is done, e.g. on Ctrl-C, failed
+// deliveries aren't retried.
+func (d *notificationDispatcher) dispatch(ctx context.Context, events []NotificationEvent) {
+	for _, event := range events {
+		for _, route := range d.routes {
+			if len(route.events) > 0 && !slices.Contains(route.events, event.Type) {
+				continue
+			}
+			if err := d.deliver(ctx, route, event); err != nil {
+				log.Warn(fmt.Sprintf("Could not notify %s of %s: %v", route.name, event.Type, err))
+				d.recordDeadLetter(route, event, err)
+				continue
+			}
+			log.Info(fmt.Sprintf("Notified %s of %s", route.name, event.Type))
+		}
+	}
+}
+
+func (d *notificationDispatcher) deliver(ctx context.Context, route notificationRoute, event NotificationEvent) error {
+	backoff := d.backoff
+	var err error
+	for attempt := 0; attempt <= d.retries; attempt++ {
+		if attempt > 0 {
+			if audit.Sleep(ctx, backoff) != nil {
+				return err
+			}
+			backoff *= 2
+		}
+		if err = route.notifier.notify(event); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// recordDeadLetter appends an undelivered event to the dead-letter log as a JSON line
+func (d *notificationDispatcher) recordDeadLetter(route notificationRoute, event NotificationEvent, deliveryErr error) {
+	if d.deadLetter == "" {
+		return
+	}
+	line, err := json.Marshal(map[string]interface{}{
+		"time":     time.Now().UTC(),
+		"notifier": route.name,
+		"error":    deliveryErr.Error(),
+		"event":    event,
+	})
+	if err != nil {
+		return
+	}
+	file, err := os.OpenFile(d.deadLetter, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Warn("Could not write the notification dead-letter log:", err.Error())
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		log.Warn("Could not write the notification dead-letter log:", err.Error())
+	}
+}
+
+// summarizeEvent renders an event for people, listing the first blocked packages with the links to request their
+// waivers
+func summarizeEvent(event NotificationEvent) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Curation %s of %s: %d packages, %d blocked", event.Command, event.LockFile, event.Total, event.Blocked))
+	for i, entry := range event.Packages {
+		if i == maxNotifiedPackages {
+			sb.WriteString(fmt.Sprintf("\n… and %d more", len(event.Packages)-maxNotifiedPackages))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("\n%s@%s %s", entry.Name, entry.Version, entry.Status))
+		if entry.RemediationURL != "" {
+			sb.WriteString(" " + entry.RemediationURL)
+		}
+	}
+	return sb.String()
+}
+
+func (n *webhookNotifier) notify(event NotificationEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return postNotification(n.url, body, n.headers)
+}
+
+func (n *slackNotifier) notify(event NotificationEvent) error {
+	body, err := json.Marshal(map[string]string{"text": summarizeEvent(event)})
+	if err != nil {
+		return err
+	}
+	return postNotification(n.url, body, nil)
+}
+
+func (n *emailNotifier) notify(event NotificationEvent) error {
+	subject := fmt.Sprintf("Curation %s: %d of %d packages blocked", event.Command, event.Blocked, event.Total)
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		n.from, strings.Join(n.to, ", "), subject, strings.ReplaceAll(summarizeEvent(event), "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if n.username != "" {
+		host := n.address
+		if index := strings.LastIndex(host, ":"); index >= 0 {
+			host = host[:index]
+		}
+		auth = smtp.PlainAuth("", n.username, n.password, host)
+	}
+	return smtp.SendMail(n.address, auth, n.from, n.to, []byte(message))
+}
+
+// notify labels the request blocked when a blocked package isn't accepted, by the ignore rules or the baseline,
+// as for --fail-on
+func (n *labelNotifier) notify(event NotificationEvent) error {
+	for _, entry := range event.Packages {
+		if entry.Acknowledged == "" && !entry.Baseline {
+			return n.labeler.applyLabels(labelBlocked, labelClean)
+		}
+	}
+	return n.labeler.applyLabels(labelClean, labelBlocked)
+}
+
+func postNotification(url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response: %d", resp.StatusCode)
+	}
+	return nil
+}
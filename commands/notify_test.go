@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLabeler struct {
+	add, remove string
+}
+
+func (l *fakeLabeler) applyLabels(add, remove string) error {
+	l.add, l.remove = add, remove
+	return nil
+}
+
+func TestNewNotificationEvents(t *testing.T) {
+	results := []audit.AuditResult{
+		{Name: "abbrev", Version: "1.1.1", StatusCode: http.StatusOK},
+		{Name: "minimist", Version: "1.2.5", StatusCode: http.StatusForbidden},
+	}
+	events := newNotificationEvents("audit", "pnpm-lock.yaml", results, nil, nil)
+	assert.Len(t, events, 2)
+	assert.Equal(t, eventAuditCompleted, events[0].Type)
+	assert.Equal(t, eventPackagesBlocked, events[1].Type)
+	assert.Equal(t, 1, events[1].Blocked)
+	assert.Equal(t, "minimist", events[1].Packages[0].Name)
+
+	assert.Len(t, newNotificationEvents("audit", "pnpm-lock.yaml", results[:1], nil, nil), 1)
+
+	// Packages pending curation aren't blocked, nor labeled as such
+	pending := append(results[:1:1], audit.AuditResult{Name: "yallist", Version: "4.0.0", StatusCode: http.StatusForbidden, Pending: true})
+	events = newNotificationEvents("audit", "pnpm-lock.yaml", pending, nil, nil)
+	require.Len(t, events, 1)
+	assert.Zero(t, events[0].Blocked)
+	labeler := &fakeLabeler{}
+	require.NoError(t, (&labelNotifier{labeler: labeler}).notify(events[0]))
+	assert.Equal(t, labelClean, labeler.add)
+}
+
+func TestLabelsAcceptFindingsLikeTheFailurePolicy(t *testing.T) {
+	ignore, err := newIgnoreList([]ignoreRule{{Package: "minimist@1.2.5", Reason: "SEC-42"}})
+	require.NoError(t, err)
+	policy := &failurePolicy{
+		failOn:   failOnBlocked,
+		ignore:   ignore,
+		baseline: &auditBaseline{findings: map[string]bool{"lodash@4.17.20": true}},
+	}
+	results := []audit.AuditResult{
+		{Name: "minimist", Version: "1.2.5", StatusCode: http.StatusForbidden},
+		{Name: "lodash", Version: "4.17.20", StatusCode: http.StatusForbidden},
+	}
+	require.NoError(t, policy.evaluate(results))
+
+	// The accepted findings are still notified, marked as such, but don't label the request blocked
+	events := newNotificationEvents("audit", "pnpm-lock.yaml", results, nil, policy)
+	require.Len(t, events, 2)
+	assert.Equal(t, "minimist@1.2.5: SEC-42", events[1].Packages[0].Acknowledged)
+	assert.True(t, events[1].Packages[1].Baseline)
+	labeler := &fakeLabeler{}
+	require.NoError(t, (&labelNotifier{labeler: labeler}).notify(events[0]))
+	assert.Equal(t, labelClean, labeler.add)
+
+	results = append(results, audit.AuditResult{Name: "react", Version: "18.2.0", StatusCode: http.StatusForbidden})
+	assert.Error(t, policy.evaluate(results))
+	events = newNotificationEvents("audit", "pnpm-lock.yaml", results, nil, policy)
+	require.NoError(t, (&labelNotifier{labeler: labeler}).notify(events[0]))
+	assert.Equal(t, labelBlocked, labeler.add)
+}
+
+func TestNotificationRouting(t *testing.T) {
+	var received []string
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		switch r.URL.Path {
+		case "/slack":
+			received = append(received, "slack: "+strings.SplitN(body["text"].(string), "\n", 2)[0])
+		case "/webhook":
+			received = append(received, "webhook: "+body["type"].(string))
+		case "/failing":
+			attempts++
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	retries := 1
+	deadLetter := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	config := &notificationConfig{
+		Retries:    &retries,
+		DeadLetter: deadLetter,
+		Notifiers: []notifierConfig{
+			{Type: notifierSlack, URL: server.URL + "/slack", Events: []string{eventPackagesBlocked}},
+			{Type: notifierWebhook, URL: server.URL + "/webhook"},
+			{Name: "flaky", Type: notifierWebhook, URL: server.URL + "/failing", Events: []string{eventAuditCompleted}},
+		},
+	}
+	labeler := &fakeLabeler{}
+	dispatcher, err := newNotificationDispatcher(config, labeler)
+	assert.NoError(t, err)
+	dispatcher.backoff = 0
+
+	dispatcher.dispatch(context.Background(), newNotificationEvents("audit", "pnpm-lock.yaml", []audit.AuditResult{
+		{Name: "minimist", Version: "1.2.5", StatusCode: http.StatusForbidden},
+	}, nil, nil))
+
+	assert.Equal(t, []string{
+		"webhook: " + eventAuditCompleted,
+		"slack: Curation audit of pnpm-lock.yaml: 1 packages, 1 blocked",
+		"webhook: " + eventPackagesBlocked,
+	}, received)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, labelBlocked, labeler.add)
+	assert.Equal(t, labelClean, labeler.remove)
+
+	data, err := os.ReadFile(deadLetter)
+	assert.NoError(t, err)
+	var entry struct {
+		Notifier string            `json:"notifier"`
+		Error    string            `json:"error"`
+		Event    NotificationEvent `json:"event"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &entry))
+	assert.Equal(t, "flaky", entry.Notifier)
+	assert.Equal(t, "unexpected response: 502", entry.Error)
+	assert.Equal(t, eventAuditCompleted, entry.Event.Type)
+}
+
+func TestNotificationRetriesStopWithTheContext(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	retries := 3
+	dispatcher, err := newNotificationDispatcher(&notificationConfig{Retries: &retries, Notifiers: []notifierConfig{{Type: notifierWebhook, URL: server.URL}}}, nil)
+	require.NoError(t, err)
+	dispatcher.backoff = time.Hour
+
+	// An interrupted run makes its first attempt, without waiting to retry
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	err = dispatcher.deliver(ctx, dispatcher.routes[0], NotificationEvent{Type: eventAuditCompleted})
+	assert.EqualError(t, err, "unexpected response: 502")
+	assert.Equal(t, 1, attempts)
+	assert.Less(t, time.Since(start), time.Minute)
+}
+
+func TestNewNotifierErrors(t *testing.T) {
+	_, err := newNotificationDispatcher(&notificationConfig{Notifiers: []notifierConfig{{Type: "pager"}}}, nil)
+	assert.ErrorContains(t, err, "unsupported notifier type 'pager'")
+
+	_, err = newNotificationDispatcher(&notificationConfig{Notifiers: []notifierConfig{{Type: notifierSlack, URL: "http://x", Events: []string{"audit.started"}}}}, nil)
+	assert.ErrorContains(t, err, "unsupported event 'audit.started'")
+
+	_, err = newNotifier(notifierConfig{Type: notifierEmail, SMTP: "smtp.acme.io:587"})
+	assert.Error(t, err)
+}
+
+func TestLoadNotificationConfigExpandsEnv(t *testing.T) {
+	t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.com/services/T/B/X")
+	path := filepath.Join(t.TempDir(), "notifications.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("notifiers:\n  - type: slack\n    url: ${SLACK_WEBHOOK_URL}\n"), 0644))
+
+	config, err := loadNotificationConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://hooks.slack.com/services/T/B/X", config.Notifiers[0].URL)
+}
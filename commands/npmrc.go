@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	npmrcFlag = "npmrc"
+
+	npmrcEnv = "CA_EXTENSION_NPMRC"
+)
+
+// Matches the ${VAR} references of .npmrc values to the environment
+var npmrcEnvPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// npmrc holds the settings of .npmrc files that route installs: the registries of the scopes, and the credentials of
+// the registries, keyed by their URL without the scheme, e.g. //acme.jfrog.io/artifactory/api/npm/npm-internal/
+type npmrc struct {
+	scopes map[string]string
+	auth   map[string]map[string]string
+}
+
+// npmScope is a scope whose packages are checked against the registry an .npmrc routes it to
+type npmScope struct {
+	registryURL string
+	credentials audit.Credentials
+}
+
+func getNpmrcFlag() components.Flag {
+	return components.NewBoolFlag(
+		npmrcFlag,
+		"Check the packages of the npm scopes the .npmrc of the project or user routes to another registry, such as @acme:registry=<url>, against that registry, with the credentials the .npmrc has for it",
+		components.WithBoolDefaultValue(false),
+	)
+}
+
+// getNpmScopes returns the scopes the .npmrc files route to registries other than the audited one, when --npmrc is
+// set. The .npmrc next to the lock file overrides the user's, at NPM_CONFIG_USERCONFIG or ~/.npmrc.
+func getNpmScopes(c *components.Context, lockFile, registryURL string) (map[string]npmScope, error) {
+	enabled := c.GetBoolFlagValue(npmrcFlag)
+	if !enabled {
+		enabled, _ = strconv.ParseBool(os.Getenv(npmrcEnv))
+	}
+	if !enabled {
+		return nil, nil
+	}
+	var paths []string
+	if userConfig := os.Getenv("NPM_CONFIG_USERCONFIG"); userConfig != "" {
+		paths = append(paths, userConfig)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".npmrc"))
+	}
+	config, err := loadNpmrc(append(paths, filepath.Join(filepath.Dir(lockFile), ".npmrc"))...)
+	if err != nil {
+		return nil, err
+	}
+	return config.scopeRegistries(registryURL), nil
+}
+
+// loadNpmrc reads the .npmrc files that exist, the settings of the later ones overriding those of the earlier
+func loadNpmrc(paths ...string) (*npmrc, error) {
+	config := &npmrc{scopes: make(map[string]string), auth: make(map[string]map[string]string)}
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", path, err)
+		}
+		err = config.parse(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", path, err)
+		}
+	}
+	return config, nil
+}
+
+// parse reads the scope registries and the registry credentials of an .npmrc, expanding the ${VAR} references to the
+// environment as npm does
+func (n *npmrc) parse(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = npmrcEnvPattern.ReplaceAllStringFunc(strings.Trim(strings.TrimSpace(value), `"'`), func(reference string) string {
+			return os.Getenv(reference[2 : len(reference)-1])
+		})
+		if scope, found := strings.CutSuffix(key, ":registry"); found && strings.HasPrefix(scope, "@") {
+			n.scopes[scope] = strings.TrimSuffix(value, "/")
+			continue
+		}
+		if strings.HasPrefix(key, "//") {
+			if index := strings.LastIndex(key, ":"); index > 0 {
+				prefix := strings.TrimSuffix(key[:index], "/") + "/"
+				if n.auth[prefix] == nil {
+					n.auth[prefix] = make(map[string]string)
+				}
+				n.auth[prefix][key[index+1:]] = value
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// credentials returns the credentials of the registry URL, those of the longest matching prefix: an _authToken, an
+// _auth of base64 user:password, or a username and base64 _password
+func (n *npmrc) credentials(registryURL string) audit.Credentials {
+	_, location, found := strings.Cut(registryURL, "//")
+	if !found {
+		return audit.Credentials{}
+	}
+	location = "//" + strings.TrimSuffix(location, "/") + "/"
+	var settings map[string]string
+	longest := 0
+	for prefix, values := range n.auth {
+		if strings.HasPrefix(location, prefix) && len(prefix) > longest {
+			settings, longest = values, len(prefix)
+		}
+	}
+	if token := settings["_authToken"]; token != "" {
+		return audit.Credentials{AccessToken: token}
+	}
+	if auth, err := base64.StdEncoding.DecodeString(settings["_auth"]); err == nil && len(auth) > 0 {
+		user, password, _ := strings.Cut(string(auth), ":")
+		return audit.Credentials{User: user, Password: password}
+	}
+	if password, err := base64.StdEncoding.DecodeString(settings["_password"]); err == nil && settings["username"] != "" {
+		return audit.Credentials{User: settings["username"], Password: string(password)}
+	}
+	return audit.Credentials{}
+}
+
+// scopeRegistries returns the scopes routed to a registry other than the audited one, with its credentials
+func (n *npmrc) scopeRegistries(registryURL string) map[string]npmScope {
+	scopes := make(map[string]npmScope)
+	for _, scope := range sortedKeys(n.scopes) {
+		scopeURL := n.scopes[scope]
+		if scopeURL == "" || strings.EqualFold(scopeURL, strings.TrimSuffix(registryURL, "/")) {
+			continue
+		}
+		scopes[scope] = npmScope{registryURL: scopeURL, credentials: n.credentials(scopeURL)}
+		log.Info(fmt.Sprintf("Checking the %s packages against %s, as .npmrc configures", scope, scopeURL))
+	}
+	return scopes
+}
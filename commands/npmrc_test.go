@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadNpmrc(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.npmrc")
+	require.NoError(t, os.WriteFile(user, []byte(`; user settings
+@acme:registry=https://acme.jfrog.io/artifactory/api/npm/npm-old/
+@tools:registry=https://tools.acme.io/npm
+//acme.jfrog.io/artifactory/:_authToken=user-token
+//tools.acme.io/:_auth=Y2k6c2VjcmV0
+`), 0600))
+	project := filepath.Join(dir, ".npmrc")
+	require.NoError(t, os.WriteFile(project, []byte(`# The project routes its scope to the internal repository
+@acme:registry=https://acme.jfrog.io/artifactory/api/npm/npm-internal/
+//acme.jfrog.io/artifactory/api/npm/npm-internal/:_authToken=${NPM_INTERNAL_TOKEN}
+registry=https://acme.jfrog.io/artifactory/api/npm/npm-curated/
+`), 0600))
+	t.Setenv("NPM_INTERNAL_TOKEN", "internal-token")
+
+	config, err := loadNpmrc(user, project, filepath.Join(dir, "missing.npmrc"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]npmScope{
+		// The project overrides the user, and the credentials of the longest prefix apply
+		"@acme":  {registryURL: "https://acme.jfrog.io/artifactory/api/npm/npm-internal", credentials: audit.Credentials{AccessToken: "internal-token"}},
+		"@tools": {registryURL: "https://tools.acme.io/npm", credentials: audit.Credentials{User: "ci", Password: "secret"}},
+	}, config.scopeRegistries("https://acme.jfrog.io/artifactory/api/npm/npm-curated"))
+	assert.Equal(t, audit.Credentials{AccessToken: "user-token"}, config.credentials("https://acme.jfrog.io/artifactory/api/npm/npm-remote"))
+	assert.Equal(t, audit.Credentials{}, config.credentials("https://registry.npmjs.org"))
+
+	// Scopes routed to the audited registry are checked as the other packages
+	assert.NotContains(t, config.scopeRegistries("https://tools.acme.io/npm/"), "@tools")
+}
@@ -0,0 +1,288 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	ociScheme = "oci://"
+
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+
+	// Upper bound of a manifest or blob read from an OCI registry
+	maxOCIArtifactSize = 16 << 20
+)
+
+// Matches the key=value parameters of a WWW-Authenticate Bearer challenge
+var authChallengePattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// ociReference represents an artifact reference such as oci://acme.jfrog.io/curation/profile:1.4.0
+type ociReference struct {
+	host       string
+	repository string
+	reference  string
+}
+
+// ociDescriptor represents a content descriptor of an OCI manifest
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ociManifest represents an OCI image manifest, as used for artifacts
+type ociManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        ociDescriptor     `json:"config"`
+	Layers        []ociDescriptor   `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// ociClient talks to an OCI distribution registry, authenticating with a bearer token when one is set and
+// answering anonymous token challenges otherwise
+type ociClient struct {
+	ref   ociReference
+	token string
+	http  *http.Client
+	// Algorithm of the digests of pushed blobs and manifests
+	algorithm string
+	// Flag to set the token with, suggested when the registry requires authentication
+	tokenFlag string
+}
+
+func parseOCIReference(ref string) (ociReference, error) {
+	parsed, err := parseOCIRepository(ref)
+	if err == nil && parsed.reference == "" {
+		err = fmt.Errorf("invalid OCI reference '%s'. Expected oci://<host>/<repository>:<tag>", ref)
+	}
+	return parsed, err
+}
+
+// parseOCIRepository parses a reference whose tag or digest is optional, such as oci://acme.jfrog.io/curation/reports
+func parseOCIRepository(ref string) (ociReference, error) {
+	rest := strings.TrimPrefix(ref, ociScheme)
+	slash := strings.Index(rest, "/")
+	if !strings.HasPrefix(ref, ociScheme) || slash <= 0 {
+		return ociReference{}, fmt.Errorf("invalid OCI reference '%s'. Expected oci://<host>/<repository>:<tag>", ref)
+	}
+	parsed := ociReference{host: rest[:slash], repository: rest[slash+1:]}
+	if at := strings.Index(parsed.repository, "@"); at >= 0 {
+		parsed.repository, parsed.reference = parsed.repository[:at], parsed.repository[at+1:]
+	} else if colon := strings.LastIndex(parsed.repository, ":"); colon >= 0 {
+		parsed.repository, parsed.reference = parsed.repository[:colon], parsed.repository[colon+1:]
+	}
+	if parsed.repository == "" {
+		return ociReference{}, fmt.Errorf("invalid OCI reference '%s'. Expected oci://<host>/<repository>:<tag>", ref)
+	}
+	return parsed, nil
+}
+
+func (r ociReference) String() string {
+	separator := ":"
+	// Tags can't contain ':', digests are <algorithm>:<hex>
+	if strings.Contains(r.reference, ":") {
+		separator = "@"
+	}
+	return ociScheme + r.host + "/" + r.repository + separator + r.reference
+}
+
+func newOCIClient(ref ociReference, token, algorithm string) *ociClient {
+	return &ociClient{ref: ref, token: token, http: &http.Client{Timeout: 2 * time.Minute}, algorithm: algorithm, tokenFlag: accessTokenFlag}
+}
+
+func (c *ociClient) url(path string) string {
+	scheme := "https"
+	// Local registries, as used for development and tests, are served over plain HTTP
+	if isLoopbackHost(c.ref.host) {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/%s", scheme, c.ref.host, c.ref.repository, path)
+}
+
+// isLoopbackHost reports whether a host, with or without its port, is exactly localhost or a loopback address, so
+// hosts such as localhost.acme.io aren't served over plain HTTP
+func isLoopbackHost(host string) bool {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return strings.EqualFold(host, "localhost") || host == "127.0.0.1" || host == "::1"
+}
+
+// pullManifest reads the manifest the reference points to
+func (c *ociClient) pullManifest() (*ociManifest, error) {
+	resp, err := c.do("GET", c.url("manifests/"+c.ref.reference), nil, map[string]string{"Accept": ociManifestMediaType})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error reading manifest %s: unexpected response: %d", c.ref.reference, resp.StatusCode)
+	}
+	var manifest ociManifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOCIArtifactSize)).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("error parsing manifest: %v", err)
+	}
+	return &manifest, nil
+}
+
+// pullBlob reads a blob and verifies it matches its digest
+func (c *ociClient) pullBlob(digest string) ([]byte, error) {
+	resp, err := c.do("GET", c.url("blobs/"+digest), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error reading blob %s: unexpected response: %d", digest, resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxOCIArtifactSize))
+	if err != nil {
+		return nil, fmt.Errorf("error reading blob %s: %v", digest, err)
+	}
+	if _, err := verifyDigest(digest, data); err != nil {
+		return nil, fmt.Errorf("blob %v", err)
+	}
+	return data, nil
+}
+
+// pushBlob uploads a blob in a single request, unless the registry already has it
+func (c *ociClient) pushBlob(mediaType string, data []byte) (ociDescriptor, error) {
+	descriptor := ociDescriptor{MediaType: mediaType, Digest: digestOf(c.algorithm, data), Size: int64(len(data))}
+	resp, err := c.do("HEAD", c.url("blobs/"+descriptor.Digest), nil, nil)
+	if err != nil {
+		return descriptor, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return descriptor, nil
+	}
+
+	resp, err = c.do("POST", c.url("blobs/uploads/"), nil, nil)
+	if err != nil {
+		return descriptor, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return descriptor, fmt.Errorf("error starting blob upload: unexpected response: %d", resp.StatusCode)
+	}
+	base, err := url.Parse(c.url("blobs/uploads/"))
+	if err != nil {
+		return descriptor, err
+	}
+	location, err := base.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return descriptor, fmt.Errorf("invalid blob upload location: %v", err)
+	}
+	query := location.Query()
+	query.Set("digest", descriptor.Digest)
+	location.RawQuery = query.Encode()
+
+	resp, err = c.do("PUT", location.String(), data, map[string]string{"Content-Type": "application/octet-stream"})
+	if err != nil {
+		return descriptor, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return descriptor, fmt.Errorf("error uploading blob %s: unexpected response: %d", descriptor.Digest, resp.StatusCode)
+	}
+	return descriptor, nil
+}
+
+// pushManifest uploads a manifest under the reference, returning its digest
+func (c *ociClient) pushManifest(manifest *ociManifest) (string, error) {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	resp, err := c.do("PUT", c.url("manifests/"+c.ref.reference), data, map[string]string{"Content-Type": ociManifestMediaType})
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("error uploading manifest %s: unexpected response: %d", c.ref.reference, resp.StatusCode)
+	}
+	return digestOf(c.algorithm, data), nil
+}
+
+// do sends a request, retrying once with an anonymous token when the registry answers with a Bearer challenge
+func (c *ociClient) do(method, requestURL string, body []byte, headers map[string]string) (*http.Response, error) {
+	resp, err := c.send(method, requestURL, body, headers, c.token)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || c.token != "" {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	token, err := c.anonymousToken(challenge)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(method, requestURL, body, headers, token)
+}
+
+func (c *ociClient) send(method, requestURL string, body []byte, headers map[string]string, token string) (*http.Response, error) {
+	req, err := http.NewRequest(method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.http.Do(req)
+}
+
+// anonymousToken answers a challenge such as Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="..."
+func (c *ociClient) anonymousToken(challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("the registry requires authentication, use --%s", c.tokenFlag)
+	}
+	params := make(map[string]string)
+	for _, match := range authChallengePattern.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	if params["realm"] == "" {
+		return "", fmt.Errorf("invalid authentication challenge: %s", challenge)
+	}
+	query := url.Values{}
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	resp, err := c.http.Get(params["realm"] + "?" + query.Encode())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error requesting a registry token: unexpected response: %d", resp.StatusCode)
+	}
+	var tokenResponse struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", fmt.Errorf("error parsing registry token: %v", err)
+	}
+	if tokenResponse.Token != "" {
+		return tokenResponse.Token, nil
+	}
+	return tokenResponse.AccessToken, nil
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ociRequest is a request the fake OCI registry received
+type ociRequest struct {
+	method        string
+	path          string
+	query         string
+	authorization string
+	contentType   string
+	body          string
+}
+
+// newRecordingOCIRegistry records the requests of the distribution API it receives, storing the uploaded blobs
+func newRecordingOCIRegistry(t *testing.T) (*httptest.Server, *[]ociRequest) {
+	var requests []ociRequest
+	blobs := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, ociRequest{
+			method:        r.Method,
+			path:          r.URL.Path,
+			query:         r.URL.RawQuery,
+			authorization: r.Header.Get("Authorization"),
+			contentType:   r.Header.Get("Content-Type"),
+			body:          string(body),
+		})
+		switch {
+		case r.Method == http.MethodHead && strings.HasPrefix(r.URL.Path, "/v2/curation/reports/blobs/"):
+			if !blobs[strings.TrimPrefix(r.URL.Path, "/v2/curation/reports/blobs/")] {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/curation/reports/blobs/uploads/":
+			w.Header().Set("Location", "/v2/curation/reports/blobs/uploads/7f3c?_state=abc")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/curation/reports/blobs/uploads/7f3c":
+			blobs[r.URL.Query().Get("digest")] = true
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/v2/curation/reports/manifests/"):
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func testOCIReference(server *httptest.Server, reference string) ociReference {
+	return ociReference{host: strings.TrimPrefix(server.URL, "http://"), repository: "curation/reports", reference: reference}
+}
+
+func TestOCIClientPush(t *testing.T) {
+	server, requests := newRecordingOCIRegistry(t)
+	client := newOCIClient(testOCIReference(server, "release"), "oci-token", digestSHA256)
+
+	data := []byte(`{"results":[]}`)
+	descriptor, err := client.pushBlob(reportMediaType, data)
+	require.NoError(t, err)
+	digest := digestOf(digestSHA256, data)
+	assert.Equal(t, ociDescriptor{MediaType: reportMediaType, Digest: digest, Size: int64(len(data))}, descriptor)
+	require.Len(t, *requests, 3)
+	assert.Equal(t, ociRequest{method: http.MethodHead, path: "/v2/curation/reports/blobs/" + digest, authorization: "Bearer oci-token"}, (*requests)[0])
+	assert.Equal(t, ociRequest{method: http.MethodPost, path: "/v2/curation/reports/blobs/uploads/", authorization: "Bearer oci-token"}, (*requests)[1])
+	// The digest is added to the query of the upload location
+	assert.Equal(t, ociRequest{
+		method:        http.MethodPut,
+		path:          "/v2/curation/reports/blobs/uploads/7f3c",
+		query:         "_state=abc&digest=" + strings.Replace(digest, ":", "%3A", 1),
+		authorization: "Bearer oci-token",
+		contentType:   "application/octet-stream",
+		body:          string(data),
+	}, (*requests)[2])
+
+	// Blobs the registry has aren't uploaded again
+	_, err = client.pushBlob(reportMediaType, data)
+	require.NoError(t, err)
+	require.Len(t, *requests, 4)
+	assert.Equal(t, http.MethodHead, (*requests)[3].method)
+
+	manifest := &ociManifest{SchemaVersion: 2, MediaType: ociManifestMediaType, ArtifactType: auditArtifactType, Config: descriptor, Layers: []ociDescriptor{descriptor}}
+	manifestDigest, err := client.pushManifest(manifest)
+	require.NoError(t, err)
+	require.Len(t, *requests, 5)
+	pushed := (*requests)[4]
+	assert.Equal(t, http.MethodPut, pushed.method)
+	assert.Equal(t, "/v2/curation/reports/manifests/release", pushed.path)
+	assert.Equal(t, ociManifestMediaType, pushed.contentType)
+	assert.Equal(t, "Bearer oci-token", pushed.authorization)
+	assert.Equal(t, digestOf(digestSHA256, []byte(pushed.body)), manifestDigest)
+	var sent ociManifest
+	require.NoError(t, json.Unmarshal([]byte(pushed.body), &sent))
+	assert.Equal(t, *manifest, sent)
+}
+
+func TestOCIClientAnonymousToken(t *testing.T) {
+	var authorizations []string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			assert.Equal(t, "registry.test", r.URL.Query().Get("service"))
+			assert.Equal(t, "repository:curation/reports:pull,push", r.URL.Query().Get("scope"))
+			assert.Empty(t, r.Header.Get("Authorization"))
+			w.Write([]byte(`{"token":"anonymous-token"}`))
+			return
+		}
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry.test",scope="repository:curation/reports:pull,push"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := newOCIClient(testOCIReference(server, "release"), "", digestSHA256)
+	_, err := client.pushBlob(reportMediaType, []byte("{}"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "Bearer anonymous-token"}, authorizations)
+}
+
+func TestOCIClientRequiresToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := newOCIClient(testOCIReference(server, "release"), "", digestSHA256)
+	_, err := client.pullManifest()
+	assert.EqualError(t, err, "the registry requires authentication, use --access-token")
+	client.tokenFlag = ociTokenFlag
+	_, err = client.pushBlob(reportMediaType, []byte("{}"))
+	assert.EqualError(t, err, "the registry requires authentication, use --oci-token")
+}
+
+func TestIsLoopbackHost(t *testing.T) {
+	for host, loopback := range map[string]bool{
+		"localhost":            true,
+		"localhost:5000":       true,
+		"127.0.0.1":            true,
+		"127.0.0.1:5000":       true,
+		"[::1]:5000":           true,
+		"[::1]":                true,
+		"localhost.evil.com":   false,
+		"127.0.0.1.nip.io":     false,
+		"127.0.0.1.nip.io:443": false,
+		"acme.jfrog.io":        false,
+	} {
+		assert.Equal(t, loopback, isLoopbackHost(host), host)
+	}
+
+	client := newOCIClient(ociReference{host: "localhost.evil.com", repository: "curation/reports"}, "", digestSHA256)
+	assert.Equal(t, "https://localhost.evil.com/v2/curation/reports/manifests/release", client.url("manifests/release"))
+	client.ref.host = "localhost:5000"
+	assert.Equal(t, "http://localhost:5000/v2/curation/reports/manifests/release", client.url("manifests/release"))
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+// dependencyPaths tells how each blocked package gets into the tree, so developers know which direct dependency
+// to pin or replace
+type dependencyPaths struct {
+	// Shortest chain from an importer to each blocked package
+	blocked map[string][]string
+	// Lock files of a single project have one importer, which paths don't need to name
+	singleProject bool
+}
+
+// findDependencyPaths traces the blocked packages of a tree back to its importers. It returns nil for trees without
+// importers, of lock files that don't record the direct dependencies.
+func findDependencyPaths(tree *audit.DependencyTree, results []audit.AuditResult) *dependencyPaths {
+	if len(tree.Importers) == 0 {
+		return nil
+	}
+	paths := &dependencyPaths{blocked: make(map[string][]string), singleProject: len(tree.Importers) == 1}
+	all := tree.DependencyPaths()
+	for _, result := range results {
+		if isBlocking(result) && result.Type != audit.TypeBundled && len(all[result.Name]) > 0 {
+			paths.blocked[result.Name] = all[result.Name]
+		}
+	}
+	return paths
+}
+
+// annotate records the path of each blocked package in the report
+func (p *dependencyPaths) annotate(report *AuditReport) {
+	if p == nil {
+		return
+	}
+	for i := range report.Results {
+		if report.Results[i].Type != audit.TypeBundled {
+			report.Results[i].Path = p.blocked[report.Results[i].Name]
+		}
+	}
+}
+
+// print lists the paths of the blocked packages, naming the importers after the workspace projects, if any
+func (p *dependencyPaths) print(results []audit.AuditResult, projects *workspaceProjects) {
+	if p == nil || len(p.blocked) == 0 {
+		return
+	}
+	fmt.Printf("\n\nDependency paths of blocked packages:")
+	for _, result := range results {
+		path := p.blocked[result.Name]
+		if !isBlocking(result) || len(path) == 0 || result.Type == audit.TypeBundled {
+			continue
+		}
+		fmt.Printf("\n%s@%s: %s", result.Name, result.Version, p.describe(path, projects))
+	}
+}
+
+func (p *dependencyPaths) describe(path []string, projects *workspaceProjects) string {
+	if p.singleProject {
+		return strings.Join(path[1:], " > ")
+	}
+	var workspace *audit.Workspace
+	if projects != nil {
+		workspace = projects.workspace
+	}
+	return strings.Join(append([]string{workspace.Describe(path[0])}, path[1:]...), " > ")
+}
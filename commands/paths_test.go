@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDependencyPaths(t *testing.T) {
+	lockFile := filepath.Join("..", "audit", "testdata", "pnpm-workspace", "pnpm-lock.yaml")
+	tree, err := audit.ParseLockFile(lockFile)
+	assert.NoError(t, err)
+	results := []audit.AuditResult{
+		{Name: "js-tokens", Version: "4.0.0", Type: audit.TypePackage, StatusCode: http.StatusForbidden},
+		{Name: "react", Version: "18.2.0", Type: audit.TypePackage, StatusCode: http.StatusOK},
+		{Name: "js-tokens", Version: "4.0.0", Type: audit.TypeBundled, StatusCode: http.StatusForbidden},
+	}
+
+	paths := findDependencyPaths(tree, results)
+	assert.Equal(t, map[string][]string{"js-tokens": {"legacy/old", "loose-envify@1.4.0", "js-tokens@4.0.0"}}, paths.blocked)
+
+	report := newAuditReport(lockFile, results)
+	paths.annotate(report)
+	assert.Equal(t, []string{"legacy/old", "loose-envify@1.4.0", "js-tokens@4.0.0"}, report.Results[0].Path)
+	assert.Nil(t, report.Results[1].Path)
+	assert.Nil(t, report.Results[2].Path)
+	assertSchemaCovers(t, schemaReport, report)
+
+	projects, err := findWorkspaceProjects(tree, lockFile, results)
+	assert.NoError(t, err)
+	assert.Equal(t, "legacy/old > loose-envify@1.4.0 > js-tokens@4.0.0", paths.describe(paths.blocked["js-tokens"], projects))
+	path := []string{"apps/web", "react-dom@18.2.0", "react@18.2.0"}
+	assert.Equal(t, "@acme/web (apps/web) > react-dom@18.2.0 > react@18.2.0", paths.describe(path, projects))
+	assert.Equal(t, "apps/web > react-dom@18.2.0 > react@18.2.0", paths.describe(path, nil))
+
+	// The importer of a single project isn't named
+	single := &dependencyPaths{singleProject: true}
+	assert.Equal(t, "react-dom@18.2.0 > react@18.2.0", single.describe(path, nil))
+
+	// Trees without importers have no paths
+	requirements, err := audit.ParseLockFileData("requirements.txt", []byte("requests==2.31.0\n"))
+	assert.NoError(t, err)
+	assert.Nil(t, findDependencyPaths(requirements, results))
+}
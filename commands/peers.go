@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-cli-plugin-template/internal/semver"
+)
+
+const peersFlag = "peers"
+
+// PeerGap represents a declared peer dependency of a direct dependency that is missing or out of range
+type PeerGap struct {
+	Importer string `json:"importer"`
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Peer     string `json:"peer"`
+	Range    string `json:"range"`
+	Resolved string `json:"resolved,omitempty"`
+}
+
+// findPeerGaps checks the peerDependencies of the direct dependencies of every project. A peer resolves to the
+// version pnpm recorded for it, else to the project's own dependency, else to the version in the tree.
+func findPeerGaps(tree *audit.DependencyTree) []PeerGap {
+	var gaps []PeerGap
+	for _, importer := range sortedKeys(tree.Importers) {
+		direct := tree.Importers[importer]
+		for _, name := range sortedKeys(direct) {
+			version, resolvedPeers := splitPeerSuffix(direct[name])
+			info, exists := tree.Packages[name]
+			if !exists || info.Version != version {
+				continue
+			}
+
+			for _, peer := range sortedKeys(info.PeerDependencies) {
+				peerRange := info.PeerDependencies[peer]
+				resolved := resolvedPeers[peer]
+				if resolved == "" {
+					resolved, _ = splitPeerSuffix(direct[peer])
+				}
+				if resolved == "" {
+					resolved = tree.Packages[peer].Version
+				}
+
+				if resolved == "" {
+					if !slices.Contains(info.OptionalPeers, peer) {
+						gaps = append(gaps, PeerGap{Importer: importer, Name: name, Version: version, Peer: peer, Range: peerRange})
+					}
+					continue
+				}
+				// Ranges that aren't semver, e.g. workspace: or npm: aliases, can't be checked
+				if satisfied, err := semver.Satisfies(resolved, peerRange); err == nil && !satisfied {
+					gaps = append(gaps, PeerGap{Importer: importer, Name: name, Version: version, Peer: peer, Range: peerRange, Resolved: resolved})
+				}
+			}
+		}
+	}
+	return gaps
+}
+
+// splitPeerSuffix splits a pnpm version such as 18.2.0(react@18.2.0)(@types/react@18.2.1) into the version and
+// the peers it was resolved with
+func splitPeerSuffix(version string) (string, map[string]string) {
+	base := strings.SplitN(version, "(", 2)[0]
+	peers := make(map[string]string)
+	depth, start := 0, 0
+	for i, char := range version {
+		switch char {
+		case '(':
+			if depth == 0 {
+				start = i + 1
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				// Peers of peers are nested, e.g. (react-dom@18.2.0(react@18.2.0))
+				packageName, packageVersion := audit.ParsePackageKey(strings.SplitN(version[start:i], "(", 2)[0])
+				if packageName != "" {
+					peers[packageName] = packageVersion
+				}
+			}
+		}
+	}
+	return base, peers
+}
+
+// printPeerGaps lists the peer dependencies that would break at install time
+func printPeerGaps(gaps []PeerGap, display *displayFormat) {
+	if len(gaps) == 0 {
+		return
+	}
+	fmt.Printf("\n\nPeer dependency gaps:")
+	for _, gap := range gaps {
+		status := "❌ Missing"
+		if gap.Resolved != "" {
+			status = fmt.Sprintf("⚠️ Resolved to %s", gap.Resolved)
+		}
+		fmt.Printf("\n%s@%s requires %s@%s (%s) %s", gap.Name, gap.Version, gap.Peer, gap.Range, gap.Importer, display.status(status))
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
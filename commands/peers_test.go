@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+const peersLock = `
+lockfileVersion: '9.0'
+importers:
+  .:
+    dependencies:
+      react:
+        specifier: ^17.0.2
+        version: 17.0.2
+      react-dom:
+        specifier: ^18.2.0
+        version: 18.2.0(react@17.0.2)
+      styled-components:
+        specifier: ^6.0.0
+        version: 6.0.0(react@17.0.2)
+packages:
+  react@17.0.2:
+    resolution: {integrity: sha512-x}
+  react-dom@18.2.0:
+    resolution: {integrity: sha512-x}
+    peerDependencies:
+      react: ^18.2.0
+  styled-components@6.0.0:
+    resolution: {integrity: sha512-x}
+    peerDependencies:
+      react: '>= 16.8.0'
+      react-is: '>= 16.8.0'
+      babel-plugin-styled-components: '*'
+    peerDependenciesMeta:
+      babel-plugin-styled-components:
+        optional: true
+`
+
+func TestFindPeerGaps(t *testing.T) {
+	tree, err := audit.ParseLockFileData("pnpm-lock.yaml", []byte(peersLock))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []PeerGap{
+		{Importer: ".", Name: "react-dom", Version: "18.2.0", Peer: "react", Range: "^18.2.0", Resolved: "17.0.2"},
+		{Importer: ".", Name: "styled-components", Version: "6.0.0", Peer: "react-is", Range: ">= 16.8.0"},
+	}, findPeerGaps(tree))
+}
+
+func TestSplitPeerSuffix(t *testing.T) {
+	version, peers := splitPeerSuffix("1.0.0(@types/react@18.2.1)(react-dom@18.2.0(react@18.2.0))")
+	assert.Equal(t, "1.0.0", version)
+	assert.Equal(t, map[string]string{"@types/react": "18.2.1", "react-dom": "18.2.0"}, peers)
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	enrichWorkersFlag = "enrich-workers"
+	skipStagesFlag    = "skip-stages"
+
+	skipStagesEnv = "CA_EXTENSION_SKIP_STAGES"
+)
+
+// Stages --skip-stages can leave out of a run. The parse and report stages always run, as every output needs them.
+var skippableStages = []string{stageAudit, stageEnrich, stagePolicy}
+
+// enrichments holds what the enrich stage looked up about the checked packages
+type enrichments struct {
+	downloads    []BinaryDownload
+	peerGaps     []PeerGap
+	suggestions  []Suggestion
+	publications *PublicationReport
+}
+
+func getPipelineFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			enrichWorkersFlag,
+			"Number of concurrent requests of each enrichment of the enrich stage (--binaries, --suggest, --as-of), apart from the --"+workersFlag+" of the availability checks. Defaults to --"+workersFlag,
+			components.WithHelpValue("n"),
+		),
+		components.NewStringFlag(
+			skipStagesFlag,
+			"Comma separated stages to leave out of the run: audit to only parse the lock file and write the tree and SBOM, enrich to check availability alone even if enrichments are configured, or policy to report without failing on --"+failOnFlag,
+			components.WithHelpValue("stages"),
+		),
+	}
+}
+
+// getEnrichWorkers returns the size of the pools of the enrichments, those of the availability checks by default
+func getEnrichWorkers(c *components.Context, workers int) (int, error) {
+	value := flagOrConfig(c, enrichWorkersFlag)
+	if value == "" {
+		return workers, nil
+	}
+	enrichWorkers, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("--%s must be a number, got '%s'", enrichWorkersFlag, value)
+	}
+	if enrichWorkers < 1 {
+		return 0, fmt.Errorf("--%s must be at least 1, got %d", enrichWorkersFlag, enrichWorkers)
+	}
+	return enrichWorkers, nil
+}
+
+// parseSkippedStages reads the stages of --skip-stages. Skipping the audit stage skips the enrich and policy stages
+// too, as they work on its results.
+func parseSkippedStages(value string) (map[string]bool, error) {
+	skipped := make(map[string]bool)
+	for _, stage := range splitList(value) {
+		stage = strings.ToLower(stage)
+		if !isSkippableStage(stage) {
+			return nil, fmt.Errorf("invalid --%s '%s'. Expected a stage of %s", skipStagesFlag, stage, strings.Join(skippableStages, ", "))
+		}
+		skipped[stage] = true
+	}
+	if skipped[stageAudit] {
+		skipped[stageEnrich], skipped[stagePolicy] = true, true
+	}
+	return skipped, nil
+}
+
+func isSkippableStage(stage string) bool {
+	for _, name := range skippableStages {
+		if name == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// skipEnrichments turns the enrichments of the configuration off when the enrich stage is skipped, e.g. those a
+// profile or the project config file sets
+func skipEnrichments(conf *auditConfiguration) {
+	if !conf.stages.skips(stageEnrich) {
+		return
+	}
+	if conf.binaries || conf.peers || conf.suggest || conf.asOf != nil {
+		log.Info(fmt.Sprintf("Skipping the enrichments of the run (--%s)", skipStagesFlag))
+	}
+	conf.binaries, conf.peers, conf.suggest, conf.notes, conf.asOf = false, false, false, false, nil
+	if conf.stages.skips(stageAudit) {
+		// The bundled and per-platform packages are checked in the audit stage
+		conf.bundled, conf.platforms = false, nil
+	}
+}
+
+// runEnrichments runs the enrichments of the configuration concurrently, each on its own pool of enrichWorkers,
+// so the slowest one alone sets the length of the stage and none holds up the availability checks, which are done
+// by then
+func runEnrichments(conf *auditConfiguration, results []audit.AuditResult, tree *audit.DependencyTree) *enrichments {
+	enriched := &enrichments{}
+	var wg sync.WaitGroup
+	run := func(enrich func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			enrich()
+		}()
+	}
+	if conf.binaries {
+		run(func() { enriched.downloads = findBinaryDownloads(results, conf.registry, conf.enrichWorkers) })
+	}
+	if conf.peers {
+		run(func() { enriched.peerGaps = findPeerGaps(tree) })
+	}
+	if conf.suggest {
+		run(func() { enriched.suggestions = suggestUpgrades(results, conf.registry, conf.notes, conf.enrichWorkers) })
+	}
+	if conf.asOf != nil {
+		run(func() { enriched.publications = evaluateAsOf(results, *conf.asOf, conf.registry, conf.enrichWorkers) })
+	}
+	wg.Wait()
+	return enriched
+}
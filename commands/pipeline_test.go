@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSkippedStages(t *testing.T) {
+	skipped, err := parseSkippedStages("enrich, Policy")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{stageEnrich: true, stagePolicy: true}, skipped)
+
+	// The later stages work on the results of the audit
+	skipped, err = parseSkippedStages("audit")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{stageAudit: true, stageEnrich: true, stagePolicy: true}, skipped)
+
+	_, err = parseSkippedStages("report")
+	assert.ErrorContains(t, err, "invalid --skip-stages 'report'. Expected a stage of audit, enrich, policy")
+}
+
+func TestEnrichmentsRunConcurrently(t *testing.T) {
+	// Each request waits for the other enrichment's, so the enrichments only complete side by side
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	both := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.URL.Path] = true
+		if len(seen) == 2 {
+			close(both)
+		}
+		mu.Unlock()
+		select {
+		case <-both:
+		case <-time.After(5 * time.Second):
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/left-pad" {
+			w.Write([]byte(`{"time": {"1.3.0": "2018-04-09T00:00:00.000Z"}}`))
+			return
+		}
+		w.Write([]byte(`{"name": "left-pad", "version": "1.3.0", "scripts": {"install": "prebuild-install || node-gyp rebuild"}, "binary": {"host": "https://downloads.example.com"}}`))
+	}))
+	defer server.Close()
+
+	asOf := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	conf := &auditConfiguration{
+		registry:      &registryConfiguration{registryURL: server.URL, requestTimeout: time.Minute},
+		enrichWorkers: 1,
+		binaries:      true,
+		asOf:          &asOf,
+	}
+	results := []audit.AuditResult{{Name: "left-pad", Version: "1.3.0", StatusCode: http.StatusOK}}
+	enriched := runEnrichments(conf, results, &audit.DependencyTree{})
+	require.NotNil(t, enriched.publications)
+	assert.Equal(t, 1, enriched.publications.Existed)
+	assert.NotEmpty(t, enriched.downloads)
+	assert.Nil(t, enriched.suggestions)
+}
+
+func TestAuditSkipsStages(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	notifications, err := newNotificationDispatcher(&notificationConfig{}, nil)
+	require.NoError(t, err)
+	newConf := func(skipped map[string]bool) *auditConfiguration {
+		dir := t.TempDir()
+		conf := &auditConfiguration{
+			registry:   &registryConfiguration{registryURL: server.URL, requestTimeout: time.Minute},
+			lockFile:   filepath.Join("..", "audit", "testdata", "pnpm-lock.yaml"),
+			workers:    2,
+			treeOutput: filepath.Join(dir, defaultTreeFileName),
+			output:     filepath.Join(dir, "results.json"),
+			display:    newDisplayFormat(true),
+			stages:     &runStages{skipped: skipped},
+			failure:    &failurePolicy{failOn: failOnBlocked},
+			suggest:    true,
+
+			notifications: notifications,
+		}
+		skipEnrichments(conf)
+		return conf
+	}
+
+	// Without the audit stage, the lock file is parsed and the tree written without checking any package
+	conf := newConf(map[string]bool{stageAudit: true, stageEnrich: true, stagePolicy: true})
+	require.NoError(t, runAudit(context.Background(), conf))
+	assert.Zero(t, requests.Load())
+	assert.FileExists(t, conf.treeOutput)
+	report, err := loadAuditReport(conf.output)
+	require.NoError(t, err)
+	assert.Empty(t, report.Results)
+
+	// Without the policy stage, the blocked packages are reported without failing the run, and without the
+	// enrich stage no upgrade is looked for
+	conf = newConf(map[string]bool{stageEnrich: true, stagePolicy: true})
+	assert.False(t, conf.suggest)
+	require.NoError(t, runAudit(context.Background(), conf))
+	report, err = loadAuditReport(conf.output)
+	require.NoError(t, err)
+	assert.NotZero(t, report.Blocked)
+	assert.Empty(t, report.Suggestions)
+	assert.Equal(t, len(report.Results), int(requests.Load()))
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	platformsFlag = "platforms"
+
+	dependencyTypeOptional = "optional"
+)
+
+// Matches the <os>-<cpu> token of per-platform packages such as @esbuild/linux-x64 or @rollup/rollup-linux-x64-musl
+var platformTokenPattern = regexp.MustCompile(`(?:^|[-/])((?:aix|android|darwin|freebsd|linux|netbsd|openbsd|openharmony|sunos|win32)-(?:arm|arm64|ia32|loong64|mips64el|ppc64|riscv64|s390x|x64))(?:-|$)`)
+
+// Matches exact versions, the only kind per-platform packages are pinned to
+var exactVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(?:[-+][0-9A-Za-z.+-]+)?$`)
+
+// parsePlatforms parses a comma separated list of <os>-<cpu> platforms, e.g. linux-x64,darwin-arm64
+func parsePlatforms(value string) ([]string, error) {
+	var platforms []string
+	for _, platform := range splitList(value) {
+		if match := platformTokenPattern.FindStringSubmatch(platform); match == nil || match[1] != platform {
+			return nil, fmt.Errorf("invalid platform '%s'. Expected <os>-<cpu>, e.g. linux-x64", platform)
+		}
+		platforms = append(platforms, platform)
+	}
+	return platforms, nil
+}
+
+// platformOf returns the <os>-<cpu> token of a per-platform package name, or an empty string
+func platformOf(packageName string) string {
+	if match := platformTokenPattern.FindStringSubmatch(packageName); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// platformVariants returns the per-platform optional packages of the requested platforms that aren't in the tree,
+// e.g. @esbuild/linux-x64 for a lockfile generated on macOS. Besides the optional dependencies recorded in the
+// lockfile, the ones declared in the registry metadata are used when fetchOptional is set, as lockfiles may only
+// record the variants of the platforms they were resolved for.
+func platformVariants(tree *audit.DependencyTree, platforms []string, fetchOptional func(name, version string) (map[string]string, error)) []audit.Dependency {
+	wanted := make(map[string]bool)
+	for _, platform := range platforms {
+		wanted[platform] = true
+	}
+
+	var packageNames []string
+	for packageName := range tree.Packages {
+		packageNames = append(packageNames, packageName)
+	}
+	sort.Strings(packageNames)
+
+	seen := make(map[string]bool)
+	var variants []audit.Dependency
+	for _, packageName := range packageNames {
+		info := tree.Packages[packageName]
+		if !hasPlatformVariants(info.OptionalDependencies) {
+			continue
+		}
+
+		declared := make(map[string]string)
+		for name, version := range info.OptionalDependencies {
+			declared[name] = version
+		}
+		if fetchOptional != nil {
+			optional, err := fetchOptional(packageName, info.Version)
+			if err != nil {
+				log.Warn(fmt.Sprintf("Could not read optional dependencies of %s@%s: %v", packageName, info.Version, err))
+			}
+			for name, version := range optional {
+				declared[name] = version
+			}
+		}
+
+		for name, version := range declared {
+			version = strings.TrimPrefix(version, "=")
+			if !wanted[platformOf(name)] || !exactVersionPattern.MatchString(version) {
+				continue
+			}
+			if existing, exists := tree.Packages[name]; exists && existing.Version == version {
+				continue
+			}
+			if key := cacheKey(name, version); !seen[key] {
+				seen[key] = true
+				variants = append(variants, audit.Dependency{Name: name, Version: version, Type: dependencyTypeOptional, Relationship: audit.RelationshipTransitive})
+			}
+		}
+	}
+
+	sort.Slice(variants, func(i, j int) bool {
+		return cacheKey(variants[i].Name, variants[i].Version) < cacheKey(variants[j].Name, variants[j].Version)
+	})
+	return variants
+}
+
+func hasPlatformVariants(optionalDependencies map[string]string) bool {
+	for name := range optionalDependencies {
+		if platformOf(name) != "" {
+			return true
+		}
+	}
+	return false
+}
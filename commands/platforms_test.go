@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePlatforms(t *testing.T) {
+	platforms, err := parsePlatforms("linux-x64, darwin-arm64")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"linux-x64", "darwin-arm64"}, platforms)
+
+	_, err = parsePlatforms("linux")
+	assert.ErrorContains(t, err, "invalid platform 'linux'")
+}
+
+func TestPlatformOf(t *testing.T) {
+	assert.Equal(t, "linux-x64", platformOf("@esbuild/linux-x64"))
+	assert.Equal(t, "linux-x64", platformOf("@rollup/rollup-linux-x64-musl"))
+	assert.Equal(t, "win32-ia32", platformOf("@next/swc-win32-ia32-msvc"))
+	assert.Equal(t, "", platformOf("linux-utils"))
+}
+
+func TestPlatformVariants(t *testing.T) {
+	tree := &audit.DependencyTree{Packages: map[string]audit.PackageInfo{
+		"esbuild": {Version: "0.19.0", OptionalDependencies: map[string]string{
+			"@esbuild/darwin-arm64": "0.19.0",
+		}},
+		"@esbuild/darwin-arm64": {Version: "0.19.0"},
+		"fsevents":              {Version: "2.3.3"},
+	}}
+	fetch := func(name, version string) (map[string]string, error) {
+		assert.Equal(t, "esbuild", name)
+		return map[string]string{
+			"@esbuild/darwin-arm64": "0.19.0",
+			"@esbuild/linux-x64":    "0.19.0",
+			"@esbuild/win32-x64":    "0.19.0",
+		}, nil
+	}
+
+	variants := platformVariants(tree, []string{"linux-x64", "darwin-arm64"}, fetch)
+	assert.Equal(t, []audit.Dependency{{Name: "@esbuild/linux-x64", Version: "0.19.0", Type: dependencyTypeOptional, Relationship: audit.RelationshipTransitive}}, variants)
+}
+
+func TestParsePnpmLockOptionalDependencies(t *testing.T) {
+	tree, err := audit.ParseLockFileData("pnpm-lock.yaml", []byte(`
+lockfileVersion: '9.0'
+packages:
+  esbuild@0.19.0:
+    resolution: {integrity: sha512-x}
+snapshots:
+  esbuild@0.19.0:
+    optionalDependencies:
+      '@esbuild/linux-x64': 0.19.0
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"@esbuild/linux-x64": "0.19.0"}, tree.Packages["esbuild"].OptionalDependencies)
+}
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	pprofFlag     = "pprof"
+	profilingFlag = "profile"
+
+	profileCPU = "cpu"
+	profileMem = "mem"
+)
+
+// profiling captures the profiles of a run, for reports of slow audits of large trees. A nil profiling captures
+// nothing.
+type profiling struct {
+	listener net.Listener
+	cpu      *os.File
+	memPath  string
+}
+
+func getProfilingFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			pprofFlag,
+			"Address to serve the net/http/pprof endpoints on while the command runs, e.g. localhost:6060",
+			components.WithHelpValue("address"),
+		),
+		components.NewStringFlag(
+			profilingFlag,
+			"Comma separated profiles to write when the command ends, as cpu=<path> and mem=<path>",
+			components.WithHelpValue("cpu=path,mem=path"),
+		),
+	}
+}
+
+// startProfiling starts the profiles of the flags. Stop the returned profiling when the command ends.
+func startProfiling(c *components.Context) (*profiling, error) {
+	paths, err := parseProfilePaths(c.GetStringFlagValue(profilingFlag))
+	if err != nil {
+		return nil, err
+	}
+	return newProfiling(c.GetStringFlagValue(pprofFlag), paths)
+}
+
+// newProfiling serves pprof on the address, if any, and starts the CPU profile of the paths, if any
+func newProfiling(address string, paths map[string]string) (p *profiling, err error) {
+	if address == "" && len(paths) == 0 {
+		return nil, nil
+	}
+
+	p = &profiling{memPath: paths[profileMem]}
+	if address != "" {
+		if p.listener, err = net.Listen("tcp", address); err != nil {
+			return nil, fmt.Errorf("error serving --%s: %v", pprofFlag, err)
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		go http.Serve(p.listener, mux)
+		log.Info(fmt.Sprintf("Serving pprof on http://%s/debug/pprof/", p.listener.Addr()))
+	}
+	if path := paths[profileCPU]; path != "" {
+		if p.cpu, err = os.Create(path); err != nil {
+			p.stop()
+			return nil, fmt.Errorf("error creating CPU profile: %v", err)
+		}
+		if err := runtimepprof.StartCPUProfile(p.cpu); err != nil {
+			p.cpu.Close()
+			p.cpu = nil
+			p.stop()
+			return nil, fmt.Errorf("error starting CPU profile: %v", err)
+		}
+	}
+	return p, nil
+}
+
+// parseProfilePaths reads the cpu=<path>,mem=<path> value of --profile
+func parseProfilePaths(value string) (map[string]string, error) {
+	paths := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		kind, path, found := strings.Cut(item, "=")
+		if !found || path == "" || (kind != profileCPU && kind != profileMem) {
+			return nil, fmt.Errorf("invalid --%s '%s'. Expected %s=<path> or %s=<path>", profilingFlag, item, profileCPU, profileMem)
+		}
+		paths[kind] = path
+	}
+	return paths, nil
+}
+
+// stop writes the profiles and stops serving pprof
+func (p *profiling) stop() {
+	if p == nil {
+		return
+	}
+	if p.cpu != nil {
+		runtimepprof.StopCPUProfile()
+		if err := p.cpu.Close(); err != nil {
+			log.Warn("Could not write the CPU profile:", err.Error())
+		} else {
+			log.Info("CPU profile saved to", p.cpu.Name())
+		}
+	}
+	if p.memPath != "" {
+		if err := writeHeapProfile(p.memPath); err != nil {
+			log.Warn("Could not write the memory profile:", err.Error())
+		} else {
+			log.Info("Memory profile saved to", p.memPath)
+		}
+	}
+	if p.listener != nil {
+		p.listener.Close()
+	}
+}
+
+func writeHeapProfile(path string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	// Collect garbage first, so the profile shows the live heap
+	runtime.GC()
+	if err := runtimepprof.WriteHeapProfile(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
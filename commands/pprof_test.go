@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProfilePaths(t *testing.T) {
+	paths, err := parseProfilePaths("cpu=cpu.pprof, mem=mem.pprof")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{profileCPU: "cpu.pprof", profileMem: "mem.pprof"}, paths)
+
+	for _, invalid := range []string{"cpu", "cpu=", "block=block.pprof"} {
+		_, err := parseProfilePaths(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestProfiling(t *testing.T) {
+	dir := t.TempDir()
+	paths := map[string]string{profileCPU: filepath.Join(dir, "cpu.pprof"), profileMem: filepath.Join(dir, "mem.pprof")}
+	profile, err := newProfiling("127.0.0.1:0", paths)
+	assert.NoError(t, err)
+
+	response, err := http.Get("http://" + profile.listener.Addr().String() + "/debug/pprof/cmdline")
+	assert.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	profile.stop()
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.NotZero(t, info.Size(), path)
+	}
+
+	none, err := newProfiling("", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, none)
+	none.stop()
+}
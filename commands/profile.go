@@ -0,0 +1,274 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	profileOutputFlag = "output"
+	sha256Flag        = "sha256"
+	digestFlag        = "digest"
+
+	profileEnv = "CA_EXTENSION_PROFILE"
+
+	profileMediaType = "application/vnd.ca-extension.profile.v1+yaml"
+)
+
+// Default location of the pulled profile, relative to the working directory
+var defaultProfilePath = filepath.Join(".ca-extension", "profile.yaml")
+
+// profiles caches the profiles read by flagOrEnv, by path
+var profiles sync.Map
+
+// CurationProfile represents a versioned, centrally managed configuration bundle. Settings are keyed by flag
+// name and apply when neither the flag nor its environment variable is set.
+type CurationProfile struct {
+	Name     string            `yaml:"name" json:"name"`
+	Version  string            `yaml:"version" json:"version"`
+	Settings map[string]string `yaml:"settings" json:"settings,omitempty"`
+}
+
+// profileLock records where the active profile was pulled from, next to the profile
+type profileLock struct {
+	Ref      string    `json:"ref"`
+	Name     string    `json:"name"`
+	Version  string    `json:"version"`
+	Digest   string    `json:"digest"`
+	PulledAt time.Time `json:"pulledAt"`
+}
+
+func GetProfileNamespace() components.Namespace {
+	return components.Namespace{
+		Name:        "profile",
+		Description: "Manages the curation profile, a versioned configuration bundle shared across repositories.",
+		Commands: []components.Command{
+			GetProfilePullCommand(),
+		},
+	}
+}
+
+func GetProfilePullCommand() components.Command {
+	return components.Command{
+		Name:        "pull",
+		Description: "Downloads a curation profile from Artifactory or an OCI registry and makes it the active profile.",
+		Arguments:   getProfilePullArguments(),
+		Flags:       getProfilePullFlags(),
+		EnvVars:     getRegistryEnvVars(),
+		Action: func(c *components.Context) error {
+			return profilePullCmd(c)
+		},
+	}
+}
+
+func getProfilePullArguments() []components.Argument {
+	return []components.Argument{
+		{
+			Name: "ref",
+			Description: "The profile to pull: oci://<host>/<repository>:<tag>, an http(s) URL, or <repo>/<path> in " +
+				"Artifactory, e.g. curation-profiles/acme/1.4.0/profile.yaml.",
+		},
+	}
+}
+
+func getProfilePullFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			artifactoryURLFlag,
+			"Artifactory or JFrog platform URL, required for <repo>/<path> references",
+			components.WithHelpValue("url"),
+		),
+		components.NewStringFlag(
+			accessTokenFlag,
+			"JFrog access token used to authenticate against Artifactory or the OCI registry",
+			components.WithHelpValue("token"),
+		),
+		components.NewStringFlag(
+			profileOutputFlag,
+			"Path to write the profile to",
+			components.WithStrDefaultValue(defaultProfilePath),
+		),
+		components.NewStringFlag(
+			sha256Flag,
+			"Expected SHA-256 digest of the profile, pinning the exact content",
+			components.WithHelpValue("digest"),
+		),
+		components.NewStringFlag(
+			digestFlag,
+			"Expected digest of the profile as <algorithm>:<hex>, e.g. sha512:<hex>, pinning the exact content",
+			components.WithHelpValue("digest"),
+		),
+		getDigestAlgorithmFlag(),
+	}
+}
+
+func profilePullCmd(c *components.Context) error {
+	if len(c.Arguments) != 1 {
+		return wrongArguments("profile pull", "<ref>", len(c.Arguments))
+	}
+	ref := c.Arguments[0]
+	outputPath := c.GetStringFlagValue(profileOutputFlag)
+	if outputPath == "" {
+		outputPath = defaultProfilePath
+	}
+
+	data, err := downloadProfile(ref, flagOrEnv(c, artifactoryURLFlag, artifactoryURLEnv), flagOrEnv(c, accessTokenFlag, accessTokenEnv))
+	if err != nil {
+		return fmt.Errorf("error pulling profile %s: %v", ref, err)
+	}
+	algorithm, err := getDigestAlgorithm(c)
+	if err != nil {
+		return err
+	}
+	// The lock records the digest of the pinned algorithm, if any
+	digest := digestOf(algorithm, data)
+	expected := c.GetStringFlagValue(digestFlag)
+	if pinned := c.GetStringFlagValue(sha256Flag); pinned != "" {
+		expected = digestSHA256 + ":" + strings.TrimPrefix(pinned, "sha256:")
+	}
+	if expected != "" {
+		if digest, err = verifyDigest(expected, data); err != nil {
+			return fmt.Errorf("profile %v", err)
+		}
+	}
+	profile, err := parseProfile(data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("error creating profile directory: %v", err)
+	}
+	if err := ioutil.WriteFile(outputPath, data, 0644); err != nil {
+		return fmt.Errorf("error writing profile: %v", err)
+	}
+	lock, err := json.MarshalIndent(profileLock{Ref: ref, Name: profile.Name, Version: profile.Version, Digest: digest, PulledAt: time.Now().UTC()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	if err := ioutil.WriteFile(profileLockPath(outputPath), lock, 0644); err != nil {
+		return fmt.Errorf("error writing profile lock: %v", err)
+	}
+	log.Info(fmt.Sprintf("Pulled profile %s %s (%s) to %s", profile.Name, profile.Version, digest, outputPath))
+	return nil
+}
+
+// downloadProfile reads the profile from an OCI registry, a URL, or a repository path in Artifactory
+func downloadProfile(ref, artifactoryURL, accessToken string) ([]byte, error) {
+	if strings.HasPrefix(ref, ociScheme) {
+		return pullOCIProfile(ref, accessToken)
+	}
+
+	profileURL := ref
+	if !strings.HasPrefix(ref, "http://") && !strings.HasPrefix(ref, "https://") {
+		if artifactoryURL == "" {
+			return nil, fmt.Errorf("--%s is required for repository paths", artifactoryURLFlag)
+		}
+		baseURL := strings.TrimSuffix(artifactoryURL, "/")
+		if !strings.HasSuffix(baseURL, "/artifactory") {
+			baseURL += "/artifactory"
+		}
+		profileURL = baseURL + "/" + strings.TrimPrefix(ref, "/")
+	}
+
+	req, err := http.NewRequest("GET", profileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response: %d", resp.StatusCode)
+	}
+	return ioutil.ReadAll(io.LimitReader(resp.Body, maxOCIArtifactSize))
+}
+
+// pullOCIProfile reads the profile layer of an OCI artifact
+func pullOCIProfile(ref, accessToken string) ([]byte, error) {
+	parsed, err := parseOCIReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	client := newOCIClient(parsed, accessToken, digestSHA256)
+	manifest, err := client.pullManifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == profileMediaType {
+			return client.pullBlob(layer.Digest)
+		}
+	}
+	return nil, fmt.Errorf("%s has no %s layer", ref, profileMediaType)
+}
+
+func parseProfile(data []byte) (*CurationProfile, error) {
+	var profile CurationProfile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("error parsing profile: %v", err)
+	}
+	if profile.Name == "" || profile.Version == "" {
+		return nil, errors.New("invalid profile: name and version are required")
+	}
+	return &profile, nil
+}
+
+func profileLockPath(profilePath string) string {
+	return strings.TrimSuffix(profilePath, filepath.Ext(profilePath)) + ".lock.json"
+}
+
+// profileSetting returns the setting of the active profile: the one at CA_EXTENSION_PROFILE, else the one
+// pulled into the working directory
+func profileSetting(name string) string {
+	path := os.Getenv(profileEnv)
+	if path == "" {
+		path = defaultProfilePath
+	}
+	cached, loaded := profiles.Load(path)
+	if !loaded {
+		cached, _ = profiles.LoadOrStore(path, loadProfile(path))
+	}
+	if profile := cached.(*CurationProfile); profile != nil {
+		return profile.Settings[name]
+	}
+	return ""
+}
+
+// loadProfile reads a profile, warning about and ignoring invalid ones
+func loadProfile(path string) *CurationProfile {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warn("Could not read the curation profile:", err.Error())
+		}
+		return nil
+	}
+	profile, err := parseProfile(data)
+	if err != nil {
+		log.Warn(fmt.Sprintf("Ignoring the curation profile at %s: %v", path, err))
+		return nil
+	}
+	return profile
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/stretchr/testify/assert"
+)
+
+const testProfile = "name: acme\nversion: 1.4.0\nsettings:\n  registry-url: https://acme.jfrog.io/artifactory/api/npm/npm-curated\n"
+
+func TestDownloadProfileFromArtifactory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/artifactory/curation-profiles/acme/1.4.0/profile.yaml", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Write([]byte(testProfile))
+	}))
+	defer server.Close()
+
+	data, err := downloadProfile("curation-profiles/acme/1.4.0/profile.yaml", server.URL+"/", "token")
+	assert.NoError(t, err)
+	assert.Equal(t, testProfile, string(data))
+
+	_, err = downloadProfile("curation-profiles/acme/1.4.0/profile.yaml", "", "")
+	assert.Error(t, err)
+}
+
+func TestDownloadProfileFromOCI(t *testing.T) {
+	digest := digestOf(digestSHA256, []byte(testProfile))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/curation/profile/manifests/1.4.0":
+			json.NewEncoder(w).Encode(ociManifest{
+				SchemaVersion: 2,
+				MediaType:     ociManifestMediaType,
+				Layers:        []ociDescriptor{{MediaType: profileMediaType, Digest: digest, Size: int64(len(testProfile))}},
+			})
+		case "/v2/curation/profile/blobs/" + digest:
+			w.Write([]byte(testProfile))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ref := "oci://" + strings.TrimPrefix(server.URL, "http://") + "/curation/profile:1.4.0"
+	data, err := downloadProfile(ref, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, testProfile, string(data))
+
+	_, err = downloadProfile(strings.Replace(ref, "1.4.0", "2.0.0", 1), "", "")
+	assert.Error(t, err)
+}
+
+func TestParseOCIReference(t *testing.T) {
+	ref, err := parseOCIReference("oci://localhost:5000/curation/profile:1.4.0")
+	assert.NoError(t, err)
+	assert.Equal(t, ociReference{host: "localhost:5000", repository: "curation/profile", reference: "1.4.0"}, ref)
+
+	ref, err = parseOCIReference("oci://acme.jfrog.io/profile@sha256:abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:abc", ref.reference)
+
+	_, err = parseOCIReference("oci://acme.jfrog.io/profile")
+	assert.Error(t, err)
+}
+
+func TestParseProfileRequiresNameAndVersion(t *testing.T) {
+	profile, err := parseProfile([]byte(testProfile))
+	assert.NoError(t, err)
+	assert.Equal(t, &CurationProfile{Name: "acme", Version: "1.4.0", Settings: map[string]string{registryURLFlag: "https://acme.jfrog.io/artifactory/api/npm/npm-curated"}}, profile)
+
+	_, err = parseProfile([]byte("name: acme\n"))
+	assert.Error(t, err)
+}
+
+func TestFlagOrEnvFallsBackToProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(testProfile), 0644))
+	t.Setenv(profileEnv, path)
+	t.Setenv(registryURLEnv, "")
+
+	c := &components.Context{}
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/api/npm/npm-curated", flagOrEnv(c, registryURLFlag, registryURLEnv))
+	t.Setenv(registryURLEnv, "https://registry.example.com")
+	assert.Equal(t, "https://registry.example.com", flagOrEnv(c, registryURLFlag, registryURLEnv))
+}
+
+func TestProfileLockPath(t *testing.T) {
+	assert.Equal(t, filepath.Join(".ca-extension", "profile.lock.json"), profileLockPath(defaultProfilePath))
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	projectFlag = "project"
+
+	projectEnv = "CA_EXTENSION_PROJECT"
+
+	// projectHeader selects the JFrog project of the requests to project-scoped repositories
+	projectHeader = "X-JFrog-Project"
+
+	// Prefix of the scopes of access tokens granting the roles of a project, as
+	// applied-permissions/roles:<project>:<role>,<role>
+	projectRolesScope = "applied-permissions/roles:"
+)
+
+// Matches JFrog project keys: lowercase letters, digits and hyphens, starting with a letter
+var projectKeyPattern = regexp.MustCompile("^[a-z][a-z0-9-]{1,31}$")
+
+func getProjectFlag() components.Flag {
+	return components.NewStringFlag(
+		projectFlag,
+		"Key of the JFrog project the repository belongs to, sent as the "+projectHeader+" header of registry requests. Defaults to the project a project-scoped access token is restricted to",
+		components.WithHelpValue("key"),
+	)
+}
+
+// resolveProject returns the project of the registry requests: the --project checked against the projects a
+// project-scoped access token grants roles in, else the single project of such a token. Without a matching project,
+// Artifactory answers 403 to every request of the repositories of the project, as it does for blocked packages.
+func resolveProject(project, accessToken string) (string, error) {
+	if project != "" && !projectKeyPattern.MatchString(project) {
+		return "", fmt.Errorf("invalid --%s '%s'. Expected a JFrog project key of 2 to 32 lowercase letters, digits and hyphens, starting with a letter", projectFlag, project)
+	}
+	scoped := tokenProjects(accessToken)
+	if project == "" {
+		if len(scoped) == 1 {
+			log.Debug(fmt.Sprintf("Sending %s: %s, the project the access token is scoped to", projectHeader, scoped[0]))
+			return scoped[0], nil
+		}
+		return "", nil
+	}
+	if len(scoped) > 0 && !slices.Contains(scoped, project) {
+		return "", fmt.Errorf("the access token is scoped to the %s projects, not --%s=%s: Artifactory would deny every request. Use a token of the project", strings.Join(scoped, ", "), projectFlag, project)
+	}
+	return project, nil
+}
+
+// tokenProjects returns the projects an access token grants roles in, from the scope of its JWT. Reference tokens,
+// API keys, and tokens of users rather than of project roles have none.
+func tokenProjects(accessToken string) []string {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil
+	}
+	var claims struct {
+		Scope string `json:"scp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil
+	}
+	var projects []string
+	for _, scope := range strings.Fields(claims.Scope) {
+		roles, found := strings.CutPrefix(scope, projectRolesScope)
+		if !found {
+			continue
+		}
+		if project, _, _ := strings.Cut(roles, ":"); project != "" && !slices.Contains(projects, project) {
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
+
+// projectTransport adds the project header to the requests to the registry hosts, and not to those following
+// redirects elsewhere, e.g. to a CDN
+type projectTransport struct {
+	base     http.RoundTripper
+	registry *registryConfiguration
+}
+
+func (t *projectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.registry.isRegistryHost(strings.ToLower(req.URL.Hostname())) || req.Header.Get(projectHeader) != "" {
+		return t.base.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+	req.Header.Set(projectHeader, t.registry.project)
+	return t.base.RoundTrip(req)
+}
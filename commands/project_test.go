@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accessTokenWithScope returns an unsigned JWT of the scope, as much of an access token as is read
+func accessTokenWithScope(scope string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"jfac@01h/users/ci","scp":"` + scope + `"}`))
+	return "eyJhbGciOiJSUzI1NiJ9." + payload + ".signature"
+}
+
+func TestResolveProject(t *testing.T) {
+	project, err := resolveProject("", "")
+	require.NoError(t, err)
+	assert.Empty(t, project)
+	project, err = resolveProject("webapp", "reference-token")
+	require.NoError(t, err)
+	assert.Equal(t, "webapp", project)
+
+	// The project of a project-scoped token is sent by default
+	scoped := accessTokenWithScope("applied-permissions/roles:webapp:Developer,Viewer")
+	project, err = resolveProject("", scoped)
+	require.NoError(t, err)
+	assert.Equal(t, "webapp", project)
+	_, err = resolveProject("payments", scoped)
+	assert.EqualError(t, err, "the access token is scoped to the webapp projects, not --project=payments: Artifactory would deny every request. Use a token of the project")
+
+	// Tokens of several projects, or of users, don't select one
+	multiple := accessTokenWithScope("applied-permissions/roles:webapp:Developer applied-permissions/roles:payments:Viewer")
+	assert.Equal(t, []string{"webapp", "payments"}, tokenProjects(multiple))
+	project, err = resolveProject("", multiple)
+	require.NoError(t, err)
+	assert.Empty(t, project)
+	assert.Empty(t, tokenProjects(accessTokenWithScope("applied-permissions/user")))
+
+	_, err = resolveProject("Web App", "")
+	assert.ErrorContains(t, err, "invalid --project 'Web App'")
+}
+
+func TestProjectTransport(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(projectHeader))
+	}))
+	defer server.Close()
+
+	registry := &registryConfiguration{registryURL: server.URL + "/artifactory/api/npm/webapp-npm-remote", project: "webapp"}
+	req, err := http.NewRequest("GET", server.URL+"/artifactory/api/npm/webapp-npm-remote/lodash", nil)
+	require.NoError(t, err)
+	resp, err := registry.httpClient(0).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	// The request of the caller is left untouched
+	assert.Empty(t, req.Header)
+
+	// Hosts other than those of the registry don't get the project
+	other := &registryConfiguration{registryURL: strings.Replace(server.URL, "127.0.0.1", "localhost", 1), project: "webapp"}
+	resp, err = other.httpClient(0).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"webapp", ""}, received)
+}
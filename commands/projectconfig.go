@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	projectConfigFileName = ".caextension.yaml"
+
+	// projectConfigEnv points at the project config file to read instead of searching for one
+	projectConfigEnv = "CA_EXTENSION_CONFIG"
+
+	// projectConfigIgnoreSection lists known-accepted packages, read along with the ignore file
+	projectConfigIgnoreSection = "ignore"
+)
+
+// projectConfigs caches the project config files read by flagOrEnv, by the directory they were searched from
+var projectConfigs sync.Map
+
+// ProjectConfig represents a .caextension.yaml, committed with a project so CI jobs don't repeat long command
+// lines. Settings are keyed by flag name, as in curation profiles, and apply when the flag is not set.
+type ProjectConfig struct {
+	Path     string
+	Settings map[string]string
+	// Ignore is the ignore section, of known-accepted packages, as in ignore files
+	Ignore []ignoreRule
+}
+
+// Flags never read from project config files, which are committed with the code
+var projectConfigSecrets = map[string]bool{accessTokenFlag: true, ociTokenFlag: true}
+
+// Opt-ins of the user running the command, never read from project config files or profiles
+var projectConfigOptIns = map[string]bool{telemetryEndpointFlag: true}
+
+// flagOrConfig returns the flag value, falling back to the project config file and then the active profile
+func flagOrConfig(c *components.Context, flagName string) string {
+	if value := c.GetStringFlagValue(flagName); value != "" {
+		return value
+	}
+	return configSetting(flagName)
+}
+
+// configSetting returns the setting of the project config file, falling back to the active profile
+func configSetting(name string) string {
+	if config := activeProjectConfig(); config != nil {
+		if value := config.Settings[name]; value != "" {
+			return value
+		}
+	}
+	return profileSetting(name)
+}
+
+// activeProjectConfig returns the project config file at CA_EXTENSION_CONFIG, else the nearest .caextension.yaml
+// of the working directory and its parents, or nil when there is none
+func activeProjectConfig() *ProjectConfig {
+	start := os.Getenv(projectConfigEnv)
+	if start == "" {
+		start, _ = os.Getwd()
+	}
+	cached, loaded := projectConfigs.Load(start)
+	if !loaded {
+		var config *ProjectConfig
+		if path := os.Getenv(projectConfigEnv); path != "" {
+			config = loadProjectConfig(path)
+		} else if path := findProjectConfig(start); path != "" {
+			config = loadProjectConfig(path)
+		}
+		cached, _ = projectConfigs.LoadOrStore(start, config)
+	}
+	return cached.(*ProjectConfig)
+}
+
+// findProjectConfig returns the nearest .caextension.yaml of a directory and its parents
+func findProjectConfig(dir string) string {
+	for dir != "" {
+		path := filepath.Join(dir, projectConfigFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+	return ""
+}
+
+// loadProjectConfig reads a project config file, warning about and ignoring invalid ones
+func loadProjectConfig(path string) *ProjectConfig {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Warn("Could not read the project config file:", err.Error())
+		return nil
+	}
+	config, warnings, err := parseProjectConfig(path, data)
+	if err != nil {
+		log.Warn(fmt.Sprintf("Ignoring the project config file %s: %v", path, err))
+		return nil
+	}
+	for _, warning := range warnings {
+		log.Warn(warning)
+	}
+	log.Debug("Using the project config file", path)
+	return config
+}
+
+// parseProjectConfig reads the settings of a project config file. Values may be scalars or lists, which are
+// joined as the comma separated values of flags. Settings of unknown flags and secrets are skipped with a warning.
+func parseProjectConfig(path string, data []byte) (*ProjectConfig, []string, error) {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, nil, fmt.Errorf("invalid YAML: %v", err)
+	}
+	var sections struct {
+		Ignore []ignoreRule `yaml:"ignore"`
+	}
+	if err := yaml.Unmarshal(data, &sections); err != nil {
+		return nil, nil, fmt.Errorf("invalid ignore section: %v", err)
+	}
+	config := &ProjectConfig{Path: path, Settings: make(map[string]string, len(raw)), Ignore: sections.Ignore}
+	known := knownFlagNames()
+	var warnings []string
+	for _, name := range sortedKeys(raw) {
+		if name == projectConfigIgnoreSection {
+			continue
+		}
+		if projectConfigSecrets[name] {
+			warnings = append(warnings, fmt.Sprintf("Skipping %s of %s: secrets aren't read from project config files, set it on the command line or in the environment", name, path))
+			continue
+		}
+		if projectConfigOptIns[name] {
+			warnings = append(warnings, fmt.Sprintf("Skipping %s of %s: opt-ins aren't read from project config files, set it on the command line or in the environment", name, path))
+			continue
+		}
+		if !known[name] {
+			warnings = append(warnings, fmt.Sprintf("Skipping %s of %s: no command has a --%s flag", name, path, name))
+			continue
+		}
+		switch value := raw[name].(type) {
+		case nil:
+		case []interface{}:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			config.Settings[name] = strings.Join(items, ",")
+		case map[string]interface{}:
+			return nil, nil, fmt.Errorf("%s must be a value or a list, not a mapping", name)
+		default:
+			config.Settings[name] = fmt.Sprint(value)
+		}
+	}
+	return config, warnings, nil
+}
+
+// knownFlagNames returns the names of the flags of every command
+func knownFlagNames() map[string]bool {
+	commands := GetCommands()
+	for _, namespace := range GetNamespaces() {
+		commands = append(commands, namespace.Commands...)
+	}
+	names := make(map[string]bool)
+	for _, command := range commands {
+		for _, flag := range command.Flags {
+			names[flag.GetName()] = true
+		}
+	}
+	return names
+}
+
+// describeProjectConfig lists the settings of the active project config file, for the config command
+func describeProjectConfig() string {
+	config := activeProjectConfig()
+	if config == nil {
+		return ""
+	}
+	names := sortedKeys(config.Settings)
+	if len(config.Ignore) > 0 {
+		names = append(names, fmt.Sprintf("%s: %d rules", projectConfigIgnoreSection, len(config.Ignore)))
+	}
+	return fmt.Sprintf("config-file: %s (%s)\n", config.Path, strings.Join(names, ", "))
+}
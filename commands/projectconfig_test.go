@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProjectConfig(t *testing.T) {
+	data := []byte("repo: npm-curated\nworkers: 8\nfail-on: any\nredirect-hosts:\n  - cdn.acme.io\n  - mirror.acme.io\naccess-token: secret\ncolour: blue\ntelemetry-endpoint: https://telemetry.acme.io\ntree-output:\n")
+	config, warnings, err := parseProjectConfig(".caextension.yaml", data)
+	assert.NoError(t, err)
+	assert.Equal(t, &ProjectConfig{Path: ".caextension.yaml", Settings: map[string]string{
+		repoFlag:          "npm-curated",
+		workersFlag:       "8",
+		failOnFlag:        failOnAny,
+		redirectHostsFlag: "cdn.acme.io,mirror.acme.io",
+	}}, config)
+	assert.Len(t, warnings, 3)
+	assert.Contains(t, warnings[0], accessTokenFlag)
+	assert.Contains(t, warnings[1], "--colour")
+	assert.Contains(t, warnings[2], "telemetry-endpoint of .caextension.yaml: opt-ins aren't read")
+
+	config, warnings, err = parseProjectConfig(".caextension.yaml", []byte("ignore:\n  - package: lodash@4.17.*\n    reason: SEC-1234\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, []ignoreRule{{Package: "lodash@4.17.*", Reason: "SEC-1234"}}, config.Ignore)
+
+	_, _, err = parseProjectConfig(".caextension.yaml", []byte("repo:\n  name: npm-curated\n"))
+	assert.EqualError(t, err, "repo must be a value or a list, not a mapping")
+	_, _, err = parseProjectConfig(".caextension.yaml", []byte("- repo\n"))
+	assert.Error(t, err)
+}
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "apps", "web")
+	assert.NoError(t, os.MkdirAll(nested, 0755))
+	assert.Equal(t, "", findProjectConfig(nested))
+
+	path := filepath.Join(root, projectConfigFileName)
+	assert.NoError(t, os.WriteFile(path, []byte("repo: npm-curated\n"), 0644))
+	assert.Equal(t, path, findProjectConfig(nested))
+	assert.Equal(t, path, findProjectConfig(root))
+}
+
+func TestFlagOrEnvFallsBackToProjectConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("registry-url: https://acme.jfrog.io/artifactory/api/npm/npm-project\nworkers: 3\n"), 0644))
+	t.Setenv(projectConfigEnv, path)
+	t.Setenv(profileEnv, filepath.Join(t.TempDir(), "profile.yaml"))
+	t.Setenv(registryURLEnv, "")
+
+	c := &components.Context{}
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/api/npm/npm-project", flagOrEnv(c, registryURLFlag, registryURLEnv))
+	t.Setenv(registryURLEnv, "https://registry.example.com")
+	assert.Equal(t, "https://registry.example.com", flagOrEnv(c, registryURLFlag, registryURLEnv))
+
+	workers, err := getWorkers(c)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, workers)
+	assert.Equal(t, "config-file: "+path+" (registry-url, workers)\n", describeProjectConfig())
+}
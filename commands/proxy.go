@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	listenFlag = "listen"
+
+	defaultProxyAddress = "127.0.0.1:4873"
+
+	// Recorded outcomes are saved in batches, at the latest every interval and when the proxy stops, rather than on
+	// every download of an install
+	proxySaveBatch    = 100
+	proxySaveInterval = 10 * time.Second
+)
+
+func GetProxyCommand() components.Command {
+	return components.Command{
+		Name:        "proxy",
+		Description: "Runs a local proxy in front of the curated registry that records the curation outcomes of regular installs into the audit cache.",
+		Flags:       getProxyFlags(),
+		EnvVars:     getRegistryEnvVars(),
+		Action: func(c *components.Context) error {
+			return proxyCmd(c)
+		},
+	}
+}
+
+func getProxyFlags() []components.Flag {
+	return append(getRegistryFlags(),
+		getCacheFileFlag(),
+		getCacheTTLFlag(),
+		components.NewStringFlag(
+			listenFlag,
+			"Address to listen on. Point the package manager's registry to it, e.g. pnpm config set registry http://"+defaultProxyAddress,
+			components.WithStrDefaultValue(defaultProxyAddress),
+		),
+	)
+}
+
+func proxyCmd(c *components.Context) error {
+	if len(c.Arguments) != 0 {
+		return wrongArguments("proxy", "", len(c.Arguments))
+	}
+	registry, err := getRegistryConfiguration(c)
+	if err != nil {
+		return err
+	}
+	cache, err := openOutcomeCache(c)
+	if err != nil {
+		return err
+	}
+	handler, err := newRecordingProxy(registry, cache)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	server := &http.Server{Addr: c.GetStringFlagValue(listenFlag), Handler: handler}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		saveOutcomes(ctx, cache, proxySaveInterval)
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancelShutdown()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Warn("Could not shut the proxy down gracefully:", err.Error())
+		}
+	}()
+
+	log.Info(fmt.Sprintf("Proxying %s on http://%s, recording curation outcomes to %s", registry.registryURL, server.Addr, cache.path))
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error serving %s: %v", server.Addr, err)
+	}
+	// The outcomes of the requests served until the shutdown are saved once they are all answered
+	<-stopped
+	if err := cache.flush(1); err != nil {
+		return err
+	}
+	log.Info("Proxy stopped")
+	return nil
+}
+
+// saveOutcomes saves the outcomes recorded since the last save every interval, until the context is done
+func saveOutcomes(ctx context.Context, cache *outcomeCache, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := cache.flush(1); err != nil {
+				log.Warn(err.Error())
+			}
+		}
+	}
+}
+
+// newRecordingProxy forwards every request to the registry, authenticating with the configured token,
+// and records the status of tarball downloads in the cache, saving it every proxySaveBatch outcomes. With a read replica selected, reads are forwarded to
+// it and publishes to the registry URL.
+func newRecordingProxy(registry *registryConfiguration, cache *outcomeCache) (http.Handler, error) {
+	target, err := url.Parse(registry.registryURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry URL %s: %v", registry.registryURL, err)
+	}
+	publishTarget, err := url.Parse(registry.publishEndpoint())
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry URL %s: %v", registry.publishEndpoint(), err)
+	}
+
+	proxy := newRegistryReverseProxy(registry, target)
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		name, version, ok := parseTarballPath(strings.TrimPrefix(resp.Request.URL.Path, target.Path))
+		if !ok {
+			return nil
+		}
+		result := audit.AuditResult{Name: name, Version: version, Status: audit.StatusForCode(resp.StatusCode), StatusCode: resp.StatusCode}
+		if !cache.record(registry, audit.Dependency{Name: name, Version: version}, result) {
+			return nil
+		}
+		if err := cache.flush(proxySaveBatch); err != nil {
+			log.Warn(err.Error())
+		}
+		log.Debug(fmt.Sprintf("Recorded %s@%s: %d", name, version, resp.StatusCode))
+		return nil
+	}
+	if *publishTarget == *target {
+		return proxy, nil
+	}
+	publishProxy := newRegistryReverseProxy(registry, publishTarget)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			proxy.ServeHTTP(w, r)
+			return
+		}
+		publishProxy.ServeHTTP(w, r)
+	}), nil
+}
+
+// newRegistryReverseProxy forwards requests to a registry endpoint, authenticating with the configured token
+func newRegistryReverseProxy(registry *registryConfiguration, target *url.URL) *httputil.ReverseProxy {
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = registry.transport()
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = target.Host
+		registry.credentials().Authorize(req)
+	}
+	return proxy
+}
+
+// parseTarballPath extracts the package name and version from a tarball path such as
+// /@scope/package/-/package-1.0.0.tgz
+func parseTarballPath(tarballPath string) (string, string, bool) {
+	unescaped, err := url.PathUnescape(tarballPath)
+	if err != nil {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(unescaped, "/"), "/-/", 2)
+	if len(parts) != 2 || !strings.HasSuffix(parts[1], ".tgz") {
+		return "", "", false
+	}
+
+	name := parts[0]
+	prefix := path.Base(name) + "-"
+	if !strings.HasPrefix(parts[1], prefix) {
+		return "", "", false
+	}
+	version := strings.TrimSuffix(strings.TrimPrefix(parts[1], prefix), ".tgz")
+	if name == "" || version == "" {
+		return "", "", false
+	}
+	return name, version, true
+}
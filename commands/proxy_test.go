@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTarballPath(t *testing.T) {
+	name, version, ok := parseTarballPath("/@types/keyv/-/keyv-3.1.4.tgz")
+	assert.True(t, ok)
+	assert.Equal(t, "@types/keyv", name)
+	assert.Equal(t, "3.1.4", version)
+
+	name, version, ok = parseTarballPath("/%40types%2fkeyv/-/keyv-3.1.4.tgz")
+	assert.True(t, ok)
+	assert.Equal(t, "@types/keyv", name)
+	assert.Equal(t, "3.1.4", version)
+
+	_, _, ok = parseTarballPath("/abbrev")
+	assert.False(t, ok)
+}
+
+func TestRecordingProxy(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		if r.URL.Path == "/api/npm/npm-remote/yallist/-/yallist-4.0.0.tgz" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer registry.Close()
+
+	cache, err := loadOutcomeCache(filepath.Join(t.TempDir(), "outcomes.json"))
+	assert.NoError(t, err)
+	upstream := &registryConfiguration{registryURL: registry.URL + "/api/npm/npm-remote", accessToken: "token"}
+	handler, err := newRecordingProxy(upstream, cache)
+	assert.NoError(t, err)
+	proxy := httptest.NewServer(handler)
+	defer proxy.Close()
+
+	for _, path := range []string{"/yallist", "/yallist/-/yallist-4.0.0.tgz", "/abbrev/-/abbrev-1.1.1.tgz"} {
+		resp, err := http.Get(proxy.URL + path)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// The outcomes are saved in batches, and when the proxy stops
+	_, err = os.Stat(cache.path)
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, cache.flush(1))
+	reloaded, err := loadOutcomeCache(cache.path)
+	assert.NoError(t, err)
+	assert.Len(t, reloaded.Outcomes, 2)
+	yallist, _ := reloaded.lookup(upstream, audit.Dependency{Name: "yallist", Version: "4.0.0"})
+	assert.Equal(t, http.StatusForbidden, yallist.StatusCode)
+	abbrev, _ := reloaded.lookup(upstream, audit.Dependency{Name: "abbrev", Version: "1.1.1"})
+	assert.Equal(t, http.StatusOK, abbrev.StatusCode)
+}
+
+func TestAuditWithCache(t *testing.T) {
+	cache, err := loadOutcomeCache(filepath.Join(t.TempDir(), "outcomes.json"))
+	assert.NoError(t, err)
+	cache.record(testCacheRegistry, audit.Dependency{Name: "abbrev", Version: "1.1.1"}, audit.AuditResult{StatusCode: http.StatusForbidden, Status: audit.StatusForCode(http.StatusForbidden)})
+
+	deps := []audit.Dependency{{Name: "abbrev", Version: "1.1.1"}, {Name: "yallist", Version: "4.0.0"}}
+	var audited []audit.Dependency
+	results := auditWithCache(deps, cache, testCacheRegistry, func(missing []audit.Dependency) []audit.AuditResult {
+		audited = missing
+		return []audit.AuditResult{{Name: "yallist", Version: "4.0.0", StatusCode: http.StatusOK}}
+	}, nil)
+
+	assert.Equal(t, []audit.Dependency{{Name: "yallist", Version: "4.0.0"}}, audited)
+	assert.Equal(t, http.StatusForbidden, results[0].StatusCode)
+	assert.Equal(t, "❌ Blocked (403 Forbidden) (cached)", results[0].Status)
+	assert.Equal(t, http.StatusOK, results[1].StatusCode)
+	_, cached := cache.lookup(testCacheRegistry, audit.Dependency{Name: "yallist", Version: "4.0.0"})
+	assert.True(t, cached)
+}
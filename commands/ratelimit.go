@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+const (
+	rateLimitFlag = "rate-limit"
+	rateLimitEnv  = "CA_EXTENSION_RATE_LIMIT"
+)
+
+// rateLimiter is a token bucket holding up to one request token, refilled at the rate limit. It is shared by the
+// registry requests of all workers. A nil limiter doesn't limit.
+type rateLimiter struct {
+	rate     float64
+	interval time.Duration
+	now      func() time.Time
+
+	mu sync.Mutex
+	// Tokens left at last, negative for the requests waiting for theirs
+	tokens float64
+	last   time.Time
+	// Requests that waited for a token, and their total wait
+	delayed int
+	waited  time.Duration
+}
+
+func getRateLimitFlag() components.Flag {
+	return components.NewStringFlag(
+		rateLimitFlag,
+		"Maximum registry requests per second, shared by all workers, e.g. 20 or 0.5, to stay under Artifactory or npmjs throttling. Defaults to no limit",
+		components.WithHelpValue("requests/s"),
+	)
+}
+
+func parseRateLimit(value string) (*rateLimiter, error) {
+	if value == "" {
+		return nil, nil
+	}
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate <= 0 {
+		return nil, fmt.Errorf("--%s must be a positive number of requests per second, got '%s'", rateLimitFlag, value)
+	}
+	return newRateLimiter(rate, time.Now), nil
+}
+
+func newRateLimiter(rate float64, now func() time.Time) *rateLimiter {
+	return &rateLimiter{
+		rate:     rate,
+		interval: time.Duration(float64(time.Second) / rate),
+		now:      now,
+		tokens:   1,
+		last:     now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait for it
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.tokens = min(1, l.tokens+float64(now.Sub(l.last))/float64(l.interval))
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	wait := time.Duration(-l.tokens * float64(l.interval))
+	l.delayed++
+	l.waited += wait
+	return wait
+}
+
+// summary describes how much the limit slowed the run, or is empty when it didn't
+func (l *rateLimiter) summary(display *displayFormat) string {
+	if l == nil {
+		return ""
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.delayed == 0 {
+		return ""
+	}
+	return fmt.Sprintf("The rate limit of %s requests/s delayed %s requests by %s in total",
+		strconv.FormatFloat(l.rate, 'f', -1, 64), display.count(l.delayed), display.duration(l.waited))
+}
+
+// rateLimitedTransport waits for a token of the limiter before each request
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *rateLimiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := t.limiter.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	limiter, err := parseRateLimit("")
+	assert.NoError(t, err)
+	assert.Nil(t, limiter)
+
+	limiter, err = parseRateLimit("0.5")
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Second, limiter.interval)
+
+	_, err = parseRateLimit("0")
+	assert.ErrorContains(t, err, "--rate-limit must be a positive number of requests per second, got '0'")
+}
+
+func TestRateLimiterReserve(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(10, func() time.Time { return now })
+
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, 100*time.Millisecond, limiter.reserve())
+	assert.Equal(t, 200*time.Millisecond, limiter.reserve())
+
+	// Tokens refill at the rate, up to one
+	now = now.Add(time.Second)
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, 100*time.Millisecond, limiter.reserve())
+
+	assert.Equal(t, "The rate limit of 10 requests/s delayed 3 requests by 400ms in total", limiter.summary(newDisplayFormat(true)))
+	assert.Equal(t, "", (*rateLimiter)(nil).summary(newDisplayFormat(true)))
+}
+
+func TestRateLimitedTransportIsShared(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	limiter, err := parseRateLimit("50")
+	assert.NoError(t, err)
+	registry := &registryConfiguration{rateLimiter: limiter}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := registry.httpClient(5 * time.Second).Get(server.URL)
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	// The first request takes the token, the others wait 20ms each after it
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+	assert.Equal(t, 4, limiter.delayed)
+}
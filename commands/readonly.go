@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+const (
+	readOnlyFlag  = "read-only"
+	outputDirFlag = "output-dir"
+)
+
+// readOnlySandbox confines the files a --read-only run writes to its --output-dir, for audits of immutable,
+// attested build inputs. A nil sandbox lets the run write anywhere.
+type readOnlySandbox struct {
+	// dir is the absolute output directory, empty when the run may write no file at all
+	dir string
+}
+
+func getReadOnlyFlags() []components.Flag {
+	return []components.Flag{
+		components.NewBoolFlag(
+			readOnlyFlag,
+			"Write nothing outside the --"+outputDirFlag+": no dependency tree next to the lock file and no outcome cache. The run fails on the outputs it would write elsewhere",
+			components.WithBoolDefaultValue(false),
+		),
+		components.NewStringFlag(
+			outputDirFlag,
+			"Existing directory the outputs of a --"+readOnlyFlag+" run are confined to. Without it, the run writes no file",
+			components.WithHelpValue("dir"),
+		),
+	}
+}
+
+func getReadOnlySandbox(c *components.Context) (*readOnlySandbox, error) {
+	dir := c.GetStringFlagValue(outputDirFlag)
+	if !c.GetBoolFlagValue(readOnlyFlag) {
+		if dir != "" {
+			return nil, fmt.Errorf("--%s needs --%s", outputDirFlag, readOnlyFlag)
+		}
+		return nil, nil
+	}
+	return newReadOnlySandbox(dir)
+}
+
+func newReadOnlySandbox(dir string) (*readOnlySandbox, error) {
+	if dir == "" {
+		return &readOnlySandbox{}, nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading --%s: %v", outputDirFlag, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("--%s %s is not a directory", outputDirFlag, dir)
+	}
+	resolved, err := resolvePath(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading --%s: %v", outputDirFlag, err)
+	}
+	return &readOnlySandbox{dir: resolved}, nil
+}
+
+// allows tells whether the run may write the file at path
+func (s *readOnlySandbox) allows(path string) bool {
+	if s == nil {
+		return true
+	}
+	if s.dir == "" {
+		return false
+	}
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(s.dir, resolved)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// check fails when the file the flag writes, if any, is outside the output directory
+func (s *readOnlySandbox) check(flag, path string) error {
+	if path == "" || s.allows(path) {
+		return nil
+	}
+	if s.dir == "" {
+		return fmt.Errorf("--%s writes %s, which --%s forbids without an --%s", flag, path, readOnlyFlag, outputDirFlag)
+	}
+	return fmt.Errorf("--%s writes %s, outside the --%s %s of --%s", flag, path, outputDirFlag, s.dir, readOnlyFlag)
+}
+
+// defaultOutput returns the path of an output written by default, within the output directory. Outside the
+// sandbox it is fallback, and without an output directory the output isn't written.
+func (s *readOnlySandbox) defaultOutput(name, fallback string) string {
+	if s == nil {
+		return fallback
+	}
+	if s.dir == "" {
+		return ""
+	}
+	return filepath.Join(s.dir, name)
+}
+
+// tempDir returns the directory temporary files are created in: the output directory of the sandbox, or the
+// default one of the system outside it
+func (s *readOnlySandbox) tempDir() string {
+	if s == nil {
+		return ""
+	}
+	return s.dir
+}
+
+// resolvePath returns the absolute path with the symlinks of its existing directories resolved, so a link can't
+// point a write out of the sandbox
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	var missing []string
+	for {
+		resolved, err := filepath.EvalSymlinks(abs)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		if !os.IsNotExist(err) || filepath.Dir(abs) == abs {
+			return "", err
+		}
+		missing = append([]string{filepath.Base(abs)}, missing...)
+		abs = filepath.Dir(abs)
+	}
+}
+
+// checkReadOnlyOutputs fails on the outputs of an audit a --read-only run would write outside its output directory
+func checkReadOnlyOutputs(c *components.Context, conf *auditConfiguration) error {
+	if conf.sandbox == nil {
+		return nil
+	}
+	if conf.cache != nil {
+		if err := conf.sandbox.check(cacheFileFlag, conf.cache.path); err != nil {
+			return err
+		}
+	}
+	if conf.verifyLockfile && conf.sandbox.dir == "" {
+		return fmt.Errorf("--%s copies the project to a temporary directory, which --%s forbids without an --%s", verifyLockfileFlag, readOnlyFlag, outputDirFlag)
+	}
+	if conf.ociPush != nil && conf.treeOutput == "" {
+		return fmt.Errorf("--%s pushes the dependency tree, which --%s doesn't write without an --%s", ociPushFlag, readOnlyFlag, outputDirFlag)
+	}
+	outputs := map[string]string{
+		treeOutputFlag:        conf.treeOutput,
+		outputFlag:            conf.output,
+		indexFlag:             conf.index,
+		gitlabCodeQualityFlag: conf.codeQuality,
+	}
+	if conf.sbom != nil {
+		outputs[sbomOutputFlag] = conf.sbom.output
+	}
+	if conf.registry != nil {
+		outputs[recordFlag] = conf.registry.cassette.recordPath()
+	}
+	if conf.baseline != nil && conf.baseline.update {
+		outputs[baselineFlag] = conf.baseline.path
+	}
+	if conf.notifications != nil {
+		outputs[notifyConfigFlag] = conf.notifications.deadLetter
+	}
+	if target := c.GetStringFlagValue(streamFlag); !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		outputs[streamFlag] = target
+	}
+	for _, flag := range sortedKeys(outputs) {
+		if err := conf.sandbox.check(flag, outputs[flag]); err != nil {
+			return err
+		}
+	}
+	profiles, err := parseProfilePaths(c.GetStringFlagValue(profilingFlag))
+	if err != nil {
+		return err
+	}
+	for _, kind := range sortedKeys(profiles) {
+		if err := conf.sandbox.check(profilingFlag, profiles[kind]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnlySandbox(t *testing.T) {
+	root := t.TempDir()
+	out := filepath.Join(root, "out")
+	assert.NoError(t, os.Mkdir(out, 0755))
+	assert.NoError(t, os.Symlink(root, filepath.Join(out, "escape")))
+
+	sandbox, err := newReadOnlySandbox(out)
+	assert.NoError(t, err)
+	assert.True(t, sandbox.allows(filepath.Join(out, "results.json")))
+	assert.True(t, sandbox.allows(filepath.Join(out, "reports", "index.json")))
+	assert.False(t, sandbox.allows(out))
+	assert.False(t, sandbox.allows(filepath.Join(root, "pnpm_dependency_tree.json")))
+	assert.False(t, sandbox.allows(filepath.Join(out, "..", "results.json")))
+	assert.False(t, sandbox.allows(filepath.Join(out, "escape", "results.json")))
+	assert.Equal(t, filepath.Join(sandbox.dir, defaultTreeFileName), sandbox.defaultOutput(defaultTreeFileName, "pnpm_dependency_tree.json"))
+	assert.ErrorContains(t, sandbox.check(outputFlag, filepath.Join(root, "results.json")), "outside the --output-dir")
+
+	// Without an output directory, nothing is written
+	none, err := newReadOnlySandbox("")
+	assert.NoError(t, err)
+	assert.Equal(t, "", none.defaultOutput(defaultTreeFileName, "pnpm_dependency_tree.json"))
+	assert.ErrorContains(t, none.check(indexFlag, "index.json"), "forbids without an --output-dir")
+	assert.NoError(t, none.check(indexFlag, ""))
+
+	var unrestricted *readOnlySandbox
+	assert.Equal(t, "pnpm_dependency_tree.json", unrestricted.defaultOutput(defaultTreeFileName, "pnpm_dependency_tree.json"))
+	assert.NoError(t, unrestricted.check(outputFlag, filepath.Join(root, "results.json")))
+
+	_, err = newReadOnlySandbox(filepath.Join(root, "missing"))
+	assert.Error(t, err)
+}
+
+func TestCheckReadOnlyOutputs(t *testing.T) {
+	out := t.TempDir()
+	sandbox, err := newReadOnlySandbox(out)
+	assert.NoError(t, err)
+	conf := &auditConfiguration{
+		sandbox:    sandbox,
+		treeOutput: filepath.Join(out, defaultTreeFileName),
+		output:     filepath.Join(out, "results.json"),
+		baseline:   &auditBaseline{path: "curation-baseline.json"},
+	}
+	assert.NoError(t, checkReadOnlyOutputs(&components.Context{}, conf))
+
+	// Baselines are only written when recorded
+	conf.baseline.update = true
+	assert.ErrorContains(t, checkReadOnlyOutputs(&components.Context{}, conf), "--baseline writes curation-baseline.json")
+
+	conf.baseline = nil
+	conf.cache = &outcomeCache{path: "outcomes.json"}
+	assert.ErrorContains(t, checkReadOnlyOutputs(&components.Context{}, conf), "--cache-file writes outcomes.json")
+}
@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	reasonsFileFlag = "reasons-file"
+
+	reasonsFileEnv = "CA_EXTENSION_REASONS_FILE"
+)
+
+// Runs of characters other than letters and digits, which condition codes and names are compared without
+var reasonSeparatorPattern = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// builtinReasons explains the conditions of the curation policy templates, by code, of which the condition names
+// Artifactory returns are the normalized form too, e.g. "Malicious package" and malicious_package
+var builtinReasons = map[string]string{
+	"malicious_package":          "The package is known to be malicious, e.g. it steals credentials or installs malware. Remove it, and rotate the secrets of the machines that installed it.",
+	"cve_with_cvss_score":        "A vulnerability of this version has a CVSS score above the threshold of the policy. Upgrade to a version with the fix.",
+	"cvss_score":                 "A vulnerability of this version has a CVSS score above the threshold of the policy. Upgrade to a version with the fix.",
+	"cve_with_fix_version":       "This version has a vulnerability fixed in a later version. Upgrade to the fixed version.",
+	"immature_package":           "This version was published more recently than the policy allows, giving new releases time to be vetted. Use an older version, or wait before upgrading.",
+	"package_version_immaturity": "This version was published more recently than the policy allows, giving new releases time to be vetted. Use an older version, or wait before upgrading.",
+	"aged_package":               "The package has had no release for longer than the policy allows, so it is likely unmaintained. Look for a maintained alternative.",
+	"package_not_maintained":     "The package has had no release for longer than the policy allows, so it is likely unmaintained. Look for a maintained alternative.",
+	"operational_risk":           "The package has a high operational risk, e.g. few maintainers, rare releases or an end of life version. Look for a better supported alternative.",
+	"end_of_life":                "This version is end of life and gets no fixes anymore. Upgrade to a supported version.",
+	"banned_licenses":            "The license of this version isn't allowed by the policy. Use a package under an approved license, or request a waiver.",
+	"license_not_allowed":        "The license of this version isn't allowed by the policy. Use a package under an approved license, or request a waiver.",
+	"unknown_license":            "The license of this version couldn't be determined, and the policy only allows known licenses. Check the license with the maintainers, or request a waiver.",
+	"multiple_licenses":          "This version is under several licenses, which the policy doesn't allow. Request a waiver once the licenses are reviewed.",
+	"unpinned_dependency":        "The package is not pinned to an exact version, which the policy requires. Pin its version in the lock file.",
+}
+
+// reasonMapping explains the conditions of the policies blocking packages, from the reasons file, for the language
+// of the environment first, and then from the built-in explanations. A nil mapping only applies the built-in ones.
+type reasonMapping struct {
+	path string
+	// Explanations by normalized condition, of the language of the environment and regardless of language
+	localized map[string]string
+	reasons   map[string]string
+}
+
+// reasonsFile represents the reasons file. Conditions are matched whatever the case and separators, so the codes and
+// the names of the conditions, localized or not, can be mapped.
+type reasonsFile struct {
+	Reasons   map[string]string            `yaml:"reasons"`
+	Languages map[string]map[string]string `yaml:"languages"`
+}
+
+func getReasonsFileFlag() components.Flag {
+	return components.NewStringFlag(
+		reasonsFileFlag,
+		"Path of a YAML file mapping the condition codes or names of curation policies to the explanations printed for the packages they block, by language with LC_MESSAGES",
+		components.WithHelpValue("path"),
+	)
+}
+
+func getReasonMapping(c *components.Context) (*reasonMapping, error) {
+	return loadReasonMapping(flagOrEnv(c, reasonsFileFlag, reasonsFileEnv), messagesLanguages(localeEnv("LC_MESSAGES")))
+}
+
+// loadReasonMapping reads the reasons file, if any, taking the explanations of the first of the languages it has
+func loadReasonMapping(path string, languages []string) (*reasonMapping, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading reasons file: %v", err)
+	}
+	var file reasonsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing reasons file %s: %v", path, err)
+	}
+	mapping := &reasonMapping{path: path, reasons: normalizeReasons(file.Reasons)}
+	for _, language := range languages {
+		if reasons, ok := file.Languages[language]; ok {
+			mapping.localized = normalizeReasons(reasons)
+			break
+		}
+	}
+	return mapping, nil
+}
+
+// messagesLanguages returns the languages of a locale such as pt_BR.UTF-8, most specific first: pt_BR, then pt
+func messagesLanguages(locale string) []string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.ReplaceAll(locale, "-", "_")
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return nil
+	}
+	language, _, found := strings.Cut(locale, "_")
+	if !found {
+		return []string{strings.ToLower(language)}
+	}
+	return []string{locale, strings.ToLower(language)}
+}
+
+func normalizeReasons(reasons map[string]string) map[string]string {
+	normalized := make(map[string]string, len(reasons))
+	for condition, explanation := range reasons {
+		normalized[normalizeCondition(condition)] = strings.TrimSpace(explanation)
+	}
+	return normalized
+}
+
+// normalizeCondition returns the form conditions are compared in, lower case words joined by underscores, e.g.
+// malicious_package for "Malicious package"
+func normalizeCondition(condition string) string {
+	return strings.Trim(reasonSeparatorPattern.ReplaceAllString(strings.ToLower(condition), "_"), "_")
+}
+
+// explain returns the explanation of a condition, or an empty string for the unknown ones. The explanations of the
+// reasons file take precedence over the built-in ones.
+func (m *reasonMapping) explain(condition string) (explanation string, fromFile bool) {
+	key := normalizeCondition(condition)
+	if key == "" {
+		return "", false
+	}
+	if m != nil {
+		if explanation := m.localized[key]; explanation != "" {
+			return explanation, true
+		}
+		if explanation := m.reasons[key]; explanation != "" {
+			return explanation, true
+		}
+	}
+	return builtinReasons[key], false
+}
+
+// apply sets the explanations of the policies of the results. Those of the reasons file replace the explanation
+// Artifactory returned, the built-in ones only fill a missing or coded one, as the explanations of Artifactory tell
+// more, e.g. the CVE of a vulnerability.
+func (m *reasonMapping) apply(results []audit.AuditResult) {
+	for i, result := range results {
+		var policies []audit.CurationPolicy
+		for j, policy := range result.Policies {
+			explanation, fromFile := m.explain(policy.Condition)
+			if explanation == "" || (!fromFile && !isCodedReason(policy.Explanation)) {
+				continue
+			}
+			if policies == nil {
+				// The policies may be shared with the results of the cache
+				policies = append([]audit.CurationPolicy(nil), result.Policies...)
+			}
+			policies[j].Explanation = explanation
+		}
+		if policies != nil {
+			results[i].Policies = policies
+		}
+	}
+}
+
+// isCodedReason tells whether an explanation is missing or a code, such as malicious_package, rather than a sentence
+func isCodedReason(explanation string) bool {
+	return !strings.ContainsAny(strings.TrimSpace(explanation), " \t")
+}
+
+// printBlockingReasons lists the explanations of the policies blocking packages
+func printBlockingReasons(results []audit.AuditResult) {
+	printed := false
+	for _, result := range results {
+		for _, policy := range result.Policies {
+			if policy.Explanation == "" {
+				continue
+			}
+			if !printed {
+				fmt.Printf("\n\nWhy packages were blocked:")
+				printed = true
+			}
+			fmt.Printf("\n%s@%s (%s): %s", result.Name, result.Version, policy.Policy, policy.Explanation)
+			if policy.Recommendation != "" {
+				fmt.Printf(" %s", policy.Recommendation)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessagesLanguages(t *testing.T) {
+	assert.Equal(t, []string{"pt_BR", "pt"}, messagesLanguages("pt_BR.UTF-8"))
+	assert.Equal(t, []string{"de"}, messagesLanguages("de"))
+	assert.Nil(t, messagesLanguages("C.UTF-8"))
+	assert.Nil(t, messagesLanguages(""))
+}
+
+func TestReasonMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reasons.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`reasons:
+  Paquete malicioso: Flagged as malicious, see SEC-42 before using it.
+  banned-licenses: GPL is not allowed in our products, ask legal@acme.io.
+languages:
+  de:
+    malicious_package: Das Paket ist als Schadsoftware bekannt.
+`), 0644))
+
+	mapping, err := loadReasonMapping(path, []string{"de_AT", "de"})
+	require.NoError(t, err)
+	results := []audit.AuditResult{
+		{Name: "event-stream", Version: "3.3.6", Policies: []audit.CurationPolicy{{Policy: "block-malicious", Condition: "Malicious package", Explanation: "malicious_package"}}},
+		{Name: "left-pad", Version: "1.3.0", Policies: []audit.CurationPolicy{{Policy: "licenses", Condition: "Banned licenses", Explanation: "GPL-3.0 is banned"}}},
+		{Name: "colors", Version: "1.4.1", Policies: []audit.CurationPolicy{{Policy: "cvss", Condition: "CVE with CVSS score", Explanation: "CVE-2021-23567 has a CVSS score of 7.5"}}},
+		{Name: "ua-parser", Version: "0.7.29", Policies: []audit.CurationPolicy{{Policy: "spanish", Condition: "paquete_malicioso"}}},
+		{Name: "lodash", Version: "4.17.21", Policies: []audit.CurationPolicy{{Policy: "age", Condition: "immature_package"}}},
+	}
+	cached := results[1].Policies
+	mapping.apply(results)
+
+	// The language of the environment goes first, then the reasons of the file, replacing those of Artifactory
+	assert.Equal(t, "Das Paket ist als Schadsoftware bekannt.", results[0].Policies[0].Explanation)
+	assert.Equal(t, "GPL is not allowed in our products, ask legal@acme.io.", results[1].Policies[0].Explanation)
+	// The built-in reasons don't replace the explanations of Artifactory
+	assert.Equal(t, "CVE-2021-23567 has a CVSS score of 7.5", results[2].Policies[0].Explanation)
+	assert.Equal(t, "Flagged as malicious, see SEC-42 before using it.", results[3].Policies[0].Explanation)
+	assert.Equal(t, builtinReasons["immature_package"], results[4].Policies[0].Explanation)
+	// The policies shared with the cache are left as they are
+	assert.Equal(t, "GPL-3.0 is banned", cached[0].Explanation)
+
+	// Without a reasons file, only the built-in reasons apply
+	var none *reasonMapping
+	results = []audit.AuditResult{{Name: "event-stream", Version: "3.3.6", Policies: []audit.CurationPolicy{{Policy: "block-malicious", Condition: "MALICIOUS_PACKAGE"}}}}
+	none.apply(results)
+	assert.Equal(t, builtinReasons["malicious_package"], results[0].Policies[0].Explanation)
+
+	_, err = loadReasonMapping(filepath.Join(t.TempDir(), "missing.yaml"), nil)
+	assert.ErrorContains(t, err, "error reading reasons file")
+}
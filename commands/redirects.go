@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	redirectsFlag     = "redirects"
+	redirectHostsFlag = "redirect-hosts"
+
+	redirectsEnv     = "CA_EXTENSION_REDIRECTS"
+	redirectHostsEnv = "CA_EXTENSION_REDIRECT_HOSTS"
+
+	redirectsFollow = "follow"
+	redirectsNone   = "none"
+
+	maxRedirects = 10
+)
+
+func getRedirectFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			redirectsFlag,
+			"Redirect policy of registry requests: follow redirects to allowed hosts, or none",
+			components.WithStrDefaultValue(redirectsFollow),
+		),
+		components.NewStringFlag(
+			redirectHostsFlag,
+			"Comma separated hosts registry requests may be redirected to besides the registry host, e.g. a CDN. *.example.com matches subdomains",
+			components.WithHelpValue("hosts"),
+		),
+	}
+}
+
+func getRedirectEnvVars() []components.EnvVar {
+	return []components.EnvVar{
+		{
+			Name:        redirectsEnv,
+			Description: "Redirect policy of registry requests, used when --" + redirectsFlag + " is not set.",
+		},
+		{
+			Name:        redirectHostsEnv,
+			Description: "Hosts registry requests may be redirected to, used when --" + redirectHostsFlag + " is not set.",
+		},
+	}
+}
+
+func parseRedirectPolicy(policy string) (string, error) {
+	switch policy {
+	case "", redirectsFollow:
+		return redirectsFollow, nil
+	case redirectsNone:
+		return redirectsNone, nil
+	}
+	return "", fmt.Errorf("unsupported --%s policy '%s'. Expected %s or %s", redirectsFlag, policy, redirectsFollow, redirectsNone)
+}
+
+func parseRedirectHosts(value string) []string {
+	var hosts []string
+	for _, host := range splitList(value) {
+		if wildcard := strings.HasPrefix(host, "*."); wildcard {
+			hosts = append(hosts, "*."+audit.NormalizeHost(host[2:]))
+		} else {
+			hosts = append(hosts, audit.NormalizeHost(host))
+		}
+	}
+	return hosts
+}
+
+// httpClient returns a client for registry requests applying the redirect policy
+func (registry *registryConfiguration) httpClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:       timeout,
+		Transport:     registry.transport(),
+		CheckRedirect: registry.checkRedirect,
+	}
+}
+
+// packageCheckClient returns the client of the package checks. A single client serves all the workers and
+// commands of the configuration, so their requests share its pool of keep-alive connections.
+func (registry *registryConfiguration) packageCheckClient() *http.Client {
+	registry.clientOnce.Do(func() {
+		registry.checkClient = registry.httpClient(registry.requestTimeout)
+	})
+	return registry.checkClient
+}
+
+// checkRedirect follows redirects that stay on the registry host or the allowed hosts, and keeps HTTPS
+func (registry *registryConfiguration) checkRedirect(req *http.Request, via []*http.Request) error {
+	if registry.redirectPolicy == redirectsNone {
+		return http.ErrUseLastResponse
+	}
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	statusCode := http.StatusFound
+	if req.Response != nil {
+		statusCode = req.Response.StatusCode
+	}
+	if !registry.isAllowedRedirect(via[len(via)-1].URL, req.URL) {
+		return &audit.RedirectError{StatusCode: statusCode, Location: req.URL.String(), Unexpected: true}
+	}
+	return nil
+}
+
+func (registry *registryConfiguration) isAllowedRedirect(from, to *url.URL) bool {
+	if from.Scheme == "https" && to.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(to.Hostname())
+	return registry.isRegistryHost(host) || audit.MatchesHost(host, registry.redirectHosts)
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckNpmRegistryRedirects(t *testing.T) {
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer cdn.Close()
+	// The registry listens on 127.0.0.1, the CDN is reached through localhost to get a different host
+	cdnURL := strings.Replace(cdn.URL, "127.0.0.1", "localhost", 1)
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, cdnURL+r.URL.Path, http.StatusFound)
+	}))
+	defer registry.Close()
+
+	abbrev := audit.Dependency{Name: "abbrev", Version: "1.1.1", Type: audit.TypePackage}
+	result := (&registryConfiguration{registryURL: registry.URL}).auditRegistry().Check(abbrev)
+	assert.Equal(t, http.StatusFound, result.StatusCode)
+	assert.Equal(t, "⚠️ Unexpected Redirect (302) to localhost", result.Status)
+
+	result = (&registryConfiguration{registryURL: registry.URL, redirectHosts: []string{"localhost"}}).auditRegistry().Check(abbrev)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+
+	result = (&registryConfiguration{registryURL: registry.URL, redirectPolicy: redirectsNone, redirectHosts: []string{"localhost"}}).auditRegistry().Check(abbrev)
+	assert.Equal(t, http.StatusFound, result.StatusCode)
+	assert.Equal(t, "⚠️ Redirect (302) to localhost not followed", result.Status)
+}
+
+func TestParseRedirectPolicy(t *testing.T) {
+	policy, err := parseRedirectPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, redirectsFollow, policy)
+
+	_, err = parseRedirectPolicy("always")
+	assert.ErrorContains(t, err, "unsupported --redirects policy 'always'")
+}
+
+func TestIsAllowedRedirect(t *testing.T) {
+	registry := &registryConfiguration{registryURL: "https://acme.jfrog.io/artifactory/api/npm/npm", redirectHosts: parseRedirectHosts("*.cloudfront.net, cdn.acme.io")}
+	from := mustParseURL(t, "https://acme.jfrog.io/artifactory/api/npm/npm/abbrev/-/abbrev-1.1.1.tgz")
+
+	assert.True(t, registry.isAllowedRedirect(from, mustParseURL(t, "https://acme.jfrog.io/other")))
+	assert.True(t, registry.isAllowedRedirect(from, mustParseURL(t, "https://d1.cloudfront.net/abbrev.tgz")))
+	assert.True(t, registry.isAllowedRedirect(from, mustParseURL(t, "https://CDN.acme.io/abbrev.tgz")))
+	assert.False(t, registry.isAllowedRedirect(from, mustParseURL(t, "https://evil.example.com/abbrev.tgz")))
+	assert.False(t, registry.isAllowedRedirect(from, mustParseURL(t, "http://cdn.acme.io/abbrev.tgz")))
+}
+
+func mustParseURL(t *testing.T, rawURL string) *url.URL {
+	parsed, err := url.Parse(rawURL)
+	assert.NoError(t, err)
+	return parsed
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+// Path of the curation audit page of the JFrog platform UI, which lists the blocking policies of a package and
+// where its waiver is requested
+const curationAuditUIPath = "/ui/curation/audit"
+
+// remediationLinks links the blocked packages to their curation audit page in the Artifactory UI, shortening the path
+// from a finding to its waiver
+type remediationLinks struct {
+	platformURL string
+	repo        string
+	ecosystem   string
+}
+
+// newRemediationLinks returns the links of the packages blocked by a registry, or nil when the registry URL isn't
+// that of an Artifactory repository
+func newRemediationLinks(registryURL, ecosystem string) *remediationLinks {
+	artifactoryURL, repo := splitRegistryURL(registryURL)
+	if artifactoryURL == "" || repo == "" {
+		return nil
+	}
+	if ecosystem == "" {
+		ecosystem = ecosystemNpm
+	}
+	return &remediationLinks{platformURL: strings.TrimSuffix(artifactoryURL, "/artifactory"), repo: repo, ecosystem: ecosystem}
+}
+
+// url returns the curation audit page of a package version in the repository
+func (l *remediationLinks) url(name, version string) string {
+	query := url.Values{}
+	query.Set("packageType", l.ecosystem)
+	query.Set("packageName", name)
+	query.Set("packageVersion", version)
+	query.Set("repository", l.repo)
+	return l.platformURL + curationAuditUIPath + "?" + query.Encode()
+}
+
+// of returns the link of a result blocked by curation, or an empty string for the other results
+func (l *remediationLinks) of(result audit.AuditResult) string {
+	if l == nil || !isBlocked(result) {
+		return ""
+	}
+	return l.url(result.Name, result.Version)
+}
+
+// annotate records the link of each blocked package of the entries, those of a report, a precheck or the
+// notification events
+func (l *remediationLinks) annotate(entries []ResultEntry) {
+	if l == nil {
+		return
+	}
+	for i, entry := range entries {
+		if entry.Error == "" && entry.StatusCode == http.StatusForbidden && !entry.Pending {
+			entries[i].RemediationURL = l.url(entry.Name, entry.Version)
+		}
+	}
+}
+
+// print lists the links of the blocked packages
+func (l *remediationLinks) print(results []audit.AuditResult) {
+	printed := false
+	for _, result := range results {
+		link := l.of(result)
+		if link == "" {
+			continue
+		}
+		if !printed {
+			fmt.Printf("\n\nRequest waivers of blocked packages in Artifactory:")
+			printed = true
+		}
+		fmt.Printf("\n%s@%s: %s", result.Name, result.Version, link)
+	}
+}
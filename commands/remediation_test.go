@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemediationLinks(t *testing.T) {
+	assert.Nil(t, newRemediationLinks("https://registry.npmjs.org", ecosystemNpm))
+
+	links := newRemediationLinks("https://acme.jfrog.io/artifactory/api/npm/npm-curated", "")
+	require.NotNil(t, links)
+	assert.Equal(t, "https://acme.jfrog.io/ui/curation/audit?packageName=%40babel%2Fcore&packageType=npm&packageVersion=7.24.0&repository=npm-curated",
+		links.url("@babel/core", "7.24.0"))
+
+	results := []audit.AuditResult{
+		{Name: "lodash", Version: "4.17.21", StatusCode: http.StatusOK},
+		{Name: "event-stream", Version: "3.3.6", StatusCode: http.StatusForbidden},
+		{Name: "left-pad", Version: "1.3.0", StatusCode: http.StatusNotFound},
+		{Name: "colors", Version: "1.4.1", StatusCode: http.StatusForbidden, Error: errors.New("connection reset")},
+	}
+	report := newAuditReport("pnpm-lock.yaml", results)
+	links.annotate(report.Results)
+	var annotated []string
+	for _, entry := range report.Results {
+		if entry.RemediationURL != "" {
+			annotated = append(annotated, entry.Name)
+		}
+	}
+	assert.Equal(t, []string{"event-stream"}, annotated)
+
+	// Links of registries other than Artifactory are left out
+	var none *remediationLinks
+	none.annotate(report.Results)
+	assert.Empty(t, none.of(results[1]))
+}
+
+func TestRemediationLinksInNotifications(t *testing.T) {
+	links := newRemediationLinks("https://acme.jfrog.io/artifactory/api/pypi/pypi-curated/simple", "pypi")
+	events := newNotificationEvents("audit", "poetry.lock", []audit.AuditResult{
+		{Name: "requests", Version: "2.31.0", Status: "❌ Blocked by Curation (403)", StatusCode: http.StatusForbidden},
+	}, nil, nil)
+	for _, event := range events {
+		links.annotate(event.Packages)
+	}
+	assert.Contains(t, summarizeEvent(events[1]),
+		"requests@2.31.0 ❌ Blocked by Curation (403) https://acme.jfrog.io/ui/curation/audit?packageName=requests&packageType=pypi&packageVersion=2.31.0&repository=pypi-curated")
+}
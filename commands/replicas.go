@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	readReplicasFlag = "read-replicas"
+	readReplicasEnv  = "CA_EXTENSION_READ_REPLICAS"
+
+	// Upper bound of the probe of a registry endpoint
+	probeTimeout = 5 * time.Second
+)
+
+// endpointProbe is the outcome of probing a registry endpoint
+type endpointProbe struct {
+	url     string
+	latency time.Duration
+	err     error
+}
+
+func getReadReplicasFlag() components.Flag {
+	return components.NewStringFlag(
+		readReplicasFlag,
+		"Comma separated URLs of read replicas of the registry, e.g. the npm Enterprise replicas or Artifactory edge nodes of other regions. Packages are read from the endpoint answering the fastest, and the proxy command sends publishes to the registry URL",
+		components.WithHelpValue("urls"),
+	)
+}
+
+func parseReadReplicas(value string) ([]string, error) {
+	var replicas []string
+	for _, replica := range splitList(value) {
+		parsed, err := url.Parse(replica)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid --%s URL '%s'. Expected an http(s) URL", readReplicasFlag, replica)
+		}
+		replicas = append(replicas, strings.TrimSuffix(replica, "/"))
+	}
+	return replicas, nil
+}
+
+// selectReadReplica points the reads at the endpoint, of the registry and its read replicas, answering the probe
+// the fastest, and keeps the registry URL for publishes. Reads fail over to the other endpoints by the same
+// ranking, where the unreachable ones come last. The registry is kept when no endpoint answers.
+func (registry *registryConfiguration) selectReadReplica() {
+	registry.publishURL = registry.registryURL
+	probes := registry.probeEndpoints(append([]string{registry.registryURL}, registry.readReplicas...))
+	for _, probe := range probes {
+		if probe.err != nil {
+			log.Debug(fmt.Sprintf("Skipping %s: %v", probe.url, probe.err))
+		} else {
+			log.Debug(fmt.Sprintf("%s answered in %v", probe.url, probe.latency))
+		}
+	}
+	// Stable, so unreachable endpoints keep their configured order
+	sort.SliceStable(probes, func(i, j int) bool {
+		if (probes[i].err == nil) != (probes[j].err == nil) {
+			return probes[i].err == nil
+		}
+		return probes[i].err == nil && probes[i].latency < probes[j].latency
+	})
+	ranked := make([]string, len(probes))
+	for i, probe := range probes {
+		ranked[i] = probe.url
+	}
+
+	if probes[0].err != nil {
+		log.Warn("None of the registry endpoints answered, reading from", registry.registryURL)
+		ranked = append([]string{registry.registryURL}, registry.readReplicas...)
+	} else if probes[0].url != registry.registryURL {
+		log.Info(fmt.Sprintf("Reading from %s, the nearest read replica", probes[0].url))
+	}
+	registry.registryURL = ranked[0]
+	registry.failover = newEndpointFailover(ranked, endpointCooldown, time.Now)
+}
+
+// probeEndpoints requests the base URL of each endpoint concurrently. Registries answer their base URL
+// differently, so any response counts: only the connection and the round trip are measured.
+func (registry *registryConfiguration) probeEndpoints(endpoints []string) []endpointProbe {
+	client := &http.Client{
+		Timeout:   probeTimeout,
+		Transport: registry.transport(),
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	probes := make([]endpointProbe, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			probes[i] = endpointProbe{url: endpoint}
+			req, err := http.NewRequest(http.MethodHead, endpoint+"/", nil)
+			if err != nil {
+				probes[i].err = err
+				return
+			}
+			registry.credentials().Authorize(req)
+			start := time.Now()
+			resp, err := client.Do(req)
+			if err != nil {
+				probes[i].err = err
+				return
+			}
+			resp.Body.Close()
+			probes[i].latency = time.Since(start)
+		}(i, endpoint)
+	}
+	wg.Wait()
+	return probes
+}
+
+// publishEndpoint returns the URL publishes are sent to: the registry URL unless a read replica was selected
+func (registry *registryConfiguration) publishEndpoint() string {
+	if registry.publishURL != "" {
+		return registry.publishURL
+	}
+	return registry.registryURL
+}
+
+// isRegistryHost reports whether a lowercase host is that of the registry, of one of its read replicas, or of the
+// registry of an npm scope or of another ecosystem of an SBOM
+func (registry *registryConfiguration) isRegistryHost(host string) bool {
+	endpoints := append([]string{registry.registryURL, registry.publishURL}, registry.readReplicas...)
+	for _, scope := range registry.scopes {
+		endpoints = append(endpoints, scope.registryURL)
+	}
+	for _, registryURL := range registry.ecosystemRegistries {
+		endpoints = append(endpoints, registryURL)
+	}
+	for _, endpoint := range endpoints {
+		if endpoint != "" && host == strings.ToLower(audit.HostOf(endpoint)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReadReplicas(t *testing.T) {
+	replicas, err := parseReadReplicas("https://eu.acme.io/api/npm/npm-remote/, https://us.acme.io/api/npm/npm-remote")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://eu.acme.io/api/npm/npm-remote", "https://us.acme.io/api/npm/npm-remote"}, replicas)
+
+	replicas, err = parseReadReplicas("")
+	assert.NoError(t, err)
+	assert.Nil(t, replicas)
+
+	_, err = parseReadReplicas("eu.acme.io")
+	assert.EqualError(t, err, "invalid --read-replicas URL 'eu.acme.io'. Expected an http(s) URL")
+}
+
+func TestSelectReadReplica(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer primary.Close()
+	replica := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer replica.Close()
+	unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachable.Close()
+
+	registry := &registryConfiguration{registryURL: primary.URL, accessToken: "token", readReplicas: []string{unreachable.URL, replica.URL}}
+	registry.selectReadReplica()
+	assert.Equal(t, replica.URL, registry.registryURL)
+	assert.Equal(t, primary.URL, registry.publishEndpoint())
+	assert.True(t, registry.isRegistryHost(audit.HostOf(primary.URL)))
+	assert.True(t, registry.isRegistryHost(audit.HostOf(unreachable.URL)))
+	assert.False(t, registry.isRegistryHost("registry.npmjs.org"))
+
+	// Without any endpoint answering, the registry is kept
+	registry = &registryConfiguration{registryURL: unreachable.URL, readReplicas: []string{unreachable.URL + "/replica"}}
+	registry.selectReadReplica()
+	assert.Equal(t, unreachable.URL, registry.registryURL)
+}
+
+func TestRecordingProxyPublishesToRegistry(t *testing.T) {
+	var primaryMethods, replicaMethods []string
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryMethods = append(primaryMethods, r.Method)
+	}))
+	defer primary.Close()
+	replica := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		replicaMethods = append(replicaMethods, r.Method)
+	}))
+	defer replica.Close()
+
+	cache, err := loadOutcomeCache(filepath.Join(t.TempDir(), "outcomes.json"))
+	assert.NoError(t, err)
+	upstream := &registryConfiguration{registryURL: replica.URL, publishURL: primary.URL}
+	handler, err := newRecordingProxy(upstream, cache)
+	assert.NoError(t, err)
+	proxy := httptest.NewServer(handler)
+	defer proxy.Close()
+
+	resp, err := http.Get(proxy.URL + "/yallist/-/yallist-4.0.0.tgz")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	req, err := http.NewRequest(http.MethodPut, proxy.URL+"/yallist", nil)
+	assert.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{http.MethodGet}, replicaMethods)
+	assert.Equal(t, []string{http.MethodPut}, primaryMethods)
+	// Outcomes are those of the registry, whichever replica served them
+	_, cached := cache.lookup(&registryConfiguration{registryURL: primary.URL}, audit.Dependency{Name: "yallist", Version: "4.0.0"})
+	assert.True(t, cached)
+}
+
+func TestResolveEquivalentRegistryURLs(t *testing.T) {
+	t.Setenv(registryURLEnv, "https://acme.jfrog.io/artifactory/api/npm/npm-remote/,https://acme-eu.jfrog.io/artifactory/api/npm/npm-remote")
+	t.Setenv(readReplicasEnv, "https://acme-ap.jfrog.io/artifactory/api/npm/npm-remote")
+
+	registry, err := resolveRegistryConfiguration(&components.Context{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/api/npm/npm-remote", registry.registryURL)
+	assert.Equal(t, []string{"https://acme-eu.jfrog.io/artifactory/api/npm/npm-remote", "https://acme-ap.jfrog.io/artifactory/api/npm/npm-remote"}, registry.readReplicas)
+}
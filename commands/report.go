@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	formatFlag = "format"
+
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// ResultEntry represents the serialized audit outcome of a single package
+type ResultEntry struct {
+	Name            string `json:"name"`
+	Version         string `json:"version"`
+	PreviousVersion string `json:"previousVersion,omitempty"`
+	Type            string `json:"type,omitempty"`
+	Status          string `json:"status"`
+	StatusCode      int    `json:"statusCode"`
+	Error           string `json:"error,omitempty"`
+	Relationship    string `json:"relationship,omitempty"`
+
+	Policies []audit.CurationPolicy `json:"policies,omitempty"`
+	// Pending is set when the curation audit API hasn't evaluated the package yet, so it isn't blocked
+	Pending  bool `json:"pending,omitempty"`
+	Attempts int  `json:"attempts,omitempty"`
+	// TimedOut is set when the check of the package timed out, a request or the --audit-timeout
+	TimedOut bool `json:"timedOut,omitempty"`
+	// Projects are the importers of a workspace pulling in a blocked package
+	Projects []string `json:"projects,omitempty"`
+	// Path is the shortest chain from an importer to a blocked package
+	Path []string `json:"path,omitempty"`
+	// Acknowledged is the ignore rule, and its reason, downgrading the findings of the package to a warning
+	Acknowledged string `json:"acknowledged,omitempty"`
+	// Baseline is set when the finding of the package is in the --baseline, so it isn't a new one
+	Baseline bool `json:"baseline,omitempty"`
+	// Escalated is set when the accepted finding of the package is unresolved past --escalate-after, which fails
+	// the run
+	Escalated bool `json:"escalated,omitempty"`
+	// RemediationURL is the curation audit page of a blocked package in the Artifactory UI, where its waiver is
+	// requested
+	RemediationURL string `json:"remediationUrl,omitempty"`
+	// Annotations are set by 'annotate', e.g. for triage, and kept when an audit rewrites its results file
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// AuditReport represents the stored results of an audit run
+type AuditReport struct {
+	SchemaVersion string        `json:"schemaVersion"`
+	Metadata      *RunMetadata  `json:"metadata,omitempty"`
+	LockFile      string        `json:"lockFile"`
+	Total         int           `json:"total"`
+	Blocked       int           `json:"blocked"`
+	Summary       *AuditSummary `json:"summary,omitempty"`
+	Results       []ResultEntry `json:"results"`
+
+	PinnedTarballs  []PinnedTarball    `json:"pinnedTarballs,omitempty"`
+	ParseFindings   []ParseFinding     `json:"parseFindings,omitempty"`
+	BinaryDownloads []BinaryDownload   `json:"binaryDownloads,omitempty"`
+	PeerGaps        []PeerGap          `json:"peerGaps,omitempty"`
+	Suggestions     []Suggestion       `json:"suggestions,omitempty"`
+	Publications    *PublicationReport `json:"publications,omitempty"`
+}
+
+func GetReportCommand() components.Command {
+	return components.Command{
+		Name:        "report",
+		Description: "Renders the results stored by 'audit --output', or prints the JSON schema of the outputs.",
+		Aliases:     []string{"r"},
+		Arguments:   getReportArguments(),
+		Flags:       getReportFlags(),
+		Action: func(c *components.Context) error {
+			return reportCmd(c)
+		},
+	}
+}
+
+func getReportArguments() []components.Argument {
+	return []components.Argument{
+		{
+			Name:        "results-file",
+			Description: "The audit results JSON file.",
+		},
+	}
+}
+
+func getReportFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			formatFlag,
+			"Output format: text, json, sarif for code scanning, or markdown for pull request comments. Defaults to text",
+			components.WithHelpValue("format"),
+		),
+		components.NewStringFlag(
+			schemaFlag,
+			"Print the JSON schema of an output instead: report or tree",
+			components.WithHelpValue("output"),
+		),
+		getUTCFlag(),
+		getAccessibleFlag(),
+	}
+}
+
+func reportCmd(c *components.Context) error {
+	if name := c.GetStringFlagValue(schemaFlag); name != "" {
+		schema, err := outputSchema(name)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(schema))
+		return nil
+	}
+	if len(c.Arguments) != 1 {
+		return wrongArguments("report", "<results-file>", len(c.Arguments))
+	}
+
+	report, err := loadAuditReport(c.Arguments[0])
+	if err != nil {
+		return err
+	}
+
+	output, err := renderReport(report, flagOrConfig(c, formatFlag), getDisplayFormat(c))
+	if err != nil {
+		return err
+	}
+	fmt.Print(output)
+	return nil
+}
+
+func newResultEntry(result audit.AuditResult) ResultEntry {
+	entry := ResultEntry{
+		Name:         result.Name,
+		Version:      result.Version,
+		Type:         result.Type,
+		Status:       result.Status,
+		StatusCode:   result.StatusCode,
+		Policies:     result.Policies,
+		Pending:      result.Pending,
+		Attempts:     result.Attempts,
+		Relationship: result.Relationship,
+	}
+	if result.Error != nil {
+		entry.Error = result.Error.Error()
+		entry.TimedOut = audit.IsTimeout(result.Error)
+	}
+	return entry
+}
+
+func newAuditReport(lockFile string, results []audit.AuditResult) *AuditReport {
+	report := &AuditReport{
+		SchemaVersion: outputSchemaVersion,
+		LockFile:      lockFile,
+		Total:         len(results),
+		Results:       []ResultEntry{},
+	}
+	for _, result := range results {
+		if isBlocking(result) {
+			report.Blocked++
+		}
+		report.Results = append(report.Results, newResultEntry(result))
+	}
+	return report
+}
+
+func writeAuditReport(report *AuditReport, outputPath string) error {
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	if err := ioutil.WriteFile(outputPath, jsonData, 0644); err != nil {
+		return fmt.Errorf("error writing results file: %v", err)
+	}
+	return nil
+}
+
+func loadAuditReport(path string) (*AuditReport, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	var report AuditReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("error parsing results file %s: %v", path, err)
+	}
+	if err := checkSchemaVersion(path, report.SchemaVersion); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func renderReport(report *AuditReport, format string, display *displayFormat) (string, error) {
+	switch format {
+	case formatJSON:
+		jsonData, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error marshaling JSON: %v", err)
+		}
+		return string(jsonData) + "\n", nil
+	case formatSarif:
+		return renderSarif(report)
+	case formatMarkdown:
+		return renderMarkdown(report, display), nil
+	case formatText, "":
+		var sb strings.Builder
+		for i, entry := range report.Results {
+			sb.WriteString(fmt.Sprintf("[%s/%s] %s@%s (%s) %s", display.count(i+1), display.count(len(report.Results)), entry.Name, entry.Version, describeType(entry.Type, entry.Relationship), display.status(entry.Status)))
+			if entry.Error != "" {
+				sb.WriteString(" - Error: " + entry.Error)
+			}
+			if entry.RemediationURL != "" {
+				sb.WriteString(" - Waiver: " + entry.RemediationURL)
+			}
+			for _, key := range sortedKeys(entry.Annotations) {
+				sb.WriteString(fmt.Sprintf(" [%s=%s]", key, entry.Annotations[key]))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("\n%s: %s packages, %s blocked\n", report.LockFile, display.count(report.Total), display.count(report.Blocked)))
+		if report.Summary != nil {
+			sb.WriteString("\n" + report.Summary.table(display))
+		}
+		return sb.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported format '%s'. Expected %s, %s, %s or %s", format, formatText, formatJSON, formatSarif, formatMarkdown)
+	}
+}
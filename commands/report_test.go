@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditReportRoundTrip(t *testing.T) {
+	results := []audit.AuditResult{
+		{Name: "abbrev", Version: "1.1.1", Type: "package", Status: "✅ Available in NPM Registry", StatusCode: http.StatusOK},
+		{Name: "yallist", Version: "4.0.0", Type: "package", Status: "❌ Blocked (403 Forbidden)", StatusCode: http.StatusForbidden},
+	}
+	path := filepath.Join(t.TempDir(), "results.json")
+	assert.NoError(t, writeAuditReport(newAuditReport("pnpm-lock.yaml", results), path))
+
+	report, err := loadAuditReport(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 1, report.Blocked)
+	assert.Equal(t, "yallist", report.Results[1].Name)
+}
+
+func TestRenderReportText(t *testing.T) {
+	report := newAuditReport("pnpm-lock.yaml", []audit.AuditResult{
+		{Name: "yallist", Version: "4.0.0", Type: "package", Status: "❌ Blocked (403 Forbidden)", StatusCode: http.StatusForbidden},
+	})
+	output, err := renderReport(report, formatText, newDisplayFormat(true))
+	assert.NoError(t, err)
+	assert.Equal(t, "[1/1] yallist@4.0.0 (package) ❌ Blocked (403 Forbidden)\n\npnpm-lock.yaml: 1 packages, 1 blocked\n", output)
+}
+
+func TestRenderReportUnsupportedFormat(t *testing.T) {
+	_, err := renderReport(&AuditReport{}, "xml", newDisplayFormat(true))
+	assert.ErrorContains(t, err, "unsupported format 'xml'")
+}
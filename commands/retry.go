@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	retryAttemptsFlag  = "retry-attempts"
+	retryBaseDelayFlag = "retry-base-delay"
+	retryMaxDelayFlag  = "retry-max-delay"
+
+	retryAttemptsEnv  = "CA_EXTENSION_RETRY_ATTEMPTS"
+	retryBaseDelayEnv = "CA_EXTENSION_RETRY_BASE_DELAY"
+	retryMaxDelayEnv  = "CA_EXTENSION_RETRY_MAX_DELAY"
+)
+
+// Retries of registry checks unless configured otherwise
+var defaultRetryPolicy = audit.RetryPolicy{Attempts: 3, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second}
+
+func getRetryFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			retryAttemptsFlag,
+			fmt.Sprintf("Maximum number of checks of a package failing with a transient error (429, 5xx, timeout), including the first. 1 disables retries. Defaults to %d", defaultRetryPolicy.Attempts),
+			components.WithHelpValue("n"),
+		),
+		components.NewStringFlag(
+			retryBaseDelayFlag,
+			"Delay before the first retry, doubled for each of the next ones and jittered, e.g. 500ms. Defaults to "+defaultRetryPolicy.BaseDelay.String(),
+			components.WithHelpValue("duration"),
+		),
+		components.NewStringFlag(
+			retryMaxDelayFlag,
+			"Maximum delay between retries, e.g. 10s. Defaults to "+defaultRetryPolicy.MaxDelay.String(),
+			components.WithHelpValue("duration"),
+		),
+	}
+}
+
+func getRetryEnvVars() []components.EnvVar {
+	return []components.EnvVar{
+		{
+			Name:        retryAttemptsEnv,
+			Description: "Maximum number of checks of a package failing with a transient error, used when --" + retryAttemptsFlag + " is not set.",
+		},
+		{
+			Name:        retryBaseDelayEnv,
+			Description: "Delay before the first retry, used when --" + retryBaseDelayFlag + " is not set.",
+		},
+		{
+			Name:        retryMaxDelayEnv,
+			Description: "Maximum delay between retries, used when --" + retryMaxDelayFlag + " is not set.",
+		},
+	}
+}
+
+func parseRetryPolicy(attempts, baseDelay, maxDelay string) (audit.RetryPolicy, error) {
+	policy := defaultRetryPolicy
+	if attempts != "" {
+		n, err := strconv.Atoi(attempts)
+		if err != nil || n < 1 {
+			return policy, fmt.Errorf("--%s must be a number of at least 1, got '%s'", retryAttemptsFlag, attempts)
+		}
+		policy.Attempts = n
+	}
+	var err error
+	if policy.BaseDelay, err = parseRetryDelay(retryBaseDelayFlag, baseDelay, policy.BaseDelay); err != nil {
+		return policy, err
+	}
+	if policy.MaxDelay, err = parseRetryDelay(retryMaxDelayFlag, maxDelay, policy.MaxDelay); err != nil {
+		return policy, err
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		return policy, fmt.Errorf("--%s (%v) must not be less than --%s (%v)", retryMaxDelayFlag, policy.MaxDelay, retryBaseDelayFlag, policy.BaseDelay)
+	}
+	return policy, nil
+}
+
+func parseRetryDelay(flag, value string, defaultDelay time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultDelay, nil
+	}
+	delay, err := time.ParseDuration(value)
+	if err != nil || delay < 0 {
+		return 0, fmt.Errorf("invalid --%s '%s'. Expected a duration such as 500ms or 2s", flag, value)
+	}
+	return delay, nil
+}
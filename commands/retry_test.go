@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetryPolicy(t *testing.T) {
+	policy, err := parseRetryPolicy("", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, defaultRetryPolicy, policy)
+
+	policy, err = parseRetryPolicy("5", "1s", "30s")
+	assert.NoError(t, err)
+	assert.Equal(t, audit.RetryPolicy{Attempts: 5, BaseDelay: time.Second, MaxDelay: 30 * time.Second}, policy)
+
+	_, err = parseRetryPolicy("0", "", "")
+	assert.ErrorContains(t, err, "--retry-attempts must be a number of at least 1")
+	_, err = parseRetryPolicy("", "soon", "")
+	assert.ErrorContains(t, err, "invalid --retry-base-delay 'soon'")
+	_, err = parseRetryPolicy("", "20s", "")
+	assert.ErrorContains(t, err, "--retry-max-delay (10s) must not be less than --retry-base-delay (20s)")
+}
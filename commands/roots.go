@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const rootFlag = "root"
+
+func getRootFlag() components.Flag {
+	return components.NewStringFlag(
+		rootFlag,
+		"Comma separated direct dependencies to restrict the audit to, with the packages they pull in, e.g. to evaluate the curation impact of upgrading one of them in isolation",
+		components.WithHelpValue("names"),
+	)
+}
+
+// rootClosure returns the packages of the subtrees of the roots: the roots and the packages they depend on, directly
+// or transitively. The roots must be direct dependencies of the projects of the lock file.
+func rootClosure(tree *audit.DependencyTree, roots []string, lockFile string) (map[string]bool, error) {
+	relationships := tree.Relationships()
+	if relationships == nil {
+		return nil, fmt.Errorf("--%s needs a lock file recording the direct dependencies, which %s doesn't", rootFlag, filepath.Base(lockFile))
+	}
+	for _, root := range roots {
+		switch relationships[root] {
+		case audit.RelationshipDirect:
+		case audit.RelationshipTransitive:
+			// The second element of its path, after the importer, is the direct dependency pulling it in
+			if path := tree.DependencyPaths()[root]; len(path) > 1 {
+				direct := path[1][:strings.LastIndex(path[1], "@")]
+				return nil, fmt.Errorf("--%s %s is a transitive dependency, pulled in by the direct dependency %s", rootFlag, root, direct)
+			}
+			return nil, fmt.Errorf("--%s %s is a transitive dependency, not a direct one", rootFlag, root)
+		default:
+			return nil, fmt.Errorf("--%s %s isn't a dependency of the projects of %s", rootFlag, root, filepath.Base(lockFile))
+		}
+	}
+	return tree.Closure(roots...), nil
+}
+
+// rootDependencies keeps the dependencies of the subtrees of the roots
+func rootDependencies(deps []audit.Dependency, closure map[string]bool, roots []string) []audit.Dependency {
+	var kept []audit.Dependency
+	for _, dep := range deps {
+		if closure[dep.Name] {
+			kept = append(kept, dep)
+		}
+	}
+	log.Info(fmt.Sprintf("Auditing the %d packages of the subtrees of %s, skipping %d (--%s)", len(kept), strings.Join(roots, ", "), len(deps)-len(kept), rootFlag))
+	return kept
+}
+
+// rootSubtree returns the tree of the packages of the closure, whose per-platform variants are audited with them
+func rootSubtree(tree *audit.DependencyTree, closure map[string]bool) *audit.DependencyTree {
+	subtree := &audit.DependencyTree{Packages: make(map[string]audit.PackageInfo, len(closure))}
+	for name, info := range tree.Packages {
+		if closure[name] {
+			subtree.Packages[name] = info
+		}
+	}
+	return subtree
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootClosure(t *testing.T) {
+	tree, err := audit.ParseLockFile(filepath.Join("..", "audit", "testdata", "pnpm-workspace", "pnpm-lock.yaml"))
+	require.NoError(t, err)
+
+	// react-dom pulls in its peer react, and loose-envify pulls in js-tokens
+	closure, err := rootClosure(tree, []string{"react-dom", "loose-envify"}, "pnpm-lock.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"react-dom": true, "react": true, "loose-envify": true, "js-tokens": true}, closure)
+
+	deps := rootDependencies(tree.Dependencies(), closure, []string{"react-dom", "loose-envify"})
+	var names []string
+	for _, dep := range deps {
+		names = append(names, dep.Name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"js-tokens", "loose-envify", "react", "react-dom"}, names)
+	subtree := rootSubtree(tree, closure)
+	assert.Len(t, subtree.Packages, 4)
+	assert.NotContains(t, subtree.Packages, "typescript")
+
+	_, err = rootClosure(tree, []string{"js-tokens"}, "pnpm-lock.yaml")
+	assert.EqualError(t, err, "--root js-tokens is a transitive dependency, pulled in by the direct dependency loose-envify")
+	_, err = rootClosure(tree, []string{"lodash"}, "pnpm-lock.yaml")
+	assert.EqualError(t, err, "--root lodash isn't a dependency of the projects of pnpm-lock.yaml")
+	_, err = rootClosure(&audit.DependencyTree{Packages: tree.Packages}, []string{"react-dom"}, "requirements.txt")
+	assert.ErrorContains(t, err, "needs a lock file recording the direct dependencies, which requirements.txt doesn't")
+}
+
+func TestAuditRoot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifications, err := newNotificationDispatcher(&notificationConfig{}, nil)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	conf := &auditConfiguration{
+		registry:   &registryConfiguration{registryURL: server.URL, requestTimeout: time.Minute},
+		lockFile:   filepath.Join("..", "audit", "testdata", "pnpm-workspace", "pnpm-lock.yaml"),
+		workers:    2,
+		roots:      []string{"loose-envify"},
+		treeOutput: filepath.Join(dir, defaultTreeFileName),
+		output:     filepath.Join(dir, "results.json"),
+		display:    newDisplayFormat(true),
+		stages:     newRunStages(nil),
+
+		notifications: notifications,
+	}
+	require.NoError(t, runAudit(context.Background(), conf))
+
+	report, err := loadAuditReport(conf.output)
+	require.NoError(t, err)
+	var audited []string
+	for _, result := range report.Results {
+		audited = append(audited, result.Name)
+	}
+	sort.Strings(audited)
+	assert.Equal(t, []string{"js-tokens", "loose-envify"}, audited)
+	// The dependency tree is still the whole lock file
+	tree, err := loadDependencyTree(conf.treeOutput)
+	require.NoError(t, err)
+	assert.Len(t, tree.Packages, 6)
+}
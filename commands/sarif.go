@@ -0,0 +1,245 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	formatSarif = "sarif"
+
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// Runs of characters not allowed in the rule IDs derived from policy names
+var sarifRuleIDPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// sarifLog represents the subset of a SARIF 2.1.0 log that code scanning uses
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool        `json:"tool"`
+	Results    []sarifResult    `json:"results"`
+	Properties *sarifProperties `json:"properties,omitempty"`
+}
+
+// sarifProperties is the property bag of a run, which keeps the outcome counts of the audit for dashboards
+type sarifProperties struct {
+	Summary *AuditSummary `json:"summary,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	Help             sarifMessage `json:"help"`
+	DefaultLevel     sarifLevel   `json:"defaultConfiguration"`
+}
+
+type sarifLevel struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// renderSarif converts the packages that would fail to install into SARIF results on the lock file, with a rule
+// per curation policy, or per outcome when the policies aren't known
+func renderSarif(report *AuditReport) (string, error) {
+	driver := sarifDriver{
+		Name:           "ca-extension",
+		InformationURI: "https://github.com/chaitanyagovande/ca-extension",
+		Rules:          []sarifRule{},
+	}
+	if report.Metadata != nil {
+		driver.Version = report.Metadata.ToolVersion
+	}
+	lines := lockFilePackageLines(report.LockFile)
+	seenRules := make(map[string]bool)
+	results := []sarifResult{}
+	for _, entry := range report.Results {
+		if entry.StatusCode == http.StatusOK && entry.Error == "" {
+			continue
+		}
+		rule := sarifRuleFor(entry)
+		if !seenRules[rule.ID] {
+			seenRules[rule.ID] = true
+			driver.Rules = append(driver.Rules, rule)
+		}
+
+		message := fmt.Sprintf("%s@%s: %s", entry.Name, entry.Version, entry.Status)
+		if entry.Error != "" {
+			message += " - Error: " + entry.Error
+		}
+		line := lines[entry.Name+"@"+entry.Version]
+		if line == 0 {
+			line = 1
+		}
+		results = append(results, sarifResult{
+			RuleID:  rule.ID,
+			Level:   rule.DefaultLevel.Level,
+			Message: sarifMessage{Text: message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(report.LockFile)},
+				Region:           sarifRegion{StartLine: line},
+			}}},
+			PartialFingerprints: map[string]string{"packageVersion/v1": entry.Name + "@" + entry.Version},
+		})
+	}
+
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: results}
+	if report.Summary != nil {
+		run.Properties = &sarifProperties{Summary: report.Summary}
+	}
+	sarif := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}
+	jsonData, err := json.MarshalIndent(sarif, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error marshaling SARIF: %v", err)
+	}
+	return string(jsonData) + "\n", nil
+}
+
+// sarifRuleFor returns the rule of a result: its first violated policy, else its outcome
+func sarifRuleFor(entry ResultEntry) sarifRule {
+	if len(entry.Policies) > 0 {
+		policy := entry.Policies[0]
+		description := policy.Condition
+		if description == "" {
+			description = "Violates the " + policy.Policy + " curation policy"
+		}
+		help := policy.Explanation
+		if policy.Recommendation != "" {
+			help = strings.TrimSpace(help + " " + policy.Recommendation)
+		}
+		if help == "" {
+			help = description
+		}
+		return sarifRule{
+			ID:               "curation/policy/" + strings.Trim(sarifRuleIDPattern.ReplaceAllString(strings.ToLower(policy.Policy), "-"), "-"),
+			Name:             policy.Policy,
+			ShortDescription: sarifMessage{Text: description},
+			Help:             sarifMessage{Text: help},
+			DefaultLevel:     sarifLevel{Level: "error"},
+		}
+	}
+
+	switch {
+	case entry.Error != "":
+		return sarifRule{
+			ID:               "curation/check-failed",
+			Name:             "CheckFailed",
+			ShortDescription: sarifMessage{Text: "The curation status of the package could not be checked"},
+			Help:             sarifMessage{Text: "Rerun the audit once the registry is reachable."},
+			DefaultLevel:     sarifLevel{Level: "warning"},
+		}
+	case entry.Pending:
+		return sarifRule{
+			ID:               "curation/pending",
+			Name:             "Pending",
+			ShortDescription: sarifMessage{Text: "The package isn't evaluated by curation yet, as it isn't cached in the remote repository"},
+			Help:             sarifMessage{Text: "Rerun the audit once the package is cached, e.g. after its first install."},
+			DefaultLevel:     sarifLevel{Level: "warning"},
+		}
+	case entry.StatusCode == http.StatusForbidden:
+		return sarifRule{
+			ID:               "curation/blocked",
+			Name:             "Blocked",
+			ShortDescription: sarifMessage{Text: "The package is blocked by the curated registry"},
+			Help:             sarifMessage{Text: "Upgrade to a version the curation policies approve, or request a waiver."},
+			DefaultLevel:     sarifLevel{Level: "error"},
+		}
+	case entry.StatusCode == http.StatusNotFound:
+		return sarifRule{
+			ID:               "curation/not-found",
+			Name:             "NotFound",
+			ShortDescription: sarifMessage{Text: "The package is not found in the curated registry"},
+			Help:             sarifMessage{Text: "Check the package name and version, and that the remote repository proxies its registry."},
+			DefaultLevel:     sarifLevel{Level: "error"},
+		}
+	default:
+		return sarifRule{
+			ID:               "curation/unexpected-response",
+			Name:             "UnexpectedResponse",
+			ShortDescription: sarifMessage{Text: "The curated registry responded unexpectedly for the package"},
+			Help:             sarifMessage{Text: "Check the registry URL and the redirect settings."},
+			DefaultLevel:     sarifLevel{Level: "warning"},
+		}
+	}
+}
+
+// lockFilePackageLines returns the first line mentioning each <name>@<version> of the lock file, so results point
+// at the package entry. Results of lock files that are gone, or of formats that don't mention packages that way,
+// point at the first line.
+func lockFilePackageLines(path string) map[string]int {
+	lines := make(map[string]int)
+	file, err := os.Open(path)
+	if err != nil {
+		return lines
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.Trim(strings.TrimSpace(scanner.Text()), `"'/:`)
+		// pnpm and Yarn keys: lodash@4.17.21, /lodash@4.17.21(react@18.2.0), "lodash@npm:4.17.21"
+		key := strings.SplitN(text, "(", 2)[0]
+		key = strings.Replace(key, "@npm:", "@", 1)
+		if _, exists := lines[key]; !exists && strings.Contains(key, "@") {
+			lines[key] = line
+		}
+	}
+	return lines
+}
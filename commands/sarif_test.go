@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderSarif(t *testing.T) {
+	lockFile := filepath.Join(t.TempDir(), "pnpm-lock.yaml")
+	require.NoError(t, os.WriteFile(lockFile, []byte("lockfileVersion: '9.0'\n\npackages:\n\n  abbrev@1.1.1:\n    resolution: {}\n\n  lodash@4.17.20:\n    resolution: {}\n"), 0644))
+	report := newAuditReport(lockFile, []audit.AuditResult{
+		{Name: "abbrev", Version: "1.1.1", Status: "✅ Available in NPM Registry", StatusCode: http.StatusOK},
+		{Name: "lodash", Version: "4.17.20", Status: "❌ Blocked by Curation Policy: Block Malicious (Malicious package)", StatusCode: http.StatusForbidden,
+			Policies: []audit.CurationPolicy{{Policy: "Block Malicious", Condition: "Malicious package", Recommendation: "Remove the package"}}},
+		{Name: "yallist", Version: "4.0.0", Status: "❌ Blocked (403 Forbidden)", StatusCode: http.StatusForbidden},
+		{Name: "left-pad", Version: "1.3.0", Status: "❌ Not Found (404)", StatusCode: http.StatusNotFound},
+	})
+
+	output, err := renderReport(report, formatSarif, newDisplayFormat(true))
+	require.NoError(t, err)
+	var sarif sarifLog
+	require.NoError(t, json.Unmarshal([]byte(output), &sarif))
+	assert.Equal(t, "2.1.0", sarif.Version)
+	require.Len(t, sarif.Runs, 1)
+
+	run := sarif.Runs[0]
+	var ruleIDs []string
+	for _, rule := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	assert.Equal(t, []string{"curation/policy/block-malicious", "curation/blocked", "curation/not-found"}, ruleIDs)
+	assert.Equal(t, "Malicious package", run.Tool.Driver.Rules[0].ShortDescription.Text)
+	assert.Equal(t, "Remove the package", run.Tool.Driver.Rules[0].Help.Text)
+
+	require.Len(t, run.Results, 3)
+	lodash := run.Results[0]
+	assert.Equal(t, "curation/policy/block-malicious", lodash.RuleID)
+	assert.Equal(t, "error", lodash.Level)
+	assert.Equal(t, filepath.ToSlash(lockFile), lodash.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 8, lodash.Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, "lodash@4.17.20", lodash.PartialFingerprints["packageVersion/v1"])
+	// Packages missing from the lock file point at its first line
+	assert.Equal(t, 1, run.Results[1].Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Nil(t, run.Properties)
+
+	// The summary of the audit is in the property bag of the run
+	report.Summary = &AuditSummary{Packages: 4, Approved: 1, Blocked: 2, NotFound: 1}
+	output, err = renderReport(report, formatSarif, newDisplayFormat(true))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(output), &sarif))
+	require.NotNil(t, sarif.Runs[0].Properties)
+	assert.Equal(t, report.Summary, sarif.Runs[0].Properties.Summary)
+}
+
+func TestLockFilePackageLines(t *testing.T) {
+	lockFile := filepath.Join(t.TempDir(), "pnpm-lock.yaml")
+	require.NoError(t, os.WriteFile(lockFile, []byte("packages:\n  /react-dom@18.2.0(react@18.2.0):\n  '@types/node@20.11.0':\n"), 0644))
+	assert.Equal(t, map[string]int{"react-dom@18.2.0": 2, "@types/node@20.11.0": 3}, lockFilePackageLines(lockFile))
+	assert.Empty(t, lockFilePackageLines(filepath.Join(t.TempDir(), "missing.yaml")))
+}
@@ -0,0 +1,398 @@
+package commands
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	sbomFlag       = "sbom"
+	sbomOutputFlag = "sbom-output"
+
+	formatCycloneDX = "cyclonedx"
+	formatSPDX      = "spdx"
+
+	cycloneDXSpecVersion = "1.5"
+	// Names of the SBOMs audits write next to the lock file, as the specifications recommend
+	defaultCycloneDXFileName = "bom.cdx.json"
+	defaultSPDXFileName      = "bom.spdx.json"
+
+	// Status of the components of the SBOM that weren't checked, e.g. skipped by an ignore rule or --direct-only
+	sbomStatusNotAudited = "not-audited"
+)
+
+// Names of the CycloneDX properties of the components, in the ca-extension namespace
+const (
+	sbomPropertyStatus       = "ca-extension:curation:status"
+	sbomPropertyStatusCode   = "ca-extension:curation:statusCode"
+	sbomPropertyPolicy       = "ca-extension:curation:policy"
+	sbomPropertyWaiver       = "ca-extension:curation:waiverUrl"
+	sbomPropertyAcknowledged = "ca-extension:curation:acknowledged"
+	sbomPropertyBaseline     = "ca-extension:curation:baseline"
+	sbomPropertyError        = "ca-extension:curation:error"
+	sbomPropertyRelationship = "ca-extension:relationship"
+	sbomPropertyType         = "ca-extension:type"
+	sbomPropertyRegistry     = "ca-extension:curation:registry"
+)
+
+// cycloneDXBOM represents the subset of a CycloneDX 1.5 JSON BOM describing the packages of a lock file
+type cycloneDXBOM struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     cycloneDXMetadata     `json:"metadata"`
+	Components   []cycloneDXComponent  `json:"components"`
+	Dependencies []cycloneDXDependency `json:"dependencies"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp  string             `json:"timestamp"`
+	Tools      cycloneDXTools     `json:"tools"`
+	Authors    []cycloneDXContact `json:"authors,omitempty"`
+	Component  cycloneDXComponent `json:"component"`
+	Properties []sbomProperty     `json:"properties,omitempty"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type       string         `json:"type"`
+	BOMRef     string         `json:"bom-ref,omitempty"`
+	Supplier   *cycloneDXOrg  `json:"supplier,omitempty"`
+	Group      string         `json:"group,omitempty"`
+	Name       string         `json:"name"`
+	Version    string         `json:"version,omitempty"`
+	PURL       string         `json:"purl,omitempty"`
+	Properties []sbomProperty `json:"properties,omitempty"`
+}
+
+// cycloneDXOrg is the organization supplying a component, which lock files don't record
+type cycloneDXOrg struct {
+	Name string `json:"name"`
+}
+
+type cycloneDXContact struct {
+	Name string `json:"name"`
+}
+
+// sbomProperty is a curation property of a package, a CycloneDX property or the comment of an SPDX annotation
+type sbomProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// sbomConfiguration is the SBOM an audit writes, if any
+type sbomConfiguration struct {
+	format string
+	output string
+}
+
+func getSBOMFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			sbomFlag,
+			"Also write an SBOM of all the parsed dependencies, annotated with their curation status: "+formatCycloneDX+" for a CycloneDX "+cycloneDXSpecVersion+" JSON BOM, or "+formatSPDX+" for an SPDX "+spdxSpecVersion+" JSON document",
+			components.WithHelpValue("format"),
+		),
+		components.NewStringFlag(
+			sbomOutputFlag,
+			"Path of the SBOM file. Defaults to "+defaultCycloneDXFileName+" or "+defaultSPDXFileName+" next to the lock file, or in the --output-dir of --read-only runs",
+			components.WithHelpValue("path"),
+		),
+	}
+}
+
+func getSBOMConfiguration(c *components.Context, lockFile string, sandbox *readOnlySandbox) (*sbomConfiguration, error) {
+	return parseSBOMConfiguration(flagOrConfig(c, sbomFlag), c.GetStringFlagValue(sbomOutputFlag), lockFile, sandbox)
+}
+
+// parseSBOMConfiguration returns the SBOM to write, or nil when no format is given
+func parseSBOMConfiguration(format, output, lockFile string, sandbox *readOnlySandbox) (*sbomConfiguration, error) {
+	format = strings.ToLower(format)
+	if format == "" {
+		if output != "" {
+			return nil, fmt.Errorf("--%s needs --%s", sbomOutputFlag, sbomFlag)
+		}
+		return nil, nil
+	}
+	fileNames := map[string]string{formatCycloneDX: defaultCycloneDXFileName, formatSPDX: defaultSPDXFileName}
+	fileName, supported := fileNames[format]
+	if !supported {
+		return nil, fmt.Errorf("unsupported SBOM format '%s'. Expected %s or %s", format, formatCycloneDX, formatSPDX)
+	}
+	if output == "" {
+		output = sandbox.defaultOutput(fileName, filepath.Join(filepath.Dir(lockFile), fileName))
+		if output == "" {
+			return nil, fmt.Errorf("--%s needs an --%s or an --%s with --%s", sbomFlag, sbomOutputFlag, outputDirFlag, readOnlyFlag)
+		}
+	}
+	return &sbomConfiguration{format: format, output: output}, nil
+}
+
+// write writes the SBOM of the tree, with the results of the report, and returns its checks for the NTIA minimum
+// elements
+func (s *sbomConfiguration) write(tree *audit.DependencyTree, report *AuditReport, registryURL string) (sbomQuality, error) {
+	var sbom interface{}
+	var quality sbomQuality
+	if s.format == formatSPDX {
+		document, err := newSPDXDocument(tree, report, registryURL)
+		if err != nil {
+			return sbomQuality{}, err
+		}
+		sbom, quality = document, checkSPDXQuality(document)
+	} else {
+		bom, err := newCycloneDXBOM(tree, report, registryURL)
+		if err != nil {
+			return sbomQuality{}, err
+		}
+		sbom, quality = bom, checkCycloneDXQuality(bom)
+	}
+	jsonData, err := json.MarshalIndent(sbom, "", "  ")
+	if err != nil {
+		return sbomQuality{}, fmt.Errorf("error marshaling SBOM: %v", err)
+	}
+	if err := ioutil.WriteFile(s.output, append(jsonData, '\n'), 0644); err != nil {
+		return sbomQuality{}, fmt.Errorf("error writing SBOM: %v", err)
+	}
+	return quality, nil
+}
+
+// newCycloneDXBOM describes the packages of the tree, and those the audit found besides, such as bundled ones, as
+// library components. Each one has the curation status of its result as properties, or not-audited, and the
+// dependencies record the graph the lock file has, from the project down.
+func newCycloneDXBOM(tree *audit.DependencyTree, report *AuditReport, registryURL string) (*cycloneDXBOM, error) {
+	serialNumber, err := newUUID()
+	if err != nil {
+		return nil, fmt.Errorf("error generating the SBOM serial number: %v", err)
+	}
+	ecosystem := audit.EcosystemOf(audit.PackageManagerFor(report.LockFile))
+	project := cycloneDXComponent{Type: "application", BOMRef: "project", Name: projectName(report.LockFile)}
+	bom := &cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  cycloneDXSpecVersion,
+		SerialNumber: "urn:uuid:" + serialNumber,
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools:     cycloneDXTools{Components: []cycloneDXComponent{{Type: "application", Name: "ca-extension", Version: appVersion}}},
+			Component: project,
+		},
+		Components:   []cycloneDXComponent{},
+		Dependencies: []cycloneDXDependency{},
+	}
+	if registryURL != "" {
+		bom.Metadata.Properties = []sbomProperty{{Name: sbomPropertyRegistry, Value: stripURLCredentials(registryURL)}}
+	}
+
+	refs := make(map[string]string)
+	for _, pkg := range sbomPackages(tree, report) {
+		component := newCycloneDXComponent(ecosystem, pkg.name, pkg.version)
+		component.Properties = pkg.properties()
+		bom.Components = append(bom.Components, component)
+		if _, exists := refs[pkg.name]; !exists {
+			refs[pkg.name] = component.BOMRef
+		}
+	}
+
+	direct, graph := sbomGraph(tree)
+	root := cycloneDXDependency{Ref: project.BOMRef, DependsOn: []string{}}
+	for _, name := range direct {
+		root.DependsOn = append(root.DependsOn, refs[name])
+	}
+	bom.Dependencies = append(bom.Dependencies, root)
+	for _, name := range sortedKeys(tree.Packages) {
+		dependency := cycloneDXDependency{Ref: refs[name], DependsOn: []string{}}
+		for _, dependsOn := range graph[name] {
+			dependency.DependsOn = append(dependency.DependsOn, refs[dependsOn])
+		}
+		bom.Dependencies = append(bom.Dependencies, dependency)
+	}
+	return bom, nil
+}
+
+// sbomPackage is a package of an SBOM, with the result of its check when it was audited
+type sbomPackage struct {
+	name         string
+	version      string
+	packageType  string
+	relationship string
+	resolution   map[string]interface{}
+	entry        *ResultEntry
+}
+
+// sbomPackages returns the packages of the tree, then those the audit found besides, such as bundled ones, each
+// version once
+func sbomPackages(tree *audit.DependencyTree, report *AuditReport) []sbomPackage {
+	entries := make(map[string]*ResultEntry, len(report.Results))
+	for i, entry := range report.Results {
+		entries[entry.Name+"@"+entry.Version] = &report.Results[i]
+	}
+	var packages []sbomPackage
+	added := make(map[string]bool)
+	add := func(pkg sbomPackage) {
+		key := pkg.name + "@" + pkg.version
+		if added[key] {
+			return
+		}
+		added[key] = true
+		pkg.entry = entries[key]
+		if pkg.relationship == "" && pkg.entry != nil {
+			pkg.relationship = pkg.entry.Relationship
+		}
+		packages = append(packages, pkg)
+	}
+	relationships := tree.Relationships()
+	for _, name := range sortedKeys(tree.Packages) {
+		info := tree.Packages[name]
+		add(sbomPackage{name: name, version: info.Version, packageType: info.Type, relationship: relationships[name], resolution: info.Resolution})
+	}
+	for _, entry := range report.Results {
+		add(sbomPackage{name: entry.Name, version: entry.Version, packageType: entry.Type, relationship: entry.Relationship})
+	}
+	return packages
+}
+
+// sbomGraph returns the direct dependencies of the projects of the tree, and what each package depends on
+func sbomGraph(tree *audit.DependencyTree) ([]string, map[string][]string) {
+	var direct []string
+	relationships := tree.Relationships()
+	for _, name := range sortedKeys(tree.Packages) {
+		if relationships[name] == audit.RelationshipDirect {
+			direct = append(direct, name)
+		}
+	}
+	return direct, tree.DependencyGraph()
+}
+
+// properties returns the curation properties of the package: its status, not-audited when it wasn't checked, and
+// the outcome of its check
+func (p sbomPackage) properties() []sbomProperty {
+	var properties []sbomProperty
+	if p.entry != nil {
+		properties = sbomEntryProperties(*p.entry)
+	} else {
+		properties = []sbomProperty{{Name: sbomPropertyStatus, Value: sbomStatusNotAudited}}
+	}
+	if p.relationship != "" {
+		properties = append(properties, sbomProperty{Name: sbomPropertyRelationship, Value: p.relationship})
+	}
+	if p.packageType != "" && p.packageType != audit.TypePackage {
+		properties = append(properties, sbomProperty{Name: sbomPropertyType, Value: p.packageType})
+	}
+	return properties
+}
+
+// newCycloneDXComponent returns the library component of a package, referenced by its package URL
+func newCycloneDXComponent(ecosystem, name, version string) cycloneDXComponent {
+	component := cycloneDXComponent{Type: "library", Name: name, Version: version, PURL: packageURL(ecosystem, name, version)}
+	component.BOMRef = component.PURL
+	switch ecosystem {
+	case audit.EcosystemNpm, audit.EcosystemComposer:
+		if group, base, found := strings.Cut(name, "/"); found {
+			component.Group, component.Name = group, base
+		}
+	case audit.EcosystemMaven:
+		if group, artifact, found := strings.Cut(name, ":"); found {
+			component.Group, component.Name = group, artifact
+		}
+	}
+	return component
+}
+
+// packageURL returns the package URL of a package version, e.g. pkg:npm/%40acme/ui@2.0.0 or
+// pkg:maven/org.slf4j/slf4j-api@2.0.9
+func packageURL(ecosystem, name, version string) string {
+	var purlType string
+	var segments []string
+	switch ecosystem {
+	case audit.EcosystemPyPI:
+		purlType, segments = "pypi", []string{strings.ReplaceAll(strings.ToLower(name), "_", "-")}
+	case audit.EcosystemGo:
+		purlType, segments = "golang", strings.Split(name, "/")
+	case audit.EcosystemMaven:
+		purlType, segments = "maven", strings.SplitN(name, ":", 2)
+	case audit.EcosystemComposer:
+		purlType, segments = "composer", strings.SplitN(name, "/", 2)
+	default:
+		purlType, segments = "npm", strings.SplitN(name, "/", 2)
+	}
+	for i, segment := range segments {
+		segments[i] = purlEscape(segment)
+	}
+	return "pkg:" + purlType + "/" + strings.Join(segments, "/") + "@" + purlEscape(version)
+}
+
+// purlEscape percent-encodes a package URL segment, which can't have the @ and + that are valid in URL paths
+func purlEscape(segment string) string {
+	escaped := url.PathEscape(segment)
+	escaped = strings.ReplaceAll(escaped, "@", "%40")
+	return strings.ReplaceAll(escaped, "+", "%2B")
+}
+
+// sbomEntryProperties returns the curation properties of an audited package
+func sbomEntryProperties(entry ResultEntry) []sbomProperty {
+	status := indexStatusOf(entry.Error != "", entry.StatusCode)
+	if entry.Error == "" && entry.Pending {
+		status = indexStatusPending
+	}
+	properties := []sbomProperty{
+		{Name: sbomPropertyStatus, Value: status},
+		{Name: sbomPropertyStatusCode, Value: fmt.Sprint(entry.StatusCode)},
+	}
+	if entry.Error != "" {
+		properties = append(properties, sbomProperty{Name: sbomPropertyError, Value: entry.Error})
+	}
+	for _, policy := range entry.Policies {
+		value := policy.Policy
+		if policy.Condition != "" {
+			value += ": " + policy.Condition
+		}
+		properties = append(properties, sbomProperty{Name: sbomPropertyPolicy, Value: value})
+	}
+	if entry.RemediationURL != "" {
+		properties = append(properties, sbomProperty{Name: sbomPropertyWaiver, Value: entry.RemediationURL})
+	}
+	if entry.Acknowledged != "" {
+		properties = append(properties, sbomProperty{Name: sbomPropertyAcknowledged, Value: entry.Acknowledged})
+	}
+	if entry.Baseline {
+		properties = append(properties, sbomProperty{Name: sbomPropertyBaseline, Value: "true"})
+	}
+	return properties
+}
+
+// projectName names the project of a lock file after its directory
+func projectName(lockFile string) string {
+	if abs, err := filepath.Abs(lockFile); err == nil {
+		lockFile = abs
+	}
+	return filepath.Base(filepath.Dir(lockFile))
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	ecosystemReposFlag = "ecosystem-repos"
+
+	ecosystemReposEnv = "CA_EXTENSION_ECOSYSTEM_REPOS"
+)
+
+func getEcosystemReposFlag() components.Flag {
+	return components.NewStringFlag(
+		ecosystemReposFlag,
+		"Comma separated ecosystem=repo of the repositories the components of the other ecosystems of an audited SBOM are checked against, e.g. pypi=pypi-remote,go=go-remote. A full registry URL may replace the repository key. Defaults to the resolver repositories of the JFrog CLI project configuration",
+		components.WithHelpValue("repos"),
+	)
+}
+
+// inputEcosystems returns the ecosystem of the audited file and, for SBOMs, the other ecosystems of their
+// components. The ecosystem of an SBOM is that of most of its components, ties going to the first by name, which
+// the registry of the run serves.
+func inputEcosystems(path string) (string, []string, error) {
+	packageManager := audit.PackageManagerFor(path)
+	if packageManager != audit.PackageManagerSBOM {
+		return audit.EcosystemOf(packageManager), nil, nil
+	}
+	tree, err := audit.ParseLockFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("error parsing %s: %v", filepath.Base(path), err)
+	}
+	counts := tree.Ecosystems()
+	ecosystems := sortedKeys(counts)
+	if len(ecosystems) == 0 {
+		return ecosystemNpm, nil, nil
+	}
+	sort.SliceStable(ecosystems, func(i, j int) bool {
+		return counts[ecosystems[i]] > counts[ecosystems[j]]
+	})
+	log.Info(fmt.Sprintf("Checking the %d %s components of %s against the registry of the run", counts[ecosystems[0]], ecosystems[0], filepath.Base(path)))
+	return ecosystems[0], ecosystems[1:], nil
+}
+
+// getEcosystemRegistries returns the registry URLs of the other ecosystems of an SBOM: those of --ecosystem-repos,
+// else those of the resolver repositories of the --server-id. The components of the ecosystems left without one
+// aren't audited.
+func getEcosystemRegistries(c *components.Context, ecosystems []string) (map[string]string, error) {
+	if len(ecosystems) == 0 {
+		return nil, nil
+	}
+	repos, err := parseEcosystemRepos(flagOrEnv(c, ecosystemReposFlag, ecosystemReposEnv))
+	if err != nil {
+		return nil, err
+	}
+	serverID := flagOrEnv(c, serverIDFlag, serverIDEnv)
+	server, err := loadServerDetails(serverID)
+	if err != nil {
+		return nil, err
+	}
+	artifactoryURL := flagOrEnv(c, artifactoryURLFlag, artifactoryURLEnv)
+	if artifactoryURL == "" {
+		artifactoryURL = serverArtifactoryURL(server)
+	}
+	registries := make(map[string]string)
+	for _, ecosystem := range ecosystems {
+		repo := repos[ecosystem]
+		if repo == "" && server != nil {
+			repo = resolverRepo(ecosystem, serverID)
+		}
+		if strings.Contains(repo, "://") {
+			registries[ecosystem] = strings.TrimSuffix(repo, "/")
+		} else if repo != "" && artifactoryURL != "" {
+			if registries[ecosystem], err = buildRegistryURL(ecosystem, artifactoryURL, repo); err != nil {
+				return nil, err
+			}
+		} else {
+			log.Warn(fmt.Sprintf("No %s registry to check the %s components of the SBOM against: set --%s %s=<repo>", ecosystem, ecosystem, ecosystemReposFlag, ecosystem))
+			continue
+		}
+		log.Info(fmt.Sprintf("Checking the %s components of the SBOM against %s", ecosystem, stripURLCredentials(registries[ecosystem])))
+	}
+	return registries, nil
+}
+
+// parseEcosystemRepos reads the ecosystem=repo pairs of --ecosystem-repos
+func parseEcosystemRepos(value string) (map[string]string, error) {
+	repos := make(map[string]string)
+	for _, item := range splitList(value) {
+		ecosystem, repo, found := strings.Cut(item, "=")
+		ecosystem, repo = strings.ToLower(strings.TrimSpace(ecosystem)), strings.TrimSpace(repo)
+		if !found || repo == "" {
+			return nil, fmt.Errorf("invalid --%s entry '%s'. Expected <ecosystem>=<repo>", ecosystemReposFlag, item)
+		}
+		if _, supported := registryPaths[ecosystem]; !supported {
+			return nil, fmt.Errorf("invalid --%s entry '%s'. Expected an ecosystem of %s", ecosystemReposFlag, item, strings.Join(supportedEcosystems(), ", "))
+		}
+		repos[ecosystem] = repo
+	}
+	return repos, nil
+}
+
+// skipUnroutedDependencies leaves out the components of the ecosystems without a registry to check them against
+func skipUnroutedDependencies(deps []audit.Dependency, registry *registryConfiguration) []audit.Dependency {
+	ecosystem := registry.ecosystem
+	if ecosystem == "" {
+		ecosystem = ecosystemNpm
+	}
+	kept := make([]audit.Dependency, 0, len(deps))
+	skipped := make(map[string]int)
+	for _, dep := range deps {
+		if dep.Ecosystem != "" && dep.Ecosystem != ecosystem && registry.ecosystemRegistries[dep.Ecosystem] == "" {
+			skipped[dep.Ecosystem]++
+			continue
+		}
+		kept = append(kept, dep)
+	}
+	for _, ecosystem := range sortedKeys(skipped) {
+		log.Warn(fmt.Sprintf("Skipping %d %s components without a registry (--%s)", skipped[ecosystem], ecosystem, ecosystemReposFlag))
+	}
+	return kept
+}
+
+// otherEcosystemRegistries returns the registries of the other ecosystems of an SBOM, which share the credentials
+// and the client of the package checks
+func (registry *registryConfiguration) otherEcosystemRegistries() map[string]*audit.Registry {
+	if len(registry.ecosystemRegistries) == 0 {
+		return nil
+	}
+	registries := make(map[string]*audit.Registry, len(registry.ecosystemRegistries))
+	for ecosystem, registryURL := range registry.ecosystemRegistries {
+		registries[ecosystem] = &audit.Registry{
+			URL:         registryURL,
+			AccessToken: registry.accessToken,
+			User:        registry.user,
+			Password:    registry.password,
+			MirrorHosts: registry.mirrorHosts,
+			CurationAPI: registry.curationAPI && ecosystem == ecosystemNpm,
+			Client:      registry.packageCheckClient(),
+			Retry:       registry.retry,
+			Ecosystem:   ecosystem,
+		}
+	}
+	return registries
+}
+
+// samePath reports whether two paths name the same file
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
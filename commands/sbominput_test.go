@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputEcosystems(t *testing.T) {
+	ecosystem, others, err := inputEcosystems(filepath.Join("..", "audit", "testdata", "sbom", "bom.cdx.json"))
+	require.NoError(t, err)
+	assert.Equal(t, audit.EcosystemNpm, ecosystem)
+	assert.Equal(t, []string{audit.EcosystemGo, audit.EcosystemMaven, audit.EcosystemPyPI}, others)
+
+	ecosystem, others, err = inputEcosystems("poetry.lock")
+	require.NoError(t, err)
+	assert.Equal(t, audit.EcosystemPyPI, ecosystem)
+	assert.Empty(t, others)
+}
+
+func TestParseEcosystemRepos(t *testing.T) {
+	repos, err := parseEcosystemRepos("pypi=pypi-remote, GO=https://acme.jfrog.io/artifactory/api/go/go-remote")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"pypi": "pypi-remote", "go": "https://acme.jfrog.io/artifactory/api/go/go-remote"}, repos)
+
+	_, err = parseEcosystemRepos("pypi")
+	assert.EqualError(t, err, "invalid --ecosystem-repos entry 'pypi'. Expected <ecosystem>=<repo>")
+	_, err = parseEcosystemRepos("cobol=cobol-remote")
+	assert.ErrorContains(t, err, "Expected an ecosystem of cargo, composer")
+}
+
+func TestAuditSBOMInput(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/pypi/"):
+			w.Write([]byte(`<a href="../../packages/requests-2.31.0.tar.gz">requests-2.31.0.tar.gz</a>`))
+		case strings.Contains(r.URL.Path, "react-dom"):
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	notifications, err := newNotificationDispatcher(&notificationConfig{}, nil)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	conf := &auditConfiguration{
+		registry: &registryConfiguration{
+			registryURL:         server.URL + "/api/npm/npm-remote",
+			ecosystem:           audit.EcosystemNpm,
+			ecosystemRegistries: map[string]string{audit.EcosystemPyPI: server.URL + "/api/pypi/pypi-remote/simple"},
+			requestTimeout:      time.Minute,
+		},
+		lockFile:   filepath.Join("..", "audit", "testdata", "sbom", "bom.cdx.json"),
+		workers:    2,
+		treeOutput: filepath.Join(dir, defaultTreeFileName),
+		output:     filepath.Join(dir, "results.json"),
+		display:    newDisplayFormat(true),
+		stages:     newRunStages(nil),
+
+		notifications: notifications,
+	}
+	require.NoError(t, runAudit(context.Background(), conf))
+
+	// The Go and Maven components have no registry
+	report, err := loadAuditReport(conf.output)
+	require.NoError(t, err)
+	statuses := make(map[string]int)
+	for _, entry := range report.Results {
+		statuses[entry.Name] = entry.StatusCode
+	}
+	assert.Equal(t, map[string]int{"@babel/core": 200, "react": 200, "react-dom": 403, "requests": 200, "semver": 200}, statuses)
+	assert.Contains(t, requested, "/api/pypi/pypi-remote/simple/requests/")
+}
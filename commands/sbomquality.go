@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NTIA minimum elements of the components of an SBOM, besides their names
+const (
+	sbomElementSupplier      = "supplier"
+	sbomElementVersion       = "version"
+	sbomElementIdentifier    = "unique identifier"
+	sbomElementRelationships = "relationships"
+
+	// Elements of the SBOM data itself
+	sbomElementAuthor    = "author"
+	sbomElementTimestamp = "timestamp"
+
+	// sbomDocumentGap is the component of the gaps of the document rather than of a component
+	sbomDocumentGap = "(document)"
+
+	// Components the gaps of an SBOM list, the others are counted
+	maxListedSBOMGaps = 20
+)
+
+// sbomGap lists the NTIA minimum elements a component of an SBOM misses
+type sbomGap struct {
+	component string
+	missing   []string
+}
+
+// sbomQuality is the outcome of the checks of an SBOM: its components, and the gaps of those and of the document
+type sbomQuality struct {
+	components int
+	gaps       []sbomGap
+}
+
+// checkCycloneDXQuality checks the components of a BOM for the NTIA minimum elements: a supplier, a version, a
+// package URL and bom-ref no other component has, and a place in the dependencies, whether as a dependency or with
+// its own. The metadata must name the tools or authors of the BOM and its timestamp.
+func checkCycloneDXQuality(bom *cycloneDXBOM) sbomQuality {
+	var gaps []sbomGap
+	var document []string
+	if len(bom.Metadata.Tools.Components) == 0 && len(bom.Metadata.Authors) == 0 {
+		document = append(document, sbomElementAuthor)
+	}
+	if bom.Metadata.Timestamp == "" {
+		document = append(document, sbomElementTimestamp)
+	}
+	if len(document) > 0 {
+		gaps = append(gaps, sbomGap{component: sbomDocumentGap, missing: document})
+	}
+
+	refs, purls := make(map[string]int), make(map[string]int)
+	for _, component := range bom.Components {
+		refs[component.BOMRef]++
+		purls[component.PURL]++
+	}
+	related := make(map[string]bool)
+	for _, dependency := range bom.Dependencies {
+		related[dependency.Ref] = true
+		for _, ref := range dependency.DependsOn {
+			related[ref] = true
+		}
+	}
+	for _, component := range bom.Components {
+		var missing []string
+		if component.Supplier == nil || component.Supplier.Name == "" {
+			missing = append(missing, sbomElementSupplier)
+		}
+		if component.Version == "" {
+			missing = append(missing, sbomElementVersion)
+		}
+		if component.BOMRef == "" || component.PURL == "" || refs[component.BOMRef] > 1 || purls[component.PURL] > 1 {
+			missing = append(missing, sbomElementIdentifier)
+		}
+		if component.BOMRef == "" || !related[component.BOMRef] {
+			missing = append(missing, sbomElementRelationships)
+		}
+		if len(missing) > 0 {
+			name := component.Name
+			if component.Group != "" {
+				name = component.Group + "/" + name
+			}
+			gaps = append(gaps, sbomGap{component: sbomComponentName(name, component.Version), missing: missing})
+		}
+	}
+	return sbomQuality{components: len(bom.Components), gaps: gaps}
+}
+
+// checkSPDXQuality checks the packages of a document as checkCycloneDXQuality does the components of a BOM: a
+// supplier other than NOASSERTION, a versionInfo, a purl external reference and an SPDXID of its own, and a
+// relationship. The packages the document describes are its subject rather than its components.
+func checkSPDXQuality(document *spdxDocument) sbomQuality {
+	var gaps []sbomGap
+	components := 0
+	var missingDocument []string
+	if len(document.CreationInfo.Creators) == 0 {
+		missingDocument = append(missingDocument, sbomElementAuthor)
+	}
+	if document.CreationInfo.Created == "" {
+		missingDocument = append(missingDocument, sbomElementTimestamp)
+	}
+	if len(missingDocument) > 0 {
+		gaps = append(gaps, sbomGap{component: sbomDocumentGap, missing: missingDocument})
+	}
+
+	ids := make(map[string]int)
+	for _, pkg := range document.Packages {
+		ids[pkg.SPDXID]++
+	}
+	related, described := make(map[string]bool), make(map[string]bool)
+	for _, relationship := range document.Relationships {
+		if relationship.SPDXElementID == document.SPDXID && relationship.RelationshipType == "DESCRIBES" {
+			described[relationship.RelatedSPDXElement] = true
+			continue
+		}
+		related[relationship.SPDXElementID] = true
+		related[relationship.RelatedSPDXElement] = true
+	}
+	for _, pkg := range document.Packages {
+		if described[pkg.SPDXID] {
+			continue
+		}
+		components++
+		var missing []string
+		if pkg.Supplier == "" || pkg.Supplier == spdxNoAssertion {
+			missing = append(missing, sbomElementSupplier)
+		}
+		if pkg.VersionInfo == "" {
+			missing = append(missing, sbomElementVersion)
+		}
+		if pkg.SPDXID == "" || ids[pkg.SPDXID] > 1 || !hasPURL(pkg) {
+			missing = append(missing, sbomElementIdentifier)
+		}
+		if !related[pkg.SPDXID] {
+			missing = append(missing, sbomElementRelationships)
+		}
+		if len(missing) > 0 {
+			gaps = append(gaps, sbomGap{component: sbomComponentName(pkg.Name, pkg.VersionInfo), missing: missing})
+		}
+	}
+	return sbomQuality{components: components, gaps: gaps}
+}
+
+func hasPURL(pkg spdxPackage) bool {
+	for _, ref := range pkg.ExternalRefs {
+		if ref.ReferenceType == "purl" && ref.ReferenceLocator != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func sbomComponentName(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + "@" + version
+}
+
+// print lists the components of the SBOM missing NTIA minimum elements, which SBOM consumers may reject it for. The
+// elements all the components miss, such as the suppliers lock files don't record, are reported once.
+func (q sbomQuality) print(path string, display *displayFormat) {
+	if len(q.gaps) == 0 {
+		return
+	}
+	counts := make(map[string]int)
+	for _, gap := range q.gaps {
+		if gap.component == sbomDocumentGap {
+			continue
+		}
+		for _, element := range gap.missing {
+			counts[element]++
+		}
+	}
+	var summary []string
+	everywhere := make(map[string]bool)
+	for _, element := range []string{sbomElementSupplier, sbomElementVersion, sbomElementIdentifier, sbomElementRelationships} {
+		switch {
+		case counts[element] == 0:
+		case counts[element] == q.components:
+			everywhere[element] = true
+			summary = append(summary, fmt.Sprintf("%s of all %s components", element, display.count(q.components)))
+		default:
+			summary = append(summary, fmt.Sprintf("%s of %s components", element, display.count(counts[element])))
+		}
+	}
+	var listed []sbomGap
+	for _, gap := range q.gaps {
+		var missing []string
+		for _, element := range gap.missing {
+			if !everywhere[element] {
+				missing = append(missing, element)
+			}
+		}
+		if gap.component == sbomDocumentGap {
+			summary = append(summary, strings.Join(missing, " and ")+" of the document")
+		} else if len(missing) > 0 {
+			listed = append(listed, sbomGap{component: gap.component, missing: missing})
+		}
+	}
+
+	fmt.Printf("\n\nSBOM gaps of %s (NTIA minimum elements): missing the %s", path, strings.Join(summary, ", the "))
+	for i, gap := range listed {
+		if i == maxListedSBOMGaps {
+			fmt.Printf("\n... and %s more components", display.count(len(listed)-i))
+			break
+		}
+		fmt.Printf("\n%s %s", gap.component, display.status("⚠️ Missing "+strings.Join(gap.missing, ", ")))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCycloneDXQuality(t *testing.T) {
+	bom := &cycloneDXBOM{
+		Metadata: cycloneDXMetadata{Timestamp: "2026-10-14T09:00:00Z", Authors: []cycloneDXContact{{Name: "Platform team"}}},
+		Components: []cycloneDXComponent{
+			{BOMRef: "pkg:npm/react@18.2.0", Supplier: &cycloneDXOrg{Name: "Meta"}, Name: "react", Version: "18.2.0", PURL: "pkg:npm/react@18.2.0"},
+			{BOMRef: "pkg:npm/loose-envify@1.4.0", Name: "loose-envify", Version: "1.4.0", PURL: "pkg:npm/loose-envify@1.4.0"},
+			{BOMRef: "pkg:npm/%40acme/ui", Supplier: &cycloneDXOrg{Name: "Acme"}, Group: "@acme", Name: "ui", PURL: "pkg:npm/%40acme/ui"},
+			{BOMRef: "pkg:npm/left-pad@1.3.0", Supplier: &cycloneDXOrg{Name: "Azer"}, Name: "left-pad", Version: "1.3.0", PURL: "pkg:npm/left-pad@1.3.0"},
+		},
+		Dependencies: []cycloneDXDependency{
+			{Ref: "project", DependsOn: []string{"pkg:npm/react@18.2.0", "pkg:npm/%40acme/ui"}},
+			{Ref: "pkg:npm/react@18.2.0", DependsOn: []string{"pkg:npm/loose-envify@1.4.0"}},
+		},
+	}
+	quality := checkCycloneDXQuality(bom)
+	assert.Equal(t, 4, quality.components)
+	assert.Equal(t, []sbomGap{
+		{component: "loose-envify@1.4.0", missing: []string{sbomElementSupplier}},
+		{component: "@acme/ui", missing: []string{sbomElementVersion}},
+		{component: "left-pad@1.3.0", missing: []string{sbomElementRelationships}},
+	}, quality.gaps)
+
+	// Components sharing a package URL can't be told apart
+	bom.Metadata = cycloneDXMetadata{}
+	bom.Components = append(bom.Components, bom.Components[0])
+	bom.Components[4].BOMRef = "react-copy"
+	assert.Equal(t, []sbomGap{
+		{component: sbomDocumentGap, missing: []string{sbomElementAuthor, sbomElementTimestamp}},
+		{component: "react@18.2.0", missing: []string{sbomElementIdentifier}},
+		{component: "loose-envify@1.4.0", missing: []string{sbomElementSupplier}},
+		{component: "@acme/ui", missing: []string{sbomElementVersion}},
+		{component: "left-pad@1.3.0", missing: []string{sbomElementRelationships}},
+		{component: "react@18.2.0", missing: []string{sbomElementIdentifier, sbomElementRelationships}},
+	}, checkCycloneDXQuality(bom).gaps)
+}
+
+func TestSPDXQuality(t *testing.T) {
+	purl := func(locator string) []spdxExternalRef {
+		return []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: locator}}
+	}
+	document := &spdxDocument{
+		SPDXID:       spdxDocumentID,
+		CreationInfo: spdxCreationInfo{Created: "2026-10-14T09:00:00Z", Creators: []string{"Tool: ca-extension-1.0.0"}},
+		Packages: []spdxPackage{
+			{SPDXID: spdxProjectID, Name: "webapp"},
+			{SPDXID: "SPDXRef-Package-react-18.2.0", Name: "react", VersionInfo: "18.2.0", Supplier: "Organization: Meta", ExternalRefs: purl("pkg:npm/react@18.2.0")},
+			{SPDXID: "SPDXRef-Package-loose-envify-1.4.0", Name: "loose-envify", VersionInfo: "1.4.0", Supplier: spdxNoAssertion, ExternalRefs: purl("pkg:npm/loose-envify@1.4.0")},
+			{SPDXID: "SPDXRef-Package-ui", Name: "@acme/ui", Supplier: "Organization: Acme", ExternalRefs: purl("pkg:npm/%40acme/ui")},
+			{SPDXID: "SPDXRef-Package-left-pad-1.3.0", Name: "left-pad", VersionInfo: "1.3.0", Supplier: "Person: Azer"},
+		},
+		Relationships: []spdxRelationship{
+			{SPDXElementID: spdxDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: spdxProjectID},
+			{SPDXElementID: spdxProjectID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-react-18.2.0"},
+			{SPDXElementID: spdxProjectID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-ui"},
+			{SPDXElementID: "SPDXRef-Package-react-18.2.0", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-loose-envify-1.4.0"},
+		},
+	}
+	// The project the document describes is its subject, not a component
+	quality := checkSPDXQuality(document)
+	assert.Equal(t, 4, quality.components)
+	assert.Equal(t, []sbomGap{
+		{component: "loose-envify@1.4.0", missing: []string{sbomElementSupplier}},
+		{component: "@acme/ui", missing: []string{sbomElementVersion}},
+		{component: "left-pad@1.3.0", missing: []string{sbomElementIdentifier, sbomElementRelationships}},
+	}, quality.gaps)
+
+	document.CreationInfo = spdxCreationInfo{}
+	assert.Equal(t, sbomGap{component: sbomDocumentGap, missing: []string{sbomElementAuthor, sbomElementTimestamp}}, checkSPDXQuality(document).gaps[0])
+}
+
+func TestGeneratedSBOMQuality(t *testing.T) {
+	lockFile := filepath.Join("..", "audit", "testdata", "pnpm-workspace", "pnpm-lock.yaml")
+	tree, err := audit.ParseLockFile(lockFile)
+	require.NoError(t, err)
+	report := &AuditReport{LockFile: lockFile}
+
+	// Lock files don't record the suppliers of packages, the other elements are all there
+	bom, err := newCycloneDXBOM(tree, report, "")
+	require.NoError(t, err)
+	quality := checkCycloneDXQuality(bom)
+	assert.Equal(t, len(tree.Packages), quality.components)
+	require.Len(t, quality.gaps, len(tree.Packages))
+	for _, gap := range quality.gaps {
+		assert.Equal(t, []string{sbomElementSupplier}, gap.missing, gap.component)
+	}
+
+	document, err := newSPDXDocument(tree, report, "")
+	require.NoError(t, err)
+	quality = checkSPDXQuality(document)
+	assert.Equal(t, len(tree.Packages), quality.components)
+	for _, gap := range quality.gaps {
+		assert.Equal(t, []string{sbomElementSupplier}, gap.missing, gap.component)
+	}
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"embed"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	schemaFlag = "schema"
+
+	schemaReport = "report"
+	schemaTree   = "tree"
+
+	// outputSchemaVersion is the <major>.<minor> version of the report and tree schemas. Minor versions only add
+	// optional fields; any other change bumps the major, which older releases refuse to read.
+	outputSchemaVersion = "1.16"
+)
+
+//go:embed schemas/*.schema.json
+var schemaFiles embed.FS
+
+// outputSchema returns the JSON schema of the report or tree output
+func outputSchema(name string) ([]byte, error) {
+	if name != schemaReport && name != schemaTree {
+		return nil, fmt.Errorf("unsupported schema '%s'. Expected %s or %s", name, schemaReport, schemaTree)
+	}
+	return schemaFiles.ReadFile("schemas/" + name + ".schema.json")
+}
+
+// checkSchemaVersion rejects outputs written with a newer major schema version. Outputs written before schema
+// versioning have none and are read as 1.0.
+func checkSchemaVersion(path, version string) error {
+	if version == "" {
+		return nil
+	}
+	major, err := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	if err != nil {
+		return fmt.Errorf("invalid schema version '%s' in %s", version, path)
+	}
+	supported, _ := strconv.Atoi(strings.SplitN(outputSchemaVersion, ".", 2)[0])
+	if major > supported {
+		return fmt.Errorf("%s uses schema version %s, this version of ca-extension supports %d.x. Upgrade ca-extension to read it", path, version, supported)
+	}
+	return nil
+}
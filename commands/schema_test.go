@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+// assertSchemaCovers checks that every field of the marshaled output is declared by the schema
+func assertSchemaCovers(t *testing.T, name string, output interface{}) {
+	data, err := outputSchema(name)
+	assert.NoError(t, err)
+	var schema struct {
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &schema))
+
+	marshaled, err := json.Marshal(output)
+	assert.NoError(t, err)
+	var fields map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(marshaled, &fields))
+	for field := range fields {
+		assert.Contains(t, schema.Properties, field)
+	}
+	for _, field := range schema.Required {
+		assert.Contains(t, fields, field)
+	}
+}
+
+func TestReportSchemaCoversReport(t *testing.T) {
+	report := newAuditReport("pnpm-lock.yaml", []audit.AuditResult{{Name: "lodash", Version: "4.17.21", StatusCode: 200}})
+	report.Metadata = &RunMetadata{ToolVersion: appVersion}
+	report.BinaryDownloads = []BinaryDownload{{Name: "sharp", Version: "0.33.2", Tool: "prebuild-install", Host: "github.com"}}
+	report.PeerGaps = []PeerGap{{Importer: ".", Name: "react-dom", Version: "18.2.0", Peer: "react", Range: "^18.2.0"}}
+	report.Suggestions = []Suggestion{{Name: "lodash", Version: "4.17.20", SuggestedVersion: "4.17.21"}}
+	report.Publications = &PublicationReport{AsOf: time.Now()}
+	assert.Equal(t, outputSchemaVersion, report.SchemaVersion)
+	assertSchemaCovers(t, schemaReport, report)
+}
+
+func TestTreeSchemaCoversTree(t *testing.T) {
+	parsed, err := audit.ParseLockFile(filepath.Join("..", "audit", "testdata", "pnpm-lock.yaml"))
+	assert.NoError(t, err)
+	treePath := filepath.Join(t.TempDir(), "pnpm_dependency_tree.json")
+	assert.NoError(t, saveDependencyTree(parsed, treePath))
+	tree, err := loadDependencyTree(treePath)
+	assert.NoError(t, err)
+	assert.Equal(t, outputSchemaVersion, tree.SchemaVersion)
+	assertSchemaCovers(t, schemaTree, tree)
+}
+
+func TestOutputSchemaRejectsUnknownOutput(t *testing.T) {
+	_, err := outputSchema("index")
+	assert.Error(t, err)
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	assert.NoError(t, checkSchemaVersion("results.json", ""))
+	assert.NoError(t, checkSchemaVersion("results.json", "1.0"))
+	assert.NoError(t, checkSchemaVersion("results.json", "1.10"))
+	assert.Error(t, checkSchemaVersion("results.json", "2.0"))
+	assert.Error(t, checkSchemaVersion("results.json", "v1"))
+}
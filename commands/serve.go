@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	portFlag = "port"
+
+	defaultPort = 8080
+)
+
+func GetServeCommand() components.Command {
+	return components.Command{
+		Name:        "serve",
+		Description: "Serves curation checks over HTTP, for editors, hooks and scripts.",
+		Aliases:     []string{"s"},
+		Flags:       getServeFlags(),
+		EnvVars:     getRegistryEnvVars(),
+		Action: func(c *components.Context) error {
+			return serveCmd(c)
+		},
+	}
+}
+
+func getServeFlags() []components.Flag {
+	return append(getRegistryFlags(),
+		components.NewStringFlag(
+			portFlag,
+			"Port to listen on",
+			components.WithIntDefaultValue(defaultPort),
+		),
+	)
+}
+
+func serveCmd(c *components.Context) error {
+	if len(c.Arguments) != 0 {
+		return wrongArguments("serve", "", len(c.Arguments))
+	}
+	registry, err := getRegistryConfiguration(c)
+	if err != nil {
+		return err
+	}
+	port, err := c.GetIntFlagValue(portFlag)
+	if err != nil {
+		return err
+	}
+
+	address := fmt.Sprintf(":%d", port)
+	log.Info(fmt.Sprintf("Serving curation checks for %s on %s", registry.registryURL, address))
+	return http.ListenAndServe(address, newServeHandler(registry))
+}
+
+// newServeHandler exposes GET /healthz and GET /api/v1/check?package=<name>@<version>
+func newServeHandler(registry *registryConfiguration) http.Handler {
+	// Shared by the checks, which reuse its connections to the registry
+	return newCheckHandler(registry.auditRegistry().Check)
+}
+
+// newCheckHandler serves the checks of the serve command and the daemon
+func newCheckHandler(check func(audit.Dependency) audit.AuditResult) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/api/v1/check", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		deps, err := parsePackageSpecs([]string{r.URL.Query().Get("package")})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result := check(deps[0])
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(newResultEntry(result)); err != nil {
+			log.Warn("Could not write response:", err.Error())
+		}
+	})
+	return mux
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeCheck(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/@types/keyv/-/keyv-3.1.4.tgz" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer registry.Close()
+
+	server := httptest.NewServer(newServeHandler(&registryConfiguration{registryURL: registry.URL}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/check?package=@types/keyv@3.1.4")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var entry ResultEntry
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&entry))
+	assert.Equal(t, "@types/keyv", entry.Name)
+	assert.Equal(t, http.StatusForbidden, entry.StatusCode)
+}
+
+func TestServeCheckInvalidPackage(t *testing.T) {
+	server := httptest.NewServer(newServeHandler(&registryConfiguration{registryURL: "http://localhost"}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/check?package=lodash")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestServeReusesRegistryConnections(t *testing.T) {
+	var connections int32
+	registry := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	registry.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	registry.Start()
+	defer registry.Close()
+
+	server := httptest.NewServer(newServeHandler(&registryConfiguration{registryURL: registry.URL}))
+	defer server.Close()
+
+	for _, spec := range []string{"abbrev@1.1.1", "lodash@4.17.21", "yallist@4.0.0"} {
+		resp, err := http.Get(server.URL + "/api/v1/check?package=" + spec)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections))
+}
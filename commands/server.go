@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jfrog/jfrog-cli-core/v2/common/project"
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	serverIDFlag = "server-id"
+	serverIDEnv  = "CA_EXTENSION_SERVER_ID"
+)
+
+// projectTypes maps each ecosystem to the JFrog CLI projects whose resolver repository is its default, e.g. the
+// one 'jf npm-config' writes to .jfrog/projects/npm.yaml
+var projectTypes = map[string][]project.ProjectType{
+	"npm":   {project.Npm, project.Pnpm, project.Yarn},
+	"pypi":  {project.Pip, project.Pipenv, project.Poetry},
+	"go":    {project.Go},
+	"maven": {project.Maven, project.Gradle},
+}
+
+func getServerIDFlag() components.Flag {
+	return components.NewStringFlag(
+		serverIDFlag,
+		"ID of a server configured with 'jf config' to read the Artifactory URL and credentials of, and the repository from the resolver of 'jf "+ecosystemNpm+"-config'",
+		components.WithHelpValue("id"),
+	)
+}
+
+// loadServerDetails reads the server of the JFrog CLI configuration, or returns nil when no server ID is given.
+// Refreshable tokens are left out, as the requests don't go through the JFrog CLI clients that refresh them.
+func loadServerDetails(serverID string) (*config.ServerDetails, error) {
+	if serverID == "" {
+		return nil, nil
+	}
+	server, err := config.GetSpecificConfig(serverID, false, true)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the '%s' server of the JFrog CLI configuration: %v", serverID, err)
+	}
+	return server, nil
+}
+
+// serverArtifactoryURL returns the Artifactory URL of a configured server, or of its platform when only that is set
+func serverArtifactoryURL(server *config.ServerDetails) string {
+	if server == nil {
+		return ""
+	}
+	if server.ArtifactoryUrl != "" {
+		return server.ArtifactoryUrl
+	}
+	return server.Url
+}
+
+// serverCredentials returns the credentials of a configured server: its access or reference token, or else its
+// user and password, which may be an API key
+func serverCredentials(server *config.ServerDetails) audit.Credentials {
+	if server == nil {
+		return audit.Credentials{}
+	}
+	if server.AccessToken != "" {
+		return audit.Credentials{AccessToken: server.AccessToken}
+	}
+	return audit.Credentials{User: server.User, Password: server.Password}
+}
+
+// resolverRepo returns the resolver repository the JFrog CLI project configuration of the ecosystem sets for the
+// server, or an empty string when there is none
+func resolverRepo(ecosystem, serverID string) string {
+	for _, projectType := range projectTypes[ecosystem] {
+		path, exists, err := project.GetProjectConfFilePath(projectType)
+		if err != nil || !exists {
+			continue
+		}
+		vConfig, err := project.ReadConfigFile(path, project.YAML)
+		if err != nil {
+			log.Debug(fmt.Sprintf("Ignoring %s: %v", path, err))
+			continue
+		}
+		prefix := project.ProjectConfigResolverPrefix + "."
+		if vConfig.GetString(prefix+project.ProjectConfigServerId) != serverID {
+			continue
+		}
+		repo := vConfig.GetString(prefix + project.ProjectConfigRepo)
+		if repo == "" {
+			// Maven resolvers name the repository releaseRepo
+			repo = vConfig.GetString(prefix + project.ProjectConfigReleaseRepo)
+		}
+		if repo != "" {
+			log.Debug(fmt.Sprintf("Using the resolver repository %s of %s", repo, path))
+			return repo
+		}
+	}
+	return ""
+}
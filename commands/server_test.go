@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configureServers writes the servers to a JFrog CLI configuration of a temporary home directory
+func configureServers(t *testing.T, servers ...*config.ServerDetails) string {
+	home := t.TempDir()
+	t.Setenv("JFROG_CLI_HOME_DIR", home)
+	require.NoError(t, config.SaveServersConf(servers))
+	return home
+}
+
+func TestRegistryFromServerID(t *testing.T) {
+	home := configureServers(t,
+		&config.ServerDetails{ServerId: "acme", Url: "https://acme.jfrog.io/", ArtifactoryUrl: "https://acme.jfrog.io/artifactory/", AccessToken: "reference-token"},
+		&config.ServerDetails{ServerId: "legacy", ArtifactoryUrl: "https://legacy.acme.io/artifactory/", User: "ci", Password: "api-key"},
+	)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, "projects"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, "projects", "npm.yaml"),
+		[]byte("version: 1\ntype: npm\nresolver:\n  repo: npm-curated\n  serverId: acme\n"), 0644))
+	t.Setenv(registryURLEnv, "")
+	t.Setenv(accessTokenEnv, "")
+
+	t.Setenv(serverIDEnv, "acme")
+	registry, err := getRegistryConfiguration(&components.Context{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://acme.jfrog.io/artifactory/api/npm/npm-curated", registry.registryURL)
+	assert.Equal(t, audit.Credentials{AccessToken: "reference-token"}, registry.credentials())
+
+	// The resolver of another server isn't used, and the repository is then required
+	t.Setenv(serverIDEnv, "legacy")
+	_, err = getRegistryConfiguration(&components.Context{})
+	assert.ErrorContains(t, err, "repository key")
+	t.Setenv(repoEnv, "npm-remote")
+	registry, err = getRegistryConfiguration(&components.Context{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://legacy.acme.io/artifactory/api/npm/npm-remote", registry.registryURL)
+	assert.Equal(t, audit.Credentials{User: "ci", Password: "api-key"}, registry.credentials())
+
+	// An explicit access token takes precedence over the credentials of the server
+	t.Setenv(accessTokenEnv, "token")
+	registry, err = getRegistryConfiguration(&components.Context{})
+	require.NoError(t, err)
+	assert.Equal(t, audit.Credentials{AccessToken: "token"}, registry.credentials())
+
+	t.Setenv(serverIDEnv, "missing")
+	_, err = getRegistryConfiguration(&components.Context{})
+	assert.ErrorContains(t, err, "error reading the 'missing' server of the JFrog CLI configuration")
+}
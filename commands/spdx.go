@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	spdxSpecVersion = "2.3"
+
+	spdxNoAssertion = "NOASSERTION"
+	spdxDocumentID  = "SPDXRef-DOCUMENT"
+	spdxProjectID   = "SPDXRef-Project"
+	// Base of the namespaces of the documents, made unique by the project name and a random UUID
+	spdxNamespaceBase = "https://github.com/chaitanyagovande/ca-extension/spdx/"
+)
+
+// Runs of characters not allowed in SPDX identifiers
+var spdxIDPattern = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// spdxAlgorithms maps the algorithms of Subresource Integrity values to those of SPDX checksums
+var spdxAlgorithms = map[string]string{
+	"sha512": "SHA512",
+	"sha384": "SHA384",
+	"sha256": "SHA256",
+	"sha1":   "SHA1",
+}
+
+// spdxDocument represents the subset of an SPDX 2.3 JSON document describing the packages of a lock file
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+	Comment  string   `json:"comment,omitempty"`
+}
+
+type spdxPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	Supplier              string            `json:"supplier,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	Annotations           []spdxAnnotation  `json:"annotations,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxAnnotation struct {
+	AnnotationDate string `json:"annotationDate"`
+	AnnotationType string `json:"annotationType"`
+	Annotator      string `json:"annotator"`
+	Comment        string `json:"comment"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// newSPDXDocument describes the project of the lock file and its packages, as newCycloneDXBOM does: each package
+// has its package URL, the download location and checksums of its resolution when the lock file records them, and
+// its curation properties as annotations, e.g. ca-extension:curation:status=blocked
+func newSPDXDocument(tree *audit.DependencyTree, report *AuditReport, registryURL string) (*spdxDocument, error) {
+	uuid, err := newUUID()
+	if err != nil {
+		return nil, fmt.Errorf("error generating the SPDX document namespace: %v", err)
+	}
+	ecosystem := audit.EcosystemOf(audit.PackageManagerFor(report.LockFile))
+	created := time.Now().UTC().Format(time.RFC3339)
+	tool := "Tool: ca-extension-" + appVersion
+	name := projectName(report.LockFile)
+	document := &spdxDocument{
+		SPDXVersion:       "SPDX-" + spdxSpecVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            spdxDocumentID,
+		Name:              name,
+		DocumentNamespace: spdxNamespaceBase + spdxIDPattern.ReplaceAllString(name, "-") + "-" + uuid,
+		CreationInfo:      spdxCreationInfo{Created: created, Creators: []string{tool}},
+		Packages: []spdxPackage{{
+			SPDXID:                spdxProjectID,
+			Name:                  name,
+			DownloadLocation:      spdxNoAssertion,
+			PrimaryPackagePurpose: "APPLICATION",
+		}},
+		Relationships: []spdxRelationship{{SPDXElementID: spdxDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: spdxProjectID}},
+	}
+	if registryURL != "" {
+		document.CreationInfo.Comment = "Curation status checked against " + stripURLCredentials(registryURL)
+	}
+
+	ids := make(map[string]string)
+	used := make(map[string]bool)
+	for _, pkg := range sbomPackages(tree, report) {
+		base := strings.Trim(spdxIDPattern.ReplaceAllString(pkg.name+"-"+pkg.version, "-"), "-")
+		id := "SPDXRef-Package-" + base
+		for suffix := 2; used[id]; suffix++ {
+			id = fmt.Sprintf("SPDXRef-Package-%s-%d", base, suffix)
+		}
+		used[id] = true
+		if _, exists := ids[pkg.name]; !exists {
+			ids[pkg.name] = id
+		}
+		spdx := spdxPackage{
+			SPDXID:                id,
+			Name:                  pkg.name,
+			VersionInfo:           pkg.version,
+			DownloadLocation:      spdxDownloadLocation(pkg.resolution),
+			PrimaryPackagePurpose: "LIBRARY",
+			Checksums:             spdxChecksums(pkg.resolution),
+			ExternalRefs: []spdxExternalRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  packageURL(ecosystem, pkg.name, pkg.version),
+			}},
+		}
+		for _, property := range pkg.properties() {
+			spdx.Annotations = append(spdx.Annotations, spdxAnnotation{
+				AnnotationDate: created,
+				AnnotationType: "OTHER",
+				Annotator:      tool,
+				Comment:        property.Name + "=" + property.Value,
+			})
+		}
+		document.Packages = append(document.Packages, spdx)
+	}
+
+	direct, graph := sbomGraph(tree)
+	for _, name := range direct {
+		document.Relationships = append(document.Relationships, spdxRelationship{SPDXElementID: spdxProjectID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: ids[name]})
+	}
+	for _, name := range sortedKeys(graph) {
+		for _, dependsOn := range graph[name] {
+			document.Relationships = append(document.Relationships, spdxRelationship{SPDXElementID: ids[name], RelationshipType: "DEPENDS_ON", RelatedSPDXElement: ids[dependsOn]})
+		}
+	}
+	return document, nil
+}
+
+// spdxDownloadLocation returns where the lock file resolved a package from: its tarball URL, or the commit of its
+// git repository, else NOASSERTION
+func spdxDownloadLocation(resolution map[string]interface{}) string {
+	if tarball, _ := resolution["tarball"].(string); strings.HasPrefix(tarball, "http://") || strings.HasPrefix(tarball, "https://") {
+		return stripURLCredentials(tarball)
+	}
+	repo, _ := resolution["repo"].(string)
+	commit, _ := resolution["commit"].(string)
+	if repo != "" && commit != "" {
+		if !strings.HasPrefix(repo, "git+") {
+			repo = "git+" + repo
+		}
+		return stripURLCredentials(repo) + "@" + commit
+	}
+	return spdxNoAssertion
+}
+
+// spdxChecksums converts the Subresource Integrity values of a resolution, e.g. sha512-<base64>, to hex checksums
+func spdxChecksums(resolution map[string]interface{}) []spdxChecksum {
+	integrity, _ := resolution["integrity"].(string)
+	var checksums []spdxChecksum
+	for _, value := range strings.Fields(integrity) {
+		algorithm, digest, found := strings.Cut(value, "-")
+		decoded, err := base64.StdEncoding.DecodeString(digest)
+		if !found || err != nil || spdxAlgorithms[algorithm] == "" {
+			continue
+		}
+		checksums = append(checksums, spdxChecksum{Algorithm: spdxAlgorithms[algorithm], ChecksumValue: hex.EncodeToString(decoded)})
+	}
+	return checksums
+}
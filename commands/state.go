@@ -0,0 +1,348 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	stateOutputFlag  = "output"
+	stateResultsFlag = "results"
+	stateDirFlag     = "dir"
+
+	defaultStateArchive = "ca-extension-state.tar.gz"
+	defaultStateDir     = "ca-extension-state"
+
+	// Version of the state archive layout
+	stateSchemaVersion = "1"
+
+	stateManifestFile = "manifest.json"
+	stateConfigFile   = "config.txt"
+	stateCacheFile    = "outcomes.json"
+	stateResultsFile  = "results.json"
+	stateIgnoreFile   = "ignore.yaml"
+)
+
+// StateManifest describes a state archive: the run it was exported from and the files it bundles, by their path
+// in the archive
+type StateManifest struct {
+	SchemaVersion string            `json:"schemaVersion"`
+	ToolVersion   string            `json:"toolVersion"`
+	CreatedAt     string            `json:"createdAt"`
+	LockFile      string            `json:"lockFile"`
+	RegistryURL   string            `json:"registryURL,omitempty"`
+	Files         []string          `json:"files"`
+	Environment   map[string]string `json:"environment,omitempty"`
+}
+
+func GetStateNamespace() components.Namespace {
+	return components.Namespace{
+		Name:        "state",
+		Description: "Bundles the state of an audit into an archive, to reproduce it on another machine.",
+		Commands: []components.Command{
+			GetStateExportCommand(),
+			GetStateImportCommand(),
+		},
+	}
+}
+
+func GetStateExportCommand() components.Command {
+	return components.Command{
+		Name:        "export",
+		Description: "Writes an archive of the lock file, the configuration, the cached outcomes of its packages and the last results, with secrets masked.",
+		Arguments: []components.Argument{
+			{
+				Name:        "lock-file",
+				Description: "The lock file of the audit to reproduce.",
+			},
+		},
+		Flags:   getStateExportFlags(),
+		EnvVars: getRegistryEnvVars(),
+		Action: func(c *components.Context) error {
+			return stateExportCmd(c)
+		},
+	}
+}
+
+func getStateExportFlags() []components.Flag {
+	return append(getRegistryFlags(),
+		components.NewStringFlag(
+			stateOutputFlag,
+			"Path of the archive to write",
+			components.WithStrDefaultValue(defaultStateArchive),
+		),
+		components.NewStringFlag(
+			stateResultsFlag,
+			"Path of the results of the last run, as written by 'audit --output', to bundle",
+			components.WithHelpValue("path"),
+		),
+		getIgnoreFileFlag(),
+		getCacheFileFlag(),
+		getCacheTTLFlag(),
+	)
+}
+
+func GetStateImportCommand() components.Command {
+	return components.Command{
+		Name:        "import",
+		Description: "Extracts an archive written by 'state export' and prints the audit command reproducing the run.",
+		Arguments: []components.Argument{
+			{
+				Name:        "archive",
+				Description: "The state archive.",
+			},
+		},
+		Flags: []components.Flag{
+			components.NewStringFlag(
+				stateDirFlag,
+				"Directory to extract the archive to",
+				components.WithStrDefaultValue(defaultStateDir),
+			),
+		},
+		Action: func(c *components.Context) error {
+			return stateImportCmd(c)
+		},
+	}
+}
+
+func stateExportCmd(c *components.Context) error {
+	if len(c.Arguments) != 1 {
+		return wrongArguments("state export", "<lock-file>", len(c.Arguments))
+	}
+	lockFile := c.Arguments[0]
+	registry, err := resolveRegistryConfiguration(c)
+	if err != nil {
+		return err
+	}
+	workers, err := getWorkers(c)
+	if err != nil {
+		return err
+	}
+	files, err := collectStateFiles(lockFile, c.GetStringFlagValue(stateResultsFlag), flagOrEnv(c, ignoreFileFlag, ignoreFileEnv))
+	if err != nil {
+		return err
+	}
+	files[stateConfigFile] = []byte(formatConfiguration(registry, workers, false) + describeProjectConfig())
+
+	cache, err := openOutcomeCache(c)
+	if err != nil {
+		return err
+	}
+	tree, err := audit.ParseLockFile(lockFile)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %v", filepath.Base(lockFile), err)
+	}
+	subset := cacheSubset(cache, registry, tree.Dependencies())
+	if len(subset.Outcomes) > 0 {
+		if files[stateCacheFile], err = json.MarshalIndent(subset, "", "  "); err != nil {
+			return fmt.Errorf("error marshaling JSON: %v", err)
+		}
+	}
+
+	manifest := &StateManifest{
+		SchemaVersion: stateSchemaVersion,
+		ToolVersion:   appVersion,
+		CreatedAt:     time.Now().UTC().Format(time.RFC3339),
+		LockFile:      filepath.Base(lockFile),
+		RegistryURL:   registry.registryURL,
+		Files:         sortedKeys(files),
+		Environment:   make(map[string]string),
+	}
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		if strings.HasPrefix(name, "CA_EXTENSION_") {
+			manifest.Environment[name] = sanitizeSetting(name, value)
+		}
+	}
+	if files[stateManifestFile], err = json.MarshalIndent(manifest, "", "  "); err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+
+	output := c.GetStringFlagValue(stateOutputFlag)
+	if err := writeStateArchive(output, files); err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("State of %s saved to %s, with %d cached outcomes", manifest.LockFile, output, len(subset.Outcomes)))
+	return nil
+}
+
+// collectStateFiles reads the files of the run bundled in the archive: the lock file and the pnpm-workspace.yaml
+// next to it, the project config file, the active profile, the ignore file and the results, keyed by their path in
+// the archive. Running the audit in the extracted directory then reads them as the exported run did.
+func collectStateFiles(lockFile, resultsPath, ignorePath string) (map[string][]byte, error) {
+	sources := map[string]string{filepath.Base(lockFile): lockFile}
+	if workspace := filepath.Join(filepath.Dir(lockFile), audit.PnpmWorkspaceFileName); fileExists(workspace) {
+		sources[audit.PnpmWorkspaceFileName] = workspace
+	}
+	if config := activeProjectConfig(); config != nil {
+		sources[projectConfigFileName] = config.Path
+	}
+	profilePath := os.Getenv(profileEnv)
+	if profilePath == "" {
+		profilePath = defaultProfilePath
+	}
+	if fileExists(profilePath) {
+		sources[filepath.ToSlash(defaultProfilePath)] = profilePath
+	}
+	if ignorePath != "" {
+		sources[stateIgnoreFile] = ignorePath
+	}
+	if resultsPath != "" {
+		sources[stateResultsFile] = resultsPath
+	}
+
+	files := make(map[string][]byte, len(sources))
+	for name, source := range sources {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", source, err)
+		}
+		files[name] = data
+	}
+	return files, nil
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// cacheSubset keeps the cached outcomes of the dependencies on the registry
+func cacheSubset(cache *outcomeCache, registry *registryConfiguration, deps []audit.Dependency) *outcomeCache {
+	subset := &outcomeCache{Outcomes: make(map[string]cachedOutcome)}
+	for _, dep := range deps {
+		if outcome, exists := cache.lookup(registry, dep); exists {
+			subset.Outcomes[outcomeKey(registry, dep)] = outcome
+		}
+	}
+	return subset
+}
+
+// writeStateArchive writes the files as a gzipped tar, in name order so archives of the same state are identical
+// but for their manifest
+func writeStateArchive(path string, files map[string][]byte) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating state archive: %v", err)
+	}
+	defer out.Close()
+	gz := gzip.NewWriter(out)
+	archive := tar.NewWriter(gz)
+	for _, name := range sortedKeys(files) {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name])), Typeflag: tar.TypeReg}
+		if err := archive.WriteHeader(header); err != nil {
+			return fmt.Errorf("error writing state archive: %v", err)
+		}
+		if _, err := archive.Write(files[name]); err != nil {
+			return fmt.Errorf("error writing state archive: %v", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("error writing state archive: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("error writing state archive: %v", err)
+	}
+	return out.Close()
+}
+
+// readStateArchive reads the files of a state archive, rejecting entries outside of it
+func readStateArchive(archivePath string) (map[string][]byte, error) {
+	in, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading state archive: %v", err)
+	}
+	defer in.Close()
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("error reading state archive %s: %v", archivePath, err)
+	}
+	archive := tar.NewReader(gz)
+	files := make(map[string][]byte)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading state archive %s: %v", archivePath, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("state archive %s has an entry outside of it: %s", archivePath, header.Name)
+		}
+		if files[name], err = io.ReadAll(archive); err != nil {
+			return nil, fmt.Errorf("error reading state archive %s: %v", archivePath, err)
+		}
+	}
+	if _, exists := files[stateManifestFile]; !exists {
+		return nil, fmt.Errorf("%s is not a state archive, it has no %s", archivePath, stateManifestFile)
+	}
+	return files, nil
+}
+
+func stateImportCmd(c *components.Context) error {
+	if len(c.Arguments) != 1 {
+		return wrongArguments("state import", "<archive>", len(c.Arguments))
+	}
+	files, err := readStateArchive(c.Arguments[0])
+	if err != nil {
+		return err
+	}
+	var manifest StateManifest
+	if err := json.Unmarshal(files[stateManifestFile], &manifest); err != nil {
+		return fmt.Errorf("error parsing %s: %v", stateManifestFile, err)
+	}
+	if manifest.SchemaVersion != stateSchemaVersion {
+		return fmt.Errorf("unsupported state archive version '%s'. Upgrade ca-extension to import it", manifest.SchemaVersion)
+	}
+
+	dir := c.GetStringFlagValue(stateDirFlag)
+	for _, name := range sortedKeys(files) {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("error creating %s: %v", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, files[name], 0644); err != nil {
+			return fmt.Errorf("error writing %s: %v", target, err)
+		}
+	}
+	log.Info(fmt.Sprintf("State of %s, exported by ca-extension %s on %s, extracted to %s", manifest.LockFile, manifest.ToolVersion, manifest.CreatedAt, dir))
+	for _, name := range sortedKeys(manifest.Environment) {
+		log.Info(fmt.Sprintf("Exported with %s=%s", name, manifest.Environment[name]))
+	}
+	fmt.Printf("Reproduce the run with:\n  cd %s && %s\n", dir, reproduceCommand(&manifest, files))
+	return nil
+}
+
+// reproduceCommand returns the audit command line running against the extracted files, answering the packages of
+// the cached outcomes from the archive instead of the registry
+func reproduceCommand(manifest *StateManifest, files map[string][]byte) string {
+	args := []string{appName, "audit", manifest.LockFile}
+	if manifest.RegistryURL != "" {
+		args = append(args, "--"+registryURLFlag+"="+manifest.RegistryURL)
+	}
+	if _, exists := files[stateCacheFile]; exists {
+		// The outcomes are as old as the archive, and keep answering however old it gets
+		args = append(args, "--"+cacheFlag, "--"+cacheFileFlag+"="+stateCacheFile, "--"+cacheTTLFlag+"=0")
+	}
+	if _, exists := files[stateIgnoreFile]; exists {
+		args = append(args, "--"+ignoreFileFlag+"="+stateIgnoreFile)
+	}
+	return strings.Join(args, " ")
+}
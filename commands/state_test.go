@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectStateFiles(t *testing.T) {
+	dir := t.TempDir()
+	lockFile := filepath.Join(dir, "pnpm-lock.yaml")
+	assert.NoError(t, os.WriteFile(lockFile, []byte("lockfileVersion: '6.0'\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, audit.PnpmWorkspaceFileName), []byte("packages:\n  - apps/*\n"), 0644))
+	results := filepath.Join(dir, "results.json")
+	assert.NoError(t, os.WriteFile(results, []byte("{}"), 0644))
+	t.Setenv(projectConfigEnv, filepath.Join(dir, "missing.yaml"))
+	t.Setenv(profileEnv, filepath.Join(dir, "profile.yaml"))
+
+	files, err := collectStateFiles(lockFile, results, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pnpm-lock.yaml", audit.PnpmWorkspaceFileName, stateResultsFile}, sortedKeys(files))
+
+	_, err = collectStateFiles(lockFile, "", filepath.Join(dir, "ignore.yaml"))
+	assert.Error(t, err)
+}
+
+func TestCacheSubset(t *testing.T) {
+	cache := &outcomeCache{Outcomes: map[string]cachedOutcome{
+		"npm https://acme.jfrog.io/artifactory/api/npm/npm-remote lodash@4.17.20": {StatusCode: 403},
+		"npm https://acme.jfrog.io/artifactory/api/npm/npm-remote react@18.2.0":   {StatusCode: 200},
+		"npm https://acme.jfrog.io/artifactory/api/npm/npm-other lodash@4.17.20":  {StatusCode: 200},
+	}}
+	subset := cacheSubset(cache, testCacheRegistry, []audit.Dependency{{Name: "lodash", Version: "4.17.20"}, {Name: "left-pad", Version: "1.3.0"}})
+	assert.Equal(t, map[string]cachedOutcome{"npm https://acme.jfrog.io/artifactory/api/npm/npm-remote lodash@4.17.20": {StatusCode: 403}}, subset.Outcomes)
+}
+
+func TestStateArchiveRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), defaultStateArchive)
+	files := map[string][]byte{
+		stateManifestFile:                    []byte(`{"schemaVersion":"1"}`),
+		"pnpm-lock.yaml":                     []byte("lockfileVersion: '6.0'\n"),
+		filepath.ToSlash(defaultProfilePath): []byte("name: acme\n"),
+	}
+	assert.NoError(t, writeStateArchive(path, files))
+	read, err := readStateArchive(path)
+	assert.NoError(t, err)
+	assert.Equal(t, files, read)
+}
+
+func TestReadStateArchiveRejectsEntriesOutsideOfIt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), defaultStateArchive)
+	out, err := os.Create(path)
+	assert.NoError(t, err)
+	gz := gzip.NewWriter(out)
+	archive := tar.NewWriter(gz)
+	assert.NoError(t, archive.WriteHeader(&tar.Header{Name: "../evil", Mode: 0644, Size: 1, Typeflag: tar.TypeReg}))
+	_, err = archive.Write([]byte("x"))
+	assert.NoError(t, err)
+	assert.NoError(t, archive.Close())
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, out.Close())
+
+	_, err = readStateArchive(path)
+	assert.ErrorContains(t, err, "outside of it")
+
+	assert.NoError(t, writeStateArchive(path, map[string][]byte{"pnpm-lock.yaml": nil}))
+	_, err = readStateArchive(path)
+	assert.ErrorContains(t, err, "not a state archive")
+}
+
+func TestReproduceCommand(t *testing.T) {
+	manifest := &StateManifest{LockFile: "pnpm-lock.yaml", RegistryURL: "https://acme.jfrog.io/artifactory/api/npm/npm-curated"}
+	files := map[string][]byte{stateCacheFile: nil, stateIgnoreFile: nil}
+	assert.Equal(t, "ca-extension audit pnpm-lock.yaml --registry-url=https://acme.jfrog.io/artifactory/api/npm/npm-curated --cache --cache-file=outcomes.json --cache-ttl=0 --ignore-file=ignore.yaml",
+		reproduceCommand(manifest, files))
+	assert.Equal(t, "ca-extension audit pnpm-lock.yaml", reproduceCommand(&StateManifest{LockFile: "pnpm-lock.yaml"}, nil))
+}
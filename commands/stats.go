@@ -0,0 +1,245 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const topFlag = "top"
+
+// Number of heaviest direct dependencies and scopes listed unless configured otherwise
+const defaultStatsTop = 10
+
+// TreeStatistics describes the composition of the dependency tree of a lock file
+type TreeStatistics struct {
+	LockFile string `json:"lockFile"`
+	Projects int    `json:"projects"`
+	// Packages counts the distinct packages of the tree, and Dependencies the dependency declarations of the
+	// projects and packages resolving to them, which a tree without deduplication would install
+	Packages     int `json:"packages"`
+	Dependencies int `json:"dependencies"`
+	Direct       int `json:"direct"`
+	Transitive   int `json:"transitive"`
+	// Depths counts the packages by the length of the shortest chain from a project to them, 1 for the direct
+	// dependencies. Absent for lock files that don't record the direct dependencies.
+	Depths   []DepthCount `json:"depths,omitempty"`
+	MaxDepth int          `json:"maxDepth,omitempty"`
+	// Heaviest are the direct dependencies pulling in the most packages
+	Heaviest []HeavyDependency `json:"heaviest,omitempty"`
+	// Scopes counts the packages of each npm scope, and Unscoped those outside of any
+	Scopes   []ScopeCount `json:"scopes,omitempty"`
+	Unscoped int          `json:"unscoped"`
+	// DevProd splits the packages between those production installs need and those only the devDependencies pull
+	// in. Absent for lock files that don't record devDependencies.
+	DevProd *DevProdSplit `json:"devProd,omitempty"`
+}
+
+// DepthCount is the number of packages at a depth of the tree
+type DepthCount struct {
+	Depth    int `json:"depth"`
+	Packages int `json:"packages"`
+}
+
+// HeavyDependency is a direct dependency with the number of packages it pulls in, directly or transitively
+type HeavyDependency struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Transitive int    `json:"transitive"`
+}
+
+// ScopeCount is the number of packages of an npm scope, e.g. @babel
+type ScopeCount struct {
+	Scope    string `json:"scope"`
+	Packages int    `json:"packages"`
+}
+
+// DevProdSplit is the number of packages production installs need, and of those only development needs
+type DevProdSplit struct {
+	Prod int `json:"prod"`
+	Dev  int `json:"dev"`
+}
+
+func GetStatsCommand() components.Command {
+	return components.Command{
+		Name:        "stats",
+		Description: "Prints the composition of the dependency tree of a lock file: package counts, depth distribution, heaviest direct dependencies, npm scopes and the dev/prod split.",
+		Arguments: []components.Argument{
+			{
+				Name:        "lock-file",
+				Description: "The path to the lock file, of any format 'audit' reads.",
+			},
+		},
+		Flags: []components.Flag{
+			components.NewStringFlag(
+				formatFlag,
+				"Output format: text or json. Defaults to text",
+				components.WithHelpValue("format"),
+			),
+			components.NewStringFlag(
+				topFlag,
+				fmt.Sprintf("Number of heaviest direct dependencies and of npm scopes to list. Defaults to %d", defaultStatsTop),
+				components.WithHelpValue("n"),
+			),
+			getUTCFlag(),
+		},
+		Action: func(c *components.Context) error {
+			return statsCmd(c)
+		},
+	}
+}
+
+func statsCmd(c *components.Context) error {
+	if len(c.Arguments) != 1 {
+		return wrongArguments("stats", "<lock-file>", len(c.Arguments))
+	}
+	top := defaultStatsTop
+	if value := c.GetStringFlagValue(topFlag); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("--%s must be a number of at least 1, got '%s'", topFlag, value)
+		}
+		top = n
+	}
+	lockFile := c.Arguments[0]
+	tree, err := audit.ParseLockFile(lockFile)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %v", filepath.Base(lockFile), err)
+	}
+	output, err := renderTreeStatistics(newTreeStatistics(lockFile, tree, top), c.GetStringFlagValue(formatFlag), getDisplayFormat(c))
+	if err != nil {
+		return err
+	}
+	fmt.Print(output)
+	return nil
+}
+
+// newTreeStatistics computes the statistics of a tree, listing the top heaviest direct dependencies and scopes
+func newTreeStatistics(lockFile string, tree *audit.DependencyTree, top int) *TreeStatistics {
+	stats := &TreeStatistics{
+		LockFile: lockFile,
+		Projects: len(tree.Importers),
+		Packages: len(tree.Packages),
+	}
+	for _, dependencies := range tree.Importers {
+		for _, version := range dependencies {
+			if !strings.HasPrefix(version, "link:") {
+				stats.Dependencies++
+			}
+		}
+	}
+	scopes := make(map[string]int)
+	for name, info := range tree.Packages {
+		stats.Dependencies += len(info.Dependencies)
+		if scope, _, found := strings.Cut(name, "/"); found && strings.HasPrefix(scope, "@") {
+			scopes[scope]++
+		} else {
+			stats.Unscoped++
+		}
+	}
+	for scope, count := range scopes {
+		stats.Scopes = append(stats.Scopes, ScopeCount{Scope: scope, Packages: count})
+	}
+	sort.Slice(stats.Scopes, func(i, j int) bool {
+		if stats.Scopes[i].Packages != stats.Scopes[j].Packages {
+			return stats.Scopes[i].Packages > stats.Scopes[j].Packages
+		}
+		return stats.Scopes[i].Scope < stats.Scopes[j].Scope
+	})
+	stats.Scopes = stats.Scopes[:min(len(stats.Scopes), top)]
+
+	relationships := tree.Relationships()
+	for _, name := range sortedKeys(relationships) {
+		if relationships[name] != audit.RelationshipDirect {
+			stats.Transitive++
+			continue
+		}
+		stats.Direct++
+		stats.Heaviest = append(stats.Heaviest, HeavyDependency{
+			Name:       name,
+			Version:    tree.Packages[name].Version,
+			Transitive: len(tree.Closure(name)) - 1,
+		})
+	}
+	sort.SliceStable(stats.Heaviest, func(i, j int) bool {
+		return stats.Heaviest[i].Transitive > stats.Heaviest[j].Transitive
+	})
+	stats.Heaviest = stats.Heaviest[:min(len(stats.Heaviest), top)]
+
+	depths := make(map[int]int)
+	for _, path := range tree.DependencyPaths() {
+		depth := len(path) - 1
+		depths[depth]++
+		stats.MaxDepth = max(stats.MaxDepth, depth)
+	}
+	for depth := 1; depth <= stats.MaxDepth; depth++ {
+		stats.Depths = append(stats.Depths, DepthCount{Depth: depth, Packages: depths[depth]})
+	}
+
+	if devOnly := tree.DevOnly(); devOnly != nil {
+		stats.DevProd = &DevProdSplit{Prod: len(tree.Packages) - len(devOnly), Dev: len(devOnly)}
+	}
+	return stats
+}
+
+func renderTreeStatistics(stats *TreeStatistics, format string, display *displayFormat) (string, error) {
+	switch format {
+	case formatJSON:
+		jsonData, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error marshaling JSON: %v", err)
+		}
+		return string(jsonData) + "\n", nil
+	case formatText, "":
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("Lock file: %s\n", stats.LockFile))
+		if stats.Projects > 0 {
+			sb.WriteString(fmt.Sprintf("Projects: %s\n", display.count(stats.Projects)))
+		}
+		sb.WriteString(fmt.Sprintf("Packages: %s unique, %s dependency declarations\n", display.count(stats.Packages), display.count(stats.Dependencies)))
+		if stats.Direct > 0 {
+			sb.WriteString(fmt.Sprintf("Direct: %s, transitive: %s\n", display.count(stats.Direct), display.count(stats.Transitive)))
+		}
+		if stats.DevProd != nil {
+			sb.WriteString(fmt.Sprintf("Production: %s (%s), dev only: %s (%s)\n",
+				display.count(stats.DevProd.Prod), percentOf(stats.DevProd.Prod, stats.Packages),
+				display.count(stats.DevProd.Dev), percentOf(stats.DevProd.Dev, stats.Packages)))
+		}
+		if len(stats.Depths) > 0 {
+			sb.WriteString(fmt.Sprintf("\nDepth distribution (max %d):\n", stats.MaxDepth))
+			for _, depth := range stats.Depths {
+				sb.WriteString(fmt.Sprintf("  %3d  %s\n", depth.Depth, display.count(depth.Packages)))
+			}
+		}
+		if len(stats.Heaviest) > 0 {
+			sb.WriteString("\nHeaviest direct dependencies:\n")
+			for _, dep := range stats.Heaviest {
+				sb.WriteString(fmt.Sprintf("  %s@%s  %s transitive\n", dep.Name, dep.Version, display.count(dep.Transitive)))
+			}
+		}
+		if len(stats.Scopes) > 0 {
+			sb.WriteString("\nScopes:\n")
+			for _, scope := range stats.Scopes {
+				sb.WriteString(fmt.Sprintf("  %s  %s\n", scope.Scope, display.count(scope.Packages)))
+			}
+			sb.WriteString(fmt.Sprintf("  (unscoped)  %s\n", display.count(stats.Unscoped)))
+		}
+		return sb.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported format '%s'. Expected %s or %s", format, formatText, formatJSON)
+	}
+}
+
+// percentOf formats the share of n in total as a rounded percentage
+func percentOf(n, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%d%%", (n*100+total/2)/total)
+}
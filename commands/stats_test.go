@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTreeStatistics(t *testing.T) {
+	lockFile := filepath.Join("..", "audit", "testdata", "pnpm-workspace", "pnpm-lock.yaml")
+	tree, err := audit.ParseLockFile(lockFile)
+	assert.NoError(t, err)
+	stats := newTreeStatistics(lockFile, tree, 2)
+	assert.Equal(t, 4, stats.Projects)
+	assert.Equal(t, 6, stats.Packages)
+	assert.Equal(t, 9, stats.Dependencies)
+	assert.Equal(t, 5, stats.Direct)
+	assert.Equal(t, 1, stats.Transitive)
+	assert.Equal(t, []DepthCount{{Depth: 1, Packages: 5}, {Depth: 2, Packages: 1}}, stats.Depths)
+	assert.Equal(t, []HeavyDependency{{Name: "react-dom", Version: "18.2.0", Transitive: 3}, {Name: "react", Version: "18.2.0", Transitive: 2}}, stats.Heaviest)
+	assert.Equal(t, &DevProdSplit{Prod: 5, Dev: 1}, stats.DevProd)
+
+	output, err := renderTreeStatistics(stats, formatText, newDisplayFormat(true))
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Production: 5 (83%), dev only: 1 (17%)")
+	assert.Contains(t, output, "  react-dom@18.2.0  3 transitive")
+	_, err = renderTreeStatistics(stats, "sarif", newDisplayFormat(true))
+	assert.Error(t, err)
+}
+
+func TestTreeStatisticsScopes(t *testing.T) {
+	tree := &audit.DependencyTree{Packages: map[string]audit.PackageInfo{
+		"@babel/core":   {Version: "7.24.0"},
+		"@babel/parser": {Version: "7.24.0"},
+		"@types/node":   {Version: "20.11.0"},
+		"lodash":        {Version: "4.17.21"},
+	}}
+	stats := newTreeStatistics("yarn.lock", tree, 10)
+	assert.Equal(t, []ScopeCount{{Scope: "@babel", Packages: 2}, {Scope: "@types", Packages: 1}}, stats.Scopes)
+	assert.Equal(t, 1, stats.Unscoped)
+	// Without the direct dependencies or devDependencies, neither depths nor the dev/prod split are known
+	assert.Empty(t, stats.Depths)
+	assert.Nil(t, stats.DevProd)
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	streamFlag       = "stream"
+	streamBufferFlag = "stream-buffer"
+
+	defaultStreamBuffer = 100
+)
+
+// resultSink receives the streamed results one at a time
+type resultSink interface {
+	write(entry ResultEntry) error
+	close() error
+}
+
+// ndjsonSink writes results as JSON lines to a file or named pipe, flushing after every line so a reader sees
+// them as they complete
+type ndjsonSink struct {
+	file   io.WriteCloser
+	writer *bufio.Writer
+}
+
+// webhookSink posts every result to a URL
+type webhookSink struct {
+	url string
+}
+
+// resultStream hands results to a sink from a separate goroutine. Up to highWaterMark results are buffered;
+// beyond that send blocks, so a slow sink slows the worker pool down instead of growing the buffer or losing
+// results.
+type resultStream struct {
+	buffer chan ResultEntry
+	done   chan error
+	// filter leaves out the results not matching --filter-results
+	filter *resultFilter
+}
+
+func getStreamFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			streamFlag,
+			"Stream each result as it completes, as JSON lines to a file or named pipe, or posted to an http(s) URL",
+			components.WithHelpValue("path|url"),
+		),
+		components.NewStringFlag(
+			streamBufferFlag,
+			"Number of results buffered for a slow --"+streamFlag+" sink before the audit waits for it",
+			components.WithStrDefaultValue(strconv.Itoa(defaultStreamBuffer)),
+		),
+	}
+}
+
+// getResultStream opens the --stream sink, if any
+func getResultStream(c *components.Context) (*resultStream, error) {
+	target := c.GetStringFlagValue(streamFlag)
+	if target == "" {
+		return nil, nil
+	}
+	highWaterMark := defaultStreamBuffer
+	if value := c.GetStringFlagValue(streamBufferFlag); value != "" {
+		var err error
+		if highWaterMark, err = strconv.Atoi(value); err != nil || highWaterMark < 1 {
+			return nil, fmt.Errorf("invalid --%s value '%s'. Expected a positive number", streamBufferFlag, value)
+		}
+	}
+	sink, err := newResultSink(target)
+	if err != nil {
+		return nil, err
+	}
+	return newResultStream(sink, highWaterMark), nil
+}
+
+func newResultSink(target string) (resultSink, error) {
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		return &webhookSink{url: target}, nil
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening stream %s: %v", target, err)
+	}
+	return &ndjsonSink{file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+func newResultStream(sink resultSink, highWaterMark int) *resultStream {
+	s := &resultStream{
+		buffer: make(chan ResultEntry, highWaterMark),
+		done:   make(chan error, 1),
+	}
+	go func() {
+		var err error
+		for entry := range s.buffer {
+			// After a failure the remaining results are drained so the audit isn't blocked, and the error
+			// is reported when the stream is closed
+			if err == nil {
+				err = sink.write(entry)
+			}
+		}
+		if closeErr := sink.close(); err == nil {
+			err = closeErr
+		}
+		s.done <- err
+	}()
+	return s
+}
+
+// send queues a result, blocking while the buffer is at its high-water mark. A nil stream discards it, as does a
+// stream whose filter doesn't keep it.
+func (s *resultStream) send(result audit.AuditResult) {
+	if s == nil || !s.filter.keep(result) {
+		return
+	}
+	s.buffer <- newResultEntry(result)
+}
+
+// close waits for the buffered results to reach the sink and returns the first error of the sink
+func (s *resultStream) close() error {
+	if s == nil {
+		return nil
+	}
+	close(s.buffer)
+	if err := <-s.done; err != nil {
+		return fmt.Errorf("error streaming results: %v", err)
+	}
+	return nil
+}
+
+func (s *ndjsonSink) write(entry ResultEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := s.writer.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return s.writer.Flush()
+}
+
+func (s *ndjsonSink) close() error {
+	if err := s.writer.Flush(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+func (s *webhookSink) write(entry ResultEntry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return postNotification(s.url, body, nil)
+}
+
+func (s *webhookSink) close() error {
+	return nil
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingSink holds every write until it is released
+type blockingSink struct {
+	release chan struct{}
+	written []ResultEntry
+	err     error
+}
+
+func (s *blockingSink) write(entry ResultEntry) error {
+	<-s.release
+	s.written = append(s.written, entry)
+	return s.err
+}
+
+func (s *blockingSink) close() error {
+	return nil
+}
+
+func TestResultStreamAppliesBackpressure(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	stream := newResultStream(sink, 1)
+
+	// The first result is held by the sink and the second fills the buffer, so the third has to wait
+	stream.send(audit.AuditResult{Name: "a"})
+	stream.send(audit.AuditResult{Name: "b"})
+	sent := make(chan struct{})
+	go func() {
+		stream.send(audit.AuditResult{Name: "c"})
+		close(sent)
+	}()
+	select {
+	case <-sent:
+		t.Fatal("send didn't wait for the slow sink")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(sink.release)
+	<-sent
+	assert.NoError(t, stream.close())
+	assert.Equal(t, []ResultEntry{{Name: "a"}, {Name: "b"}, {Name: "c"}}, sink.written)
+}
+
+func TestResultStreamReportsSinkErrors(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{}), err: errors.New("broken pipe")}
+	close(sink.release)
+	stream := newResultStream(sink, 1)
+	for i := 0; i < 5; i++ {
+		stream.send(audit.AuditResult{Name: "a"})
+	}
+	assert.EqualError(t, stream.close(), "error streaming results: broken pipe")
+	assert.Len(t, sink.written, 1)
+}
+
+func TestNDJSONSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.ndjson")
+	sink, err := newResultSink(path)
+	assert.NoError(t, err)
+	stream := newResultStream(sink, defaultStreamBuffer)
+	stream.send(audit.AuditResult{Name: "lodash", Version: "4.17.21", Status: "✅ Approved", StatusCode: 200})
+	stream.send(audit.AuditResult{Name: "abbrev", Version: "1.1.1", Status: "❌ Blocked", StatusCode: 403})
+	assert.NoError(t, stream.close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"lodash","version":"4.17.21","status":"✅ Approved","statusCode":200}
+{"name":"abbrev","version":"1.1.1","status":"❌ Blocked","statusCode":403}
+`, string(data))
+}
+
+func TestNilResultStream(t *testing.T) {
+	var stream *resultStream
+	stream.send(audit.AuditResult{Name: "a"})
+	assert.NoError(t, stream.close())
+}
@@ -0,0 +1,265 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-cli-plugin-template/internal/semver"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	suggestFlag      = "suggest"
+	releaseNotesFlag = "release-notes"
+
+	// Upper bound of the candidate versions checked for each blocked package
+	maxSuggestionCandidates = 5
+	// Upper bound of the characters kept from the body of a release
+	maxReleaseSummary = 200
+
+	defaultGitHubAPIURL = "https://api.github.com"
+)
+
+// Matches the GitHub owner/repository of a repository URL or shorthand
+var githubRepositoryPattern = regexp.MustCompile(`^(?:github:)?([\w.-]+)/([\w.-]+?)(?:\.git)?$|github\.com[/:]([\w.-]+)/([\w.-]+?)(?:\.git)?(?:[/#].*)?$`)
+
+// ReleaseNote represents the summary of a release between the audited and the suggested version
+type ReleaseNote struct {
+	Version string `json:"version"`
+	Title   string `json:"title,omitempty"`
+	Summary string `json:"summary,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// githubRelease holds the parts of a GitHub release used in release notes
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Name    string `json:"name"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Suggestion represents an available version to upgrade a blocked package to
+type Suggestion struct {
+	Name             string        `json:"name"`
+	Version          string        `json:"version"`
+	SuggestedVersion string        `json:"suggestedVersion,omitempty"`
+	ReleaseNotes     []ReleaseNote `json:"releaseNotes,omitempty"`
+}
+
+// suggestUpgrades looks for the lowest newer version of each blocked package the curated registry serves,
+// preferring versions of the same major, on numWorkers goroutines. With releaseNotes, the GitHub releases in
+// between are summarized. Suggestions are in the order of the results.
+func suggestUpgrades(results []audit.AuditResult, registry *registryConfiguration, releaseNotes bool, numWorkers int) []Suggestion {
+	var blocked []audit.AuditResult
+	for _, result := range results {
+		if isBlocked(result) {
+			blocked = append(blocked, result)
+		}
+	}
+	suggestions := make([]Suggestion, len(blocked))
+	indexes := make([]int, len(blocked))
+	for i := range blocked {
+		indexes[i] = i
+	}
+	audit.RunPool(context.Background(), indexes, numWorkers, func(i int) error {
+		suggestions[i] = suggestUpgrade(blocked[i], registry, releaseNotes)
+		return nil
+	}, func(i int, err error) {
+		suggestions[i] = Suggestion{Name: blocked[i].Name, Version: blocked[i].Version}
+		log.Warn(fmt.Sprintf("Could not suggest an upgrade of %s: %v", blocked[i].Name, err))
+	})
+	return suggestions
+}
+
+// suggestUpgrade looks for the version to upgrade a blocked package to
+func suggestUpgrade(result audit.AuditResult, registry *registryConfiguration, releaseNotes bool) Suggestion {
+	suggestion := Suggestion{Name: result.Name, Version: result.Version}
+	doc, err := fetchPackument(result.Name, registry)
+	if err != nil {
+		log.Warn(fmt.Sprintf("Could not read versions of %s: %v", result.Name, err))
+		return suggestion
+	}
+
+	for _, candidate := range upgradeCandidates(doc, result.Version) {
+		if registry.auditRegistry().Check(audit.Dependency{Name: result.Name, Version: candidate, Type: result.Type}).StatusCode == http.StatusOK {
+			suggestion.SuggestedVersion = candidate
+			break
+		}
+	}
+	if releaseNotes && suggestion.SuggestedVersion != "" {
+		notes, err := fetchReleaseNotes(doc.Repository, result.Version, suggestion.SuggestedVersion)
+		if err != nil {
+			log.Warn(fmt.Sprintf("Could not read release notes of %s: %v", result.Name, err))
+		}
+		suggestion.ReleaseNotes = notes
+	}
+	return suggestion
+}
+
+// upgradeCandidates returns the stable versions newer than version, those of the same major first, each group ascending
+func upgradeCandidates(doc *packument, version string) []string {
+	current, err := semver.Parse(version)
+	if err != nil {
+		return nil
+	}
+	var sameMajor, otherMajors []semver.Version
+	names := make(map[semver.Version]string)
+	for name, info := range doc.Versions {
+		candidate, err := semver.Parse(name)
+		if err != nil || candidate.Prerelease != "" || info.Deprecated != "" || semver.Compare(candidate, current) <= 0 {
+			continue
+		}
+		names[candidate] = name
+		if candidate.Parts[0] == current.Parts[0] {
+			sameMajor = append(sameMajor, candidate)
+		} else {
+			otherMajors = append(otherMajors, candidate)
+		}
+	}
+
+	var candidates []string
+	for _, group := range [][]semver.Version{sameMajor, otherMajors} {
+		sort.Slice(group, func(i, j int) bool {
+			return semver.Compare(group[i], group[j]) < 0
+		})
+		for _, candidate := range group {
+			if len(candidates) == maxSuggestionCandidates {
+				return candidates
+			}
+			candidates = append(candidates, names[candidate])
+		}
+	}
+	return candidates
+}
+
+// fetchReleaseNotes summarizes the GitHub releases after fromVersion up to toVersion
+func fetchReleaseNotes(repository json.RawMessage, fromVersion, toVersion string) ([]ReleaseNote, error) {
+	slug := githubRepositorySlug(repository)
+	if slug == "" {
+		return nil, nil
+	}
+	apiURL := os.Getenv("GITHUB_API_URL")
+	if apiURL == "" {
+		apiURL = defaultGitHubAPIURL
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/repos/%s/releases?per_page=100", apiURL, slug), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response: %d", resp.StatusCode)
+	}
+
+	var releases []githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("error parsing releases: %v", err)
+	}
+	return releaseNotesBetween(releases, fromVersion, toVersion), nil
+}
+
+func releaseNotesBetween(releases []githubRelease, fromVersion, toVersion string) []ReleaseNote {
+	from, fromErr := semver.Parse(fromVersion)
+	to, toErr := semver.Parse(toVersion)
+	if fromErr != nil || toErr != nil {
+		return nil
+	}
+
+	var notes []ReleaseNote
+	for _, release := range releases {
+		// Tags are v1.2.3, 1.2.3 or, in monorepos, name@1.2.3
+		tag := release.TagName[strings.LastIndex(release.TagName, "@")+1:]
+		version, err := semver.Parse(tag)
+		if err != nil || version.Parts[2] < 0 || semver.Compare(version, from) <= 0 || semver.Compare(version, to) > 0 {
+			continue
+		}
+		notes = append(notes, ReleaseNote{
+			Version: strings.TrimPrefix(tag, "v"),
+			Title:   release.Name,
+			Summary: summarizeRelease(release.Body),
+			URL:     release.HTMLURL,
+		})
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		return isNewerVersion(notes[j].Version, notes[i].Version)
+	})
+	return notes
+}
+
+// summarizeRelease keeps the first line of a release body with text, skipping markdown headings such as "## Fixes"
+func summarizeRelease(body string) string {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimLeft(line, "*- "))
+		if runes := []rune(line); len(runes) > maxReleaseSummary {
+			line = string(runes[:maxReleaseSummary]) + "…"
+		}
+		return line
+	}
+	return ""
+}
+
+// githubRepositorySlug returns owner/repository of a package hosted on GitHub, or an empty string
+func githubRepositorySlug(repository json.RawMessage) string {
+	var repositoryURL string
+	if err := json.Unmarshal(repository, &repositoryURL); err != nil {
+		var repositoryObject struct {
+			URL string `json:"url"`
+		}
+		if err := json.Unmarshal(repository, &repositoryObject); err != nil {
+			return ""
+		}
+		repositoryURL = repositoryObject.URL
+	}
+	match := githubRepositoryPattern.FindStringSubmatch(repositoryURL)
+	switch {
+	case match == nil:
+		return ""
+	case match[1] != "":
+		return match[1] + "/" + match[2]
+	default:
+		return match[3] + "/" + match[4]
+	}
+}
+
+// printSuggestions lists the upgrade suggestions for the blocked packages
+func printSuggestions(suggestions []Suggestion, display *displayFormat) {
+	if len(suggestions) == 0 {
+		return
+	}
+	fmt.Printf("\n\nUpgrade suggestions:")
+	for _, suggestion := range suggestions {
+		if suggestion.SuggestedVersion == "" {
+			fmt.Printf("\n%s@%s %s", suggestion.Name, suggestion.Version, display.status("⚠️ No available newer version found"))
+			continue
+		}
+		fmt.Printf("\n%s@%s %s", suggestion.Name, suggestion.Version, display.status("➡️ "+suggestion.SuggestedVersion))
+		for _, note := range suggestion.ReleaseNotes {
+			fmt.Printf("\n    %s: %s", note.Version, note.Summary)
+		}
+	}
+}
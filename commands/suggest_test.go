@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpgradeCandidates(t *testing.T) {
+	doc := &packument{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"versions": {
+		"1.0.0": {}, "1.0.1": {"deprecated": "bad"}, "1.2.0": {}, "1.1.0": {}, "2.0.0": {}, "2.1.0-rc.1": {}
+	}}`), doc))
+	assert.Equal(t, []string{"1.1.0", "1.2.0", "2.0.0"}, upgradeCandidates(doc, "1.0.0"))
+}
+
+func TestSuggestUpgrades(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/minimist"):
+			w.Write([]byte(`{"versions": {"1.2.5": {}, "1.2.6": {}, "1.2.7": {}}}`))
+		case strings.HasSuffix(r.URL.Path, "minimist-1.2.6.tgz"):
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer registry.Close()
+
+	results := []audit.AuditResult{
+		{Name: "abbrev", Version: "1.1.1", StatusCode: http.StatusOK},
+		{Name: "minimist", Version: "1.2.5", Type: "package", StatusCode: http.StatusForbidden},
+	}
+	suggestions := suggestUpgrades(results, &registryConfiguration{registryURL: registry.URL}, false, 2)
+	assert.Equal(t, []Suggestion{{Name: "minimist", Version: "1.2.5", SuggestedVersion: "1.2.7"}}, suggestions)
+}
+
+func TestReleaseNotesBetween(t *testing.T) {
+	releases := []githubRelease{
+		{TagName: "v1.2.8", Body: "Too new"},
+		{TagName: "v1.2.7", Name: "1.2.7", Body: "## Fixes\n\n- Prototype pollution fix", HTMLURL: "https://github.com/x/y/releases/v1.2.7"},
+		{TagName: "minimist@1.2.6", Body: "First fix"},
+		{TagName: "v1.2.5", Body: "Current"},
+	}
+	assert.Equal(t, []ReleaseNote{
+		{Version: "1.2.6", Summary: "First fix"},
+		{Version: "1.2.7", Title: "1.2.7", Summary: "Prototype pollution fix", URL: "https://github.com/x/y/releases/v1.2.7"},
+	}, releaseNotesBetween(releases, "1.2.5", "1.2.7"))
+}
+
+func TestGithubRepositorySlug(t *testing.T) {
+	assert.Equal(t, "lodash/lodash", githubRepositorySlug(json.RawMessage(`{"type": "git", "url": "git+https://github.com/lodash/lodash.git"}`)))
+	assert.Equal(t, "substack/minimist", githubRepositorySlug(json.RawMessage(`"github:substack/minimist"`)))
+	assert.Equal(t, "babel/babel", githubRepositorySlug(json.RawMessage(`{"url": "https://github.com/babel/babel.git", "directory": "packages/core"}`)))
+	assert.Equal(t, "", githubRepositorySlug(json.RawMessage(`"https://gitlab.com/x/y"`)))
+}
@@ -0,0 +1,217 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+// Version of the exit summary line
+const runSummarySchemaVersion = "1"
+
+// RunSummary is the outcome of a run, written as a single JSON line to stderr when the run ends, whatever it
+// printed before, so wrapper scripts don't have to read the full report
+type RunSummary struct {
+	SchemaVersion string `json:"schemaVersion"`
+	Command       string `json:"command"`
+	LockFile      string `json:"lockFile,omitempty"`
+	ExitCode      int    `json:"exitCode"`
+	Error         string `json:"error,omitempty"`
+	DurationMs    int64  `json:"durationMs"`
+	Packages      int    `json:"packages"`
+	Approved      int    `json:"approved"`
+	Blocked       int    `json:"blocked"`
+	NotFound      int    `json:"notFound"`
+	Errors        int    `json:"errors"`
+	// TimedOut counts the errors of checks that timed out
+	TimedOut int `json:"timedOut,omitempty"`
+	// Reports maps the outputs the run wrote, results, tree, index or oci, to their paths or references
+	Reports map[string]string `json:"reports,omitempty"`
+	// Stages are the timings of the stages the run went through, with their --budget
+	Stages []StageTiming `json:"stages,omitempty"`
+}
+
+// runSummary collects the summary of a run as it progresses. A nil summary collects and writes nothing.
+type runSummary struct {
+	out     io.Writer
+	start   time.Time
+	summary RunSummary
+}
+
+func newRunSummary(command string, out io.Writer) *runSummary {
+	return &runSummary{
+		out:     out,
+		start:   time.Now(),
+		summary: RunSummary{SchemaVersion: runSummarySchemaVersion, Command: command},
+	}
+}
+
+func (s *runSummary) recordLockFile(lockFile string) {
+	if s == nil {
+		return
+	}
+	s.summary.LockFile = lockFile
+}
+
+// recordResults records the outcome counts of the results
+func (s *runSummary) recordResults(results []audit.AuditResult) {
+	if s == nil {
+		return
+	}
+	counts := newAuditSummary(results, 0)
+	s.summary.Packages, s.summary.Approved, s.summary.Blocked = counts.Packages, counts.Approved, counts.Blocked
+	s.summary.NotFound, s.summary.Errors, s.summary.TimedOut = counts.NotFound, counts.Errors, counts.TimedOut
+}
+
+// recordReport records an output the run wrote
+func (s *runSummary) recordReport(kind, path string) {
+	if s == nil {
+		return
+	}
+	if s.summary.Reports == nil {
+		s.summary.Reports = make(map[string]string)
+	}
+	s.summary.Reports[kind] = path
+}
+
+// recordStages records the timings of the stages of the run
+func (s *runSummary) recordStages(stages *runStages) {
+	if s == nil {
+		return
+	}
+	s.summary.Stages = stages.timings
+}
+
+// write ends the summary with the error the run returns, if any, and the exit code it leads to
+func (s *runSummary) write(err error) {
+	if s == nil {
+		return
+	}
+	s.summary.DurationMs = time.Since(s.start).Milliseconds()
+	s.summary.ExitCode = exitCodeOf(err)
+	if err != nil {
+		s.summary.Error = err.Error()
+	}
+	line, marshalErr := json.Marshal(s.summary)
+	if marshalErr != nil {
+		return
+	}
+	fmt.Fprintln(s.out, string(line))
+}
+
+// exitCodeOf returns the code the CLI exits with when a command returns err
+func exitCodeOf(err error) int {
+	var cliError coreutils.CliError
+	if errors.As(err, &cliError) {
+		return cliError.ExitCode.Code
+	}
+	return coreutils.GetExitCode(err, 0, 0, false).Code
+}
+
+// AuditSummary is the outcome counts of the checks of an audit, printed as a table when it ends and stored with
+// its results for dashboards. It counts every result, those --filter-results leaves out of the report included.
+type AuditSummary struct {
+	Packages int `json:"packages"`
+	Approved int `json:"approved"`
+	Blocked  int `json:"blocked"`
+	// BlockedBy splits the blocked packages by the curation policy blocking them, or the status code of the
+	// registry when the policy isn't known
+	BlockedBy []BlockedCount `json:"blockedBy,omitempty"`
+	NotFound  int            `json:"notFound"`
+	// Pending counts the packages the curation audit API hasn't evaluated yet, which aren't blocked
+	Pending int `json:"pending,omitempty"`
+	Errors  int `json:"errors"`
+	// TimedOut counts the errors of checks that timed out
+	TimedOut   int   `json:"timedOut,omitempty"`
+	DurationMs int64 `json:"durationMs"`
+}
+
+// BlockedCount is the number of packages blocked for a reason
+type BlockedCount struct {
+	Reason     string `json:"reason"`
+	StatusCode int    `json:"statusCode"`
+	Packages   int    `json:"packages"`
+}
+
+// newAuditSummary counts the outcomes of the results of a run that took duration
+func newAuditSummary(results []audit.AuditResult, duration time.Duration) *AuditSummary {
+	summary := &AuditSummary{Packages: len(results), TimedOut: timedOutResults(results), DurationMs: duration.Milliseconds()}
+	blocked := make(map[string]*BlockedCount)
+	for _, result := range results {
+		switch {
+		case result.Error != nil:
+			summary.Errors++
+		case result.StatusCode == http.StatusOK:
+			summary.Approved++
+		case result.Pending:
+			summary.Pending++
+		case result.StatusCode == http.StatusForbidden:
+			summary.Blocked++
+			reason := blockReason(result)
+			if blocked[reason] == nil {
+				blocked[reason] = &BlockedCount{Reason: reason, StatusCode: result.StatusCode}
+			}
+			blocked[reason].Packages++
+		case result.StatusCode == http.StatusNotFound:
+			summary.NotFound++
+		default:
+			summary.Errors++
+		}
+	}
+	for _, reason := range sortedKeys(blocked) {
+		summary.BlockedBy = append(summary.BlockedBy, *blocked[reason])
+	}
+	sort.SliceStable(summary.BlockedBy, func(i, j int) bool {
+		return summary.BlockedBy[i].Packages > summary.BlockedBy[j].Packages
+	})
+	return summary
+}
+
+// blockReason returns the first curation policy blocking a package, the most telling one, or the status of the
+// registry response
+func blockReason(result audit.AuditResult) string {
+	if len(result.Policies) > 0 && result.Policies[0].Policy != "" {
+		return result.Policies[0].Policy
+	}
+	return fmt.Sprintf("%d %s", result.StatusCode, http.StatusText(result.StatusCode))
+}
+
+// table renders the summary as a table of counts
+func (summary *AuditSummary) table(display *displayFormat) string {
+	rows := [][2]string{
+		{"Packages", display.count(summary.Packages)},
+		{"Approved", display.count(summary.Approved)},
+		{"Blocked", display.count(summary.Blocked)},
+	}
+	for _, blocked := range summary.BlockedBy {
+		rows = append(rows, [2]string{"  " + blocked.Reason, display.count(blocked.Packages)})
+	}
+	rows = append(rows, [2]string{"Not found", display.count(summary.NotFound)})
+	if summary.Pending > 0 {
+		rows = append(rows, [2]string{"Pending curation", display.count(summary.Pending)})
+	}
+	rows = append(rows, [2]string{"Errors", display.count(summary.Errors)})
+	if summary.TimedOut > 0 {
+		rows = append(rows, [2]string{"  Timed out", display.count(summary.TimedOut)})
+	}
+	rows = append(rows, [2]string{"Duration", display.duration(time.Duration(summary.DurationMs) * time.Millisecond)})
+
+	labelWidth, valueWidth := 0, 0
+	for _, row := range rows {
+		labelWidth, valueWidth = max(labelWidth, len([]rune(row[0]))), max(valueWidth, len([]rune(row[1])))
+	}
+	var sb strings.Builder
+	sb.WriteString("Summary:\n")
+	for _, row := range rows {
+		sb.WriteString(fmt.Sprintf("  %s%s  %s%s\n", row[0], strings.Repeat(" ", labelWidth-len([]rune(row[0]))), strings.Repeat(" ", valueWidth-len([]rune(row[1]))), row[1]))
+	}
+	return sb.String()
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSummary(t *testing.T) {
+	var out bytes.Buffer
+	summary := newRunSummary("audit", &out)
+	summary.recordLockFile("pnpm-lock.yaml")
+	summary.recordResults([]audit.AuditResult{
+		{Name: "lodash", StatusCode: http.StatusOK},
+		{Name: "yallist", StatusCode: http.StatusForbidden},
+		{Name: "left-pad", StatusCode: http.StatusNotFound},
+		{Name: "ms", Error: errors.New("connection reset")},
+	})
+	summary.recordReport("results", "results.json")
+	summary.write(errors.New("audit failed: 1 of 4 packages are blocked by curation (--fail-on=blocked)"))
+
+	line := out.String()
+	assert.Equal(t, 1, strings.Count(line, "\n"))
+	var written RunSummary
+	assert.NoError(t, json.Unmarshal([]byte(line), &written))
+	assert.Equal(t, RunSummary{
+		SchemaVersion: runSummarySchemaVersion,
+		Command:       "audit",
+		LockFile:      "pnpm-lock.yaml",
+		ExitCode:      1,
+		Error:         "audit failed: 1 of 4 packages are blocked by curation (--fail-on=blocked)",
+		DurationMs:    written.DurationMs,
+		Packages:      4,
+		Approved:      1,
+		Blocked:       1,
+		NotFound:      1,
+		Errors:        1,
+		Reports:       map[string]string{"results": "results.json"},
+	}, written)
+
+	out.Reset()
+	newRunSummary("audit", &out).write(nil)
+	assert.True(t, strings.HasPrefix(out.String(), `{"schemaVersion":"1","command":"audit","exitCode":0,"durationMs":`))
+	assert.NotContains(t, out.String(), `"error"`)
+
+	// A nil summary writes nothing
+	var none *runSummary
+	none.recordResults(nil)
+	none.write(nil)
+}
+
+func TestAuditSummary(t *testing.T) {
+	summary := newAuditSummary([]audit.AuditResult{
+		{Name: "lodash", StatusCode: http.StatusOK},
+		{Name: "yallist", StatusCode: http.StatusForbidden},
+		{Name: "event-stream", StatusCode: http.StatusForbidden, Policies: []audit.CurationPolicy{{Policy: "Block Malicious"}}},
+		{Name: "flatmap-stream", StatusCode: http.StatusForbidden, Policies: []audit.CurationPolicy{{Policy: "Block Malicious"}, {Policy: "Aged"}}},
+		{Name: "left-pad", StatusCode: http.StatusNotFound},
+		{Name: "yallist", StatusCode: http.StatusForbidden, Pending: true},
+		{Name: "ms", StatusCode: http.StatusBadGateway},
+		{Name: "debug", Error: context.DeadlineExceeded},
+	}, 1500*time.Millisecond)
+	assert.Equal(t, &AuditSummary{
+		Packages: 8,
+		Approved: 1,
+		Blocked:  3,
+		BlockedBy: []BlockedCount{
+			{Reason: "Block Malicious", StatusCode: http.StatusForbidden, Packages: 2},
+			{Reason: "403 Forbidden", StatusCode: http.StatusForbidden, Packages: 1},
+		},
+		NotFound:   1,
+		Pending:    1,
+		Errors:     2,
+		TimedOut:   1,
+		DurationMs: 1500,
+	}, summary)
+
+	assert.Equal(t, `Summary:
+  Packages              8
+  Approved              1
+  Blocked               3
+    Block Malicious     2
+    403 Forbidden       1
+  Not found             1
+  Pending curation      1
+  Errors                2
+    Timed out           1
+  Duration           1.5s
+`, summary.table(newDisplayFormat(true)))
+}
+
+func TestExitCodeOf(t *testing.T) {
+	assert.Equal(t, 0, exitCodeOf(nil))
+	assert.Equal(t, 1, exitCodeOf(errors.New("failed")))
+	assert.Equal(t, 3, exitCodeOf(coreutils.CliError{ExitCode: coreutils.ExitCodeVulnerableBuild, ErrorMsg: "vulnerable"}))
+}
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	honorResolutionFlag = "honor-resolution"
+	mirrorHostsFlag     = "mirror-hosts"
+
+	mirrorHostsEnv = "CA_EXTENSION_MIRROR_HOSTS"
+)
+
+// Hosts of the public registry tarball URLs, which package managers rewrite to the configured registry
+var defaultTarballHosts = []string{"registry.npmjs.org", "registry.yarnpkg.com"}
+
+// PinnedTarball represents a package whose lock file resolution pins its tarball to a host other than the registry
+type PinnedTarball struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	URL      string `json:"url"`
+	Host     string `json:"host"`
+	Approved bool   `json:"approved"`
+}
+
+func getTarballFlags() []components.Flag {
+	return []components.Flag{
+		components.NewBoolFlag(
+			honorResolutionFlag,
+			"Audit packages whose lock file resolution pins a tarball URL against the pinned host instead of the registry",
+			components.WithBoolDefaultValue(false),
+		),
+		components.NewStringFlag(
+			mirrorHostsFlag,
+			"Comma separated mirror hosts lock files may pin tarballs to. *.example.com matches subdomains",
+			components.WithHelpValue("hosts"),
+		),
+	}
+}
+
+// findPinnedTarballs lists the packages resolved to tarballs outside the registry and the public registries
+func findPinnedTarballs(tree *audit.DependencyTree, registry *registryConfiguration) []PinnedTarball {
+	var pinned []PinnedTarball
+	for _, name := range sortedKeys(tree.Packages) {
+		info := tree.Packages[name]
+		tarball, _ := info.Resolution["tarball"].(string)
+		if !strings.HasPrefix(tarball, "http://") && !strings.HasPrefix(tarball, "https://") {
+			continue
+		}
+		host := strings.ToLower(audit.HostOf(tarball))
+		if host == "" || registry.isRegistryHost(host) || audit.MatchesHost(host, defaultTarballHosts) {
+			continue
+		}
+		pinned = append(pinned, PinnedTarball{
+			Name:     name,
+			Version:  info.Version,
+			URL:      tarball,
+			Host:     host,
+			Approved: audit.MatchesHost(host, registry.mirrorHosts),
+		})
+	}
+	return pinned
+}
+
+// honorPinnedTarballs makes the audit request the pinned tarballs of the dependencies
+func honorPinnedTarballs(deps []audit.Dependency, pinned []PinnedTarball) []audit.Dependency {
+	tarballs := make(map[string]string, len(pinned))
+	for _, tarball := range pinned {
+		tarballs[cacheKey(tarball.Name, tarball.Version)] = tarball.URL
+	}
+	for i, dep := range deps {
+		deps[i].Tarball = tarballs[cacheKey(dep.Name, dep.Version)]
+	}
+	return deps
+}
+
+// printPinnedTarballs lists the pinned tarballs, flagging the mirrors outside the approved hosts
+func printPinnedTarballs(pinned []PinnedTarball, display *displayFormat) {
+	if len(pinned) == 0 {
+		return
+	}
+	fmt.Printf("\n\nTarballs pinned to other hosts:")
+	for _, tarball := range pinned {
+		status := "⚠️ Mirror outside the approved hosts"
+		if tarball.Approved {
+			status = "✅ Approved mirror"
+		}
+		fmt.Printf("\n%s@%s %s %s", tarball.Name, tarball.Version, tarball.Host, display.status(status))
+	}
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindPinnedTarballs(t *testing.T) {
+	tree := &audit.DependencyTree{Packages: map[string]audit.PackageInfo{
+		"abbrev":  {Version: "1.1.1", Resolution: map[string]interface{}{"tarball": "https://registry.npmjs.org/abbrev/-/abbrev-1.1.1.tgz"}},
+		"chalk":   {Version: "4.1.2", Resolution: map[string]interface{}{"tarball": "https://acme.jfrog.io/artifactory/api/npm/npm/chalk/-/chalk-4.1.2.tgz"}},
+		"lodash":  {Version: "4.17.21", Resolution: map[string]interface{}{"tarball": "https://mirror.acme.io/lodash/-/lodash-4.17.21.tgz"}},
+		"semver":  {Version: "7.5.4", Resolution: map[string]interface{}{"tarball": "https://npm.example.com/semver/-/semver-7.5.4.tgz"}},
+		"local":   {Version: "0.1.0", Resolution: map[string]interface{}{"tarball": "file:../local.tgz"}},
+		"yallist": {Version: "4.0.0", Resolution: map[string]interface{}{"integrity": "sha512-abc"}},
+	}}
+	registry := &registryConfiguration{registryURL: "https://acme.jfrog.io/artifactory/api/npm/npm", mirrorHosts: parseRedirectHosts("*.acme.io")}
+
+	assert.Equal(t, []PinnedTarball{
+		{Name: "lodash", Version: "4.17.21", URL: "https://mirror.acme.io/lodash/-/lodash-4.17.21.tgz", Host: "mirror.acme.io", Approved: true},
+		{Name: "semver", Version: "7.5.4", URL: "https://npm.example.com/semver/-/semver-7.5.4.tgz", Host: "npm.example.com"},
+	}, findPinnedTarballs(tree, registry))
+}
+
+func TestHonorPinnedTarballs(t *testing.T) {
+	var authorization []string
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		if r.URL.Path != "/lodash-4.17.21.tgz" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mirror.Close()
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer registry.Close()
+
+	deps := honorPinnedTarballs([]audit.Dependency{{Name: "lodash", Version: "4.17.21", Type: "package"}, {Name: "abbrev", Version: "1.1.1", Type: "package"}},
+		[]PinnedTarball{{Name: "lodash", Version: "4.17.21", URL: mirror.URL + "/lodash-4.17.21.tgz", Host: "127.0.0.1"}})
+	conf := &registryConfiguration{registryURL: registry.URL, accessToken: "secret"}
+
+	result := conf.auditRegistry().Check(deps[0])
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, "✅ Available in NPM Registry (pinned to 127.0.0.1)", result.Status)
+	assert.Equal(t, http.StatusForbidden, conf.auditRegistry().Check(deps[1]).StatusCode)
+
+	// The access token is only sent to approved mirrors
+	conf.mirrorHosts = []string{strings.ToLower(audit.HostOf(mirror.URL))}
+	conf.auditRegistry().Check(deps[0])
+	assert.Equal(t, []string{"", "Bearer secret"}, authorization)
+}
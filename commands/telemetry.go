@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	telemetryEndpointFlag = "telemetry-endpoint"
+
+	telemetryEndpointEnv = "CA_EXTENSION_TELEMETRY_ENDPOINT"
+	// telemetryEnv set to off disables telemetry, whatever the flags, environment and profile configure
+	telemetryEnv = "CA_EXTENSION_TELEMETRY"
+
+	telemetryOff = "off"
+
+	// Version of the usage event payload
+	telemetrySchemaVersion = "1"
+)
+
+// UsageEvent represents the anonymous usage of a run. It holds no package names, paths, URLs or hosts.
+type UsageEvent struct {
+	SchemaVersion  string         `json:"schemaVersion"`
+	ToolVersion    string         `json:"toolVersion"`
+	Command        string         `json:"command"`
+	OS             string         `json:"os"`
+	Arch           string         `json:"arch"`
+	PackageManager string         `json:"packageManager,omitempty"`
+	Packages       int            `json:"packages"`
+	Blocked        int            `json:"blocked"`
+	NotFound       int            `json:"notFound"`
+	DurationMs     int64          `json:"durationMs"`
+	FailedStage    string         `json:"failedStage,omitempty"`
+	ErrorClasses   map[string]int `json:"errorClasses,omitempty"`
+}
+
+// telemetryReporter collects the usage of a run and sends it to the endpoint. A nil reporter, the default,
+// collects and sends nothing.
+type telemetryReporter struct {
+	endpoint string
+	client   *http.Client
+	start    time.Time
+	event    UsageEvent
+}
+
+func getTelemetryFlag() components.Flag {
+	return components.NewStringFlag(
+		telemetryEndpointFlag,
+		"Opt in to sending anonymous usage metrics (package manager, package counts, duration, error classes) to this endpoint. "+telemetryEnv+"=off disables it",
+		components.WithHelpValue("url"),
+	)
+}
+
+func getTelemetryEnvVars() []components.EnvVar {
+	return []components.EnvVar{
+		{
+			Name:        telemetryEndpointEnv,
+			Description: "Endpoint of the opt-in anonymous usage metrics, used when --" + telemetryEndpointFlag + " is not set.",
+		},
+		{
+			Name:        telemetryEnv,
+			Description: "Set to off to disable telemetry, even when an endpoint is configured.",
+		},
+	}
+}
+
+// getTelemetryReporter returns the reporter of the run, or nil unless an endpoint is configured and telemetry isn't
+// switched off
+func getTelemetryReporter(c *components.Context, command string) (*telemetryReporter, error) {
+	if strings.EqualFold(os.Getenv(telemetryEnv), telemetryOff) {
+		return nil, nil
+	}
+	// Only the user opts in: the endpoint isn't read from the project config or profile, which others write
+	endpoint := c.GetStringFlagValue(telemetryEndpointFlag)
+	if endpoint == "" {
+		endpoint = os.Getenv(telemetryEndpointEnv)
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(endpoint, "https://") && !strings.HasPrefix(endpoint, "http://") {
+		return nil, fmt.Errorf("invalid --%s '%s'. Expected an http(s) URL", telemetryEndpointFlag, endpoint)
+	}
+	return newTelemetryReporter(endpoint, command), nil
+}
+
+func newTelemetryReporter(endpoint, command string) *telemetryReporter {
+	return &telemetryReporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		start:    time.Now(),
+		event: UsageEvent{
+			SchemaVersion: telemetrySchemaVersion,
+			ToolVersion:   appVersion,
+			Command:       command,
+			OS:            runtime.GOOS,
+			Arch:          runtime.GOARCH,
+		},
+	}
+}
+
+// recordLockFile records the package manager of the audited lock file, but not its path
+func (t *telemetryReporter) recordLockFile(lockFile string) {
+	if t == nil {
+		return
+	}
+	t.event.PackageManager = audit.PackageManagerFor(lockFile)
+}
+
+// recordResults records the outcome counts of the results and the classes of their errors
+func (t *telemetryReporter) recordResults(results []audit.AuditResult) {
+	if t == nil {
+		return
+	}
+	t.event.Packages = len(results)
+	t.event.Blocked, t.event.NotFound = 0, 0
+	t.event.ErrorClasses = nil
+	for _, result := range results {
+		switch {
+		case result.Error != nil:
+			if t.event.ErrorClasses == nil {
+				t.event.ErrorClasses = make(map[string]int)
+			}
+			t.event.ErrorClasses[classifyError(result.Error)]++
+		case isBlocked(result):
+			t.event.Blocked++
+		case result.StatusCode == http.StatusNotFound:
+			t.event.NotFound++
+		}
+	}
+}
+
+// send reports the usage of the run, recording the stage it failed at, if any. Telemetry never fails or slows
+// down a run beyond the client timeout, so errors are only logged at debug level.
+func (t *telemetryReporter) send(failedStage string) {
+	if t == nil {
+		return
+	}
+	t.event.DurationMs = time.Since(t.start).Milliseconds()
+	t.event.FailedStage = failedStage
+
+	payload, err := json.Marshal(t.event)
+	if err != nil {
+		log.Debug("Could not encode usage metrics:", err.Error())
+		return
+	}
+	resp, err := t.client.Post(t.endpoint, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		log.Debug("Could not send usage metrics:", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Debug(fmt.Sprintf("Usage metrics endpoint responded %d", resp.StatusCode))
+	}
+}
+
+// classifyError returns a coarse class of a check error, which reveals nothing about the registry
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, &dnsErr):
+		return "dns"
+	case errors.As(err, &certErr), errors.As(err, &recordErr):
+		return "tls"
+	case errors.As(err, &netErr):
+		return "network"
+	default:
+		return "other"
+	}
+}
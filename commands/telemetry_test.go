@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelemetryIsOptIn(t *testing.T) {
+	reporter, err := getTelemetryReporter(&components.Context{}, "audit")
+	assert.NoError(t, err)
+	assert.Nil(t, reporter)
+
+	t.Setenv(telemetryEndpointEnv, "https://telemetry.acme.io/v1/events")
+	reporter, err = getTelemetryReporter(&components.Context{}, "audit")
+	assert.NoError(t, err)
+	assert.NotNil(t, reporter)
+
+	// The off switch wins over any configured endpoint
+	t.Setenv(telemetryEnv, "OFF")
+	reporter, err = getTelemetryReporter(&components.Context{}, "audit")
+	assert.NoError(t, err)
+	assert.Nil(t, reporter)
+
+	// A nil reporter records and sends nothing
+	reporter.recordLockFile("pnpm-lock.yaml")
+	reporter.recordResults([]audit.AuditResult{{Name: "abbrev"}})
+	reporter.send("")
+}
+
+func TestTelemetryEndpointIsNotReadFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "ci.yaml")
+	require.NoError(t, os.WriteFile(config, []byte("telemetry-endpoint: https://telemetry.acme.io/v1/events\n"), 0644))
+	profile := filepath.Join(dir, "profile.yaml")
+	require.NoError(t, os.WriteFile(profile, []byte("name: acme\nversion: 1.4.0\nsettings:\n  telemetry-endpoint: https://telemetry.acme.io/v1/events\n"), 0644))
+	t.Setenv(projectConfigEnv, config)
+	t.Setenv(profileEnv, profile)
+	t.Setenv(telemetryEndpointEnv, "")
+
+	reporter, err := getTelemetryReporter(&components.Context{}, "audit")
+	assert.NoError(t, err)
+	assert.Nil(t, reporter)
+}
+
+func TestTelemetryInvalidEndpoint(t *testing.T) {
+	t.Setenv(telemetryEndpointEnv, "telemetry.acme.io")
+	_, err := getTelemetryReporter(&components.Context{}, "audit")
+	assert.ErrorContains(t, err, "invalid --telemetry-endpoint 'telemetry.acme.io'")
+}
+
+func TestTelemetrySendsAnonymousUsage(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	reporter := newTelemetryReporter(server.URL, "audit")
+	reporter.recordLockFile("/home/dev/secret-project/package-lock.json")
+	reporter.recordResults([]audit.AuditResult{
+		{Name: "abbrev", Version: "1.1.1", StatusCode: http.StatusOK},
+		{Name: "@acme/internal", Version: "2.0.0", StatusCode: http.StatusForbidden},
+		{Name: "left-pad", Version: "1.3.0", StatusCode: http.StatusNotFound},
+		{Name: "yallist", Version: "4.0.0", Error: &net.DNSError{Err: "no such host", Name: "acme.jfrog.io"}},
+	})
+	reporter.send("report")
+
+	require.NotNil(t, received)
+	assert.Equal(t, "audit", received["command"])
+	assert.Equal(t, "npm", received["packageManager"])
+	assert.Equal(t, runtime.GOOS, received["os"])
+	assert.Equal(t, float64(4), received["packages"])
+	assert.Equal(t, float64(1), received["blocked"])
+	assert.Equal(t, float64(1), received["notFound"])
+	assert.Equal(t, "report", received["failedStage"])
+	assert.Equal(t, map[string]interface{}{"dns": float64(1)}, received["errorClasses"])
+
+	payload, err := json.Marshal(received)
+	require.NoError(t, err)
+	for _, identifying := range []string{"secret-project", "@acme/internal", "acme.jfrog.io"} {
+		assert.NotContains(t, string(payload), identifying)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	assert.Equal(t, "dns", classifyError(&net.DNSError{Err: "no such host"}))
+	assert.Equal(t, "timeout", classifyError(&net.DNSError{Err: "timeout", IsTimeout: true}))
+	assert.Equal(t, "network", classifyError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.Equal(t, "other", classifyError(errors.New("invalid scoped package format")))
+}
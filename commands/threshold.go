@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	failOnFlag     = "fail-on"
+	maxBlockedFlag = "max-blocked"
+
+	failOnBlocked  = "blocked"
+	failOnNotFound = "not-found"
+	failOnAny      = "any"
+)
+
+// failurePolicy decides whether the findings of an audit fail the run
+type failurePolicy struct {
+	// Findings that count towards the threshold, or empty to never fail on findings
+	failOn string
+	// Number of findings tolerated before the run fails
+	maxBlocked int
+	// Findings acknowledged by the ignore rules, or in the baseline, don't count
+	ignore   *ignoreList
+	baseline *auditBaseline
+	// Accepted findings unresolved past --escalate-after fail the run whatever failOn is
+	escalation *escalationPolicy
+}
+
+func getThresholdFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			failOnFlag,
+			"Fail the run when the audit finds packages that are blocked (403), not-found (404), or any that wouldn't install. Defaults to blocked with --"+maxBlockedFlag,
+			components.WithHelpValue(failOnBlocked+"|"+failOnNotFound+"|"+failOnAny),
+		),
+		components.NewStringFlag(
+			maxBlockedFlag,
+			"Number of --"+failOnFlag+" findings tolerated before the run fails",
+			components.WithHelpValue("n"),
+		),
+	}
+}
+
+func getFailurePolicy(c *components.Context) (*failurePolicy, error) {
+	return parseFailurePolicy(flagOrConfig(c, failOnFlag), flagOrConfig(c, maxBlockedFlag))
+}
+
+func parseFailurePolicy(failOn, maxBlocked string) (*failurePolicy, error) {
+	policy := &failurePolicy{failOn: failOn}
+	switch policy.failOn {
+	case "", failOnBlocked, failOnNotFound, failOnAny:
+	default:
+		return nil, fmt.Errorf("unsupported --%s '%s'. Expected %s, %s or %s", failOnFlag, policy.failOn, failOnBlocked, failOnNotFound, failOnAny)
+	}
+	if maxBlocked != "" {
+		tolerated, err := strconv.Atoi(maxBlocked)
+		if err != nil || tolerated < 0 {
+			return nil, fmt.Errorf("--%s must be a number of at least 0, got '%s'", maxBlockedFlag, maxBlocked)
+		}
+		policy.maxBlocked = tolerated
+		if policy.failOn == "" {
+			policy.failOn = failOnBlocked
+		}
+	}
+	return policy, nil
+}
+
+// matches reports whether a result counts towards the threshold
+func (policy *failurePolicy) matches(result audit.AuditResult) bool {
+	if policy.accepts(result) {
+		return false
+	}
+	switch policy.failOn {
+	case failOnBlocked:
+		return isBlocked(result)
+	case failOnNotFound:
+		return result.Error == nil && result.StatusCode == http.StatusNotFound
+	case failOnAny:
+		return isBlocking(result)
+	}
+	return false
+}
+
+// accepts reports whether the findings of a result are acknowledged by an ignore rule or in the baseline
+func (policy *failurePolicy) accepts(result audit.AuditResult) bool {
+	if policy == nil {
+		return false
+	}
+	return policy.ignore.acknowledged(result) != nil || policy.baseline.accepted(result)
+}
+
+// annotate marks an entry with the ignore rule acknowledging its findings, or as in the baseline
+func (policy *failurePolicy) annotate(entry *ResultEntry, result audit.AuditResult) {
+	if policy == nil {
+		return
+	}
+	if rule := policy.ignore.acknowledged(result); rule != nil {
+		entry.Acknowledged = rule.acknowledgement()
+	}
+	entry.Baseline = policy.baseline.accepted(result)
+}
+
+// evaluate fails when more results than tolerated match, with a summary line describing why
+func (policy *failurePolicy) evaluate(results []audit.AuditResult) error {
+	if policy == nil {
+		return nil
+	}
+	if err := policy.escalation.evaluate(results, policy.ignore, policy.baseline); err != nil {
+		return err
+	}
+	if policy.failOn == "" {
+		return nil
+	}
+	matched := 0
+	for _, result := range results {
+		if policy.matches(result) {
+			matched++
+		}
+	}
+	if matched <= policy.maxBlocked {
+		return nil
+	}
+
+	findings := map[string]string{
+		failOnBlocked:  "are blocked by curation",
+		failOnNotFound: "are not found in the curated registry",
+		failOnAny:      "wouldn't install from the curated registry",
+	}[policy.failOn]
+	if policy.maxBlocked == 0 {
+		return fmt.Errorf("audit failed: %d of %d packages %s (--%s=%s)",
+			matched, len(results), findings, failOnFlag, policy.failOn)
+	}
+	return fmt.Errorf("audit failed: %d of %d packages %s (--%s=%s), more than the %d allowed by --%s",
+		matched, len(results), findings, failOnFlag, policy.failOn, policy.maxBlocked, maxBlockedFlag)
+}
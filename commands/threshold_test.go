@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var thresholdResults = []audit.AuditResult{
+	{Name: "abbrev", Version: "1.1.1", StatusCode: http.StatusOK},
+	{Name: "lodash", Version: "4.17.20", StatusCode: http.StatusForbidden},
+	{Name: "yallist", Version: "4.0.0", StatusCode: http.StatusForbidden},
+	{Name: "left-pad", Version: "1.3.0", StatusCode: http.StatusNotFound},
+	{Name: "semver", Version: "7.6.0", Error: errors.New("connection reset")},
+}
+
+func TestFailurePolicy(t *testing.T) {
+	policy, err := parseFailurePolicy("", "")
+	require.NoError(t, err)
+	assert.NoError(t, policy.evaluate(thresholdResults))
+
+	policy, err = parseFailurePolicy(failOnBlocked, "")
+	require.NoError(t, err)
+	assert.EqualError(t, policy.evaluate(thresholdResults), "audit failed: 2 of 5 packages are blocked by curation (--fail-on=blocked)")
+	assert.NoError(t, policy.evaluate(thresholdResults[:1]))
+
+	policy, err = parseFailurePolicy(failOnNotFound, "")
+	require.NoError(t, err)
+	assert.EqualError(t, policy.evaluate(thresholdResults), "audit failed: 1 of 5 packages are not found in the curated registry (--fail-on=not-found)")
+
+	policy, err = parseFailurePolicy(failOnAny, "3")
+	require.NoError(t, err)
+	assert.EqualError(t, policy.evaluate(thresholdResults),
+		"audit failed: 4 of 5 packages wouldn't install from the curated registry (--fail-on=any), more than the 3 allowed by --max-blocked")
+
+	// --max-blocked alone fails on blocked packages
+	policy, err = parseFailurePolicy("", "2")
+	require.NoError(t, err)
+	assert.Equal(t, failOnBlocked, policy.failOn)
+	assert.NoError(t, policy.evaluate(thresholdResults))
+}
+
+func TestFailurePolicyIgnoresPendingCuration(t *testing.T) {
+	results := []audit.AuditResult{
+		{Name: "lodash", Version: "4.17.20", StatusCode: http.StatusForbidden},
+		{Name: "yallist", Version: "4.0.0", StatusCode: http.StatusForbidden, Status: "⚠️ Not Cached (curation pending)", Pending: true},
+	}
+	for _, failOn := range []string{failOnBlocked, failOnAny} {
+		policy, err := parseFailurePolicy(failOn, "")
+		require.NoError(t, err)
+		assert.True(t, policy.matches(results[0]), failOn)
+		assert.False(t, policy.matches(results[1]), failOn)
+		assert.NoError(t, policy.evaluate(results[1:]), failOn)
+	}
+}
+
+func TestParseFailurePolicyInvalid(t *testing.T) {
+	_, err := parseFailurePolicy("warnings", "")
+	assert.ErrorContains(t, err, "unsupported --fail-on 'warnings'")
+	_, err = parseFailurePolicy(failOnBlocked, "-1")
+	assert.ErrorContains(t, err, "--max-blocked must be a number of at least 0, got '-1'")
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+const (
+	requestTimeoutFlag = "request-timeout"
+	auditTimeoutFlag   = "audit-timeout"
+
+	requestTimeoutEnv = "CA_EXTENSION_REQUEST_TIMEOUT"
+	auditTimeoutEnv   = "CA_EXTENSION_AUDIT_TIMEOUT"
+)
+
+// Timeout of each registry request of the package checks unless configured otherwise
+const defaultRequestTimeout = 30 * time.Second
+
+func getRequestTimeoutFlag() components.Flag {
+	return components.NewStringFlag(
+		requestTimeoutFlag,
+		"Timeout of each registry request of the package checks, e.g. 2m behind slow proxies. A request timing out is retried as a transient error. Defaults to "+defaultRequestTimeout.String(),
+		components.WithHelpValue("duration"),
+	)
+}
+
+func getAuditTimeoutFlag() components.Flag {
+	return components.NewStringFlag(
+		auditTimeoutFlag,
+		"Deadline of the package checks of the whole audit, e.g. 20m. The checks in flight are aborted when it passes, and the packages left are reported as timed out. Defaults to no deadline",
+		components.WithHelpValue("duration"),
+	)
+}
+
+// parseTimeout reads the duration of a timeout flag, or returns the default when it is not set
+func parseTimeout(flag, value string, defaultTimeout time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid --%s '%s'. Expected a positive duration such as 45s or 10m", flag, value)
+	}
+	return timeout, nil
+}
+
+// timedOutResults counts the results of the checks that timed out
+func timedOutResults(results []audit.AuditResult) int {
+	count := 0
+	for _, result := range results {
+		if audit.IsTimeout(result.Error) {
+			count++
+		}
+	}
+	return count
+}
+
+// requestTimeouts counts the results of the checks whose requests timed out, leaving out the checks the deadline
+// of a --budget kept from starting
+func requestTimeouts(results []audit.AuditResult) int {
+	count := 0
+	for _, result := range results {
+		if audit.IsTimeout(result.Error) && !errors.Is(result.Error, audit.ErrDeadlineExceeded) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimeout(t *testing.T) {
+	timeout, err := parseTimeout(requestTimeoutFlag, "", defaultRequestTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultRequestTimeout, timeout)
+
+	timeout, err = parseTimeout(requestTimeoutFlag, "2m", defaultRequestTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, timeout)
+
+	for _, value := range []string{"0", "-5s", "soon"} {
+		_, err = parseTimeout(auditTimeoutFlag, value, 0)
+		assert.ErrorContains(t, err, "invalid --audit-timeout", value)
+	}
+}
+
+func TestTimedOutAuditReportsPackages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	notifications, err := newNotificationDispatcher(&notificationConfig{}, nil)
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	conf := &auditConfiguration{
+		registry:   &registryConfiguration{registryURL: server.URL, requestTimeout: time.Minute},
+		lockFile:   filepath.Join("..", "audit", "testdata", "pnpm-lock.yaml"),
+		workers:    2,
+		treeOutput: filepath.Join(dir, defaultTreeFileName),
+		output:     filepath.Join(dir, "results.json"),
+		display:    newDisplayFormat(true),
+		stages:     newRunStages(nil),
+		timeout:    50 * time.Millisecond,
+
+		notifications: notifications,
+	}
+	assert.ErrorContains(t, runAudit(context.Background(), conf), "audit timed out after 50ms")
+
+	// Unlike an interrupt, the packages left are reported as timed out
+	report, err := loadAuditReport(conf.output)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, report.Results)
+	for _, entry := range report.Results {
+		assert.True(t, entry.TimedOut, entry.Name)
+		assert.Equal(t, "⏱️ Timed Out", entry.Status)
+	}
+}
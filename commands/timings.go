@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+const (
+	verboseFlag = "verbose"
+
+	verboseEnv = "CA_EXTENSION_VERBOSE"
+)
+
+// Phases of the --verbose timing breakdown, finer than the stages of --budget: the graph phase saves the dependency
+// tree and indexes it, the dedupe phase reduces it to the package versions to check, and the network phase checks
+// and enriches them against the registry
+const (
+	phaseParse   = "parse"
+	phaseGraph   = "graph"
+	phaseDedupe  = "dedupe"
+	phaseNetwork = "network"
+	phaseReport  = "report"
+)
+
+var timingPhaseNames = []string{phaseParse, phaseGraph, phaseDedupe, phaseNetwork, phaseReport}
+
+// timingBreakdown times the phases of a run and the registry requests of its network phase for --verbose, which
+// prints them when the run ends and sends them nowhere. A nil breakdown times nothing.
+type timingBreakdown struct {
+	start     time.Time
+	durations map[string]time.Duration
+
+	current string
+	started time.Time
+
+	// Registry requests sent, and the time until their responses, in nanoseconds
+	requests    int64
+	requestTime int64
+}
+
+func getVerboseFlag() components.Flag {
+	return components.NewBoolFlag(
+		verboseFlag,
+		"Print the time each phase of the run took when it ends, parse, graph, dedupe, network and report, to tell a slow registry from a slow tree. Also set by "+verboseEnv+"=true",
+		components.WithBoolDefaultValue(false),
+	)
+}
+
+// getTimingBreakdown returns the breakdown of a --verbose run, or nil
+func getTimingBreakdown(c *components.Context) *timingBreakdown {
+	enabled, _ := strconv.ParseBool(os.Getenv(verboseEnv))
+	if !enabled && !c.GetBoolFlagValue(verboseFlag) {
+		return nil
+	}
+	return newTimingBreakdown()
+}
+
+func newTimingBreakdown() *timingBreakdown {
+	return &timingBreakdown{start: time.Now(), durations: make(map[string]time.Duration)}
+}
+
+// phase ends the current phase and starts the next one. A phase started again adds to its time.
+func (b *timingBreakdown) phase(name string) {
+	if b == nil {
+		return
+	}
+	b.finish()
+	b.current, b.started = name, time.Now()
+}
+
+// finish ends the current phase, if any
+func (b *timingBreakdown) finish() {
+	if b == nil || b.current == "" {
+		return
+	}
+	b.durations[b.current] += time.Since(b.started)
+	b.current = ""
+}
+
+// transport times the requests of base, until their responses
+func (b *timingBreakdown) transport(base http.RoundTripper) http.RoundTripper {
+	if b == nil {
+		return base
+	}
+	return &timedTransport{base: base, timings: b}
+}
+
+type timedTransport struct {
+	base    http.RoundTripper
+	timings *timingBreakdown
+}
+
+func (t *timedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	atomic.AddInt64(&t.timings.requests, 1)
+	atomic.AddInt64(&t.timings.requestTime, int64(time.Since(started)))
+	return resp, err
+}
+
+// table renders the time of each phase and of the whole run, with the registry requests of the network phase
+func (b *timingBreakdown) table(display *displayFormat) string {
+	var rows [][3]string
+	for _, name := range timingPhaseNames {
+		duration, timed := b.durations[name]
+		if !timed {
+			continue
+		}
+		note := ""
+		if requests := atomic.LoadInt64(&b.requests); name == phaseNetwork && requests > 0 {
+			average := time.Duration(atomic.LoadInt64(&b.requestTime) / requests)
+			note = fmt.Sprintf("%s registry requests, %s on average", display.count(int(requests)), display.duration(average))
+		}
+		rows = append(rows, [3]string{name, display.duration(duration), note})
+	}
+	rows = append(rows, [3]string{"total", display.duration(time.Since(b.start)), ""})
+
+	labelWidth, valueWidth := 0, 0
+	for _, row := range rows {
+		labelWidth, valueWidth = max(labelWidth, len(row[0])), max(valueWidth, len(row[1]))
+	}
+	var sb strings.Builder
+	sb.WriteString("Timings:\n")
+	for _, row := range rows {
+		line := fmt.Sprintf("  %s%s  %s%s", row[0], strings.Repeat(" ", labelWidth-len(row[0])), strings.Repeat(" ", valueWidth-len(row[1])), row[1])
+		if row[2] != "" {
+			line += "  " + row[2]
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// print ends the current phase and writes the table to stderr, apart from the results and reports on stdout
+func (b *timingBreakdown) print(display *displayFormat) {
+	if b == nil {
+		return
+	}
+	b.finish()
+	fmt.Fprint(os.Stderr, "\n"+b.table(display))
+}
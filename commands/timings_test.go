@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimingBreakdownTable(t *testing.T) {
+	timings := &timingBreakdown{
+		start: time.Now().Add(-4 * time.Second),
+		durations: map[string]time.Duration{
+			phaseParse:   120 * time.Millisecond,
+			phaseDedupe:  3 * time.Millisecond,
+			phaseNetwork: 3700 * time.Millisecond,
+			phaseReport:  45 * time.Millisecond,
+		},
+		requests:    1200,
+		requestTime: int64(1200 * 25 * time.Millisecond),
+	}
+	assert.Equal(t, "Timings:\n"+
+		"  parse    120ms\n"+
+		"  dedupe     3ms\n"+
+		"  network   3.7s  1200 registry requests, 25ms on average\n"+
+		"  report    45ms\n"+
+		"  total     4.0s\n", timings.table(newDisplayFormat(true)))
+
+	var none *timingBreakdown
+	none.phase(phaseParse)
+	none.print(newDisplayFormat(true))
+	assert.Equal(t, http.DefaultTransport, none.transport(http.DefaultTransport))
+}
+
+func TestAuditTimingBreakdown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifications, err := newNotificationDispatcher(&notificationConfig{}, nil)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	timings := newTimingBreakdown()
+	conf := &auditConfiguration{
+		registry:   &registryConfiguration{registryURL: server.URL, requestTimeout: time.Minute, timings: timings},
+		lockFile:   filepath.Join("..", "audit", "testdata", "pnpm-workspace", "pnpm-lock.yaml"),
+		workers:    2,
+		treeOutput: filepath.Join(dir, defaultTreeFileName),
+		display:    newDisplayFormat(true),
+		stages:     newRunStages(nil),
+
+		notifications: notifications,
+	}
+	require.NoError(t, runAudit(context.Background(), conf))
+
+	assert.Equal(t, timingPhaseNames, sortedPhases(timings))
+	assert.Equal(t, int64(6), timings.requests)
+	assert.Empty(t, timings.current)
+}
+
+// sortedPhases returns the timed phases, in the order they run
+func sortedPhases(timings *timingBreakdown) []string {
+	var phases []string
+	for _, name := range timingPhaseNames {
+		if _, timed := timings.durations[name]; timed {
+			phases = append(phases, name)
+		}
+	}
+	return phases
+}
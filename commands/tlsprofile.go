@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+const (
+	tlsProfileFlag = "tls-profile"
+	tlsProfileEnv  = "CA_EXTENSION_TLS_PROFILE"
+)
+
+// TLS profiles of registry connections
+const (
+	// Go's defaults: TLS 1.2 and 1.3 with the secure cipher suites
+	tlsProfileDefault = "default"
+	// TLS 1.3 only
+	tlsProfileModern = "modern"
+	// TLS 1.2 and 1.3 with the FIPS 140 approved cipher suites and curves only
+	tlsProfileFIPS = "fips"
+)
+
+// FIPS 140 approved suites of TLS 1.2. The TLS 1.3 suites aren't configurable, and all are approved but
+// ChaCha20-Poly1305, which FIPS builds don't negotiate.
+var fipsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+var fipsCurves = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+
+func getTLSProfileFlag() components.Flag {
+	return components.NewStringFlag(
+		tlsProfileFlag,
+		"TLS versions and cipher suites of registry connections: "+tlsProfileDefault+", "+tlsProfileModern+" (TLS 1.3 only), or "+tlsProfileFIPS+" (FIPS 140 approved suites and curves only). Defaults to "+buildTLSProfile,
+		components.WithHelpValue(tlsProfileDefault+"|"+tlsProfileModern+"|"+tlsProfileFIPS),
+	)
+}
+
+// parseTLSProfile returns the profile, or the default of the build. FIPS builds refuse the other profiles.
+func parseTLSProfile(profile string) (string, error) {
+	profile = strings.ToLower(profile)
+	switch profile {
+	case "":
+		return buildTLSProfile, nil
+	case tlsProfileDefault, tlsProfileModern:
+		if buildTLSProfile == tlsProfileFIPS {
+			return "", fmt.Errorf("--%s %s is not allowed by this FIPS build. Expected %s", tlsProfileFlag, profile, tlsProfileFIPS)
+		}
+		return profile, nil
+	case tlsProfileFIPS:
+		return profile, nil
+	}
+	return "", fmt.Errorf("unsupported --%s '%s'. Expected %s, %s or %s", tlsProfileFlag, profile, tlsProfileDefault, tlsProfileModern, tlsProfileFIPS)
+}
+
+// applyTLSProfile restricts a TLS configuration to the versions and suites of the profile
+func applyTLSProfile(config *tls.Config, profile string) {
+	switch profile {
+	case tlsProfileModern:
+		config.MinVersion = tls.VersionTLS13
+	case tlsProfileFIPS:
+		config.MinVersion = tls.VersionTLS12
+		config.MaxVersion = tls.VersionTLS13
+		config.CipherSuites = fipsCipherSuites
+		config.CurvePreferences = fipsCurves
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !fips
+
+package commands
+
+// buildTLSProfile is the TLS profile of registry connections unless --tls-profile is set
+const buildTLSProfile = tlsProfileDefault
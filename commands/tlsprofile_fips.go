@@ -0,0 +1,6 @@
+//go:build fips
+
+package commands
+
+// buildTLSProfile is the TLS profile of FIPS builds, which only allow the fips profile
+const buildTLSProfile = tlsProfileFIPS
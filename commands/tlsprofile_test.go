@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"crypto/tls"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTLSProfile(t *testing.T) {
+	profile, err := parseTLSProfile("")
+	assert.NoError(t, err)
+	assert.Equal(t, buildTLSProfile, profile)
+
+	profile, err = parseTLSProfile("FIPS")
+	assert.NoError(t, err)
+	assert.Equal(t, tlsProfileFIPS, profile)
+
+	_, err = parseTLSProfile("legacy")
+	assert.ErrorContains(t, err, "unsupported --tls-profile 'legacy'")
+}
+
+func TestApplyTLSProfile(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	get := func(profile string) (*tls.ConnectionState, error) {
+		transport := server.Client().Transport.(*http.Transport).Clone()
+		applyTLSProfile(transport.TLSClientConfig, profile)
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+		return resp.TLS, nil
+	}
+
+	state, err := get(tlsProfileFIPS)
+	assert.NoError(t, err)
+	assert.Equal(t, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, state.CipherSuite)
+
+	_, err = get(tlsProfileModern)
+	assert.Error(t, err)
+}
@@ -0,0 +1,290 @@
+package commands
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"golang.org/x/net/proxy"
+)
+
+const (
+	proxyFlag    = "proxy"
+	resolveFlag  = "resolve"
+	ipFamilyFlag = "ip-family"
+
+	proxyEnv    = "CA_EXTENSION_PROXY"
+	resolveEnv  = "CA_EXTENSION_RESOLVE"
+	ipFamilyEnv = "CA_EXTENSION_IP_FAMILY"
+)
+
+// Idle connections kept per registry host by the commands without a worker count, more than the default of 2 so
+// concurrent checks reuse theirs
+const defaultIdleConnsPerHost = 32
+
+// IP families registry connections may use
+const (
+	ipFamilyAuto = "auto"
+	ipFamilyIPv4 = "ipv4"
+	ipFamilyIPv6 = "ipv6"
+)
+
+func getTransportFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag(
+			proxyFlag,
+			"Proxy of registry requests: http://, https://, or socks5:// (socks5h:// resolves hosts through the proxy), e.g. an SSH dynamic forward. The hosts NO_PROXY lists are reached directly. Defaults to HTTPS_PROXY and HTTP_PROXY",
+			components.WithHelpValue("url"),
+		),
+		components.NewStringFlag(
+			resolveFlag,
+			"Comma separated host:ip DNS overrides of the hosts registry requests connect to, e.g. acme.jfrog.io:10.0.0.12 in split-horizon DNS environments",
+			components.WithHelpValue("host:ip"),
+		),
+		components.NewStringFlag(
+			ipFamilyFlag,
+			"IP family of registry connections: auto dials IPv4 and IPv6 addresses in parallel (Happy Eyeballs), ipv4 or ipv6 only dial that family. Defaults to auto",
+			components.WithHelpValue(ipFamilyAuto+"|"+ipFamilyIPv4+"|"+ipFamilyIPv6),
+		),
+	}
+}
+
+func parseProxyURL(value string) (*url.URL, error) {
+	if value == "" {
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s URL: %v", proxyFlag, err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported --%s scheme '%s'. Expected http, https, socks5 or socks5h", proxyFlag, proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid --%s URL '%s': missing host", proxyFlag, value)
+	}
+	return proxyURL, nil
+}
+
+// parseResolveOverrides parses host:ip overrides, where IPv6 addresses may be bracketed, e.g. acme.jfrog.io:[fd00::12]
+func parseResolveOverrides(value string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	for _, override := range splitList(value) {
+		host, address, found := strings.Cut(override, ":")
+		address = strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+		if !found || host == "" || net.ParseIP(address) == nil {
+			return nil, fmt.Errorf("invalid --%s override '%s'. Expected host:ip", resolveFlag, override)
+		}
+		overrides[strings.ToLower(host)] = address
+	}
+	return overrides, nil
+}
+
+func parseIPFamily(family string) (string, error) {
+	switch family {
+	case "", ipFamilyAuto:
+		return ipFamilyAuto, nil
+	case ipFamilyIPv4, ipFamilyIPv6:
+		return family, nil
+	}
+	return "", fmt.Errorf("unsupported --%s '%s'. Expected %s, %s or %s", ipFamilyFlag, family, ipFamilyAuto, ipFamilyIPv4, ipFamilyIPv6)
+}
+
+// resolveAddress replaces the host of a dialed address with its DNS override
+func (registry *registryConfiguration) resolveAddress(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	if ip, ok := registry.resolve[strings.ToLower(host)]; ok {
+		return net.JoinHostPort(ip, port)
+	}
+	return address
+}
+
+// dialNetwork restricts a dialed network to the IP family
+func (registry *registryConfiguration) dialNetwork(network string) string {
+	if network != "tcp" {
+		return network
+	}
+	switch registry.ipFamily {
+	case ipFamilyIPv4:
+		return "tcp4"
+	case ipFamilyIPv6:
+		return "tcp6"
+	}
+	return network
+}
+
+// transport returns the round tripper of registry requests, which goes through the proxy and adds the project and
+// custom headers, and records or replays the requests of a cassette. It is created once per configuration, so the requests
+// of a command, or of every check a long-running command serves, reuse its connections instead of resolving hosts
+// and handshaking again.
+func (registry *registryConfiguration) transport() http.RoundTripper {
+	registry.transportOnce.Do(func() {
+		base := registry.timings.transport(registry.cassette.transport(registry.dialingTransport()))
+		if registry.project != "" {
+			base = &projectTransport{base: base, registry: registry}
+		}
+		if len(registry.headers) > 0 {
+			base = &headerTransport{base: base, headers: registry.headers}
+		}
+		if registry.rateLimiter != nil {
+			base = &rateLimitedTransport{base: base, limiter: registry.rateLimiter}
+		}
+		if len(registry.readReplicas) > 0 {
+			base = &failoverTransport{base: base, registry: registry}
+		}
+		registry.roundTripper = base
+	})
+	return registry.roundTripper
+}
+
+// contextDialer adapts a dial function to the dialers of golang.org/x/net/proxy
+type contextDialer func(ctx context.Context, network, address string) (net.Conn, error)
+
+func (d contextDialer) Dial(network, address string) (net.Conn, error) {
+	return d(context.Background(), network, address)
+}
+
+func (d contextDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d(ctx, network, address)
+}
+
+// dialingTransport returns a transport that dials with the IP family restriction and the DNS overrides, and sends
+// requests through the proxy, dialing SOCKS5 proxies itself
+func (registry *registryConfiguration) dialingTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Keep a connection per worker, and resume TLS sessions for the connections opened later
+	transport.MaxIdleConnsPerHost = defaultIdleConnsPerHost
+	if registry.workers > 0 {
+		transport.MaxIdleConnsPerHost = registry.workers
+	}
+	// The custom dialer and TLS config would otherwise turn HTTP/2 off, and registries serving it multiplex the
+	// requests of all workers over a connection
+	transport.ForceAttemptHTTP2 = true
+	transport.TLSClientConfig = &tls.Config{ClientSessionCache: tls.NewLRUClientSessionCache(0)}
+	applyTLSProfile(transport.TLSClientConfig, registry.tlsProfile)
+	transport.TLSClientConfig.RootCAs = registry.rootCAs
+	transport.TLSClientConfig.InsecureSkipVerify = registry.insecure
+	// Same as the default transport, which dials both families in parallel (Happy Eyeballs) unless restricted
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	var dial contextDialer = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return dialer.DialContext(ctx, registry.dialNetwork(network), address)
+	}
+
+	// Without a --proxy, the default transport uses HTTPS_PROXY, HTTP_PROXY and NO_PROXY, and the hosts NO_PROXY
+	// lists also bypass the --proxy
+	noProxy := noProxyEnv()
+	direct, socks := dial, false
+	if registry.proxyURL != nil {
+		if registry.proxyURL.Scheme == "http" || registry.proxyURL.Scheme == "https" {
+			transport.Proxy = func(req *http.Request) (*url.URL, error) {
+				if bypassesProxy(noProxy, req.URL.Host, req.URL.Scheme) {
+					return nil, nil
+				}
+				return registry.proxyURL, nil
+			}
+		} else {
+			transport.Proxy, socks = nil, true
+			socksDialer, err := proxy.FromURL(registry.proxyURL, dial)
+			if err != nil {
+				// The scheme was validated with the flag, but fail the requests rather than bypass the proxy
+				dial = func(context.Context, string, string) (net.Conn, error) {
+					return nil, fmt.Errorf("error creating the SOCKS5 dialer: %v", err)
+				}
+			} else if socksContext, ok := socksDialer.(proxy.ContextDialer); ok {
+				dial = socksContext.DialContext
+			} else {
+				dial = func(_ context.Context, network, address string) (net.Conn, error) {
+					return socksDialer.Dial(network, address)
+				}
+			}
+		}
+	}
+
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		// HTTP proxies are bypassed by the Proxy function, SOCKS5 ones by dialing directly
+		if socks && bypassesProxy(noProxy, address, "") {
+			return direct(ctx, network, registry.resolveAddress(address))
+		}
+		return dial(ctx, network, registry.resolveAddress(address))
+	}
+	return transport
+}
+
+// proxyOf returns the proxy the requests to a URL go through, if any
+func (registry *registryConfiguration) proxyOf(target string) (*url.URL, error) {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	if registry.proxyURL == nil {
+		return http.ProxyFromEnvironment(&http.Request{URL: targetURL})
+	}
+	if bypassesProxy(noProxyEnv(), targetURL.Host, targetURL.Scheme) {
+		return nil, nil
+	}
+	return registry.proxyURL, nil
+}
+
+func noProxyEnv() string {
+	if value := os.Getenv("NO_PROXY"); value != "" {
+		return value
+	}
+	return os.Getenv("no_proxy")
+}
+
+// bypassesProxy reports whether the comma separated NO_PROXY entries exclude a host, with an optional port, from
+// the proxy. An entry is * for every host, an IP address or CIDR range, or a domain matching itself and its
+// subdomains, with a leading dot or not, and may end with the only port it applies to.
+func bypassesProxy(noProxy, hostPort, scheme string) bool {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		host = strings.Trim(hostPort, "[]")
+	}
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[scheme]
+	}
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+	for _, entry := range splitList(noProxy) {
+		entry = strings.ToLower(entry)
+		if entry == "*" {
+			return true
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		entryHost, entryPort, err := net.SplitHostPort(entry)
+		if err != nil {
+			entryHost, entryPort = strings.Trim(entry, "[]"), ""
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		domain := strings.TrimPrefix(strings.TrimPrefix(entryHost, "*"), ".")
+		if domain != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			return true
+		}
+	}
+	return false
+}
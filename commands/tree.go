@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+// Name of the dependency tree file audits write next to the lock file
+const defaultTreeFileName = "pnpm_dependency_tree.json"
+
+// TreePackage represents a package added to or removed from a dependency tree
+type TreePackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Type    string `json:"type,omitempty"`
+}
+
+// TreeChange represents a package whose version changed between two dependency trees
+type TreeChange struct {
+	Name        string `json:"name"`
+	FromVersion string `json:"fromVersion"`
+	ToVersion   string `json:"toVersion"`
+	Type        string `json:"type,omitempty"`
+}
+
+// TreeDiff represents the differences between two dependency trees, each list sorted by name
+type TreeDiff struct {
+	Added   []TreePackage `json:"added"`
+	Removed []TreePackage `json:"removed"`
+	Changed []TreeChange  `json:"changed"`
+}
+
+func GetTreeNamespace() components.Namespace {
+	return components.Namespace{
+		Name:        "tree",
+		Description: "Works with the dependency trees saved by 'audit'.",
+		Commands: []components.Command{
+			GetTreeDiffCommand(),
+		},
+	}
+}
+
+func GetTreeDiffCommand() components.Command {
+	return components.Command{
+		Name:        "diff",
+		Description: "Lists the packages added, removed and changed between two dependency tree files.",
+		Arguments:   getTreeDiffArguments(),
+		Flags:       getReportFlags(),
+		Action: func(c *components.Context) error {
+			return treeDiffCmd(c)
+		},
+	}
+}
+
+func getTreeDiffArguments() []components.Argument {
+	return []components.Argument{
+		{
+			Name:        "base-tree",
+			Description: "The base pnpm_dependency_tree.json file.",
+		},
+		{
+			Name:        "tree",
+			Description: "The updated pnpm_dependency_tree.json file.",
+		},
+	}
+}
+
+func treeDiffCmd(c *components.Context) error {
+	if len(c.Arguments) != 2 {
+		return wrongArguments("tree diff", "<base-tree> <tree>", len(c.Arguments))
+	}
+	base, err := loadDependencyTree(c.Arguments[0])
+	if err != nil {
+		return err
+	}
+	head, err := loadDependencyTree(c.Arguments[1])
+	if err != nil {
+		return err
+	}
+
+	output, err := renderTreeDiff(diffTrees(base, head), c.GetStringFlagValue(formatFlag))
+	if err != nil {
+		return err
+	}
+	fmt.Print(output)
+	return nil
+}
+
+func loadDependencyTree(path string) (*audit.DependencyTree, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	var tree audit.DependencyTree
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("error parsing dependency tree %s: %v", path, err)
+	}
+	if err := checkSchemaVersion(path, tree.SchemaVersion); err != nil {
+		return nil, err
+	}
+	return &tree, nil
+}
+
+// diffTrees compares the packages of two trees by name
+func diffTrees(base, head *audit.DependencyTree) TreeDiff {
+	diff := TreeDiff{Added: []TreePackage{}, Removed: []TreePackage{}, Changed: []TreeChange{}}
+	for _, name := range sortedKeys(head.Packages) {
+		info := head.Packages[name]
+		baseInfo, exists := base.Packages[name]
+		switch {
+		case !exists:
+			diff.Added = append(diff.Added, TreePackage{Name: name, Version: info.Version, Type: info.Type})
+		case baseInfo.Version != info.Version:
+			diff.Changed = append(diff.Changed, TreeChange{Name: name, FromVersion: baseInfo.Version, ToVersion: info.Version, Type: info.Type})
+		}
+	}
+	for _, name := range sortedKeys(base.Packages) {
+		if _, exists := head.Packages[name]; !exists {
+			info := base.Packages[name]
+			diff.Removed = append(diff.Removed, TreePackage{Name: name, Version: info.Version, Type: info.Type})
+		}
+	}
+	return diff
+}
+
+func renderTreeDiff(diff TreeDiff, format string) (string, error) {
+	switch format {
+	case formatJSON:
+		jsonData, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error marshaling JSON: %v", err)
+		}
+		return string(jsonData) + "\n", nil
+	case formatText, "":
+		var sb strings.Builder
+		for _, pkg := range diff.Added {
+			sb.WriteString(fmt.Sprintf("+ %s@%s\n", pkg.Name, pkg.Version))
+		}
+		for _, pkg := range diff.Removed {
+			sb.WriteString(fmt.Sprintf("- %s@%s\n", pkg.Name, pkg.Version))
+		}
+		for _, change := range diff.Changed {
+			sb.WriteString(fmt.Sprintf("~ %s %s -> %s\n", change.Name, change.FromVersion, change.ToVersion))
+		}
+		sb.WriteString(fmt.Sprintf("\n%d added, %d removed, %d changed\n", len(diff.Added), len(diff.Removed), len(diff.Changed)))
+		return sb.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported format '%s'. Expected %s or %s", format, formatText, formatJSON)
+	}
+}
+
+// saveDependencyTree writes the tree deterministically: JSON objects are sorted by key and an unchanged tree
+// isn't rewritten, so the file's content, ETag and modification time only change with the lock file
+func saveDependencyTree(dependencies *audit.DependencyTree, outputPath string) error {
+	dependencies.SchemaVersion = outputSchemaVersion
+
+	// Convert to JSON
+	jsonData, err := json.MarshalIndent(dependencies, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	jsonData = append(jsonData, '\n')
+
+	if existing, err := ioutil.ReadFile(outputPath); err == nil && bytes.Equal(existing, jsonData) {
+		fmt.Printf("PNPM dependency tree at %s is up to date\n", outputPath)
+		return nil
+	}
+
+	// Write to file
+	if err := ioutil.WriteFile(outputPath, jsonData, 0644); err != nil {
+		return fmt.Errorf("error writing JSON file: %v", err)
+	}
+
+	fmt.Printf("PNPM dependency tree saved to %s\n", outputPath)
+	return nil
+}
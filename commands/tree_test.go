@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveDependencyTreeIsStable(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "pnpm_dependency_tree.json")
+
+	first, err := audit.ParseLockFile(filepath.Join("..", "audit", "testdata", "pnpm-lock.yaml"))
+	assert.NoError(t, err)
+	assert.NoError(t, saveDependencyTree(first, outputPath))
+	written, err := os.ReadFile(outputPath)
+	assert.NoError(t, err)
+	past := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(outputPath, past, past))
+
+	second, err := audit.ParseLockFile(filepath.Join("..", "audit", "testdata", "pnpm-lock.yaml"))
+	assert.NoError(t, err)
+	assert.NoError(t, saveDependencyTree(second, outputPath))
+	rewritten, err := os.ReadFile(outputPath)
+	assert.NoError(t, err)
+	assert.Equal(t, written, rewritten)
+
+	info, err := os.Stat(outputPath)
+	assert.NoError(t, err)
+	assert.Equal(t, past.Unix(), info.ModTime().Unix())
+
+	loaded, err := loadDependencyTree(outputPath)
+	assert.NoError(t, err)
+	assert.Equal(t, first.Packages["abbrev"].Version, loaded.Packages["abbrev"].Version)
+}
+
+func TestDiffTrees(t *testing.T) {
+	base := &audit.DependencyTree{Packages: map[string]audit.PackageInfo{
+		"abbrev": {Version: "1.1.1", Type: "package"},
+		"semver": {Version: "7.6.0", Type: "package"},
+		"lodash": {Version: "4.17.21", Type: "package"},
+	}}
+	head := &audit.DependencyTree{Packages: map[string]audit.PackageInfo{
+		"abbrev":  {Version: "2.0.0", Type: "package"},
+		"semver":  {Version: "7.6.0", Type: "package"},
+		"yallist": {Version: "4.0.0", Type: "package"},
+	}}
+
+	diff := diffTrees(base, head)
+	assert.Equal(t, []TreePackage{{Name: "yallist", Version: "4.0.0", Type: "package"}}, diff.Added)
+	assert.Equal(t, []TreePackage{{Name: "lodash", Version: "4.17.21", Type: "package"}}, diff.Removed)
+	assert.Equal(t, []TreeChange{{Name: "abbrev", FromVersion: "1.1.1", ToVersion: "2.0.0", Type: "package"}}, diff.Changed)
+
+	output, err := renderTreeDiff(diff, formatText)
+	assert.NoError(t, err)
+	assert.Equal(t, "+ yallist@4.0.0\n- lodash@4.17.21\n~ abbrev 1.1.1 -> 2.0.0\n\n1 added, 1 removed, 1 changed\n", output)
+}
@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+// ParseFinding represents a lock file entry of which the name or version isn't valid in its ecosystem, so it was
+// not requested from the registry
+type ParseFinding struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Reason  string `json:"reason"`
+}
+
+// validateDependencies keeps the dependencies that are valid in the ecosystem of the registry, and lists the others
+// as parse findings
+func validateDependencies(deps []audit.Dependency, registry *registryConfiguration) ([]audit.Dependency, []ParseFinding) {
+	valid, invalid := audit.ValidateDependencies(registry.ecosystem, deps)
+	var findings []ParseFinding
+	for _, dep := range invalid {
+		findings = append(findings, ParseFinding{Name: dep.Name, Version: dep.Version, Reason: dep.Reason})
+	}
+	return valid, findings
+}
+
+func printParseFindings(findings []ParseFinding, display *displayFormat) {
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Printf("\n\nInvalid lock file entries, not audited:")
+	for _, finding := range findings {
+		fmt.Printf("\n%s@%s %s", finding.Name, finding.Version, display.status("⚠️ "+finding.Reason))
+	}
+}
@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDependencies(t *testing.T) {
+	deps := []audit.Dependency{
+		{Name: "react", Version: "18.2.0", Type: audit.TypePackage},
+		{Name: "lodash", Version: "github:lodash/lodash", Type: audit.TypePackage},
+	}
+	valid, findings := validateDependencies(deps, &registryConfiguration{ecosystem: audit.EcosystemNpm})
+	assert.Equal(t, deps[:1], valid)
+	assert.Equal(t, []ParseFinding{{Name: "lodash", Version: "github:lodash/lodash", Reason: "version 'github:lodash/lodash' of lodash isn't a valid semver version"}}, findings)
+
+	valid, findings = validateDependencies(deps[:1], &registryConfiguration{ecosystem: audit.EcosystemNpm})
+	assert.Equal(t, deps[:1], valid)
+	assert.Empty(t, findings)
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+)
+
+const versionJSONFlag = "json"
+
+// Version of the build information schema
+const buildInfoSchemaVersion = "1"
+
+// BuildInfo is the provenance of the running binary: the source revision it was built from and how, as the Go
+// toolchain embeds them, for verifying which source produced a binary
+type BuildInfo struct {
+	SchemaVersion string `json:"schemaVersion"`
+	Name          string `json:"name"`
+	Version       string `json:"version"`
+	// Module is the path of the main module, and ModuleVersion its version when built with go install <path>@<version>
+	Module        string `json:"module,omitempty"`
+	ModuleVersion string `json:"moduleVersion,omitempty"`
+	ModuleSum     string `json:"moduleSum,omitempty"`
+	GoVersion     string `json:"goVersion"`
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+	// VCS, Revision and CommitTime are those of the checkout the binary was built in, Modified whether it had
+	// uncommitted changes
+	VCS        string `json:"vcs,omitempty"`
+	Revision   string `json:"revision,omitempty"`
+	CommitTime string `json:"commitTime,omitempty"`
+	Modified   bool   `json:"modified"`
+	// Settings are the build flags and environment, e.g. -trimpath, -tags, CGO_ENABLED and GOAMD64
+	Settings     map[string]string `json:"settings,omitempty"`
+	Dependencies []BuildDependency `json:"dependencies,omitempty"`
+}
+
+// BuildDependency is a module compiled into the binary
+type BuildDependency struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+	// Replace is the module replacing it, if any
+	Replace string `json:"replace,omitempty"`
+}
+
+func GetVersionCommand() components.Command {
+	return components.Command{
+		Name:        "version",
+		Description: "Prints the version of the binary and the source revision it was built from.",
+		Flags: []components.Flag{
+			components.NewBoolFlag(
+				versionJSONFlag,
+				"Print the build information as JSON, with the build settings and the modules compiled in",
+				components.WithBoolDefaultValue(false),
+			),
+		},
+		Action: func(c *components.Context) error {
+			return versionCmd(c)
+		},
+	}
+}
+
+func versionCmd(c *components.Context) error {
+	if len(c.Arguments) != 0 {
+		return wrongArguments("version", "", len(c.Arguments))
+	}
+	info, _ := debug.ReadBuildInfo()
+	build := newBuildInfo(info)
+	if c.GetBoolFlagValue(versionJSONFlag) {
+		data, err := json.MarshalIndent(build, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling JSON: %v", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	fmt.Println(build.String())
+	return nil
+}
+
+// newBuildInfo describes the binary from the build information of the toolchain, which is nil for binaries built
+// without module support
+func newBuildInfo(info *debug.BuildInfo) *BuildInfo {
+	build := &BuildInfo{
+		SchemaVersion: buildInfoSchemaVersion,
+		Name:          appName,
+		Version:       appVersion,
+		GoVersion:     runtime.Version(),
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+	}
+	if info == nil {
+		return build
+	}
+	build.GoVersion = info.GoVersion
+	build.Module = info.Main.Path
+	if info.Main.Version != "(devel)" {
+		build.ModuleVersion = info.Main.Version
+	}
+	build.ModuleSum = info.Main.Sum
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs":
+			build.VCS = setting.Value
+		case "vcs.revision":
+			build.Revision = setting.Value
+		case "vcs.time":
+			build.CommitTime = setting.Value
+		case "vcs.modified":
+			build.Modified = setting.Value == "true"
+		default:
+			if build.Settings == nil {
+				build.Settings = make(map[string]string)
+			}
+			build.Settings[setting.Key] = setting.Value
+		}
+	}
+	for _, dep := range info.Deps {
+		dependency := BuildDependency{Path: dep.Path, Version: dep.Version, Sum: dep.Sum}
+		if dep.Replace != nil {
+			dependency.Replace = dep.Replace.Path + "@" + dep.Replace.Version
+			dependency.Sum = dep.Replace.Sum
+		}
+		build.Dependencies = append(build.Dependencies, dependency)
+	}
+	return build
+}
+
+// String is the one line summary printed by version, e.g.
+// ca-extension v1.0.0 (3f2a9c1d4e5b, 2026-03-02T10:04:05Z) go1.22.4 linux/amd64
+func (b *BuildInfo) String() string {
+	var source []string
+	if b.Revision != "" {
+		revision := b.Revision[:min(len(b.Revision), 12)]
+		if b.Modified {
+			revision += "-modified"
+		}
+		source = append(source, revision)
+	}
+	if b.CommitTime != "" {
+		source = append(source, b.CommitTime)
+	}
+	line := b.Name + " " + b.Version
+	if len(source) > 0 {
+		line += " (" + strings.Join(source, ", ") + ")"
+	}
+	return fmt.Sprintf("%s %s %s/%s", line, b.GoVersion, b.OS, b.Arch)
+}
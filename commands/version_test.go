@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBuildInfo(t *testing.T) {
+	build := newBuildInfo(&debug.BuildInfo{
+		GoVersion: "go1.22.4",
+		Main:      debug.Module{Path: "github.com/jfrog/jfrog-cli-plugin-template", Version: "v1.1.0", Sum: "h1:main="},
+		Deps: []*debug.Module{
+			{Path: "gopkg.in/yaml.v3", Version: "v3.0.1", Sum: "h1:yaml="},
+			{Path: "golang.org/x/net", Version: "v0.25.0", Replace: &debug.Module{Path: "golang.org/x/net", Version: "v0.26.0", Sum: "h1:net="}},
+		},
+		Settings: []debug.BuildSetting{
+			{Key: "-trimpath", Value: "true"},
+			{Key: "CGO_ENABLED", Value: "0"},
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "3f2a9c1d4e5b6a7980b1c2d3e4f5a6b7c8d9e0f1"},
+			{Key: "vcs.time", Value: "2026-03-02T10:04:05Z"},
+			{Key: "vcs.modified", Value: "false"},
+		},
+	})
+	assert.Equal(t, &BuildInfo{
+		SchemaVersion: buildInfoSchemaVersion,
+		Name:          appName,
+		Version:       appVersion,
+		Module:        "github.com/jfrog/jfrog-cli-plugin-template",
+		ModuleVersion: "v1.1.0",
+		ModuleSum:     "h1:main=",
+		GoVersion:     "go1.22.4",
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		VCS:           "git",
+		Revision:      "3f2a9c1d4e5b6a7980b1c2d3e4f5a6b7c8d9e0f1",
+		CommitTime:    "2026-03-02T10:04:05Z",
+		Settings:      map[string]string{"-trimpath": "true", "CGO_ENABLED": "0"},
+		Dependencies: []BuildDependency{
+			{Path: "gopkg.in/yaml.v3", Version: "v3.0.1", Sum: "h1:yaml="},
+			{Path: "golang.org/x/net", Version: "v0.25.0", Sum: "h1:net=", Replace: "golang.org/x/net@v0.26.0"},
+		},
+	}, build)
+	assert.Equal(t, "ca-extension "+appVersion+" (3f2a9c1d4e5b, 2026-03-02T10:04:05Z) go1.22.4 "+runtime.GOOS+"/"+runtime.GOARCH, build.String())
+
+	// Development builds have no module version, and may have no VCS information
+	build = newBuildInfo(&debug.BuildInfo{GoVersion: "go1.22.4", Main: debug.Module{Path: "github.com/jfrog/jfrog-cli-plugin-template", Version: "(devel)"}})
+	assert.Empty(t, build.ModuleVersion)
+	assert.Equal(t, "ca-extension "+appVersion+" go1.22.4 "+runtime.GOOS+"/"+runtime.GOARCH, build.String())
+	assert.Equal(t, runtime.Version(), newBuildInfo(nil).GoVersion)
+}
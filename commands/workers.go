@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+// printAuditResults prints the results in original order
+func printAuditResults(results []audit.AuditResult, display *displayFormat) {
+	for i, result := range results {
+		fmt.Printf("\n[%s/%s] %s@%s (%s) %s",
+			display.count(i+1), display.count(len(results)), result.Name, result.Version, describeType(result.Type, result.Relationship), display.status(result.Status))
+		if result.Error != nil {
+			fmt.Printf(" - Error: %v", result.Error)
+		}
+	}
+}
+
+// describeType describes the type of a result and its relationship when known, e.g. package, direct
+func describeType(resultType, relationship string) string {
+	if relationship == "" {
+		return resultType
+	}
+	return resultType + ", " + relationship
+}
+
+// collectAuditResults audits the dependencies and returns the results in the original dependency order. Results
+// are also sent to the stream, if any, as they complete. Checks stop starting after the deadline, if any, and are
+// aborted once the context is done.
+func collectAuditResults(ctx context.Context, deps []audit.Dependency, registry *registryConfiguration, numWorkers int, showProgress bool, stream *resultStream, deadline time.Time) []audit.AuditResult {
+	completed := 0
+	auditor := &audit.Auditor{
+		Registry: registry.auditRegistry(),
+		Workers:  numWorkers,
+		Deadline: deadline,
+		OnResult: func(result audit.AuditResult) {
+			completed++
+			if !audit.IsInterrupted(result) {
+				stream.send(result)
+			}
+
+			// Print progress
+			if showProgress {
+				fmt.Printf("\rProgress: %d/%d packages checked", completed, len(deps))
+			}
+		},
+	}
+	results := auditor.AuditContext(ctx, deps)
+
+	if showProgress {
+		fmt.Println() // New line after progress
+	}
+	return results
+}
+
+// availableResults returns the results of the packages the curated registry serves
+func availableResults(results []audit.AuditResult) []audit.AuditResult {
+	var available []audit.AuditResult
+	for _, result := range results {
+		if result.StatusCode == http.StatusOK {
+			available = append(available, result)
+		}
+	}
+	return available
+}
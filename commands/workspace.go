@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+)
+
+// workspaceProjects tells which projects of a workspace pull in each blocked package, so developers know where to
+// fix it
+type workspaceProjects struct {
+	workspace *audit.Workspace
+	// Importers pulling in each blocked package
+	blocked map[string][]string
+}
+
+// findWorkspaceProjects maps the blocked packages of a lock file with several importers to the importers pulling
+// them in, naming them after the pnpm-workspace.yaml projects next to the lock file. It returns nil for lock files
+// of a single project.
+func findWorkspaceProjects(tree *audit.DependencyTree, lockFile string, results []audit.AuditResult) (*workspaceProjects, error) {
+	if len(tree.Importers) < 2 {
+		return nil, nil
+	}
+	workspace, err := audit.ReadPnpmWorkspace(filepath.Dir(lockFile), tree.Importers)
+	if err != nil {
+		return nil, err
+	}
+	projects := &workspaceProjects{workspace: workspace, blocked: make(map[string][]string)}
+	pulledBy := tree.ImportersOf()
+	for _, result := range results {
+		if isBlocking(result) && len(pulledBy[result.Name]) > 0 {
+			projects.blocked[result.Name] = pulledBy[result.Name]
+		}
+	}
+	return projects, nil
+}
+
+// annotate records the importers pulling in each blocked package in the report
+func (p *workspaceProjects) annotate(report *AuditReport) {
+	if p == nil {
+		return
+	}
+	for i := range report.Results {
+		if report.Results[i].Type != audit.TypeBundled {
+			report.Results[i].Projects = p.blocked[report.Results[i].Name]
+		}
+	}
+}
+
+func (p *workspaceProjects) print(results []audit.AuditResult) {
+	if p == nil || len(p.blocked) == 0 {
+		return
+	}
+	fmt.Printf("\n\nWorkspace projects pulling in blocked packages:")
+	for _, result := range results {
+		importers := p.blocked[result.Name]
+		if !isBlocking(result) || len(importers) == 0 || result.Type == audit.TypeBundled {
+			continue
+		}
+		described := make([]string, len(importers))
+		for i, importer := range importers {
+			described[i] = p.workspace.Describe(importer)
+		}
+		fmt.Printf("\n%s@%s: %s", result.Name, result.Version, strings.Join(described, ", "))
+	}
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-plugin-template/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindWorkspaceProjects(t *testing.T) {
+	lockFile := filepath.Join("..", "audit", "testdata", "pnpm-workspace", "pnpm-lock.yaml")
+	tree, err := audit.ParseLockFile(lockFile)
+	assert.NoError(t, err)
+	results := []audit.AuditResult{
+		{Name: "js-tokens", Version: "4.0.0", Type: audit.TypePackage, StatusCode: http.StatusForbidden},
+		{Name: "react", Version: "18.2.0", Type: audit.TypePackage, StatusCode: http.StatusOK},
+		{Name: "typescript", Version: "5.4.2", Type: audit.TypePackage, StatusCode: http.StatusForbidden},
+	}
+
+	projects, err := findWorkspaceProjects(tree, lockFile, results)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"js-tokens":  {"apps/web", "legacy/old", "packages/ui"},
+		"typescript": {"."},
+	}, projects.blocked)
+
+	report := newAuditReport(lockFile, results)
+	projects.annotate(report)
+	assert.Equal(t, []string{"apps/web", "legacy/old", "packages/ui"}, report.Results[0].Projects)
+	assert.Nil(t, report.Results[1].Projects)
+	assertSchemaCovers(t, schemaReport, report)
+
+	// Lock files of a single project aren't annotated
+	single, err := audit.ParseLockFile(filepath.Join("..", "audit", "testdata", "pnpm-lock.yaml"))
+	assert.NoError(t, err)
+	projects, err = findWorkspaceProjects(single, "pnpm-lock.yaml", results)
+	assert.NoError(t, err)
+	assert.Nil(t, projects)
+	projects.annotate(report)
+}
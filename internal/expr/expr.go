@@ -0,0 +1,723 @@
+// Package expr compiles and evaluates boolean expressions over the fields of a record, in the subset of CEL (the
+// Common Expression Language) that filters need: field names, string, number, boolean and list literals, the ==, !=,
+// <, <=, >, >= and in relations, the !, && and || logical operators, parentheses, the size() function and the
+// startsWith, endsWith, contains and matches methods of strings, e.g.
+//
+//	status == 'blocked' && depClass == 'prod' && !name.startsWith('@acme/')
+//
+// Expressions are type checked when compiled, so a comparison of a string with a number or an unknown field fails
+// before any record is evaluated.
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Type is the type of a field or of a value of an expression
+type Type struct {
+	kind kind
+	// elem is the kind of the elements of lists
+	elem kind
+}
+
+type kind int
+
+const (
+	kindString kind = iota + 1
+	kindNumber
+	kindBool
+	kindList
+)
+
+// Types of the fields of records
+var (
+	String     = Type{kind: kindString}
+	Number     = Type{kind: kindNumber}
+	Bool       = Type{kind: kindBool}
+	StringList = Type{kind: kindList, elem: kindString}
+)
+
+func (t Type) String() string {
+	switch t.kind {
+	case kindString:
+		return "string"
+	case kindNumber:
+		return "number"
+	case kindBool:
+		return "bool"
+	case kindList:
+		return "list(" + Type{kind: t.elem}.String() + ")"
+	default:
+		return "unknown"
+	}
+}
+
+// Program is a compiled expression
+type Program struct {
+	expression string
+	root       node
+}
+
+// Compile parses an expression over records of the fields, which must evaluate to a boolean
+func Compile(expression string, fields map[string]Type) (*Program, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, fields: fields}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected '%s' at position %d", token.text, token.pos+1)
+	}
+	if root.typ() != Bool {
+		return nil, fmt.Errorf("the expression is a %s, expected a bool", root.typ())
+	}
+	return &Program{expression: expression, root: root}, nil
+}
+
+// String returns the source of the expression
+func (p *Program) String() string {
+	return p.expression
+}
+
+// Eval evaluates the expression for a record, whose values are string, float64 or int, bool and []string. Fields
+// missing from the record are the zero value of their type.
+func (p *Program) Eval(record map[string]interface{}) (bool, error) {
+	value, err := p.root.eval(record)
+	if err != nil {
+		return false, err
+	}
+	return value.(bool), nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// value is the unquoted string or the number of literals
+	value interface{}
+	pos   int
+}
+
+// Operators, the two character ones first so they take precedence
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "-", "(", ")", "[", "]", ",", "."}
+
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	for pos := 0; pos < len(expression); {
+		c := expression[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+		case c == '\'' || c == '"':
+			value, end, err := unquote(expression, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: expression[pos:end], value: value, pos: pos})
+			pos = end
+		case c >= '0' && c <= '9':
+			end := pos
+			for end < len(expression) && (expression[end] >= '0' && expression[end] <= '9' || expression[end] == '.') {
+				end++
+			}
+			number, err := strconv.ParseFloat(expression[pos:end], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number '%s' at position %d", expression[pos:end], pos+1)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: expression[pos:end], value: number, pos: pos})
+			pos = end
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			end := pos
+			for end < len(expression) && isIdentChar(expression[end]) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: expression[pos:end], pos: pos})
+			pos = end
+		default:
+			operator := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(expression[pos:], candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator == "" {
+				return nil, fmt.Errorf("unexpected '%c' at position %d", c, pos+1)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: operator, pos: pos})
+			pos += len(operator)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, text: "end of expression", pos: len(expression)}), nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// unquote reads the string literal starting at pos, returning its value and the position after its closing quote
+func unquote(expression string, pos int) (string, int, error) {
+	quote := expression[pos]
+	var value strings.Builder
+	for i := pos + 1; i < len(expression); i++ {
+		switch c := expression[i]; {
+		case c == quote:
+			return value.String(), i + 1, nil
+		case c == '\\' && i+1 < len(expression):
+			i++
+			switch escaped := expression[i]; escaped {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			default:
+				value.WriteByte(escaped)
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string at position %d", pos+1)
+}
+
+type parser struct {
+	tokens []token
+	next   int
+	fields map[string]Type
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	token := p.tokens[p.next]
+	if token.kind != tokenEOF {
+		p.next++
+	}
+	return token
+}
+
+// accept consumes the next token if it is the operator
+func (p *parser) accept(operator string) bool {
+	if token := p.peek(); token.kind == tokenOperator && token.text == operator {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(operator string) error {
+	if !p.accept(operator) {
+		token := p.peek()
+		return fmt.Errorf("expected '%s' at position %d, got '%s'", operator, token.pos+1, token.text)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseLogical("||", p.parseAnd)
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseLogical("&&", p.parseRelation)
+}
+
+func (p *parser) parseLogical(operator string, operand func() (node, error)) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		pos := p.peek().pos
+		if !p.accept(operator) {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if left.typ() != Bool || right.typ() != Bool {
+			return nil, overloadError(operator, pos, left, right)
+		}
+		left = &logicalNode{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *parser) parseRelation() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	token := p.peek()
+	operator := token.text
+	isRelation := token.kind == tokenOperator && (operator == "==" || operator == "!=" || operator == "<" || operator == "<=" || operator == ">" || operator == ">=")
+	if !isRelation && !(token.kind == tokenIdent && operator == "in") {
+		return left, nil
+	}
+	p.advance()
+	right, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	switch operator {
+	case "in":
+		if right.typ().kind != kindList || left.typ().kind != right.typ().elem {
+			return nil, overloadError(operator, token.pos, left, right)
+		}
+		return &inNode{value: left, list: right}, nil
+	case "==", "!=":
+		if left.typ() != right.typ() {
+			return nil, overloadError(operator, token.pos, left, right)
+		}
+	default:
+		if left.typ() != right.typ() || (left.typ() != String && left.typ() != Number) {
+			return nil, overloadError(operator, token.pos, left, right)
+		}
+	}
+	return &compareNode{operator: operator, left: left, right: right}, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	token := p.peek()
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if operand.typ() != Bool {
+			return nil, fmt.Errorf("no matching overload for '!' with %s at position %d", operand.typ(), token.pos+1)
+		}
+		return &notNode{operand: operand}, nil
+	}
+	if p.accept("-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if operand.typ() != Number {
+			return nil, fmt.Errorf("no matching overload for '-' with %s at position %d", operand.typ(), token.pos+1)
+		}
+		return &negateNode{operand: operand}, nil
+	}
+	return p.parseMember()
+}
+
+// parseMember reads a primary expression and the method calls on it, e.g. name.startsWith('@acme/')
+func (p *parser) parseMember() (node, error) {
+	target, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(".") {
+		method := p.advance()
+		if method.kind != tokenIdent {
+			return nil, fmt.Errorf("expected a method name at position %d, got '%s'", method.pos+1, method.text)
+		}
+		args, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+		if target, err = newCall(method, target, args); err != nil {
+			return nil, err
+		}
+	}
+	return target, nil
+}
+
+func (p *parser) parseArguments() ([]node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []node
+	for !p.accept(")") {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	token := p.advance()
+	switch token.kind {
+	case tokenString:
+		return &literalNode{value: token.value, t: String}, nil
+	case tokenNumber:
+		return &literalNode{value: token.value, t: Number}, nil
+	case tokenIdent:
+		switch token.text {
+		case "true", "false":
+			return &literalNode{value: token.text == "true", t: Bool}, nil
+		case "size":
+			args, err := p.parseArguments()
+			if err != nil {
+				return nil, err
+			}
+			if len(args) != 1 {
+				return nil, fmt.Errorf("size() takes 1 argument at position %d, got %d", token.pos+1, len(args))
+			}
+			return newCall(token, args[0], nil)
+		}
+		t, exists := p.fields[token.text]
+		if !exists {
+			return nil, fmt.Errorf("unknown field '%s' at position %d. Expected one of: %s", token.text, token.pos+1, strings.Join(fieldNames(p.fields), ", "))
+		}
+		return &fieldNode{name: token.text, t: t}, nil
+	case tokenOperator:
+		switch token.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		case "[":
+			return p.parseList(token)
+		}
+	}
+	return nil, fmt.Errorf("unexpected '%s' at position %d", token.text, token.pos+1)
+}
+
+// parseList reads the literal list after its opening bracket, of elements of the same string or number type
+func (p *parser) parseList(open token) (node, error) {
+	list := &listNode{t: StringList}
+	for !p.accept("]") {
+		if len(list.items) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		item, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if item.typ() != String && item.typ() != Number {
+			return nil, fmt.Errorf("lists can only have strings or numbers, got a %s at position %d", item.typ(), open.pos+1)
+		}
+		if len(list.items) == 0 {
+			list.t = Type{kind: kindList, elem: item.typ().kind}
+		} else if item.typ().kind != list.t.elem {
+			return nil, fmt.Errorf("the elements of the list at position %d aren't all of the same type", open.pos+1)
+		}
+		list.items = append(list.items, item)
+	}
+	return list, nil
+}
+
+// newCall type checks a method call, or size()
+func newCall(method token, target node, args []node) (node, error) {
+	call := &callNode{method: method.text, target: target, args: args}
+	switch method.text {
+	case "size":
+		if len(args) != 0 || (target.typ() != String && target.typ().kind != kindList) {
+			return nil, fmt.Errorf("no matching overload for size() of %s at position %d", target.typ(), method.pos+1)
+		}
+		call.t = Number
+	case "startsWith", "endsWith", "contains", "matches":
+		if target.typ() != String || len(args) != 1 || args[0].typ() != String {
+			return nil, fmt.Errorf("no matching overload for %s() of %s at position %d, expected a string and a string argument", method.text, target.typ(), method.pos+1)
+		}
+		call.t = Bool
+		if literal, ok := args[0].(*literalNode); ok && method.text == "matches" {
+			pattern, err := regexp.Compile(literal.value.(string))
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression at position %d: %v", method.pos+1, err)
+			}
+			call.pattern = pattern
+		}
+	default:
+		return nil, fmt.Errorf("unknown method '%s' at position %d. Expected size, startsWith, endsWith, contains or matches", method.text, method.pos+1)
+	}
+	return call, nil
+}
+
+func overloadError(operator string, pos int, left, right node) error {
+	return fmt.Errorf("no matching overload for '%s' with %s and %s at position %d", operator, left.typ(), right.typ(), pos+1)
+}
+
+func fieldNames(fields map[string]Type) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type node interface {
+	typ() Type
+	eval(record map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+	t     Type
+}
+
+func (n *literalNode) typ() Type { return n.t }
+
+func (n *literalNode) eval(map[string]interface{}) (interface{}, error) { return n.value, nil }
+
+type fieldNode struct {
+	name string
+	t    Type
+}
+
+func (n *fieldNode) typ() Type { return n.t }
+
+func (n *fieldNode) eval(record map[string]interface{}) (interface{}, error) {
+	value, exists := record[n.name]
+	switch n.t {
+	case String:
+		if !exists {
+			return "", nil
+		}
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case Number:
+		switch number := value.(type) {
+		case nil:
+			return float64(0), nil
+		case float64:
+			return number, nil
+		case int:
+			return float64(number), nil
+		}
+	case Bool:
+		if !exists {
+			return false, nil
+		}
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case StringList:
+		switch list := value.(type) {
+		case nil:
+			return []interface{}{}, nil
+		case []string:
+			items := make([]interface{}, len(list))
+			for i, item := range list {
+				items[i] = item
+			}
+			return items, nil
+		}
+	}
+	return nil, fmt.Errorf("field '%s' is a %T, expected a %s", n.name, value, n.t)
+}
+
+type listNode struct {
+	items []node
+	t     Type
+}
+
+func (n *listNode) typ() Type { return n.t }
+
+func (n *listNode) eval(record map[string]interface{}) (interface{}, error) {
+	items := make([]interface{}, len(n.items))
+	for i, item := range n.items {
+		value, err := item.eval(record)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = value
+	}
+	return items, nil
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) typ() Type { return Bool }
+
+func (n *notNode) eval(record map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(record)
+	if err != nil {
+		return nil, err
+	}
+	return !value.(bool), nil
+}
+
+type negateNode struct {
+	operand node
+}
+
+func (n *negateNode) typ() Type { return Number }
+
+func (n *negateNode) eval(record map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(record)
+	if err != nil {
+		return nil, err
+	}
+	return -value.(float64), nil
+}
+
+// logicalNode evaluates && and ||, short-circuiting as CEL does
+type logicalNode struct {
+	operator    string
+	left, right node
+}
+
+func (n *logicalNode) typ() Type { return Bool }
+
+func (n *logicalNode) eval(record map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(record)
+	if err != nil {
+		return nil, err
+	}
+	if left.(bool) == (n.operator == "||") {
+		return left, nil
+	}
+	return n.right.eval(record)
+}
+
+type compareNode struct {
+	operator    string
+	left, right node
+}
+
+func (n *compareNode) typ() Type { return Bool }
+
+func (n *compareNode) eval(record map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(record)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(record)
+	if err != nil {
+		return nil, err
+	}
+	switch n.operator {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	}
+	var order int
+	if l, ok := left.(string); ok {
+		order = strings.Compare(l, right.(string))
+	} else if l, r := left.(float64), right.(float64); l < r {
+		order = -1
+	} else if l > r {
+		order = 1
+	}
+	switch n.operator {
+	case "<":
+		return order < 0, nil
+	case "<=":
+		return order <= 0, nil
+	case ">":
+		return order > 0, nil
+	default:
+		return order >= 0, nil
+	}
+}
+
+func equal(left, right interface{}) bool {
+	leftList, isList := left.([]interface{})
+	if !isList {
+		return left == right
+	}
+	rightList := right.([]interface{})
+	if len(leftList) != len(rightList) {
+		return false
+	}
+	for i := range leftList {
+		if leftList[i] != rightList[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type inNode struct {
+	value, list node
+}
+
+func (n *inNode) typ() Type { return Bool }
+
+func (n *inNode) eval(record map[string]interface{}) (interface{}, error) {
+	value, err := n.value.eval(record)
+	if err != nil {
+		return nil, err
+	}
+	list, err := n.list.eval(record)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range list.([]interface{}) {
+		if item == value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type callNode struct {
+	method  string
+	target  node
+	args    []node
+	t       Type
+	pattern *regexp.Regexp
+}
+
+func (n *callNode) typ() Type { return n.t }
+
+func (n *callNode) eval(record map[string]interface{}) (interface{}, error) {
+	target, err := n.target.eval(record)
+	if err != nil {
+		return nil, err
+	}
+	if n.method == "size" {
+		if list, ok := target.([]interface{}); ok {
+			return float64(len(list)), nil
+		}
+		return float64(len([]rune(target.(string)))), nil
+	}
+	arg, err := n.args[0].eval(record)
+	if err != nil {
+		return nil, err
+	}
+	s, argument := target.(string), arg.(string)
+	switch n.method {
+	case "startsWith":
+		return strings.HasPrefix(s, argument), nil
+	case "endsWith":
+		return strings.HasSuffix(s, argument), nil
+	case "contains":
+		return strings.Contains(s, argument), nil
+	}
+	pattern := n.pattern
+	if pattern == nil {
+		if pattern, err = regexp.Compile(argument); err != nil {
+			return nil, fmt.Errorf("invalid regular expression '%s': %v", argument, err)
+		}
+	}
+	return pattern.MatchString(s), nil
+}
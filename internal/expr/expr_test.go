@@ -0,0 +1,92 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testFields = map[string]Type{
+	"name":       String,
+	"status":     String,
+	"statusCode": Number,
+	"timedOut":   Bool,
+	"policies":   StringList,
+}
+
+func TestEval(t *testing.T) {
+	record := map[string]interface{}{
+		"name":       "@acme/ui",
+		"status":     "blocked",
+		"statusCode": 403,
+		"policies":   []string{"malicious-package", "cve-9"},
+	}
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{"status == 'blocked'", true},
+		{`status != "blocked"`, false},
+		{"status == 'blocked' && statusCode >= 400 && statusCode < 500", true},
+		{"status == 'approved' || name.startsWith('@acme/')", true},
+		{"!(status == 'blocked')", false},
+		{"status in ['blocked', 'unknown']", true},
+		{"statusCode in [200, 404]", false},
+		{"'cve-9' in policies", true},
+		{"size(policies) == 2 && name.size() == 8", true},
+		{"name.endsWith('/ui') && name.contains('acme')", true},
+		{"name.matches('^@[a-z]+/')", true},
+		{"statusCode == -403", false},
+		{"name > '@a' && name <= '@b'", true},
+		{"timedOut", false},
+		{"policies == ['malicious-package', 'cve-9']", true},
+		{"true || status.matches(name)", true},
+		{"'it\\'s' == \"it's\"", true},
+	}
+	for _, test := range tests {
+		program, err := Compile(test.expression, testFields)
+		require.NoError(t, err, test.expression)
+		matches, err := program.Eval(record)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, matches, test.expression)
+	}
+
+	// Missing fields are zero values
+	program, err := Compile("name == '' && statusCode == 0 && size(policies) == 0 && !timedOut", testFields)
+	require.NoError(t, err)
+	matches, err := program.Eval(map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.True(t, matches)
+
+	_, err = program.Eval(map[string]interface{}{"name": 42})
+	assert.EqualError(t, err, "field 'name' is a int, expected a string")
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"status == 403", "no matching overload for '==' with string and number at position 8"},
+		{"depClass == 'prod'", "unknown field 'depClass' at position 1. Expected one of: name, policies, status, statusCode, timedOut"},
+		{"status", "the expression is a string, expected a bool"},
+		{"status == 'blocked' &&", "unexpected 'end of expression' at position 23"},
+		{"(timedOut", "expected ')' at position 10, got 'end of expression'"},
+		{"status == 'blocked", "unterminated string at position 11"},
+		{"status = 'blocked'", "unexpected '=' at position 8"},
+		{"timedOut timedOut", "unexpected 'timedOut' at position 10"},
+		{"name.lower() == 'a'", "unknown method 'lower' at position 6. Expected size, startsWith, endsWith, contains or matches"},
+		{"statusCode.startsWith('4')", "no matching overload for startsWith() of number at position 12, expected a string and a string argument"},
+		{"name.matches('[')", "invalid regular expression at position 6: error parsing regexp: missing closing ]: `[`"},
+		{"status in ['a', 1]", "the elements of the list at position 11 aren't all of the same type"},
+		{"statusCode in policies", "no matching overload for 'in' with number and list(string) at position 12"},
+		{"timedOut < timedOut", "no matching overload for '<' with bool and bool at position 10"},
+		{"name.matches('^' + name)", "unexpected '+' at position 18"},
+		{"!status", "no matching overload for '!' with string at position 1"},
+	}
+	for _, test := range tests {
+		_, err := Compile(test.expression, testFields)
+		assert.EqualError(t, err, test.expected, test.expression)
+	}
+}
@@ -0,0 +1,254 @@
+// Package semver parses npm versions and matches them against npm semver ranges.
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Matches full and partial versions such as 1.2.3-beta.1, 1.2, 1.x or *
+var versionPattern = regexp.MustCompile(`^v?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// Version represents a parsed version. Missing or wildcard parts of partial versions are -1.
+type Version struct {
+	Parts      [3]int
+	Prerelease string
+}
+
+// comparator represents a single version constraint such as >=1.2.3
+type comparator struct {
+	operator string
+	version  Version
+}
+
+// Parse reads a full or partial version such as 1.2.3-beta.1, 1.2 or 1.x
+func Parse(value string) (Version, error) {
+	match := versionPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return Version{}, fmt.Errorf("invalid version '%s'", value)
+	}
+	version := Version{Parts: [3]int{-1, -1, -1}, Prerelease: match[4]}
+	for i := 0; i < 3; i++ {
+		part, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			break
+		}
+		version.Parts[i] = part
+	}
+	return version, nil
+}
+
+// Compare orders two versions, returning -1, 0 or 1. Prereleases precede their release.
+func Compare(a, b Version) int {
+	for i := 0; i < 3; i++ {
+		if a.Parts[i] != b.Parts[i] {
+			if a.Parts[i] < b.Parts[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case a.Prerelease == b.Prerelease:
+		return 0
+	case a.Prerelease == "":
+		return 1
+	case b.Prerelease == "":
+		return -1
+	}
+	return comparePrereleases(a.Prerelease, b.Prerelease)
+}
+
+func comparePrereleases(a, b string) int {
+	aIdentifiers, bIdentifiers := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aIdentifiers) && i < len(bIdentifiers); i++ {
+		aNumber, aErr := strconv.Atoi(aIdentifiers[i])
+		bNumber, bErr := strconv.Atoi(bIdentifiers[i])
+		switch {
+		case aErr == nil && bErr == nil && aNumber != bNumber:
+			if aNumber < bNumber {
+				return -1
+			}
+			return 1
+		case aErr == nil && bErr != nil:
+			return -1
+		case aErr != nil && bErr == nil:
+			return 1
+		case aIdentifiers[i] != bIdentifiers[i]:
+			return strings.Compare(aIdentifiers[i], bIdentifiers[i])
+		}
+	}
+	return len(aIdentifiers) - len(bIdentifiers)
+}
+
+// Satisfies reports whether version matches an npm semver range, e.g. ^16.8.0 || >=17 <19
+func Satisfies(version, versionRange string) (bool, error) {
+	parsed, err := Parse(version)
+	if err != nil {
+		return false, err
+	}
+	for _, alternative := range strings.Split(versionRange, "||") {
+		comparators, err := parseComparators(alternative)
+		if err != nil {
+			return false, err
+		}
+		if matchesAll(parsed, comparators) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func matchesAll(version Version, comparators []comparator) bool {
+	for _, c := range comparators {
+		result := Compare(version, c.version)
+		matched := false
+		switch c.operator {
+		case ">":
+			matched = result > 0
+		case ">=":
+			matched = result >= 0
+		case "<":
+			matched = result < 0
+		case "<=":
+			matched = result <= 0
+		default:
+			matched = result == 0
+		}
+		if !matched {
+			return false
+		}
+	}
+	// Prereleases only match ranges explicitly allowing a prerelease of the same version
+	if version.Prerelease != "" {
+		for _, c := range comparators {
+			if c.version.Prerelease != "" && c.version.Parts == version.Parts {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// parseComparators desugars the hyphen, caret, tilde and x-range forms of a space separated range to comparators
+func parseComparators(versionRange string) ([]comparator, error) {
+	fields := strings.Fields(versionRange)
+	if len(fields) == 3 && fields[1] == "-" {
+		lower, err := Parse(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		upper, err := Parse(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		comparators := []comparator{{">=", fill(lower)}}
+		if upper.Parts[0] >= 0 {
+			comparators = append(comparators, upperBound("<=", upper))
+		}
+		return comparators, nil
+	}
+
+	var comparators []comparator
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		value := strings.TrimLeft(field, "<>=~^")
+		operator := field[:len(field)-len(value)]
+		// Operators separated from their version, e.g. ">= 16"
+		if value == "" && i+1 < len(fields) {
+			i++
+			value = fields[i]
+		}
+		version, err := Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		desugared, err := desugar(operator, version)
+		if err != nil {
+			return nil, err
+		}
+		comparators = append(comparators, desugared...)
+	}
+	return comparators, nil
+}
+
+func desugar(operator string, version Version) ([]comparator, error) {
+	major, minor, patch := version.Parts[0], version.Parts[1], version.Parts[2]
+	switch operator {
+	case "^":
+		if major < 0 {
+			return nil, nil
+		}
+		lower := comparator{">=", fill(version)}
+		switch {
+		case major > 0 || minor < 0:
+			return []comparator{lower, {"<", Version{Parts: [3]int{major + 1, 0, 0}, Prerelease: "0"}}}, nil
+		case minor > 0 || patch < 0:
+			return []comparator{lower, {"<", Version{Parts: [3]int{0, minor + 1, 0}, Prerelease: "0"}}}, nil
+		default:
+			return []comparator{lower, {"<", Version{Parts: [3]int{0, 0, patch + 1}, Prerelease: "0"}}}, nil
+		}
+	case "~", "~>":
+		if major < 0 {
+			return nil, nil
+		}
+		lower := comparator{">=", fill(version)}
+		if minor < 0 {
+			return []comparator{lower, {"<", Version{Parts: [3]int{major + 1, 0, 0}, Prerelease: "0"}}}, nil
+		}
+		return []comparator{lower, {"<", Version{Parts: [3]int{major, minor + 1, 0}, Prerelease: "0"}}}, nil
+	case "", "=":
+		if major < 0 {
+			return nil, nil
+		}
+		if minor >= 0 && patch >= 0 {
+			return []comparator{{"=", version}}, nil
+		}
+		return []comparator{{">=", fill(version)}, upperBound("<=", version)}, nil
+	case ">", ">=":
+		if major < 0 {
+			return nil, nil
+		}
+		if operator == ">" && (minor < 0 || patch < 0) {
+			// >1.2 means >=1.3.0
+			next := upperBound("<=", version).version
+			next.Prerelease = ""
+			return []comparator{{">=", next}}, nil
+		}
+		return []comparator{{operator, fill(version)}}, nil
+	case "<", "<=":
+		if major < 0 {
+			return []comparator{{"<", Version{Prerelease: "0"}}}, nil
+		}
+		if operator == "<=" && (minor < 0 || patch < 0) {
+			return []comparator{upperBound("<=", version)}, nil
+		}
+		return []comparator{{"<", fill(version)}}, nil
+	}
+	return nil, fmt.Errorf("invalid range operator '%s'", operator)
+}
+
+// fill sets the missing parts of a partial version to 0
+func fill(version Version) Version {
+	for i := range version.Parts {
+		if version.Parts[i] < 0 {
+			version.Parts[i] = 0
+		}
+	}
+	return version
+}
+
+// upperBound returns the exclusive bound of a partial version, e.g. <2.0.0-0 for <=1.x
+func upperBound(operator string, version Version) comparator {
+	major, minor, patch := version.Parts[0], version.Parts[1], version.Parts[2]
+	switch {
+	case minor < 0:
+		return comparator{"<", Version{Parts: [3]int{major + 1, 0, 0}, Prerelease: "0"}}
+	case patch < 0:
+		return comparator{"<", Version{Parts: [3]int{major, minor + 1, 0}, Prerelease: "0"}}
+	}
+	return comparator{operator, version}
+}
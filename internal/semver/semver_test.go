@@ -0,0 +1,41 @@
+package semver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSatisfiesRange(t *testing.T) {
+	tests := []struct {
+		version      string
+		versionRange string
+		expected     bool
+	}{
+		{"18.2.0", "^18.0.0", true},
+		{"19.0.0", "^18.0.0", false},
+		{"0.2.5", "^0.2.3", true},
+		{"0.3.0", "^0.2.3", false},
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"17.0.2", "^16.8.0 || ^17.0.0", true},
+		{"16.7.0", "^16.8.0 || ^17.0.0", false},
+		{"5.1.0", ">=4 <6", true},
+		{"6.0.0", ">= 4 < 6", false},
+		{"1.9.0", "1.x", true},
+		{"2.0.0", "1.x", false},
+		{"2.5.0", "1.0.0 - 2.x", true},
+		{"3.0.0", "1.0.0 - 2.x", false},
+		{"4.0.0", "*", true},
+		{"18.3.0-canary.1", "^18.0.0", false},
+		{"18.3.0-canary.2", ">=18.3.0-canary.1", true},
+	}
+	for _, test := range tests {
+		satisfied, err := Satisfies(test.version, test.versionRange)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, satisfied, "%s %s", test.version, test.versionRange)
+	}
+
+	_, err := Satisfies("1.0.0", "workspace:*")
+	assert.Error(t, err)
+}
@@ -1,25 +1,13 @@
+//go:generate go run ./cmd/gendocs -out dist
+
 package main
 
 import (
 	"github.com/jfrog/jfrog-cli-core/v2/plugins"
-	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
 	"github.com/jfrog/jfrog-cli-plugin-template/commands"
 )
 
+// The JFrog CLI plugin entry point, installed with 'jf plugin install ca-extension'.
 func main() {
-	plugins.PluginMain(getApp())
-}
-
-func getApp() components.App {
-	app := components.App{}
-	app.Name = "hello-frog"
-	app.Description = "Easily greet anyone."
-	app.Version = "v0.1.2"
-	app.Commands = getCommands()
-	return app
-}
-
-func getCommands() []components.Command {
-	return []components.Command{
-		commands.GetHelloCommand()}
+	plugins.PluginMain(commands.GetApp())
 }